@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/analyze"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/spf13/cobra"
+)
+
+var (
+	analyzeStream   string
+	analyzeDuration time.Duration
+	analyzeWait     time.Duration
+	analyzeFormat   string
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Receive a stream and print a report on its health",
+	Long: `Receive a stream for a given duration and print a report with its packet
+rate, loss, jitter, pacing, channel levels and PTP status, e.g. for attaching
+to commissioning documents.
+
+The stream is given by its ID hash or name. Interrupting the analysis prints
+the report of what was received so far.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyze,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+	analyzeCmd.Flags().StringVar(&analyzeStream, "stream", "", "ID hash or name of the stream to analyze")
+	analyzeCmd.Flags().DurationVar(&analyzeDuration, "duration", time.Minute, "How long to receive the stream")
+	analyzeCmd.Flags().DurationVar(&analyzeWait, "wait", 10*time.Second, "How long to wait for the stream to be discovered")
+	analyzeCmd.Flags().StringVar(&analyzeFormat, "format", "text", "Report format ("+strings.Join(analyze.Formats, ", ")+")")
+	_ = analyzeCmd.MarkFlagRequired("stream")
+	_ = analyzeCmd.RegisterFlagCompletionFunc("stream", completeStreams)
+	_ = analyzeCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(analyze.Formats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// findStream returns the stream with the given ID hash or name
+func findStream(manager *stream.Manager, key string) (*stream.Stream, bool) {
+	for _, s := range manager.GetAllStreams() {
+		if s.IDHash() == key || s.Name() == key {
+			return s, true
+		}
+	}
+
+	return nil, false
+}
+
+// waitForStream waits for the stream with the given ID hash or name to be
+// discovered
+func waitForStream(ctx context.Context, manager *stream.Manager, key string, wait time.Duration) (*stream.Stream, error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		if s, ok := findStream(manager, key); ok {
+			return s, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("stream %q not found within %s", key, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// runAnalyze analyzes a stream and prints the report to stdout
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(analyze.Formats, analyzeFormat) {
+		return fmt.Errorf("unknown format %q, must be one of %s", analyzeFormat, strings.Join(analyze.Formats, ", "))
+	}
+
+	if analyzeDuration <= 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	manager, multicastIfis, err := startDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	ptpMonitor, err := ptp.NewMonitor(multicastIfis)
+	if err != nil {
+		slog.Error("error monitoring PTP - are you root?", "error", err)
+	} else {
+		defer ptpMonitor.Close()
+	}
+
+	s, err := waitForStream(ctx, manager, analyzeStream, analyzeWait)
+	if err != nil {
+		return err
+	}
+
+	analyzer, err := analyze.Start(analyze.Options{
+		Stream:     s,
+		PTPMonitor: ptpMonitor,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to receive stream: %w", err)
+	}
+	defer analyzer.Close()
+
+	slog.Info("Analyzing stream", "id-hash", s.IDHash(), "name", s.Name(), "duration", analyzeDuration)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(analyzeDuration):
+	}
+
+	return analyzer.Report().Write(os.Stdout, analyzeFormat)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const (
+	// streamCacheAge is how long streams that are no longer discovered
+	// stay in the stream cache
+	streamCacheAge = 7 * 24 * time.Hour
+
+	// streamCacheRefresh is how often the time a stream was last seen is
+	// updated in the stream cache
+	streamCacheRefresh = time.Hour
+)
+
+// cachedStream is a stream in the stream cache, which lists the streams
+// discovered by recent runs for completing their names and ID hashes
+type cachedStream struct {
+	IDHash   string    `json:"id-hash"`
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last-seen"`
+}
+
+// streamCachePath returns the location of the stream cache, empty if there
+// is no cache directory
+func streamCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "rtp-monitor", "streams.json")
+}
+
+// loadStreamCache reads the stream cache, leaving out expired streams
+func loadStreamCache(path string) ([]cachedStream, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []cachedStream
+	if err := json.Unmarshal(b, &streams); err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(streams, func(s cachedStream) bool {
+		return time.Since(s.LastSeen) > streamCacheAge
+	}), nil
+}
+
+// saveStreamCache writes the stream cache, replacing the file at once so
+// completions never read a partial file
+func saveStreamCache(path string, streams []cachedStream) error {
+	b, err := json.MarshalIndent(streams, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// cacheStreams adds the streams discovered by the manager to the stream
+// cache as they appear. Streams found by earlier runs are kept until they
+// expire.
+func cacheStreams(manager *stream.Manager) {
+	path := streamCachePath()
+	if path == "" {
+		return
+	}
+
+	cached := make(map[string]cachedStream)
+
+	streams, _ := loadStreamCache(path)
+	for _, s := range streams {
+		cached[s.IDHash] = s
+	}
+
+	var mutex sync.Mutex
+
+	manager.OnUpdate(func(streams []*stream.Stream) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		now := time.Now()
+		changed := false
+
+		for _, s := range streams {
+			c, ok := cached[s.IDHash()]
+			if ok && c.Name == s.Name() && now.Sub(c.LastSeen) < streamCacheRefresh {
+				continue
+			}
+
+			cached[s.IDHash()] = cachedStream{IDHash: s.IDHash(), Name: s.Name(), LastSeen: now}
+			changed = true
+		}
+
+		if !changed {
+			return
+		}
+
+		list := slices.SortedFunc(maps.Values(cached), func(a, b cachedStream) int {
+			return strings.Compare(a.Name+a.IDHash, b.Name+b.IDHash)
+		})
+
+		if err := saveStreamCache(path, list); err != nil {
+			slog.Debug("Failed to write the stream cache", "path", path, "error", err)
+		}
+	})
+}
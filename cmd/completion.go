@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// completionDiscoveryTime is how long streams are discovered for completing
+// their names and ID hashes if there is no stream cache
+const completionDiscoveryTime = 2 * time.Second
+
+// discoverForCompletion calls fn with the ID hash and name of each stream in
+// the stream cache. Without a cache, streams are discovered as configured by
+// the flags on the command line instead, which misses SAP streams that are
+// not announced within completionDiscoveryTime.
+func discoverForCompletion(fn func(idHash, name string)) {
+	if cached, err := loadStreamCache(streamCachePath()); err == nil && len(cached) > 0 {
+		for _, s := range cached {
+			fn(s.IDHash, s.Name)
+		}
+
+		return
+	}
+
+	// Log messages would garble the completion
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	manager, _, err := startDiscovery(context.Background())
+	if err != nil {
+		return
+	}
+	defer manager.Close()
+
+	time.Sleep(completionDiscoveryTime)
+
+	for _, s := range manager.GetAllStreams() {
+		fn(s.IDHash(), s.Name())
+	}
+}
+
+// completeStreamHashes completes the ID hashes of the discovered streams,
+// described by their names
+func completeStreamHashes(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+
+	discoverForCompletion(func(idHash, name string) {
+		completions = append(completions, cobra.CompletionWithDesc(idHash, name))
+	})
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeStreams completes the names and ID hashes of the discovered
+// streams
+func completeStreams(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var completions []cobra.Completion
+
+	discoverForCompletion(func(idHash, name string) {
+		completions = append(completions,
+			cobra.CompletionWithDesc(name, idHash),
+			cobra.CompletionWithDesc(idHash, name))
+	})
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/analyze"
+	"github.com/holoplot/rtp-monitor/internal/conformance"
+	"github.com/spf13/cobra"
+)
+
+var (
+	conformanceStream   string
+	conformanceDuration time.Duration
+	conformanceWait     time.Duration
+	conformanceFormat   string
+)
+
+var conformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Check a stream against AES67 and the ST 2110-30 conformance levels",
+	Long: `Check the sample rate, packet time, channel count, payload format and
+reference clock of a stream against AES67 and the ST 2110-30 conformance
+levels A, B and C, and receive it for a given duration to judge its packet
+pacing. Each item passes, warns or fails.
+
+The stream is given by its ID hash or name. A duration of zero checks the
+SDP only.`,
+	Args: cobra.NoArgs,
+	RunE: runConformance,
+}
+
+func init() {
+	rootCmd.AddCommand(conformanceCmd)
+	conformanceCmd.Flags().StringVar(&conformanceStream, "stream", "", "ID hash or name of the stream to check")
+	conformanceCmd.Flags().DurationVar(&conformanceDuration, "duration", 10*time.Second, "How long to receive the stream to measure its pacing")
+	conformanceCmd.Flags().DurationVar(&conformanceWait, "wait", 10*time.Second, "How long to wait for the stream to be discovered")
+	conformanceCmd.Flags().StringVar(&conformanceFormat, "format", "text", "Report format ("+strings.Join(conformance.Formats, ", ")+")")
+	_ = conformanceCmd.MarkFlagRequired("stream")
+	_ = conformanceCmd.RegisterFlagCompletionFunc("stream", completeStreams)
+	_ = conformanceCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(conformance.Formats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// runConformance checks a stream and prints the report to stdout
+func runConformance(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(conformance.Formats, conformanceFormat) {
+		return fmt.Errorf("unknown format %q, must be one of %s", conformanceFormat, strings.Join(conformance.Formats, ", "))
+	}
+
+	if conformanceDuration < 0 {
+		return fmt.Errorf("--duration must not be negative")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	manager, _, err := startDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	s, err := waitForStream(ctx, manager, conformanceStream, conformanceWait)
+	if err != nil {
+		return err
+	}
+
+	var pacing []string
+
+	if conformanceDuration > 0 {
+		analyzer, err := analyze.Start(analyze.Options{Stream: s})
+		if err != nil {
+			return fmt.Errorf("failed to receive stream: %w", err)
+		}
+		defer analyzer.Close()
+
+		slog.Info("Receiving stream", "id-hash", s.IDHash(), "name", s.Name(), "duration", conformanceDuration)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(conformanceDuration):
+		}
+
+		pacing = conformance.Pacing(analyzer.Report())
+	}
+
+	return conformance.Check(s, pacing).Write(os.Stdout, conformanceFormat)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// runDaemon runs discovery, statistics and alerting without the UI and
+// writes all events as structured lines to out. Statistics are collected for
+// the streams in monitorIDHashes, or for all streams if none are given, and
+// written at most once per reportInterval and stream.
+func runDaemon(manager *stream.Manager, collector *stats.Collector, watcher *events.Watcher,
+	monitorIDHashes []string, reportInterval time.Duration, out io.Writer, format string) error {
+	writer, err := events.NewWriter(out, format)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Starting daemon mode", "monitorIDs", monitorIDHashes, "reportInterval", reportInterval, "format", format)
+
+	ch := watcher.SubscribeWithStreams()
+	defer watcher.Unsubscribe(ch)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	limiter := events.NewStatsLimiter(reportInterval)
+
+	for {
+		select {
+		case sig := <-sigChan:
+			slog.Info("Received signal, shutting down gracefully", "signal", sig)
+			collector.StopAll()
+
+			slog.Info("Daemon mode shutdown complete")
+			return nil
+
+		case event := <-ch:
+			switch event.Type {
+			case events.TypeStreamAdded:
+				entry := event.Data.(inventory.Entry)
+
+				if len(monitorIDHashes) == 0 || slices.Contains(monitorIDHashes, entry.IDHash) {
+					if s, ok := manager.GetStream(entry.ID); ok {
+						if err := collector.Start(s); err != nil {
+							slog.Error("Failed to collect statistics", "stream", s.ID, "error", err)
+						}
+					}
+				}
+
+			case events.TypeStreamRemoved:
+				collector.Stop(event.Data.(inventory.Entry).ID)
+			}
+
+			if !limiter.Allow(event) {
+				continue
+			}
+
+			if err := writer.Write(event); err != nil {
+				return err
+			}
+		}
+	}
+}
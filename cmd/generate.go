@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/generate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateName        string
+	generateAddress     string
+	generateEncoding    string
+	generateSampleRate  uint32
+	generateChannels    int
+	generatePacketTime  time.Duration
+	generateSignals     []string
+	generateLevel       float64
+	generatePayloadType uint8
+	generateTTL         int
+	generateDSCP        int
+	generateSAP         bool
+	generateDuration    time.Duration
+	generateOutput      string
+
+	generateLoss                  float64
+	generateJitter                time.Duration
+	generateReorder               float64
+	generateSSRCChangeInterval    time.Duration
+	generateTimestampJumpInterval time.Duration
+	generateTimestampJump         int
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Transmit an AES67 test stream",
+	Long: `Transmit an AES67 multicast stream with a sine, noise or silence on each
+channel, so receivers and the monitor itself can be exercised without audio
+hardware.
+
+The stream is sent on the first interface given with --interface, or on the
+default route. Its SDP can be announced via SAP with --sap or saved with
+--output for loading it with --sdp. The RTP timestamps follow the system
+clock, which is not locked to PTP.
+
+Impairments such as packet loss, jitter, reordering, SSRC changes and
+timestamp jumps can be injected to validate receivers and the statistics of
+the monitor against known fault patterns.`,
+	Args: cobra.NoArgs,
+	RunE: runGenerate,
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.Flags().StringVar(&generateName, "name", "rtp-monitor test stream", "Session name of the stream")
+	generateCmd.Flags().StringVar(&generateAddress, "address", "239.69.1.1:5004", "Multicast group and port to send the stream to")
+	generateCmd.Flags().StringVar(&generateEncoding, "encoding", "L24", "Sample encoding ("+strings.Join(generate.Encodings, ", ")+")")
+	generateCmd.Flags().Uint32Var(&generateSampleRate, "sample-rate", 48000, "Sample rate in Hz")
+	generateCmd.Flags().IntVar(&generateChannels, "channels", 2, "Number of channels")
+	generateCmd.Flags().DurationVar(&generatePacketTime, "ptime", time.Millisecond, "Packet time")
+	generateCmd.Flags().StringSliceVar(&generateSignals, "signal", []string{"sine:1000"}, "Signal of each channel, the last one is used for the remaining channels (sine[:<Hz>], noise, silence)")
+	generateCmd.Flags().Float64Var(&generateLevel, "level", -18, "Level of the signals in dBFS")
+	generateCmd.Flags().Uint8Var(&generatePayloadType, "payload-type", 98, "RTP payload type")
+	generateCmd.Flags().IntVar(&generateTTL, "ttl", 16, "Multicast TTL")
+	generateCmd.Flags().IntVar(&generateDSCP, "dscp", 34, "DSCP of the packets")
+	generateCmd.Flags().BoolVar(&generateSAP, "sap", false, "Announce the stream via SAP")
+	generateCmd.Flags().DurationVar(&generateDuration, "duration", 0, "How long to transmit the stream (default until interrupted)")
+	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "File to save the SDP of the stream to")
+	generateCmd.Flags().Float64Var(&generateLoss, "loss", 0, "Percentage of packets to drop")
+	generateCmd.Flags().DurationVar(&generateJitter, "jitter", 0, "Maximum random delay of a packet")
+	generateCmd.Flags().Float64Var(&generateReorder, "reorder", 0, "Percentage of packets to send after the next one")
+	generateCmd.Flags().DurationVar(&generateSSRCChangeInterval, "ssrc-change-interval", 0, "Interval between two changes of the SSRC")
+	generateCmd.Flags().DurationVar(&generateTimestampJumpInterval, "timestamp-jump-interval", 0, "Interval between two jumps of the RTP timestamp")
+	generateCmd.Flags().IntVar(&generateTimestampJump, "timestamp-jump", 4800, "Frames the RTP timestamp jumps by")
+	_ = generateCmd.RegisterFlagCompletionFunc("encoding", cobra.FixedCompletions(generate.Encodings, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// runGenerate transmits a test stream until interrupted or --duration passed
+func runGenerate(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(generate.Encodings, generateEncoding) {
+		return fmt.Errorf("unknown encoding %q, must be one of %s", generateEncoding, strings.Join(generate.Encodings, ", "))
+	}
+
+	if generateDuration < 0 {
+		return fmt.Errorf("--duration must not be negative")
+	}
+
+	if generateTTL < 1 || generateTTL > 255 {
+		return fmt.Errorf("--ttl must be between 1 and 255")
+	}
+
+	if generateDSCP < 0 || generateDSCP > 63 {
+		return fmt.Errorf("--dscp must be between 0 and 63")
+	}
+
+	if generateLevel > 0 {
+		return fmt.Errorf("--level must not be above 0 dBFS")
+	}
+
+	if generateLoss < 0 || generateLoss > 100 || generateReorder < 0 || generateReorder > 100 {
+		return fmt.Errorf("--loss and --reorder must be between 0 and 100")
+	}
+
+	if generateJitter < 0 || generateSSRCChangeInterval < 0 || generateTimestampJumpInterval < 0 {
+		return fmt.Errorf("--jitter and the intervals must not be negative")
+	}
+
+	destination, err := net.ResolveUDPAddr("udp4", generateAddress)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", generateAddress, err)
+	}
+
+	var signals []generate.Signal
+
+	for _, s := range generateSignals {
+		signal, err := generate.ParseSignal(s)
+		if err != nil {
+			return err
+		}
+
+		signals = append(signals, signal)
+	}
+
+	var ifi *net.Interface
+
+	switch len(interfaceNames) {
+	case 0:
+	case 1:
+		if ifi, err = net.InterfaceByName(interfaceNames[0]); err != nil {
+			return fmt.Errorf("failed to get network interface %s: %w", interfaceNames[0], err)
+		}
+	default:
+		return fmt.Errorf("a stream can only be sent on one interface")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+
+	g, err := generate.Start(generate.Options{
+		Name:        generateName,
+		Destination: destination,
+		Interface:   ifi,
+		Encoding:    generateEncoding,
+		SampleRate:  generateSampleRate,
+		Channels:    generateChannels,
+		PacketTime:  generatePacketTime,
+		Signals:     signals,
+		Level:       generateLevel,
+		PayloadType: generatePayloadType,
+		TTL:         generateTTL,
+		DSCP:        generateDSCP,
+		SAP:         generateSAP,
+		Impairments: generate.Impairments{
+			Loss:                  generateLoss,
+			Jitter:                generateJitter,
+			Reorder:               generateReorder,
+			SSRCChangeInterval:    generateSSRCChangeInterval,
+			TimestampJumpInterval: generateTimestampJumpInterval,
+			TimestampJump:         generateTimestampJump,
+		},
+	}, func(err error) {
+		select {
+		case errChan <- err:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	if generateOutput != "" {
+		if err := os.WriteFile(generateOutput, g.SDP(), 0o644); err != nil {
+			return fmt.Errorf("failed to save SDP: %w", err)
+		}
+	}
+
+	slog.Info("Transmitting test stream", "name", generateName, "address", destination,
+		"format", fmt.Sprintf("%s/%d/%d", generateEncoding, generateSampleRate, generateChannels),
+		"ptime", generatePacketTime, "sap", generateSAP)
+
+	var timeout <-chan time.Time
+	if generateDuration > 0 {
+		timeout = time.After(generateDuration)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-timeout:
+	case err := <-errChan:
+		return err
+	}
+
+	c := g.Counters()
+	slog.Info("Stopped transmitting test stream", "packets", c.Packets, "dropped", c.Dropped,
+		"reordered", c.Reordered, "ssrc-changes", c.SSRCChanges, "timestamp-jumps", c.TimestampJumps)
+
+	return nil
+}
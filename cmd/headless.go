@@ -12,11 +12,13 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/holoplot/rtp-monitor/internal/stats"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 )
 
-// runHeadless runs the application in headless mode
-func runHeadless(manager *stream.Manager, monitorIDHashes []string, reportInterval time.Duration) error {
+// runHeadless runs the application in headless mode. If collector is not
+// nil, statistics of the monitored streams are collected for the metrics.
+func runHeadless(manager *stream.Manager, monitorIDHashes []string, reportInterval time.Duration, collector *stats.Collector) error {
 	slog.Info("Starting headless mode", "monitorIDs", monitorIDHashes, "reportInterval", reportInterval)
 
 	// Track discovered streams
@@ -45,6 +47,12 @@ func runHeadless(manager *stream.Manager, monitorIDHashes []string, reportInterv
 				// Start monitoring if this stream ID is in the monitor list
 				if slices.Contains(monitorIDHashes, s.IDHash()) {
 					monitoredReceivers[s.ID] = startMonitoring(s, reportInterval)
+
+					if collector != nil {
+						if err := collector.Start(s); err != nil {
+							slog.Error("Failed to collect statistics", "stream", s.ID, "error", err)
+						}
+					}
 				}
 			}
 		}
@@ -55,6 +63,10 @@ func runHeadless(manager *stream.Manager, monitorIDHashes []string, reportInterv
 				slog.Info("Stream disappeared", "id", id, "id-hash", s.IDHash(), "name", s.Name())
 				delete(discoveredStreams, id)
 
+				if collector != nil {
+					collector.Stop(id)
+				}
+
 				// Stop monitoring if this stream was being monitored
 				if monitor, exists := monitoredReceivers[id]; exists {
 					monitor.Stop()
@@ -92,6 +104,10 @@ func runHeadless(manager *stream.Manager, monitorIDHashes []string, reportInterv
 		monitor.Stop()
 	}
 
+	if collector != nil {
+		collector.StopAll()
+	}
+
 	slog.Info("Headless mode shutdown complete")
 	return nil
 }
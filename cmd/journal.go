@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	journalTypes  []string
+	journalHashes []string
+	journalSince  string
+	journalUntil  string
+	journalJSON   bool
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal <file>...",
+	Short: "Print the events of session journals",
+	Long: `Print the events of the journals written with --journal-dir, one line per
+event, to reconstruct the timeline of an incident.
+
+--since and --until take a time in RFC 3339 format, e.g. 2026-10-15T14:00:00+02:00,
+or a duration that is subtracted from the current time, e.g. 2h. With --json, the
+matching lines are printed as they are in the journal.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runJournal,
+}
+
+func init() {
+	rootCmd.AddCommand(journalCmd)
+	journalCmd.Flags().StringArrayVar(&journalTypes, "type", []string{}, "Event type to print (can be used multiple times)")
+	journalCmd.Flags().StringArrayVar(&journalHashes, "hash", []string{}, "Stream ID hash to print the events of (can be used multiple times)")
+	journalCmd.Flags().StringVar(&journalSince, "since", "", "Print events from this time on")
+	journalCmd.Flags().StringVar(&journalUntil, "until", "", "Print events up to this time")
+	journalCmd.Flags().BoolVar(&journalJSON, "json", false, "Print the matching journal lines as JSON")
+
+	_ = journalCmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(journalEventTypes(), cobra.ShellCompDirectiveNoFileComp))
+}
+
+// journalEventTypes lists the types of the entries in a journal
+func journalEventTypes() []string {
+	types := slices.DeleteFunc(slices.Clone(events.Types), func(t string) bool {
+		return t == events.TypeStats
+	})
+
+	return append(types, journal.TypeSessionStarted, journal.TypeSessionStopped)
+}
+
+// parseJournalTime parses a time in RFC 3339 format or a duration before now
+func parseJournalTime(flag, s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q, must be a time in RFC 3339 format or a duration", flag, s)
+	}
+
+	return t, nil
+}
+
+// runJournal prints the matching entries of the given journals
+func runJournal(cmd *cobra.Command, args []string) error {
+	types := journalEventTypes()
+	for _, t := range journalTypes {
+		if !slices.Contains(types, t) {
+			return fmt.Errorf("unknown event type %q, must be one of %s", t, strings.Join(types, ", "))
+		}
+	}
+
+	now := time.Now()
+
+	since, err := parseJournalTime("since", journalSince, now)
+	if err != nil {
+		return err
+	}
+
+	until, err := parseJournalTime("until", journalUntil, now)
+	if err != nil {
+		return err
+	}
+
+	filter := journal.Filter{
+		Types:    journalTypes,
+		IDHashes: journalHashes,
+		Since:    since,
+		Until:    until,
+	}
+
+	for _, path := range args {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open journal: %w", err)
+		}
+
+		err = journal.Read(f, func(e journal.Entry) error {
+			if !filter.Match(e) {
+				return nil
+			}
+
+			if journalJSON {
+				_, err := fmt.Fprintf(os.Stdout, "%s\n", e.Line)
+				return err
+			}
+
+			_, err := fmt.Fprintln(os.Stdout, journal.Format(e))
+			return err
+		})
+
+		f.Close()
+
+		if err != nil {
+			return fmt.Errorf("failed to read journal %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
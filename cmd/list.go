@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listFormat string
+	listWait   time.Duration
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the discovered streams",
+	Long: `Discover streams for a bounded time and print them with all parsed fields,
+for scripts and for importing into asset databases and spreadsheets.
+
+CSV output has one row per stream source, JSON output one object per stream,
+table output one aligned row per stream.`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().StringVar(&listFormat, "format", "csv", "Output format ("+strings.Join(inventory.Formats, ", ")+")")
+	listCmd.Flags().DurationVar(&listWait, "wait", 5*time.Second, "How long to discover streams before printing them")
+	_ = listCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(inventory.Formats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// runList discovers streams and prints the inventory to stdout
+func runList(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(inventory.Formats, listFormat) {
+		return fmt.Errorf("unknown format %q, must be one of %s", listFormat, strings.Join(inventory.Formats, ", "))
+	}
+
+	if listWait < 0 {
+		return fmt.Errorf("--wait must not be negative")
+	}
+
+	manager, _, err := startDiscovery(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	time.Sleep(listWait)
+
+	return inventory.Write(os.Stdout, listFormat, inventory.FromStreams(manager.GetAllStreams()))
+}
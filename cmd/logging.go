@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/logfile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logFile     string
+	logMaxSize  int
+	logMaxFiles int
+
+	logWriter *logfile.Writer
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "File to write the log to as JSON lines, including stream events and alerts")
+	rootCmd.PersistentFlags().IntVar(&logMaxSize, "log-max-size", 10, "Size in MB at which --log-file is rotated")
+	rootCmd.PersistentFlags().IntVar(&logMaxFiles, "log-max-files", 5, "Number of rotated log files to keep")
+
+	rootCmd.PersistentPreRunE = setupLogging
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if logWriter != nil {
+			logWriter.Close()
+		}
+	}
+}
+
+// setupLogging sends the log to --log-file if given, instead of stderr where
+// it is overdrawn by the UI
+func setupLogging(cmd *cobra.Command, args []string) error {
+	if logFile == "" {
+		return nil
+	}
+
+	if logMaxSize <= 0 {
+		return fmt.Errorf("--log-max-size must be positive")
+	}
+
+	w, err := logfile.Open(logFile, int64(logMaxSize)<<20, logMaxFiles)
+	if err != nil {
+		return err
+	}
+
+	logWriter = w
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, nil)))
+
+	return nil
+}
+
+// logEvents logs the known streams, then stream changes, alerts and PTP
+// grandmaster changes until the watcher is closed
+func logEvents(watcher *events.Watcher) {
+	ch := watcher.SubscribeWithStreams()
+
+	go func() {
+		defer watcher.Unsubscribe(ch)
+
+		for {
+			select {
+			case <-watcher.Done():
+				return
+			case event := <-ch:
+				logEvent(event)
+			}
+		}
+	}()
+}
+
+// streamEventMessages are the log messages of stream events
+var streamEventMessages = map[string]string{
+	events.TypeStreamAdded:   "Stream added",
+	events.TypeStreamUpdated: "Stream updated",
+	events.TypeStreamRemoved: "Stream removed",
+}
+
+func logEvent(event events.Event) {
+	switch data := event.Data.(type) {
+	case inventory.Entry:
+		slog.Info(streamEventMessages[event.Type],
+			"id-hash", event.IDHash, "name", data.Name, "host", data.Host, "discovery", data.Discovery)
+
+	case events.Alert:
+		level := slog.LevelWarn
+		if data.Level == "error" {
+			level = slog.LevelError
+		}
+
+		slog.Log(context.Background(), level, data.Message, "id-hash", event.IDHash, "metric", data.Metric, "value", data.Value)
+
+	case events.GrandmasterChange:
+		slog.Info("PTP grandmaster changed",
+			"domain", data.Domain, "grandmaster", data.Grandmaster, "previous", data.Previous)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ptpDuration time.Duration
+	ptpFormat   string
+)
+
+var ptpCmd = &cobra.Command{
+	Use:   "ptp",
+	Short: "Listen for PTP messages and print the transmitters seen",
+	Long: `Passively listen for PTP messages for a given duration and print the
+transmitters seen with their domains, announced grandmasters, clock classes
+and message rates, for troubleshooting clocking without the UI.
+
+Listening on the PTP ports requires root privileges. Interrupting the command
+prints the report of what was received so far.`,
+	Args: cobra.NoArgs,
+	RunE: runPTP,
+}
+
+func init() {
+	rootCmd.AddCommand(ptpCmd)
+	ptpCmd.Flags().DurationVar(&ptpDuration, "duration", 10*time.Second, "How long to listen for PTP messages")
+	ptpCmd.Flags().StringVar(&ptpFormat, "format", "text", "Report format ("+strings.Join(ptp.Formats, ", ")+")")
+	_ = ptpCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(ptp.Formats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// runPTP monitors PTP and prints the report to stdout
+func runPTP(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(ptp.Formats, ptpFormat) {
+		return fmt.Errorf("unknown format %q, must be one of %s", ptpFormat, strings.Join(ptp.Formats, ", "))
+	}
+
+	if ptpDuration <= 0 {
+		return fmt.Errorf("--duration must be positive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	multicastIfis, err := multicastInterfaces()
+	if err != nil {
+		return err
+	}
+
+	monitor, err := ptp.NewMonitor(multicastIfis)
+	if err != nil {
+		return fmt.Errorf("failed to monitor PTP (are you root?): %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(ptpDuration):
+	}
+
+	return monitor.Report().Write(os.Stdout, ptpFormat)
+}
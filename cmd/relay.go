@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/relay"
+	"github.com/spf13/cobra"
+)
+
+var (
+	relayStream      string
+	relaySource      int
+	relayTo          string
+	relayToInterface string
+	relayTTL         int
+	relayDSCP        int
+	relayName        string
+	relaySAP         bool
+	relayWait        time.Duration
+	relayOutput      string
+)
+
+var relayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "Re-transmit a stream to another multicast group",
+	Long: `Receive a stream and re-transmit its packets unchanged to another multicast
+group, e.g. for crossing between networks or feeding lab receivers a copy of
+a production stream.
+
+The stream is given by its ID hash or name and received on the interfaces
+given with --interface. The copy is sent on --to-interface, or on the default
+route. Its SDP can be announced via SAP with --sap or saved with --output.`,
+	Args: cobra.NoArgs,
+	RunE: runRelay,
+}
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+	relayCmd.Flags().StringVar(&relayStream, "stream", "", "ID hash or name of the stream to relay")
+	relayCmd.Flags().IntVar(&relaySource, "source", 1, "Source of a redundant stream to relay")
+	relayCmd.Flags().StringVar(&relayTo, "to", "", "Multicast group and port to relay the stream to, e.g. 239.69.2.1:5004")
+	relayCmd.Flags().StringVar(&relayToInterface, "to-interface", "", "Network interface to relay the stream on")
+	relayCmd.Flags().IntVar(&relayTTL, "ttl", 16, "Multicast TTL")
+	relayCmd.Flags().IntVar(&relayDSCP, "dscp", 34, "DSCP of the packets")
+	relayCmd.Flags().StringVar(&relayName, "name", "", "Session name of the relayed stream (default the name of the stream with \" (relay)\")")
+	relayCmd.Flags().BoolVar(&relaySAP, "sap", false, "Announce the relayed stream via SAP")
+	relayCmd.Flags().DurationVar(&relayWait, "wait", 10*time.Second, "How long to wait for the stream to be discovered")
+	relayCmd.Flags().StringVarP(&relayOutput, "output", "o", "", "File to save the SDP of the relayed stream to")
+	_ = relayCmd.MarkFlagRequired("stream")
+	_ = relayCmd.MarkFlagRequired("to")
+	_ = relayCmd.RegisterFlagCompletionFunc("stream", completeStreams)
+}
+
+// runRelay relays a stream until interrupted
+func runRelay(cmd *cobra.Command, args []string) error {
+	if relayTTL < 1 || relayTTL > 255 {
+		return fmt.Errorf("--ttl must be between 1 and 255")
+	}
+
+	if relayDSCP < 0 || relayDSCP > 63 {
+		return fmt.Errorf("--dscp must be between 0 and 63")
+	}
+
+	destination, err := net.ResolveUDPAddr("udp4", relayTo)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", relayTo, err)
+	}
+
+	var ifi *net.Interface
+
+	if relayToInterface != "" {
+		if ifi, err = net.InterfaceByName(relayToInterface); err != nil {
+			return fmt.Errorf("failed to get network interface %s: %w", relayToInterface, err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	manager, _, err := startDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	s, err := waitForStream(ctx, manager, relayStream, relayWait)
+	if err != nil {
+		return err
+	}
+
+	name := relayName
+	if name == "" {
+		name = s.Name() + " (relay)"
+	}
+
+	r, err := relay.Start(relay.Options{
+		Stream:      s,
+		Source:      relaySource - 1,
+		Destination: destination,
+		Interface:   ifi,
+		TTL:         relayTTL,
+		DSCP:        relayDSCP,
+		Name:        name,
+		SAP:         relaySAP,
+	}, func(err error) {
+		slog.Error("error relaying stream", "error", err)
+	})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if relayOutput != "" {
+		if err := os.WriteFile(relayOutput, r.SDP(), 0o644); err != nil {
+			return fmt.Errorf("failed to save SDP: %w", err)
+		}
+	}
+
+	slog.Info("Relaying stream", "id-hash", s.IDHash(), "name", s.Name(), "source", relaySource,
+		"to", destination, "sap", relaySAP)
+
+	<-ctx.Done()
+
+	relayed, failed := r.Packets()
+	slog.Info("Stopped relaying stream", "packets", relayed, "failed", failed)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/spf13/cobra"
+)
+
+var sdpOutput string
+
+var sdpCmd = &cobra.Command{
+	Use:   "sdp",
+	Short: "Work with session descriptions",
+}
+
+var sdpFetchCmd = &cobra.Command{
+	Use:   "fetch <rtsp-url|mdns-service-name>",
+	Short: "Fetch the SDP of a stream via RTSP",
+	Long: `Fetch the SDP of a stream with an RTSP DESCRIBE request and print it, or
+save it with --output.
+
+The stream is given by its RTSP URL, e.g. rtsp://192.168.1.10/by-name/Stage,
+or by the name of the RAVENNA session announced via mDNS, which is resolved
+with Avahi.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSDPFetch,
+}
+
+func init() {
+	rootCmd.AddCommand(sdpCmd)
+	sdpCmd.AddCommand(sdpFetchCmd)
+	sdpFetchCmd.Flags().StringVarP(&sdpOutput, "output", "o", "", "File to save the SDP to instead of printing it")
+}
+
+// runSDPFetch fetches an SDP and prints or saves it
+func runSDPFetch(cmd *cobra.Command, args []string) error {
+	uri := args[0]
+
+	if !strings.HasPrefix(uri, "rtsp://") && !strings.HasPrefix(uri, "rtsps://") {
+		var err error
+		if uri, err = stream.ResolveMDNS(args[0]); err != nil {
+			return err
+		}
+	}
+
+	sdp, err := stream.ReadRTSP(uri)
+	if err != nil {
+		return fmt.Errorf("failed to fetch SDP from %s: %w", uri, err)
+	}
+
+	if sdpOutput == "" {
+		_, err := os.Stdout.Write(sdp)
+		return err
+	}
+
+	if err := os.WriteFile(sdpOutput, sdp, 0o644); err != nil {
+		return fmt.Errorf("failed to save SDP: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,373 @@
+// Package analyze receives a stream for a while and reports on its health,
+// e.g. for attaching to commissioning documents.
+package analyze
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+// delayFactorWindow is the window the delay factor is measured over
+const delayFactorWindow = time.Second
+
+// Pacing verdicts. Receivers following AES67 buffer at least three packet
+// times, so a delay factor up to that is tolerated.
+const (
+	PacingGood       = "good"
+	PacingAcceptable = "acceptable"
+	PacingPoor       = "poor"
+	PacingUnknown    = "unknown"
+)
+
+// PTP states of a source
+const (
+	PTPLocked        = "grandmaster matches"
+	PTPMismatch      = "grandmaster differs"
+	PTPNoGrandmaster = "no grandmaster seen in domain"
+	PTPNotMonitored  = "not monitored"
+	PTPNoReference   = "no PTP reference clock"
+)
+
+// SourceReport is the analysis of one stream source
+type SourceReport struct {
+	Sender      string `json:"sender"`
+	Destination string `json:"destination"`
+
+	// Error is why the source could not be received, if it could not
+	Error string `json:"error,omitempty"`
+
+	Packets        uint64  `json:"packets"`
+	Bytes          uint64  `json:"bytes"`
+	PacketRate     float64 `json:"packet-rate"`
+	Bitrate        float64 `json:"bitrate"`
+	Expected       uint64  `json:"expected"`
+	Lost           int64   `json:"lost"`
+	LossPercent    float64 `json:"loss-percent"`
+	Reordered      uint64  `json:"reordered"`
+	SequenceErrors uint64  `json:"sequence-errors"`
+	RTPErrors      uint64  `json:"rtp-errors"`
+
+	// JitterSeconds is the interarrival jitter as in RFC 3550
+	JitterSeconds float64 `json:"jitter-seconds"`
+
+	// MaxInterArrivalSeconds is the longest time between two packets
+	MaxInterArrivalSeconds float64 `json:"max-inter-arrival-seconds"`
+
+	// DelayFactorSeconds is the largest time-stamped delay factor (TS-DF,
+	// EBU Tech 3337) of a one second window: the spread of the packet
+	// arrival times relative to their RTP timestamps
+	DelayFactorSeconds float64 `json:"delay-factor-seconds"`
+	PacketTimeSeconds  float64 `json:"packet-time-seconds"`
+	Pacing             string  `json:"pacing"`
+
+	ReferenceClock string `json:"reference-clock,omitempty"`
+	Grandmaster    string `json:"grandmaster,omitempty"`
+	PTP            string `json:"ptp"`
+
+	Levels []levels.ChannelLevel `json:"levels,omitempty"`
+}
+
+// Report is the analysis of a stream
+type Report struct {
+	Stream   inventory.Entry `json:"stream"`
+	Started  time.Time       `json:"started"`
+	Duration float64         `json:"duration-seconds"`
+	Sources  []SourceReport  `json:"sources"`
+}
+
+// source accumulates the measurements of one stream source
+type source struct {
+	clockRate  uint32
+	packetTime time.Duration
+
+	packets      uint64
+	bytes        uint64
+	firstArrival time.Time
+	lastArrival  time.Time
+	maxGap       time.Duration
+
+	sequence stream.SequenceTracker
+
+	// Interarrival jitter as in RFC 3550, in units of the RTP clock
+	jitter        float64
+	elapsed       float64 // RTP clock units since the first packet
+	lastTransit   float64
+	lastTimestamp uint32
+
+	// Delay factor of the current window and the largest so far, in seconds
+	windowStart    time.Time
+	windowMin      float64
+	windowMax      float64
+	maxDelayFactor float64
+}
+
+// add accounts a packet received at the given time
+func (s *source) add(now time.Time, packet *rtp.Packet) {
+	first := s.packets == 0
+
+	s.packets++
+	s.bytes += uint64(packet.MarshalSize())
+
+	if first {
+		s.firstArrival = now
+	} else {
+		s.maxGap = max(s.maxGap, now.Sub(s.lastArrival))
+	}
+
+	s.sequence.Update(packet.SequenceNumber)
+
+	s.lastArrival = now
+
+	if s.clockRate == 0 {
+		return
+	}
+
+	if !first {
+		delta := int32(packet.Timestamp - s.lastTimestamp)
+		s.elapsed += float64(delta)
+
+		// Without a=framecount, the packet time is taken from the
+		// timestamps
+		if s.packetTime == 0 && delta > 0 {
+			s.packetTime = time.Duration(delta) * time.Second / time.Duration(s.clockRate)
+		}
+	}
+
+	arrival := now.Sub(s.firstArrival).Seconds() * float64(s.clockRate)
+	transit := arrival - s.elapsed
+
+	if !first && packet.Timestamp != s.lastTimestamp {
+		s.jitter += (math.Abs(transit-s.lastTransit) - s.jitter) / 16
+	}
+
+	s.lastTransit = transit
+	s.lastTimestamp = packet.Timestamp
+
+	seconds := transit / float64(s.clockRate)
+
+	if first || now.Sub(s.windowStart) >= delayFactorWindow {
+		if !first {
+			s.maxDelayFactor = max(s.maxDelayFactor, s.windowMax-s.windowMin)
+		}
+
+		s.windowStart = now
+		s.windowMin, s.windowMax = seconds, seconds
+	}
+
+	s.windowMin = min(s.windowMin, seconds)
+	s.windowMax = max(s.windowMax, seconds)
+}
+
+// delayFactor returns the largest delay factor, including the current
+// window
+func (s *source) delayFactor() float64 {
+	if s.packets == 0 {
+		return 0
+	}
+
+	return max(s.maxDelayFactor, s.windowMax-s.windowMin)
+}
+
+// report fills in the measurements of the source
+func (s *source) report(r *SourceReport, duration time.Duration) {
+	r.Packets = s.packets
+	r.Bytes = s.bytes
+
+	if sequence := s.sequence.Stats(); sequence.Expected > 0 {
+		r.Expected = sequence.Expected
+		r.Lost = sequence.Lost
+		r.LossPercent = max(float64(r.Lost), 0) / float64(r.Expected) * 100
+		r.Reordered = sequence.Reordered
+	}
+
+	if seconds := duration.Seconds(); seconds > 0 {
+		r.PacketRate = float64(s.packets) / seconds
+		r.Bitrate = float64(s.bytes*8) / seconds
+	}
+
+	if s.clockRate > 0 {
+		r.JitterSeconds = s.jitter / float64(s.clockRate)
+	}
+
+	r.MaxInterArrivalSeconds = s.maxGap.Seconds()
+	r.DelayFactorSeconds = s.delayFactor()
+	r.PacketTimeSeconds = s.packetTime.Seconds()
+	r.Pacing = pacing(r.DelayFactorSeconds, s.packetTime, s.packets)
+}
+
+// pacing returns the verdict for a delay factor
+func pacing(delayFactor float64, packetTime time.Duration, packets uint64) string {
+	switch {
+	case packetTime == 0 || packets < 2:
+		return PacingUnknown
+	case delayFactor <= packetTime.Seconds():
+		return PacingGood
+	case delayFactor <= 3*packetTime.Seconds():
+		return PacingAcceptable
+	default:
+		return PacingPoor
+	}
+}
+
+// Options configures an Analyzer
+type Options struct {
+	Stream     *stream.Stream
+	PTPMonitor *ptp.Monitor // may be nil
+}
+
+// Analyzer receives a stream and measures it until it is closed
+type Analyzer struct {
+	opts     Options
+	started  time.Time
+	receiver *stream.RTPReceiver
+	meter    *levels.Meter
+
+	mutex   sync.Mutex
+	sources []*source
+}
+
+// Start starts receiving the stream
+func Start(opts Options) (*Analyzer, error) {
+	s := opts.Stream
+
+	a := &Analyzer{
+		opts:    opts,
+		started: time.Now(),
+		sources: make([]*source, len(s.Description.Sources)),
+	}
+
+	for i, src := range s.Description.Sources {
+		a.sources[i] = &source{
+			clockRate:  s.Description.SampleRate,
+			packetTime: src.PacketTime,
+		}
+	}
+
+	receiver, err := s.NewRTPReceiver(func(sourceIndex int, _ net.Addr, packet *rtp.Packet, arrival time.Time) {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		if sourceIndex < len(a.sources) {
+			a.sources[sourceIndex].add(arrival, packet)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.receiver = receiver
+
+	if levels.IsAudio(s) {
+		if a.meter, err = levels.NewMeter(s); err != nil {
+			receiver.Close()
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// Close stops receiving the stream
+func (a *Analyzer) Close() {
+	a.receiver.Close()
+
+	if a.meter != nil {
+		a.meter.Close()
+	}
+}
+
+// Report returns the analysis since the start. Levels are measured since
+// the previous report.
+func (a *Analyzer) Report() Report {
+	s := a.opts.Stream
+	duration := time.Since(a.started)
+
+	report := Report{
+		Stream:   inventory.FromStreams([]*stream.Stream{s})[0],
+		Started:  a.started,
+		Duration: duration.Seconds(),
+		Sources:  make([]SourceReport, len(a.sources)),
+	}
+
+	var channelLevels [][]levels.ChannelLevel
+	if a.meter != nil {
+		channelLevels = a.meter.Levels()
+	}
+
+	grandmasters := make(map[uint8]string)
+	if a.opts.PTPMonitor != nil {
+		a.opts.PTPMonitor.ForEachGrandmaster(func(domain uint8, id ptp.ClockIdentity) {
+			grandmasters[domain] = id.String()
+		})
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for i, src := range a.sources {
+		r := &report.Sources[i]
+		desc := s.Description.Sources[i]
+
+		r.Sender = report.Stream.Sources[i].Sender
+		r.Destination = net.JoinHostPort(report.Stream.Sources[i].Destination, strconv.Itoa(int(desc.DestinationPort)))
+		r.SequenceErrors = a.receiver.SequenceErrors(i)
+		r.RTPErrors = a.receiver.RTPErrors(i)
+		r.ReferenceClock = desc.ReferenceClock
+
+		if err := a.receiver.SourceError(i); err != nil {
+			r.Error = err.Error()
+		}
+
+		src.report(r, duration)
+
+		r.Grandmaster, r.PTP = ptpStatus(desc.ReferenceClock, grandmasters, a.opts.PTPMonitor != nil)
+
+		if i < len(channelLevels) {
+			r.Levels = channelLevels[i]
+		}
+	}
+
+	return report
+}
+
+// ptpStatus compares the PTP reference clock of a source, e.g.
+// "ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:0", with the grandmaster seen
+// in its domain
+func ptpStatus(refClock string, grandmasters map[uint8]string, monitored bool) (string, string) {
+	clock, ok := stream.ParsePTPClock(refClock)
+	if !ok {
+		return "", PTPNoReference
+	}
+
+	if !monitored {
+		return "", PTPNotMonitored
+	}
+
+	grandmaster, ok := grandmasters[clock.Domain]
+	if !ok {
+		return "", PTPNoGrandmaster
+	}
+
+	// Sources referencing a traceable clock accept any grandmaster
+	if clock.Grandmaster != "" && normalizeClockID(clock.Grandmaster) != normalizeClockID(grandmaster) {
+		return grandmaster, PTPMismatch
+	}
+
+	return grandmaster, PTPLocked
+}
+
+// normalizeClockID removes the separators of a clock identity and lowers its
+// case
+func normalizeClockID(s string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", ":", "").Replace(s))
+}
@@ -0,0 +1,139 @@
+package analyze
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+var testStart = time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)
+
+// feed adds packets of 48 frames at 48 kHz, sent every millisecond, with
+// their arrival delayed by delay(i) and the packets for which skip(i) is true
+// left out
+func feed(s *source, n int, firstSequence uint16, delay func(int) time.Duration, skip func(int) bool) {
+	for i := range n {
+		if skip != nil && skip(i) {
+			continue
+		}
+
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				SequenceNumber: firstSequence + uint16(i),
+				Timestamp:      uint32(i * 48),
+			},
+			Payload: make([]byte, 288),
+		}
+
+		s.add(testStart.Add(time.Duration(i)*time.Millisecond+delay(i)), packet)
+	}
+}
+
+func noDelay(int) time.Duration {
+	return 0
+}
+
+func TestSourceGoodPacing(t *testing.T) {
+	s := &source{clockRate: 48000}
+
+	feed(s, 3000, 65000, noDelay, func(i int) bool { return i == 1000 || i == 1001 })
+
+	var r SourceReport
+	s.report(&r, 3*time.Second)
+
+	if r.Packets != 2998 || r.Expected != 3000 || r.Lost != 2 {
+		t.Errorf("got %d packets, %d expected, %d lost; want 2998, 3000, 2", r.Packets, r.Expected, r.Lost)
+	}
+
+	if r.PacketTimeSeconds != 0.001 {
+		t.Errorf("packet time = %v, want 1 ms", r.PacketTimeSeconds)
+	}
+
+	if r.DelayFactorSeconds > 1e-6 || r.JitterSeconds > 1e-6 {
+		t.Errorf("delay factor %v and jitter %v, want 0", r.DelayFactorSeconds, r.JitterSeconds)
+	}
+
+	if r.Pacing != PacingGood {
+		t.Errorf("pacing = %s, want %s", r.Pacing, PacingGood)
+	}
+
+	if r.MaxInterArrivalSeconds < 0.0029 || r.MaxInterArrivalSeconds > 0.0031 {
+		t.Errorf("max inter-arrival = %v, want 3 ms", r.MaxInterArrivalSeconds)
+	}
+}
+
+func TestSourcePacing(t *testing.T) {
+	for delay, want := range map[time.Duration]string{
+		2 * time.Millisecond: PacingAcceptable,
+		5 * time.Millisecond: PacingPoor,
+	} {
+		s := &source{clockRate: 48000}
+
+		// Every 100th packet is late
+		feed(s, 3000, 0, func(i int) time.Duration {
+			if i%100 == 50 {
+				return delay
+			}
+
+			return 0
+		}, nil)
+
+		var r SourceReport
+		s.report(&r, 3*time.Second)
+
+		if r.Pacing != want {
+			t.Errorf("pacing with %s late packets = %s (delay factor %v), want %s", delay, r.Pacing, r.DelayFactorSeconds, want)
+		}
+	}
+}
+
+func TestPTPStatus(t *testing.T) {
+	grandmasters := map[uint8]string{0: "39:a7:94:ff:fe:07:cb:d0", 1: "00:1d:c1:ff:fe:12:34:56"}
+
+	for _, tc := range []struct {
+		refClock  string
+		monitored bool
+		want      string
+	}{
+		{"ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:0", true, PTPLocked},
+		{"ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:1", true, PTPMismatch},
+		{"ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:5", true, PTPNoGrandmaster},
+		{"ptp=IEEE1588-2008:traceable", true, PTPLocked},
+		{"ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:0", false, PTPNotMonitored},
+		{"localmac=00-1D-C1-12-34-56", true, PTPNoReference},
+		{"", true, PTPNoReference},
+	} {
+		if _, got := ptpStatus(tc.refClock, grandmasters, tc.monitored); got != tc.want {
+			t.Errorf("ptpStatus(%q) = %s, want %s", tc.refClock, got, tc.want)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	s := &source{clockRate: 48000}
+	feed(s, 1000, 0, noDelay, nil)
+
+	report := Report{Started: testStart, Duration: 1, Sources: make([]SourceReport, 1)}
+	report.Stream.Name = "Stage Left"
+	report.Sources[0].PTP = PTPNoReference
+	s.report(&report.Sources[0], time.Second)
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, "text"); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	for _, want := range []string{"Stream:", "Stage Left", "Loss:", "0 of 1000", "Pacing:", "good"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	if err := report.Write(&buf, "xml"); err == nil {
+		t.Error("Write() succeeded for an unknown format")
+	}
+}
@@ -0,0 +1,86 @@
+package analyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{"text", "json"}
+
+// Write writes the report in the given format
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// milliseconds formats seconds as milliseconds
+func milliseconds(seconds float64) string {
+	return fmt.Sprintf("%.3f ms", seconds*1000)
+}
+
+// WriteText writes the report as plain text
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	line := func(label, format string, args ...any) {
+		fmt.Fprintf(tw, "%s\t"+format+"\n", append([]any{label}, args...)...)
+	}
+
+	e := r.Stream
+
+	fmt.Fprintf(tw, "Stream analysis report\n\n")
+
+	line("Stream:", "%s (%s)", e.Name, e.IDHash)
+	line("Format:", "%s %d Hz %d ch", e.ContentType, e.SampleRate, e.Channels)
+	line("Discovery:", "%s", e.Discovery)
+	line("Started:", "%s", r.Started.Format(time.RFC3339))
+	line("Duration:", "%s", time.Duration(r.Duration*float64(time.Second)).Round(time.Millisecond))
+
+	for i, s := range r.Sources {
+		fmt.Fprintf(tw, "\nSource %d: %s from %s\n", i+1, s.Destination, s.Sender)
+
+		if s.Error != "" {
+			line("  Error:", "%s", s.Error)
+		}
+
+		line("  Packets:", "%d (%.1f/s, %.2f Mbit/s)", s.Packets, s.PacketRate, s.Bitrate/1e6)
+		line("  Loss:", "%d of %d (%.3f%%)", max(s.Lost, 0), s.Expected, s.LossPercent)
+		line("  Reordered:", "%d", s.Reordered)
+		line("  Sequence errors:", "%d", s.SequenceErrors)
+		line("  RTP errors:", "%d", s.RTPErrors)
+		line("  Jitter:", "%s", milliseconds(s.JitterSeconds))
+		line("  Max inter-arrival:", "%s", milliseconds(s.MaxInterArrivalSeconds))
+		line("  Delay factor:", "%s (packet time %s)", milliseconds(s.DelayFactorSeconds), milliseconds(s.PacketTimeSeconds))
+		line("  Pacing:", "%s", s.Pacing)
+
+		ptp := s.PTP
+		if s.Grandmaster != "" {
+			ptp += ", grandmaster " + s.Grandmaster
+		}
+
+		if s.ReferenceClock != "" {
+			ptp += " (" + s.ReferenceClock + ")"
+		}
+
+		line("  PTP:", "%s", ptp)
+
+		for ch, l := range s.Levels {
+			line(fmt.Sprintf("  Channel %d:", ch+1), "%.1f dBFS RMS, %.1f dBFS peak", l.RMS, l.Peak)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,242 @@
+// Package ansihtml converts text with ANSI color sequences, as rendered by
+// lipgloss, to HTML.
+package ansihtml
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sgr matches Select Graphic Rendition sequences
+var sgr = regexp.MustCompile(`\x1b\[([0-9;:]*)m`)
+
+// basicColors holds the 16 standard terminal colors, in the xterm defaults
+var basicColors = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// style is the text style in effect at some point of the input
+type style struct {
+	fg, bg    string
+	bold      bool
+	italic    bool
+	underline bool
+	reverse   bool
+}
+
+// css returns the inline CSS of the style, or an empty string for the
+// default style
+func (s style) css() string {
+	fg, bg := s.fg, s.bg
+	if s.reverse {
+		fg, bg = bg, fg
+	}
+
+	var props []string
+
+	if fg != "" {
+		props = append(props, "color:"+fg)
+	}
+
+	if bg != "" {
+		props = append(props, "background-color:"+bg)
+	}
+
+	if s.bold {
+		props = append(props, "font-weight:bold")
+	}
+
+	if s.italic {
+		props = append(props, "font-style:italic")
+	}
+
+	if s.underline {
+		props = append(props, "text-decoration:underline")
+	}
+
+	return strings.Join(props, ";")
+}
+
+// Convert converts text with ANSI sequences to an HTML fragment. Colors and
+// text attributes become inline styles of <span> elements; all other escape
+// sequences are dropped.
+func Convert(s string) string {
+	var b strings.Builder
+	var current style
+
+	open := false
+
+	write := func(text string) {
+		if text == "" {
+			return
+		}
+
+		b.WriteString(html.EscapeString(ansi.Strip(text)))
+	}
+
+	last := 0
+
+	for _, m := range sgr.FindAllStringSubmatchIndex(s, -1) {
+		write(s[last:m[0]])
+		last = m[1]
+
+		next := apply(current, s[m[2]:m[3]])
+		if next == current {
+			continue
+		}
+
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+
+		current = next
+
+		if css := current.css(); css != "" {
+			fmt.Fprintf(&b, `<span style="%s">`, css)
+			open = true
+		}
+	}
+
+	write(s[last:])
+
+	if open {
+		b.WriteString("</span>")
+	}
+
+	return b.String()
+}
+
+// Document wraps the converted text in a standalone HTML page with the given
+// title and page colors
+func Document(s, title, foreground, background string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body style="margin:0;background-color:%s">
+<pre style="color:%s;background-color:%s;font-family:monospace;line-height:1.2;padding:1em">%s</pre>
+</body>
+</html>
+`, html.EscapeString(title), background, foreground, background, Convert(s))
+}
+
+// apply returns the style after applying the parameters of an SGR sequence
+func apply(s style, params string) style {
+	codes := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(codes) == 0 {
+		return style{}
+	}
+
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			s = style{}
+		case code == 1:
+			s.bold = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.reverse = true
+		case code == 22:
+			s.bold = false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.reverse = false
+		case code >= 30 && code <= 37:
+			s.fg = basicColors[code-30]
+		case code >= 90 && code <= 97:
+			s.fg = basicColors[code-90+8]
+		case code >= 40 && code <= 47:
+			s.bg = basicColors[code-40]
+		case code >= 100 && code <= 107:
+			s.bg = basicColors[code-100+8]
+		case code == 39:
+			s.fg = ""
+		case code == 49:
+			s.bg = ""
+		case code == 38 || code == 48:
+			color, n := extendedColor(codes[i+1:])
+			i += n
+
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+
+	return s
+}
+
+// extendedColor parses the parameters following 38 or 48, either 5;n for the
+// 256 color palette or 2;r;g;b for true color. It returns the color and the
+// number of parameters used.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+
+	n := make([]int, 0, 4)
+	for _, c := range codes {
+		v, err := strconv.Atoi(c)
+		if err != nil {
+			break
+		}
+
+		n = append(n, v)
+	}
+
+	switch {
+	case len(n) >= 2 && n[0] == 5:
+		return palette256(n[1]), 2
+	case len(n) >= 4 && n[0] == 2:
+		return fmt.Sprintf("#%02x%02x%02x", n[1]&0xff, n[2]&0xff, n[3]&0xff), 4
+	default:
+		return "", len(n)
+	}
+}
+
+// palette256 returns a color of the xterm 256 color palette
+func palette256(i int) string {
+	switch {
+	case i < 0 || i > 255:
+		return ""
+	case i < 16:
+		return basicColors[i]
+	case i < 232:
+		i -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+
+			return 55 + v*40
+		}
+
+		return fmt.Sprintf("#%02x%02x%02x", level(i/36), level(i/6%6), level(i%6))
+	default:
+		v := 8 + (i-232)*10
+
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
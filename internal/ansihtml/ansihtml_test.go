@@ -0,0 +1,63 @@
+package ansihtml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain text is escaped",
+			in:   "a < b & c",
+			want: "a &lt; b &amp; c",
+		},
+		{
+			name: "true color with reset",
+			in:   "\x1b[38;2;166;226;46mok\x1b[0m done",
+			want: `<span style="color:#a6e22e">ok</span> done`,
+		},
+		{
+			name: "bold on background",
+			in:   "\x1b[1;48;2;39;40;34mtitle\x1b[m",
+			want: `<span style="background-color:#272822;font-weight:bold">title</span>`,
+		},
+		{
+			name: "256 and basic colors",
+			in:   "\x1b[38;5;196mred\x1b[32mgreen\x1b[39m",
+			want: `<span style="color:#ff0000">red</span><span style="color:#00cd00">green</span>`,
+		},
+		{
+			name: "reverse swaps colors",
+			in:   "\x1b[31;7mx\x1b[0m",
+			want: `<span style="background-color:#cd0000">x</span>`,
+		},
+		{
+			name: "other sequences are dropped",
+			in:   "\x1b[2Ka\x1b]0;title\x07b",
+			want: "ab",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Convert(tt.in); got != tt.want {
+				t.Errorf("Convert(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument(t *testing.T) {
+	got := Document("\x1b[1mhi\x1b[0m", "<screen>", "#ffffff", "#000000")
+
+	for _, want := range []string{"<title>&lt;screen&gt;</title>", `<span style="font-weight:bold">hi</span>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Document() does not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+// Package api implements an HTTP and gRPC API to query streams and their
+// statistics and to control recordings, and serves a web UI built on top of
+// it.
+package api
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"google.golang.org/grpc"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// webFS holds the files of the web UI
+var webFS, _ = fs.Sub(webFiles, "web")
+
+// shutdownTimeout is how long Close waits for requests in flight
+const shutdownTimeout = 2 * time.Second
+
+// Options holds the dependencies of the API server
+type Options struct {
+	Manager   *stream.Manager
+	Collector *stats.Collector
+	Watcher   *events.Watcher
+
+	// WavFolder is where recordings are written to
+	WavFolder string
+
+	// AllowedOrigins are the origins of web pages, e.g.
+	// "http://dashboard:3000", that may open WebSockets besides the web UI
+	// served by the API itself
+	AllowedOrigins []string
+}
+
+// Server serves the HTTP API. Streams are addressed by their ID hash.
+type Server struct {
+	manager   *stream.Manager
+	collector *stats.Collector
+	watcher   *events.Watcher
+	wavFolder string
+
+	mux      *http.ServeMux
+	grpc     *grpc.Server
+	upgrader websocket.Upgrader
+
+	// allowedOrigins holds Options.AllowedOrigins
+	allowedOrigins map[string]bool
+
+	mutex      sync.Mutex
+	recordings map[string]*recorder.Recorder
+
+	// server is the HTTP server started by Serve, nil if not serving
+	server *http.Server
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a new API server
+func New(opts Options) *Server {
+	s := &Server{
+		manager:    opts.Manager,
+		collector:  opts.Collector,
+		watcher:    opts.Watcher,
+		wavFolder:  opts.WavFolder,
+		mux:        http.NewServeMux(),
+		recordings: make(map[string]*recorder.Recorder),
+		done:       make(chan struct{}),
+
+		allowedOrigins: make(map[string]bool),
+	}
+
+	for _, origin := range opts.AllowedOrigins {
+		s.allowedOrigins[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	s.upgrader.CheckOrigin = s.checkOrigin
+
+	s.mux.HandleFunc("GET /api/streams", s.handleStreams)
+	s.mux.HandleFunc("GET /api/streams/{hash}", s.withStream(s.handleStream))
+	s.mux.HandleFunc("GET /api/streams/{hash}/sdp", s.withStream(s.handleSDP))
+	s.mux.HandleFunc("GET /api/streams/{hash}/stats", s.withStream(s.handleStats))
+	s.mux.HandleFunc("POST /api/streams/{hash}/stats", s.mutating(s.withStream(s.handleStartStats)))
+	s.mux.HandleFunc("DELETE /api/streams/{hash}/stats", s.mutating(s.withStream(s.handleStopStats)))
+	s.mux.HandleFunc("GET /api/streams/{hash}/recording", s.withStream(s.handleRecording))
+	s.mux.HandleFunc("POST /api/streams/{hash}/recording", s.mutating(s.withStream(s.handleStartRecording)))
+	s.mux.HandleFunc("DELETE /api/streams/{hash}/recording", s.mutating(s.withStream(s.handleStopRecording)))
+	s.mux.HandleFunc("GET /api/recordings", s.handleRecordings)
+	s.mux.HandleFunc("GET /api/streams/{hash}/levels", s.withStream(s.handleLevels))
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+	s.mux.Handle("GET /", http.FileServerFS(webFS))
+
+	s.grpc = newGRPCServer(s)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isGRPC(r) {
+		s.grpc.ServeHTTP(w, r)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve listens on the given address and serves the API in the background.
+// Listening errors are returned, later errors are reported to errorFn.
+func (s *Server) Serve(addr string, errorFn func(error)) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	// HTTP/2 without TLS is needed for gRPC
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	server := &http.Server{
+		Handler:   s,
+		Protocols: &protocols,
+	}
+
+	s.mutex.Lock()
+	s.server = server
+	s.mutex.Unlock()
+
+	go func() {
+		if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errorFn(err)
+		}
+	}()
+
+	return nil
+}
+
+// Close stops serving, disconnects WebSocket clients and stops all
+// recordings started through the API. Requests in flight are given
+// shutdownTimeout to complete.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.grpc.Stop()
+	})
+
+	s.mutex.Lock()
+	server := s.server
+	s.server = nil
+	recordings := s.recordings
+	s.recordings = make(map[string]*recorder.Recorder)
+	s.mutex.Unlock()
+
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			_ = server.Close()
+		}
+	}
+
+	for _, r := range recordings {
+		r.Stop()
+	}
+}
+
+// writeJSON writes v as JSON with the given status code
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeError writes an error message as JSON
+func writeError(w http.ResponseWriter, code int, format string, args ...any) {
+	writeJSON(w, code, map[string]string{
+		"error": fmt.Sprintf(format, args...),
+	})
+}
+
+// mutating guards a handler that changes state against cross-site requests.
+// The API has no authentication, so any web page could otherwise make the
+// browser of a visitor post a form to it. Only requests a form can't send
+// are accepted: with a JSON content type or an X-Requested-With header,
+// which browsers don't send cross-origin without a CORS preflight that the
+// API does not answer.
+func (s *Server) mutating(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		if contentType != "application/json" && r.Header.Get("X-Requested-With") == "" {
+			writeError(w, http.StatusForbidden, "requests that change state need an X-Requested-With header or a JSON content type")
+			return
+		}
+
+		fn(w, r)
+	}
+}
+
+// checkOrigin accepts WebSockets from clients that are no browsers, from
+// the web UI and from Options.AllowedOrigins
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || s.allowedOrigins[origin] {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// withStream looks up the stream addressed by the request before calling fn
+func (s *Server) withStream(fn func(http.ResponseWriter, *http.Request, *stream.Stream)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hash := r.PathValue("hash")
+
+		st, ok := s.streamByHash(hash)
+		if !ok {
+			writeError(w, http.StatusNotFound, "stream %s not found", hash)
+			return
+		}
+
+		fn(w, r, st)
+	}
+}
+
+// streamByHash looks up a stream by its ID hash
+func (s *Server) streamByHash(hash string) (*stream.Stream, bool) {
+	for _, st := range s.manager.GetAllStreams() {
+		if st.IDHash() == hash {
+			return st, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *Server) handleStreams(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, inventory.FromStreams(s.manager.GetAllStreams()))
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	writeJSON(w, http.StatusOK, inventory.FromStreams([]*stream.Stream{st})[0])
+}
+
+func (s *Server) handleSDP(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	w.Header().Set("Content-Type", "application/sdp")
+	_, _ = w.Write(st.SDP)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	snapshot, ok := s.collector.Snapshot(st.ID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no statistics are collected for stream %s, POST to start", st.IDHash())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events.NewStats(snapshot))
+}
+
+func (s *Server) handleStartStats(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	if err := s.collector.Start(st); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to collect statistics: %v", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleStopStats(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	s.collector.Stop(st.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pause is the JSON representation of a pause in a recording
+type pause struct {
+	PositionSeconds float64    `json:"position-s"`
+	Start           time.Time  `json:"start"`
+	End             *time.Time `json:"end,omitempty"`
+}
+
+// recordedFile is the JSON representation of a recorded file
+type recordedFile struct {
+	Name            string  `json:"name"`
+	Bytes           uint64  `json:"bytes"`
+	RecordedSeconds float64 `json:"recorded-s"`
+	Error           string  `json:"error,omitempty"`
+
+	// Summary is the integrity report, set once the recording stopped
+	Summary *recorder.Summary `json:"summary,omitempty"`
+}
+
+// recording is the JSON representation of a recording
+type recording struct {
+	IDHash  string         `json:"id-hash"`
+	Name    string         `json:"name"`
+	Started time.Time      `json:"started"`
+	Stopped bool           `json:"stopped"`
+	Paused  bool           `json:"paused"`
+	Pauses  []pause        `json:"pauses"`
+	Files   []recordedFile `json:"files"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// recordingStatus returns the JSON representation of a recording
+func recordingStatus(r *recorder.Recorder) recording {
+	status := r.Status()
+
+	result := recording{
+		IDHash:  r.Stream().IDHash(),
+		Name:    r.Stream().Name(),
+		Started: status.StartTime,
+		Stopped: status.Stopped,
+		Paused:  status.Paused,
+		Pauses:  make([]pause, 0, len(status.Pauses)),
+		Files:   make([]recordedFile, 0, len(status.Files)),
+	}
+
+	if status.Err != nil {
+		result.Error = status.Err.Error()
+	}
+
+	for _, p := range status.Pauses {
+		jp := pause{
+			PositionSeconds: p.Position.Seconds(),
+			Start:           p.Start,
+		}
+
+		if !p.End.IsZero() {
+			jp.End = &p.End
+		}
+
+		result.Pauses = append(result.Pauses, jp)
+	}
+
+	for _, f := range status.Files {
+		jf := recordedFile{
+			Name:            f.Name,
+			Bytes:           f.Bytes,
+			RecordedSeconds: f.Recorded.Seconds(),
+			Summary:         f.Summary,
+		}
+
+		if f.Err != nil {
+			jf.Error = f.Err.Error()
+		}
+
+		result.Files = append(result.Files, jf)
+	}
+
+	return result
+}
+
+func (s *Server) handleRecordings(w http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+
+	result := make([]recording, 0, len(s.recordings))
+	for _, r := range s.recordings {
+		result = append(result, recordingStatus(r))
+	}
+
+	s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleRecording(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	s.mutex.Lock()
+	r, ok := s.recordings[st.ID]
+	s.mutex.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "stream %s is not recorded", st.IDHash())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recordingStatus(r))
+}
+
+// errAlreadyRecorded is returned when starting a recording twice
+var errAlreadyRecorded = errors.New("stream is already recorded")
+
+// startRecording starts recording a stream. Existing files are only
+// overwritten if overwrite is set, recorder.ErrFilesExist is returned
+// otherwise.
+func (s *Server) startRecording(st *stream.Stream, overwrite bool) (*recorder.Recorder, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.recordings[st.ID]; ok {
+		return nil, errAlreadyRecorded
+	}
+
+	r := recorder.New(st, s.wavFolder)
+
+	if err := r.Start(overwrite); err != nil {
+		if !errors.Is(err, recorder.ErrFilesExist) {
+			r.Stop()
+		}
+
+		return nil, err
+	}
+
+	s.recordings[st.ID] = r
+
+	return r, nil
+}
+
+// stopRecording stops the recording of a stream, if any
+func (s *Server) stopRecording(st *stream.Stream) (*recorder.Recorder, bool) {
+	s.mutex.Lock()
+	r, ok := s.recordings[st.ID]
+	delete(s.recordings, st.ID)
+	s.mutex.Unlock()
+
+	if ok {
+		r.Stop()
+	}
+
+	return r, ok
+}
+
+// handleStartRecording starts recording a stream. Existing files are only
+// overwritten with ?overwrite=true; ?paused=true and ?paused=false pause and
+// resume a running recording instead.
+func (s *Server) handleStartRecording(w http.ResponseWriter, req *http.Request, st *stream.Stream) {
+	s.mutex.Lock()
+	r, ok := s.recordings[st.ID]
+	s.mutex.Unlock()
+
+	if ok {
+		switch req.URL.Query().Get("paused") {
+		case "true":
+			r.SetPaused(true)
+		case "false":
+			r.SetPaused(false)
+		default:
+			writeError(w, http.StatusConflict, "stream %s is already recorded", st.IDHash())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, recordingStatus(r))
+
+		return
+	}
+
+	r, err := s.startRecording(st, req.URL.Query().Get("overwrite") == "true")
+	switch {
+	case errors.Is(err, errAlreadyRecorded):
+		writeError(w, http.StatusConflict, "stream %s is already recorded", st.IDHash())
+		return
+	case errors.Is(err, recorder.ErrFilesExist):
+		writeError(w, http.StatusConflict, "files exist, use ?overwrite=true to replace them")
+		return
+	case errors.Is(err, stream.ErrUnsupportedContentType):
+		writeError(w, http.StatusBadRequest, "can't record stream %s: %v", st.IDHash(), err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to start recording: %v", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, recordingStatus(r))
+}
+
+func (s *Server) handleStopRecording(w http.ResponseWriter, _ *http.Request, st *stream.Stream) {
+	r, ok := s.stopRecording(st)
+	if !ok {
+		writeError(w, http.StatusNotFound, "stream %s is not recorded", st.IDHash())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recordingStatus(r))
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+func newTestServer(t *testing.T) (*Server, *stream.Stream) {
+	t.Helper()
+
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "test.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	collector := stats.NewCollector()
+
+	watcher := events.NewWatcher(events.Options{
+		Manager:   manager,
+		Collector: collector,
+	})
+	t.Cleanup(watcher.Close)
+
+	server := New(Options{
+		Manager:   manager,
+		Collector: collector,
+		Watcher:   watcher,
+		WavFolder: t.TempDir(),
+	})
+	t.Cleanup(server.Close)
+
+	return server, s
+}
+
+func get(t *testing.T, server *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return w
+}
+
+func TestServeClose(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	// Pick a free port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := l.Addr().String()
+	l.Close()
+
+	errs := make(chan error, 1)
+
+	if err := server.Serve(addr, func(err error) { errs <- err }); err != nil {
+		t.Fatalf("Serve() failed: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/api/streams")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+
+	server.Close()
+
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("still listening after Close()")
+	}
+
+	select {
+	case err := <-errs:
+		t.Errorf("Close() reported error %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStreams(t *testing.T) {
+	server, s := newTestServer(t)
+
+	w := get(t, server, "/api/streams")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	var entries []inventory.Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].IDHash != s.IDHash() || entries[0].Name != "Stage Left" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+
+	if w := get(t, server, "/api/streams/"+s.IDHash()); w.Code != http.StatusOK {
+		t.Errorf("unexpected status %d for stream", w.Code)
+	}
+
+	if w := get(t, server, "/api/streams/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status %d for unknown stream", w.Code)
+	}
+}
+
+func TestSDP(t *testing.T) {
+	server, s := newTestServer(t)
+
+	w := get(t, server, "/api/streams/"+s.IDHash()+"/sdp")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/sdp" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	if !strings.Contains(w.Body.String(), "s=Stage Left") {
+		t.Errorf("unexpected SDP %q", w.Body.String())
+	}
+}
+
+func TestStatsNotCollected(t *testing.T) {
+	server, s := newTestServer(t)
+
+	w := get(t, server, "/api/streams/"+s.IDHash()+"/stats")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] == "" {
+		t.Errorf("expected JSON error, got %q", w.Body.String())
+	}
+}
+
+func TestRecordingNotRunning(t *testing.T) {
+	server, s := newTestServer(t)
+
+	if w := get(t, server, "/api/streams/"+s.IDHash()+"/recording"); w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/streams/"+s.IDHash()+"/recording", nil)
+	r.Header.Set("X-Requested-With", "test")
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status %d for DELETE", w.Code)
+	}
+
+	if w := get(t, server, "/api/recordings"); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("unexpected recordings %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestCrossSiteRequests(t *testing.T) {
+	server, s := newTestServer(t)
+
+	path := "/api/streams/" + s.IDHash() + "/recording?overwrite=true"
+
+	// What a form on another web page can post
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader("a=b"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unexpected status %d for a form post", w.Code)
+	}
+
+	if w := get(t, server, "/api/recordings"); strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("form post started a recording: %q", w.Body.String())
+	}
+
+	// Requests of scripts on the same origin or of other clients
+	r = httptest.NewRequest(http.MethodPost, "/api/streams/"+s.IDHash()+"/stats", nil)
+	r.Header.Set("Content-Type", "application/json")
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	if w.Code == http.StatusForbidden {
+		t.Errorf("JSON request was refused: %q", w.Body.String())
+	}
+}
+
+func TestWebSocketOrigins(t *testing.T) {
+	server, _ := newTestServer(t)
+	server.allowedOrigins["http://dashboard:3000"] = true
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/events"
+
+	for origin, want := range map[string]bool{
+		"":                      true,
+		ts.URL:                  true,
+		"http://dashboard:3000": true,
+		"http://evil.example":   false,
+	} {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if err == nil {
+			conn.Close()
+		}
+
+		if (err == nil) != want {
+			t.Errorf("origin %q: got error %v, want accepted %v", origin, err, want)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/events?types=" + events.TypeStreamAdded + "," + events.TypeStreamRemoved
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer conn.Close()
+
+	s, err := server.manager.AddStreamFromSDP([]byte(strings.NewReplacer("o=- 1", "o=- 2", "s=Stage Left", "s=Stage Right").Replace(testSDP)),
+		stream.DiscoveryMethodManual, "right.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	readEvent := func(want string) {
+		t.Helper()
+
+		var event struct {
+			Type   string          `json:"type"`
+			IDHash string          `json:"id-hash"`
+			Data   inventory.Entry `json:"data"`
+		}
+
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("ReadJSON() failed: %v", err)
+		}
+
+		if event.Type != want || event.IDHash != s.IDHash() || event.Data.Name != "Stage Right" {
+			t.Errorf("unexpected event %+v, want %s", event, want)
+		}
+	}
+
+	// Changes within the update delay of the manager are coalesced, so the
+	// stream is removed after it was announced
+	readEvent(events.TypeStreamAdded)
+
+	server.manager.RemoveStream(s.ID)
+
+	readEvent(events.TypeStreamRemoved)
+}
+
+func TestWebUI(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	for path, want := range map[string]string{
+		"/":          "<title>RTP Stream Monitor</title>",
+		"/app.js":    "/api/events",
+		"/style.css": "--background",
+	} {
+		w := get(t, server, path)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("unexpected response for %s: %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// eventWriteTimeout is how long writing a message to a WebSocket client may take
+const eventWriteTimeout = 10 * time.Second
+
+// readUntilClosed reads from a WebSocket connection to process control
+// messages. The returned channel is closed when the client goes away.
+func readUntilClosed(conn *websocket.Conn) <-chan struct{} {
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	return closed
+}
+
+// handleEvents streams events to a WebSocket client. ?types= limits the
+// events to a comma separated list of types.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	var types map[string]bool
+
+	if t := r.URL.Query().Get("types"); t != "" {
+		types = make(map[string]bool)
+		for _, name := range strings.Split(t, ",") {
+			types[strings.TrimSpace(name)] = true
+		}
+	}
+
+	// Subscribe first so no events are missed once the client is connected
+	ch := s.watcher.Subscribe()
+	defer s.watcher.Unsubscribe(ch)
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		return
+	}
+	defer conn.Close()
+
+	closed := readUntilClosed(conn)
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			return
+		case event := <-ch:
+			if types != nil && !types[event.Type] {
+				continue
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	rtpmonitorv1 "github.com/holoplot/rtp-monitor/proto/rtpmonitor/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The gRPC service defined in proto/rtpmonitor/v1/rtpmonitor.proto is served
+// on the same address as the HTTP API, over HTTP/2 without TLS.
+
+// grpcService implements the RTPMonitor service on top of the server
+type grpcService struct {
+	rtpmonitorv1.UnimplementedRTPMonitorServer
+
+	s *Server
+}
+
+// newGRPCServer creates the gRPC server of the RTPMonitor service
+func newGRPCServer(s *Server) *grpc.Server {
+	server := grpc.NewServer()
+	rtpmonitorv1.RegisterRTPMonitorServer(server, &grpcService{s: s})
+
+	return server
+}
+
+// isGRPC reports whether a request is a gRPC call
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// streamByHash returns the stream with the given ID hash, or a NOT_FOUND
+// status
+func (g *grpcService) streamByHash(idHash string) (*stream.Stream, error) {
+	st, ok := g.s.streamByHash(idHash)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "stream %s not found", idHash)
+	}
+
+	return st, nil
+}
+
+func (g *grpcService) ListStreams(context.Context, *rtpmonitorv1.ListStreamsRequest) (*rtpmonitorv1.ListStreamsResponse, error) {
+	var response rtpmonitorv1.ListStreamsResponse
+
+	for _, e := range inventory.FromStreams(g.s.manager.GetAllStreams()) {
+		response.Streams = append(response.Streams, pbStream(e))
+	}
+
+	return &response, nil
+}
+
+func (g *grpcService) WatchEvents(req *rtpmonitorv1.WatchEventsRequest, server grpc.ServerStreamingServer[rtpmonitorv1.Event]) error {
+	var types map[string]bool
+
+	for _, t := range req.GetTypes() {
+		if types == nil {
+			types = make(map[string]bool)
+		}
+
+		types[t] = true
+	}
+
+	ch := g.s.watcher.Subscribe()
+	defer g.s.watcher.Unsubscribe(ch)
+
+	// The headers tell clients that events are watched from now on
+	if err := server.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-server.Context().Done():
+			return nil
+		case <-g.s.done:
+			return nil
+		case event := <-ch:
+			if types != nil && !types[event.Type] {
+				continue
+			}
+
+			if err := server.Send(pbEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (g *grpcService) GetStats(_ context.Context, req *rtpmonitorv1.GetStatsRequest) (*rtpmonitorv1.Stats, error) {
+	st, err := g.streamByHash(req.GetIdHash())
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, ok := g.s.collector.Snapshot(st.ID)
+	if !ok && req.GetStart() {
+		if err := g.s.collector.Start(st); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to collect statistics: %v", err)
+		}
+
+		snapshot, ok = g.s.collector.Snapshot(st.ID)
+	}
+
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "no statistics are collected for stream %s, set start to start", st.IDHash())
+	}
+
+	return pbStats(events.NewStats(snapshot)), nil
+}
+
+func (g *grpcService) StartRecording(_ context.Context, req *rtpmonitorv1.StartRecordingRequest) (*rtpmonitorv1.Recording, error) {
+	st, err := g.streamByHash(req.GetIdHash())
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := g.s.startRecording(st, req.GetOverwrite())
+	switch {
+	case errors.Is(err, errAlreadyRecorded):
+		return nil, status.Errorf(codes.AlreadyExists, "stream %s is already recorded", st.IDHash())
+	case errors.Is(err, recorder.ErrFilesExist):
+		return nil, status.Errorf(codes.AlreadyExists, "files exist, set overwrite to replace them")
+	case errors.Is(err, stream.ErrUnsupportedContentType):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to start recording: %v", err)
+	}
+
+	return pbRecording(recordingStatus(r)), nil
+}
+
+func (g *grpcService) StopRecording(_ context.Context, req *rtpmonitorv1.StopRecordingRequest) (*rtpmonitorv1.Recording, error) {
+	st, err := g.streamByHash(req.GetIdHash())
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := g.s.stopRecording(st)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "stream %s is not recorded", st.IDHash())
+	}
+
+	return pbRecording(recordingStatus(r)), nil
+}
+
+// pbTimestamp converts an optional time, nil if it is not set
+func pbTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+// pbStream converts an inventory entry to a Stream message
+func pbStream(e inventory.Entry) *rtpmonitorv1.Stream {
+	m := &rtpmonitorv1.Stream{
+		Id:            e.ID,
+		IdHash:        e.IDHash,
+		Name:          e.Name,
+		Host:          e.Host,
+		ContentType:   e.ContentType,
+		SampleRate:    e.SampleRate,
+		Channels:      e.Channels,
+		Discovery:     e.Discovery,
+		LastAnnounced: pbTimestamp(e.LastAnnounced),
+		Sdp:           e.SDP,
+	}
+
+	for _, source := range e.Sources {
+		m.Sources = append(m.Sources, &rtpmonitorv1.Source{
+			Sender:          source.Sender,
+			Destination:     source.Destination,
+			Port:            uint32(source.Port),
+			Ttl:             uint32(source.TTL),
+			FramesPerPacket: source.FramesPerPacket,
+			ClockDomain:     source.ClockDomain,
+			ReferenceClock:  source.ReferenceClock,
+			MediaClock:      source.MediaClock,
+			SyncTime:        source.SyncTime,
+		})
+	}
+
+	return m
+}
+
+// pbStats converts statistics to a Stats message
+func pbStats(stats events.Stats) *rtpmonitorv1.Stats {
+	m := &rtpmonitorv1.Stats{Started: timestamppb.New(stats.Started)}
+
+	for _, source := range stats.Sources {
+		m.Sources = append(m.Sources, &rtpmonitorv1.SourceStats{
+			Packets:        source.Packets,
+			Bytes:          source.Bytes,
+			PacketRate:     source.PacketRate,
+			Bitrate:        source.Bitrate,
+			SequenceErrors: source.SequenceErrors,
+			RtpErrors:      source.RTPErrors,
+			JitterSeconds:  source.JitterSeconds,
+			LastPacket:     pbTimestamp(source.LastPacket),
+			Lost:           source.Lost,
+			Reordered:      source.Reordered,
+		})
+	}
+
+	return m
+}
+
+// pbEvent converts an event to an Event message. Events without a message
+// of their own, such as recording events, carry no data.
+func pbEvent(event events.Event) *rtpmonitorv1.Event {
+	m := &rtpmonitorv1.Event{
+		Type:   event.Type,
+		Time:   timestamppb.New(event.Time),
+		IdHash: event.IDHash,
+	}
+
+	switch data := event.Data.(type) {
+	case inventory.Entry:
+		m.Data = &rtpmonitorv1.Event_Stream{Stream: pbStream(data)}
+
+	case events.Alert:
+		m.Data = &rtpmonitorv1.Event_Alert{Alert: &rtpmonitorv1.Alert{
+			Level:   data.Level,
+			Message: data.Message,
+			Metric:  data.Metric,
+			Value:   data.Value,
+		}}
+
+	case events.GrandmasterChange:
+		m.Data = &rtpmonitorv1.Event_Grandmaster{Grandmaster: &rtpmonitorv1.GrandmasterChange{
+			Domain:      uint32(data.Domain),
+			Grandmaster: data.Grandmaster,
+			Previous:    data.Previous,
+		}}
+
+	case events.Stats:
+		m.Data = &rtpmonitorv1.Event_Stats{Stats: pbStats(data)}
+	}
+
+	return m
+}
+
+// pbRecording converts a recording to a Recording message
+func pbRecording(r recording) *rtpmonitorv1.Recording {
+	m := &rtpmonitorv1.Recording{
+		IdHash:  r.IDHash,
+		Name:    r.Name,
+		Started: timestamppb.New(r.Started),
+		Stopped: r.Stopped,
+		Paused:  r.Paused,
+		Error:   r.Error,
+	}
+
+	for _, p := range r.Pauses {
+		m.Pauses = append(m.Pauses, &rtpmonitorv1.Pause{
+			PositionSeconds: p.PositionSeconds,
+			Start:           timestamppb.New(p.Start),
+			End:             pbTimestamp(p.End),
+		})
+	}
+
+	for _, f := range r.Files {
+		m.Files = append(m.Files, &rtpmonitorv1.RecordedFile{
+			Name:            f.Name,
+			Bytes:           f.Bytes,
+			RecordedSeconds: f.RecordedSeconds,
+			Error:           f.Error,
+		})
+	}
+
+	return m
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	rtpmonitorv1 "github.com/holoplot/rtp-monitor/proto/rtpmonitor/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// newGRPCClient serves the API over HTTP/2 without TLS and returns a client
+// of the generated package connected to it
+func newGRPCClient(t *testing.T, server *Server) rtpmonitorv1.RTPMonitorClient {
+	t.Helper()
+
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	ts := httptest.NewUnstartedServer(server)
+	ts.Config.Protocols = &protocols
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(ts.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return rtpmonitorv1.NewRTPMonitorClient(conn)
+}
+
+func TestGRPCListStreams(t *testing.T) {
+	server, s := newTestServer(t)
+	client := newGRPCClient(t, server)
+
+	response, err := client.ListStreams(t.Context(), &rtpmonitorv1.ListStreamsRequest{})
+	if err != nil {
+		t.Fatalf("ListStreams() failed: %v", err)
+	}
+
+	if len(response.GetStreams()) != 1 {
+		t.Fatalf("expected one stream, got %d", len(response.GetStreams()))
+	}
+
+	got := response.GetStreams()[0]
+	if got.GetIdHash() != s.IDHash() || got.GetName() != "Stage Left" || len(got.GetSources()) != len(s.Description.Sources) {
+		t.Errorf("unexpected stream %v", got)
+	}
+}
+
+func TestGRPCWatchEvents(t *testing.T) {
+	server, _ := newTestServer(t)
+	client := newGRPCClient(t, server)
+
+	watch, err := client.WatchEvents(t.Context(), &rtpmonitorv1.WatchEventsRequest{Types: []string{events.TypeStreamAdded}})
+	if err != nil {
+		t.Fatalf("WatchEvents() failed: %v", err)
+	}
+
+	// Events are watched once the headers arrive
+	if _, err := watch.Header(); err != nil {
+		t.Fatalf("Header() failed: %v", err)
+	}
+
+	s, err := server.manager.AddStreamFromSDP([]byte(strings.NewReplacer("o=- 1", "o=- 2", "s=Stage Left", "s=Stage Right").Replace(testSDP)),
+		stream.DiscoveryMethodManual, "right.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	event, err := watch.Recv()
+	if err != nil {
+		t.Fatalf("Recv() failed: %v", err)
+	}
+
+	if event.GetType() != events.TypeStreamAdded || event.GetIdHash() != s.IDHash() || event.GetStream().GetName() != "Stage Right" {
+		t.Errorf("unexpected event %v", event)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	server, s := newTestServer(t)
+	client := newGRPCClient(t, server)
+
+	_, err := client.GetStats(t.Context(), &rtpmonitorv1.GetStatsRequest{IdHash: "0000000000"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND for an unknown stream, got %v", err)
+	}
+
+	_, err = client.GetStats(t.Context(), &rtpmonitorv1.GetStatsRequest{IdHash: s.IDHash()})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FAILED_PRECONDITION without statistics, got %v", err)
+	}
+
+	_, err = client.StopRecording(t.Context(), &rtpmonitorv1.StopRecordingRequest{IdHash: s.IDHash()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND without recording, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// levelInterval is how often audio levels are sent to a client
+const levelInterval = 100 * time.Millisecond
+
+// Levels is the message sent to clients of the levels endpoint, with the
+// channel levels of each source since the previous message
+type Levels struct {
+	Time    time.Time               `json:"time"`
+	Sources [][]levels.ChannelLevel `json:"sources"`
+}
+
+// handleLevels streams the audio levels of a stream to a WebSocket client
+func (s *Server) handleLevels(w http.ResponseWriter, r *http.Request, st *stream.Stream) {
+	if !levels.IsAudio(st) {
+		writeError(w, http.StatusBadRequest, "stream %s is not an audio stream", st.IDHash())
+		return
+	}
+
+	meter, err := levels.NewMeter(st)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to receive stream: %v", err)
+		return
+	}
+	defer meter.Close()
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	closed := readUntilClosed(conn)
+
+	ticker := time.NewTicker(levelInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			_ = conn.SetWriteDeadline(now.Add(eventWriteTimeout))
+
+			if err := conn.WriteJSON(Levels{Time: now, Sources: meter.Levels()}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,449 @@
+// Web UI of rtp-monitor, built on the HTTP API and its WebSocket event stream
+"use strict";
+
+const receivingTimeout = 2000; // ms, as in the terminal UI
+const toastDuration = 5000; // ms
+const maxToasts = 5;
+const meterRange = 100; // dB shown by the meters, as in the terminal UI
+const clipThreshold = -0.1; // dBFS
+const peakHold = 1000; // ms
+
+const state = {
+  streams: new Map(), // id-hash -> stream entry
+  stats: new Map(), // id-hash -> latest statistics
+  selected: null,
+  meters: null, // WebSocket of the open meters
+};
+
+const $ = (id) => document.getElementById(id);
+
+function wsURL(path) {
+  const proto = location.protocol === "https:" ? "wss:" : "ws:";
+  return `${proto}//${location.host}${path}`;
+}
+
+async function api(method, path) {
+  // The header marks the request as not coming from a cross-site form
+  const response = await fetch(path, { method, headers: { "X-Requested-With": "rtp-monitor" } });
+  if (!response.ok) {
+    let message = response.statusText;
+    try {
+      message = (await response.json()).error;
+    } catch (e) {
+      // not a JSON error
+    }
+    throw new Error(message);
+  }
+  return response.status === 204 ? null : response.json();
+}
+
+function address(entry) {
+  const source = entry.sources[0];
+  return source ? `${source.destination}:${source.port}` : "";
+}
+
+function format(entry) {
+  if (entry["content-type"] === "Undefined") {
+    return "";
+  }
+  return `${entry["content-type"]} ${entry["sample-rate"] / 1000} kHz ${entry.channels} ch`;
+}
+
+function status(hash) {
+  const stats = state.stats.get(hash);
+  if (!stats) {
+    return { text: "", className: "" };
+  }
+
+  const last = Math.max(0, ...stats.sources.map((s) => (s["last-packet"] ? Date.parse(s["last-packet"]) : 0)));
+  if (last && Date.now() - last <= receivingTimeout) {
+    return { text: "receiving", className: "receiving" };
+  }
+  return { text: "idle", className: "idle" };
+}
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function matchesFilter(entry, filter) {
+  if (!filter) {
+    return true;
+  }
+  return [entry.name, entry["id-hash"], address(entry), entry.host || ""]
+    .some((field) => field.toLowerCase().includes(filter));
+}
+
+function renderTable() {
+  const filter = $("filter").value.trim().toLowerCase();
+  const entries = [...state.streams.values()]
+    .filter((e) => matchesFilter(e, filter))
+    .sort((a, b) => a.name.localeCompare(b.name) || a.id.localeCompare(b.id));
+
+  const rows = entries.map((entry) => {
+    const hash = entry["id-hash"];
+    const stats = state.stats.get(hash);
+    const st = status(hash);
+
+    const tr = document.createElement("tr");
+    tr.dataset.hash = hash;
+    if (hash === state.selected) {
+      tr.className = "selected";
+    }
+
+    let rate = "";
+    let errors = "";
+    if (stats) {
+      rate = stats.sources.map((s) => s["packet-rate"].toFixed(0)).join(" / ");
+      errors = stats.sources.reduce((n, s) => n + s["sequence-errors"], 0);
+    }
+
+    tr.append(
+      cell(entry.name),
+      cell(address(entry)),
+      cell(format(entry)),
+      cell(entry.discovery),
+      cell(st.text, st.className),
+      cell(rate, "number"),
+      cell(errors, errors > 0 ? "number errors" : "number"),
+    );
+    tr.addEventListener("click", () => select(hash));
+
+    return tr;
+  });
+
+  $("streams").replaceChildren(...rows);
+  $("count").textContent = `${entries.length} of ${state.streams.size} streams`;
+}
+
+function field(dl, name, value) {
+  if (value === undefined || value === null || value === "") {
+    return;
+  }
+  const dt = document.createElement("dt");
+  dt.textContent = name;
+  const dd = document.createElement("dd");
+  dd.textContent = value;
+  dl.append(dt, dd);
+}
+
+function renderDetails() {
+  const entry = state.streams.get(state.selected);
+  if (!entry) {
+    $("details").hidden = true;
+    return;
+  }
+
+  $("details").hidden = false;
+  $("details-name").textContent = entry.name;
+
+  const dl = $("details-fields");
+  dl.replaceChildren();
+  field(dl, "ID", entry.id);
+  field(dl, "ID hash", entry["id-hash"]);
+  field(dl, "Host", entry.host);
+  field(dl, "Format", format(entry));
+  field(dl, "Discovery", entry.discovery);
+  field(dl, "Last announced", entry["last-announced"] && new Date(entry["last-announced"]).toLocaleString());
+
+  const stats = state.stats.get(state.selected);
+  const header = ["Source", "Sender", "Destination", "TTL", "Frames/packet", "Clock domain"];
+  if (stats) {
+    header.push("Packets", "Packets/s", "Seq. errors", "Jitter");
+  }
+
+  const table = $("details-sources");
+  const head = document.createElement("tr");
+  head.append(...header.map((h) => {
+    const th = document.createElement("th");
+    th.textContent = h;
+    return th;
+  }));
+
+  const rows = entry.sources.map((source, i) => {
+    const tr = document.createElement("tr");
+    tr.append(
+      cell(i + 1),
+      cell(source.sender),
+      cell(`${source.destination}:${source.port}`),
+      cell(source.ttl),
+      cell(source["frames-per-packet"]),
+      cell(source["clock-domain"] || ""),
+    );
+
+    const s = stats && stats.sources[i];
+    if (s) {
+      tr.append(
+        cell(s.packets, "number"),
+        cell(s["packet-rate"].toFixed(1), "number"),
+        cell(s["sequence-errors"], s["sequence-errors"] > 0 ? "number errors" : "number"),
+        cell(`${(s["jitter-s"] * 1e6).toFixed(0)} µs`, "number"),
+      );
+    }
+    return tr;
+  });
+
+  table.replaceChildren(head, ...rows);
+  $("details-sdp").textContent = entry.sdp;
+
+  $("stats-toggle").classList.toggle("on", !!stats);
+  $("meters-toggle").classList.toggle("on", !!state.meters);
+  $("meters-toggle").disabled = entry["content-type"] === "Undefined";
+}
+
+function select(hash) {
+  if (state.selected !== hash) {
+    closeMeters();
+  }
+  state.selected = hash;
+  renderTable();
+  renderDetails();
+}
+
+function toast(level, text) {
+  const div = document.createElement("div");
+  div.className = `toast ${level}`;
+  div.textContent = text;
+
+  const toasts = $("toasts");
+  toasts.append(div);
+  while (toasts.children.length > maxToasts) {
+    toasts.firstChild.remove();
+  }
+
+  setTimeout(() => div.remove(), toastDuration);
+}
+
+async function loadStreams() {
+  const entries = await api("GET", "/api/streams");
+  state.streams = new Map(entries.map((e) => [e["id-hash"], e]));
+  renderTable();
+  renderDetails();
+}
+
+function handleEvent(event) {
+  switch (event.type) {
+    case "stream-added":
+      state.streams.set(event["id-hash"], event.data);
+      toast("info", `New stream: ${event.data.name}`);
+      break;
+    case "stream-updated":
+      state.streams.set(event["id-hash"], event.data);
+      break;
+    case "stream-removed":
+      state.streams.delete(event["id-hash"]);
+      state.stats.delete(event["id-hash"]);
+      toast("warning", `Stream gone: ${event.data.name}`);
+      if (state.selected === event["id-hash"]) {
+        select(null);
+      }
+      break;
+    case "stats":
+      // The table is refreshed once per second
+      state.stats.set(event["id-hash"], event.data);
+      if (event["id-hash"] === state.selected) {
+        renderDetails();
+      }
+      return;
+    case "alert":
+      toast(event.data.level, event.data.message);
+      return;
+    case "ptp-grandmaster":
+      toast("warning", `PTP domain ${event.data.domain}: grandmaster ${event.data.grandmaster}`);
+      return;
+  }
+
+  renderTable();
+  if (event["id-hash"] === state.selected) {
+    renderDetails();
+  }
+}
+
+// Statistics events only arrive for streams with statistics, so drop the
+// ones that stopped
+function pruneStats() {
+  const now = Date.now();
+  for (const [hash, stats] of state.stats) {
+    if (now - stats.received > 3000) {
+      state.stats.delete(hash);
+    }
+  }
+}
+
+function connectEvents() {
+  const ws = new WebSocket(wsURL("/api/events"));
+
+  ws.onopen = () => {
+    $("connection").textContent = "connected";
+    $("connection").className = "connected";
+    loadStreams().catch((e) => toast("error", `Loading streams failed: ${e.message}`));
+  };
+
+  ws.onmessage = (msg) => {
+    const event = JSON.parse(msg.data);
+    if (event.type === "stats") {
+      event.data.received = Date.now();
+    }
+    handleEvent(event);
+  };
+
+  ws.onclose = () => {
+    $("connection").textContent = "disconnected";
+    $("connection").className = "disconnected";
+    setTimeout(connectEvents, 2000);
+  };
+}
+
+function createMeters(levels) {
+  const container = $("meters");
+  container.replaceChildren();
+
+  levels.sources.forEach((channels, i) => {
+    if (levels.sources.length > 1) {
+      const label = document.createElement("div");
+      label.className = "source";
+      label.textContent = `Source ${i + 1}`;
+      container.append(label);
+    }
+
+    channels.forEach((_, ch) => {
+      const meter = document.createElement("div");
+      meter.className = "meter";
+      meter.innerHTML = `<span class="label">${ch + 1}</span>` +
+        `<span class="bar"><span class="level"></span><span class="mask"></span><span class="peak"></span></span>` +
+        `<span class="value"></span>`;
+      meter.peak = { db: -meterRange, time: 0 };
+      container.append(meter);
+    });
+  });
+}
+
+function percent(db) {
+  return Math.min(100, Math.max(0, (db + meterRange) / meterRange * 100));
+}
+
+function updateMeters(levels) {
+  const meters = $("meters").querySelectorAll(".meter");
+  const flat = levels.sources.flat();
+  if (meters.length !== flat.length) {
+    createMeters(levels);
+    return updateMeters(levels);
+  }
+
+  const now = Date.now();
+
+  flat.forEach((level, i) => {
+    const meter = meters[i];
+
+    if (level.peak >= meter.peak.db || now - meter.peak.time > peakHold) {
+      meter.peak = { db: level.peak, time: now };
+    }
+
+    meter.querySelector(".mask").style.width = `${100 - percent(level.rms)}%`;
+    meter.querySelector(".peak").style.left = `${percent(meter.peak.db)}%`;
+
+    const value = meter.querySelector(".value");
+    value.textContent = meter.peak.db <= -meterRange ? "-inf" : meter.peak.db.toFixed(1);
+    value.classList.toggle("clip", meter.peak.db > clipThreshold);
+  });
+}
+
+function openMeters() {
+  const ws = new WebSocket(wsURL(`/api/streams/${state.selected}/levels`));
+  state.meters = ws;
+
+  ws.onmessage = (msg) => updateMeters(JSON.parse(msg.data));
+  ws.onclose = () => {
+    if (state.meters === ws) {
+      closeMeters();
+      toast("error", "Meters disconnected");
+    }
+  };
+
+  $("meters").hidden = false;
+  renderDetails();
+}
+
+function closeMeters() {
+  if (state.meters) {
+    const ws = state.meters;
+    state.meters = null;
+    ws.close();
+  }
+  $("meters").hidden = true;
+  $("meters").replaceChildren();
+  $("meters-toggle").classList.remove("on");
+}
+
+async function toggleStats() {
+  const hash = state.selected;
+  const running = state.stats.has(hash);
+  try {
+    await api(running ? "DELETE" : "POST", `/api/streams/${hash}/stats`);
+    if (running) {
+      state.stats.delete(hash);
+    }
+    renderTable();
+    renderDetails();
+  } catch (e) {
+    toast("error", `Statistics: ${e.message}`);
+  }
+}
+
+$("filter").addEventListener("input", renderTable);
+$("details-close").addEventListener("click", () => select(null));
+$("stats-toggle").addEventListener("click", toggleStats);
+$("meters-toggle").addEventListener("click", () => (state.meters ? closeMeters() : openMeters()));
+
+document.addEventListener("keydown", (e) => {
+  if (e.target.tagName === "INPUT") {
+    return;
+  }
+
+  const rows = [...$("streams").children];
+  const index = rows.findIndex((tr) => tr.dataset.hash === state.selected);
+
+  switch (e.key) {
+    case "ArrowDown":
+    case "j":
+      if (index < rows.length - 1) {
+        select(rows[index + 1].dataset.hash);
+      }
+      break;
+    case "ArrowUp":
+    case "k":
+      if (index > 0) {
+        select(rows[index - 1].dataset.hash);
+      }
+      break;
+    case "Escape":
+      select(null);
+      break;
+    case "m":
+      if (state.selected) {
+        state.meters ? closeMeters() : openMeters();
+      }
+      break;
+    case "b":
+      if (state.selected) {
+        toggleStats();
+      }
+      break;
+    default:
+      return;
+  }
+
+  e.preventDefault();
+});
+
+setInterval(() => {
+  pruneStats();
+  renderTable();
+}, 1000);
+
+connectEvents();
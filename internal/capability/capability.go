@@ -0,0 +1,136 @@
+// Package capability detects which features of the monitor are unavailable
+// for lack of privileges or devices, and why, so that can be reported
+// rather than features failing silently.
+package capability
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Names of the features that are checked
+const (
+	PTP  = "PTP monitoring"
+	IGMP = "IGMP diagnostics"
+	FPGA = "FPGA streaming"
+)
+
+// Feature is a feature of the monitor that needs privileges or a device
+type Feature struct {
+	Name string
+
+	// Err tells why the feature is unavailable, nil if it is available
+	Err error
+}
+
+// Report lists the features checked at startup
+type Report struct {
+	mutex    sync.Mutex
+	features []Feature
+}
+
+// Detect checks the privileges PTP monitoring and IGMP diagnostics need,
+// and the access to the FPGA stream device if there is one at fpgaDevice
+func Detect(fpgaDevice string) *Report {
+	r := &Report{}
+
+	r.Update(PTP, LowPorts())
+	r.Update(IGMP, RawSockets())
+
+	if _, err := os.Stat(fpgaDevice); err == nil {
+		r.Update(FPGA, Device(fpgaDevice))
+	}
+
+	return r
+}
+
+// Update records whether a feature is available. A failure of a feature
+// whose check already failed keeps the reason of the check.
+func (r *Report) Update(name string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, f := range r.features {
+		if f.Name != name {
+			continue
+		}
+
+		if err != nil && f.Err != nil {
+			err = fmt.Errorf("%w (%v)", f.Err, err)
+		}
+
+		r.features[i].Err = err
+
+		return
+	}
+
+	r.features = append(r.features, Feature{Name: name, Err: err})
+}
+
+// Features returns the checked features in the order they were first
+// recorded
+func (r *Report) Features() []Feature {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Feature{}, r.features...)
+}
+
+// Unavailable returns the features that are unavailable
+func (r *Report) Unavailable() []Feature {
+	var unavailable []Feature
+
+	for _, f := range r.Features() {
+		if f.Err != nil {
+			unavailable = append(unavailable, f)
+		}
+	}
+
+	return unavailable
+}
+
+// LowPorts returns an error if UDP ports below 1024, such as 319 and 320 of
+// PTP, can't be bound. A port that is in use counts as bindable.
+func LowPorts() error {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:1023")
+	if errors.Is(err, os.ErrPermission) {
+		return errors.New("binding ports below 1024 needs root or CAP_NET_BIND_SERVICE")
+	}
+
+	if err == nil {
+		conn.Close()
+	}
+
+	return nil
+}
+
+// RawSockets returns an error if raw sockets, such as those receiving IGMP
+// messages, can't be opened
+func RawSockets() error {
+	conn, err := net.ListenPacket("ip4:igmp", "0.0.0.0")
+	if errors.Is(err, os.ErrPermission) {
+		return errors.New("raw sockets need root or CAP_NET_RAW")
+	}
+
+	if err == nil {
+		conn.Close()
+	}
+
+	return nil
+}
+
+// Device returns an error if the device at path can't be opened for reading
+// and writing
+func Device(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("no permission to open %s, it needs root or membership in the group of the device", path)
+	} else if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	return f.Close()
+}
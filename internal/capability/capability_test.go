@@ -0,0 +1,51 @@
+package capability
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReportUpdate(t *testing.T) {
+	r := &Report{}
+
+	checked := errors.New("binding ports below 1024 needs root")
+	r.Update(PTP, checked)
+	r.Update(IGMP, errors.New("raw sockets need root"))
+
+	// A failure at startup keeps the reason of the check
+	r.Update(PTP, errors.New("permission denied"))
+
+	// A feature that works after all is available
+	r.Update(IGMP, nil)
+
+	features := r.Features()
+	if len(features) != 2 || features[0].Name != PTP || features[1].Name != IGMP {
+		t.Fatalf("got features %+v, want PTP and IGMP", features)
+	}
+
+	if err := features[0].Err; !errors.Is(err, checked) || err.Error() != "binding ports below 1024 needs root (permission denied)" {
+		t.Errorf("PTP error = %v", err)
+	}
+
+	if unavailable := r.Unavailable(); len(unavailable) != 1 || unavailable[0].Name != PTP {
+		t.Errorf("Unavailable() = %+v, want PTP only", unavailable)
+	}
+}
+
+func TestDevice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device")
+
+	if err := Device(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Device() of a missing file = %v, want ErrNotExist", err)
+	}
+
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Device(path); err != nil {
+		t.Errorf("Device() = %v, want nil", err)
+	}
+}
@@ -2,12 +2,20 @@ package clipboard
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
 )
 
+// ErrNoTool is returned if no clipboard tool is installed for the current
+// session. The error wrapping it names the tools that can be installed.
+var ErrNoTool = errors.New("no clipboard tool found")
+
 func WriteString(s string) error {
 	return Write([]byte(s))
 }
@@ -20,41 +28,71 @@ func Write(b []byte) error {
 	return WriteContext(context.Background(), b)
 }
 
+// WriteContext copies b to the system clipboard. In SSH sessions without a
+// clipboard tool, b is sent to the local terminal as OSC 52 escape sequence
+// instead, which most terminal emulators copy to their clipboard.
 func WriteContext(ctx context.Context, b []byte) error {
-	var cmd *exec.Cmd
+	err := writeNative(ctx, b)
+	if errors.Is(err, ErrNoTool) && sshSession() && isTerminal(os.Stdout) {
+		return writeOSC52(os.Stdout, b)
+	}
 
-	switch {
-	case runtime.GOOS == "darwin":
-		cmd = exec.CommandContext(ctx, "pbcopy")
-	case runtime.GOOS == "linux":
-		if os.Getenv("WAYLAND_DISPLAY") != "" {
-			cmd = exec.CommandContext(ctx, "wl-copy", "-t", "text/plain")
-		} else {
-			cmd = exec.CommandContext(ctx, "xclip", "-in", "-selection", "clipboard")
+	return err
+}
+
+// tool is a command that reads the clipboard content from stdin
+type tool struct {
+	name string
+	args []string
+}
+
+// runTool pipes b into the command of t
+func runTool(ctx context.Context, t tool, b []byte) error {
+	cmd := exec.CommandContext(ctx, t.name, t.args...)
+	cmd.Stdin = strings.NewReader(string(b))
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", t.name, err, msg)
 		}
-	default:
-		return fmt.Errorf("OS %s not supported", runtime.GOOS)
-	}
 
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return err
+		return fmt.Errorf("%s: %w", t.name, err)
 	}
 
-	err = cmd.Start()
+	return nil
+}
+
+// sshSession returns whether the process runs in an SSH session, where the
+// clipboard of the remote host is of no use
+func sshSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// isTerminal returns whether f is a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
 	if err != nil {
-		return err
+		return false
 	}
 
-	_, err = stdin.Write(b)
-	if err != nil {
-		return err
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// writeOSC52 writes b to w as OSC 52 escape sequence, wrapped for tmux and
+// screen if the process runs inside them
+func writeOSC52(w io.Writer, b []byte) error {
+	seq := osc52.New(string(b))
+
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case os.Getenv("STY") != "":
+		seq = seq.Screen()
 	}
 
-	err = stdin.Close()
-	if err != nil {
-		return err
+	if _, err := seq.WriteTo(w); err != nil {
+		return fmt.Errorf("writing OSC 52 sequence: %w", err)
 	}
 
-	return cmd.Wait()
+	return nil
 }
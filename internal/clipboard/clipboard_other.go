@@ -0,0 +1,54 @@
+//go:build !windows
+
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// tools returns the clipboard tools that work in the session described by
+// the environment, in order of preference
+func tools(goos string, getenv func(string) string) []tool {
+	if goos == "darwin" {
+		return []tool{{"pbcopy", nil}}
+	}
+
+	var t []tool
+
+	if getenv("WAYLAND_DISPLAY") != "" {
+		t = append(t, tool{"wl-copy", []string{"-t", "text/plain"}})
+	}
+
+	if getenv("DISPLAY") != "" {
+		t = append(t,
+			tool{"xclip", []string{"-in", "-selection", "clipboard"}},
+			tool{"xsel", []string{"--input", "--clipboard"}},
+		)
+	}
+
+	return t
+}
+
+// writeNative copies b with the first clipboard tool that is installed
+func writeNative(ctx context.Context, b []byte) error {
+	candidates := tools(runtime.GOOS, os.Getenv)
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: no Wayland or X11 display", ErrNoTool)
+	}
+
+	names := make([]string, len(candidates))
+	for i, t := range candidates {
+		if _, err := exec.LookPath(t.name); err == nil {
+			return runTool(ctx, t, b)
+		}
+
+		names[i] = t.name
+	}
+
+	return fmt.Errorf("%w, install %s", ErrNoTool, strings.Join(names, " or "))
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+
+package clipboard
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestTools(t *testing.T) {
+	tests := []struct {
+		goos string
+		env  map[string]string
+		want []string
+	}{
+		{"darwin", nil, []string{"pbcopy"}},
+		{"linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xclip", "xsel"}},
+		{"linux", map[string]string{"DISPLAY": ":0"}, []string{"xclip", "xsel"}},
+		{"linux", nil, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, tool := range tools(tt.goos, func(k string) string { return tt.env[k] }) {
+			got = append(got, tool.name)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("tools(%s, %v) = %v, want %v", tt.goos, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestWriteOSC52(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("STY", "")
+
+	var buf bytes.Buffer
+	if err := writeOSC52(&buf, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "\x1b]52;c;aGVsbG8=\x07"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
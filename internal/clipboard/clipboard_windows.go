@@ -0,0 +1,86 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	openClipboard    = user32.NewProc("OpenClipboard")
+	closeClipboard   = user32.NewProc("CloseClipboard")
+	emptyClipboard   = user32.NewProc("EmptyClipboard")
+	setClipboardData = user32.NewProc("SetClipboardData")
+
+	globalAlloc  = kernel32.NewProc("GlobalAlloc")
+	globalFree   = kernel32.NewProc("GlobalFree")
+	globalLock   = kernel32.NewProc("GlobalLock")
+	globalUnlock = kernel32.NewProc("GlobalUnlock")
+)
+
+// writeNative copies b to the Windows clipboard as Unicode text
+func writeNative(ctx context.Context, b []byte) error {
+	text, err := syscall.UTF16FromString(string(b))
+	if err != nil {
+		return fmt.Errorf("converting text: %w", err)
+	}
+
+	// The clipboard belongs to the thread that opened it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// Another application may hold the clipboard for a moment
+	for {
+		if r, _, _ := openClipboard.Call(0); r != 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("opening clipboard: %w", ctx.Err())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer closeClipboard.Call()
+
+	if r, _, err := emptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("emptying clipboard: %w", err)
+	}
+
+	size := uintptr(len(text)) * unsafe.Sizeof(text[0])
+
+	mem, _, err := globalAlloc.Call(gmemMoveable, size)
+	if mem == 0 {
+		return fmt.Errorf("allocating clipboard memory: %w", err)
+	}
+
+	p, _, err := globalLock.Call(mem)
+	if p == 0 {
+		globalFree.Call(mem)
+		return fmt.Errorf("locking clipboard memory: %w", err)
+	}
+
+	copy(unsafe.Slice((*uint16)(unsafe.Add(nil, p)), len(text)), text)
+	globalUnlock.Call(mem)
+
+	// The clipboard owns the memory once SetClipboardData succeeds
+	if r, _, err := setClipboardData.Call(cfUnicodeText, mem); r == 0 {
+		globalFree.Call(mem)
+		return fmt.Errorf("setting clipboard data: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	dirName  = "rtp-monitor"
+	fileName = "config.json"
+)
+
+// Config holds the user configuration persisted in the config file
+type Config struct {
+	// Keys maps action names to the keys that trigger them. Actions not
+	// listed here keep their default bindings.
+	Keys map[string][]string `json:"keys,omitempty"`
+
+	// Favorites holds the ID hashes of streams marked as favorites
+	Favorites []string `json:"favorites,omitempty"`
+
+	// Expected holds the ID hashes of streams that raise alerts when they
+	// disappear or stop
+	Expected []string `json:"expected,omitempty"`
+
+	// StreamInterfaces maps the ID hashes of streams to the interface their
+	// receivers join on instead of all interfaces
+	StreamInterfaces map[string]string `json:"stream_interfaces,omitempty"`
+
+	// Annotations maps the ID hashes of streams to the alias and note the
+	// user gave them
+	Annotations map[string]Annotation `json:"annotations,omitempty"`
+
+	// Settings holds the options changed in the settings modal
+	Settings Settings `json:"settings"`
+
+	// Webhooks are notified of alerts and other events
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+
+	// Hooks are commands run on events
+	Hooks Hooks `json:"hooks,omitzero"`
+
+	// FPGA holds the parameters of streams received with a RAVENNA FPGA
+	FPGA FPGA `json:"fpga"`
+
+	path string
+}
+
+// Annotation labels a stream without changing its announcement
+type Annotation struct {
+	// Alias is shown instead of the announced name
+	Alias string `json:"alias,omitempty"`
+
+	// Note is a free text, e.g. where the stream is patched
+	Note string `json:"note,omitempty"`
+}
+
+// Webhook is an HTTP endpoint that events are posted to
+type Webhook struct {
+	URL string `json:"url"`
+
+	// Events are the event types to post, by default alerts and removed
+	// streams
+	Events []string `json:"events,omitempty"`
+
+	// Template is "slack" or "teams" for a message in the format of these
+	// services, or a Go template for the request body. By default, the
+	// notification is posted as JSON.
+	Template string `json:"template,omitempty"`
+
+	// Headers are added to each request, e.g. for authorization
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Hooks are shell commands run on events, with the event described in
+// environment variables
+type Hooks struct {
+	OnStreamAdded   string `json:"on_stream_added,omitempty"`
+	OnStreamRemoved string `json:"on_stream_removed,omitempty"`
+	OnAlert         string `json:"on_alert,omitempty"`
+}
+
+// DefaultPath returns the default location of the config file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return fileName
+	}
+
+	return filepath.Join(dir, dirName, fileName)
+}
+
+// Load reads the config file at the given path. A missing file is not an
+// error; an empty configuration is returned instead.
+func Load(path string) (*Config, error) {
+	c := &Config{
+		path: path,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Settings = DefaultSettings()
+		c.FPGA = DefaultFPGA()
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Settings missing from the file keep their defaults, so values that
+	// are valid as zero, like a clip threshold of 0 dBFS, can be set
+	c.Settings = DefaultSettings()
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	c.Settings.applyDefaults()
+	c.FPGA.applyDefaults()
+
+	return c, nil
+}
+
+// Path returns the path the config was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Save writes the config back to the file it was loaded from
+func (c *Config) Save() error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// IsFavorite returns whether the stream with the given ID hash is a favorite
+func (c *Config) IsFavorite(idHash string) bool {
+	return slices.Contains(c.Favorites, idHash)
+}
+
+// ToggleFavorite adds or removes a stream from the favorites and returns
+// whether it is a favorite now
+func (c *Config) ToggleFavorite(idHash string) bool {
+	if i := slices.Index(c.Favorites, idHash); i >= 0 {
+		c.Favorites = slices.Delete(c.Favorites, i, i+1)
+		return false
+	}
+
+	c.Favorites = append(c.Favorites, idHash)
+
+	return true
+}
+
+// IsExpected returns whether the stream with the given ID hash is expected
+func (c *Config) IsExpected(idHash string) bool {
+	return slices.Contains(c.Expected, idHash)
+}
+
+// ToggleExpected adds or removes a stream from the expected streams and
+// returns whether it is expected now
+func (c *Config) ToggleExpected(idHash string) bool {
+	if i := slices.Index(c.Expected, idHash); i >= 0 {
+		c.Expected = slices.Delete(c.Expected, i, i+1)
+		return false
+	}
+
+	c.Expected = append(c.Expected, idHash)
+
+	return true
+}
+
+// SetStreamInterface sets the interface the receivers of a stream join on,
+// or removes it if name is empty
+func (c *Config) SetStreamInterface(idHash, name string) {
+	if name == "" {
+		delete(c.StreamInterfaces, idHash)
+		return
+	}
+
+	if c.StreamInterfaces == nil {
+		c.StreamInterfaces = make(map[string]string)
+	}
+
+	c.StreamInterfaces[idHash] = name
+}
+
+// Annotation returns the annotation of a stream, empty if it has none
+func (c *Config) Annotation(idHash string) Annotation {
+	return c.Annotations[idHash]
+}
+
+// SetAnnotation sets the annotation of a stream, or removes it if both alias
+// and note are empty
+func (c *Config) SetAnnotation(idHash string, a Annotation) {
+	if a == (Annotation{}) {
+		delete(c.Annotations, idHash)
+		return
+	}
+
+	if c.Annotations == nil {
+		c.Annotations = make(map[string]Annotation)
+	}
+
+	c.Annotations[idHash] = a
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if c.Path() != path {
+		t.Errorf("Path() = %q, want %q", c.Path(), path)
+	}
+
+	if len(c.Keys) != 0 || len(c.Favorites) != 0 {
+		t.Errorf("Expected empty config, got %+v", c)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.json")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	c.Keys = map[string][]string{"rtcp": {"R"}}
+	c.ToggleFavorite("71cb8481ed")
+
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if got := loaded.Keys["rtcp"]; len(got) != 1 || got[0] != "R" {
+		t.Errorf("Keys[\"rtcp\"] = %v, want [R]", got)
+	}
+
+	if !loaded.IsFavorite("71cb8481ed") {
+		t.Error("Expected favorite to be persisted")
+	}
+}
+
+func TestToggleFavorite(t *testing.T) {
+	c := &Config{}
+
+	if !c.ToggleFavorite("a") {
+		t.Error("Expected first toggle to add favorite")
+	}
+
+	if !c.IsFavorite("a") {
+		t.Error("Expected IsFavorite() to be true")
+	}
+
+	if c.ToggleFavorite("a") {
+		t.Error("Expected second toggle to remove favorite")
+	}
+
+	if c.IsFavorite("a") {
+		t.Error("Expected IsFavorite() to be false")
+	}
+}
+
+func TestToggleExpected(t *testing.T) {
+	c := &Config{}
+
+	if !c.ToggleExpected("a") || !c.IsExpected("a") {
+		t.Error("Expected first toggle to add the stream")
+	}
+
+	if c.ToggleExpected("a") || c.IsExpected("a") {
+		t.Error("Expected second toggle to remove the stream")
+	}
+}
+
+func TestSetStreamInterface(t *testing.T) {
+	c := &Config{}
+
+	c.SetStreamInterface("a", "eth1")
+
+	if got := c.StreamInterfaces["a"]; got != "eth1" {
+		t.Errorf("StreamInterfaces[\"a\"] = %q, want eth1", got)
+	}
+
+	c.SetStreamInterface("a", "")
+
+	if _, ok := c.StreamInterfaces["a"]; ok {
+		t.Error("Expected interface to be removed")
+	}
+}
+
+func TestSetAnnotation(t *testing.T) {
+	c := &Config{}
+
+	c.SetAnnotation("a", Annotation{Alias: "Stagebox 3 FOH mix"})
+
+	if got := c.Annotation("a"); got.Alias != "Stagebox 3 FOH mix" || got.Note != "" {
+		t.Errorf("Annotation(\"a\") = %+v, want the alias only", got)
+	}
+
+	c.SetAnnotation("a", Annotation{})
+
+	if _, ok := c.Annotations["a"]; ok {
+		t.Error("Expected annotation to be removed")
+	}
+}
+
+func TestSettingsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte(`{"settings": {"refresh-interval-ms": 250, "modal-refresh-ms": {"meters": 100, "details": 0}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	want := DefaultSettings()
+	want.RefreshIntervalMs = 250
+	want.ModalRefreshMs = map[string]int{"meters": 100}
+
+	if !reflect.DeepEqual(c.Settings, want) {
+		t.Errorf("Settings = %+v, want %+v", c.Settings, want)
+	}
+
+	if got := c.Settings.RefreshInterval(); got != 250*time.Millisecond {
+		t.Errorf("RefreshInterval() = %v, want 250ms", got)
+	}
+
+	if got := c.Settings.ModalRefreshIntervals()["meters"]; got != 100*time.Millisecond {
+		t.Errorf("ModalRefreshIntervals()[\"meters\"] = %v, want 100ms", got)
+	}
+}
+
+func TestClipThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		config string
+		want   float64
+	}{
+		{`{"settings": {}}`, -0.1},
+		{`{"settings": {"clip-threshold-db": 0}}`, 0},
+		{`{"settings": {"clip-threshold-db": -3}}`, -3},
+		{`{"settings": {"clip-threshold-db": 1}}`, -0.1},
+	} {
+		path := filepath.Join(t.TempDir(), "config.json")
+
+		if err := os.WriteFile(path, []byte(tc.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+
+		if c.Settings.ClipThreshold != tc.want {
+			t.Errorf("ClipThreshold of %s = %v, want %v", tc.config, c.Settings.ClipThreshold, tc.want)
+		}
+	}
+}
+
+func TestFPGADefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte(`{"fpga": {"rtp_offset": 250, "sample_rates": [48000, 96000]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	want := DefaultFPGA()
+	want.RTPOffset = 250
+	want.SampleRates = []uint32{48000, 96000}
+
+	if !reflect.DeepEqual(c.FPGA, want) {
+		t.Errorf("FPGA = %+v, want %+v", c.FPGA, want)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+// FPGA holds the parameters of streams received with a RAVENNA FPGA. Zero
+// values are replaced by the defaults when the config is loaded.
+type FPGA struct {
+	// Device is the path of the stream device
+	Device string `json:"device,omitempty"`
+
+	// PayloadType overrides the RTP payload type of the SDP if not zero
+	PayloadType uint8 `json:"payload_type,omitempty"`
+
+	// RTPOffset is the RTP offset and jitter buffer margin in samples
+	RTPOffset int `json:"rtp_offset,omitempty"`
+
+	// StartTrack is the track the first channel is played out on
+	StartTrack int `json:"start_track,omitempty"`
+
+	// SampleRates are the sample rates the media clock of the FPGA runs at
+	SampleRates []uint32 `json:"sample_rates,omitempty"`
+
+	// TXSource is the address of the network interface of the FPGA that
+	// test streams are sent from
+	TXSource string `json:"tx_source,omitempty"`
+
+	// TXDestination is the address and port test streams are sent to
+	TXDestination string `json:"tx_destination,omitempty"`
+}
+
+// DefaultFPGA returns the FPGA parameters used when nothing is configured
+func DefaultFPGA() FPGA {
+	return FPGA{
+		Device:        "/dev/ravenna-stream-device",
+		RTPOffset:     500,
+		SampleRates:   []uint32{48000},
+		TXDestination: "239.69.255.1:5004",
+	}
+}
+
+// applyDefaults replaces unset values with their defaults
+func (f *FPGA) applyDefaults() {
+	d := DefaultFPGA()
+
+	if f.Device == "" {
+		f.Device = d.Device
+	}
+
+	if f.RTPOffset <= 0 {
+		f.RTPOffset = d.RTPOffset
+	}
+
+	if len(f.SampleRates) == 0 {
+		f.SampleRates = d.SampleRates
+	}
+
+	if f.TXDestination == "" {
+		f.TXDestination = d.TXDestination
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"time"
+)
+
+// Settings holds the options that can be changed in the settings modal.
+// Zero values are replaced by the defaults when the config is loaded.
+type Settings struct {
+	// RefreshIntervalMs is the refresh interval of the detail pane and the
+	// table, and the shortest one of modals
+	RefreshIntervalMs int `json:"refresh-interval-ms"`
+
+	// ModalRefreshMs overrides the refresh interval of modals by name, such
+	// as "meters" or "details"
+	ModalRefreshMs map[string]int `json:"modal-refresh-ms,omitempty"`
+
+	// ClipThreshold is the peak level in dBFS above which meters show a clip
+	ClipThreshold float64 `json:"clip-threshold-db"`
+
+	// ClipHoldSeconds is how long the clip indicator stays on
+	ClipHoldSeconds int `json:"clip-hold-s"`
+
+	// WavFolder is where recordings are written to, unless --wav is given
+	WavFolder string `json:"wav-folder"`
+
+	// ExportFolder is where exported files are written to, unless --export-dir is given
+	ExportFolder string `json:"export-folder"`
+
+	// Theme is the name of the color theme
+	Theme string `json:"theme"`
+
+	// MeterGradient is the name of the meter gradient, or "theme" to use
+	// the gradient of the theme
+	MeterGradient string `json:"meter-gradient"`
+
+	// StaleTimeoutSeconds is the age of the last SAP announcement after
+	// which a stream is shown as stale
+	StaleTimeoutSeconds int `json:"stale-timeout-s"`
+
+	// SequenceErrorThreshold is the number of new sequence errors per second
+	// that triggers an alert
+	SequenceErrorThreshold int `json:"sequence-error-threshold"`
+
+	// LogRetention is the number of entries kept in logs that grow while
+	// they are open, such as the RTCP log
+	LogRetention int `json:"log-retention"`
+
+	// LinkOffsetUs is the link offset of the virtual receiver in the stream
+	// details, in microseconds
+	LinkOffsetUs int `json:"link-offset-us"`
+
+	// LinkCapacityMbps is the capacity of the links of the interfaces in
+	// Mbit/s, which the bandwidth of the joined groups is warned about when
+	// approaching
+	LinkCapacityMbps int `json:"link-capacity-mbps"`
+
+	// MiniMeters shows the peak level of the streams statistics are
+	// collected for in the table
+	MiniMeters bool `json:"mini-meters"`
+}
+
+// DefaultSettings returns the settings used when nothing is configured
+func DefaultSettings() Settings {
+	return Settings{
+		RefreshIntervalMs:      50,
+		ClipThreshold:          -0.1,
+		ClipHoldSeconds:        5,
+		Theme:                  "monokai",
+		MeterGradient:          "theme",
+		StaleTimeoutSeconds:    120,
+		SequenceErrorThreshold: 1,
+		LogRetention:           5000,
+		LinkOffsetUs:           1000,
+		LinkCapacityMbps:       1000,
+	}
+}
+
+// applyDefaults replaces unset values with their defaults
+func (s *Settings) applyDefaults() {
+	d := DefaultSettings()
+
+	if s.RefreshIntervalMs <= 0 {
+		s.RefreshIntervalMs = d.RefreshIntervalMs
+	}
+
+	// Peaks can't exceed 0 dBFS
+	if s.ClipThreshold > 0 {
+		s.ClipThreshold = d.ClipThreshold
+	}
+
+	if s.ClipHoldSeconds <= 0 {
+		s.ClipHoldSeconds = d.ClipHoldSeconds
+	}
+
+	if s.Theme == "" {
+		s.Theme = d.Theme
+	}
+
+	if s.MeterGradient == "" {
+		s.MeterGradient = d.MeterGradient
+	}
+
+	if s.StaleTimeoutSeconds <= 0 {
+		s.StaleTimeoutSeconds = d.StaleTimeoutSeconds
+	}
+
+	if s.SequenceErrorThreshold <= 0 {
+		s.SequenceErrorThreshold = d.SequenceErrorThreshold
+	}
+
+	if s.LogRetention <= 0 {
+		s.LogRetention = d.LogRetention
+	}
+
+	if s.LinkOffsetUs <= 0 {
+		s.LinkOffsetUs = d.LinkOffsetUs
+	}
+
+	if s.LinkCapacityMbps <= 0 {
+		s.LinkCapacityMbps = d.LinkCapacityMbps
+	}
+
+	for name, ms := range s.ModalRefreshMs {
+		if ms <= 0 {
+			delete(s.ModalRefreshMs, name)
+		}
+	}
+}
+
+// RefreshInterval returns the refresh interval as a duration
+func (s Settings) RefreshInterval() time.Duration {
+	return time.Duration(s.RefreshIntervalMs) * time.Millisecond
+}
+
+// LinkOffset returns the link offset of the virtual receiver as a duration
+func (s Settings) LinkOffset() time.Duration {
+	return time.Duration(s.LinkOffsetUs) * time.Microsecond
+}
+
+// LinkCapacity returns the link capacity in bits per second
+func (s Settings) LinkCapacity() float64 {
+	return float64(s.LinkCapacityMbps) * 1e6
+}
+
+// ModalRefreshIntervals returns the refresh intervals of modals by name as
+// durations
+func (s Settings) ModalRefreshIntervals() map[string]time.Duration {
+	intervals := make(map[string]time.Duration, len(s.ModalRefreshMs))
+	for name, ms := range s.ModalRefreshMs {
+		intervals[name] = time.Duration(ms) * time.Millisecond
+	}
+
+	return intervals
+}
+
+// ClipHold returns the clip hold time as a duration
+func (s Settings) ClipHold() time.Duration {
+	return time.Duration(s.ClipHoldSeconds) * time.Second
+}
+
+// StaleTimeout returns the stale timeout as a duration
+func (s Settings) StaleTimeout() time.Duration {
+	return time.Duration(s.StaleTimeoutSeconds) * time.Second
+}
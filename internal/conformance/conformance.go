@@ -0,0 +1,361 @@
+// Package conformance checks the format and timing of a stream against the
+// AES67 interoperability profile and the conformance levels of SMPTE ST
+// 2110-30.
+package conformance
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/analyze"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	Pass Status = iota
+	Warn
+	Fail
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "pass"
+	case Warn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+// MarshalText encodes the status as its name
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Item is the result of one check
+type Item struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Profile holds the checks against one profile or conformance level. Its
+// status is the worst of its items.
+type Profile struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Items  []Item `json:"items"`
+}
+
+// Report is the conformance of a stream
+type Report struct {
+	Stream   inventory.Entry `json:"stream"`
+	Profiles []Profile       `json:"profiles"`
+}
+
+// aes67MaxPayload is the largest payload AES67 allows, in bytes
+const aes67MaxPayload = 1440
+
+// packetTimes are the packet times of AES67, with their number of frames at
+// 48 kHz
+var packetTimes = []struct {
+	name   string
+	frames int
+}{
+	{"125 µs", 6},
+	{"250 µs", 12},
+	{"333 µs", 16},
+	{"1 ms", 48},
+	{"4 ms", 192},
+}
+
+// level is a conformance level of ST 2110-30, with the maximum number of
+// channels by packet time at 48 kHz, and at 96 kHz for its X variant
+type level struct {
+	name       string
+	channels   map[string]int
+	channels96 map[string]int
+}
+
+var levels = []level{
+	{"A", map[string]int{"1 ms": 8}, map[string]int{"1 ms": 4}},
+	{"B", map[string]int{"1 ms": 8, "125 µs": 8}, map[string]int{"1 ms": 4, "125 µs": 8}},
+	{"C", map[string]int{"1 ms": 8, "125 µs": 64}, map[string]int{"1 ms": 4, "125 µs": 32}},
+}
+
+// packetTimeClass returns the AES67 packet time a packet time is, by its
+// number of frames, which double with the sample rate. At 44.1 kHz, packets
+// carry as many frames as at 48 kHz.
+func packetTimeClass(sampleRate uint32, packetTime time.Duration) (string, bool) {
+	frames := int(stream.FramesIn(packetTime, sampleRate))
+	scale := int(stream.NominalRate(sampleRate) / 48000)
+
+	for _, p := range packetTimes {
+		if frames == p.frames*scale {
+			return p.name, true
+		}
+	}
+
+	return "", false
+}
+
+// bytesPerSample returns the sample size of an encoding, zero if it is not
+// linear PCM
+func bytesPerSample(encoding string) int {
+	switch encoding {
+	case "L16":
+		return 2
+	case "L24":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// perSource combines the checks of all sources into an item with the worst
+// status. Details are prefixed with their source if they differ.
+func perSource(name string, n int, check func(i int) (Status, string)) Item {
+	item := Item{Name: name}
+
+	details := make([]string, n)
+	same := true
+
+	for i := range n {
+		var status Status
+		status, details[i] = check(i)
+
+		item.Status = max(item.Status, status)
+		same = same && details[i] == details[0]
+	}
+
+	switch {
+	case n == 0:
+		item.Status = Fail
+		item.Detail = "no sources"
+	case same:
+		item.Detail = details[0]
+	default:
+		for i := range details {
+			details[i] = fmt.Sprintf("source %d: %s", i+1, details[i])
+		}
+
+		item.Detail = strings.Join(details, "; ")
+	}
+
+	return item
+}
+
+func newProfile(name string, items ...Item) Profile {
+	p := Profile{Name: name, Items: items}
+
+	for _, item := range items {
+		p.Status = max(p.Status, item.Status)
+	}
+
+	return p
+}
+
+func formatRate(sampleRate uint32) string {
+	return fmt.Sprintf("%g kHz", float64(sampleRate)/1000)
+}
+
+func checkPayloadFormat(desc *stream.StreamDescription, profile string) Item {
+	if bytesPerSample(desc.Encoding) > 0 {
+		return Item{Name: "Payload format", Status: Pass, Detail: desc.Encoding}
+	}
+
+	encoding := desc.Encoding
+	if encoding == "" {
+		encoding = "unknown"
+	}
+
+	return Item{Name: "Payload format", Status: Fail, Detail: encoding + ", " + profile + " requires L16 or L24"}
+}
+
+func checkReferenceClock(desc *stream.StreamDescription) Item {
+	return perSource("Reference clock", len(desc.Sources), func(i int) (Status, string) {
+		refClock := desc.Sources[i].ReferenceClock
+
+		switch {
+		case refClock == "":
+			return Fail, "no a=ts-refclk"
+		case !strings.HasPrefix(refClock, "ptp="):
+			return Warn, refClock + ", not PTP"
+		default:
+			return Pass, refClock
+		}
+	})
+}
+
+// checkPacing judges the pacing verdicts of the analysis, nil if the stream
+// was not received
+func checkPacing(n int, pacing []string) Item {
+	return perSource("Pacing", n, func(i int) (Status, string) {
+		if i >= len(pacing) {
+			return Warn, "not measured"
+		}
+
+		switch pacing[i] {
+		case analyze.PacingGood:
+			return Pass, pacing[i]
+		case analyze.PacingAcceptable:
+			return Warn, pacing[i] + ", within the AES67 receiver buffer"
+		case analyze.PacingPoor:
+			return Fail, pacing[i] + ", beyond the AES67 receiver buffer"
+		default:
+			return Warn, "not measured"
+		}
+	})
+}
+
+func checkAES67(desc *stream.StreamDescription, pacing []string) Profile {
+	rate := Item{Name: "Sample rate", Detail: formatRate(desc.SampleRate)}
+
+	switch desc.SampleRate {
+	case 48000:
+		rate.Status = Pass
+	case 44100, 96000:
+		rate.Status = Warn
+		rate.Detail += ", optional in AES67"
+	default:
+		rate.Status = Fail
+	}
+
+	packetTime := perSource("Packet time", len(desc.Sources), func(i int) (Status, string) {
+		pt := desc.Sources[i].PacketTime
+
+		class, ok := packetTimeClass(desc.SampleRate, pt)
+
+		switch {
+		case pt == 0:
+			return Fail, "unknown, no a=ptime"
+		case !ok:
+			return Fail, pt.String() + " is not an AES67 packet time"
+		case class != "1 ms":
+			return Warn, class + ", receivers need only support 1 ms"
+		default:
+			return Pass, class
+		}
+	})
+
+	channels := perSource("Channels", len(desc.Sources), func(i int) (Status, string) {
+		ch := int(desc.ChannelCount)
+		frames := int(math.Round(desc.Sources[i].PacketTime.Seconds() * float64(desc.SampleRate)))
+		payload := ch * bytesPerSample(desc.Encoding) * frames
+
+		switch {
+		case ch == 0:
+			return Fail, "unknown"
+		case payload > aes67MaxPayload:
+			return Fail, fmt.Sprintf("%d, a payload of %d bytes exceeds %d", ch, payload, aes67MaxPayload)
+		case ch > 8:
+			return Warn, fmt.Sprintf("%d, receivers need only support 8", ch)
+		default:
+			return Pass, fmt.Sprintf("%d", ch)
+		}
+	})
+
+	return newProfile("AES67",
+		rate,
+		packetTime,
+		channels,
+		checkPayloadFormat(desc, "AES67"),
+		checkReferenceClock(desc),
+		checkPacing(len(desc.Sources), pacing))
+}
+
+func checkST2110(desc *stream.StreamDescription, l level, pacing []string) Profile {
+	rate := Item{Name: "Sample rate", Detail: formatRate(desc.SampleRate)}
+	limits := l.channels
+
+	switch desc.SampleRate {
+	case 48000:
+		rate.Status = Pass
+	case 96000:
+		rate.Status = Warn
+		rate.Detail += ", requires a level " + l.name + "X receiver"
+		limits = l.channels96
+	default:
+		rate.Status = Fail
+	}
+
+	packetTime := perSource("Packet time", len(desc.Sources), func(i int) (Status, string) {
+		pt := desc.Sources[i].PacketTime
+
+		class, _ := packetTimeClass(desc.SampleRate, pt)
+
+		switch _, ok := limits[class]; {
+		case pt == 0:
+			return Fail, "unknown, no a=ptime"
+		case !ok:
+			return Fail, fmt.Sprintf("%s, not allowed in level %s", pt, l.name)
+		default:
+			return Pass, class
+		}
+	})
+
+	channels := perSource("Channels", len(desc.Sources), func(i int) (Status, string) {
+		ch := int(desc.ChannelCount)
+		class, _ := packetTimeClass(desc.SampleRate, desc.Sources[i].PacketTime)
+
+		limit, ok := limits[class]
+
+		switch {
+		case ch == 0:
+			return Fail, "unknown"
+		case !ok:
+			return Fail, fmt.Sprintf("%d, no limit for the packet time in level %s", ch, l.name)
+		case ch > limit:
+			return Fail, fmt.Sprintf("%d, level %s allows %d at %s", ch, l.name, limit, class)
+		default:
+			return Pass, fmt.Sprintf("%d of %d", ch, limit)
+		}
+	})
+
+	return newProfile("ST 2110-30 level "+l.name,
+		rate,
+		packetTime,
+		channels,
+		checkPayloadFormat(desc, "ST 2110-30"),
+		checkReferenceClock(desc),
+		checkPacing(len(desc.Sources), pacing))
+}
+
+// checkProfiles checks a stream description against all profiles
+func checkProfiles(desc *stream.StreamDescription, pacing []string) []Profile {
+	profiles := []Profile{checkAES67(desc, pacing)}
+
+	for _, l := range levels {
+		profiles = append(profiles, checkST2110(desc, l, pacing))
+	}
+
+	return profiles
+}
+
+// Check checks a stream against AES67 and the ST 2110-30 conformance
+// levels. pacing holds the pacing verdict of each source from an analysis,
+// nil if the stream was not received.
+func Check(s *stream.Stream, pacing []string) Report {
+	return Report{
+		Stream:   inventory.FromStreams([]*stream.Stream{s})[0],
+		Profiles: checkProfiles(&s.Description, pacing),
+	}
+}
+
+// Pacing returns the pacing verdicts of the sources of an analysis
+func Pacing(r analyze.Report) []string {
+	pacing := make([]string, len(r.Sources))
+
+	for i, s := range r.Sources {
+		pacing[i] = s.Pacing
+	}
+
+	return pacing
+}
@@ -0,0 +1,149 @@
+package conformance
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/analyze"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const refClock = "ptp=IEEE1588-2008:00-1D-C1-FF-FE-12-34-56:0"
+
+func description(sampleRate, channels uint32, packetTime time.Duration) *stream.StreamDescription {
+	return &stream.StreamDescription{
+		SampleRate:   sampleRate,
+		ChannelCount: channels,
+		Encoding:     "L24",
+		Sources: []stream.StreamSource{
+			{PacketTime: packetTime, ReferenceClock: refClock},
+		},
+	}
+}
+
+// statuses returns the status of each profile, by name
+func statuses(profiles []Profile) map[string]Status {
+	m := make(map[string]Status)
+
+	for _, p := range profiles {
+		m[p.Name] = p.Status
+	}
+
+	return m
+}
+
+func TestCheckProfiles(t *testing.T) {
+	good := []string{analyze.PacingGood}
+
+	for _, tt := range []struct {
+		name   string
+		desc   *stream.StreamDescription
+		pacing []string
+		want   map[string]Status
+	}{
+		{
+			name:   "8 channels at 1 ms",
+			desc:   description(48000, 8, time.Millisecond),
+			pacing: good,
+			want:   map[string]Status{"AES67": Pass, "ST 2110-30 level A": Pass, "ST 2110-30 level B": Pass, "ST 2110-30 level C": Pass},
+		},
+		{
+			name:   "64 channels at 125 µs",
+			desc:   description(48000, 64, 125*time.Microsecond),
+			pacing: good,
+			want:   map[string]Status{"AES67": Warn, "ST 2110-30 level A": Fail, "ST 2110-30 level B": Fail, "ST 2110-30 level C": Pass},
+		},
+		{
+			name:   "16 channels at 1 ms exceed the payload",
+			desc:   description(48000, 16, time.Millisecond),
+			pacing: good,
+			want:   map[string]Status{"AES67": Fail, "ST 2110-30 level C": Fail},
+		},
+		{
+			name:   "96 kHz",
+			desc:   description(96000, 4, time.Millisecond),
+			pacing: good,
+			want:   map[string]Status{"AES67": Warn, "ST 2110-30 level A": Warn},
+		},
+		{
+			name: "not measured",
+			desc: description(48000, 2, time.Millisecond),
+			want: map[string]Status{"AES67": Warn},
+		},
+		{
+			name:   "poor pacing",
+			desc:   description(48000, 2, time.Millisecond),
+			pacing: []string{analyze.PacingPoor},
+			want:   map[string]Status{"AES67": Fail},
+		},
+	} {
+		got := statuses(checkProfiles(tt.desc, tt.pacing))
+
+		for name, want := range tt.want {
+			if got[name] != want {
+				t.Errorf("%s: %s is %s, want %s", tt.name, name, got[name], want)
+			}
+		}
+	}
+}
+
+func TestCheckSources(t *testing.T) {
+	desc := description(48000, 2, time.Millisecond)
+	desc.Encoding = "L8"
+	desc.Sources = append(desc.Sources, stream.StreamSource{PacketTime: 4 * time.Millisecond})
+
+	for _, item := range checkAES67(desc, nil).Items {
+		switch item.Name {
+		case "Payload format":
+			if item.Status != Fail {
+				t.Errorf("L8 payload is %s, want fail", item.Status)
+			}
+		case "Packet time":
+			if item.Status != Warn || !strings.Contains(item.Detail, "source 2: 4 ms") {
+				t.Errorf("packet time is %s (%s), want warn for source 2", item.Status, item.Detail)
+			}
+		case "Reference clock":
+			if item.Status != Fail || !strings.Contains(item.Detail, "source 2: no a=ts-refclk") {
+				t.Errorf("reference clock is %s (%s), want fail for source 2", item.Status, item.Detail)
+			}
+		}
+	}
+}
+
+func TestPacketTimeClass(t *testing.T) {
+	for _, tt := range []struct {
+		sampleRate uint32
+		packetTime time.Duration
+		want       string
+	}{
+		{48000, time.Millisecond, "1 ms"},
+		{48000, 333 * time.Microsecond, "333 µs"},
+		{96000, 125 * time.Microsecond, "125 µs"},
+		{44100, 1088 * time.Microsecond, "1 ms"},
+		{88200, 1088 * time.Microsecond, "1 ms"},
+		{192000, 125 * time.Microsecond, "125 µs"},
+		{48000, 2 * time.Millisecond, ""},
+	} {
+		if got, _ := packetTimeClass(tt.sampleRate, tt.packetTime); got != tt.want {
+			t.Errorf("packetTimeClass(%d, %s) = %q, want %q", tt.sampleRate, tt.packetTime, got, tt.want)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	report := Report{Profiles: checkProfiles(description(48000, 2, time.Millisecond), nil)}
+	report.Stream.Name = "Stage Left"
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, "text"); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	for _, want := range []string{"Stage Left", "AES67: warn", "ST 2110-30 level C", "not measured"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
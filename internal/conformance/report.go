@@ -0,0 +1,47 @@
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{"text", "json"}
+
+// Write writes the report in the given format
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// WriteText writes the report as plain text
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	e := r.Stream
+
+	fmt.Fprintf(tw, "Stream conformance report\n\n")
+	fmt.Fprintf(tw, "Stream:\t%s (%s)\n", e.Name, e.IDHash)
+	fmt.Fprintf(tw, "Format:\t%s %d Hz %d ch\n", e.ContentType, e.SampleRate, e.Channels)
+
+	for _, p := range r.Profiles {
+		fmt.Fprintf(tw, "\n%s: %s\n", p.Name, p.Status)
+
+		for _, item := range p.Items {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", item.Status, item.Name, item.Detail)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,137 @@
+// Package debug serves profiling and runtime counters over HTTP, for
+// diagnosing CPU and memory issues of long-running deployments.
+package debug
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/memory"
+	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Options holds what the counters are taken from
+type Options struct {
+	Manager   *stream.Manager
+	Collector *stats.Collector
+	Watcher   *events.Watcher // may be nil
+}
+
+// Handler serves pprof on /debug/pprof/ and expvar on /debug/vars. Besides
+// the standard memstats and cmdline, the variables include the monitor's
+// own counters.
+type Handler struct {
+	mux  *http.ServeMux
+	vars []expvar.KeyValue
+}
+
+// NewHandler creates a new debug handler
+func NewHandler(opts Options) *Handler {
+	h := &Handler{mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	h.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	h.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	h.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	h.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	h.mux.HandleFunc("/debug/vars", h.serveVars)
+
+	h.vars = []expvar.KeyValue{
+		{Key: "goroutines", Value: expvar.Func(func() any {
+			return runtime.NumGoroutine()
+		})},
+		{Key: "streams", Value: expvar.Func(func() any {
+			return opts.Manager.Count()
+		})},
+		{Key: "multicast-consumers", Value: expvar.Func(func() any {
+			return opts.Manager.ConsumerCount()
+		})},
+		{Key: "stream-feeds", Value: expvar.Func(func() any {
+			return opts.Manager.FeedCount()
+		})},
+		{Key: "stream-queue-drops", Value: expvar.Func(func() any {
+			return opts.Manager.QueueDrops()
+		})},
+		{Key: "stats-collectors", Value: expvar.Func(func() any {
+			return opts.Collector.Count()
+		})},
+		{Key: "memory-budget", Value: expvar.Func(func() any {
+			return map[string]any{
+				"limit":    memory.Limit(),
+				"used":     memory.Used(),
+				"accounts": memory.Accounts(),
+			}
+		})},
+		{Key: "ring-buffers", Value: expvar.Func(func() any {
+			buffers, elements, capacity := ring.Stats()
+
+			return map[string]int64{
+				"buffers":  buffers,
+				"elements": elements,
+				"capacity": capacity,
+			}
+		})},
+	}
+
+	if opts.Watcher != nil {
+		h.vars = append(h.vars, expvar.KeyValue{Key: "event-subscribers", Value: expvar.Func(func() any {
+			return opts.Watcher.SubscriberCount()
+		})})
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// serveVars writes the published variables and the monitor's counters as
+// one JSON object, as expvar.Handler does
+func (h *Handler) serveVars(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	first := true
+	write := func(kv expvar.KeyValue) {
+		if !first {
+			fmt.Fprint(w, ",\n")
+		}
+
+		first = false
+		fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+	}
+
+	fmt.Fprint(w, "{\n")
+	expvar.Do(write)
+
+	for _, kv := range h.vars {
+		write(kv)
+	}
+
+	fmt.Fprint(w, "\n}\n")
+}
+
+// Serve listens on the given address and serves in the background.
+// Listening errors are returned, later errors are reported to errorFn.
+func (h *Handler) Serve(addr string, errorFn func(error)) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	go func() {
+		if err := http.Serve(l, h); err != nil {
+			errorFn(err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+func TestVars(t *testing.T) {
+	h := NewHandler(Options{
+		Manager:   stream.NewManager(t.Context(), nil),
+		Collector: stats.NewCollector(),
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, rec.Body)
+	}
+
+	for _, key := range []string{"memstats", "goroutines", "streams", "multicast-consumers", "stream-feeds", "stream-queue-drops", "stats-collectors", "memory-budget", "ring-buffers"} {
+		if _, ok := vars[key]; !ok {
+			t.Errorf("missing %s", key)
+		}
+	}
+
+	if _, ok := vars["event-subscribers"]; ok {
+		t.Errorf("event-subscribers without watcher")
+	}
+}
+
+func TestPprof(t *testing.T) {
+	h := NewHandler(Options{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d", rec.Code)
+	}
+}
@@ -0,0 +1,345 @@
+package emberplus
+
+import (
+	"errors"
+	"math"
+)
+
+// BER tag classes
+const (
+	classUniversal   = 0x00
+	classApplication = 0x40
+	classContext     = 0x80
+)
+
+// Universal tags used by Glow
+const (
+	tagBoolean     = 1
+	tagInteger     = 2
+	tagReal        = 9
+	tagUTF8String  = 12
+	tagRelativeOID = 13
+	tagSequence    = 16
+	tagSet         = 17
+)
+
+var errMalformed = errors.New("malformed BER data")
+
+// tlv is a decoded BER element. Constructed elements hold their decoded
+// children, primitive elements their content.
+type tlv struct {
+	class       byte
+	constructed bool
+	tag         int
+	content     []byte
+	children    []tlv
+}
+
+// is reports whether the element has the given class and tag
+func (t tlv) is(class byte, tag int) bool {
+	return t.class == class && t.tag == tag
+}
+
+// child returns the first child with the given class and tag
+func (t tlv) child(class byte, tag int) (tlv, bool) {
+	for _, c := range t.children {
+		if c.is(class, tag) {
+			return c, true
+		}
+	}
+
+	return tlv{}, false
+}
+
+// decodeTLV decodes one element and returns the remaining data. Both
+// definite and indefinite lengths are supported.
+func decodeTLV(b []byte) (tlv, []byte, error) {
+	if len(b) < 2 {
+		return tlv{}, nil, errMalformed
+	}
+
+	t := tlv{
+		class:       b[0] & 0xc0,
+		constructed: b[0]&0x20 != 0,
+		tag:         int(b[0] & 0x1f),
+	}
+	b = b[1:]
+
+	if t.tag == 0x1f {
+		t.tag = 0
+
+		for {
+			if len(b) == 0 || t.tag > math.MaxInt32>>7 {
+				return tlv{}, nil, errMalformed
+			}
+
+			t.tag = t.tag<<7 | int(b[0]&0x7f)
+			more := b[0]&0x80 != 0
+			b = b[1:]
+
+			if !more {
+				break
+			}
+		}
+	}
+
+	if len(b) == 0 {
+		return tlv{}, nil, errMalformed
+	}
+
+	length := int(b[0])
+	b = b[1:]
+
+	if length == 0x80 {
+		// Indefinite length, children up to the end-of-contents marker
+		if !t.constructed {
+			return tlv{}, nil, errMalformed
+		}
+
+		for {
+			if len(b) >= 2 && b[0] == 0 && b[1] == 0 {
+				return t, b[2:], nil
+			}
+
+			child, rest, err := decodeTLV(b)
+			if err != nil {
+				return tlv{}, nil, err
+			}
+
+			t.children = append(t.children, child)
+			b = rest
+		}
+	}
+
+	if length > 0x80 {
+		n := length & 0x7f
+		if n > 4 || len(b) < n {
+			return tlv{}, nil, errMalformed
+		}
+
+		length = 0
+		for _, c := range b[:n] {
+			length = length<<8 | int(c)
+		}
+
+		b = b[n:]
+	}
+
+	if length > len(b) {
+		return tlv{}, nil, errMalformed
+	}
+
+	t.content, b = b[:length], b[length:]
+
+	if t.constructed {
+		for content := t.content; len(content) > 0; {
+			child, rest, err := decodeTLV(content)
+			if err != nil {
+				return tlv{}, nil, err
+			}
+
+			t.children = append(t.children, child)
+			content = rest
+		}
+	}
+
+	return t, b, nil
+}
+
+// decodeInteger decodes the content of an INTEGER
+func decodeInteger(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, errMalformed
+	}
+
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+
+	return v, nil
+}
+
+// decodeRelativeOID decodes the content of a RELATIVE-OID
+func decodeRelativeOID(b []byte) ([]int, error) {
+	var (
+		oid []int
+		v   int
+	)
+
+	for i, c := range b {
+		v = v<<7 | int(c&0x7f)
+
+		if c&0x80 == 0 {
+			oid = append(oid, v)
+			v = 0
+		} else if i == len(b)-1 {
+			return nil, errMalformed
+		}
+	}
+
+	return oid, nil
+}
+
+// encodeTLV encodes an element with the given identifier octets
+func encodeTLV(identifier []byte, content []byte) []byte {
+	b := append([]byte(nil), identifier...)
+
+	switch n := len(content); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n < 0x100:
+		b = append(b, 0x81, byte(n))
+	case n < 0x10000:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+
+	return append(b, content...)
+}
+
+// identifier returns the identifier octets of a tag
+func identifier(class byte, constructed bool, tag int) []byte {
+	first := class
+	if constructed {
+		first |= 0x20
+	}
+
+	if tag < 0x1f {
+		return []byte{first | byte(tag)}
+	}
+
+	var b []byte
+	for v := tag; v > 0; v >>= 7 {
+		b = append([]byte{byte(v&0x7f) | 0x80}, b...)
+	}
+	b[len(b)-1] &= 0x7f
+
+	return append([]byte{first | 0x1f}, b...)
+}
+
+func concat(parts [][]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+
+	return b
+}
+
+// contextTag encodes an explicitly tagged context-specific element
+func contextTag(tag int, parts ...[]byte) []byte {
+	return encodeTLV(identifier(classContext, true, tag), concat(parts))
+}
+
+// applicationTag encodes an implicitly tagged application element
+func applicationTag(tag int, parts ...[]byte) []byte {
+	return encodeTLV(identifier(classApplication, true, tag), concat(parts))
+}
+
+func berSet(parts ...[]byte) []byte {
+	return encodeTLV(identifier(classUniversal, true, tagSet), concat(parts))
+}
+
+func berBoolean(v bool) []byte {
+	if v {
+		return []byte{tagBoolean, 1, 0xff}
+	}
+
+	return []byte{tagBoolean, 1, 0}
+}
+
+// twosComplement returns the shortest two's complement encoding of v
+func twosComplement(v int64) []byte {
+	b := []byte{byte(v)}
+
+	for v >>= 8; ; v >>= 8 {
+		// Stop once the remaining bits are just the sign extension
+		if (v == 0 && b[0]&0x80 == 0) || (v == -1 && b[0]&0x80 != 0) {
+			return b
+		}
+
+		b = append([]byte{byte(v)}, b...)
+	}
+}
+
+func berInteger(v int64) []byte {
+	return encodeTLV([]byte{tagInteger}, twosComplement(v))
+}
+
+func berString(s string) []byte {
+	return encodeTLV([]byte{tagUTF8String}, []byte(s))
+}
+
+func berRelativeOID(oid []int) []byte {
+	var content []byte
+
+	for _, v := range oid {
+		var component []byte
+		for {
+			component = append([]byte{byte(v & 0x7f)}, component...)
+			v >>= 7
+
+			if v == 0 {
+				break
+			}
+		}
+
+		for i := range len(component) - 1 {
+			component[i] |= 0x80
+		}
+
+		content = append(content, component...)
+	}
+
+	return encodeTLV([]byte{tagRelativeOID}, content)
+}
+
+// berReal encodes a REAL in binary form with base 2
+func berReal(v float64) []byte {
+	switch {
+	case v == 0:
+		return []byte{tagReal, 0}
+	case math.IsInf(v, 1):
+		return []byte{tagReal, 1, 0x40}
+	case math.IsInf(v, -1):
+		return []byte{tagReal, 1, 0x41}
+	case math.IsNaN(v):
+		return []byte{tagReal, 1, 0x42}
+	}
+
+	first := byte(0x80)
+	if v < 0 {
+		first |= 0x40
+		v = -v
+	}
+
+	frac, exp := math.Frexp(v)
+	mantissa := uint64(math.Ldexp(frac, 53))
+	exp -= 53
+
+	for mantissa&1 == 0 {
+		mantissa >>= 1
+		exp++
+	}
+
+	exponent := twosComplement(int64(exp))
+
+	switch len(exponent) {
+	case 1, 2, 3:
+		first |= byte(len(exponent) - 1)
+	default:
+		first |= 0x03
+		exponent = append([]byte{byte(len(exponent))}, exponent...)
+	}
+
+	content := append([]byte{first}, exponent...)
+
+	var m []byte
+	for ; mantissa > 0; mantissa >>= 8 {
+		m = append([]byte{byte(mantissa)}, m...)
+	}
+
+	return encodeTLV([]byte{tagReal}, append(content, m...))
+}
@@ -0,0 +1,74 @@
+package emberplus
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestBERReal(t *testing.T) {
+	for v, want := range map[float64][]byte{
+		0:    {},
+		1:    {0x80, 0x00, 0x01},
+		0.5:  {0x80, 0xff, 0x01},
+		-3:   {0xc0, 0x00, 0x03},
+		1000: {0x80, 0x03, 0x7d},
+	} {
+		got := berReal(v)
+
+		if got[0] != tagReal || !bytes.Equal(got[2:], want) {
+			t.Errorf("berReal(%v) = % x, want content % x", v, got, want)
+		}
+	}
+
+	if got := berReal(math.Inf(1)); !bytes.Equal(got, []byte{tagReal, 1, 0x40}) {
+		t.Errorf("berReal(+Inf) = % x", got)
+	}
+}
+
+func TestBERInteger(t *testing.T) {
+	for _, v := range []int64{0, 1, 127, 128, -1, -128, -129, 1 << 40, math.MinInt64} {
+		b := berInteger(v)
+
+		tlv, rest, err := decodeTLV(b)
+		if err != nil || len(rest) > 0 {
+			t.Fatalf("decodeTLV(% x) failed: %v", b, err)
+		}
+
+		if got, err := decodeInteger(tlv.content); err != nil || got != v {
+			t.Errorf("got %d (%v), want %d", got, err, v)
+		}
+	}
+}
+
+func TestBERRelativeOID(t *testing.T) {
+	want := []int{1, 200, 3}
+
+	tlv, _, err := decodeTLV(berRelativeOID(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodeRelativeOID(tlv.content)
+	if err != nil || len(got) != 3 || got[0] != 1 || got[1] != 200 || got[2] != 3 {
+		t.Errorf("got %v (%v), want %v", got, err, want)
+	}
+}
+
+func TestDecodeIndefiniteLength(t *testing.T) {
+	// [0] { INTEGER 5 } with indefinite length, followed by another element
+	b := []byte{0xa0, 0x80, 0x02, 0x01, 0x05, 0x00, 0x00, 0x01, 0x01, 0xff}
+
+	tlv, rest, err := decodeTLV(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tlv.is(classContext, 0) || len(tlv.children) != 1 || tlv.children[0].content[0] != 5 {
+		t.Errorf("unexpected element %+v", tlv)
+	}
+
+	if !bytes.Equal(rest, []byte{0x01, 0x01, 0xff}) {
+		t.Errorf("unexpected rest % x", rest)
+	}
+}
@@ -0,0 +1,275 @@
+package emberplus
+
+import "fmt"
+
+// Glow application tags
+const (
+	glowRoot                  = 0
+	glowParameter             = 1
+	glowCommand               = 2
+	glowNode                  = 3
+	glowElementCollection     = 4
+	glowQualifiedParameter    = 9
+	glowQualifiedNode         = 10
+	glowRootElementCollection = 11
+)
+
+// Glow command numbers
+const (
+	commandSubscribe    = 30
+	commandUnsubscribe  = 31
+	commandGetDirectory = 32
+)
+
+// Glow parameter types
+const (
+	parameterInteger = 1
+	parameterReal    = 2
+	parameterString  = 3
+	parameterBoolean = 4
+)
+
+// parameterAccessRead makes parameters read-only
+const parameterAccessRead = 1
+
+// element is a node or a parameter of the tree. Parameters have a value of
+// type int64, float64, string or bool.
+type element struct {
+	number      int
+	identifier  string
+	description string
+	online      bool
+	value       any
+	children    []*element
+}
+
+func (e *element) isNode() bool {
+	return e.value == nil
+}
+
+// find returns the element at a path below e
+func (e *element) find(path []int) *element {
+	if len(path) == 0 {
+		return e
+	}
+
+	for _, c := range e.children {
+		if c.number == path[0] {
+			return c.find(path[1:])
+		}
+	}
+
+	return nil
+}
+
+func node(number int, identifier, description string, children ...*element) *element {
+	return &element{
+		number:      number,
+		identifier:  identifier,
+		description: description,
+		online:      true,
+		children:    children,
+	}
+}
+
+func parameter(number int, identifier string, value any) *element {
+	return &element{
+		number:     number,
+		identifier: identifier,
+		online:     true,
+		value:      value,
+	}
+}
+
+// contents encodes the contents of an element
+func (e *element) contents() []byte {
+	fields := [][]byte{contextTag(0, berString(e.identifier))}
+
+	if e.description != "" {
+		fields = append(fields, contextTag(1, berString(e.description)))
+	}
+
+	if e.isNode() {
+		fields = append(fields, contextTag(3, berBoolean(e.online)))
+		return berSet(fields...)
+	}
+
+	var (
+		value []byte
+		typ   int
+	)
+
+	switch v := e.value.(type) {
+	case int64:
+		value, typ = berInteger(v), parameterInteger
+	case float64:
+		value, typ = berReal(v), parameterReal
+	case string:
+		value, typ = berString(v), parameterString
+	case bool:
+		value, typ = berBoolean(v), parameterBoolean
+	default:
+		panic(fmt.Sprintf("unsupported parameter value %T", v))
+	}
+
+	fields = append(fields,
+		contextTag(2, value),
+		contextTag(5, berInteger(parameterAccessRead)),
+		contextTag(9, berBoolean(e.online)),
+		contextTag(13, berInteger(int64(typ))))
+
+	return berSet(fields...)
+}
+
+// encode encodes an element with its contents but without children
+func (e *element) encode() []byte {
+	tag := glowParameter
+	if e.isNode() {
+		tag = glowNode
+	}
+
+	return applicationTag(tag,
+		contextTag(0, berInteger(int64(e.number))),
+		contextTag(1, e.contents()))
+}
+
+// encodeQualified encodes an element at a path with its contents and the
+// given, already encoded, children
+func (e *element) encodeQualified(path []int, children [][]byte) []byte {
+	tag := glowQualifiedParameter
+	if e.isNode() {
+		tag = glowQualifiedNode
+	}
+
+	fields := [][]byte{
+		contextTag(0, berRelativeOID(path)),
+		contextTag(1, e.contents()),
+	}
+
+	if len(children) > 0 {
+		fields = append(fields, contextTag(2, elementCollection(children)))
+	}
+
+	return applicationTag(tag, fields...)
+}
+
+// elementCollection encodes an ElementCollection of encoded elements
+func elementCollection(elements [][]byte) []byte {
+	var items [][]byte
+	for _, e := range elements {
+		items = append(items, contextTag(0, e))
+	}
+
+	return applicationTag(glowElementCollection, items...)
+}
+
+// root encodes a Root with a RootElementCollection of encoded elements
+func root(elements [][]byte) []byte {
+	var items [][]byte
+	for _, e := range elements {
+		items = append(items, contextTag(0, e))
+	}
+
+	return encodeTLV(identifier(classApplication, true, glowRoot),
+		applicationTag(glowRootElementCollection, items...))
+}
+
+// request is a command of a consumer for the element at a path
+type request struct {
+	path    []int
+	command int
+}
+
+// requests extracts the commands from a Root sent by a consumer. Commands
+// can be nested in nodes or qualified nodes.
+func requests(b []byte) ([]request, error) {
+	t, _, err := decodeTLV(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.is(classApplication, glowRoot) || len(t.children) != 1 {
+		return nil, fmt.Errorf("unexpected Glow message")
+	}
+
+	collection := t.children[0]
+	if !collection.is(classApplication, glowRootElementCollection) {
+		// Streams and invocation results are not of interest
+		return nil, nil
+	}
+
+	var result []request
+
+	if err := collectRequests(nil, collection, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// collectRequests walks the elements of a collection
+func collectRequests(path []int, collection tlv, result *[]request) error {
+	for _, item := range collection.children {
+		if !item.is(classContext, 0) || len(item.children) != 1 {
+			return errMalformed
+		}
+
+		e := item.children[0]
+
+		if e.is(classApplication, glowCommand) {
+			number, ok := e.child(classContext, 0)
+			if !ok || len(number.children) != 1 {
+				return errMalformed
+			}
+
+			command, err := decodeInteger(number.children[0].content)
+			if err != nil {
+				return err
+			}
+
+			*result = append(*result, request{path: path, command: int(command)})
+
+			continue
+		}
+
+		var elementPath []int
+
+		switch {
+		case e.is(classApplication, glowNode), e.is(classApplication, glowParameter):
+			number, ok := e.child(classContext, 0)
+			if !ok || len(number.children) != 1 {
+				return errMalformed
+			}
+
+			n, err := decodeInteger(number.children[0].content)
+			if err != nil {
+				return err
+			}
+
+			elementPath = append(append([]int(nil), path...), int(n))
+
+		case e.is(classApplication, glowQualifiedNode), e.is(classApplication, glowQualifiedParameter):
+			oid, ok := e.child(classContext, 0)
+			if !ok || len(oid.children) != 1 {
+				return errMalformed
+			}
+
+			var err error
+			if elementPath, err = decodeRelativeOID(oid.children[0].content); err != nil {
+				return err
+			}
+
+		default:
+			// Matrices, functions and templates are not provided
+			continue
+		}
+
+		if children, ok := e.child(classContext, 2); ok && len(children.children) == 1 {
+			if err := collectRequests(elementPath, children.children[0], result); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
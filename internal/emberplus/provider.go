@@ -0,0 +1,416 @@
+// Package emberplus exposes the discovered streams and their statistics as
+// an Ember+ provider, for broadcast control systems that consume Ember+
+// natively. It implements the parts of S101 framing and the Glow DTD needed
+// for a read-only tree of nodes and parameters.
+package emberplus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const (
+	// updateInterval is how often subscribed consumers receive changes
+	updateInterval = time.Second
+
+	// writeTimeout is how long sending to a consumer may take
+	writeTimeout = 10 * time.Second
+)
+
+// Options holds the dependencies of the provider
+type Options struct {
+	Manager    *stream.Manager
+	Collector  *stats.Collector
+	PTPMonitor *ptp.Monitor // may be nil
+}
+
+// knownStream is a stream that has been in the tree. Streams keep their
+// number once they disappear, and are shown as offline.
+type knownStream struct {
+	number int
+	idHash string
+	name   string
+}
+
+// Provider serves the tree to Ember+ consumers
+type Provider struct {
+	opts Options
+
+	mutex   sync.Mutex
+	streams map[string]*knownStream // by stream ID
+
+	listener net.Listener
+	done     chan struct{}
+}
+
+// NewProvider creates a new provider
+func NewProvider(opts Options) *Provider {
+	return &Provider{
+		opts:    opts,
+		streams: make(map[string]*knownStream),
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve listens on the given address and serves consumers in the background.
+// Listening errors are returned, later errors are reported to errorFn.
+func (p *Provider) Serve(addr string, errorFn func(error)) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	p.listener = l
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				select {
+				case <-p.done:
+				default:
+					errorFn(err)
+				}
+
+				return
+			}
+
+			go p.serveConn(c)
+		}
+	}()
+
+	return nil
+}
+
+// Close stops serving and disconnects all consumers
+func (p *Provider) Close() {
+	close(p.done)
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+}
+
+// tree builds the current tree. The root node is the only top-level element.
+func (p *Provider) tree(now time.Time) *element {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, s := range p.opts.Manager.GetAllStreams() {
+		if _, ok := p.streams[s.ID]; !ok {
+			p.streams[s.ID] = &knownStream{number: len(p.streams) + 1}
+		}
+
+		// Names can change with new announcements
+		p.streams[s.ID].idHash = s.IDHash()
+		p.streams[s.ID].name = s.Name()
+	}
+
+	streamsNode := node(1, "streams", "Discovered streams")
+
+	for id, known := range p.streams {
+		n := node(known.number, known.idHash, known.name)
+
+		if s, ok := p.opts.Manager.GetStream(id); ok {
+			n.children = p.streamChildren(now, s)
+		} else {
+			n.online = false
+		}
+
+		streamsNode.children = append(streamsNode.children, n)
+	}
+
+	slices.SortFunc(streamsNode.children, func(a, b *element) int {
+		return a.number - b.number
+	})
+
+	ptpNode := node(2, "ptp", "PTP grandmasters by domain")
+
+	if p.opts.PTPMonitor != nil {
+		p.opts.PTPMonitor.ForEachGrandmaster(func(domain uint8, id ptp.ClockIdentity) {
+			ptpNode.children = append(ptpNode.children,
+				parameter(int(domain)+1, fmt.Sprintf("domain-%d", domain), id.String()))
+		})
+	}
+
+	return node(0, "", "", node(1, "rtp-monitor", "RTP Stream Monitor", streamsNode, ptpNode))
+}
+
+// streamChildren returns the parameters of a stream
+func (p *Provider) streamChildren(now time.Time, s *stream.Stream) []*element {
+	snapshot, collected := p.opts.Collector.Snapshot(s.ID)
+
+	sources := node(6, "sources", "Statistics per source")
+
+	for i, source := range snapshot.Sources {
+		receiving := !source.LastPacket.IsZero() && now.Sub(source.LastPacket) <= events.ReceivingTimeout
+
+		sources.children = append(sources.children, node(i+1, fmt.Sprintf("source-%d", i+1), "",
+			parameter(1, "packets", int64(source.Packets)),
+			parameter(2, "packet-rate", source.PacketRate),
+			parameter(3, "bitrate", source.Bitrate),
+			parameter(4, "sequence-errors", int64(source.SequenceErrors)),
+			parameter(5, "rtp-errors", int64(source.RTPErrors)),
+			parameter(6, "jitter-ms", float64(source.Jitter.Microseconds())/1000),
+			parameter(7, "receiving", receiving),
+		))
+	}
+
+	return []*element{
+		parameter(1, "name", s.Name()),
+		parameter(2, "address", s.Address()),
+		parameter(3, "codec", s.CodecInfo()),
+		parameter(4, "discovery", s.DiscoveryLabel()),
+		parameter(5, "statistics", collected),
+		sources,
+	}
+}
+
+// consumer is the state of a connected consumer
+type consumer struct {
+	p    *Provider
+	conn net.Conn
+
+	mutex sync.Mutex
+
+	// subscriptions are the paths of the elements whose directory was
+	// requested, by key
+	subscriptions map[string][]int
+
+	// sent are the encoded elements last sent, by key
+	sent map[string][]byte
+}
+
+func pathKey(path []int) string {
+	return strings.Trim(fmt.Sprint(path), "[]")
+}
+
+func (p *Provider) serveConn(conn net.Conn) {
+	c := &consumer{
+		p:             p,
+		conn:          conn,
+		subscriptions: make(map[string][]int),
+		sent:          make(map[string][]byte),
+	}
+
+	closed := make(chan struct{})
+
+	go func() {
+		select {
+		case <-p.done:
+		case <-closed:
+		}
+
+		conn.Close()
+	}()
+
+	go func() {
+		ticker := time.NewTicker(updateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case now := <-ticker.C:
+				if err := c.update(now); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	_ = c.read()
+
+	close(closed)
+}
+
+// read processes the messages of the consumer until the connection fails
+func (c *consumer) read() error {
+	r := bufio.NewReader(c.conn)
+
+	var payload []byte
+
+	for {
+		message, err := readFrame(r)
+		if errors.Is(err, errCRC) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if len(message) < 4 || message[1] != s101MessageType {
+			continue
+		}
+
+		switch message[2] {
+		case s101CommandKeepAliveRequest:
+			c.mutex.Lock()
+			err := c.write(frame([]byte{0x00, s101MessageType, s101CommandKeepAliveReply, s101Version}))
+			c.mutex.Unlock()
+
+			if err != nil {
+				return err
+			}
+
+		case s101CommandEmber:
+			if len(message) < 7 || len(message) < 7+int(message[6]) {
+				continue
+			}
+
+			flags := message[4]
+			if flags&s101FlagEmpty != 0 {
+				continue
+			}
+
+			if flags&s101FlagFirst != 0 {
+				payload = payload[:0]
+			}
+
+			payload = append(payload, message[7+int(message[6]):]...)
+
+			if flags&s101FlagLast != 0 {
+				if err := c.handle(payload); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// write sends frames to the consumer. The caller must hold c.mutex.
+func (c *consumer) write(frames ...[]byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	for _, f := range frames {
+		if _, err := c.conn.Write(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send sends a Root with the given elements
+func (c *consumer) send(elements [][]byte) error {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	return c.write(emberFrames(root(elements))...)
+}
+
+// handle processes a Glow message. Malformed messages are ignored.
+func (c *consumer) handle(payload []byte) error {
+	reqs, err := requests(payload)
+	if err != nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tree := c.p.tree(time.Now())
+
+	var elements [][]byte
+
+	for _, req := range reqs {
+		key := pathKey(req.path)
+
+		switch req.command {
+		case commandGetDirectory, commandSubscribe:
+			if len(req.path) == 0 {
+				for _, e := range tree.children {
+					elements = append(elements, e.encode())
+				}
+
+				continue
+			}
+
+			e := tree.find(req.path)
+			if e == nil {
+				continue
+			}
+
+			c.subscriptions[key] = req.path
+			elements = append(elements, c.changes(req.path, e, true)...)
+
+		case commandUnsubscribe:
+			delete(c.subscriptions, key)
+		}
+	}
+
+	return c.send(elements)
+}
+
+// changes returns the elements to send for a subscribed element: its
+// children for nodes, itself for parameters. Unless all is set, only what
+// changed since it was last sent is returned.
+func (c *consumer) changes(path []int, e *element, all bool) [][]byte {
+	if !e.isNode() {
+		encoded := e.contents()
+		key := pathKey(path)
+
+		if !all && slices.Equal(c.sent[key], encoded) {
+			return nil
+		}
+
+		c.sent[key] = encoded
+
+		return [][]byte{e.encodeQualified(path, nil)}
+	}
+
+	var children [][]byte
+
+	for _, child := range e.children {
+		encoded := child.encode()
+		key := pathKey(append(slices.Clone(path), child.number))
+
+		if !all && slices.Equal(c.sent[key], encoded) {
+			continue
+		}
+
+		c.sent[key] = encoded
+		children = append(children, encoded)
+	}
+
+	if len(children) == 0 && !all {
+		return nil
+	}
+
+	return [][]byte{e.encodeQualified(path, children)}
+}
+
+// update sends the changes of all subscribed elements
+func (c *consumer) update(now time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if len(c.subscriptions) == 0 {
+		return nil
+	}
+
+	tree := c.p.tree(now)
+
+	var elements [][]byte
+
+	for _, path := range c.subscriptions {
+		if e := tree.find(path); e != nil {
+			elements = append(elements, c.changes(path, e, false)...)
+		}
+	}
+
+	return c.send(elements)
+}
@@ -0,0 +1,163 @@
+package emberplus
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+func TestFrame(t *testing.T) {
+	message := []byte{0x00, 0x0e, 0x00, 0x01, 0xfe, 0xff, 0xfd, 0x42}
+
+	got, err := readFrame(bufio.NewReader(bytes.NewReader(frame(message))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(message) {
+		t.Errorf("got % x, want % x", got, message)
+	}
+
+	corrupted := frame(message)
+	corrupted[2] ^= 0x01
+
+	if _, err := readFrame(bufio.NewReader(bytes.NewReader(corrupted))); err != errCRC {
+		t.Errorf("expected a CRC error, got %v", err)
+	}
+}
+
+func TestEmberFramesSplit(t *testing.T) {
+	frames := emberFrames(make([]byte, 2*s101MaxPayload+1))
+
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(frames))
+	}
+
+	for i, want := range []byte{s101FlagFirst, 0, s101FlagLast} {
+		message, err := readFrame(bufio.NewReader(bytes.NewReader(frames[i])))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if message[4] != want {
+			t.Errorf("frame %d: got flags %02x, want %02x", i, message[4], want)
+		}
+	}
+}
+
+// receive reads the next Glow message from the provider
+func receive(t *testing.T, r *bufio.Reader) tlv {
+	t.Helper()
+
+	message, err := readFrame(r)
+	if err != nil {
+		t.Fatalf("readFrame() failed: %v", err)
+	}
+
+	root, _, err := decodeTLV(message[9:])
+	if err != nil {
+		t.Fatalf("invalid Glow message: %v", err)
+	}
+
+	// Root > RootElementCollection
+	return root.children[0]
+}
+
+// identifiers returns the identifiers of the elements in a collection
+func identifiers(t *testing.T, collection tlv) []string {
+	t.Helper()
+
+	var result []string
+
+	for _, item := range collection.children {
+		contents, ok := item.children[0].child(classContext, 1)
+		if !ok {
+			t.Fatal("element without contents")
+		}
+
+		identifier, _ := contents.children[0].child(classContext, 0)
+		result = append(result, string(identifier.children[0].content))
+	}
+
+	return result
+}
+
+func TestProvider(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "test.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	p := NewProvider(Options{Manager: manager, Collector: stats.NewCollector()})
+	defer p.Close()
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go p.serveConn(server)
+
+	r := bufio.NewReader(client)
+	getDirectory := applicationTag(glowCommand, contextTag(0, berInteger(commandGetDirectory)))
+
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	for _, f := range emberFrames(root([][]byte{getDirectory})) {
+		if _, err := client.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := identifiers(t, receive(t, r)); len(got) != 1 || got[0] != "rtp-monitor" {
+		t.Errorf("unexpected root elements %v", got)
+	}
+
+	// Directory of rtp-monitor/streams, as qualified node
+	request := applicationTag(glowQualifiedNode,
+		contextTag(0, berRelativeOID([]int{1, 1})),
+		contextTag(2, elementCollection([][]byte{getDirectory})))
+
+	for _, f := range emberFrames(root([][]byte{request})) {
+		if _, err := client.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	qualified := receive(t, r).children[0].children[0]
+	if !qualified.is(classApplication, glowQualifiedNode) {
+		t.Fatalf("expected a qualified node, got tag %d", qualified.tag)
+	}
+
+	children, _ := qualified.child(classContext, 2)
+	if got := identifiers(t, children.children[0]); len(got) != 1 || got[0] != s.IDHash() {
+		t.Errorf("unexpected streams %v", got)
+	}
+
+	// The removed stream is sent again, now offline
+	manager.RemoveStream(s.ID)
+
+	qualified = receive(t, r).children[0].children[0]
+	children, _ = qualified.child(classContext, 2)
+
+	contents, _ := children.children[0].children[0].children[0].child(classContext, 1)
+	online, ok := contents.children[0].child(classContext, 3)
+	if !ok || online.children[0].content[0] != 0 {
+		t.Errorf("expected the stream to be offline")
+	}
+}
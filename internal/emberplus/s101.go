@@ -0,0 +1,172 @@
+package emberplus
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// S101 framing bytes
+const (
+	s101BOF = 0xfe // begin of frame
+	s101EOF = 0xff // end of frame
+	s101CE  = 0xfd // escape
+	s101Inv = 0xf8 // bytes from here on are escaped
+)
+
+// S101 message header fields
+const (
+	s101MessageType = 0x0e
+	s101Version     = 0x01
+
+	s101CommandEmber            = 0x00
+	s101CommandKeepAliveRequest = 0x01
+	s101CommandKeepAliveReply   = 0x02
+
+	s101FlagFirst = 0x80
+	s101FlagLast  = 0x40
+	s101FlagEmpty = 0x20
+
+	s101DTDGlow = 0x01
+)
+
+// glowVersion is the Glow DTD version announced in packets, 2.31
+var glowVersion = []byte{0x1f, 0x02}
+
+// s101MaxPayload is the largest Glow payload sent in one packet. Longer
+// messages are split, as consumers expect.
+const s101MaxPayload = 1024
+
+// crcTable is the table of the CRC-CCITT used by S101
+var crcTable = func() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i)
+
+		for range 8 {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+
+		table[i] = crc
+	}
+
+	return table
+}()
+
+func crc16(b []byte) uint16 {
+	crc := uint16(0xffff)
+
+	for _, c := range b {
+		crc = crc>>8 ^ crcTable[byte(crc)^c]
+	}
+
+	return crc
+}
+
+// crcGood is the CRC of a frame including its CRC
+const crcGood = 0xf0b8
+
+var errCRC = errors.New("S101 frame with invalid CRC")
+
+// appendEscaped appends a byte, escaped if needed
+func appendEscaped(b []byte, c byte) []byte {
+	if c >= s101Inv {
+		return append(b, s101CE, c^0x20)
+	}
+
+	return append(b, c)
+}
+
+// frame encodes an S101 frame with the given message
+func frame(message []byte) []byte {
+	b := []byte{s101BOF}
+
+	for _, c := range message {
+		b = appendEscaped(b, c)
+	}
+
+	crc := ^crc16(message)
+	b = appendEscaped(b, byte(crc))
+	b = appendEscaped(b, byte(crc>>8))
+
+	return append(b, s101EOF)
+}
+
+// readFrame reads the next S101 frame and returns its message
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	// Skip anything up to the start of a frame
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == s101BOF {
+			break
+		}
+	}
+
+	var b []byte
+	escaped := false
+
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return nil, err
+		}
+
+		switch {
+		case c == s101BOF:
+			// A new frame starts, drop the incomplete one
+			b, escaped = b[:0], false
+		case c == s101EOF:
+			if len(b) < 2 || crc16(b) != crcGood {
+				return nil, errCRC
+			}
+
+			return b[:len(b)-2], nil
+		case c == s101CE:
+			escaped = true
+		case escaped:
+			b = append(b, c^0x20)
+			escaped = false
+		default:
+			b = append(b, c)
+		}
+	}
+}
+
+// emberFrames encodes a Glow payload as S101 frames, split into several
+// packets if needed
+func emberFrames(payload []byte) [][]byte {
+	var frames [][]byte
+
+	for first := true; first || len(payload) > 0; first = false {
+		n := min(len(payload), s101MaxPayload)
+
+		var flags byte
+		if first {
+			flags |= s101FlagFirst
+		}
+
+		if n == len(payload) {
+			flags |= s101FlagLast
+		}
+
+		message := []byte{0x00, s101MessageType, s101CommandEmber, s101Version, flags, s101DTDGlow, byte(len(glowVersion))}
+		message = append(message, glowVersion...)
+		message = append(message, payload[:n]...)
+
+		frames = append(frames, frame(message))
+		payload = payload[n:]
+	}
+
+	return frames
+}
@@ -0,0 +1,574 @@
+// Package events watches streams, their statistics and PTP for changes and
+// distributes them as events, e.g. to WebSocket clients or as structured
+// output.
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Event types
+const (
+	TypeStreamAdded    = "stream-added"
+	TypeStreamRemoved  = "stream-removed"
+	TypeStreamUpdated  = "stream-updated"
+	TypeAlert          = "alert"
+	TypePTPGrandmaster = "ptp-grandmaster"
+	TypeStats          = "stats"
+
+	TypeRecordingStarted = "recording-started"
+	TypeRecordingStopped = "recording-stopped"
+)
+
+// Types lists all event types
+var Types = []string{
+	TypeStreamAdded, TypeStreamRemoved, TypeStreamUpdated,
+	TypeAlert, TypePTPGrandmaster, TypeStats,
+	TypeRecordingStarted, TypeRecordingStopped,
+}
+
+const (
+	// checkInterval is how often statistics, alerts and PTP changes are checked
+	checkInterval = time.Second
+
+	// subscriberBuffer is the number of events queued per subscriber before
+	// events are dropped for it
+	subscriberBuffer = 256
+
+	// ReceivingTimeout is the time without packets after which a stream is
+	// no longer considered receiving, as in the Status column of the UI
+	ReceivingTimeout = 2 * time.Second
+
+	// discoveryWindow is how long after the start expected streams may take
+	// to be discovered before they are reported missing. SAP announcements
+	// usually repeat every 30 seconds.
+	discoveryWindow = 40 * time.Second
+)
+
+// Event is a change of a stream, an alert or a statistics update
+type Event struct {
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+	IDHash string    `json:"id-hash,omitempty"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// Alert is the data of an alert event
+type Alert struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+
+	// Metric is the name of the metric that raised the alert, and Value its
+	// offending value
+	Metric string  `json:"metric,omitempty"`
+	Value  float64 `json:"value,omitempty"`
+}
+
+// GrandmasterChange is the data of a PTP grandmaster event
+type GrandmasterChange struct {
+	Domain      uint8  `json:"domain"`
+	Grandmaster string `json:"grandmaster"`
+	Previous    string `json:"previous,omitempty"`
+}
+
+// Recording is the data of a recording event
+type Recording struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files,omitempty"`
+
+	// RecordedSeconds is the length of the recorded audio, set once stopped
+	RecordedSeconds float64 `json:"recorded-s,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// NewRecording converts a change of a recording
+func NewRecording(c recorder.Change) Recording {
+	r := Recording{
+		Name:            c.Stream.Name(),
+		Files:           c.Files,
+		RecordedSeconds: c.Recorded.Seconds(),
+	}
+
+	if c.Err != nil {
+		r.Error = c.Err.Error()
+	}
+
+	return r
+}
+
+// SourceStats is the statistics of a stream source
+type SourceStats struct {
+	Packets        uint64     `json:"packets"`
+	Bytes          uint64     `json:"bytes"`
+	PacketRate     float64    `json:"packet-rate"`
+	Bitrate        float64    `json:"bitrate"`
+	SequenceErrors uint64     `json:"sequence-errors"`
+	Lost           int64      `json:"lost"`
+	Reordered      uint64     `json:"reordered"`
+	RTPErrors      uint64     `json:"rtp-errors"`
+	JitterSeconds  float64    `json:"jitter-s"`
+	LastPacket     *time.Time `json:"last-packet,omitempty"`
+}
+
+// Stats is the data of a statistics event
+type Stats struct {
+	Started time.Time     `json:"started"`
+	Sources []SourceStats `json:"sources"`
+}
+
+// NewStats converts a statistics snapshot
+func NewStats(snapshot stats.Snapshot) Stats {
+	result := Stats{
+		Started: snapshot.Started,
+		Sources: make([]SourceStats, len(snapshot.Sources)),
+	}
+
+	for i, source := range snapshot.Sources {
+		result.Sources[i] = SourceStats{
+			Packets:        source.Packets,
+			Bytes:          source.Bytes,
+			PacketRate:     source.PacketRate,
+			Bitrate:        source.Bitrate,
+			SequenceErrors: source.SequenceErrors,
+			Lost:           source.Lost,
+			Reordered:      source.Reordered,
+			RTPErrors:      source.RTPErrors,
+			JitterSeconds:  source.Jitter.Seconds(),
+		}
+
+		if !source.LastPacket.IsZero() {
+			result.Sources[i].LastPacket = &source.LastPacket
+		}
+	}
+
+	return result
+}
+
+// Options holds the sources of events
+type Options struct {
+	Manager    *stream.Manager
+	Collector  *stats.Collector
+	PTPMonitor *ptp.Monitor // may be nil
+
+	// SequenceErrorThreshold is the number of new sequence errors per second
+	// that raises an alert
+	SequenceErrorThreshold int
+}
+
+// Watcher publishes stream changes as they happen and checks statistics,
+// alerts and PTP grandmasters periodically
+type Watcher struct {
+	manager                *stream.Manager
+	collector              *stats.Collector
+	ptpMonitor             *ptp.Monitor
+	sequenceErrorThreshold uint64
+
+	subscribersMutex sync.Mutex
+	subscribers      map[chan Event]struct{}
+
+	// State of the last check, to publish changes only
+	mutex          sync.Mutex
+	streams        map[string]inventory.Entry
+	sequenceErrors map[string]uint64
+	silent         map[string]bool
+	missing        map[string]bool
+	collisions     map[uint32]bool
+	grandmasters   map[uint8]string
+
+	stopObserving func()
+	started       time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWatcher creates a new watcher and starts watching
+func NewWatcher(opts Options) *Watcher {
+	w := &Watcher{
+		manager:                opts.Manager,
+		collector:              opts.Collector,
+		ptpMonitor:             opts.PTPMonitor,
+		sequenceErrorThreshold: uint64(max(opts.SequenceErrorThreshold, 0)),
+		subscribers:            make(map[chan Event]struct{}),
+		streams:                make(map[string]inventory.Entry),
+		sequenceErrors:         make(map[string]uint64),
+		silent:                 make(map[string]bool),
+		missing:                make(map[string]bool),
+		collisions:             make(map[uint32]bool),
+		grandmasters:           make(map[uint8]string),
+		done:                   make(chan struct{}),
+		started:                time.Now(),
+	}
+
+	streams := w.manager.GetAllStreams()
+
+	for _, e := range inventory.FromStreams(streams) {
+		w.streams[e.ID] = e
+	}
+
+	w.collectExpected(streams)
+
+	w.manager.OnUpdate(func(streams []*stream.Stream) {
+		w.streamsUpdated(time.Now(), streams)
+	})
+
+	w.manager.OnInterruption(w.interrupted)
+
+	w.stopObserving = recorder.Observe(func(c recorder.Change) {
+		eventType := TypeRecordingStopped
+		if c.Started {
+			eventType = TypeRecordingStarted
+		}
+
+		w.publish(Event{Type: eventType, Time: time.Now(), IDHash: c.Stream.IDHash(), Data: NewRecording(c)})
+	})
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.done:
+				return
+			case now := <-ticker.C:
+				w.check(now)
+			}
+		}
+	}()
+
+	return w
+}
+
+// Close stops watching. The channels of subscribers are not closed.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		w.stopObserving()
+		close(w.done)
+	})
+}
+
+// Done returns a channel that is closed when the watcher is closed
+func (w *Watcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// Subscribe returns a channel that receives all events published from now on
+func (w *Watcher) Subscribe() chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	w.subscribersMutex.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.subscribersMutex.Unlock()
+
+	return ch
+}
+
+// SubscribeWithStreams is like Subscribe, but the channel first receives a
+// stream-added event for each stream that is already known
+func (w *Watcher) SubscribeWithStreams() chan Event {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	ch := make(chan Event, len(w.streams)+subscriberBuffer)
+
+	for _, e := range w.streams {
+		ch <- Event{Type: TypeStreamAdded, Time: now, IDHash: e.IDHash, Data: e}
+	}
+
+	w.subscribersMutex.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.subscribersMutex.Unlock()
+
+	return ch
+}
+
+// Unsubscribe stops sending events to a channel returned by Subscribe
+func (w *Watcher) Unsubscribe(ch chan Event) {
+	w.subscribersMutex.Lock()
+	delete(w.subscribers, ch)
+	w.subscribersMutex.Unlock()
+}
+
+// subscribed reports whether anybody is subscribed
+func (w *Watcher) subscribed() bool {
+	return w.SubscriberCount() > 0
+}
+
+// SubscriberCount returns the number of subscribed channels
+func (w *Watcher) SubscriberCount() int {
+	w.subscribersMutex.Lock()
+	defer w.subscribersMutex.Unlock()
+
+	return len(w.subscribers)
+}
+
+// publish sends an event to all subscribers. Subscribers that do not keep
+// up miss events rather than blocking the others.
+func (w *Watcher) publish(event Event) {
+	w.subscribersMutex.Lock()
+	defer w.subscribersMutex.Unlock()
+
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// sameStream reports whether two entries describe the same stream setup.
+// Announcement times are ignored, as they change with every announcement.
+func sameStream(a, b inventory.Entry) bool {
+	a.LastAnnounced = nil
+	b.LastAnnounced = nil
+
+	return reflect.DeepEqual(a, b)
+}
+
+// collectExpected starts collecting statistics for the expected streams, so
+// they raise an alert when they stop even if nothing else receives them
+func (w *Watcher) collectExpected(streams []*stream.Stream) {
+	for _, s := range streams {
+		if w.manager.IsExpected(s.IDHash()) {
+			if err := w.collector.Start(s); err != nil {
+				slog.Warn("Failed to collect statistics of an expected stream", "stream", s.Name(), "error", err)
+			}
+		}
+	}
+}
+
+// streamsUpdated publishes added, changed and removed streams, and an alert
+// for expected streams that disappeared
+func (w *Watcher) streamsUpdated(now time.Time, streams []*stream.Stream) {
+	w.collectExpected(streams)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	current := make(map[string]inventory.Entry, len(streams))
+
+	for _, e := range inventory.FromStreams(streams) {
+		current[e.ID] = e
+
+		previous, ok := w.streams[e.ID]
+		switch {
+		case !ok:
+			w.publish(Event{Type: TypeStreamAdded, Time: now, IDHash: e.IDHash, Data: e})
+		case !sameStream(previous, e):
+			w.publish(Event{Type: TypeStreamUpdated, Time: now, IDHash: e.IDHash, Data: e})
+		}
+	}
+
+	for id, e := range w.streams {
+		if _, ok := current[id]; !ok {
+			w.publish(Event{Type: TypeStreamRemoved, Time: now, IDHash: e.IDHash, Data: e})
+
+			if w.manager.IsExpected(e.IDHash) {
+				w.missing[e.IDHash] = true
+				w.publish(Event{Type: TypeAlert, Time: now, IDHash: e.IDHash, Data: Alert{
+					Level:   "error",
+					Message: fmt.Sprintf("%s: expected stream disappeared", e.Name),
+					Metric:  "expected-stream-missing",
+					Value:   1,
+				}})
+			}
+		}
+	}
+
+	w.streams = current
+}
+
+// checkMissing publishes an alert for each expected stream that was not
+// discovered since the start, once the discovery window has passed. The
+// caller must hold the mutex.
+func (w *Watcher) checkMissing(now time.Time) {
+	present := make(map[string]bool, len(w.streams))
+	for _, e := range w.streams {
+		present[e.IDHash] = true
+	}
+
+	for idHash := range w.missing {
+		if present[idHash] {
+			delete(w.missing, idHash)
+		}
+	}
+
+	if now.Sub(w.started) < discoveryWindow {
+		return
+	}
+
+	for _, idHash := range w.manager.ExpectedIDs() {
+		if present[idHash] || w.missing[idHash] {
+			continue
+		}
+
+		w.missing[idHash] = true
+		w.publish(Event{Type: TypeAlert, Time: now, IDHash: idHash, Data: Alert{
+			Level:   "error",
+			Message: fmt.Sprintf("%s: expected stream not discovered", idHash),
+			Metric:  "expected-stream-missing",
+			Value:   1,
+		}})
+	}
+}
+
+// interrupted publishes an alert for each stream that receives a group whose
+// delivery was interrupted, distinct from the losses of the sender
+func (w *Watcher) interrupted(i stream.Interruption) {
+	detail := ""
+	if i.Elsewhere {
+		detail = ", other interfaces kept receiving"
+	}
+
+	for _, s := range w.manager.GetAllStreams() {
+		if !s.ReceivesGroup(i.Group) {
+			continue
+		}
+
+		w.publish(Event{Type: TypeAlert, Time: i.End, IDHash: s.IDHash(), Data: Alert{
+			Level: "warning",
+			Message: fmt.Sprintf("%s: multicast delivery interruption of %s on %s for %s, %d packets lost%s",
+				s.Name(), i.Group, i.Interface, i.Duration().Round(time.Millisecond), i.Lost, detail),
+			Metric: "delivery-interruption-s",
+			Value:  i.Duration().Seconds(),
+		}})
+	}
+}
+
+// check publishes statistics, alerts and PTP grandmaster changes
+func (w *Watcher) check(now time.Time) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	publishStats := w.subscribed()
+
+	for _, s := range w.manager.GetAllStreams() {
+		snapshot, ok := w.collector.Snapshot(s.ID)
+		if !ok {
+			delete(w.sequenceErrors, s.ID)
+			delete(w.silent, s.ID)
+			continue
+		}
+
+		if publishStats {
+			w.publish(Event{Type: TypeStats, Time: now, IDHash: s.IDHash(), Data: NewStats(snapshot)})
+		}
+
+		n := snapshot.SequenceErrors()
+		if last, ok := w.sequenceErrors[s.ID]; ok && w.sequenceErrorThreshold > 0 && n >= last+w.sequenceErrorThreshold {
+			w.publish(Event{Type: TypeAlert, Time: now, IDHash: s.IDHash(), Data: Alert{
+				Level:   "warning",
+				Message: fmt.Sprintf("%s: %d new sequence errors", s.Name(), n-last),
+				Metric:  "sequence-errors",
+				Value:   float64(n - last),
+			}})
+		}
+		w.sequenceErrors[s.ID] = n
+
+		// Expected streams that never delivered a packet are silent too,
+		// counted from the start of their collection
+		lastPacket := snapshot.LastPacket()
+		if lastPacket.IsZero() && w.manager.IsExpected(s.IDHash()) {
+			lastPacket = snapshot.Started
+		}
+
+		silent := !lastPacket.IsZero() && now.Sub(lastPacket) > ReceivingTimeout
+
+		if silent && !w.silent[s.ID] {
+			w.publish(Event{Type: TypeAlert, Time: now, IDHash: s.IDHash(), Data: Alert{
+				Level:   "error",
+				Message: fmt.Sprintf("%s: no packets received", s.Name()),
+				Metric:  "last-packet-age-s",
+				Value:   now.Sub(lastPacket).Seconds(),
+			}})
+		}
+		w.silent[s.ID] = silent
+	}
+
+	w.checkMissing(now)
+
+	colliding := make(map[uint32]bool)
+
+	for _, c := range w.manager.SSRCCollisions(now) {
+		if !w.collisions[c.SSRC] {
+			w.publish(Event{Type: TypeAlert, Time: now, Data: Alert{
+				Level:   "warning",
+				Message: w.manager.CollisionLabel(c),
+				Metric:  "ssrc-collision",
+				Value:   float64(c.SSRC),
+			}})
+		}
+
+		colliding[c.SSRC] = true
+	}
+
+	w.collisions = colliding
+
+	if w.ptpMonitor == nil {
+		return
+	}
+
+	w.ptpMonitor.ForEachGrandmaster(func(domain uint8, id ptp.ClockIdentity) {
+		grandmaster := id.String()
+
+		if previous := w.grandmasters[domain]; previous != grandmaster {
+			w.publish(Event{Type: TypePTPGrandmaster, Time: now, Data: GrandmasterChange{
+				Domain:      domain,
+				Grandmaster: grandmaster,
+				Previous:    previous,
+			}})
+		}
+
+		w.grandmasters[domain] = grandmaster
+	})
+}
+
+// StatsLimiter lets statistics events of each stream pass at most once per
+// interval
+type StatsLimiter struct {
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+// statsSlack allows for jitter between two statistics events
+const statsSlack = 100 * time.Millisecond
+
+// NewStatsLimiter creates a new limiter
+func NewStatsLimiter(interval time.Duration) *StatsLimiter {
+	return &StatsLimiter{
+		interval: interval,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Allow reports whether an event should be passed on. Events other than
+// statistics always are.
+func (l *StatsLimiter) Allow(e Event) bool {
+	switch e.Type {
+	case TypeStreamRemoved:
+		delete(l.last, e.IDHash)
+
+	case TypeStats:
+		// Statistics events are sent every second, give or take
+		if e.Time.Sub(l.last[e.IDHash]) < l.interval-statsSlack {
+			return false
+		}
+
+		l.last[e.IDHash] = e.Time
+	}
+
+	return true
+}
@@ -0,0 +1,234 @@
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+func receive(t *testing.T, ch chan Event) Event {
+	t.Helper()
+
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestWatcherStreams(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+
+	left, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "left.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	w := NewWatcher(Options{
+		Manager:   manager,
+		Collector: stats.NewCollector(),
+	})
+	defer w.Close()
+
+	ch := w.SubscribeWithStreams()
+	defer w.Unsubscribe(ch)
+
+	if e := receive(t, ch); e.Type != TypeStreamAdded || e.IDHash != left.IDHash() {
+		t.Errorf("expected the known stream to be replayed, got %+v", e)
+	}
+
+	right, err := manager.AddStreamFromSDP([]byte(strings.NewReplacer("o=- 1", "o=- 2", "Left", "Right").Replace(testSDP)),
+		stream.DiscoveryMethodManual, "right.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	if e := receive(t, ch); e.Type != TypeStreamAdded || e.IDHash != right.IDHash() {
+		t.Errorf("expected the new stream to be added, got %+v", e)
+	}
+
+	// A second discovery changes the stream
+	if _, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodSAP, "192.168.1.10"); err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	if e := receive(t, ch); e.Type != TypeStreamUpdated || e.IDHash != left.IDHash() {
+		t.Errorf("expected the stream to be updated, got %+v", e)
+	}
+
+	manager.RemoveStream(right.ID)
+
+	if e := receive(t, ch); e.Type != TypeStreamRemoved || e.IDHash != right.IDHash() {
+		t.Errorf("expected the stream to be removed, got %+v", e)
+	}
+}
+
+func TestWatcherExpected(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "left.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	manager.SetExpected(s.IDHash(), true)
+
+	collector := stats.NewCollector()
+	defer collector.StopAll()
+
+	w := NewWatcher(Options{
+		Manager:   manager,
+		Collector: collector,
+	})
+	defer w.Close()
+
+	// Expected streams are collected, so they alert when they stop
+	if !collector.IsRunning(s.ID) {
+		t.Error("expected stream not collected")
+	}
+
+	ch := w.Subscribe()
+	defer w.Unsubscribe(ch)
+
+	manager.RemoveStream(s.ID)
+
+	if e := receive(t, ch); e.Type != TypeStreamRemoved {
+		t.Errorf("expected the stream to be removed, got %+v", e)
+	}
+
+	e := receive(t, ch)
+	if alert, ok := e.Data.(Alert); !ok || alert.Metric != "expected-stream-missing" || e.IDHash != s.IDHash() {
+		t.Errorf("expected an alert for the expected stream, got %+v", e)
+	}
+}
+
+// receiveAlert returns the next alert with the given metric, skipping other
+// events
+func receiveAlert(t *testing.T, ch chan Event, metric string) Event {
+	t.Helper()
+
+	for {
+		e := receive(t, ch)
+		if alert, ok := e.Data.(Alert); ok && alert.Metric == metric {
+			return e
+		}
+	}
+}
+
+func TestWatcherExpectedSilent(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "left.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	manager.SetExpected(s.IDHash(), true)
+
+	collector := stats.NewCollector()
+	defer collector.StopAll()
+
+	w := NewWatcher(Options{
+		Manager:   manager,
+		Collector: collector,
+	})
+	defer w.Close()
+
+	ch := w.Subscribe()
+	defer w.Unsubscribe(ch)
+
+	// No packet is ever received
+	w.check(time.Now().Add(2 * ReceivingTimeout))
+
+	if e := receiveAlert(t, ch, "last-packet-age-s"); e.IDHash != s.IDHash() {
+		t.Errorf("expected a silence alert for the expected stream, got %+v", e)
+	}
+}
+
+func TestWatcherExpectedNotDiscovered(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+	manager.SetExpected("0123456789", true)
+
+	w := NewWatcher(Options{
+		Manager:   manager,
+		Collector: stats.NewCollector(),
+	})
+	defer w.Close()
+
+	ch := w.Subscribe()
+	defer w.Unsubscribe(ch)
+
+	w.check(w.started.Add(discoveryWindow / 2))
+	w.check(w.started.Add(discoveryWindow))
+
+	if e := receiveAlert(t, ch, "expected-stream-missing"); e.IDHash != "0123456789" || !e.Time.Equal(w.started.Add(discoveryWindow)) {
+		t.Errorf("expected an alert for the missing stream after the discovery window, got %+v", e)
+	}
+
+	// The alert is raised once
+	w.check(w.started.Add(2 * discoveryWindow))
+
+	select {
+	case e := <-ch:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestWriterLogfmt(t *testing.T) {
+	var b bytes.Buffer
+
+	w, err := NewWriter(&b, "logfmt")
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+
+	err = w.Write(Event{
+		Type:   TypeAlert,
+		Time:   time.Date(2026, 1, 12, 10, 31, 2, 0, time.UTC),
+		IDHash: "71cb8481ed",
+		Data:   Alert{Level: "error", Message: `Stage "Left": no packets received`},
+	})
+	if err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	want := `time=2026-01-12T10:31:02Z type=alert id-hash=71cb8481ed level=error message="Stage \"Left\": no packets received"` + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+
+	if err := w.Write(Event{Type: TypeStats, Data: Stats{Sources: []SourceStats{{Packets: 10}, {Packets: 20}}}}); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	for _, field := range []string{" sources.0.packets=10 ", " sources.1.packets=20 "} {
+		if !strings.Contains(b.String(), field) {
+			t.Errorf("%q does not contain %q", b.String(), field)
+		}
+	}
+}
+
+func TestWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
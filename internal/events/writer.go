@@ -0,0 +1,163 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats lists the output formats supported by Writer
+var Formats = []string{"json", "logfmt"}
+
+// Writer writes events as structured lines
+type Writer struct {
+	w      io.Writer
+	format string
+}
+
+// NewWriter creates a writer for the given format, see Formats
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("unknown format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	return &Writer{
+		w:      w,
+		format: format,
+	}, nil
+}
+
+// Write writes one event as a line
+func (w *Writer) Write(e Event) error {
+	var (
+		line []byte
+		err  error
+	)
+
+	switch w.format {
+	case "json":
+		line, err = json.Marshal(e)
+	default:
+		line, err = logfmt(e)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to format %s event: %w", e.Type, err)
+	}
+
+	line = append(line, '\n')
+
+	if _, err := w.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
+
+// logfmt formats an event as key=value pairs. The fields of the event data
+// are flattened into dotted keys, e.g. sources.0.sender.
+func logfmt(e Event) ([]byte, error) {
+	var b bytes.Buffer
+
+	seen := make(map[string]bool)
+
+	write := func(key, value string) {
+		if seen[key] {
+			return
+		}
+
+		seen[key] = true
+
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(value))
+	}
+
+	write("time", e.Time.Format(time.RFC3339Nano))
+	write("type", e.Type)
+
+	if e.IDHash != "" {
+		write("id-hash", e.IDHash)
+	}
+
+	if e.Data == nil {
+		return b.Bytes(), nil
+	}
+
+	// Go through JSON to use the same field names as the JSON output
+	j, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	flatten("", data, write)
+
+	return b.Bytes(), nil
+}
+
+// flatten calls write for all scalar values in v, with their dotted path
+// as key
+func flatten(prefix string, v any, write func(key, value string)) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+
+		return prefix + "." + key
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			flatten(join(k), v[k], write)
+		}
+
+	case []any:
+		for i, item := range v {
+			flatten(join(strconv.Itoa(i)), item, write)
+		}
+
+	case nil:
+
+	case string:
+		write(prefix, v)
+
+	default:
+		write(prefix, fmt.Sprint(v))
+	}
+}
+
+// logfmtValue quotes a value if needed
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\\") || strings.ContainsFunc(s, func(r rune) bool {
+		return r < ' ' || r == 0x7f
+	}) {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
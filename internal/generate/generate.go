@@ -0,0 +1,381 @@
+// Package generate transmits an AES67 test stream with synthetic signals, so
+// receivers and the monitor itself can be exercised without audio hardware.
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/transmit"
+	"github.com/pion/rtp/v2"
+)
+
+// Encodings lists the supported sample encodings
+var Encodings = []string{"L24", "L16"}
+
+// Signal is a test signal of a channel
+type Signal struct {
+	// Type is "sine", "noise" or "silence"
+	Type string
+
+	// Frequency of a sine in Hz
+	Frequency float64
+}
+
+// ParseSignal parses a signal given as "sine:<frequency>", "sine" for a
+// 1 kHz sine, "noise" or "silence"
+func ParseSignal(s string) (Signal, error) {
+	typ, frequency, hasFrequency := strings.Cut(s, ":")
+
+	switch typ {
+	case "sine":
+		signal := Signal{Type: typ, Frequency: 1000}
+
+		if hasFrequency {
+			f, err := strconv.ParseFloat(frequency, 64)
+			if err != nil || f <= 0 {
+				return Signal{}, fmt.Errorf("invalid frequency %q", frequency)
+			}
+
+			signal.Frequency = f
+		}
+
+		return signal, nil
+	case "noise", "silence":
+		if hasFrequency {
+			return Signal{}, fmt.Errorf("signal %s takes no frequency", typ)
+		}
+
+		return Signal{Type: typ}, nil
+	default:
+		return Signal{}, fmt.Errorf("unknown signal %q, must be sine, noise or silence", s)
+	}
+}
+
+// Options configures a generator
+type Options struct {
+	// Name of the session in the SDP
+	Name string
+
+	Destination *net.UDPAddr
+	Interface   *net.Interface // may be nil for the default route
+
+	Encoding   string
+	SampleRate uint32
+	Channels   int
+	PacketTime time.Duration
+
+	// Signals holds the signal of each channel. The last one is used for the
+	// remaining channels.
+	Signals []Signal
+
+	// Level of the signals in dBFS
+	Level float64
+
+	PayloadType uint8
+	TTL         int
+	DSCP        int
+
+	// SAP enables announcing the stream via SAP
+	SAP bool
+
+	Impairments Impairments
+}
+
+// Generator transmits a test stream
+type Generator struct {
+	opts   Options
+	conn   *net.UDPConn
+	origin net.IP
+	sdp    []byte
+
+	bytesPerSample  int
+	framesPerPacket int
+
+	// phases holds the phase of each sine channel in radians
+	phases []float64
+
+	// held is a packet held back for reordering
+	held    []byte
+	holding bool
+
+	counters counters
+
+	errorFn func(error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// framesPerPacket returns the number of frames in a packet, which must be a
+// whole number. At 44.1 kHz, packets carry as many frames as at 48 kHz.
+func framesPerPacket(sampleRate uint32, packetTime time.Duration) (int, error) {
+	frames := time.Duration(stream.NominalRate(sampleRate)) * packetTime
+
+	if packetTime <= 0 || frames%time.Second != 0 {
+		return 0, fmt.Errorf("packet time %s is not a whole number of frames at %d Hz", packetTime, sampleRate)
+	}
+
+	if frames == 0 {
+		return 0, fmt.Errorf("packet time %s holds no frames at %d Hz", packetTime, sampleRate)
+	}
+
+	return int(frames / time.Second), nil
+}
+
+// Start opens the socket and starts transmitting the stream and, if enabled,
+// announcing it. Errors that stop the transmission or the announcements are
+// reported to errorFn.
+func Start(opts Options, errorFn func(error)) (*Generator, error) {
+	g := &Generator{
+		opts:    opts,
+		phases:  make([]float64, opts.Channels),
+		errorFn: errorFn,
+	}
+
+	switch opts.Encoding {
+	case "L24":
+		g.bytesPerSample = 3
+	case "L16":
+		g.bytesPerSample = 2
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", opts.Encoding)
+	}
+
+	if opts.Channels < 1 {
+		return nil, errors.New("at least one channel is required")
+	}
+
+	if opts.SampleRate == 0 {
+		return nil, errors.New("a sample rate is required")
+	}
+
+	if len(opts.Signals) == 0 {
+		return nil, errors.New("at least one signal is required")
+	}
+
+	var err error
+	if g.framesPerPacket, err = framesPerPacket(opts.SampleRate, opts.PacketTime); err != nil {
+		return nil, err
+	}
+
+	if size := g.framesPerPacket * opts.Channels * g.bytesPerSample; size > 1440 {
+		return nil, fmt.Errorf("payload of %d bytes exceeds the MTU, use fewer channels or a shorter packet time", size)
+	}
+
+	if g.conn, err = transmit.Dial(opts.Destination, opts.Interface, opts.TTL, opts.DSCP); err != nil {
+		return nil, err
+	}
+
+	g.origin = g.conn.LocalAddr().(*net.UDPAddr).IP
+	g.sdp = g.buildSDP(rand.Uint64N(1 << 32))
+
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+
+	g.wg.Add(1)
+	go g.transmit()
+
+	if opts.SAP {
+		g.wg.Add(1)
+		go g.announce()
+	}
+
+	return g, nil
+}
+
+// SDP returns the session description of the stream
+func (g *Generator) SDP() []byte {
+	return g.sdp
+}
+
+// Close stops transmitting the stream and sends a SAP deletion if the stream
+// was announced
+func (g *Generator) Close() {
+	g.cancel()
+	g.wg.Wait()
+	g.conn.Close()
+}
+
+// buildSDP returns the session description of the stream
+func (g *Generator) buildSDP(sessionID uint64) []byte {
+	o := g.opts
+
+	refClock := "local"
+	if o.Interface != nil && len(o.Interface.HardwareAddr) == 6 {
+		refClock = "localmac=" + strings.ToUpper(strings.ReplaceAll(o.Interface.HardwareAddr.String(), ":", "-"))
+	}
+
+	lines := []string{
+		"v=0",
+		fmt.Sprintf("o=- %d %d IN IP4 %s", sessionID, sessionID, g.origin),
+		"s=" + o.Name,
+		fmt.Sprintf("c=IN IP4 %s/%d", o.Destination.IP, o.TTL),
+		"t=0 0",
+		fmt.Sprintf("m=audio %d RTP/AVP %d", o.Destination.Port, o.PayloadType),
+		fmt.Sprintf("a=rtpmap:%d %s/%d/%d", o.PayloadType, o.Encoding, o.SampleRate, o.Channels),
+		"a=ptime:" + strconv.FormatFloat(float64(g.packetTime().Round(time.Microsecond))/float64(time.Millisecond), 'f', -1, 64),
+		fmt.Sprintf("a=framecount:%d", g.framesPerPacket),
+		"a=recvonly",
+		"a=ts-refclk:" + refClock,
+		"a=mediaclk:direct=0",
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// mediaClock returns the RTP timestamp of a point in time, with the media
+// clock derived from TAI as a PTP locked device would
+func mediaClock(t time.Time, sampleRate uint32) uint32 {
+	tai := ptp.ConvertUtcToTai(t.UTC())
+	seconds := uint64(tai.Unix())
+	nanoseconds := uint64(tai.Nanosecond())
+
+	return uint32(seconds*uint64(sampleRate) + nanoseconds*uint64(sampleRate)/uint64(time.Second))
+}
+
+// packetTime returns the length of the packets, which is longer than the
+// packet time of the options at 44.1 kHz
+func (g *Generator) packetTime() time.Duration {
+	return time.Duration(g.framesPerPacket) * time.Second / time.Duration(g.opts.SampleRate)
+}
+
+// due returns when the packet with the given number is sent, counting the
+// frames from start so the packet times don't round
+func (g *Generator) due(start time.Time, n int) time.Time {
+	frames := uint64(n) * uint64(g.framesPerPacket)
+	rate := uint64(g.opts.SampleRate)
+
+	return start.Add(time.Duration(frames/rate)*time.Second + time.Duration(frames%rate)*time.Second/time.Duration(rate))
+}
+
+// fillPayload writes the next frames of the signals to payload
+func (g *Generator) fillPayload(payload []byte) {
+	o := g.opts
+	amplitude := math.Pow(10, o.Level/20)
+	fullScale := float64(int32(1)<<(8*g.bytesPerSample-1) - 1)
+
+	for frame := range g.framesPerPacket {
+		for ch := range o.Channels {
+			signal := o.Signals[min(ch, len(o.Signals)-1)]
+
+			var v float64
+
+			switch signal.Type {
+			case "sine":
+				v = math.Sin(g.phases[ch])
+				g.phases[ch] = math.Mod(g.phases[ch]+2*math.Pi*signal.Frequency/float64(o.SampleRate), 2*math.Pi)
+			case "noise":
+				v = 2*rand.Float64() - 1
+			}
+
+			sample := int32(math.Round(v * amplitude * fullScale))
+			offset := (frame*o.Channels + ch) * g.bytesPerSample
+
+			if g.bytesPerSample == 3 {
+				payload[offset] = byte(sample >> 16)
+				payload[offset+1] = byte(sample >> 8)
+				payload[offset+2] = byte(sample)
+			} else {
+				payload[offset] = byte(sample >> 8)
+				payload[offset+1] = byte(sample)
+			}
+		}
+	}
+}
+
+// transmit sends packets paced by the packet time until the generator is
+// closed
+func (g *Generator) transmit() {
+	defer g.wg.Done()
+
+	o := g.opts
+	start := time.Now()
+
+	packet := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    o.PayloadType,
+			SequenceNumber: uint16(rand.Uint32()),
+			Timestamp:      mediaClock(start, o.SampleRate),
+			SSRC:           rand.Uint32(),
+		},
+		Payload: make([]byte, g.framesPerPacket*o.Channels*g.bytesPerSample),
+	}
+
+	buf := make([]byte, packet.MarshalSize())
+
+	i := o.Impairments
+	lastSSRCChange, lastTimestampJump := start, start
+
+	for n := 1; ; n++ {
+		now := time.Now()
+
+		if i.SSRCChangeInterval > 0 && now.Sub(lastSSRCChange) >= i.SSRCChangeInterval {
+			packet.SSRC = rand.Uint32()
+			lastSSRCChange = now
+			g.counters.ssrcChanges.Add(1)
+
+			slog.Info("Changed SSRC", "ssrc", packet.SSRC)
+		}
+
+		if i.TimestampJumpInterval > 0 && now.Sub(lastTimestampJump) >= i.TimestampJumpInterval {
+			packet.Timestamp += uint32(i.TimestampJump)
+			lastTimestampJump = now
+			g.counters.timestampJumps.Add(1)
+
+			slog.Info("Jumped RTP timestamp", "frames", i.TimestampJump)
+		}
+
+		g.fillPayload(packet.Payload)
+
+		size, err := packet.MarshalTo(buf)
+		if err != nil {
+			g.errorFn(fmt.Errorf("failed to marshal packet: %w", err))
+			return
+		}
+
+		if err := g.send(buf[:size]); err != nil {
+			g.errorFn(fmt.Errorf("failed to send packet: %w", err))
+			return
+		}
+
+		packet.Marker = false
+		packet.SequenceNumber++
+		packet.Timestamp += uint32(g.framesPerPacket)
+
+		// Packets are sent at fixed times from the start, so pacing errors
+		// and jitter do not add up
+		delay := time.Until(g.due(start, n))
+		if i.Jitter > 0 {
+			delay += rand.N(i.Jitter)
+		}
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// announce sends SAP announcements until the generator is closed
+func (g *Generator) announce() {
+	defer g.wg.Done()
+
+	if err := transmit.Announce(g.ctx, g.origin, g.sdp); err != nil {
+		g.errorFn(err)
+	}
+}
@@ -0,0 +1,273 @@
+package generate
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+func TestParseSignal(t *testing.T) {
+	for s, want := range map[string]Signal{
+		"sine":       {Type: "sine", Frequency: 1000},
+		"sine:440.5": {Type: "sine", Frequency: 440.5},
+		"noise":      {Type: "noise"},
+		"silence":    {Type: "silence"},
+	} {
+		got, err := ParseSignal(s)
+		if err != nil || got != want {
+			t.Errorf("ParseSignal(%q) = %+v, %v; want %+v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"square", "sine:", "sine:-1", "noise:100"} {
+		if _, err := ParseSignal(s); err == nil {
+			t.Errorf("ParseSignal(%q) succeeded", s)
+		}
+	}
+}
+
+func TestFramesPerPacket(t *testing.T) {
+	for _, tc := range []struct {
+		sampleRate uint32
+		packetTime time.Duration
+		want       int
+	}{
+		{48000, time.Millisecond, 48},
+		{48000, 125 * time.Microsecond, 6},
+		{96000, 250 * time.Microsecond, 24},
+		{48000, 4 * time.Millisecond, 192},
+		{44100, time.Millisecond, 48},
+		{88200, 125 * time.Microsecond, 12},
+	} {
+		if got, err := framesPerPacket(tc.sampleRate, tc.packetTime); err != nil || got != tc.want {
+			t.Errorf("framesPerPacket(%d, %s) = %d, %v; want %d", tc.sampleRate, tc.packetTime, got, err, tc.want)
+		}
+	}
+
+	if _, err := framesPerPacket(44100, 333*time.Microsecond); err == nil {
+		t.Error("framesPerPacket() succeeded for a fractional number of frames")
+	}
+
+	if _, err := framesPerPacket(0, time.Millisecond); err == nil {
+		t.Error("framesPerPacket() succeeded without a sample rate")
+	}
+}
+
+func TestPacketTiming(t *testing.T) {
+	g := &Generator{opts: Options{SampleRate: 44100}, framesPerPacket: 48}
+
+	if got := g.packetTime().Round(time.Microsecond); got != 1088*time.Microsecond {
+		t.Errorf("packetTime() = %s, want 1.088ms", got)
+	}
+
+	// 44100 packets of 48 frames last 48 seconds, without rounding errors
+	start := time.Unix(1000, 0)
+	if got := g.due(start, 44100); !got.Equal(start.Add(48 * time.Second)) {
+		t.Errorf("due() = %v, want 48s after start", got.Sub(start))
+	}
+}
+
+func TestFillPayload(t *testing.T) {
+	g := &Generator{
+		opts: Options{
+			SampleRate: 48000,
+			Channels:   2,
+			Signals:    []Signal{{Type: "sine", Frequency: 1000}, {Type: "silence"}},
+		},
+		bytesPerSample:  3,
+		framesPerPacket: 48,
+		phases:          make([]float64, 2),
+	}
+
+	payload := make([]byte, 48*2*3)
+	g.fillPayload(payload)
+
+	peak := 0.0
+
+	for frame := range 48 {
+		left := int32(payload[frame*6])<<24 | int32(payload[frame*6+1])<<16 | int32(payload[frame*6+2])<<8
+		right := int32(payload[frame*6+3])<<24 | int32(payload[frame*6+4])<<16 | int32(payload[frame*6+5])<<8
+
+		peak = max(peak, math.Abs(float64(left>>8)))
+
+		if right != 0 {
+			t.Fatalf("silent channel has sample %d", right>>8)
+		}
+	}
+
+	if want := float64(1<<23 - 1); peak < want*0.99 || peak > want {
+		t.Errorf("peak of a full scale sine = %v, want %v", peak, want)
+	}
+}
+
+// startGenerator starts a generator sending 8 channels of noise to a local
+// socket, which is returned
+func startGenerator(t *testing.T, impairments Impairments) (*Generator, *net.UDPConn) {
+	t.Helper()
+
+	l, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	g, err := Start(Options{
+		Name:        "Test Tone",
+		Destination: l.LocalAddr().(*net.UDPAddr),
+		Encoding:    "L16",
+		SampleRate:  48000,
+		Channels:    8,
+		PacketTime:  time.Millisecond,
+		Signals:     []Signal{{Type: "noise"}},
+		Level:       -20,
+		PayloadType: 98,
+		TTL:         1,
+		DSCP:        34,
+		Impairments: impairments,
+	}, func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	t.Cleanup(g.Close)
+
+	return g, l
+}
+
+// receive receives n packets
+func receive(t *testing.T, l *net.UDPConn, n int) []rtp.Packet {
+	t.Helper()
+
+	if err := l.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1500)
+	packets := make([]rtp.Packet, n)
+
+	for i := range packets {
+		n, err := l.Read(buf)
+		if err != nil {
+			t.Fatalf("failed to receive packet: %v", err)
+		}
+
+		if err := packets[i].Unmarshal(buf[:n]); err != nil {
+			t.Fatalf("failed to parse packet: %v", err)
+		}
+	}
+
+	return packets
+}
+
+func TestGenerator(t *testing.T) {
+	g, l := startGenerator(t, Impairments{})
+
+	desc, _, err := stream.ParseSDP(g.SDP())
+	if err != nil {
+		t.Fatalf("ParseSDP() failed: %v\n%s", err, g.SDP())
+	}
+
+	if desc.Name != "Test Tone" || desc.SampleRate != 48000 || desc.ChannelCount != 8 ||
+		len(desc.Sources) != 1 || desc.Sources[0].FramesPerPacket != 48 {
+		t.Errorf("unexpected description %+v from SDP\n%s", desc, g.SDP())
+	}
+
+	packets := receive(t, l, 10)
+
+	for i, packet := range packets {
+		if len(packet.Payload) != 48*8*2 || packet.PayloadType != 98 {
+			t.Fatalf("got payload type %d with %d bytes", packet.PayloadType, len(packet.Payload))
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		previous := packets[i-1]
+
+		if packet.SequenceNumber != previous.SequenceNumber+1 || packet.Timestamp != previous.Timestamp+48 {
+			t.Errorf("packet %d/%d follows %d/%d", packet.SequenceNumber, packet.Timestamp, previous.SequenceNumber, previous.Timestamp)
+		}
+	}
+}
+
+func TestStartWithoutSampleRate(t *testing.T) {
+	_, err := Start(Options{
+		Destination: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5004},
+		Encoding:    "L24",
+		Channels:    2,
+		PacketTime:  time.Millisecond,
+		Signals:     []Signal{{Type: "noise"}},
+	}, func(error) {})
+	if err == nil {
+		t.Fatal("Start() succeeded without a sample rate")
+	}
+}
+
+func TestReorder(t *testing.T) {
+	g, l := startGenerator(t, Impairments{Reorder: 100})
+
+	packets := receive(t, l, 10)
+
+	// Every other packet is held back and sent after the next one
+	for i := 0; i < len(packets); i += 2 {
+		if packets[i+1].SequenceNumber != packets[i].SequenceNumber-1 {
+			t.Errorf("packet %d followed by %d, want swapped pairs", packets[i].SequenceNumber, packets[i+1].SequenceNumber)
+		}
+	}
+
+	if c := g.Counters(); c.Reordered < 5 {
+		t.Errorf("counted %d reordered packets, want at least 5", c.Reordered)
+	}
+}
+
+func TestLoss(t *testing.T) {
+	g, l := startGenerator(t, Impairments{Loss: 100})
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := l.SetReadDeadline(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.Read(make([]byte, 1500)); err == nil {
+		t.Error("received a packet with all packets lost")
+	}
+
+	if c := g.Counters(); c.Packets != 0 || c.Dropped == 0 {
+		t.Errorf("counted %d packets and %d dropped, want all dropped", c.Packets, c.Dropped)
+	}
+}
+
+func TestSSRCChangeAndTimestampJump(t *testing.T) {
+	g, l := startGenerator(t, Impairments{
+		SSRCChangeInterval:    5 * time.Millisecond,
+		TimestampJumpInterval: 5 * time.Millisecond,
+		TimestampJump:         4800,
+	})
+
+	packets := receive(t, l, 20)
+
+	ssrcChanges, jumps := 0, 0
+
+	for i := 1; i < len(packets); i++ {
+		if packets[i].SSRC != packets[i-1].SSRC {
+			ssrcChanges++
+		}
+
+		if packets[i].Timestamp-packets[i-1].Timestamp == 48+4800 {
+			jumps++
+		}
+	}
+
+	if ssrcChanges == 0 || jumps == 0 {
+		t.Errorf("got %d SSRC changes and %d timestamp jumps, want some", ssrcChanges, jumps)
+	}
+
+	if c := g.Counters(); c.SSRCChanges < uint64(ssrcChanges) || c.TimestampJumps < uint64(jumps) {
+		t.Errorf("counted %+v, want at least %d SSRC changes and %d jumps", c, ssrcChanges, jumps)
+	}
+}
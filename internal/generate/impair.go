@@ -0,0 +1,101 @@
+package generate
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+)
+
+// Impairments are faults injected into the stream, to validate receivers
+// and the statistics of the monitor against known patterns. The zero value
+// injects none.
+type Impairments struct {
+	// Loss is the percentage of packets that are dropped
+	Loss float64
+
+	// Jitter is the maximum random delay of a packet
+	Jitter time.Duration
+
+	// Reorder is the percentage of packets that are sent after the next one
+	Reorder float64
+
+	// SSRCChangeInterval is the time between two changes of the SSRC, zero
+	// to keep it
+	SSRCChangeInterval time.Duration
+
+	// TimestampJumpInterval is the time between two jumps of the RTP
+	// timestamp by TimestampJump frames, zero for none
+	TimestampJumpInterval time.Duration
+	TimestampJump         int
+}
+
+// Counters counts the packets sent and the impairments injected
+type Counters struct {
+	Packets        uint64
+	Dropped        uint64
+	Reordered      uint64
+	SSRCChanges    uint64
+	TimestampJumps uint64
+}
+
+type counters struct {
+	packets        atomic.Uint64
+	dropped        atomic.Uint64
+	reordered      atomic.Uint64
+	ssrcChanges    atomic.Uint64
+	timestampJumps atomic.Uint64
+}
+
+// Counters returns the number of packets sent and impairments injected so
+// far
+func (g *Generator) Counters() Counters {
+	return Counters{
+		Packets:        g.counters.packets.Load(),
+		Dropped:        g.counters.dropped.Load(),
+		Reordered:      g.counters.reordered.Load(),
+		SSRCChanges:    g.counters.ssrcChanges.Load(),
+		TimestampJumps: g.counters.timestampJumps.Load(),
+	}
+}
+
+// chance returns true with the given percentage
+func chance(percentage float64) bool {
+	return percentage > 0 && rand.Float64()*100 < percentage
+}
+
+// send sends a packet, or drops or holds it back as configured by the
+// impairments
+func (g *Generator) send(packet []byte) error {
+	i := g.opts.Impairments
+
+	if chance(i.Loss) {
+		g.counters.dropped.Add(1)
+		return nil
+	}
+
+	if !g.holding && chance(i.Reorder) {
+		g.held = append(g.held[:0], packet...)
+		g.holding = true
+		g.counters.reordered.Add(1)
+
+		return nil
+	}
+
+	if _, err := g.conn.Write(packet); err != nil {
+		return err
+	}
+
+	g.counters.packets.Add(1)
+
+	if g.holding {
+		g.holding = false
+
+		if _, err := g.conn.Write(g.held); err != nil {
+			return err
+		}
+
+		g.counters.packets.Add(1)
+	}
+
+	return nil
+}
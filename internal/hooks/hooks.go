@@ -0,0 +1,259 @@
+// Package hooks runs external commands on stream events, as a generic way of
+// integrating the monitor with other systems.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+// hookTimeout is how long a command may run before it is killed
+const hookTimeout = 30 * time.Second
+
+// hookWorkers is the number of commands run at the same time, and
+// hookQueueSize the number of commands waiting for a worker before further
+// events are dropped, e.g. during a burst of discovered streams
+const (
+	hookWorkers   = 4
+	hookQueueSize = 256
+)
+
+// envPrefix is the prefix of the environment variables passed to commands
+const envPrefix = "RTP_MONITOR_"
+
+// Options configures a Runner
+type Options struct {
+	Watcher *events.Watcher
+	Hooks   config.Hooks
+}
+
+// Runner runs the configured commands on events
+type Runner struct {
+	watcher *events.Watcher
+	hooks   config.Hooks
+	jobs    chan job
+
+	// dropped counts the events whose command was not run as the queue
+	// was full, saturated is set while it is
+	dropped   atomic.Uint64
+	saturated bool
+
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// streams are the known streams by ID hash, to describe the stream of
+	// an alert
+	streams map[string]inventory.Entry
+}
+
+// New creates a new runner
+func New(opts Options) *Runner {
+	return &Runner{
+		watcher: opts.Watcher,
+		hooks:   opts.Hooks,
+		jobs:    make(chan job, hookQueueSize),
+		done:    make(chan struct{}),
+		streams: make(map[string]inventory.Entry),
+	}
+}
+
+// job is a command to run for an event
+type job struct {
+	name    string
+	command string
+	env     []string
+	payload []byte
+}
+
+// Start runs commands in the background until Stop is called. on_stream_added
+// also runs for the streams known when starting. Up to hookWorkers commands
+// run concurrently; failures are reported to errorFn.
+func (r *Runner) Start(errorFn func(error)) {
+	ch := r.watcher.SubscribeWithStreams()
+
+	for range hookWorkers {
+		go func() {
+			for {
+				select {
+				case <-r.done:
+					return
+				case j := <-r.jobs:
+					ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+
+					if err := run(ctx, j.command, j.env, j.payload); err != nil {
+						errorFn(fmt.Errorf("%s hook failed: %w", j.name, err))
+					}
+
+					cancel()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer r.watcher.Unsubscribe(ch)
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case event := <-ch:
+				name, command := r.command(event)
+				env := r.environment(event)
+				r.track(event)
+
+				if command == "" {
+					continue
+				}
+
+				payload, _ := json.Marshal(event)
+
+				r.enqueue(job{name: name, command: command, env: env, payload: payload}, errorFn)
+			}
+		}
+	}()
+}
+
+// enqueue queues a command for the workers, or drops it if the queue is
+// full. Dropping is reported once each time the queue fills up.
+func (r *Runner) enqueue(j job, errorFn func(error)) {
+	select {
+	case r.jobs <- j:
+		r.saturated = false
+	default:
+		r.dropped.Add(1)
+
+		if !r.saturated {
+			r.saturated = true
+			errorFn(fmt.Errorf("%s hook not run, %d commands are waiting already", j.name, hookQueueSize))
+		}
+	}
+}
+
+// Dropped returns the number of events whose command was not run as too
+// many commands were waiting
+func (r *Runner) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Stop stops running commands. Commands that are running are not stopped,
+// waiting ones are dropped.
+func (r *Runner) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+// track updates the known streams
+func (r *Runner) track(event events.Event) {
+	switch event.Type {
+	case events.TypeStreamAdded, events.TypeStreamUpdated:
+		r.streams[event.IDHash] = event.Data.(inventory.Entry)
+	case events.TypeStreamRemoved:
+		delete(r.streams, event.IDHash)
+	}
+}
+
+// command returns the name and command of the hook for an event, or an
+// empty command if there is none
+func (r *Runner) command(event events.Event) (string, string) {
+	switch event.Type {
+	case events.TypeStreamAdded:
+		return "on_stream_added", r.hooks.OnStreamAdded
+	case events.TypeStreamRemoved:
+		return "on_stream_removed", r.hooks.OnStreamRemoved
+	case events.TypeAlert:
+		return "on_alert", r.hooks.OnAlert
+	}
+
+	return "", ""
+}
+
+// environment describes an event in environment variables
+func (r *Runner) environment(event events.Event) []string {
+	vars := map[string]string{
+		"EVENT":   event.Type,
+		"TIME":    event.Time.Format(time.RFC3339),
+		"ID_HASH": event.IDHash,
+	}
+
+	entry, ok := r.streams[event.IDHash]
+
+	switch data := event.Data.(type) {
+	case inventory.Entry:
+		entry, ok = data, true
+
+	case events.Alert:
+		vars["ALERT_LEVEL"] = data.Level
+		vars["ALERT_MESSAGE"] = data.Message
+		vars["ALERT_METRIC"] = data.Metric
+		vars["ALERT_VALUE"] = strconv.FormatFloat(data.Value, 'f', -1, 64)
+	}
+
+	if ok {
+		destinations := make([]string, len(entry.Sources))
+		senders := make([]string, len(entry.Sources))
+
+		for i, source := range entry.Sources {
+			destinations[i] = fmt.Sprintf("%s:%d", source.Destination, source.Port)
+			senders[i] = source.Sender
+		}
+
+		vars["STREAM_ID"] = entry.ID
+		vars["STREAM_NAME"] = entry.Name
+		vars["STREAM_HOST"] = entry.Host
+		vars["STREAM_ADDRESS"] = strings.Join(destinations, " ")
+		vars["STREAM_SENDER"] = strings.Join(senders, " ")
+		vars["STREAM_CONTENT_TYPE"] = entry.ContentType
+		vars["STREAM_SAMPLE_RATE"] = strconv.FormatUint(uint64(entry.SampleRate), 10)
+		vars["STREAM_CHANNELS"] = strconv.FormatUint(uint64(entry.Channels), 10)
+		vars["STREAM_DISCOVERY"] = entry.Discovery
+	}
+
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		env = append(env, envPrefix+key+"="+value)
+	}
+
+	return env
+}
+
+// run runs a command with the shell, with env added to the environment and
+// the event as JSON on stdin
+func run(ctx context.Context, command string, env []string, stdin []byte) error {
+	var cmd *exec.Cmd
+
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if output := strings.TrimSpace(string(output)); output != "" {
+			return fmt.Errorf("%w: %s", err, output)
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+var testEntry = inventory.Entry{
+	ID:          "stage-left",
+	IDHash:      "71cb8481ed",
+	Name:        "Stage Left",
+	ContentType: "audio",
+	SampleRate:  48000,
+	Channels:    2,
+	Discovery:   "SAP",
+	Sources: []inventory.Source{
+		{Sender: "192.168.1.10", Destination: "239.1.1.1", Port: 5004},
+		{Sender: "192.168.2.10", Destination: "239.2.1.1", Port: 5004},
+	},
+}
+
+func TestEnvironment(t *testing.T) {
+	r := New(Options{})
+	now := time.Date(2026, 1, 12, 10, 31, 2, 0, time.UTC)
+
+	r.track(events.Event{Type: events.TypeStreamAdded, IDHash: testEntry.IDHash, Data: testEntry})
+
+	env := r.environment(events.Event{
+		Type:   events.TypeAlert,
+		Time:   now,
+		IDHash: testEntry.IDHash,
+		Data: events.Alert{
+			Level:   "warning",
+			Message: "Stage Left: 12 new sequence errors",
+			Metric:  "sequence-errors",
+			Value:   12,
+		},
+	})
+
+	for _, want := range []string{
+		"RTP_MONITOR_EVENT=alert",
+		"RTP_MONITOR_TIME=2026-01-12T10:31:02Z",
+		"RTP_MONITOR_ID_HASH=71cb8481ed",
+		"RTP_MONITOR_STREAM_NAME=Stage Left",
+		"RTP_MONITOR_STREAM_ADDRESS=239.1.1.1:5004 239.2.1.1:5004",
+		"RTP_MONITOR_STREAM_SENDER=192.168.1.10 192.168.2.10",
+		"RTP_MONITOR_STREAM_SAMPLE_RATE=48000",
+		"RTP_MONITOR_ALERT_LEVEL=warning",
+		"RTP_MONITOR_ALERT_METRIC=sequence-errors",
+		"RTP_MONITOR_ALERT_VALUE=12",
+	} {
+		if !slices.Contains(env, want) {
+			t.Errorf("missing %s in %v", want, env)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	r := New(Options{Hooks: config.Hooks{OnAlert: "notify"}})
+
+	if name, command := r.command(events.Event{Type: events.TypeAlert}); name != "on_alert" || command != "notify" {
+		t.Errorf("got %s %q for an alert", name, command)
+	}
+
+	if _, command := r.command(events.Event{Type: events.TypeStreamAdded}); command != "" {
+		t.Errorf("got %q for an unconfigured hook", command)
+	}
+
+	if _, command := r.command(events.Event{Type: events.TypeStats}); command != "" {
+		t.Errorf("got %q for statistics", command)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+
+	err := run(context.Background(), `echo "$RTP_MONITOR_STREAM_NAME" > `+out+` && cat >> `+out,
+		[]string{"RTP_MONITOR_STREAM_NAME=Stage Left"}, []byte(`{"type":"stream-added"}`))
+	if err != nil {
+		t.Fatalf("run() failed: %v", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "Stage Left\n" + `{"type":"stream-added"}`; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+
+	err = run(context.Background(), "echo broken >&2; exit 3", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the output in the error, got %v", err)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	r := New(Options{})
+
+	var errs []error
+
+	for range hookQueueSize + 2 {
+		r.enqueue(job{name: "on_stream_added", command: "true"}, func(err error) { errs = append(errs, err) })
+	}
+
+	if r.Dropped() != 2 {
+		t.Errorf("Dropped() = %d, want 2", r.Dropped())
+	}
+
+	if len(errs) != 1 {
+		t.Errorf("got %d errors, want one for the queue filling up", len(errs))
+	}
+
+	// Stopping twice is fine
+	r.Stop()
+	r.Stop()
+}
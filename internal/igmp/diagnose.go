@@ -0,0 +1,38 @@
+package igmp
+
+import (
+	"fmt"
+	"time"
+)
+
+// joinGracePeriod is how long packets may take to arrive after a join
+// before their absence is reported
+const joinGracePeriod = 2 * time.Second
+
+// Diagnose explains whether packets arrive for a group joined on an
+// interface, and if not, what the IGMP state of the interface suggests. ifi
+// is nil if IGMP messages are not monitored.
+func Diagnose(ifi *Interface, joined time.Time, packets uint64, lastPacket, now time.Time) string {
+	querier := ifi != nil && ifi.QuerierPresent(now)
+
+	switch {
+	case packets > 0 && now.Sub(lastPacket) < joinGracePeriod:
+		return "receiving"
+	case packets > 0 && ifi != nil && !querier:
+		return fmt.Sprintf("stopped %s ago, no IGMP querier: the membership may have expired on the switch",
+			now.Sub(lastPacket).Truncate(time.Second))
+	case packets > 0:
+		return fmt.Sprintf("stopped %s ago: the sender stopped or the group is no longer forwarded",
+			now.Sub(lastPacket).Truncate(time.Second))
+	case now.Sub(joined) < joinGracePeriod:
+		return "waiting for packets"
+	case ifi == nil:
+		return "no packets"
+	case !querier:
+		return "no packets and no IGMP querier: snooping switches may not forward multicast without one"
+	case ifi.ReportsAfterQuery == 0 && now.Sub(ifi.LastQuery) > ifi.MaxResponse:
+		return "no packets, and no membership report answered the last query: reports may not reach the querier"
+	default:
+		return "no packets although a querier is present: no sender, or the switch does not forward the group"
+	}
+}
@@ -0,0 +1,101 @@
+// Package igmp watches the IGMP messages on the monitored interfaces, to
+// tell whether a querier is present and hosts answer its queries.
+package igmp
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// Message types of RFC 2236 and RFC 3376
+const (
+	messageTypeQuery    = 0x11
+	messageTypeV1Report = 0x12
+	messageTypeV2Report = 0x16
+	messageTypeLeave    = 0x17
+	messageTypeV3Report = 0x22
+)
+
+// message is a parsed IGMP message
+type message struct {
+	messageType uint8
+
+	// version is the IGMP version of a query
+	version int
+
+	// group is the group of a group-specific query, a leave or a version 1
+	// or 2 report, unspecified for general queries
+	group net.IP
+
+	// groups are the groups of a version 3 report
+	groups []net.IP
+
+	maxResponse time.Duration
+
+	// queryInterval is announced by version 3 queries, zero otherwise
+	queryInterval time.Duration
+}
+
+// decodeCode decodes the floating point representation of the Max Resp Code
+// and QQIC fields of RFC 3376, section 4.1.1
+func decodeCode(code uint8) int {
+	if code < 128 {
+		return int(code)
+	}
+
+	mant := int(code & 0x0f)
+	exp := int(code>>4) & 0x07
+
+	return (mant | 0x10) << (exp + 3)
+}
+
+func parseMessage(b []byte) (message, error) {
+	if len(b) < 8 {
+		return message{}, errors.New("message too short")
+	}
+
+	m := message{messageType: b[0]}
+
+	switch m.messageType {
+	case messageTypeQuery:
+		m.group = net.IP(b[4:8])
+
+		switch {
+		case len(b) >= 12:
+			m.version = 3
+			m.maxResponse = time.Duration(decodeCode(b[1])) * 100 * time.Millisecond
+			m.queryInterval = time.Duration(decodeCode(b[9])) * time.Second
+		case b[1] == 0:
+			m.version = 1
+			m.maxResponse = 10 * time.Second
+		default:
+			m.version = 2
+			m.maxResponse = time.Duration(b[1]) * 100 * time.Millisecond
+		}
+
+	case messageTypeV1Report, messageTypeV2Report, messageTypeLeave:
+		m.group = net.IP(b[4:8])
+
+	case messageTypeV3Report:
+		records := int(b[6])<<8 | int(b[7])
+		offset := 8
+
+		for range records {
+			if len(b) < offset+8 {
+				return message{}, errors.New("group record too short")
+			}
+
+			auxLen := int(b[offset+1])
+			sources := int(b[offset+2])<<8 | int(b[offset+3])
+
+			m.groups = append(m.groups, net.IP(b[offset+4:offset+8]))
+			offset += 8 + 4*sources + 4*auxLen
+		}
+
+	default:
+		return message{}, errors.New("unknown message type")
+	}
+
+	return m, nil
+}
@@ -0,0 +1,219 @@
+package igmp
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+// defaultQuerierTimeout is the Other Querier Present Interval of RFC 3376
+// with the default robustness, query interval and response time
+const defaultQuerierTimeout = 255 * time.Second
+
+// allReportsGroup is the group IGMPv3 reports are sent to
+var allReportsGroup = net.IPv4(224, 0, 0, 22)
+
+// Interface is the IGMP state observed on an interface
+type Interface struct {
+	Name string
+
+	// Querier is the address of the last querier, nil if no query was
+	// received
+	Querier      net.IP
+	QueryVersion int
+	LastQuery    time.Time
+	Queries      uint64
+
+	// QueryInterval is announced by IGMPv3 queriers, or measured between two
+	// general queries otherwise
+	QueryInterval time.Duration
+	MaxResponse   time.Duration
+
+	Reports    uint64
+	LastReport time.Time
+
+	// ReportsAfterQuery counts the reports received within the maximum
+	// response time of the last general query
+	ReportsAfterQuery uint64
+
+	// Groups maps the groups reported by hosts on the link to the time of
+	// their last report
+	Groups map[string]time.Time
+}
+
+// QuerierPresent returns whether a querier sent a query within the Other
+// Querier Present Interval
+func (i *Interface) QuerierPresent(now time.Time) bool {
+	if i.LastQuery.IsZero() {
+		return false
+	}
+
+	timeout := defaultQuerierTimeout
+	if i.QueryInterval > 0 {
+		timeout = 2*i.QueryInterval + i.MaxResponse/2
+	}
+
+	return now.Sub(i.LastQuery) < timeout
+}
+
+// Monitor watches the IGMP messages received on a set of interfaces
+type Monitor struct {
+	mutex      sync.Mutex
+	conn       *ipv4.PacketConn
+	interfaces map[int]*Interface
+	started    time.Time
+}
+
+// NewMonitor opens a raw socket to receive IGMP messages on the given
+// interfaces, which requires root privileges
+func NewMonitor(ifis []*net.Interface) (*Monitor, error) {
+	m := &Monitor{
+		interfaces: make(map[int]*Interface),
+		started:    time.Now(),
+	}
+
+	for _, ifi := range ifis {
+		m.interfaces[ifi.Index] = &Interface{
+			Name:   ifi.Name,
+			Groups: make(map[string]time.Time),
+		}
+	}
+
+	c, err := net.ListenPacket("ip4:igmp", "0.0.0.0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IGMP socket: %w", err)
+	}
+
+	m.conn = ipv4.NewPacketConn(c)
+
+	if err := m.conn.SetControlMessage(ipv4.FlagInterface, true); err != nil {
+		m.conn.Close()
+		return nil, fmt.Errorf("failed to set control message: %w", err)
+	}
+
+	// IGMPv3 reports of other hosts are only received as a member of their
+	// group
+	for _, ifi := range ifis {
+		if err := m.conn.JoinGroup(ifi, &net.IPAddr{IP: allReportsGroup}); err != nil {
+			m.conn.Close()
+			return nil, fmt.Errorf("failed to join group %s on interface %s: %w", allReportsGroup, ifi.Name, err)
+		}
+	}
+
+	go m.readLoop()
+
+	return m, nil
+}
+
+func (m *Monitor) readLoop() {
+	buf := make([]byte, 1500)
+
+	for {
+		n, cm, src, err := m.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil || cm == nil {
+			continue
+		}
+
+		var ip net.IP
+		if addr, ok := src.(*net.IPAddr); ok {
+			ip = addr.IP
+		}
+
+		m.handleMessage(cm.IfIndex, ip, buf[:n], time.Now())
+	}
+}
+
+func (m *Monitor) handleMessage(ifIndex int, src net.IP, b []byte, now time.Time) {
+	msg, err := parseMessage(b)
+	if err != nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	i, ok := m.interfaces[ifIndex]
+	if !ok {
+		return
+	}
+
+	switch msg.messageType {
+	case messageTypeQuery:
+		// Group-specific queries follow leaves and say nothing about the
+		// query interval
+		if !msg.group.IsUnspecified() {
+			return
+		}
+
+		if msg.queryInterval > 0 {
+			i.QueryInterval = msg.queryInterval
+		} else if !i.LastQuery.IsZero() {
+			i.QueryInterval = now.Sub(i.LastQuery).Round(time.Second)
+		}
+
+		i.Querier = append(net.IP{}, src...)
+		i.QueryVersion = msg.version
+		i.MaxResponse = msg.maxResponse
+		i.LastQuery = now
+		i.Queries++
+		i.ReportsAfterQuery = 0
+
+	case messageTypeV1Report, messageTypeV2Report, messageTypeV3Report:
+		i.Reports++
+		i.LastReport = now
+
+		// Hosts delay their answers by up to the maximum response time
+		if !i.LastQuery.IsZero() && now.Sub(i.LastQuery) <= i.MaxResponse+time.Second {
+			i.ReportsAfterQuery++
+		}
+
+		groups := msg.groups
+		if msg.group != nil {
+			groups = []net.IP{msg.group}
+		}
+
+		for _, group := range groups {
+			i.Groups[group.String()] = now
+		}
+	}
+}
+
+// Interfaces returns the state of the monitored interfaces, sorted by name
+func (m *Monitor) Interfaces() []Interface {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	interfaces := make([]Interface, 0, len(m.interfaces))
+
+	for _, i := range m.interfaces {
+		c := *i
+		c.Groups = maps.Clone(i.Groups)
+		interfaces = append(interfaces, c)
+	}
+
+	sort.Slice(interfaces, func(a, b int) bool {
+		return interfaces[a].Name < interfaces[b].Name
+	})
+
+	return interfaces
+}
+
+// Started returns when the monitor was created
+func (m *Monitor) Started() time.Time {
+	return m.started
+}
+
+// Close stops receiving IGMP messages
+func (m *Monitor) Close() {
+	m.conn.Close()
+}
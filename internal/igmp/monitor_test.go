@@ -0,0 +1,118 @@
+package igmp
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	generalQueryV2 = []byte{messageTypeQuery, 100, 0, 0, 0, 0, 0, 0}
+	generalQueryV3 = []byte{messageTypeQuery, 100, 0, 0, 0, 0, 0, 0, 0x02, 60, 0, 0}
+	reportV2       = []byte{messageTypeV2Report, 0, 0, 0, 239, 1, 1, 1}
+
+	// reportV3 holds two group records, the first with one source
+	reportV3 = []byte{
+		messageTypeV3Report, 0, 0, 0, 0, 0, 0, 2,
+		1, 0, 0, 1, 239, 1, 1, 1, 192, 168, 1, 10,
+		2, 0, 0, 0, 239, 2, 2, 2,
+	}
+)
+
+func TestParseMessage(t *testing.T) {
+	m, err := parseMessage(generalQueryV3)
+	if err != nil || m.version != 3 || m.maxResponse != 10*time.Second || m.queryInterval != time.Minute {
+		t.Errorf("IGMPv3 query parsed as %+v, %v", m, err)
+	}
+
+	m, err = parseMessage(generalQueryV2)
+	if err != nil || m.version != 2 || m.maxResponse != 10*time.Second || !m.group.IsUnspecified() {
+		t.Errorf("IGMPv2 query parsed as %+v, %v", m, err)
+	}
+
+	m, err = parseMessage(reportV3)
+	if err != nil || len(m.groups) != 2 || !m.groups[1].Equal(net.IPv4(239, 2, 2, 2)) {
+		t.Errorf("IGMPv3 report parsed as %+v, %v", m, err)
+	}
+
+	if _, err := parseMessage(reportV3[:24]); err == nil {
+		t.Error("parseMessage() succeeded for a truncated group record")
+	}
+
+	// Codes of 128 and above are floating point values
+	if got := decodeCode(0x8f); got != 248 {
+		t.Errorf("decodeCode(0x8f) = %d, want 248", got)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	m := &Monitor{
+		interfaces: map[int]*Interface{
+			2: {Name: "eth0", Groups: make(map[string]time.Time)},
+		},
+	}
+
+	start := time.Now()
+	querier := net.IPv4(192, 168, 1, 1)
+
+	m.handleMessage(2, querier, generalQueryV2, start)
+	m.handleMessage(2, nil, reportV2, start.Add(time.Second))
+	m.handleMessage(2, querier, generalQueryV2, start.Add(125*time.Second))
+	m.handleMessage(2, nil, reportV3, start.Add(126*time.Second))
+
+	// Not monitored
+	m.handleMessage(3, querier, generalQueryV2, start)
+
+	interfaces := m.Interfaces()
+	if len(interfaces) != 1 {
+		t.Fatalf("got %d interfaces, want 1", len(interfaces))
+	}
+
+	i := interfaces[0]
+
+	if !i.Querier.Equal(querier) || i.QueryVersion != 2 || i.Queries != 2 || i.QueryInterval != 125*time.Second {
+		t.Errorf("unexpected querier state %+v", i)
+	}
+
+	if i.Reports != 2 || i.ReportsAfterQuery != 1 || len(i.Groups) != 2 {
+		t.Errorf("unexpected report state %+v", i)
+	}
+
+	if !i.QuerierPresent(start.Add(300*time.Second)) || i.QuerierPresent(start.Add(400*time.Second)) {
+		t.Error("querier timeout does not follow the query interval")
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	now := time.Now()
+	joined := now.Add(-time.Minute)
+
+	withQuerier := &Interface{
+		Querier:           net.IPv4(192, 168, 1, 1),
+		LastQuery:         now.Add(-10 * time.Second),
+		QueryInterval:     125 * time.Second,
+		MaxResponse:       10 * time.Second,
+		ReportsAfterQuery: 1,
+	}
+
+	for _, tt := range []struct {
+		name       string
+		ifi        *Interface
+		joined     time.Time
+		packets    uint64
+		lastPacket time.Time
+		want       string
+	}{
+		{"receiving", withQuerier, joined, 100, now, "receiving"},
+		{"just joined", withQuerier, now, 0, time.Time{}, "waiting"},
+		{"stopped", withQuerier, joined, 100, now.Add(-30 * time.Second), "stopped 30s ago: the sender"},
+		{"no querier", &Interface{}, joined, 0, time.Time{}, "no IGMP querier"},
+		{"querier", withQuerier, joined, 0, time.Time{}, "no sender, or the switch"},
+		{"unmonitored", nil, joined, 0, time.Time{}, "no packets"},
+	} {
+		if got := Diagnose(tt.ifi, tt.joined, tt.packets, tt.lastPacket, now); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: Diagnose() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
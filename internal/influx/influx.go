@@ -0,0 +1,265 @@
+// Package influx periodically writes stream and PTP statistics in the
+// InfluxDB line protocol to an HTTP endpoint or a file.
+package influx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/metrics"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// httpTimeout is how long writing to an HTTP endpoint may take
+const httpTimeout = 10 * time.Second
+
+// Options configures an Exporter
+type Options struct {
+	Manager    *stream.Manager
+	Collector  *stats.Collector
+	PTPMonitor *ptp.Monitor // may be nil
+
+	// Output is the URL of a write endpoint, e.g.
+	// http://localhost:8086/api/v2/write?org=studio&bucket=rtp, or the path
+	// of a file to append to
+	Output string
+
+	// Token is sent as API token to HTTP endpoints, if set
+	Token string
+
+	// Interval is the time between two writes
+	Interval time.Duration
+}
+
+// Exporter writes the statistics periodically. Per-source statistics are
+// only available for streams the collector gathers statistics for.
+type Exporter struct {
+	opts   Options
+	client *http.Client
+	done   chan struct{}
+}
+
+// NewExporter creates a new exporter
+func NewExporter(opts Options) *Exporter {
+	return &Exporter{
+		opts:   opts,
+		client: &http.Client{Timeout: httpTimeout},
+		done:   make(chan struct{}),
+	}
+}
+
+// isURL reports whether the output is an HTTP endpoint rather than a file
+func (e *Exporter) isURL() bool {
+	return strings.HasPrefix(e.opts.Output, "http://") || strings.HasPrefix(e.opts.Output, "https://")
+}
+
+// Start writes the statistics in the background until Stop is called.
+// Errors are reported to errorFn.
+func (e *Exporter) Start(errorFn func(error)) {
+	go func() {
+		ticker := time.NewTicker(e.opts.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.done:
+				return
+			case now := <-ticker.C:
+				if err := e.export(now); err != nil {
+					errorFn(err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops writing
+func (e *Exporter) Stop() {
+	close(e.done)
+}
+
+// export writes the current statistics once
+func (e *Exporter) export(now time.Time) error {
+	streams := e.opts.Manager.GetAllStreams()
+
+	var b bytes.Buffer
+	if err := Write(&b, now, streams, metrics.Snapshots(e.opts.Collector, streams), metrics.Transmitters(e.opts.PTPMonitor)); err != nil {
+		return err
+	}
+
+	if e.isURL() {
+		return e.post(b.Bytes())
+	}
+
+	f, err := os.OpenFile(e.opts.Output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", e.opts.Output, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.opts.Output, err)
+	}
+
+	return nil
+}
+
+// post sends lines to the HTTP endpoint
+func (e *Exporter) post(lines []byte) error {
+	req, err := http.NewRequest(http.MethodPost, e.opts.Output, bytes.NewReader(lines))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	if e.opts.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.opts.Token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("failed to write to InfluxDB: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// tag is a tag key and value of a point
+type tag struct {
+	key, value string
+}
+
+// field is a field key and its formatted value
+type field struct {
+	key, value string
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+func floatField(key string, v float64) field {
+	return field{key, strconv.FormatFloat(v, 'g', -1, 64)}
+}
+
+func intField(key string, v uint64) field {
+	return field{key, strconv.FormatUint(v, 10) + "i"}
+}
+
+func signedField(key string, v int64) field {
+	return field{key, strconv.FormatInt(v, 10) + "i"}
+}
+
+func stringField(key, v string) field {
+	return field{key, `"` + stringEscaper.Replace(v) + `"`}
+}
+
+// point writes one line. Tags with empty values are left out, as the line
+// protocol does not allow them.
+func point(w *bufio.Writer, measurement string, tags []tag, fields []field, now time.Time) {
+	w.WriteString(measurementEscaper.Replace(measurement))
+
+	for _, t := range tags {
+		if t.value == "" {
+			continue
+		}
+
+		w.WriteString(",")
+		w.WriteString(tagEscaper.Replace(t.key))
+		w.WriteString("=")
+		w.WriteString(tagEscaper.Replace(t.value))
+	}
+
+	for i, f := range fields {
+		if i == 0 {
+			w.WriteString(" ")
+		} else {
+			w.WriteString(",")
+		}
+
+		w.WriteString(tagEscaper.Replace(f.key))
+		w.WriteString("=")
+		w.WriteString(f.value)
+	}
+
+	w.WriteString(" ")
+	w.WriteString(strconv.FormatInt(now.UnixNano(), 10))
+	w.WriteString("\n")
+}
+
+// Write writes the points of the given streams, their statistics by stream
+// ID, and the PTP transmitters, all with the timestamp now
+func Write(out io.Writer, now time.Time, streams []*stream.Stream, snapshots map[string]stats.Snapshot, transmitters []metrics.Transmitter) error {
+	w := bufio.NewWriter(out)
+
+	point(w, "rtp_monitor", nil, []field{
+		intField("streams", uint64(len(streams))),
+		intField("ptp_transmitters", uint64(len(transmitters))),
+	}, now)
+
+	for _, s := range streams {
+		snapshot, ok := snapshots[s.ID]
+		if !ok {
+			continue
+		}
+
+		for i, source := range snapshot.Sources {
+			tags := []tag{
+				{"id_hash", s.IDHash()},
+				{"name", s.Name()},
+				{"address", s.Address()},
+				{"source", strconv.Itoa(i + 1)},
+			}
+
+			fields := []field{
+				intField("packets", source.Packets),
+				intField("bytes", source.Bytes),
+				floatField("packet_rate", source.PacketRate),
+				floatField("bitrate", source.Bitrate),
+				intField("sequence_errors", source.SequenceErrors),
+				signedField("lost", source.Lost),
+				intField("reordered", source.Reordered),
+				intField("rtp_errors", source.RTPErrors),
+				floatField("jitter_seconds", source.Jitter.Seconds()),
+				stringField("codec", s.CodecInfo()),
+			}
+
+			if !source.LastPacket.IsZero() {
+				fields = append(fields, floatField("last_packet_age_seconds", now.Sub(source.LastPacket).Seconds()))
+			}
+
+			point(w, "rtp_monitor_source", tags, fields, now)
+		}
+	}
+
+	for _, t := range transmitters {
+		point(w, "rtp_monitor_ptp", []tag{
+			{"clock_identity", t.ClockIdentity},
+			{"domain", strconv.Itoa(int(t.Domain))},
+			{"interface", t.Interface},
+		}, []field{
+			floatField("timestamp_age_seconds", now.Sub(t.LastTimestamp).Seconds()),
+		}, now)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,95 @@
+package influx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/metrics"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+func TestWrite(t *testing.T) {
+	now := time.Unix(2000, 0)
+
+	s := &stream.Stream{
+		ID: "test",
+		Description: stream.StreamDescription{
+			Name: "Stage Left, main",
+		},
+	}
+
+	snapshots := map[string]stats.Snapshot{
+		"test": {
+			Sources: []stats.SourceSnapshot{
+				{Packets: 1000, PacketRate: 1000, Jitter: 250 * time.Microsecond, LastPacket: now.Add(-time.Second)},
+				{Packets: 998, SequenceErrors: 2},
+			},
+		},
+	}
+
+	transmitters := []metrics.Transmitter{
+		{ClockIdentity: "00-11-22-ff-fe-33-44-55", Domain: 0, Interface: "eth0", LastTimestamp: now.Add(-500 * time.Millisecond)},
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, now, []*stream.Stream{s}, snapshots, transmitters); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	out := b.String()
+
+	for _, want := range []string{
+		"rtp_monitor streams=1i,ptp_transmitters=1i 2000000000000\n",
+		`rtp_monitor_source,id_hash=` + s.IDHash() + `,name=Stage\ Left\,\ main,source=1 packets=1000i,`,
+		`,jitter_seconds=0.00025,`,
+		`,last_packet_age_seconds=1 `,
+		`,source=2 packets=998i,bytes=0i,packet_rate=0,bitrate=0,sequence_errors=2i,`,
+		"rtp_monitor_ptp,clock_identity=00-11-22-ff-fe-33-44-55,domain=0,interface=eth0 timestamp_age_seconds=0.5 2000000000000\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// Empty tags are not allowed
+	if strings.Contains(out, "address=") {
+		t.Errorf("unexpected empty tag:\n%s", out)
+	}
+}
+
+func TestExportHTTP(t *testing.T) {
+	var body, auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	e := NewExporter(Options{
+		Manager:   stream.NewManager(t.Context(), nil),
+		Collector: stats.NewCollector(),
+		Output:    server.URL + "/api/v2/write?org=studio&bucket=rtp",
+		Token:     "secret",
+	})
+
+	if err := e.export(time.Unix(2000, 0)); err != nil {
+		t.Fatalf("export() failed: %v", err)
+	}
+
+	if auth != "Token secret" {
+		t.Errorf("unexpected authorization %q", auth)
+	}
+
+	if body != "rtp_monitor streams=0i,ptp_transmitters=0i 2000000000000\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
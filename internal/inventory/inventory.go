@@ -0,0 +1,205 @@
+// Package inventory exports the list of discovered streams in machine
+// readable formats.
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"csv", "json", "table"}
+
+// Source describes one source (e.g. primary or secondary) of a stream
+type Source struct {
+	Sender          string `json:"sender"`
+	Destination     string `json:"destination"`
+	Port            uint16 `json:"port"`
+	TTL             uint8  `json:"ttl"`
+	FramesPerPacket uint32 `json:"frames-per-packet"`
+	ClockDomain     string `json:"clock-domain,omitempty"`
+	ReferenceClock  string `json:"reference-clock,omitempty"`
+	MediaClock      string `json:"media-clock,omitempty"`
+	SyncTime        uint32 `json:"sync-time"`
+}
+
+// Entry describes one stream with all parsed fields
+type Entry struct {
+	ID            string     `json:"id"`
+	IDHash        string     `json:"id-hash"`
+	Name          string     `json:"name"`
+	Host          string     `json:"host,omitempty"`
+	RTSPURL       string     `json:"rtsp-url,omitempty"`
+	ContentType   string     `json:"content-type"`
+	SampleRate    uint32     `json:"sample-rate"`
+	Channels      uint32     `json:"channels"`
+	Discovery     string     `json:"discovery"`
+	LastAnnounced *time.Time `json:"last-announced,omitempty"`
+	Sources       []Source   `json:"sources"`
+	SDP           string     `json:"sdp"`
+}
+
+// FromStreams creates the inventory entries of the given streams
+func FromStreams(streams []*stream.Stream) []Entry {
+	entries := make([]Entry, 0, len(streams))
+
+	for _, s := range streams {
+		e := Entry{
+			ID:          s.ID,
+			IDHash:      s.IDHash(),
+			Name:        s.Name(),
+			Host:        s.Host(),
+			RTSPURL:     s.RTSPURL(),
+			ContentType: string(s.Description.ContentType),
+			SampleRate:  s.Description.SampleRate,
+			Channels:    s.Description.ChannelCount,
+			Discovery:   s.DiscoveryLabel(),
+			Sources:     make([]Source, 0, len(s.Description.Sources)),
+			SDP:         string(s.SDP),
+		}
+
+		if t := s.LastAnnounced(); !t.IsZero() {
+			e.LastAnnounced = &t
+		}
+
+		for _, src := range s.Description.Sources {
+			e.Sources = append(e.Sources, Source{
+				Sender:          ipString(src.SenderAddress.String()),
+				Destination:     ipString(src.DestinationAddress.String()),
+				Port:            src.DestinationPort,
+				TTL:             src.TTL,
+				FramesPerPacket: src.FramesPerPacket,
+				ClockDomain:     src.ClockDomain,
+				ReferenceClock:  src.ReferenceClock,
+				MediaClock:      src.MediaClock,
+				SyncTime:        src.SyncTime,
+			})
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// ipString maps the string of a nil IP to an empty string
+func ipString(s string) string {
+	if s == "<nil>" {
+		return ""
+	}
+
+	return s
+}
+
+// Write writes the entries in the given format
+func Write(w io.Writer, format string, entries []Entry) error {
+	switch format {
+	case "csv":
+		return WriteCSV(w, entries)
+	case "json":
+		return WriteJSON(w, entries)
+	case "table":
+		return WriteTable(w, entries)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// WriteJSON writes the entries as an indented JSON array
+func WriteJSON(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(entries)
+}
+
+// csvHeader lists the CSV columns. The SDP is left out as it spans multiple
+// lines.
+var csvHeader = []string{
+	"id", "id-hash", "name", "host", "content-type", "sample-rate", "channels",
+	"discovery", "last-announced", "source", "sender", "destination", "port", "ttl",
+	"frames-per-packet", "clock-domain", "reference-clock", "media-clock", "sync-time",
+}
+
+// WriteCSV writes the entries as CSV with a header line. Streams get one
+// row per source, numbered from 1, so every address has its own cell.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		lastAnnounced := ""
+		if e.LastAnnounced != nil {
+			lastAnnounced = e.LastAnnounced.Format(time.RFC3339)
+		}
+
+		row := []string{
+			e.ID, e.IDHash, e.Name, e.Host, e.ContentType,
+			strconv.FormatUint(uint64(e.SampleRate), 10),
+			strconv.FormatUint(uint64(e.Channels), 10),
+			e.Discovery, lastAnnounced,
+		}
+
+		// Streams without sources still get a row
+		if len(e.Sources) == 0 {
+			if err := cw.Write(append(row, make([]string, len(csvHeader)-len(row))...)); err != nil {
+				return err
+			}
+		}
+
+		for i, src := range e.Sources {
+			r := append(append([]string{}, row...),
+				strconv.Itoa(i+1), src.Sender, src.Destination,
+				strconv.FormatUint(uint64(src.Port), 10),
+				strconv.FormatUint(uint64(src.TTL), 10),
+				strconv.FormatUint(uint64(src.FramesPerPacket), 10),
+				src.ClockDomain, src.ReferenceClock, src.MediaClock,
+				strconv.FormatUint(uint64(src.SyncTime), 10))
+
+			if err := cw.Write(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// WriteTable writes the entries as a table with aligned columns, for
+// reading in a terminal. Streams get one row with the destinations of all
+// sources.
+func WriteTable(w io.Writer, entries []Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID-HASH\tNAME\tFORMAT\tADDRESS\tHOST\tDISCOVERY")
+
+	for _, e := range entries {
+		format := e.ContentType
+		if e.SampleRate > 0 {
+			format += fmt.Sprintf(" %d Hz %d ch", e.SampleRate, e.Channels)
+		}
+
+		addresses := make([]string, len(e.Sources))
+		for i, src := range e.Sources {
+			addresses[i] = fmt.Sprintf("%s:%d", src.Destination, src.Port)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.IDHash, e.Name, format, strings.Join(addresses, ","), e.Host, e.Discovery)
+	}
+
+	return tw.Flush()
+}
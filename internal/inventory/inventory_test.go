@@ -0,0 +1,140 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+func testStreams() []*stream.Stream {
+	return []*stream.Stream{
+		{
+			ID: "test-1",
+			Description: stream.StreamDescription{
+				Name:         "Stage, left",
+				SampleRate:   48000,
+				ChannelCount: 8,
+				ContentType:  stream.ContentTypePCM24,
+				Sources: []stream.StreamSource{
+					{
+						SenderAddress:      net.ParseIP("192.168.1.10"),
+						DestinationAddress: net.ParseIP("239.1.1.1"),
+						DestinationPort:    5004,
+						TTL:                15,
+					},
+					{
+						SenderAddress:      net.ParseIP("192.168.2.10"),
+						DestinationAddress: net.ParseIP("239.2.1.1"),
+						DestinationPort:    5004,
+						TTL:                15,
+					},
+				},
+			},
+			Discoveries: []stream.Discovery{
+				{Method: stream.DiscoveryMethodManual},
+			},
+		},
+		{
+			ID: "test-2",
+			Description: stream.StreamDescription{
+				Name: "No sources",
+			},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+
+	if err := Write(&b, "csv", FromStreams(testStreams())); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV failed: %v", err)
+	}
+
+	// Header, two sources of the first stream and one row for the second
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+
+	for i, row := range rows {
+		if len(row) != len(csvHeader) {
+			t.Errorf("row %d has %d columns, want %d", i, len(row), len(csvHeader))
+		}
+	}
+
+	if rows[1][2] != "Stage, left" {
+		t.Errorf("name = %q, want %q", rows[1][2], "Stage, left")
+	}
+
+	if rows[2][9] != "2" || rows[2][11] != "239.2.1.1" {
+		t.Errorf("second source row = %v, want source 2 to 239.2.1.1", rows[2])
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+
+	if err := Write(&b, "json", FromStreams(testStreams())); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(b.Bytes(), &entries); err != nil {
+		t.Fatalf("decoding JSON failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	if got := entries[0].Sources[1].Sender; got != "192.168.2.10" {
+		t.Errorf("sender = %q, want 192.168.2.10", got)
+	}
+
+	if entries[0].LastAnnounced != nil {
+		t.Errorf("last-announced = %v, want none for manual streams", entries[0].LastAnnounced)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "xml", nil); err == nil {
+		t.Error("Write() succeeded for an unknown format")
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteTable(&buf, FromStreams(testStreams())); err != nil {
+		t.Fatalf("WriteTable() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 streams:\n%s", len(lines), buf.String())
+	}
+
+	if !strings.HasPrefix(lines[0], "ID-HASH") {
+		t.Errorf("header = %q", lines[0])
+	}
+
+	for _, want := range []string{"Stage, left", "PCM24 48000 Hz 8 ch", "239.1.1.1:5004,239.2.1.1:5004", "Manual"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q does not contain %q", lines[1], want)
+		}
+	}
+
+	// Columns are aligned
+	if a, b := strings.Index(lines[0], "NAME"), strings.Index(lines[1], "Stage"); a != b {
+		t.Errorf("NAME column at %d, value at %d", a, b)
+	}
+}
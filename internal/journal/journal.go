@@ -0,0 +1,159 @@
+// Package journal writes the events of a monitoring session to an append-only
+// JSON lines file, one file per session, and reads them back to reconstruct
+// what happened when.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/version"
+)
+
+// Types of the entries that mark the start and end of a session
+const (
+	TypeSessionStarted = "session-started"
+	TypeSessionStopped = "session-stopped"
+)
+
+// Session is the data of the session entries
+type Session struct {
+	Version  string `json:"version,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	PID      int    `json:"pid"`
+}
+
+// Options configures a Journal
+type Options struct {
+	Watcher *events.Watcher
+
+	// Folder is where the journal files are created
+	Folder string
+}
+
+// Journal appends all events but statistics to the file of the session
+type Journal struct {
+	watcher *events.Watcher
+	file    *os.File
+	session Session
+
+	mutex sync.Mutex
+	err   error
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// FileName returns the name of the journal file of a session started at t
+func FileName(t time.Time) string {
+	return "journal-" + t.Format("20060102-150405") + ".jsonl"
+}
+
+// New creates the journal file of a new session in the folder and writes
+// the session-started entry
+func New(opts Options) (*Journal, error) {
+	if err := os.MkdirAll(opts.Folder, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal folder: %w", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(opts.Folder, FileName(now))
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+
+	j := &Journal{
+		watcher: opts.Watcher,
+		file:    f,
+		session: Session{
+			Version:  version.Version,
+			Hostname: hostname,
+			PID:      os.Getpid(),
+		},
+		done: make(chan struct{}),
+	}
+
+	if err := j.write(events.Event{Type: TypeSessionStarted, Time: now, Data: j.session}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Path returns the path of the journal file
+func (j *Journal) Path() string {
+	return j.file.Name()
+}
+
+// write appends an event as a line. The file is synced, so entries survive
+// a crash of the monitor, which is when the journal is needed most.
+func (j *Journal) write(e events.Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", e.Type, err)
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	return j.file.Sync()
+}
+
+// Start journals events in the background until Stop is called, starting
+// with the streams that are already known. Errors are reported to errorFn.
+func (j *Journal) Start(errorFn func(error)) {
+	ch := j.watcher.SubscribeWithStreams()
+
+	j.wg.Add(1)
+
+	go func() {
+		defer j.wg.Done()
+		defer j.watcher.Unsubscribe(ch)
+
+		for {
+			select {
+			case <-j.done:
+				return
+			case <-j.watcher.Done():
+				return
+			case event := <-ch:
+				if event.Type == events.TypeStats {
+					continue
+				}
+
+				if err := j.write(event); err != nil {
+					errorFn(err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops journaling, writes the session-stopped entry and closes the
+// file
+func (j *Journal) Stop() error {
+	close(j.done)
+	j.wg.Wait()
+
+	err := j.write(events.Event{Type: TypeSessionStopped, Time: time.Now(), Data: j.session})
+
+	if closeErr := j.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package journal
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+func readAll(t *testing.T, path string, filter Filter) []Entry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+
+	err = Read(f, func(e Entry) error {
+		if filter.Match(e) {
+			entries = append(entries, e)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() failed: %v", err)
+	}
+
+	return entries
+}
+
+func TestJournal(t *testing.T) {
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "left.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	watcher := events.NewWatcher(events.Options{
+		Manager:   manager,
+		Collector: stats.NewCollector(),
+	})
+	defer watcher.Close()
+
+	j, err := New(Options{Watcher: watcher, Folder: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	j.Start(func(err error) { t.Error(err) })
+
+	// The known stream is journaled first
+	deadline := time.Now().Add(5 * time.Second)
+	for len(readAll(t, j.Path(), Filter{Types: []string{events.TypeStreamAdded}})) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream-added was not journaled")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := j.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+
+	entries := readAll(t, j.Path(), Filter{})
+
+	var types []string
+	for _, e := range entries {
+		types = append(types, e.Type)
+	}
+
+	if want := "session-started stream-added session-stopped"; strings.Join(types, " ") != want {
+		t.Fatalf("journaled %v, want %s", types, want)
+	}
+
+	if got := Format(entries[1]); !strings.Contains(got, s.IDHash()+"  Stage Left (239.1.1.1:5004) via Manual@left.sdp") {
+		t.Errorf("Format() = %q", got)
+	}
+
+	// Session entries pass the stream filter
+	if got := readAll(t, j.Path(), Filter{IDHashes: []string{"0000000000"}}); len(got) != 2 {
+		t.Errorf("filtered %d entries, want the 2 session entries", len(got))
+	}
+
+	if got := readAll(t, j.Path(), Filter{Since: time.Now().Add(time.Hour)}); len(got) != 0 {
+		t.Errorf("filtered %d entries from the future", len(got))
+	}
+}
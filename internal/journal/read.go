@@ -0,0 +1,169 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+// maxLineSize is the longest line read, enough for stream events with large
+// SDPs
+const maxLineSize = 1 << 20
+
+// Entry is an event read from a journal, with its data left encoded
+type Entry struct {
+	Type   string          `json:"type"`
+	Time   time.Time       `json:"time"`
+	IDHash string          `json:"id-hash,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+
+	// Line is the line the entry was read from
+	Line []byte `json:"-"`
+}
+
+// Read calls fn for each entry of a journal. Reading stops at the first
+// error returned by fn.
+func Read(r io.Reader, fn func(Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+
+		e.Line = slices.Clone(line)
+
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Filter selects entries. Empty fields match all entries.
+type Filter struct {
+	Types    []string
+	IDHashes []string
+	Since    time.Time
+	Until    time.Time
+}
+
+// Match returns whether an entry passes the filter. Session entries pass
+// the stream filter, so the sessions remain visible.
+func (f Filter) Match(e Entry) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+
+	if len(f.IDHashes) > 0 && !slices.Contains(f.IDHashes, e.IDHash) &&
+		e.Type != TypeSessionStarted && e.Type != TypeSessionStopped {
+		return false
+	}
+
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Format returns a one-line description of an entry, e.g.
+// "2026-10-15 05:47:26.123  stream-added       71cb8481ed  Stage (239.1.1.1:5004) via SAP@eth0"
+func Format(e Entry) string {
+	idHash := e.IDHash
+	if idHash == "" {
+		idHash = "-"
+	}
+
+	return fmt.Sprintf("%s  %-18s %-10s  %s",
+		e.Time.Local().Format("2006-01-02 15:04:05.000"), e.Type, idHash, describe(e))
+}
+
+// describe summarizes the data of an entry
+func describe(e Entry) string {
+	switch e.Type {
+	case events.TypeStreamAdded, events.TypeStreamUpdated, events.TypeStreamRemoved:
+		var data inventory.Entry
+		if json.Unmarshal(e.Data, &data) != nil {
+			break
+		}
+
+		addresses := make([]string, len(data.Sources))
+		for i, source := range data.Sources {
+			addresses[i] = fmt.Sprintf("%s:%d", source.Destination, source.Port)
+		}
+
+		return fmt.Sprintf("%s (%s) via %s", data.Name, strings.Join(addresses, ", "), data.Discovery)
+
+	case events.TypeAlert:
+		var data events.Alert
+		if json.Unmarshal(e.Data, &data) != nil {
+			break
+		}
+
+		return fmt.Sprintf("%s: %s", strings.ToUpper(data.Level), data.Message)
+
+	case events.TypePTPGrandmaster:
+		var data events.GrandmasterChange
+		if json.Unmarshal(e.Data, &data) != nil {
+			break
+		}
+
+		if data.Previous == "" {
+			return fmt.Sprintf("domain %d: grandmaster %s", data.Domain, data.Grandmaster)
+		}
+
+		return fmt.Sprintf("domain %d: grandmaster changed from %s to %s", data.Domain, data.Previous, data.Grandmaster)
+
+	case events.TypeRecordingStarted, events.TypeRecordingStopped:
+		var data events.Recording
+		if json.Unmarshal(e.Data, &data) != nil {
+			break
+		}
+
+		s := fmt.Sprintf("%s to %s", data.Name, strings.Join(data.Files, ", "))
+		if data.RecordedSeconds > 0 {
+			s += fmt.Sprintf(", %s recorded", time.Duration(data.RecordedSeconds*float64(time.Second)).Round(time.Second))
+		}
+
+		if data.Error != "" {
+			s += ", error: " + data.Error
+		}
+
+		return s
+
+	case TypeSessionStarted, TypeSessionStopped:
+		var data Session
+		if json.Unmarshal(e.Data, &data) != nil {
+			break
+		}
+
+		version := data.Version
+		if version == "" {
+			version = "unknown version"
+		}
+
+		return fmt.Sprintf("%s on %s, pid %d", version, data.Hostname, data.PID)
+	}
+
+	return string(e.Data)
+}
@@ -0,0 +1,269 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action identifies something the user can trigger with a key
+type Action string
+
+const (
+	ActionUp              Action = "up"
+	ActionDown            Action = "down"
+	ActionPageUp          Action = "page-up"
+	ActionPageDown        Action = "page-down"
+	ActionHome            Action = "home"
+	ActionEnd             Action = "end"
+	ActionJump            Action = "jump"
+	ActionQuit            Action = "quit"
+	ActionClose           Action = "close"
+	ActionHelp            Action = "help"
+	ActionCopy            Action = "copy"
+	ActionSave            Action = "save"
+	ActionDetails         Action = "details"
+	ActionFpgaRx          Action = "fpga-rx"
+	ActionFpgaTx          Action = "fpga-tx"
+	ActionMeters          Action = "meters"
+	ActionSDP             Action = "sdp"
+	ActionRTCP            Action = "rtcp"
+	ActionRecord          Action = "record"
+	ActionSplitView       Action = "split-view"
+	ActionGroup           Action = "group"
+	ActionCollapse        Action = "collapse"
+	ActionFavorite        Action = "favorite"
+	ActionFavoritesOnly   Action = "favorites-only"
+	ActionExpected        Action = "expected"
+	ActionAnnotate        Action = "annotate"
+	ActionMark            Action = "mark"
+	ActionMarkAll         Action = "mark-all"
+	ActionExportSDP       Action = "export-sdp"
+	ActionExportTable     Action = "export-table"
+	ActionScreenshot      Action = "screenshot"
+	ActionBackgroundStats Action = "background-stats"
+	ActionCompare         Action = "compare"
+	ActionInterface       Action = "interface"
+	ActionIGMP            Action = "igmp"
+	ActionConformance     Action = "conformance"
+	ActionSettings        Action = "settings"
+	ActionStatus          Action = "status"
+	ActionNextTab         Action = "next-tab"
+	ActionPrevTab         Action = "prev-tab"
+
+	// Actions of single modals
+	ActionRTCPLog     Action = "rtcp-log"
+	ActionPause       Action = "pause"
+	ActionClear       Action = "clear"
+	ActionFilterType  Action = "filter-type"
+	ActionFilterSSRC  Action = "filter-ssrc"
+	ActionMeterLayout Action = "meter-layout"
+	ActionNextSource  Action = "next-source"
+	ActionPrevSource  Action = "prev-source"
+	ActionEdit        Action = "edit"
+	ActionMarkStats   Action = "mark-stats"
+	ActionClearMark   Action = "clear-mark"
+)
+
+// Binding describes the keys bound to an action
+type Binding struct {
+	Action      Action
+	Keys        []string
+	Description string
+}
+
+// defaultBindings lists all actions in the order they are shown in the help
+var defaultBindings = []Binding{
+	{ActionUp, []string{"up", "k"}, "Move selection up"},
+	{ActionDown, []string{"down", "j"}, "Move selection down"},
+	{ActionPageUp, []string{"pgup"}, "Move up one page"},
+	{ActionPageDown, []string{"pgdown"}, "Move down one page"},
+	{ActionHome, []string{"home"}, "Go to first entry"},
+	{ActionEnd, []string{"end"}, "Go to last entry"},
+	{ActionJump, []string{"'"}, "Jump to streams by typing the first letter of their name"},
+	{ActionCopy, []string{"c"}, "Copy modal content, or the marked or selected streams as SDP, JSON, address or RTSP URL"},
+	{ActionSave, []string{"w"}, "Save modal content to a timestamped text file"},
+	{ActionDetails, []string{"d"}, "Show stream details"},
+	{ActionFpgaRx, []string{"f"}, "Show FPGA RX modal"},
+	{ActionFpgaTx, []string{"T"}, "Loop the selected stream back through the FPGA as a test stream"},
+	{ActionMeters, []string{"m"}, "Show live meters"},
+	{ActionSDP, []string{"s"}, "Show SDP"},
+	{ActionRTCP, []string{"r"}, "Show RTCP log"},
+	{ActionRecord, []string{"R"}, "Record WAV files of the marked or selected streams"},
+	{ActionSplitView, []string{"v"}, "Toggle split view with detail pane"},
+	{ActionGroup, []string{"g"}, "Group streams by sender, by clock domain or not at all"},
+	{ActionCollapse, []string{"enter"}, "Collapse or expand the selected group"},
+	{ActionFavorite, []string{"*"}, "Mark or unmark the selected stream as favorite"},
+	{ActionFavoritesOnly, []string{"F"}, "Show favorites only"},
+	{ActionExpected, []string{"!"}, "Mark or unmark the selected stream as expected, alerting when it disappears or stops"},
+	{ActionAnnotate, []string{"n"}, "Edit the alias and note of the selected stream"},
+	{ActionMark, []string{" "}, "Mark or unmark the selected stream for batch actions"},
+	{ActionMarkAll, []string{"a"}, "Mark all visible streams, or clear all marks"},
+	{ActionExportSDP, []string{"e"}, "Export SDPs of the marked or selected streams to files"},
+	{ActionExportTable, []string{"E"}, "Export the stream list to CSV and JSON files"},
+	{ActionScreenshot, []string{"P"}, "Save the screen to text, ANSI and HTML files"},
+	{ActionBackgroundStats, []string{"b"}, "Toggle background statistics for the marked or selected streams"},
+	{ActionCompare, []string{"="}, "Compare the two marked streams side by side"},
+	{ActionInterface, []string{"I"}, "Cycle the interface the selected stream is received on"},
+	{ActionIGMP, []string{"N"}, "Show IGMP and multicast join diagnostics"},
+	{ActionConformance, []string{"A"}, "Check the selected stream against AES67 and ST 2110-30"},
+	{ActionSettings, []string{"o"}, "Show settings"},
+	{ActionStatus, []string{"S"}, "Show memory usage, runtime status, interface bandwidth and unavailable features"},
+	{ActionHelp, []string{"?"}, "Show key bindings"},
+	{ActionNextTab, []string{"tab"}, "Switch to next modal tab (1-9 select directly)"},
+	{ActionPrevTab, []string{"shift+tab"}, "Switch to previous modal tab"},
+	{ActionClose, []string{"x", "esc"}, "Close modal tab"},
+	{ActionQuit, []string{"q", "ctrl+c"}, "Quit (closes modal if open)"},
+	{ActionMarkStats, []string{"M"}, "Details: mark the statistics to compare against"},
+	{ActionClearMark, []string{"U"}, "Details: clear the mark"},
+	{ActionMeterLayout, []string{"L"}, "Meters: switch between the bar, compact and vertical layout"},
+	{ActionNextSource, []string{"]"}, "Meters: show the next source"},
+	{ActionPrevSource, []string{"["}, "Meters: show the previous source"},
+	{ActionRTCPLog, []string{"l"}, "RTCP: switch between the analysis and the packet log"},
+	{ActionPause, []string{"p"}, "RTCP: pause the log; Recording: pause or resume"},
+	{ActionClear, []string{"C"}, "RTCP: clear the log; FPGA: reset the counters"},
+	{ActionFilterType, []string{"t"}, "RTCP: cycle the packet type shown in the log"},
+	{ActionFilterSSRC, []string{"i"}, "RTCP: cycle the SSRC shown in the log"},
+	{ActionEdit, []string{"ctrl+e"}, "FPGA RX: edit the parameters"},
+}
+
+// KeyMap maps keys to actions
+type KeyMap struct {
+	bindings []Binding
+	actions  map[string]Action
+}
+
+// New creates a key map from the default bindings, replacing the keys of every
+// action listed in overrides. An error is returned for unknown actions and
+// for keys bound to more than one action.
+func New(overrides map[string][]string) (*KeyMap, error) {
+	km := &KeyMap{
+		bindings: make([]Binding, len(defaultBindings)),
+		actions:  make(map[string]Action),
+	}
+
+	known := make(map[Action]int)
+
+	for i, b := range defaultBindings {
+		km.bindings[i] = Binding{
+			Action:      b.Action,
+			Keys:        append([]string{}, b.Keys...),
+			Description: b.Description,
+		}
+		known[b.Action] = i
+	}
+
+	// Apply overrides in a stable order so errors are deterministic
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		i, ok := known[Action(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+
+		km.bindings[i].Keys = append([]string{}, overrides[name]...)
+	}
+
+	var conflicts []string
+
+	for _, b := range km.bindings {
+		for _, key := range b.Keys {
+			if other, ok := km.actions[key]; ok && other != b.Action {
+				conflicts = append(conflicts, fmt.Sprintf("key %q is bound to both %q and %q", key, other, b.Action))
+				continue
+			}
+
+			km.actions[key] = b.Action
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("conflicting key bindings: %s", strings.Join(conflicts, "; "))
+	}
+
+	return km, nil
+}
+
+// Default returns the key map with the built-in bindings
+func Default() *KeyMap {
+	km, err := New(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return km
+}
+
+// Action returns the action bound to a key, or an empty action
+func (km *KeyMap) Action(key string) Action {
+	return km.actions[key]
+}
+
+// Keys returns the keys bound to an action
+func (km *KeyMap) Keys(action Action) []string {
+	for _, b := range km.bindings {
+		if b.Action == action {
+			return b.Keys
+		}
+	}
+
+	return nil
+}
+
+// Bindings returns all bindings in help order
+func (km *KeyMap) Bindings() []Binding {
+	return km.bindings
+}
+
+// Label returns a short label of the primary key for an action, e.g. "↑"
+func (km *KeyMap) Label(action Action) string {
+	keys := km.Keys(action)
+	if len(keys) == 0 {
+		return "-"
+	}
+
+	return KeyLabel(keys[0])
+}
+
+// KeyLabel returns a human friendly rendering of a key name
+func KeyLabel(key string) string {
+	switch key {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "pgup":
+		return "PgUp"
+	case "pgdown":
+		return "PgDn"
+	case "home":
+		return "Home"
+	case "end":
+		return "End"
+	case "esc":
+		return "Esc"
+	case "enter":
+		return "Enter"
+	case " ":
+		return "Space"
+	case "tab":
+		return "Tab"
+	case "shift+tab":
+		return "Shift+Tab"
+	}
+
+	if strings.HasPrefix(key, "ctrl+") {
+		return "Ctrl+" + strings.ToUpper(strings.TrimPrefix(key, "ctrl+"))
+	}
+
+	return key
+}
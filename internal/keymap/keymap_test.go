@@ -0,0 +1,133 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultBindings(t *testing.T) {
+	km := Default()
+
+	tests := []struct {
+		key      string
+		expected Action
+	}{
+		{"up", ActionUp},
+		{"k", ActionUp},
+		{"down", ActionDown},
+		{"r", ActionRTCP},
+		{"R", ActionRecord},
+		{"q", ActionQuit},
+		{"esc", ActionClose},
+		{"?", ActionHelp},
+		{"z", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := km.Action(tt.key); got != tt.expected {
+				t.Errorf("Action(%q) = %q, want %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	km, err := New(map[string][]string{
+		"rtcp":   {"R"},
+		"record": {"r"},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	if got := km.Action("r"); got != ActionRecord {
+		t.Errorf("Action(\"r\") = %q, want %q", got, ActionRecord)
+	}
+
+	if got := km.Action("R"); got != ActionRTCP {
+		t.Errorf("Action(\"R\") = %q, want %q", got, ActionRTCP)
+	}
+
+	if got := km.Label(ActionRTCP); got != "R" {
+		t.Errorf("Label(ActionRTCP) = %q, want %q", got, "R")
+	}
+}
+
+func TestOverrideReplacesAllKeys(t *testing.T) {
+	km, err := New(map[string][]string{
+		"up": {"h"},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	if got := km.Action("k"); got != "" {
+		t.Errorf("Action(\"k\") = %q, want no action", got)
+	}
+
+	if got := km.Action("h"); got != ActionUp {
+		t.Errorf("Action(\"h\") = %q, want %q", got, ActionUp)
+	}
+}
+
+func TestOverrideModalAction(t *testing.T) {
+	km, err := New(map[string][]string{
+		"pause": {"ctrl+p"},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	if got := km.Action("p"); got != "" {
+		t.Errorf("Action(\"p\") = %q, want no action", got)
+	}
+
+	if got := km.Action("ctrl+p"); got != ActionPause {
+		t.Errorf("Action(\"ctrl+p\") = %q, want %q", got, ActionPause)
+	}
+
+	if got := km.Label(ActionPause); got != "Ctrl+P" {
+		t.Errorf("Label(ActionPause) = %q, want %q", got, "Ctrl+P")
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	_, err := New(map[string][]string{
+		"rtcp": {"R"},
+	})
+	if err == nil {
+		t.Fatal("Expected conflict error")
+	}
+
+	if !strings.Contains(err.Error(), `"R"`) {
+		t.Errorf("Expected error to mention the conflicting key, got %v", err)
+	}
+}
+
+func TestUnknownAction(t *testing.T) {
+	_, err := New(map[string][]string{
+		"does-not-exist": {"x"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for unknown action")
+	}
+}
+
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"up", "↑"},
+		{"pgdown", "PgDn"},
+		{"ctrl+c", "Ctrl+C"},
+		{"R", "R"},
+	}
+
+	for _, tt := range tests {
+		if got := KeyLabel(tt.key); got != tt.expected {
+			t.Errorf("KeyLabel(%q) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+}
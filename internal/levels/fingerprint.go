@@ -0,0 +1,311 @@
+package levels
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+const (
+	// FingerprintFrame is the length of the audio summarized by one frame
+	// of a fingerprint
+	FingerprintFrame = 20 * time.Millisecond
+
+	// fingerprintFrames is the number of frames kept per channel, ten
+	// seconds of audio
+	fingerprintFrames = 500
+
+	// maxFingerprintLag is the largest offset in frames between two
+	// channels that they are matched at, one second
+	maxFingerprintLag = 50
+
+	// minFingerprintOverlap is the number of frames two channels must
+	// overlap by to be compared
+	minFingerprintOverlap = 100
+
+	fingerprintBands = 4
+)
+
+// bandCutoffs are the crossover frequencies in Hz of the bands whose energy
+// makes up a frame of a fingerprint
+var bandCutoffs = [fingerprintBands - 1]float64{200, 1000, 4000}
+
+type fingerprintChannel struct {
+	// lowpass holds the state of the filters at the band cutoffs
+	lowpass [fingerprintBands - 1]float64
+	energy  [fingerprintBands]float64
+
+	frames [fingerprintFrames][fingerprintBands]float64
+	next   int
+	count  int
+}
+
+// Fingerprint summarizes the recent audio of each channel of a stream by
+// the energy in a few frequency bands over time. How the band energies
+// change does not depend on the gain, the codec or the sample rate, so the
+// fingerprints of two streams carrying the same program match.
+type Fingerprint struct {
+	mutex sync.Mutex
+
+	// coefficients of the lowpass filters at bandCutoffs
+	coefficients [fingerprintBands - 1]float64
+
+	frameLength int
+	samples     int
+	channels    []fingerprintChannel
+}
+
+// NewFingerprint creates a fingerprint of the given number of channels at
+// the given sample rate
+func NewFingerprint(channels int, sampleRate uint32) *Fingerprint {
+	f := &Fingerprint{
+		frameLength: max(int(FingerprintFrame.Seconds()*float64(sampleRate)), 1),
+		channels:    make([]fingerprintChannel, channels),
+	}
+
+	for i, cutoff := range bandCutoffs {
+		f.coefficients[i] = 1 - math.Exp(-2*math.Pi*cutoff/float64(max(sampleRate, 1)))
+	}
+
+	return f
+}
+
+// Add accounts samples interleaved by channel
+func (f *Fingerprint) Add(samples []stream.Sample) {
+	channels := len(f.channels)
+	if channels == 0 {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i := 0; i+channels <= len(samples); i += channels {
+		for ch := range f.channels {
+			f.channels[ch].add(float64(int32(samples[i+ch]))/math.MaxInt32, &f.coefficients)
+		}
+
+		f.samples++
+		if f.samples < f.frameLength {
+			continue
+		}
+
+		for ch := range f.channels {
+			f.channels[ch].endFrame(f.frameLength)
+		}
+
+		f.samples = 0
+	}
+}
+
+// add splits a sample into bands and accounts their energy
+func (c *fingerprintChannel) add(s float64, coefficients *[fingerprintBands - 1]float64) {
+	below := 0.0
+
+	for i, a := range coefficients {
+		c.lowpass[i] += a * (s - c.lowpass[i])
+
+		band := c.lowpass[i] - below
+		c.energy[i] += band * band
+		below = c.lowpass[i]
+	}
+
+	band := s - below
+	c.energy[fingerprintBands-1] += band * band
+}
+
+// endFrame stores the band energies of a frame in dB
+func (c *fingerprintChannel) endFrame(length int) {
+	for i, energy := range c.energy {
+		c.frames[c.next][i] = ToDB(energy / float64(length))
+	}
+
+	c.energy = [fingerprintBands]float64{}
+	c.next = (c.next + 1) % fingerprintFrames
+	c.count = min(c.count+1, fingerprintFrames)
+}
+
+// Channels returns the number of channels of the fingerprint
+func (f *Fingerprint) Channels() int {
+	return len(f.channels)
+}
+
+// features returns the change of the band energies from frame to frame of
+// a channel, oldest first
+func (f *Fingerprint) features(channel int) [][fingerprintBands]float64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	c := &f.channels[channel]
+	if c.count < 2 {
+		return nil
+	}
+
+	features := make([][fingerprintBands]float64, c.count-1)
+	first := (c.next - c.count + fingerprintFrames) % fingerprintFrames
+
+	for i := range features {
+		previous := &c.frames[(first+i)%fingerprintFrames]
+		frame := &c.frames[(first+i+1)%fingerprintFrames]
+
+		for band := range frame {
+			features[i][band] = frame[band] - previous[band]
+		}
+	}
+
+	return features
+}
+
+// FingerprintMatch is the result of comparing a channel of two fingerprints
+type FingerprintMatch struct {
+	// Confidence is the correlation of the fingerprints from 0 to 1
+	Confidence float64
+
+	// Offset is how much later the audio arrives in the second fingerprint
+	Offset time.Duration
+}
+
+// Match compares a channel of a with a channel of b at offsets of up to one
+// second. It returns false if either has not received enough audio yet or
+// is silent or constant, as there is nothing to match then.
+func Match(a *Fingerprint, channelA int, b *Fingerprint, channelB int) (FingerprintMatch, bool) {
+	if channelA >= a.Channels() || channelB >= b.Channels() {
+		return FingerprintMatch{}, false
+	}
+
+	// The latest frames of both were received at about the same time
+	fa := a.features(channelA)
+	fb := b.features(channelB)
+
+	n := min(len(fa), len(fb))
+	if n < minFingerprintOverlap {
+		return FingerprintMatch{}, false
+	}
+
+	fa = fa[len(fa)-n:]
+	fb = fb[len(fb)-n:]
+
+	best := FingerprintMatch{Confidence: -1}
+	compared := false
+
+	for lag := -maxFingerprintLag; lag <= maxFingerprintLag; lag++ {
+		// Frame i of a matches frame i+lag of b
+		start := max(0, -lag)
+		end := min(n, n-lag)
+
+		if end-start < minFingerprintOverlap {
+			continue
+		}
+
+		r, ok := correlation(fa[start:end], fb[start+lag:end+lag])
+		if !ok {
+			continue
+		}
+
+		compared = true
+
+		if r > best.Confidence {
+			best = FingerprintMatch{Confidence: r, Offset: time.Duration(lag) * FingerprintFrame}
+		}
+	}
+
+	if !compared {
+		return FingerprintMatch{}, false
+	}
+
+	best.Confidence = min(max(best.Confidence, 0), 1)
+
+	return best, true
+}
+
+// correlation returns the Pearson correlation of two series of features,
+// and false if either of them does not vary
+func correlation(a, b [][fingerprintBands]float64) (float64, bool) {
+	var sumA, sumB, sumAA, sumBB, sumAB float64
+
+	n := float64(len(a) * fingerprintBands)
+
+	for i := range a {
+		for band := range fingerprintBands {
+			x, y := a[i][band], b[i][band]
+
+			sumA += x
+			sumB += y
+			sumAA += x * x
+			sumBB += y * y
+			sumAB += x * y
+		}
+	}
+
+	varA := sumAA - sumA*sumA/n
+	varB := sumBB - sumB*sumB/n
+
+	// Digital silence does not vary at all, dither hardly
+	if varA < n*1e-3 || varB < n*1e-3 {
+		return 0, false
+	}
+
+	return (sumAB - sumA*sumB/n) / math.Sqrt(varA*varB), true
+}
+
+// Fingerprinter receives a stream to fingerprint its audio. Only the first
+// source is used, the others of redundant streams carry the same audio.
+type Fingerprinter struct {
+	mutex       sync.Mutex
+	receiver    *stream.RTPReceiver
+	fingerprint *Fingerprint
+
+	// samples is the decode buffer reused for every packet
+	samples []stream.Sample
+}
+
+// NewFingerprinter starts receiving a stream to fingerprint its audio
+func NewFingerprinter(s *stream.Stream) (*Fingerprinter, error) {
+	f := &Fingerprinter{
+		fingerprint: NewFingerprint(int(s.Description.ChannelCount), s.Description.SampleRate),
+	}
+
+	receiver, err := s.NewRTPReceiver(f.rtpReceiverCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	f.receiver = receiver
+	f.mutex.Unlock()
+
+	return f, nil
+}
+
+func (f *Fingerprinter) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet, _ time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// The callback might fire before NewRTPReceiver() returns
+	if f.receiver == nil || sourceIndex != 0 {
+		return
+	}
+
+	var err error
+
+	f.samples, err = f.receiver.DecodeSamples(f.samples, packet)
+	if err != nil {
+		return
+	}
+
+	f.fingerprint.Add(f.samples)
+}
+
+// Fingerprint returns the fingerprint of the audio received
+func (f *Fingerprinter) Fingerprint() *Fingerprint {
+	return f.fingerprint
+}
+
+// Close stops receiving the stream
+func (f *Fingerprinter) Close() {
+	f.receiver.Close()
+}
@@ -0,0 +1,79 @@
+package levels
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// program returns seconds of audio at 48 kHz whose tones and noise change
+// their level every 50 ms, like program material
+func program(seed uint64, seconds int) []float64 {
+	r := rand.New(rand.NewPCG(seed, seed))
+	samples := make([]float64, seconds*48000)
+
+	var low, high, noise float64
+
+	for i := range samples {
+		if i%2400 == 0 {
+			low, high, noise = r.Float64(), r.Float64(), r.Float64()
+		}
+
+		t := float64(i) / 48000
+		samples[i] = 0.3*low*math.Sin(2*math.Pi*100*t) + 0.3*high*math.Sin(2*math.Pi*2000*t) + 0.2*noise*(r.Float64()-0.5)
+	}
+
+	return samples
+}
+
+// interleave returns two channels of samples scaled by gain
+func interleave(a, b []float64, gain float64) []stream.Sample {
+	samples := make([]stream.Sample, 2*len(a))
+
+	for i := range a {
+		samples[2*i] = stream.Sample(a[i] * gain * math.MaxInt32)
+		samples[2*i+1] = stream.Sample(b[i] * gain * math.MaxInt32)
+	}
+
+	return samples
+}
+
+func TestFingerprintMatch(t *testing.T) {
+	a := program(1, 12)
+	other := program(2, 12)
+	silence := make([]float64, len(a))
+
+	// The second stream carries the same program 100 ms later, 6 dB lower
+	delay := 4800
+	delayed := append(make([]float64, delay), a[:len(a)-delay]...)
+
+	fa := NewFingerprint(2, 48000)
+	fb := NewFingerprint(2, 48000)
+
+	fa.Add(interleave(a, silence, 1))
+	fb.Add(interleave(delayed, other, 0.5))
+
+	match, ok := Match(fa, 0, fb, 0)
+	if !ok || match.Confidence < 0.9 || match.Offset != 100*time.Millisecond {
+		t.Errorf("same program: got %+v, %v; want a match 100 ms later", match, ok)
+	}
+
+	if match, ok := Match(fa, 0, fb, 1); !ok || match.Confidence > 0.5 {
+		t.Errorf("other program: got %+v, %v; want no match", match, ok)
+	}
+
+	if _, ok := Match(fa, 1, fb, 0); ok {
+		t.Error("silence compared")
+	}
+
+	if _, ok := Match(fa, 0, fb, 2); ok {
+		t.Error("unknown channel compared")
+	}
+
+	if _, ok := Match(NewFingerprint(2, 48000), 0, fb, 0); ok {
+		t.Error("empty fingerprint compared")
+	}
+}
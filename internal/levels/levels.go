@@ -0,0 +1,139 @@
+// Package levels measures the audio levels of all channels of a stream.
+package levels
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+// SilenceDB is the level reported for silence instead of -Inf
+const SilenceDB = -100
+
+// ChannelLevel is the level of one channel in dBFS
+type ChannelLevel struct {
+	RMS  float64 `json:"rms"`
+	Peak float64 `json:"peak"`
+}
+
+// IsAudio reports whether levels can be measured for a stream, see
+// StreamDescription.SamplesError for why not
+func IsAudio(s *stream.Stream) bool {
+	return s.Description.SamplesError() == nil
+}
+
+// channelPower accumulates the squared samples of a channel
+type channelPower struct {
+	sum   float64
+	peak  float64
+	count int
+}
+
+// Meter measures the levels of all channels of a stream between two calls
+// of Levels
+type Meter struct {
+	mutex    sync.Mutex
+	receiver *stream.RTPReceiver
+	sources  [][]channelPower
+
+	// samples is the decode buffer reused for every packet
+	samples []stream.Sample
+}
+
+// NewMeter starts receiving a stream to measure its levels
+func NewMeter(s *stream.Stream) (*Meter, error) {
+	m := &Meter{
+		sources: make([][]channelPower, len(s.Description.Sources)),
+	}
+
+	for i := range m.sources {
+		m.sources[i] = make([]channelPower, s.Description.ChannelCount)
+	}
+
+	receiver, err := s.NewRTPReceiver(m.rtpReceiverCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	m.receiver = receiver
+	m.mutex.Unlock()
+
+	return m, nil
+}
+
+func (m *Meter) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet, _ time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// The callback might fire before NewRTPReceiver() returns
+	if m.receiver == nil || sourceIndex >= len(m.sources) {
+		return
+	}
+
+	var err error
+
+	m.samples, err = m.receiver.DecodeSamples(m.samples, packet)
+	if err != nil || len(m.sources[sourceIndex]) == 0 {
+		return
+	}
+
+	channels := m.sources[sourceIndex]
+
+	for i, value := range m.samples {
+		ch := i % len(channels)
+
+		s := float64(int32(value)) / math.MaxInt32
+		power := s * s
+
+		channels[ch].sum += power
+		channels[ch].peak = max(channels[ch].peak, power)
+		channels[ch].count++
+	}
+}
+
+// ToDB converts a squared sample value to dBFS
+func ToDB(power float64) float64 {
+	if power <= 0 {
+		return SilenceDB
+	}
+
+	return max(10*math.Log10(power), SilenceDB)
+}
+
+// Levels returns the levels of each channel of each source since the last
+// call
+func (m *Meter) Levels() [][]ChannelLevel {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make([][]ChannelLevel, len(m.sources))
+
+	for i, channels := range m.sources {
+		result[i] = make([]ChannelLevel, len(channels))
+
+		for ch, p := range channels {
+			result[i][ch] = ChannelLevel{
+				RMS:  SilenceDB,
+				Peak: ToDB(p.peak),
+			}
+
+			if p.count > 0 {
+				result[i][ch].RMS = ToDB(p.sum / float64(p.count))
+			}
+
+			channels[ch] = channelPower{}
+		}
+	}
+
+	return result
+}
+
+// Close stops receiving the stream
+func (m *Meter) Close() {
+	m.receiver.Close()
+}
@@ -0,0 +1,49 @@
+package levels
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestToDB(t *testing.T) {
+	for power, want := range map[float64]float64{
+		1:     0,
+		0.01:  -20,
+		0:     SilenceDB,
+		1e-20: SilenceDB,
+	} {
+		if got := ToDB(power); math.Abs(got-want) > 1e-9 {
+			t.Errorf("ToDB(%v) = %v, want %v", power, got, want)
+		}
+	}
+}
+
+func TestRunning(t *testing.T) {
+	r := NewRunning(50*time.Millisecond, 48000)
+
+	// A full scale sine has an RMS level of -3 dBFS
+	for i := range 48000 {
+		s := math.Sin(2 * math.Pi * 1000 * float64(i) / 48000)
+		r.Add(s * s)
+	}
+
+	if l := r.Level(); math.Abs(l.RMS+3.01) > 0.1 || math.Abs(l.Peak) > 0.1 {
+		t.Errorf("level of a full scale sine is %.2f/%.2f dBFS, want -3/0", l.RMS, l.Peak)
+	}
+
+	// One window of silence lets both fall by 1/e
+	for range 2400 {
+		r.Add(0)
+	}
+
+	if l := r.Level(); math.Abs(l.Peak+4.34) > 0.1 {
+		t.Errorf("peak fell to %.2f dBFS after a window, want -4.34", l.Peak)
+	}
+
+	r.Reset()
+
+	if l := r.Level(); l.RMS != SilenceDB || l.Peak != SilenceDB {
+		t.Errorf("level after Reset() = %+v, want silence", l)
+	}
+}
@@ -0,0 +1,47 @@
+package levels
+
+import (
+	"math"
+	"time"
+)
+
+// Running measures the level of a channel incrementally as samples arrive.
+// The mean square and the peak decay exponentially with the time constant
+// of the window, so reading the level takes constant time regardless of the
+// window length.
+type Running struct {
+	// decay is the factor applied per sample
+	decay float64
+
+	meanSquare float64
+	peak       float64
+}
+
+// NewRunning creates a level of the given window at the given sample rate
+func NewRunning(window time.Duration, sampleRate uint32) Running {
+	samples := max(window.Seconds()*float64(sampleRate), 1)
+
+	return Running{
+		decay: math.Exp(-1 / samples),
+	}
+}
+
+// Add accounts a squared sample value
+func (r *Running) Add(power float64) {
+	r.meanSquare = r.decay*r.meanSquare + (1-r.decay)*power
+	r.peak = max(r.decay*r.peak, power)
+}
+
+// Reset drops the level to silence
+func (r *Running) Reset() {
+	r.meanSquare = 0
+	r.peak = 0
+}
+
+// Level returns the RMS and peak level in dBFS
+func (r *Running) Level() ChannelLevel {
+	return ChannelLevel{
+		RMS:  ToDB(r.meanSquare),
+		Peak: ToDB(r.peak),
+	}
+}
@@ -0,0 +1,146 @@
+// Package logfile implements a log file that is rotated by size, so the log
+// of a long-running monitor does not fill up the disk.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Writer appends to a log file. Once the file would grow beyond the maximum
+// size, it is renamed to <path>.1, older files move up by one, and a new file
+// is started. Only the given number of rotated files are kept.
+type Writer struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mutex sync.Mutex
+	file  *os.File
+	size  int64
+}
+
+// Open opens or creates the log file at path
+func Open(path string, maxSize int64, maxFiles int) (*Writer, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("maximum size must be positive")
+	}
+
+	if maxFiles < 0 {
+		return nil, fmt.Errorf("number of rotated files must not be negative")
+	}
+
+	w := &Writer{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	w.file, w.size = f, info.Size()
+
+	return nil
+}
+
+// rotatedPath returns the path of the nth rotated file
+func (w *Writer) rotatedPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+// rotate moves the current file aside and starts a new one. If moving fails,
+// the current file is reopened so logging continues.
+func (w *Writer) rotate() error {
+	_ = w.file.Close()
+
+	err := w.shift()
+
+	if openErr := w.open(); openErr != nil {
+		w.file = nil
+		return openErr
+	}
+
+	return err
+}
+
+// shift renames the log file and the rotated files up by one, dropping the
+// oldest
+func (w *Writer) shift() error {
+	if w.maxFiles == 0 {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+
+		return nil
+	}
+
+	_ = os.Remove(w.rotatedPath(w.maxFiles))
+
+	for n := w.maxFiles - 1; n > 0; n-- {
+		if err := os.Rename(w.rotatedPath(n), w.rotatedPath(n+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	if err := os.Rename(w.path, w.rotatedPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return nil
+}
+
+// Write appends p to the log file, rotating it first if needed. A single
+// write is never split across files.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		// Failing to rotate only loses log lines if the file is gone
+		if err := w.rotate(); err != nil && w.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
@@ -0,0 +1,85 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rtp-monitor.log")
+
+	w, err := Open(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for p, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		if got := readFile(t, p); got != want {
+			t.Errorf("%s contains %q, want %q", filepath.Base(p), got, want)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more rotated files than configured are kept")
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rtp-monitor.log")
+
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing content counts towards the size
+	for _, line := range []string{"new\n", "newer\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.Close()
+
+	if got := readFile(t, path+".1"); got != "old\nnew\n" {
+		t.Errorf("rotated file contains %q", got)
+	}
+
+	if got := readFile(t, path); got != "newer\n" {
+		t.Errorf("log file contains %q", got)
+	}
+
+	if _, err := w.Write([]byte("closed\n")); err == nil {
+		t.Errorf("writing to a closed log file succeeded")
+	}
+}
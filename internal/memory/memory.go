@@ -0,0 +1,116 @@
+// Package memory keeps the bytes held by buffers that grow at runtime, such
+// as packet queues, recording queues and logs, within a budget shared by all
+// of them. Buffers that would exceed the budget drop data instead of growing,
+// so long-running probes degrade rather than run out of memory.
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultLimit is the budget unless SetLimit is called
+const DefaultLimit = 256 << 20
+
+var (
+	limit atomic.Int64
+	used  atomic.Int64
+
+	accountsMutex sync.Mutex
+	accounts      []*Account
+)
+
+func init() {
+	limit.Store(DefaultLimit)
+}
+
+// Account is the share of one kind of buffer in the budget
+type Account struct {
+	name   string
+	used   atomic.Int64
+	denied atomic.Uint64
+}
+
+// NewAccount registers an account with the given name. Accounts are meant to
+// be created once per kind of buffer, in package variables.
+func NewAccount(name string) *Account {
+	a := &Account{name: name}
+
+	accountsMutex.Lock()
+	accounts = append(accounts, a)
+	accountsMutex.Unlock()
+
+	return a
+}
+
+// Reserve adds n bytes to the account and returns true, or returns false
+// and counts a denial if that would exceed the budget. The caller must drop
+// the data it wanted to hold if the reservation is denied.
+func (a *Account) Reserve(n int) bool {
+	if used.Add(int64(n)) > limit.Load() {
+		used.Add(-int64(n))
+		a.denied.Add(1)
+
+		return false
+	}
+
+	a.used.Add(int64(n))
+
+	return true
+}
+
+// Release returns n reserved bytes to the budget
+func (a *Account) Release(n int) {
+	a.used.Add(-int64(n))
+	used.Add(-int64(n))
+}
+
+// SetLimit sets the number of bytes all accounts may hold together. Lowering
+// it below the current usage denies new reservations until enough is
+// released.
+func SetLimit(n int64) {
+	limit.Store(n)
+}
+
+// Limit returns the number of bytes all accounts may hold together
+func Limit() int64 {
+	return limit.Load()
+}
+
+// Used returns the number of bytes held by all accounts
+func Used() int64 {
+	return used.Load()
+}
+
+// Usage is the state of one account
+type Usage struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+
+	// Denied is the number of reservations denied as the budget was
+	// exhausted, each of which dropped data
+	Denied uint64 `json:"denied"`
+}
+
+// Accounts returns the state of all accounts, sorted by name
+func Accounts() []Usage {
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+
+	usage := make([]Usage, len(accounts))
+	for i, a := range accounts {
+		usage[i] = Usage{
+			Name:   a.name,
+			Bytes:  a.used.Load(),
+			Denied: a.denied.Load(),
+		}
+	}
+
+	slices.SortFunc(usage, func(a, b Usage) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return usage
+}
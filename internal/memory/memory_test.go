@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"testing"
+)
+
+func TestReserve(t *testing.T) {
+	defer SetLimit(Limit())
+	SetLimit(Used() + 1000)
+
+	a := NewAccount("test")
+
+	if !a.Reserve(600) {
+		t.Fatal("reservation within the budget was denied")
+	}
+
+	// Would exceed the budget
+	if a.Reserve(600) {
+		t.Fatal("reservation exceeding the budget was granted")
+	}
+
+	a.Release(600)
+
+	if !a.Reserve(600) {
+		t.Fatal("reservation after release was denied")
+	}
+
+	a.Release(600)
+
+	for _, u := range Accounts() {
+		if u.Name == "test" && (u.Bytes != 0 || u.Denied != 1) {
+			t.Errorf("account holds %d bytes with %d denials, want 0 and 1", u.Bytes, u.Denied)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Package metrics exposes stream and PTP statistics in the Prometheus text
+// exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Handler serves the metrics of the streams known to the manager. Per-source
+// metrics are only available for streams the collector gathers statistics
+// for. ptpMonitor may be nil.
+type Handler struct {
+	manager    *stream.Manager
+	collector  *stats.Collector
+	ptpMonitor *ptp.Monitor
+}
+
+// NewHandler creates a new metrics handler
+func NewHandler(manager *stream.Manager, collector *stats.Collector, ptpMonitor *ptp.Monitor) *Handler {
+	return &Handler{
+		manager:    manager,
+		collector:  collector,
+		ptpMonitor: ptpMonitor,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	streams := h.manager.GetAllStreams()
+
+	_ = Write(w, time.Now(), streams, Snapshots(h.collector, streams), Transmitters(h.ptpMonitor))
+}
+
+// Snapshots returns the statistics the collector gathers for the given
+// streams, by stream ID
+func Snapshots(collector *stats.Collector, streams []*stream.Stream) map[string]stats.Snapshot {
+	snapshots := make(map[string]stats.Snapshot)
+
+	for _, s := range streams {
+		if snapshot, ok := collector.Snapshot(s.ID); ok {
+			snapshots[s.ID] = snapshot
+		}
+	}
+
+	return snapshots
+}
+
+// Transmitters returns the PTP time transmitters seen by the monitor, which
+// may be nil
+func Transmitters(ptpMonitor *ptp.Monitor) []Transmitter {
+	var transmitters []Transmitter
+
+	if ptpMonitor != nil {
+		ptpMonitor.ForEachTransmitter(func(id ptp.ClockIdentity, t *ptp.Transmitter) {
+			// Only announced so far
+			if t.LastTimestamp.IsZero() {
+				return
+			}
+
+			transmitters = append(transmitters, Transmitter{
+				ClockIdentity: id.String(),
+				Domain:        t.Domain,
+				Interface:     t.IfiName,
+				LastTimestamp: t.LastTimestamp.Time,
+			})
+		})
+	}
+
+	return transmitters
+}
+
+// Serve listens on the given address and serves the metrics on /metrics in
+// the background. Listening errors are returned, later errors are reported
+// to errorFn.
+func (h *Handler) Serve(addr string, errorFn func(error)) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", h)
+
+	go func() {
+		if err := http.Serve(l, mux); err != nil {
+			errorFn(err)
+		}
+	}()
+
+	return nil
+}
+
+// Transmitter holds the state of a PTP time transmitter
+type Transmitter struct {
+	ClockIdentity string
+	Domain        uint8
+	Interface     string
+	LastTimestamp time.Time
+}
+
+// writer writes metric families in the text exposition format
+type writer struct {
+	w *bufio.Writer
+}
+
+// family writes the HELP and TYPE lines of a metric
+func (w *writer) family(name, typ, help string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one sample. labels holds pairs of label names and values.
+func (w *writer) sample(name string, value float64, labels ...string) {
+	w.w.WriteString(name)
+
+	if len(labels) > 0 {
+		w.w.WriteString("{")
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.w.WriteString(",")
+			}
+
+			fmt.Fprintf(w.w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+
+		w.w.WriteString("}")
+	}
+
+	w.w.WriteString(" ")
+	w.w.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	w.w.WriteString("\n")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// unixSeconds returns a time as seconds since the epoch, or 0 for the zero time
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return float64(t.UnixNano()) / 1e9
+}
+
+// sourceMetric describes a per-source metric taken from the statistics
+type sourceMetric struct {
+	name  string
+	typ   string
+	help  string
+	value func(stats.SourceSnapshot) float64
+}
+
+var sourceMetrics = []sourceMetric{
+	{"rtp_monitor_source_packets_total", "counter", "Number of received RTP packets.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.Packets) }},
+	{"rtp_monitor_source_bytes_total", "counter", "Number of received RTP bytes, including headers.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.Bytes) }},
+	{"rtp_monitor_source_packet_rate", "gauge", "Received packets per second.",
+		func(s stats.SourceSnapshot) float64 { return s.PacketRate }},
+	{"rtp_monitor_source_bitrate_bits_per_second", "gauge", "Received bits per second, including RTP headers.",
+		func(s stats.SourceSnapshot) float64 { return s.Bitrate }},
+	{"rtp_monitor_source_sequence_errors_total", "counter", "Number of lost or reordered packets.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.SequenceErrors) }},
+	{"rtp_monitor_source_lost_packets", "gauge", "Number of lost packets, from the extended sequence numbers. Negative for duplicates.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.Lost) }},
+	{"rtp_monitor_source_reordered_total", "counter", "Number of packets received late or duplicated.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.Reordered) }},
+	{"rtp_monitor_source_rtp_errors_total", "counter", "Number of packets that could not be parsed.",
+		func(s stats.SourceSnapshot) float64 { return float64(s.RTPErrors) }},
+	{"rtp_monitor_source_jitter_seconds", "gauge", "Interarrival jitter as defined in RFC 3550.",
+		func(s stats.SourceSnapshot) float64 { return s.Jitter.Seconds() }},
+	{"rtp_monitor_source_last_packet_timestamp_seconds", "gauge", "Time of the last received packet.",
+		func(s stats.SourceSnapshot) float64 { return unixSeconds(s.LastPacket) }},
+}
+
+// Write writes the metrics of the given streams, their statistics by stream
+// ID, and the PTP transmitters
+func Write(out io.Writer, now time.Time, streams []*stream.Stream, snapshots map[string]stats.Snapshot, transmitters []Transmitter) error {
+	w := &writer{w: bufio.NewWriter(out)}
+
+	w.family("rtp_monitor_streams", "gauge", "Number of discovered streams.")
+	w.sample("rtp_monitor_streams", float64(len(streams)))
+
+	w.family("rtp_monitor_stream_info", "gauge", "Discovered stream, with its properties as labels.")
+	for _, s := range streams {
+		w.sample("rtp_monitor_stream_info", 1,
+			"id_hash", s.IDHash(), "name", s.Name(), "address", s.Address(),
+			"codec", s.CodecInfo(), "discovery", s.DiscoveryLabel())
+	}
+
+	w.family("rtp_monitor_stream_last_announced_timestamp_seconds", "gauge", "Time of the last SAP announcement.")
+	for _, s := range streams {
+		if t := s.LastAnnounced(); !t.IsZero() {
+			w.sample("rtp_monitor_stream_last_announced_timestamp_seconds", unixSeconds(t),
+				"id_hash", s.IDHash(), "name", s.Name())
+		}
+	}
+
+	for _, m := range sourceMetrics {
+		w.family(m.name, m.typ, m.help)
+
+		for _, s := range streams {
+			snapshot, ok := snapshots[s.ID]
+			if !ok {
+				continue
+			}
+
+			for i, source := range snapshot.Sources {
+				w.sample(m.name, m.value(source),
+					"id_hash", s.IDHash(), "name", s.Name(), "source", strconv.Itoa(i+1))
+			}
+		}
+	}
+
+	w.family("rtp_monitor_ptp_transmitters", "gauge", "Number of PTP time transmitters seen.")
+	w.sample("rtp_monitor_ptp_transmitters", float64(len(transmitters)))
+
+	w.family("rtp_monitor_ptp_timestamp_age_seconds", "gauge", "Time since the last timestamp of a PTP time transmitter.")
+	for _, t := range transmitters {
+		w.sample("rtp_monitor_ptp_timestamp_age_seconds", now.Sub(t.LastTimestamp).Seconds(),
+			"clock_identity", t.ClockIdentity, "domain", strconv.Itoa(int(t.Domain)), "interface", t.Interface)
+	}
+
+	return w.w.Flush()
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+func TestWrite(t *testing.T) {
+	now := time.Unix(2000, 0)
+
+	s := &stream.Stream{
+		ID: "test",
+		Description: stream.StreamDescription{
+			Name: `Stage "left"`,
+		},
+	}
+
+	snapshots := map[string]stats.Snapshot{
+		"test": {
+			Sources: []stats.SourceSnapshot{
+				{Packets: 1000, PacketRate: 1000, Jitter: 250 * time.Microsecond, LastPacket: now},
+				{Packets: 998, SequenceErrors: 2},
+			},
+		},
+	}
+
+	transmitters := []Transmitter{
+		{ClockIdentity: "00-11-22-ff-fe-33-44-55", Domain: 0, Interface: "eth0", LastTimestamp: now.Add(-500 * time.Millisecond)},
+	}
+
+	var b bytes.Buffer
+	if err := Write(&b, now, []*stream.Stream{s}, snapshots, transmitters); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE rtp_monitor_streams gauge\nrtp_monitor_streams 1\n",
+		`name="Stage \"left\""`,
+		`rtp_monitor_source_packets_total{id_hash="` + s.IDHash() + `",name="Stage \"left\"",source="1"} 1000`,
+		`rtp_monitor_source_sequence_errors_total{id_hash="` + s.IDHash() + `",name="Stage \"left\"",source="2"} 2`,
+		`rtp_monitor_source_jitter_seconds{id_hash="` + s.IDHash() + `",name="Stage \"left\"",source="1"} 0.00025`,
+		`rtp_monitor_source_last_packet_timestamp_seconds{id_hash="` + s.IDHash() + `",name="Stage \"left\"",source="1"} 2000`,
+		"rtp_monitor_ptp_transmitters 1\n",
+		`rtp_monitor_ptp_timestamp_age_seconds{clock_identity="00-11-22-ff-fe-33-44-55",domain="0",interface="eth0"} 0.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// Streams without SAP announcements have no announcement time
+	if strings.Contains(out, "rtp_monitor_stream_last_announced_timestamp_seconds{") {
+		t.Errorf("unexpected announcement time:\n%s", out)
+	}
+}
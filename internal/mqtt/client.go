@@ -0,0 +1,333 @@
+// Package mqtt publishes events to an MQTT broker. It implements the parts
+// of MQTT 3.1.1 needed for that: connecting, publishing with QoS 0 and
+// keeping the connection alive.
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPingreq    = 12
+	packetDisconnect = 14
+)
+
+const (
+	// dialTimeout is how long connecting to the broker may take
+	dialTimeout = 10 * time.Second
+
+	// keepAlive is the keep alive interval announced to the broker
+	keepAlive = 30 * time.Second
+
+	// maxBrokerPacket is the largest packet accepted from the broker. Only
+	// CONNACK and PINGRESP are expected, as nothing is subscribed, so a
+	// larger packet is a broken or hostile broker.
+	maxBrokerPacket = 4096
+)
+
+// connackErrors are the reasons for refused connections
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Client is a connection to an MQTT broker
+type Client struct {
+	mutex sync.Mutex
+	conn  net.Conn
+	err   error
+	done  chan struct{}
+}
+
+// appendString appends a string with its 16-bit length
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// packet creates a control packet with fixed header
+func packet(typ, flags byte, body []byte) []byte {
+	b := []byte{typ<<4 | flags}
+
+	// Remaining length, 7 bits per byte
+	n := len(body)
+	for {
+		digit := byte(n % 128)
+		n /= 128
+
+		if n > 0 {
+			digit |= 0x80
+		}
+
+		b = append(b, digit)
+
+		if n == 0 {
+			break
+		}
+	}
+
+	return append(b, body...)
+}
+
+// Dial connects to the broker at rawURL, which is of the form
+// mqtt://[user:password@]host[:port] or mqtts:// for TLS
+func Dial(rawURL, clientID string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+
+	host := u.Host
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var conn net.Conn
+
+	switch u.Scheme {
+	case "mqtt", "tcp":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "1883")
+		}
+
+		conn, err = dialer.Dial("tcp", host)
+
+	case "mqtts", "ssl", "tls":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "8883")
+		}
+
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+
+	default:
+		return nil, fmt.Errorf("unsupported broker URL scheme %q", u.Scheme)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+
+	// Variable header: protocol name and level, flags, keep alive
+	flags := byte(0x02) // clean session
+	body := appendString(nil, "MQTT")
+	body = append(body, 4)
+
+	username := u.User.Username()
+	password, hasPassword := u.User.Password()
+
+	if username != "" {
+		flags |= 0x80
+	}
+
+	if hasPassword {
+		flags |= 0x40
+	}
+
+	body = append(body, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive/time.Second))
+
+	// Payload
+	body = appendString(body, clientID)
+
+	if username != "" {
+		body = appendString(body, username)
+	}
+
+	if hasPassword {
+		body = appendString(body, password)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	if _, err := conn.Write(packet(packetConnect, 0, body)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+
+	typ, ack, err := readPacket(r, maxBrokerPacket)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+
+	if typ != packetConnack || len(ack) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected packet type %d instead of CONNACK", typ)
+	}
+
+	if code := ack[1]; code != 0 {
+		conn.Close()
+
+		if reason, ok := connackErrors[code]; ok {
+			return nil, fmt.Errorf("connection refused: %s", reason)
+		}
+
+		return nil, fmt.Errorf("connection refused with code %d", code)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn: conn,
+		done: make(chan struct{}),
+	}
+
+	go c.read(r)
+	go c.ping()
+
+	return c, nil
+}
+
+// readPacket reads one control packet of at most maxLength bytes and returns
+// its type and body
+func readPacket(r *bufio.Reader, maxLength int) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length := 0
+
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		length |= int(digit&0x7f) << (7 * i)
+
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	if length > maxLength {
+		return 0, nil, fmt.Errorf("packet of %d bytes exceeds the limit of %d bytes", length, maxLength)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header >> 4, body, nil
+}
+
+// fail closes the connection after an error. Later calls return err.
+func (c *Client) fail(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failLocked(err)
+}
+
+// failLocked is fail for callers that hold c.mutex
+func (c *Client) failLocked(err error) {
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+// read reads packets from the broker until the connection fails. Nothing is
+// subscribed, so only PINGRESP packets are expected.
+func (c *Client) read(r *bufio.Reader) {
+	for {
+		if _, _, err := readPacket(r, maxBrokerPacket); err != nil {
+			c.fail(fmt.Errorf("connection lost: %w", err))
+			return
+		}
+	}
+}
+
+// ping keeps the connection alive
+func (c *Client) ping() {
+	ticker := time.NewTicker(keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(packet(packetPingreq, 0, nil)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends a packet
+func (c *Client) write(p []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+
+	if _, err := c.conn.Write(p); err != nil {
+		c.failLocked(fmt.Errorf("connection lost: %w", err))
+		return c.err
+	}
+
+	return nil
+}
+
+// Publish publishes a message with QoS 0. Retained messages are kept by the
+// broker for clients that subscribe later.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	var flags byte
+	if retain {
+		flags = 0x01
+	}
+
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+
+	return c.write(packet(packetPublish, flags, body))
+}
+
+// Done returns a channel that is closed when the connection is lost
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was lost, or nil
+func (c *Client) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+// Close disconnects from the broker
+func (c *Client) Close() {
+	_ = c.write(packet(packetDisconnect, 0, nil))
+	c.fail(errors.New("client closed"))
+}
@@ -0,0 +1,187 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+type message struct {
+	topic   string
+	payload string
+	retain  bool
+}
+
+// broker accepts one connection, checks the CONNECT packet and sends the
+// published messages to the returned channel
+func broker(t *testing.T) (string, chan message) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	messages := make(chan message, 16)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+
+		typ, body, err := readPacket(r, 1<<20)
+		if err != nil || typ != packetConnect {
+			t.Errorf("expected CONNECT, got %d, %v", typ, err)
+			return
+		}
+
+		// Protocol name "MQTT", level 4, flags with user name and password
+		if string(body[2:6]) != "MQTT" || body[6] != 4 || body[7] != 0xc2 {
+			t.Errorf("unexpected CONNECT header % x", body[:10])
+		}
+
+		_, _ = conn.Write([]byte{packetConnack << 4, 2, 0, 0})
+
+		for {
+			header, err := r.Peek(1)
+			if err != nil {
+				return
+			}
+
+			retain := header[0]&0x01 != 0
+
+			typ, body, err := readPacket(r, 1<<20)
+			if err != nil {
+				return
+			}
+
+			if typ != packetPublish {
+				continue
+			}
+
+			n := binary.BigEndian.Uint16(body)
+			messages <- message{
+				topic:   string(body[2 : 2+n]),
+				payload: string(body[2+n:]),
+				retain:  retain,
+			}
+		}
+	}()
+
+	return "mqtt://user:secret@" + l.Addr().String(), messages
+}
+
+func receive(t *testing.T, messages chan message) message {
+	t.Helper()
+
+	select {
+	case m := <-messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return message{}
+	}
+}
+
+func TestPacketRemainingLength(t *testing.T) {
+	for length, want := range map[int][]byte{
+		0:     {0x00},
+		127:   {0x7f},
+		128:   {0x80, 0x01},
+		16383: {0xff, 0x7f},
+		16384: {0x80, 0x80, 0x01},
+	} {
+		p := packet(packetPublish, 0, make([]byte, length))
+
+		if got := p[1 : 1+len(want)]; string(got) != string(want) {
+			t.Errorf("length %d: got % x, want % x", length, got, want)
+		}
+
+		if len(p) != 1+len(want)+length {
+			t.Errorf("length %d: unexpected packet length %d", length, len(p))
+		}
+	}
+}
+
+func TestReadPacketTooLarge(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader(packet(packetPublish, 0, make([]byte, maxBrokerPacket+1))))
+
+	if _, _, err := readPacket(r, maxBrokerPacket); err == nil {
+		t.Error("expected an error for a packet exceeding the limit")
+	}
+
+	r = bufio.NewReader(bytes.NewReader(packet(packetConnack, 0, []byte{0, 0})))
+
+	if typ, body, err := readPacket(r, maxBrokerPacket); err != nil || typ != packetConnack || len(body) != 2 {
+		t.Errorf("readPacket() = %d, % x, %v, want CONNACK", typ, body, err)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	url, messages := broker(t)
+
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "test.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	watcher := events.NewWatcher(events.Options{
+		Manager:   manager,
+		Collector: stats.NewCollector(),
+	})
+	defer watcher.Close()
+
+	p := NewPublisher(Options{
+		Watcher:       watcher,
+		URL:           url,
+		Topic:         "studio/rtp",
+		StatsInterval: time.Second,
+	})
+
+	p.Start(func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	defer p.Stop()
+
+	if m := receive(t, messages); m.topic != "studio/rtp/streams/"+s.IDHash() || !m.retain {
+		t.Errorf("expected the retained stream, got %+v", m)
+	}
+
+	if m := receive(t, messages); m.topic != "studio/rtp/stream-added/"+s.IDHash() || m.retain {
+		t.Errorf("expected the stream-added event, got %+v", m)
+	}
+
+	manager.RemoveStream(s.ID)
+
+	if m := receive(t, messages); m.topic != "studio/rtp/streams/"+s.IDHash() || !m.retain || m.payload != "" {
+		t.Errorf("expected the retained stream to be cleared, got %+v", m)
+	}
+
+	if m := receive(t, messages); m.topic != "studio/rtp/stream-removed/"+s.IDHash() {
+		t.Errorf("expected the stream-removed event, got %+v", m)
+	}
+}
@@ -0,0 +1,183 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+// reconnectDelay is the time between two connection attempts
+const reconnectDelay = 5 * time.Second
+
+// Options configures a Publisher
+type Options struct {
+	Watcher *events.Watcher
+
+	// URL is the broker URL, see Dial
+	URL string
+
+	// Topic is the prefix of all topics
+	Topic string
+
+	// StatsInterval is the time between two statistics messages of a stream
+	StatsInterval time.Duration
+}
+
+// Publisher publishes events to a broker. Each event is published as JSON on
+// <topic>/<type>/<id-hash>, or <topic>/<type> for events that do not belong
+// to a stream. The current streams are also published as retained messages on
+// <topic>/streams/<id-hash>, so that clients learn about them when they
+// subscribe.
+type Publisher struct {
+	opts     Options
+	clientID string
+	done     chan struct{}
+
+	// streams are the known streams, published again after reconnecting
+	streams map[string]inventory.Entry
+}
+
+// NewPublisher creates a new publisher
+func NewPublisher(opts Options) *Publisher {
+	hostname, _ := os.Hostname()
+
+	return &Publisher{
+		opts:     opts,
+		clientID: fmt.Sprintf("rtp-monitor-%s-%d", hostname, os.Getpid()),
+		done:     make(chan struct{}),
+		streams:  make(map[string]inventory.Entry),
+	}
+}
+
+// Start publishes events in the background until Stop is called. The
+// connection is reestablished when it fails; events that occur while
+// disconnected are lost. Errors are reported to errorFn.
+func (p *Publisher) Start(errorFn func(error)) {
+	ch := p.opts.Watcher.SubscribeWithStreams()
+
+	go func() {
+		defer p.opts.Watcher.Unsubscribe(ch)
+
+		limiter := events.NewStatsLimiter(p.opts.StatsInterval)
+
+		for {
+			client, err := Dial(p.opts.URL, p.clientID)
+			if err != nil {
+				errorFn(err)
+			} else {
+				err := p.run(client, ch, limiter)
+				client.Close()
+
+				if err == nil {
+					return
+				}
+
+				errorFn(err)
+			}
+
+			// Keep track of the streams while disconnected
+			timer := time.NewTimer(reconnectDelay)
+
+		wait:
+			for {
+				select {
+				case <-p.done:
+					timer.Stop()
+					return
+				case event := <-ch:
+					p.track(event)
+				case <-timer.C:
+					break wait
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops publishing and disconnects
+func (p *Publisher) Stop() {
+	close(p.done)
+}
+
+// track updates the known streams. It returns the retained message for the
+// stream state, if the event changed it.
+func (p *Publisher) track(event events.Event) (topic string, payload []byte, ok bool) {
+	switch event.Type {
+	case events.TypeStreamAdded, events.TypeStreamUpdated:
+		entry := event.Data.(inventory.Entry)
+		p.streams[entry.IDHash] = entry
+
+		payload, _ := json.Marshal(entry)
+
+		return p.streamTopic(entry.IDHash), payload, true
+
+	case events.TypeStreamRemoved:
+		delete(p.streams, event.IDHash)
+
+		// An empty retained message removes the retained message
+		return p.streamTopic(event.IDHash), nil, true
+	}
+
+	return "", nil, false
+}
+
+func (p *Publisher) streamTopic(idHash string) string {
+	return p.opts.Topic + "/streams/" + idHash
+}
+
+// eventTopic returns the topic an event is published on
+func (p *Publisher) eventTopic(event events.Event) string {
+	topic := p.opts.Topic + "/" + event.Type
+
+	if event.IDHash != "" {
+		topic += "/" + event.IDHash
+	}
+
+	return topic
+}
+
+// run publishes events until the connection fails or the publisher is stopped,
+// in which case nil is returned
+func (p *Publisher) run(client *Client, ch chan events.Event, limiter *events.StatsLimiter) error {
+	for idHash, entry := range p.streams {
+		payload, _ := json.Marshal(entry)
+
+		if err := client.Publish(p.streamTopic(idHash), payload, true); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-p.done:
+			return nil
+
+		case <-client.Done():
+			return client.Err()
+
+		case event := <-ch:
+			if topic, payload, ok := p.track(event); ok {
+				if err := client.Publish(topic, payload, true); err != nil {
+					return err
+				}
+			}
+
+			if !limiter.Allow(event) {
+				continue
+			}
+
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+			}
+
+			if err := client.Publish(p.eventTopic(event), payload, false); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package osc sends the audio levels of streams as Open Sound Control
+// messages over UDP, for metering bridges and show control systems.
+package osc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// updateInterval is how often the metered streams are updated
+const updateInterval = time.Second
+
+// maxDatagram is the largest bundle sent in one UDP datagram. Larger
+// bundles are split.
+const maxDatagram = 8192
+
+// appendString appends an OSC string, null terminated and padded to four
+// bytes
+func appendString(b []byte, s string) []byte {
+	b = append(b, s...)
+
+	for n := 4 - len(s)%4; n > 0; n-- {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// message encodes a message with float arguments
+func message(address string, args ...float32) []byte {
+	b := appendString(nil, address)
+
+	tags := []byte{','}
+	for range args {
+		tags = append(tags, 'f')
+	}
+
+	b = appendString(b, string(tags))
+
+	for _, arg := range args {
+		b = binary.BigEndian.AppendUint32(b, math.Float32bits(arg))
+	}
+
+	return b
+}
+
+// bundles groups messages into bundles to be executed immediately, each
+// fitting into one datagram
+func bundles(messages [][]byte) [][]byte {
+	var (
+		result [][]byte
+		b      []byte
+	)
+
+	for _, m := range messages {
+		if b != nil && len(b)+4+len(m) > maxDatagram {
+			result = append(result, b)
+			b = nil
+		}
+
+		if b == nil {
+			b = appendString(nil, "#bundle")
+			b = binary.BigEndian.AppendUint64(b, 1) // immediately
+		}
+
+		b = binary.BigEndian.AppendUint32(b, uint32(len(m)))
+		b = append(b, m...)
+	}
+
+	if b != nil {
+		result = append(result, b)
+	}
+
+	return result
+}
+
+// Options configures a Sender
+type Options struct {
+	Manager *stream.Manager
+
+	// Target is the host:port to send to
+	Target string
+
+	// IDHashes are the streams to send the levels of. Streams that are not
+	// discovered yet are metered once they are.
+	IDHashes []string
+
+	// Prefix is the start of all OSC addresses
+	Prefix string
+
+	// Interval is the time between two level updates
+	Interval time.Duration
+}
+
+// Sender sends the levels of streams periodically. The levels of each
+// channel are sent to <prefix>/<id-hash>/<source>/<channel>, both counted
+// from 1, with the RMS and peak level in dBFS as float arguments.
+type Sender struct {
+	opts Options
+	conn net.Conn
+	done chan struct{}
+
+	// meters are the meters of the streams being received, by stream ID
+	meters map[string]*levels.Meter
+
+	// failed are the streams that could not be received, to report errors
+	// only once
+	failed map[string]bool
+}
+
+// NewSender creates a new sender
+func NewSender(opts Options) *Sender {
+	return &Sender{
+		opts:   opts,
+		done:   make(chan struct{}),
+		meters: make(map[string]*levels.Meter),
+		failed: make(map[string]bool),
+	}
+}
+
+// Start sends levels in the background until Stop is called. Errors are
+// reported to errorFn.
+func (s *Sender) Start(errorFn func(error)) error {
+	conn, err := net.Dial("udp", s.opts.Target)
+	if err != nil {
+		return fmt.Errorf("failed to resolve OSC target: %w", err)
+	}
+
+	s.conn = conn
+
+	go func() {
+		defer conn.Close()
+
+		ticker := time.NewTicker(s.opts.Interval)
+		defer ticker.Stop()
+
+		updateTicker := time.NewTicker(updateInterval)
+		defer updateTicker.Stop()
+
+		if err := s.update(); err != nil {
+			errorFn(err)
+		}
+
+		for {
+			select {
+			case <-s.done:
+				for _, m := range s.meters {
+					m.Close()
+				}
+
+				return
+
+			case <-updateTicker.C:
+				if err := s.update(); err != nil {
+					errorFn(err)
+				}
+
+			case <-ticker.C:
+				if err := s.send(); err != nil {
+					errorFn(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops sending
+func (s *Sender) Stop() {
+	close(s.done)
+}
+
+// update starts meters for streams that appeared and stops those of
+// streams that disappeared
+func (s *Sender) update() error {
+	current := make(map[string]bool)
+
+	var errs []error
+
+	for _, st := range s.opts.Manager.GetAllStreams() {
+		if !slices.Contains(s.opts.IDHashes, st.IDHash()) || !levels.IsAudio(st) {
+			continue
+		}
+
+		current[st.ID] = true
+
+		if _, ok := s.meters[st.ID]; ok || s.failed[st.ID] {
+			continue
+		}
+
+		m, err := levels.NewMeter(st)
+		if err != nil {
+			s.failed[st.ID] = true
+			errs = append(errs, fmt.Errorf("failed to receive %s: %w", st.Name(), err))
+
+			continue
+		}
+
+		s.meters[st.ID] = m
+	}
+
+	for id, m := range s.meters {
+		if !current[id] {
+			m.Close()
+			delete(s.meters, id)
+		}
+	}
+
+	for id := range s.failed {
+		if !current[id] {
+			delete(s.failed, id)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send sends the levels of all metered streams
+func (s *Sender) send() error {
+	var messages [][]byte
+
+	for id, m := range s.meters {
+		st, ok := s.opts.Manager.GetStream(id)
+		if !ok {
+			continue
+		}
+
+		for i, channels := range m.Levels() {
+			for ch, level := range channels {
+				address := s.opts.Prefix + "/" + st.IDHash() + "/" + strconv.Itoa(i+1) + "/" + strconv.Itoa(ch+1)
+				messages = append(messages, message(address, float32(level.RMS), float32(level.Peak)))
+			}
+		}
+	}
+
+	for _, b := range bundles(messages) {
+		if _, err := s.conn.Write(b); err != nil {
+			return fmt.Errorf("failed to send OSC bundle: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMessage(t *testing.T) {
+	got := message("/a/1", -20, 0)
+
+	want := []byte("/a/1\x00\x00\x00\x00,ff\x00")
+	want = binary.BigEndian.AppendUint32(want, 0xc1a00000) // -20
+	want = binary.BigEndian.AppendUint32(want, 0)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestBundles(t *testing.T) {
+	m := message("/rtp-monitor/71cb8481ed/1/1", -20, -6)
+
+	var messages [][]byte
+	for range 500 {
+		messages = append(messages, m)
+	}
+
+	result := bundles(messages)
+	if len(result) < 2 {
+		t.Fatalf("expected the messages to be split, got %d bundles", len(result))
+	}
+
+	count := 0
+
+	for _, b := range result {
+		if len(b) > maxDatagram {
+			t.Errorf("bundle of %d bytes exceeds the datagram size", len(b))
+		}
+
+		if !bytes.HasPrefix(b, []byte("#bundle\x00")) {
+			t.Fatalf("missing bundle header in % x", b[:16])
+		}
+
+		for rest := b[16:]; len(rest) > 0; count++ {
+			size := binary.BigEndian.Uint32(rest)
+			if !bytes.Equal(rest[4:4+size], m) {
+				t.Fatalf("unexpected element % x", rest[4:4+size])
+			}
+
+			rest = rest[4+size:]
+		}
+	}
+
+	if count != len(messages) {
+		t.Errorf("got %d messages, want %d", count, len(messages))
+	}
+}
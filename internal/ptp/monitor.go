@@ -1,6 +1,7 @@
 package ptp
 
 import (
+	"encoding/binary"
 	"net"
 	"sort"
 	"sync"
@@ -9,10 +10,30 @@ import (
 	"github.com/holoplot/go-multicast/pkg/multicast"
 )
 
+// Announce holds the grandmaster data of an Announce message
+type Announce struct {
+	Grandmaster   ClockIdentity
+	Priority1     uint8
+	ClockClass    uint8
+	ClockAccuracy uint8
+	Variance      uint16 // offsetScaledLogVariance
+	Priority2     uint8
+	StepsRemoved  uint16
+	TimeSource    uint8
+	UTCOffset     int16
+}
+
 type Transmitter struct {
 	Domain        uint8
-	LastTimestamp Timestamp
+	LastTimestamp Timestamp // zero until a Sync or Follow_Up message is received
 	IfiName       string
+
+	// Messages counts the messages received from the transmitter by type
+	Messages map[string]uint64
+
+	// Announce is the data of the last Announce message, nil if none was
+	// received
+	Announce *Announce
 }
 
 type Monitor struct {
@@ -20,6 +41,8 @@ type Monitor struct {
 	multicastListener *multicast.Listener
 	consumer          *multicast.Consumer
 	transmitters      map[ClockIdentity]*Transmitter
+	grandmasters      map[uint8]ClockIdentity
+	started           time.Time
 }
 
 func (m *Monitor) parsePacket(ifi *net.Interface, _ net.Addr, data []byte) {
@@ -38,6 +61,23 @@ func (m *Monitor) parsePacket(ifi *net.Interface, _ net.Addr, data []byte) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	// transmitter returns the transmitter sending the packet, which is
+	// added if it is not known yet
+	transmitter := func() *Transmitter {
+		t, ok := m.transmitters[clockIdentity]
+		if !ok {
+			t = &Transmitter{
+				Domain:   domainNumber,
+				Messages: make(map[string]uint64),
+			}
+			m.transmitters[clockIdentity] = t
+		}
+
+		t.IfiName = ifi.Name
+
+		return t
+	}
+
 	switch messageType {
 	case messageTypeSync, messageTypeFollowUp:
 		timeStamp := Timestamp{
@@ -50,16 +90,33 @@ func (m *Monitor) parsePacket(ifi *net.Interface, _ net.Addr, data []byte) {
 			return
 		}
 
-		if transmitter, ok := m.transmitters[clockIdentity]; ok {
-			transmitter.LastTimestamp = timeStamp
-			transmitter.IfiName = ifi.Name
-		} else {
-			m.transmitters[clockIdentity] = &Transmitter{
-				Domain:        domainNumber,
-				LastTimestamp: timeStamp,
-				IfiName:       ifi.Name,
-			}
+		transmitter().LastTimestamp = timeStamp
+
+	case messageTypeAnnounce:
+		if len(data) < 64 {
+			return
 		}
+
+		a := &Announce{
+			UTCOffset:     int16(binary.BigEndian.Uint16(data[44:46])),
+			Priority1:     data[47],
+			ClockClass:    data[48],
+			ClockAccuracy: data[49],
+			Variance:      binary.BigEndian.Uint16(data[50:52]),
+			Priority2:     data[52],
+			StepsRemoved:  binary.BigEndian.Uint16(data[61:63]),
+			TimeSource:    data[63],
+		}
+		copy(a.Grandmaster.octets[:], data[53:61])
+
+		transmitter().Announce = a
+		m.grandmasters[domainNumber] = a.Grandmaster
+	}
+
+	// Other messages, e.g. Delay_Resp, are only counted for known
+	// transmitters, as Delay_Req messages are sent by every receiver
+	if t, ok := m.transmitters[clockIdentity]; ok {
+		t.Messages[messageTypeName(messageType)]++
 	}
 }
 
@@ -82,10 +139,58 @@ func (m *Monitor) ForEachTransmitter(fn func(ClockIdentity, *Transmitter)) {
 	}
 }
 
+// TimeAt estimates the PTP time (TAI) at the local time t from the most
+// recent Sync or Follow_Up message of any transmitter. It returns false if
+// none was received.
+func (m *Monitor) TimeAt(t time.Time) (time.Time, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var latest Timestamp
+	for _, transmitter := range m.transmitters {
+		if ts := transmitter.LastTimestamp; !ts.IsZero() && ts.Time.After(latest.Time) {
+			latest = ts
+		}
+	}
+
+	if latest.IsZero() {
+		return time.Time{}, false
+	}
+
+	ptpTime, err := latest.At(t)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return ptpTime, true
+}
+
+// ForEachGrandmaster calls fn with the grandmaster clock identity last
+// announced in each domain, sorted by domain
+func (m *Monitor) ForEachGrandmaster(fn func(domain uint8, id ClockIdentity)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	domains := make([]uint8, 0, len(m.grandmasters))
+	for domain := range m.grandmasters {
+		domains = append(domains, domain)
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i] < domains[j]
+	})
+
+	for _, domain := range domains {
+		fn(domain, m.grandmasters[domain])
+	}
+}
+
 func NewMonitor(ifis []*net.Interface) (*Monitor, error) {
 	m := &Monitor{
+		started:           time.Now(),
 		multicastListener: multicast.NewListener(ifis),
 		transmitters:      make(map[ClockIdentity]*Transmitter),
+		grandmasters:      make(map[uint8]ClockIdentity),
 	}
 
 	addr := &net.UDPAddr{
@@ -96,6 +201,7 @@ func NewMonitor(ifis []*net.Interface) (*Monitor, error) {
 	if c, err := m.multicastListener.AddConsumer(addr, m.parsePacket); err == nil {
 		m.consumer = c
 	} else {
+		m.Close()
 		return nil, err
 	}
 
@@ -104,8 +210,14 @@ func NewMonitor(ifis []*net.Interface) (*Monitor, error) {
 	if c, err := m.multicastListener.AddConsumer(addr, m.parsePacket); err == nil {
 		m.consumer = c
 	} else {
+		m.Close()
 		return nil, err
 	}
 
 	return m, nil
 }
+
+// Close leaves the PTP multicast groups
+func (m *Monitor) Close() {
+	m.multicastListener.Close()
+}
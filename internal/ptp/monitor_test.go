@@ -0,0 +1,152 @@
+package ptp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func announce(domain uint8, grandmaster [8]byte) []byte {
+	data := make([]byte, 64)
+	data[0] = messageTypeAnnounce
+	data[4] = domain
+	copy(data[53:61], grandmaster[:])
+
+	return data
+}
+
+func TestMonitorGrandmasters(t *testing.T) {
+	m := &Monitor{
+		transmitters: make(map[ClockIdentity]*Transmitter),
+		grandmasters: make(map[uint8]ClockIdentity),
+	}
+
+	ifi := &net.Interface{Name: "eth0"}
+
+	m.parsePacket(ifi, nil, announce(1, [8]byte{1, 2, 3, 4, 5, 6, 7, 8}))
+	m.parsePacket(ifi, nil, announce(0, [8]byte{8, 7, 6, 5, 4, 3, 2, 1}))
+	m.parsePacket(ifi, nil, announce(1, [8]byte{0xaa, 2, 3, 4, 5, 6, 7, 8}))
+
+	// Too short to hold a grandmaster identity
+	m.parsePacket(ifi, nil, announce(2, [8]byte{})[:50])
+
+	var got []string
+
+	m.ForEachGrandmaster(func(domain uint8, id ClockIdentity) {
+		got = append(got, fmt.Sprintf("%d %s", domain, id))
+	})
+
+	want := []string{
+		"0 08:07:06:05:04:03:02:01",
+		"1 aa:02:03:04:05:06:07:08",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got[i], want[i])
+		}
+	}
+}
+
+func syncMessage(domain uint8, clockIdentity [8]byte) []byte {
+	data := make([]byte, 44)
+	data[0] = messageTypeSync
+	data[4] = domain
+	copy(data[20:28], clockIdentity[:])
+	data[39] = 1 // seconds
+
+	return data
+}
+
+func TestMonitorReport(t *testing.T) {
+	started := time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)
+
+	m := &Monitor{
+		transmitters: make(map[ClockIdentity]*Transmitter),
+		grandmasters: make(map[uint8]ClockIdentity),
+		started:      started,
+	}
+
+	ifi := &net.Interface{Name: "eth0"}
+	transmitter := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	a := announce(0, transmitter)
+	copy(a[20:28], transmitter[:])
+	a[48] = 6 // clock class
+	a[62] = 1 // steps removed
+
+	for range 16 {
+		m.parsePacket(ifi, nil, syncMessage(0, transmitter))
+	}
+
+	for range 4 {
+		m.parsePacket(ifi, nil, a)
+	}
+
+	// Sent by a receiver, which is not a transmitter
+	delayReq := syncMessage(0, [8]byte{9})
+	delayReq[0] = messageTypeDelayReq
+	m.parsePacket(ifi, nil, delayReq)
+
+	r := m.report(started.Add(2 * time.Second))
+
+	if len(r.Domains) != 1 || len(r.Domains[0].Transmitters) != 1 {
+		t.Fatalf("got domains %+v, want one with one transmitter", r.Domains)
+	}
+
+	d := r.Domains[0]
+	if d.Grandmaster != "01:02:03:04:05:06:07:08" {
+		t.Errorf("grandmaster = %s", d.Grandmaster)
+	}
+
+	tr := d.Transmitters[0]
+	if tr.Announce == nil || tr.Announce.ClockClass != 6 || tr.Announce.StepsRemoved != 1 {
+		t.Errorf("announce = %+v, want clock class 6 and one step removed", tr.Announce)
+	}
+
+	if tr.Messages["Sync"] != 8 || tr.Messages["Announce"] != 2 || len(tr.Messages) != 2 {
+		t.Errorf("message rates = %v, want 8 Sync and 2 Announce per second", tr.Messages)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, "text"); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	for _, want := range []string{"Domain 0", "locked to primary reference", "Sync:", "8.00/s"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestMonitorTimeAt(t *testing.T) {
+	m := &Monitor{
+		transmitters: make(map[ClockIdentity]*Transmitter),
+		grandmasters: make(map[uint8]ClockIdentity),
+	}
+
+	if _, ok := m.TimeAt(time.Now()); ok {
+		t.Error("TimeAt() succeeded without transmitters")
+	}
+
+	m.parsePacket(&net.Interface{Name: "eth0"}, nil, syncMessage(0, [8]byte{1}))
+
+	received := m.transmitters[ClockIdentity{octets: [8]byte{1}}].LastTimestamp.Time
+
+	got, ok := m.TimeAt(received.Add(500 * time.Millisecond))
+	if !ok {
+		t.Fatal("TimeAt() failed")
+	}
+
+	if want := time.Unix(1, 500_000_000); !got.Equal(want) {
+		t.Errorf("TimeAt() = %v, want %v", got, want)
+	}
+}
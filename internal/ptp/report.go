@@ -0,0 +1,210 @@
+package ptp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats lists the supported report formats
+var Formats = []string{"text", "json"}
+
+// clockClasses describes the clock classes defined by IEEE 1588-2008
+var clockClasses = map[uint8]string{
+	6:   "locked to primary reference",
+	7:   "holdover, within specification",
+	13:  "locked to application-specific time",
+	14:  "holdover, application-specific time",
+	52:  "degraded",
+	58:  "degraded, application-specific time",
+	187: "degraded, may be followed",
+	193: "degraded, application-specific time, may be followed",
+	248: "default",
+	255: "slave-only",
+}
+
+// ClockClassDescription returns a description of a clock class
+func ClockClassDescription(clockClass uint8) string {
+	if d, ok := clockClasses[clockClass]; ok {
+		return d
+	}
+
+	return "alternate profile"
+}
+
+// AnnounceReport is the grandmaster data announced by a transmitter
+type AnnounceReport struct {
+	Grandmaster   string `json:"grandmaster"`
+	Priority1     uint8  `json:"priority1"`
+	ClockClass    uint8  `json:"clock-class"`
+	ClockAccuracy uint8  `json:"clock-accuracy"`
+	Variance      uint16 `json:"offset-scaled-log-variance"`
+	Priority2     uint8  `json:"priority2"`
+	StepsRemoved  uint16 `json:"steps-removed"`
+	UTCOffset     int16  `json:"utc-offset"`
+}
+
+// TransmitterReport is a transmitter observed by the monitor
+type TransmitterReport struct {
+	ClockIdentity string          `json:"clock-identity"`
+	Interface     string          `json:"interface"`
+	Announce      *AnnounceReport `json:"announce,omitempty"`
+
+	// Messages is the number of messages per second by type
+	Messages map[string]float64 `json:"messages"`
+}
+
+// DomainReport holds the transmitters of a domain
+type DomainReport struct {
+	Domain       uint8               `json:"domain"`
+	Grandmaster  string              `json:"grandmaster,omitempty"`
+	Transmitters []TransmitterReport `json:"transmitters"`
+}
+
+// Report is what the monitor observed since it was created
+type Report struct {
+	Started  time.Time      `json:"started"`
+	Duration float64        `json:"duration-seconds"`
+	Domains  []DomainReport `json:"domains"`
+}
+
+// Report returns what the monitor observed since it was created
+func (m *Monitor) Report() Report {
+	return m.report(time.Now())
+}
+
+func (m *Monitor) report(now time.Time) Report {
+	r := Report{
+		Started:  m.started,
+		Duration: now.Sub(m.started).Seconds(),
+		Domains:  []DomainReport{},
+	}
+
+	domains := make(map[uint8]*DomainReport)
+
+	domain := func(number uint8) *DomainReport {
+		d, ok := domains[number]
+		if !ok {
+			d = &DomainReport{Domain: number, Transmitters: []TransmitterReport{}}
+			domains[number] = d
+		}
+
+		return d
+	}
+
+	m.ForEachGrandmaster(func(number uint8, id ClockIdentity) {
+		domain(number).Grandmaster = id.String()
+	})
+
+	m.ForEachTransmitter(func(id ClockIdentity, t *Transmitter) {
+		tr := TransmitterReport{
+			ClockIdentity: id.String(),
+			Interface:     t.IfiName,
+			Messages:      make(map[string]float64),
+		}
+
+		for name, count := range t.Messages {
+			tr.Messages[name] = float64(count) / r.Duration
+		}
+
+		if a := t.Announce; a != nil {
+			tr.Announce = &AnnounceReport{
+				Grandmaster:   a.Grandmaster.String(),
+				Priority1:     a.Priority1,
+				ClockClass:    a.ClockClass,
+				ClockAccuracy: a.ClockAccuracy,
+				Variance:      a.Variance,
+				Priority2:     a.Priority2,
+				StepsRemoved:  a.StepsRemoved,
+				UTCOffset:     a.UTCOffset,
+			}
+		}
+
+		d := domain(t.Domain)
+		d.Transmitters = append(d.Transmitters, tr)
+	})
+
+	for _, d := range domains {
+		r.Domains = append(r.Domains, *d)
+	}
+
+	sort.Slice(r.Domains, func(i, j int) bool {
+		return r.Domains[i].Domain < r.Domains[j].Domain
+	})
+
+	return r
+}
+
+// Write writes the report in the given format
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// WriteText writes the report as plain text
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	line := func(label, format string, args ...any) {
+		fmt.Fprintf(tw, "%s\t"+format+"\n", append([]any{label}, args...)...)
+	}
+
+	fmt.Fprintf(tw, "PTP report\n\n")
+
+	line("Started:", "%s", r.Started.Format(time.RFC3339))
+	line("Duration:", "%s", time.Duration(r.Duration*float64(time.Second)).Round(time.Millisecond))
+
+	if len(r.Domains) == 0 {
+		fmt.Fprintf(tw, "\nNo PTP transmitters seen\n")
+	}
+
+	for _, d := range r.Domains {
+		grandmaster := d.Grandmaster
+		if grandmaster == "" {
+			grandmaster = "none announced"
+		}
+
+		fmt.Fprintf(tw, "\nDomain %d: grandmaster %s\n", d.Domain, grandmaster)
+
+		for _, t := range d.Transmitters {
+			fmt.Fprintf(tw, "\n  Transmitter %s on %s\n", t.ClockIdentity, t.Interface)
+
+			if a := t.Announce; a != nil {
+				line("    Grandmaster:", "%s", a.Grandmaster)
+				line("    Clock class:", "%d (%s)", a.ClockClass, ClockClassDescription(a.ClockClass))
+				line("    Clock accuracy:", "0x%02x", a.ClockAccuracy)
+				line("    Variance:", "0x%04x", a.Variance)
+				line("    Priorities:", "%d/%d", a.Priority1, a.Priority2)
+				line("    Steps removed:", "%d", a.StepsRemoved)
+				line("    UTC offset:", "%d s", a.UTCOffset)
+			} else {
+				line("    Grandmaster:", "no Announce messages received")
+			}
+
+			names := make([]string, 0, len(t.Messages))
+			for name := range t.Messages {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			for _, name := range names {
+				line("    "+name+":", "%.2f/s", t.Messages[name])
+			}
+		}
+	}
+
+	return tw.Flush()
+}
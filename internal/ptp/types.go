@@ -20,6 +20,34 @@ const (
 	messageTypeManagement         = 0xd
 )
 
+// messageTypeName returns the name of a message type as in IEEE 1588
+func messageTypeName(messageType uint8) string {
+	switch messageType {
+	case messageTypeSync:
+		return "Sync"
+	case messageTypeDelayReq:
+		return "Delay_Req"
+	case messageTypePDelayReq:
+		return "Pdelay_Req"
+	case messageTypePDelayResp:
+		return "Pdelay_Resp"
+	case messageTypeFollowUp:
+		return "Follow_Up"
+	case messageTypeDelayResp:
+		return "Delay_Resp"
+	case messageTypePDelayRespFollowUp:
+		return "Pdelay_Resp_Follow_Up"
+	case messageTypeAnnounce:
+		return "Announce"
+	case messageTypeSignaling:
+		return "Signaling"
+	case messageTypeManagement:
+		return "Management"
+	default:
+		return fmt.Sprintf("0x%x", messageType)
+	}
+}
+
 type ClockIdentity struct {
 	octets [8]byte
 }
@@ -98,6 +126,17 @@ func (ts Timestamp) asTAI() (time.Time, error) {
 	return epoch.Add(duration), nil
 }
 
+// At estimates the PTP time (TAI) at the local time t, assuming the clock
+// advanced at the same pace since the timestamp was received
+func (ts Timestamp) At(t time.Time) (time.Time, error) {
+	tai, err := ts.asTAI()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return tai.Add(t.Sub(ts.Time)), nil
+}
+
 func (ts Timestamp) AsUTC() string {
 	utc, err := ts.asTAI()
 	if errors.Is(err, ErrTimestampOutOfRange) {
@@ -0,0 +1,210 @@
+// Package receiverreport sends RTCP receiver reports for received streams,
+// so senders that display receiver feedback see the monitor as a diagnostic
+// receiver.
+package receiverreport
+
+import (
+	"math/rand/v2"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/transmit"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp/v2"
+)
+
+// DefaultInterval is the minimum interval between two reports recommended
+// by RFC 3550
+const DefaultInterval = 5 * time.Second
+
+// Options configures the receiver reports
+type Options struct {
+	// Interface to send the reports on, nil for the default route
+	Interface *net.Interface
+
+	// Interval is the average interval between two reports, randomized by
+	// ±50% as in RFC 3550
+	Interval time.Duration
+}
+
+// Reporter receives a stream and sends receiver reports for its sources to
+// the RTCP port of each, until it is closed
+type Reporter struct {
+	opts         Options
+	ssrc         uint32
+	cname        string
+	rtpReceiver  *stream.RTPReceiver
+	rtcpReceiver *stream.RTCPReceiver
+	conns        []*net.UDPConn
+
+	mutex   sync.Mutex
+	sources []*source
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// cname returns the canonical name of the reporter as in RFC 3550
+func cname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+
+	return "rtp-monitor@" + host
+}
+
+// Start starts receiving a stream and sending receiver reports for it
+func Start(s *stream.Stream, opts Options) (*Reporter, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+
+	r := &Reporter{
+		opts:    opts,
+		ssrc:    rand.Uint32(),
+		cname:   cname(),
+		sources: make([]*source, len(s.Description.Sources)),
+		done:    make(chan struct{}),
+	}
+
+	for i, src := range s.Description.Sources {
+		r.sources[i] = &source{clockRate: s.Description.SampleRate}
+
+		conn, err := transmit.Dial(src.RTCPAddr(), opts.Interface, max(int(src.TTL), 1), 0)
+		if err != nil {
+			r.closeConns()
+			return nil, err
+		}
+
+		r.conns = append(r.conns, conn)
+	}
+
+	var err error
+
+	r.rtpReceiver, err = s.NewRTPReceiver(func(sourceIndex int, _ net.Addr, packet *rtp.Packet, arrival time.Time) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if sourceIndex < len(r.sources) {
+			r.sources[sourceIndex].add(arrival, packet.SSRC, packet.SequenceNumber, packet.Timestamp)
+		}
+	})
+	if err != nil {
+		r.closeConns()
+		return nil, err
+	}
+
+	r.rtcpReceiver, err = s.NewRTCPReceiver(func(sourceIndex int, _ net.Addr, packet rtcp.Packet) {
+		sr, ok := packet.(*rtcp.SenderReport)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		if sourceIndex < len(r.sources) && r.sources[sourceIndex].ssrc == sr.SSRC {
+			r.sources[sourceIndex].addSenderReport(now, sr.NTPTime)
+		}
+	})
+	if err != nil {
+		r.rtpReceiver.Close()
+		r.closeConns()
+		return nil, err
+	}
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r, nil
+}
+
+func (r *Reporter) closeConns() {
+	for _, conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// run sends the reports at randomized intervals until the reporter is
+// closed
+func (r *Reporter) run() {
+	defer r.wg.Done()
+
+	for {
+		interval := time.Duration(float64(r.opts.Interval) * (0.5 + rand.Float64()))
+
+		select {
+		case <-r.done:
+			return
+		case <-time.After(interval):
+		}
+
+		r.send()
+	}
+}
+
+// send sends a report for each source packets were received from since the
+// previous report
+func (r *Reporter) send() {
+	now := time.Now()
+
+	for i, conn := range r.conns {
+		r.mutex.Lock()
+		src := r.sources[i]
+		active := src.started && src.sequence.Stats().Received != src.receivedPrior
+		report := src.report(now)
+		r.mutex.Unlock()
+
+		if !active {
+			continue
+		}
+
+		b, err := rtcp.Marshal([]rtcp.Packet{
+			&rtcp.ReceiverReport{
+				SSRC:    r.ssrc,
+				Reports: []rtcp.ReceptionReport{report},
+			},
+			rtcp.NewCNAMESourceDescription(r.ssrc, r.cname),
+		})
+		if err != nil {
+			continue
+		}
+
+		// Sending fails e.g. while the interface is down, and is retried
+		// with the next report
+		_, _ = conn.Write(b)
+	}
+}
+
+// Close stops receiving the stream and sending reports, and says goodbye
+// with an RTCP BYE packet to the sources reports were sent to
+func (r *Reporter) Close() {
+	close(r.done)
+	r.wg.Wait()
+
+	b, err := rtcp.Marshal([]rtcp.Packet{
+		&rtcp.ReceiverReport{SSRC: r.ssrc},
+		rtcp.NewCNAMESourceDescription(r.ssrc, r.cname),
+		&rtcp.Goodbye{Sources: []uint32{r.ssrc}},
+	})
+
+	for i, conn := range r.conns {
+		r.mutex.Lock()
+		reported := r.sources[i].receivedPrior > 0
+		r.mutex.Unlock()
+
+		if err == nil && reported {
+			_, _ = conn.Write(b)
+		}
+	}
+
+	r.rtcpReceiver.Close()
+	r.rtpReceiver.Close()
+	r.closeConns()
+}
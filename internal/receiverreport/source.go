@@ -0,0 +1,128 @@
+package receiverreport
+
+import (
+	"math"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtcp"
+)
+
+// source holds the reception statistics of a stream source as in RFC 3550,
+// appendix A
+type source struct {
+	ssrc      uint32
+	clockRate uint32
+	started   bool
+
+	sequence stream.SequenceTracker
+
+	expectedPrior uint64
+	receivedPrior uint64
+
+	// Interarrival jitter in RTP clock units, appendix A.8
+	firstArrival time.Time
+	transit      float64
+	jitter       float64
+
+	// lastSR is the middle 32 bits of the NTP timestamp of the last sender
+	// report, received at lastSRTime
+	lastSR     uint32
+	lastSRTime time.Time
+}
+
+// add accounts a packet received at the given time
+func (s *source) add(now time.Time, ssrc uint32, seq uint16, timestamp uint32) {
+	// A new SSRC is a new source
+	if !s.started || ssrc != s.ssrc {
+		*s = source{
+			ssrc:      ssrc,
+			clockRate: s.clockRate,
+			started:   true,
+		}
+	}
+
+	switch s.sequence.Update(seq) {
+	case stream.SequenceDropped:
+		return
+	case stream.SequenceReset:
+		// A restart of the sender starts the statistics over
+		*s = source{
+			ssrc:      ssrc,
+			clockRate: s.clockRate,
+			started:   true,
+			sequence:  s.sequence,
+		}
+	}
+
+	// Duplicates and reordered packets are counted as received
+
+	s.addJitter(now, timestamp)
+}
+
+// addJitter updates the interarrival jitter with a packet
+func (s *source) addJitter(now time.Time, timestamp uint32) {
+	if s.clockRate == 0 {
+		return
+	}
+
+	first := s.firstArrival.IsZero()
+	if first {
+		s.firstArrival = now
+	}
+
+	// The arrival time in RTP clock units, relative to the first packet as
+	// only differences matter. Wrap-arounds cancel out in the uint32
+	// difference.
+	arrival := uint32(int64(now.Sub(s.firstArrival).Seconds() * float64(s.clockRate)))
+	transit := float64(int32(arrival - timestamp))
+
+	if !first {
+		s.jitter += (math.Abs(transit-s.transit) - s.jitter) / 16
+	}
+
+	s.transit = transit
+}
+
+// addSenderReport records the NTP timestamp of a sender report received at
+// the given time
+func (s *source) addSenderReport(now time.Time, ntpTime uint64) {
+	s.lastSR = uint32(ntpTime >> 16)
+	s.lastSRTime = now
+}
+
+// report returns the reception report block and starts a new interval, as
+// in RFC 3550, appendix A.3
+func (s *source) report(now time.Time) rtcp.ReceptionReport {
+	stats := s.sequence.Stats()
+
+	// The cumulative number of packets lost is a signed 24 bit number
+	lost := max(min(stats.Lost, 0x7fffff), -0x800000)
+
+	expectedInterval := stats.Expected - s.expectedPrior
+	receivedInterval := stats.Received - s.receivedPrior
+	lostInterval := int64(expectedInterval) - int64(receivedInterval)
+
+	s.expectedPrior = stats.Expected
+	s.receivedPrior = stats.Received
+
+	var fraction uint8
+	if expectedInterval > 0 && lostInterval > 0 {
+		fraction = uint8((lostInterval << 8) / int64(expectedInterval))
+	}
+
+	r := rtcp.ReceptionReport{
+		SSRC:               s.ssrc,
+		FractionLost:       fraction,
+		TotalLost:          uint32(lost) & 0xffffff,
+		LastSequenceNumber: uint32(stats.ExtendedHighest),
+		Jitter:             uint32(s.jitter),
+	}
+
+	if !s.lastSRTime.IsZero() {
+		r.LastSenderReport = s.lastSR
+		r.Delay = uint32(now.Sub(s.lastSRTime).Seconds() * 65536)
+	}
+
+	return r
+}
@@ -0,0 +1,82 @@
+package receiverreport
+
+import (
+	"testing"
+	"time"
+)
+
+var testStart = time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)
+
+// feed adds packets of 48 frames at 48 kHz, arriving every millisecond,
+// leaving out the packets for which skip(i) is true
+func feed(s *source, first, n int, firstSequence uint16, skip func(int) bool) {
+	for i := first; i < first+n; i++ {
+		if skip != nil && skip(i) {
+			continue
+		}
+
+		s.add(testStart.Add(time.Duration(i)*time.Millisecond), 0x1234, firstSequence+uint16(i), uint32(i*48))
+	}
+}
+
+func TestReportLoss(t *testing.T) {
+	s := &source{clockRate: 48000}
+
+	// 4 of 100 packets lost, across a sequence number wrap-around
+	feed(s, 0, 100, 65500, func(i int) bool { return i%25 == 10 })
+
+	r := s.report(testStart.Add(time.Second))
+
+	if r.SSRC != 0x1234 || r.TotalLost != 4 || r.FractionLost != 4*256/100 {
+		t.Errorf("got SSRC %x, %d lost, fraction %d; want 1234, 4, %d", r.SSRC, r.TotalLost, r.FractionLost, 4*256/100)
+	}
+
+	if want := uint32(1<<16 + (65500+99)%(1<<16)); r.LastSequenceNumber != want {
+		t.Errorf("extended highest sequence number = %d, want %d", r.LastSequenceNumber, want)
+	}
+
+	if r.Jitter != 0 {
+		t.Errorf("jitter = %d, want 0 for regular arrivals", r.Jitter)
+	}
+
+	// No loss in the next interval
+	feed(s, 100, 100, 65500, nil)
+
+	if r := s.report(testStart.Add(2 * time.Second)); r.FractionLost != 0 || r.TotalLost != 4 {
+		t.Errorf("got fraction %d and %d lost, want 0 and 4", r.FractionLost, r.TotalLost)
+	}
+}
+
+func TestReportRestart(t *testing.T) {
+	s := &source{clockRate: 48000}
+
+	feed(s, 0, 100, 0, nil)
+
+	// A sender restarting with a new sequence number is accepted after two
+	// sequential packets
+	feed(s, 100, 10, 30000, nil)
+
+	if r := s.report(testStart.Add(time.Second)); r.TotalLost != 0 || r.LastSequenceNumber != 30109 {
+		t.Errorf("got %d lost up to %d, want 0 up to 30109", r.TotalLost, r.LastSequenceNumber)
+	}
+
+	// A new SSRC restarts the statistics
+	s.add(testStart.Add(time.Second), 0x5678, 7, 0)
+
+	if r := s.report(testStart.Add(time.Second)); r.SSRC != 0x5678 || r.LastSequenceNumber != 7 {
+		t.Errorf("got SSRC %x up to %d, want 5678 up to 7", r.SSRC, r.LastSequenceNumber)
+	}
+}
+
+func TestReportSenderReport(t *testing.T) {
+	s := &source{clockRate: 48000}
+
+	feed(s, 0, 10, 0, nil)
+	s.addSenderReport(testStart, 0x0123456789abcdef)
+
+	r := s.report(testStart.Add(500 * time.Millisecond))
+
+	if r.LastSenderReport != 0x456789ab || r.Delay != 32768 {
+		t.Errorf("got LSR %x and DLSR %d, want 456789ab and 32768", r.LastSenderReport, r.Delay)
+	}
+}
@@ -0,0 +1,107 @@
+package recorder
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// maxAlignmentSeconds limits the silence prepended to a file to start it on
+// the common sample. Sources further apart are recorded unaligned, as they
+// are most likely not locked to the same clock after all.
+const maxAlignmentSeconds = 10
+
+// Alignment makes the files of one or more recordings start on the same
+// media clock sample. The first packet received of any source sets the
+// start sample of its clock; the files of other sources of that clock are
+// padded with silence or cut to start on it.
+type Alignment struct {
+	mutex  sync.Mutex
+	starts map[string]uint32
+}
+
+// NewAlignment creates an alignment to share between recordings
+func NewAlignment() *Alignment {
+	return &Alignment{
+		starts: make(map[string]uint32),
+	}
+}
+
+// start returns the start sample of a clock, which the first caller sets
+func (a *Alignment) start(clock string, sample uint32) uint32 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if start, ok := a.starts[clock]; ok {
+		return start
+	}
+
+	a.starts[clock] = sample
+
+	return sample
+}
+
+// alignmentClock returns the clock the files of a source are aligned by,
+// and the offset of its RTP timestamps to the media clock. Only sources
+// with a reference clock and a direct media clock can be aligned.
+func alignmentClock(desc *stream.StreamDescription, source stream.StreamSource) (string, uint32, bool) {
+	offset, ok := source.MediaClockOffset()
+	if !ok || source.ReferenceClock == "" {
+		return "", 0, false
+	}
+
+	return fmt.Sprintf("%s@%d", source.ReferenceClock, desc.SampleRate), offset, true
+}
+
+// shiftFrames cuts the given number of frames off the start of the
+// interleaved samples of a packet if shift is negative, or prepends as many
+// silent frames if it is positive
+func shiftFrames(samples []stream.Sample, shift int, channels uint32) []stream.Sample {
+	if shift < 0 {
+		return samples[min(-shift*int(channels), len(samples)):]
+	}
+
+	padded := make([]stream.Sample, shift*int(channels), shift*int(channels)+len(samples))
+
+	return append(padded, samples...)
+}
+
+// align cuts or pads the samples of the first packets of a file so it starts
+// on the common sample of its clock. Packets ending before that sample are
+// dropped.
+func (r *Recorder) align(f *file, timestamp uint32, samples []stream.Sample) []stream.Sample {
+	r.alignMutex.Lock()
+	defer r.alignMutex.Unlock()
+
+	channels := r.stream.Description.ChannelCount
+
+	if f.started {
+		return samples
+	}
+
+	if f.clock == "" || channels == 0 {
+		f.started = true
+		return samples
+	}
+
+	sample := timestamp - f.mediaClockOffset
+	start := r.alignment.start(f.clock, sample)
+	shift := int(int32(start - sample))
+
+	limit := maxAlignmentSeconds * int(r.stream.Description.SampleRate)
+
+	switch {
+	case shift < -limit || shift > limit:
+		f.started = true
+		return samples
+	case shift >= len(samples)/int(channels):
+		return nil
+	}
+
+	f.started = true
+	f.aligned = true
+	f.startSample = start
+
+	return shiftFrames(samples, -shift, channels)
+}
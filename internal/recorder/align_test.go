@@ -0,0 +1,107 @@
+package recorder
+
+import (
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// packet returns the samples of a packet of 48 frames of two channels at
+// 48 kHz, the first channel with the media clock sample of the frame as value
+func packet(sample uint32) []stream.Sample {
+	samples := make([]stream.Sample, 2*48)
+
+	for i := range 48 {
+		samples[2*i] = stream.Sample(sample + uint32(i))
+	}
+
+	return samples
+}
+
+func alignedStream() *stream.Stream {
+	s := testStream()
+
+	for i := range s.Description.Sources {
+		s.Description.Sources[i].ReferenceClock = "ptp=IEEE1588-2008:00-1D-C1-FF-FE-12-34-56:0"
+		s.Description.Sources[i].MediaClock = "direct=1000"
+	}
+
+	return s
+}
+
+func TestAlignSources(t *testing.T) {
+	r := New(alignedStream(), t.TempDir())
+
+	files := make([]*file, 2)
+	for i := range files {
+		files[i] = &file{}
+		files[i].clock, files[i].mediaClockOffset, _ = alignmentClock(&r.stream.Description, r.stream.Description.Sources[i])
+	}
+
+	// The first packet of source 1 sets the start to media clock sample
+	// 96000, RTP timestamps are 1000 ahead
+	if got := r.align(files[0], 97000, packet(96000)); len(got) != 2*48 || got[0] != 96000 {
+		t.Errorf("first packet aligned to %d samples starting with %v", len(got), got[0])
+	}
+
+	// Source 2 arrives a bit later, with its packets 10 samples earlier
+	if got := r.align(files[1], 96990-48, packet(95990-48)); got != nil {
+		t.Errorf("packet before the start was not dropped: %d samples", len(got))
+	}
+
+	if got := r.align(files[1], 96990, packet(95990)); len(got) != 2*38 || got[0] != 96000 {
+		t.Errorf("got %d samples starting with %v, want 38 frames starting with 96000", len(got), got[0])
+	}
+
+	// Later packets are not touched
+	if got := r.align(files[1], 97038, packet(96038)); len(got) != 2*48 {
+		t.Errorf("got %d samples of a later packet, want 96", len(got))
+	}
+
+	if !files[1].aligned || files[1].startSample != 96000 {
+		t.Errorf("file is aligned %v to %d, want true and 96000", files[1].aligned, files[1].startSample)
+	}
+}
+
+func TestAlignShared(t *testing.T) {
+	a := NewAlignment()
+
+	first := New(alignedStream(), t.TempDir())
+	first.SetAlignment(a)
+
+	second := New(alignedStream(), t.TempDir())
+	second.SetAlignment(a)
+
+	f := &file{clock: "clock", mediaClockOffset: 1000}
+	first.align(f, 1000, packet(0))
+
+	// A recording started later is padded with silence up to its first
+	// packet
+	f = &file{clock: "clock", mediaClockOffset: 1000}
+
+	got := second.align(f, 1100, packet(100))
+	if len(got) != 2*148 || got[2*99] != 0 || got[2*100] != 100 {
+		t.Errorf("got %d samples, want 100 silent frames before the packet", len(got))
+	}
+
+	// Too far apart to be the same clock
+	f = &file{clock: "clock", mediaClockOffset: 1000}
+
+	if got := second.align(f, 1000+48000*20, packet(48000*20)); len(got) != 2*48 || f.aligned {
+		t.Errorf("got %d samples, aligned %v; want the packet unaligned", len(got), f.aligned)
+	}
+}
+
+func TestAlignmentClock(t *testing.T) {
+	s := alignedStream()
+
+	if _, offset, ok := alignmentClock(&s.Description, s.Description.Sources[0]); !ok || offset != 1000 {
+		t.Errorf("alignmentClock() = %d, %v; want 1000, true", offset, ok)
+	}
+
+	s.Description.Sources[0].MediaClock = ""
+
+	if _, _, ok := alignmentClock(&s.Description, s.Description.Sources[0]); ok {
+		t.Error("source without media clock can be aligned")
+	}
+}
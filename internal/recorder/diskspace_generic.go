@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package recorder
+
+import (
+	"errors"
+)
+
+// FreeDiskSpace is not implemented on this platform
+func FreeDiskSpace(folder string) (uint64, error) {
+	return 0, errors.New("not supported on this platform")
+}
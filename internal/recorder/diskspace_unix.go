@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package recorder
+
+import (
+	"syscall"
+)
+
+// FreeDiskSpace returns the number of bytes available to unprivileged users
+// on the file system that holds folder
+func FreeDiskSpace(folder string) (uint64, error) {
+	if folder == "" {
+		folder = "."
+	}
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(folder, &st); err != nil {
+		return 0, err
+	}
+
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
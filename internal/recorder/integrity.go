@@ -0,0 +1,163 @@
+package recorder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// maxGapSeconds limits the silence filled in for lost packets. Longer gaps
+// are taken as a restart of the sender and not filled.
+const maxGapSeconds = 1
+
+// Summary is the integrity report of a recorded file, written next to it as
+// JSON once the recording is stopped, so the file can be trusted as evidence
+type Summary struct {
+	File       string    `json:"file"`
+	Stream     string    `json:"stream"`
+	Source     int       `json:"source"`
+	Started    time.Time `json:"started"`
+	Stopped    time.Time `json:"stopped"`
+	Duration   float64   `json:"duration-seconds"`
+	SampleRate uint32    `json:"sample-rate"`
+	Channels   uint32    `json:"channels"`
+	Frames     uint64    `json:"frames"`
+	Bytes      int64     `json:"bytes"`
+
+	// Aligned is set if the file starts on the media clock sample
+	// StartSample
+	Aligned     bool   `json:"aligned"`
+	StartSample uint32 `json:"start-sample,omitempty"`
+
+	// PacketsLost are the packets that did not arrive, or arrived too late,
+	// and whose audio was filled with silence in GapsFilled gaps of
+	// FramesFilled frames in total. PacketsLate are those that arrived
+	// after their audio was filled and were dropped.
+	PacketsLost  int64  `json:"packets-lost"`
+	PacketsLate  uint64 `json:"packets-late"`
+	GapsFilled   uint64 `json:"gaps-filled"`
+	FramesFilled uint64 `json:"frames-filled"`
+
+	// PeakLevels are the highest peaks of the channels, in dBFS
+	PeakLevels []float64 `json:"peak-levels-dbfs"`
+
+	SHA256 string `json:"sha256"`
+}
+
+// SummaryFileName returns the name of the summary written next to a file
+func SummaryFileName(fileName string) string {
+	return strings.TrimSuffix(fileName, ".wav") + ".json"
+}
+
+// fill keeps the samples of a file continuous: the audio of lost packets is
+// filled with silence, and packets arriving after their audio was filled
+// are dropped. frames is the number of frames of the packet before it was
+// aligned. The first packet, and the first after a pause, start afresh.
+func (r *Recorder) fill(f *file, timestamp uint32, frames int, samples []stream.Sample) []stream.Sample {
+	r.alignMutex.Lock()
+	defer r.alignMutex.Unlock()
+
+	if !f.continuous {
+		f.continuous = true
+		f.nextTimestamp = timestamp + uint32(frames)
+
+		return samples
+	}
+
+	gap := int(int32(timestamp - f.nextTimestamp))
+	limit := maxGapSeconds * int(r.stream.Description.SampleRate)
+
+	switch {
+	case gap < 0 && gap > -limit:
+		f.packetsLate++
+		return nil
+	case gap > 0 && gap <= limit:
+		f.gapsFilled++
+		f.framesFilled += uint64(gap)
+		samples = shiftFrames(samples, gap, r.stream.Description.ChannelCount)
+	}
+
+	f.nextTimestamp = timestamp + uint32(frames)
+
+	return samples
+}
+
+// summary returns the integrity report of the finalized file with the
+// given index. The caller must hold the mutex.
+func (r *Recorder) summary(index int, f *file) (*Summary, error) {
+	checksum, err := sha256File(r.fileNames[index])
+	if err != nil {
+		return nil, err
+	}
+
+	_, maxPeaks := f.channelLevels()
+
+	r.alignMutex.Lock()
+	defer r.alignMutex.Unlock()
+
+	desc := r.stream.Description
+
+	s := &Summary{
+		File:         r.fileNames[index],
+		Stream:       r.stream.Name(),
+		Source:       index,
+		Started:      r.startTime,
+		Stopped:      r.stopTime,
+		Duration:     r.duration(f.bytes).Seconds(),
+		SampleRate:   desc.SampleRate,
+		Channels:     desc.ChannelCount,
+		Bytes:        f.size,
+		Aligned:      f.aligned,
+		StartSample:  f.startSample,
+		PacketsLost:  max(f.sequence.Stats().Lost, 0),
+		PacketsLate:  f.packetsLate,
+		GapsFilled:   f.gapsFilled,
+		FramesFilled: f.framesFilled,
+		PeakLevels:   maxPeaks,
+		SHA256:       checksum,
+	}
+
+	if desc.ChannelCount > 0 {
+		// Samples are written with 32 bits
+		s.Frames = f.bytes / (uint64(desc.ChannelCount) * 4)
+	}
+
+	return s, nil
+}
+
+// writeSummary writes a summary as JSON next to its file
+func writeSummary(s *Summary) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(SummaryFileName(s.File), append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write the summary: %w", err)
+	}
+
+	return nil
+}
+
+// sha256File returns the hex encoded SHA-256 checksum of a file
+func sha256File(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", name, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,75 @@
+package recorder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFill(t *testing.T) {
+	r := New(testStream(), t.TempDir())
+	f := &file{}
+
+	if got := r.fill(f, 1000, 48, packet(0)); len(got) != 2*48 {
+		t.Fatalf("first packet has %d samples, want %d", len(got), 2*48)
+	}
+
+	if got := r.fill(f, 1048, 48, packet(48)); len(got) != 2*48 {
+		t.Errorf("continuous packet has %d samples, want %d", len(got), 2*48)
+	}
+
+	// A lost packet is filled with silence before the next one
+	got := r.fill(f, 1144, 48, packet(144))
+	if len(got) != 2*96 || got[0] != 0 || got[2*48] != 144 {
+		t.Errorf("packet after a gap has %d samples starting %v, want 48 silent frames first", len(got), got[:2])
+	}
+
+	// The lost packet arriving late is dropped
+	if got := r.fill(f, 1096, 48, packet(96)); got != nil {
+		t.Errorf("late packet has %d samples, want none", len(got))
+	}
+
+	// A jump of more than a second is a restart, not a gap
+	if got := r.fill(f, 1192+48000*2, 48, packet(0)); len(got) != 2*48 {
+		t.Errorf("packet after a restart has %d samples, want %d", len(got), 2*48)
+	}
+
+	if f.gapsFilled != 1 || f.framesFilled != 48 || f.packetsLate != 1 {
+		t.Errorf("got %d gaps of %d frames and %d late packets, want 1, 48 and 1", f.gapsFilled, f.framesFilled, f.packetsLate)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "stream_0.wav")
+	if err := os.WriteFile(name, []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := sha256File(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "a40ff3d5900fb7698b8c865041347cb49eccedc8f93945f89629ad104aaecce4"; checksum != want {
+		t.Errorf("checksum = %s, want %s", checksum, want)
+	}
+
+	if err := writeSummary(&Summary{File: name, SHA256: checksum, PeakLevels: []float64{-3}}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(SummaryFileName(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Summary
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.File != name || s.SHA256 != checksum || len(s.PeakLevels) != 1 {
+		t.Errorf("got summary %+v", s)
+	}
+}
@@ -0,0 +1,80 @@
+package recorder
+
+import (
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// Change describes a recording that started or stopped
+type Change struct {
+	Stream  *stream.Stream
+	Started bool
+	Files   []string
+
+	// Recorded is the length of the audio written, set once stopped
+	Recorded time.Duration
+
+	// Err is the first error of the recording, if any
+	Err error
+}
+
+var (
+	observersMutex sync.Mutex
+	observers      = make(map[int]func(Change))
+	nextObserver   int
+)
+
+// Observe calls fn whenever a recording starts or stops, until the returned
+// function is called. fn must not block.
+func Observe(fn func(Change)) func() {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+
+	id := nextObserver
+	nextObserver++
+	observers[id] = fn
+
+	return func() {
+		observersMutex.Lock()
+		delete(observers, id)
+		observersMutex.Unlock()
+	}
+}
+
+// notify passes a change to all observers
+func notify(c Change) {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+
+	for _, fn := range observers {
+		fn(c)
+	}
+}
+
+// change describes the recording for the observers. The caller must hold
+// the mutex.
+func (r *Recorder) change(started bool) Change {
+	c := Change{
+		Stream:  r.stream,
+		Started: started,
+		Err:     r.err,
+	}
+
+	for i, f := range r.files {
+		if f.file != nil {
+			c.Files = append(c.Files, r.fileNames[i])
+		}
+
+		if c.Err == nil {
+			c.Err = f.err
+		}
+	}
+
+	if !started {
+		c.Recorded = r.recordedDuration()
+	}
+
+	return c
+}
@@ -0,0 +1,709 @@
+// Package recorder records the sources of a stream to WAV files.
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/memory"
+	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+var (
+	// ErrStarted is returned when starting a recording twice
+	ErrStarted = errors.New("recording already started")
+
+	// ErrFilesExist is returned when starting a recording would overwrite
+	// existing files
+	ErrFilesExist = errors.New("files already exist")
+)
+
+var fileNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// SanitizeFileName replaces all characters that are not safe to use in file
+// names on all platforms
+func SanitizeFileName(name string) string {
+	return fileNameUnsafe.ReplaceAllString(name, "_")
+}
+
+// Pause is a pause during a recording
+type Pause struct {
+	// Position is the recorded time at which the pause started
+	Position time.Duration
+	Start    time.Time
+
+	// End is the zero time while the pause lasts
+	End time.Time
+}
+
+// FileStatus describes the state of one recorded file
+type FileStatus struct {
+	Name     string
+	Bytes    uint64
+	Recorded time.Duration
+	Err      error
+
+	// Aligned is set if the file starts on the media clock sample
+	// StartSample, shared with the other sources of its clock
+	Aligned     bool
+	StartSample uint32
+
+	// Levels are the current levels of the channels of the source, and
+	// MaxPeaks the highest peak of each channel since recording started,
+	// in dBFS
+	Levels   []levels.ChannelLevel
+	MaxPeaks []float64
+
+	// Size is the size of the finalized file including its header, set
+	// once the recording is stopped. Removed is set instead if the file was
+	// removed because no audio was written to it.
+	Size    int64
+	Removed bool
+
+	// PacketsLost and GapsFilled tell how many packets were lost while
+	// recording and in how many gaps their audio was filled with silence
+	PacketsLost int64
+	GapsFilled  uint64
+
+	// Summary is the integrity report of the file, set once the recording
+	// is stopped unless the file was removed or finalizing it failed
+	Summary *Summary
+}
+
+// Status describes the state of a recording
+type Status struct {
+	Started   bool
+	Stopped   bool
+	StartTime time.Time
+	Paused    bool
+	Pauses    []Pause
+	Files     []FileStatus
+
+	// Err is set if the recording could not be started
+	Err error
+}
+
+const (
+	// queueSize is the number of packets queued per file, about a second
+	// of audio at the usual packet times
+	queueSize = 1024
+
+	// writeInterval is how often the writers take the queued packets
+	writeInterval = 10 * time.Millisecond
+
+	// levelWindow is the window of the levels measured while recording
+	levelWindow = 300 * time.Millisecond
+)
+
+// queueMemory accounts the samples queued for the writers
+var queueMemory = memory.NewAccount("recording queues")
+
+// samplePool holds the buffers packets are decoded into, which the writers
+// return once the samples are written
+var samplePool = sync.Pool{
+	New: func() any {
+		return new([]stream.Sample)
+	},
+}
+
+// packetSamples are the samples of a packet, in a buffer of samplePool
+type packetSamples struct {
+	samples []stream.Sample
+	buf     *[]stream.Sample
+}
+
+// size returns the bytes accounted for the buffer of the samples
+func (p packetSamples) size() int {
+	return cap(*p.buf) * int(unsafe.Sizeof(stream.Sample(0)))
+}
+
+// release returns the buffer to the pool and the budget
+func (p packetSamples) release() {
+	queueMemory.Release(p.size())
+	samplePool.Put(p.buf)
+}
+
+type file struct {
+	// queue passes the packets of the source from the receiver, which is
+	// called by one worker at a time, to the writer without locking
+	queue *ring.SPSC[packetSamples]
+
+	file       *os.File
+	wavEncoder *wav.Encoder
+	bytes      uint64
+	err        error
+
+	// clock is the clock the file is aligned by, empty if it is not
+	clock            string
+	mediaClockOffset uint32
+	started          bool
+	aligned          bool
+	startSample      uint32
+
+	// levelMutex guards the levels of the channels, which are measured
+	// by the receiver while recording, also while paused
+	levelMutex sync.Mutex
+	levels     []levels.Running
+	maxPeaks   []float64
+
+	// sequence counts the lost packets, and nextTimestamp is the RTP
+	// timestamp the next packet continues the file at. Both are guarded
+	// by alignMutex of the recorder.
+	sequence      stream.SequenceTracker
+	continuous    bool
+	nextTimestamp uint32
+	packetsLate   uint64
+	gapsFilled    uint64
+	framesFilled  uint64
+
+	// size, removed and summary describe the file once it is finalized
+	size    int64
+	removed bool
+	summary *Summary
+}
+
+// measure accounts the samples of a packet in the levels of the channels
+func (f *file) measure(samples []stream.Sample) {
+	f.levelMutex.Lock()
+	defer f.levelMutex.Unlock()
+
+	for i, value := range samples {
+		s := float64(int32(value)) / math.MaxInt32
+		power := s * s
+
+		channel := i % len(f.levels)
+		f.levels[channel].Add(power)
+		f.maxPeaks[channel] = max(f.maxPeaks[channel], power)
+	}
+}
+
+// channelLevels returns the current levels and the highest peaks in dBFS
+func (f *file) channelLevels() ([]levels.ChannelLevel, []float64) {
+	f.levelMutex.Lock()
+	defer f.levelMutex.Unlock()
+
+	current := make([]levels.ChannelLevel, len(f.levels))
+	maxPeaks := make([]float64, len(f.maxPeaks))
+
+	for i := range f.levels {
+		current[i] = f.levels[i].Level()
+		maxPeaks[i] = levels.ToDB(f.maxPeaks[i])
+	}
+
+	return current, maxPeaks
+}
+
+// Recorder records each source of a stream to its own WAV file. The files
+// are planned when the recorder is created, so they can be confirmed before
+// recording starts.
+type Recorder struct {
+	mutex sync.Mutex
+
+	stream *stream.Stream
+
+	// receiver is read by the callback without the mutex, as packets are
+	// delivered while it is set
+	receiver atomic.Pointer[stream.RTPReceiver]
+
+	fileNames []string
+	existing  []bool
+
+	started    bool
+	stopped    bool
+	startTime  time.Time
+	stopTime   time.Time
+	cancelFunc context.CancelFunc
+	writers    sync.WaitGroup
+	err        error
+
+	// While paused, received samples are dropped
+	paused atomic.Bool
+	pauses []Pause
+
+	files []*file
+
+	alignMutex sync.Mutex
+	alignment  *Alignment
+}
+
+// New creates a recorder for the given stream, with the file names based
+// on the stream name and the current time
+func New(s *stream.Stream, folder string) *Recorder {
+	r := &Recorder{
+		stream:    s,
+		startTime: time.Now(),
+		alignment: NewAlignment(),
+	}
+
+	streamName := SanitizeFileName(s.Name())
+
+	for i := range s.Description.Sources {
+		fileName := fmt.Sprintf("%s_%s-%d.wav", streamName, r.startTime.Format(time.RFC3339), i)
+		fileName = path.Join(folder, fileName)
+
+		_, err := os.Stat(fileName)
+
+		r.fileNames = append(r.fileNames, fileName)
+		r.existing = append(r.existing, err == nil)
+	}
+
+	return r
+}
+
+// SetAlignment shares the start sample of the files with other recordings.
+// It must be called before Start.
+func (r *Recorder) SetAlignment(a *Alignment) {
+	r.alignment = a
+}
+
+// Stream returns the recorded stream
+func (r *Recorder) Stream() *stream.Stream {
+	return r.stream
+}
+
+// FileNames returns the names of the files recorded to, one per source
+func (r *Recorder) FileNames() []string {
+	return r.fileNames
+}
+
+// Existing returns for each file whether it existed when the recorder was
+// created
+func (r *Recorder) Existing() []bool {
+	return r.existing
+}
+
+// AnyExisting returns whether any of the files already existed
+func (r *Recorder) AnyExisting() bool {
+	return slices.Contains(r.existing, true)
+}
+
+// BytesPerSecond returns the data rate of one recorded file
+func (r *Recorder) BytesPerSecond() uint64 {
+	// Samples are written with 32 bits
+	return uint64(r.stream.Description.SampleRate) * uint64(r.stream.Description.ChannelCount) * 4
+}
+
+func (r *Recorder) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet, _ time.Time) {
+	// The callback might fire before NewRTPReceiver() returns. Just ignore that packet.
+	receiver := r.receiver.Load()
+	if receiver == nil {
+		return
+	}
+
+	// Files that could not be created have no writer
+	if sourceIndex >= len(r.files) || r.files[sourceIndex].file == nil {
+		return
+	}
+
+	f := r.files[sourceIndex]
+
+	// The buffer is handed on to the writer, which returns it to the pool
+	buf := samplePool.Get().(*[]stream.Sample)
+
+	samples, err := receiver.DecodeSamples(*buf, packet)
+	*buf = samples
+
+	if err == nil && len(f.levels) > 0 {
+		f.measure(samples)
+	}
+
+	r.alignMutex.Lock()
+	f.sequence.Update(packet.SequenceNumber)
+	r.alignMutex.Unlock()
+
+	if channels := int(r.stream.Description.ChannelCount); err == nil && !r.paused.Load() && channels > 0 {
+		frames := len(samples) / channels
+
+		samples = r.align(f, packet.Timestamp, samples)
+		if len(samples) > 0 {
+			samples = r.fill(f, packet.Timestamp, frames, samples)
+		}
+	} else {
+		samples = nil
+	}
+
+	if len(samples) == 0 {
+		samplePool.Put(buf)
+		return
+	}
+
+	// Never block the receiver, e.g. when the writer stopped after an error,
+	// and drop the samples rather than exceed the memory budget
+	p := packetSamples{samples: samples, buf: buf}
+	if !queueMemory.Reserve(p.size()) {
+		samplePool.Put(buf)
+		return
+	}
+
+	if !f.queue.Push(p) {
+		p.release()
+	}
+}
+
+// Start creates the files and starts recording. Unless overwrite is set,
+// ErrFilesExist is returned if any of the files exists. Errors creating
+// individual files are reported in their status. Streams whose samples
+// can't be decoded are not recorded, see StreamDescription.SamplesError.
+func (r *Recorder) Start(overwrite bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.started || r.stopped {
+		return ErrStarted
+	}
+
+	if err := r.stream.Description.SamplesError(); err != nil {
+		return err
+	}
+
+	if !overwrite && r.AnyExisting() {
+		return ErrFilesExist
+	}
+
+	r.started = true
+	r.startTime = time.Now()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	r.cancelFunc = cancelFunc
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	for i, fileName := range r.fileNames {
+		channels := int(r.stream.Description.ChannelCount)
+
+		f := &file{
+			queue:    ring.NewSPSC[packetSamples](queueSize),
+			levels:   make([]levels.Running, channels),
+			maxPeaks: make([]float64, channels),
+		}
+
+		for c := range f.levels {
+			f.levels[c] = levels.NewRunning(levelWindow, r.stream.Description.SampleRate)
+		}
+
+		f.clock, f.mediaClockOffset, _ = alignmentClock(&r.stream.Description, r.stream.Description.Sources[i])
+
+		r.files = append(r.files, f)
+
+		outFile, err := os.OpenFile(fileName, flags, 0o644)
+		if err != nil {
+			f.err = err
+			continue
+		}
+
+		f.file = outFile
+
+		f.wavEncoder = wav.NewEncoder(outFile, int(r.stream.Description.SampleRate), 32,
+			int(r.stream.Description.ChannelCount), 1)
+
+		r.writers.Add(1)
+
+		go func() {
+			defer r.writers.Done()
+			r.write(ctx, f)
+		}()
+	}
+
+	receiver, err := r.stream.NewRTPReceiver(r.rtpReceiverCallback)
+	if err != nil {
+		r.err = err
+		return err
+	}
+
+	r.receiver.Store(receiver)
+
+	notify(r.change(true))
+
+	return nil
+}
+
+// write encodes the received frames of one file until the context is
+// cancelled or writing fails. The queue is drained once more when the
+// context is cancelled, as the receiver is closed before.
+func (r *Recorder) write(ctx context.Context, f *file) {
+	buf := &audio.IntBuffer{
+		Format: &audio.Format{
+			NumChannels: int(r.stream.Description.ChannelCount),
+			SampleRate:  int(r.stream.Description.SampleRate),
+		},
+		SourceBitDepth: 32,
+	}
+
+	ticker := time.NewTicker(writeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.writeQueued(f, buf)
+			return
+		case <-ticker.C:
+			if !r.writeQueued(f, buf) {
+				return
+			}
+		}
+	}
+}
+
+// writeQueued writes the queued packets of a file and returns false if
+// writing failed
+func (r *Recorder) writeQueued(f *file, buf *audio.IntBuffer) bool {
+	for {
+		p, ok := f.queue.Pop()
+		if !ok {
+			return true
+		}
+
+		buf.Data = buf.Data[:0]
+
+		for _, sample := range p.samples {
+			buf.Data = append(buf.Data, int(sample))
+		}
+
+		p.release()
+
+		err := f.wavEncoder.Write(buf)
+
+		r.mutex.Lock()
+
+		if err != nil {
+			if errors.Is(err, syscall.ENOSPC) {
+				f.err = fmt.Errorf("disk full, recording stopped: %w", err)
+			} else {
+				f.err = fmt.Errorf("failed to write to WAV file: %w", err)
+			}
+
+			r.mutex.Unlock()
+
+			return false
+		}
+
+		f.bytes += uint64(len(buf.Data) * 4)
+
+		r.mutex.Unlock()
+	}
+}
+
+// SetPaused pauses or resumes the recording
+func (r *Recorder) SetPaused(paused bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.started || paused == r.paused.Load() {
+		return
+	}
+
+	if !paused {
+		// Audio is not filled in for the pause
+		r.alignMutex.Lock()
+		for _, f := range r.files {
+			f.continuous = false
+		}
+		r.alignMutex.Unlock()
+
+		r.pauses[len(r.pauses)-1].End = time.Now()
+		r.paused.Store(false)
+
+		return
+	}
+
+	r.paused.Store(true)
+	r.pauses = append(r.pauses, Pause{
+		Position: r.recordedDuration(),
+		Start:    time.Now(),
+	})
+}
+
+// Paused returns whether the recording is paused
+func (r *Recorder) Paused() bool {
+	return r.paused.Load()
+}
+
+// duration returns the length of the audio in the given number of bytes
+func (r *Recorder) duration(bytes uint64) time.Duration {
+	rate := r.BytesPerSecond()
+	if rate == 0 {
+		return 0
+	}
+
+	return time.Duration(bytes * uint64(time.Second) / rate)
+}
+
+// recordedDuration returns the length of the audio written so far, taken
+// from the first file that has not failed. The caller must hold the mutex.
+func (r *Recorder) recordedDuration() time.Duration {
+	for _, f := range r.files {
+		if f.err == nil {
+			return r.duration(f.bytes)
+		}
+	}
+
+	return 0
+}
+
+// Status returns the current state of the recording
+func (r *Recorder) Status() Status {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := Status{
+		Started:   r.started,
+		Stopped:   r.stopped,
+		StartTime: r.startTime,
+		Paused:    r.paused.Load(),
+		Pauses:    slices.Clone(r.pauses),
+		Err:       r.err,
+	}
+
+	r.alignMutex.Lock()
+	defer r.alignMutex.Unlock()
+
+	for i, f := range r.files {
+		current, maxPeaks := f.channelLevels()
+
+		status.Files = append(status.Files, FileStatus{
+			Name:        r.fileNames[i],
+			Bytes:       f.bytes,
+			Recorded:    r.duration(f.bytes),
+			Err:         f.err,
+			Aligned:     f.aligned,
+			StartSample: f.startSample,
+			Levels:      current,
+			MaxPeaks:    maxPeaks,
+			Size:        f.size,
+			Removed:     f.removed,
+			PacketsLost: max(f.sequence.Stats().Lost, 0),
+			GapsFilled:  f.gapsFilled,
+			Summary:     f.summary,
+		})
+	}
+
+	return status
+}
+
+// Err returns the first error of the recording, if any
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	for _, f := range r.files {
+		if f.err != nil {
+			return f.err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the recording and closes the files. Files without any audio
+// are removed.
+func (r *Recorder) Stop() {
+	r.mutex.Lock()
+
+	if r.stopped {
+		r.mutex.Unlock()
+		return
+	}
+
+	r.stopped = true
+	r.stopTime = time.Now()
+
+	if receiver := r.receiver.Load(); receiver != nil {
+		receiver.Close()
+	}
+
+	if r.cancelFunc != nil {
+		r.cancelFunc()
+	}
+
+	r.mutex.Unlock()
+
+	// The writers take the mutex, so wait for them without holding it
+	r.writers.Wait()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.started && r.receiver.Load() != nil {
+		defer notify(r.change(false))
+	}
+
+	for i, f := range r.files {
+		// Samples are left over if the writer stopped after an error
+		for {
+			p, ok := f.queue.Pop()
+			if !ok {
+				break
+			}
+
+			p.release()
+		}
+
+		// Closing the encoder writes the sizes into the header
+		if f.wavEncoder != nil {
+			if err := f.wavEncoder.Close(); err != nil && f.err == nil {
+				f.err = fmt.Errorf("failed to finalize %s: %w", f.file.Name(), err)
+			}
+		}
+
+		if f.file != nil {
+			if info, err := f.file.Stat(); err == nil {
+				f.size = info.Size()
+			}
+
+			if err := f.file.Close(); err != nil && f.err == nil {
+				f.err = err
+			}
+
+			// Empty files are worthless, so remove them to avoid confusion
+			if f.bytes == 0 {
+				f.removed = os.Remove(f.file.Name()) == nil
+			}
+		}
+
+		if f.file != nil && !f.removed && f.err == nil {
+			summary, err := r.summary(i, f)
+			if err == nil {
+				err = writeSummary(summary)
+			}
+
+			if err != nil {
+				f.err = err
+			} else {
+				f.summary = summary
+			}
+		}
+	}
+}
+
+// Stopped returns whether the recording has been stopped
+func (r *Recorder) Stopped() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.stopped
+}
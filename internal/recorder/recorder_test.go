@@ -0,0 +1,129 @@
+package recorder
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+func testStream() *stream.Stream {
+	return &stream.Stream{
+		ID: "test",
+		Description: stream.StreamDescription{
+			Name:         "Stage/left 1",
+			SampleRate:   48000,
+			ChannelCount: 2,
+			ContentType:  stream.ContentTypePCM24,
+			Sources:      make([]stream.StreamSource, 2),
+		},
+	}
+}
+
+func TestNewPlansOneFilePerSource(t *testing.T) {
+	dir := t.TempDir()
+	r := New(testStream(), dir)
+
+	files := r.FileNames()
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+
+	for i, f := range files {
+		if filepath.Dir(f) != dir {
+			t.Errorf("file %d is in %s, want %s", i, filepath.Dir(f), dir)
+		}
+
+		if !strings.HasPrefix(filepath.Base(f), "Stage_left_1_") || !strings.HasSuffix(f, ".wav") {
+			t.Errorf("unexpected file name %s", f)
+		}
+	}
+
+	if r.AnyExisting() {
+		t.Error("AnyExisting() = true in an empty folder")
+	}
+
+	// 48 kHz, 2 channels, 32 bit
+	if got := r.BytesPerSecond(); got != 384000 {
+		t.Errorf("BytesPerSecond() = %d, want 384000", got)
+	}
+}
+
+func TestStartRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+
+	// Plan once to learn the file names, then create one of them
+	files := New(testStream(), dir).FileNames()
+	if err := os.WriteFile(files[1], []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(testStream(), dir)
+	if r.FileNames()[1] != files[1] {
+		t.Skip("file names changed between calls, the clock ticked over")
+	}
+
+	if !r.AnyExisting() {
+		t.Fatal("AnyExisting() = false with an existing file")
+	}
+
+	if err := r.Start(false); !errors.Is(err, ErrFilesExist) {
+		t.Errorf("Start(false) = %v, want ErrFilesExist", err)
+	}
+
+	if b, _ := os.ReadFile(files[1]); string(b) != "keep" {
+		t.Errorf("existing file was modified: %q", b)
+	}
+}
+
+func TestStartRefusesCompressed(t *testing.T) {
+	s := testStream()
+	s.Description.ContentType = stream.ContentTypeOpus
+
+	r := New(s, t.TempDir())
+	defer r.Stop()
+
+	if err := r.Start(false); !errors.Is(err, stream.ErrUnsupportedContentType) {
+		t.Errorf("Start(false) = %v, want ErrUnsupportedContentType", err)
+	}
+
+	if r.AnyExisting() {
+		t.Error("files created for a compressed stream")
+	}
+}
+
+func TestFileLevels(t *testing.T) {
+	f := &file{
+		levels:   []levels.Running{levels.NewRunning(levelWindow, 48000), levels.NewRunning(levelWindow, 48000)},
+		maxPeaks: make([]float64, 2),
+	}
+
+	// A full scale first channel and a silent second one
+	samples := make([]stream.Sample, 2*48)
+	for i := 0; i < len(samples); i += 2 {
+		samples[i] = math.MaxInt32
+	}
+
+	f.measure(samples)
+
+	// The peak is kept when the signal drops
+	f.measure(make([]stream.Sample, 2*48))
+
+	current, maxPeaks := f.channelLevels()
+	if len(current) != 2 || len(maxPeaks) != 2 {
+		t.Fatalf("got %d levels and %d peaks, want 2", len(current), len(maxPeaks))
+	}
+
+	if maxPeaks[0] < -0.01 || maxPeaks[1] != levels.SilenceDB {
+		t.Errorf("max peaks = %v, want 0 and %v dBFS", maxPeaks, levels.SilenceDB)
+	}
+
+	if current[0].Peak >= maxPeaks[0] || current[0].Peak < -1 {
+		t.Errorf("current peak = %v, want decaying below %v", current[0].Peak, maxPeaks[0])
+	}
+}
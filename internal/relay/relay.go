@@ -0,0 +1,163 @@
+// Package relay re-transmits a received stream to another multicast group,
+// e.g. for crossing between networks or feeding lab receivers a copy of a
+// production stream.
+package relay
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/transmit"
+	"github.com/pion/rtp/v2"
+)
+
+// Options configures a relay
+type Options struct {
+	Stream *stream.Stream
+
+	// Source is the index of the stream source to relay
+	Source int
+
+	Destination *net.UDPAddr
+	Interface   *net.Interface // may be nil for the default route
+	TTL         int
+	DSCP        int
+
+	// Name is the session name of the relayed stream
+	Name string
+
+	// SAP enables announcing the relayed stream via SAP
+	SAP bool
+}
+
+// Relay re-transmits a stream until it is closed
+type Relay struct {
+	opts     Options
+	conn     *net.UDPConn
+	receiver *stream.RTPReceiver
+	origin   net.IP
+	sdp      []byte
+
+	// mutex guards buf, as packets may be received on several interfaces
+	mutex sync.Mutex
+	buf   []byte
+
+	packets   atomic.Uint64
+	errors    atomic.Uint64
+	errorOnce sync.Once
+	errorFn   func(error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start starts relaying the stream and, if enabled, announcing the relayed
+// stream. The first error sending a packet and errors announcing the stream
+// are reported to errorFn.
+func Start(opts Options, errorFn func(error)) (*Relay, error) {
+	sources := opts.Stream.Description.Sources
+
+	if opts.Source < 0 || opts.Source >= len(sources) {
+		return nil, fmt.Errorf("stream has no source %d", opts.Source+1)
+	}
+
+	if src := sources[opts.Source]; src.DestinationAddress.Equal(opts.Destination.IP) && int(src.DestinationPort) == opts.Destination.Port {
+		return nil, fmt.Errorf("relaying to %s would loop", opts.Destination)
+	}
+
+	r := &Relay{
+		opts:    opts,
+		buf:     make([]byte, 1500),
+		errorFn: errorFn,
+	}
+
+	var err error
+
+	if r.conn, err = transmit.Dial(opts.Destination, opts.Interface, opts.TTL, opts.DSCP); err != nil {
+		return nil, err
+	}
+
+	r.origin = r.conn.LocalAddr().(*net.UDPAddr).IP
+
+	if r.sdp, err = rewriteSDP(opts.Stream.SDP, opts.Source, opts.Name, rand.Uint64N(1<<32), r.origin, opts.Destination, opts.TTL); err != nil {
+		r.conn.Close()
+		return nil, fmt.Errorf("failed to rewrite SDP: %w", err)
+	}
+
+	r.receiver, err = opts.Stream.NewRTPReceiver(func(sourceIndex int, _ net.Addr, packet *rtp.Packet, _ time.Time) {
+		if sourceIndex != opts.Source {
+			return
+		}
+
+		r.forward(packet)
+	})
+	if err != nil {
+		r.conn.Close()
+		return nil, err
+	}
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+
+	if opts.SAP {
+		r.wg.Add(1)
+		go r.announce()
+	}
+
+	return r, nil
+}
+
+// forward sends a received packet to the destination
+func (r *Relay) forward(packet *rtp.Packet) {
+	r.mutex.Lock()
+	n, err := packet.MarshalTo(r.buf)
+	if err == nil {
+		_, err = r.conn.Write(r.buf[:n])
+	}
+	r.mutex.Unlock()
+
+	if err != nil {
+		r.errors.Add(1)
+		r.errorOnce.Do(func() {
+			r.errorFn(fmt.Errorf("failed to relay packet: %w", err))
+		})
+
+		return
+	}
+
+	r.packets.Add(1)
+}
+
+// announce sends SAP announcements until the relay is closed
+func (r *Relay) announce() {
+	defer r.wg.Done()
+
+	if err := transmit.Announce(r.ctx, r.origin, r.sdp); err != nil {
+		r.errorFn(err)
+	}
+}
+
+// SDP returns the session description of the relayed stream
+func (r *Relay) SDP() []byte {
+	return r.sdp
+}
+
+// Packets returns the number of packets relayed and failed to relay
+func (r *Relay) Packets() (relayed, failed uint64) {
+	return r.packets.Load(), r.errors.Load()
+}
+
+// Close stops relaying the stream and sends a SAP deletion if the relayed
+// stream was announced
+func (r *Relay) Close() {
+	r.receiver.Close()
+	r.cancel()
+	r.wg.Wait()
+	r.conn.Close()
+}
@@ -0,0 +1,80 @@
+package relay
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// rewriteSDP returns the SDP of the relayed stream: the session description
+// with the media description of the relayed source only, sent from origin to
+// the destination. The session ID is replaced, so the relayed stream has a
+// different ID even if it is sent from the same host. RTCP attributes are
+// dropped, as RTCP is not relayed.
+func rewriteSDP(sdp []byte, source int, name string, sessionID uint64, origin net.IP, destination *net.UDPAddr, ttl int) ([]byte, error) {
+	connection := fmt.Sprintf("c=IN IP4 %s/%d", destination.IP, ttl)
+	sourceFilter := fmt.Sprintf("a=source-filter: incl IN IP4 %s %s", destination.IP, origin)
+
+	var lines []string
+
+	// audio is the index of the current audio media description, -1 for the
+	// session description
+	audio := -1
+	keep := true
+	found := false
+
+	for line := range strings.Lines(string(sdp)) {
+		line = strings.TrimRight(line, "\r\n")
+
+		if media, ok := strings.CutPrefix(line, "m="); ok {
+			keep = false
+
+			fields := strings.Fields(media)
+			if len(fields) < 3 || fields[0] != "audio" {
+				continue
+			}
+
+			audio++
+
+			if keep = audio == source; keep {
+				found = true
+				fields[1] = fmt.Sprint(destination.Port)
+				line = "m=" + strings.Join(fields, " ")
+			}
+		}
+
+		if !keep {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "o="):
+			fields := strings.Fields(line)
+			if len(fields) != 6 {
+				return nil, fmt.Errorf("invalid origin %q", line)
+			}
+
+			fields[1] = fmt.Sprint(sessionID)
+			fields[2] = fields[1]
+			fields[5] = origin.String()
+			line = strings.Join(fields, " ")
+		case strings.HasPrefix(line, "s="):
+			line = "s=" + name
+		case strings.HasPrefix(line, "c="):
+			line = connection
+		case strings.HasPrefix(line, "a=source-filter:"):
+			line = sourceFilter
+		case strings.HasPrefix(line, "a=rtcp:"), line == "a=rtcp-mux":
+			// RTCP is not relayed
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("SDP has no source %d", source+1)
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n"), nil
+}
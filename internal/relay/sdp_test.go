@@ -0,0 +1,66 @@
+package relay
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const redundantSDP = "v=0\r\n" +
+	"o=- 1311738121 1311738121 IN IP4 192.168.1.10\r\n" +
+	"s=Stage Left\r\n" +
+	"t=0 0\r\n" +
+	"a=clock-domain:PTPv2 0\r\n" +
+	"m=audio 5004 RTP/AVP 98\r\n" +
+	"c=IN IP4 239.1.1.1/32\r\n" +
+	"a=source-filter: incl IN IP4 239.1.1.1 192.168.1.10\r\n" +
+	"a=rtpmap:98 L24/48000/2\r\n" +
+	"a=framecount:48\r\n" +
+	"m=audio 5004 RTP/AVP 98\r\n" +
+	"c=IN IP4 239.2.1.1/32\r\n" +
+	"a=source-filter: incl IN IP4 239.2.1.1 192.168.2.10\r\n" +
+	"a=rtpmap:98 L24/48000/2\r\n" +
+	"a=rtcp:5008 IN IP4 239.2.1.2\r\n" +
+	"a=framecount:48\r\n"
+
+func TestRewriteSDP(t *testing.T) {
+	destination := &net.UDPAddr{IP: net.IPv4(239, 69, 2, 1), Port: 5006}
+
+	// Relayed from the host that sends the stream
+
+	sdp, err := rewriteSDP([]byte(redundantSDP), 1, "Stage Left (relay)", 42, net.IPv4(192, 168, 1, 10), destination, 16)
+	if err != nil {
+		t.Fatalf("rewriteSDP() failed: %v", err)
+	}
+
+	desc, id, err := stream.ParseSDP(sdp)
+	if err != nil {
+		t.Fatalf("ParseSDP() failed: %v\n%s", err, sdp)
+	}
+
+	if _, originalID, _ := stream.ParseSDP([]byte(redundantSDP)); id == originalID {
+		t.Errorf("relayed stream has the ID %s of the original", id)
+	}
+
+	if desc.Name != "Stage Left (relay)" || len(desc.Sources) != 1 {
+		t.Fatalf("got %q with %d sources, want one\n%s", desc.Name, len(desc.Sources), sdp)
+	}
+
+	src := desc.Sources[0]
+	if !src.DestinationAddress.Equal(destination.IP) || src.DestinationPort != 5006 || src.TTL != 16 ||
+		!src.SenderAddress.Equal(net.IPv4(192, 168, 1, 10)) || src.FramesPerPacket != 48 {
+		t.Errorf("unexpected source %+v\n%s", src, sdp)
+	}
+
+	for _, unwanted := range []string{"239.1.1.1", "239.2.1.1", "192.168.2.10", "a=rtcp"} {
+		if strings.Contains(string(sdp), unwanted) {
+			t.Errorf("relayed SDP contains %s:\n%s", unwanted, sdp)
+		}
+	}
+
+	if _, err := rewriteSDP([]byte(redundantSDP), 2, "", 42, net.IPv4(192, 168, 1, 10), destination, 16); err == nil {
+		t.Error("rewriteSDP() succeeded for a missing source")
+	}
+}
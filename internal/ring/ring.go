@@ -2,9 +2,32 @@ package ring
 
 import (
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
+// Totals of all ring buffers that have not been garbage collected, for
+// diagnostics
+var (
+	totalBuffers  atomic.Int64
+	totalElements atomic.Int64
+	totalCapacity atomic.Int64
+)
+
+// Stats returns the number of ring buffers in use, the number of elements
+// they hold and their total capacity
+func Stats() (buffers, elements, capacity int64) {
+	return totalBuffers.Load(), totalElements.Load(), totalCapacity.Load()
+}
+
+// usage is what a ring buffer adds to the totals. It is kept apart from the
+// buffer to be subtracted once the buffer is garbage collected.
+type usage struct {
+	elements atomic.Int64
+	capacity int64
+}
+
 // RingBuffer is a thread-safe generic ring buffer implementation
 type RingBuffer[T any] struct {
 	buffer  []T
@@ -14,6 +37,7 @@ type RingBuffer[T any] struct {
 	maxSize int
 	isFull  bool
 	mu      sync.RWMutex
+	usage   *usage
 }
 
 // NewRingBuffer creates a new ring buffer with the specified maximum size
@@ -21,10 +45,28 @@ func NewRingBuffer[T any](maxSize int) *RingBuffer[T] {
 	if maxSize <= 0 {
 		panic("maxSize must be greater than 0")
 	}
-	return &RingBuffer[T]{
+	rb := &RingBuffer[T]{
 		buffer:  make([]T, maxSize),
 		maxSize: maxSize,
+		usage:   &usage{capacity: int64(maxSize)},
 	}
+
+	totalBuffers.Add(1)
+	totalCapacity.Add(int64(maxSize))
+
+	runtime.AddCleanup(rb, func(u *usage) {
+		totalBuffers.Add(-1)
+		totalElements.Add(-u.elements.Load())
+		totalCapacity.Add(-u.capacity)
+	}, rb.usage)
+
+	return rb
+}
+
+// grow adds n elements to the usage of the buffer
+func (rb *RingBuffer[T]) grow(n int) {
+	rb.usage.elements.Add(int64(n))
+	totalElements.Add(int64(n))
 }
 
 // Push adds an element to the ring buffer
@@ -40,12 +82,38 @@ func (rb *RingBuffer[T]) Push(item T) {
 		rb.head = (rb.head + 1) % rb.maxSize
 	} else {
 		rb.size++
+		rb.grow(1)
 		if rb.size == rb.maxSize {
 			rb.isFull = true
 		}
 	}
 }
 
+// PushN adds all items to the ring buffer under one lock. If they do not
+// fit, the oldest elements are overwritten, as with Push.
+func (rb *RingBuffer[T]) PushN(items []T) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	// Only the last maxSize items survive
+	if len(items) > rb.maxSize {
+		items = items[len(items)-rb.maxSize:]
+	}
+
+	n := copy(rb.buffer[rb.tail:], items)
+	copy(rb.buffer, items[n:])
+	rb.tail = (rb.tail + len(items)) % rb.maxSize
+
+	added := min(len(items), rb.maxSize-rb.size)
+	rb.size += added
+	rb.grow(added)
+
+	rb.isFull = rb.size == rb.maxSize
+	if rb.isFull {
+		rb.head = rb.tail
+	}
+}
+
 // Pop removes and returns the oldest element from the buffer
 // Returns the element and true if successful, zero value and false if empty
 func (rb *RingBuffer[T]) Pop() (T, bool) {
@@ -61,11 +129,58 @@ func (rb *RingBuffer[T]) Pop() (T, bool) {
 	rb.buffer[rb.head] = zero // Clear the slot to avoid memory leaks
 	rb.head = (rb.head + 1) % rb.maxSize
 	rb.size--
+	rb.grow(-1)
 	rb.isFull = false
 
 	return item, true
 }
 
+// PopN removes and returns up to n of the oldest elements, oldest first.
+// It returns nil if the buffer is empty.
+func (rb *RingBuffer[T]) PopN(n int) []T {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	n = min(n, rb.size)
+	if n <= 0 {
+		return nil
+	}
+
+	result := make([]T, n)
+	rb.copyOut(result)
+
+	var zero T
+	for i := range n {
+		rb.buffer[(rb.head+i)%rb.maxSize] = zero // Clear the slots to avoid memory leaks
+	}
+
+	rb.head = (rb.head + n) % rb.maxSize
+	rb.size -= n
+	rb.grow(-n)
+	rb.isFull = false
+
+	return result
+}
+
+// CopyTo copies the oldest elements, up to the length of dst, into dst
+// without removing them and returns the number of elements copied
+func (rb *RingBuffer[T]) CopyTo(dst []T) int {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	return rb.copyOut(dst)
+}
+
+// copyOut copies the oldest elements into dst. The caller must hold the lock.
+func (rb *RingBuffer[T]) copyOut(dst []T) int {
+	n := min(len(dst), rb.size)
+
+	first := copy(dst[:n], rb.buffer[rb.head:])
+	copy(dst[first:n], rb.buffer)
+
+	return n
+}
+
 // Peek returns the oldest element without removing it
 // Returns the element and true if successful, zero value and false if empty
 func (rb *RingBuffer[T]) Peek() (T, bool) {
@@ -115,6 +230,7 @@ func (rb *RingBuffer[T]) Clear() {
 	for i := range rb.buffer {
 		rb.buffer[i] = zero
 	}
+	rb.grow(-rb.size)
 	rb.head = 0
 	rb.tail = 0
 	rb.size = 0
@@ -177,10 +293,7 @@ func (rb *RingBuffer[T]) ToSlice() []T {
 	}
 
 	result := make([]T, rb.size)
-	for i := 0; i < rb.size; i++ {
-		idx := (rb.head + i) % rb.maxSize
-		result[i] = rb.buffer[idx]
-	}
+	rb.copyOut(result)
 	return result
 }
 
@@ -1,6 +1,7 @@
 package ring
 
 import (
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -463,6 +464,91 @@ func findSubstring(s, substr string) bool {
 	return false
 }
 
+func TestPushN(t *testing.T) {
+	rb := NewRingBuffer[int](5)
+
+	rb.Push(0)
+	rb.PushN([]int{1, 2, 3})
+
+	if got := rb.ToSlice(); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("Expected [0 1 2 3], got %v", got)
+	}
+
+	// Wraps around and overwrites the oldest elements
+	rb.PushN([]int{4, 5, 6})
+
+	if got := rb.ToSlice(); !slices.Equal(got, []int{2, 3, 4, 5, 6}) {
+		t.Errorf("Expected [2 3 4 5 6], got %v", got)
+	}
+	if !rb.IsFull() {
+		t.Error("Expected buffer to be full")
+	}
+
+	// Only the last elements of a batch larger than the buffer are kept
+	rb.PushN([]int{7, 8, 9, 10, 11, 12, 13})
+
+	if got := rb.ToSlice(); !slices.Equal(got, []int{9, 10, 11, 12, 13}) {
+		t.Errorf("Expected [9 10 11 12 13], got %v", got)
+	}
+
+	rb.Push(14)
+
+	if got := rb.ToSlice(); !slices.Equal(got, []int{10, 11, 12, 13, 14}) {
+		t.Errorf("Expected [10 11 12 13 14] after Push, got %v", got)
+	}
+
+	if n := rb.usage.elements.Load(); n != 5 {
+		t.Errorf("Expected usage of 5 elements, got %d", n)
+	}
+}
+
+func TestPopN(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+
+	if got := rb.PopN(2); got != nil {
+		t.Errorf("Expected nil from empty buffer, got %v", got)
+	}
+
+	rb.PushN([]int{1, 2, 3, 4, 5, 6})
+
+	if got := rb.PopN(3); !slices.Equal(got, []int{3, 4, 5}) {
+		t.Errorf("Expected [3 4 5], got %v", got)
+	}
+
+	rb.PushN([]int{7, 8})
+
+	if got := rb.PopN(10); !slices.Equal(got, []int{6, 7, 8}) {
+		t.Errorf("Expected [6 7 8], got %v", got)
+	}
+
+	if !rb.IsEmpty() || rb.IsFull() {
+		t.Errorf("Expected empty buffer, got size %d", rb.Size())
+	}
+
+	if n := rb.usage.elements.Load(); n != 0 {
+		t.Errorf("Expected usage of 0 elements, got %d", n)
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+	rb.PushN([]int{1, 2, 3, 4, 5})
+
+	dst := make([]int, 3)
+	if n := rb.CopyTo(dst); n != 3 || !slices.Equal(dst, []int{2, 3, 4}) {
+		t.Errorf("Expected 3 elements [2 3 4], got %d %v", n, dst)
+	}
+
+	dst = make([]int, 10)
+	if n := rb.CopyTo(dst); n != 4 || !slices.Equal(dst[:n], []int{2, 3, 4, 5}) {
+		t.Errorf("Expected 4 elements [2 3 4 5], got %d %v", n, dst[:n])
+	}
+
+	if rb.Size() != 4 {
+		t.Errorf("Expected CopyTo to keep the elements, got size %d", rb.Size())
+	}
+}
+
 // Benchmark tests
 func BenchmarkPush(b *testing.B) {
 	rb := NewRingBuffer[int](1000)
@@ -485,6 +571,15 @@ func BenchmarkPop(b *testing.B) {
 	}
 }
 
+func BenchmarkPushN(b *testing.B) {
+	rb := NewRingBuffer[int](1000)
+	batch := make([]int, 48)
+
+	for b.Loop() {
+		rb.PushN(batch)
+	}
+}
+
 func BenchmarkIterator(b *testing.B) {
 	rb := NewRingBuffer[int](1000)
 	// Fill buffer
@@ -499,3 +594,27 @@ func BenchmarkIterator(b *testing.B) {
 		}
 	}
 }
+
+func TestStats(t *testing.T) {
+	rb := NewRingBuffer[int](1000)
+
+	for i := range 3 {
+		rb.Push(i)
+	}
+	rb.Pop()
+
+	if n := rb.usage.elements.Load(); n != 2 {
+		t.Errorf("Expected usage of 2 elements, got %d", n)
+	}
+
+	if buffers, elements, capacity := Stats(); buffers < 1 || elements < 2 || capacity < 1000 {
+		t.Errorf("Expected totals to include the buffer, got %d buffers, %d elements, capacity %d",
+			buffers, elements, capacity)
+	}
+
+	rb.Clear()
+
+	if n := rb.usage.elements.Load(); n != 0 {
+		t.Errorf("Expected usage of 0 elements after Clear, got %d", n)
+	}
+}
@@ -0,0 +1,106 @@
+package ring
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// cacheLinePad separates the indices of an SPSC buffer, so the producer and
+// the consumer do not invalidate each other's cache line on every update
+type cacheLinePad [64]byte
+
+// SPSC is a lock-free ring buffer for exactly one producer and one consumer
+// goroutine. Unlike RingBuffer, it never overwrites: only the consumer may
+// advance the head, so Push fails when the buffer is full.
+//
+// The elements held are not included in Stats, as counting them would share
+// a counter between all producers.
+type SPSC[T any] struct {
+	buffer []T
+	mask   uint64
+
+	_    cacheLinePad
+	head atomic.Uint64 // next element to pop, written by the consumer
+	_    cacheLinePad
+	tail atomic.Uint64 // next slot to push to, written by the producer
+	_    cacheLinePad
+}
+
+// NewSPSC creates a new SPSC ring buffer holding at least the given number
+// of elements. The capacity is rounded up to a power of two.
+func NewSPSC[T any](minSize int) *SPSC[T] {
+	if minSize <= 0 {
+		panic("minSize must be greater than 0")
+	}
+
+	size := 1
+	for size < minSize {
+		size <<= 1
+	}
+
+	rb := &SPSC[T]{
+		buffer: make([]T, size),
+		mask:   uint64(size - 1),
+	}
+
+	totalBuffers.Add(1)
+	totalCapacity.Add(int64(size))
+
+	runtime.AddCleanup(rb, func(size int64) {
+		totalBuffers.Add(-1)
+		totalCapacity.Add(-size)
+	}, int64(size))
+
+	return rb
+}
+
+// Push adds an element to the buffer and returns false if it is full. It
+// must only be called by the producer.
+func (rb *SPSC[T]) Push(item T) bool {
+	tail := rb.tail.Load()
+
+	if tail-rb.head.Load() == uint64(len(rb.buffer)) {
+		return false
+	}
+
+	rb.buffer[tail&rb.mask] = item
+
+	// Publishes the element to the consumer
+	rb.tail.Store(tail + 1)
+
+	return true
+}
+
+// Pop removes and returns the oldest element, or false if the buffer is
+// empty. It must only be called by the consumer.
+func (rb *SPSC[T]) Pop() (T, bool) {
+	var zero T
+
+	head := rb.head.Load()
+
+	if head == rb.tail.Load() {
+		return zero, false
+	}
+
+	item := rb.buffer[head&rb.mask]
+	rb.buffer[head&rb.mask] = zero // Clear the slot to avoid memory leaks
+
+	// Hands the slot back to the producer
+	rb.head.Store(head + 1)
+
+	return item, true
+}
+
+// Size returns the number of elements in the buffer. It is exact only when
+// called by the producer or the consumer while the other one is idle.
+func (rb *SPSC[T]) Size() int {
+	head := rb.head.Load()
+	tail := rb.tail.Load()
+
+	return int(tail - head)
+}
+
+// MaxSize returns the capacity of the buffer
+func (rb *SPSC[T]) MaxSize() int {
+	return len(rb.buffer)
+}
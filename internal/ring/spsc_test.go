@@ -0,0 +1,89 @@
+package ring
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSPSCPushAndPop(t *testing.T) {
+	rb := NewSPSC[int](3)
+
+	// The capacity is rounded up to a power of two
+	if rb.MaxSize() != 4 {
+		t.Errorf("Expected max size 4, got %d", rb.MaxSize())
+	}
+
+	for i := range 4 {
+		if !rb.Push(i) {
+			t.Fatalf("Expected push of %d to succeed", i)
+		}
+	}
+
+	if rb.Push(4) {
+		t.Error("Expected push to a full buffer to fail")
+	}
+
+	if rb.Size() != 4 {
+		t.Errorf("Expected size 4, got %d", rb.Size())
+	}
+
+	for i := range 4 {
+		val, ok := rb.Pop()
+		if !ok || val != i {
+			t.Errorf("Expected to pop %d, got %d, ok=%v", i, val, ok)
+		}
+	}
+
+	if val, ok := rb.Pop(); ok || val != 0 {
+		t.Errorf("Expected pop from empty buffer to return 0, false, got %d, %v", val, ok)
+	}
+}
+
+func TestSPSCConcurrency(t *testing.T) {
+	rb := NewSPSC[int](64)
+	const numOps = 10000
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < numOps; {
+			if rb.Push(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	// Elements arrive in order, none is lost or duplicated
+	for want := 0; want < numOps; {
+		val, ok := rb.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+
+		if val != want {
+			t.Fatalf("Expected to pop %d, got %d", want, val)
+		}
+		want++
+	}
+
+	wg.Wait()
+
+	if rb.Size() != 0 {
+		t.Errorf("Expected empty buffer, got size %d", rb.Size())
+	}
+}
+
+func BenchmarkSPSCPushPop(b *testing.B) {
+	rb := NewSPSC[int](1024)
+
+	for i := 0; b.Loop(); i++ {
+		rb.Push(i)
+		rb.Pop()
+	}
+}
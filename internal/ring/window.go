@@ -0,0 +1,103 @@
+package ring
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is an element of a Window together with the time it was added
+type Entry[T any] struct {
+	Time  time.Time
+	Value T
+}
+
+// Window is a thread-safe ring buffer that keeps the elements of a time
+// span rather than a number of elements. Elements older than the window are
+// evicted as new ones are pushed or the elements are read. Elements must be
+// pushed in the order of their time.
+type Window[T any] struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries *RingBuffer[Entry[T]]
+}
+
+// NewWindow creates a new window of the given duration. maxSize bounds the
+// number of elements kept if they are pushed faster than expected; the
+// oldest ones are overwritten then, as with RingBuffer.
+func NewWindow[T any](window time.Duration, maxSize int) *Window[T] {
+	if window <= 0 {
+		panic("window must be greater than 0")
+	}
+
+	return &Window[T]{
+		window:  window,
+		entries: NewRingBuffer[Entry[T]](maxSize),
+	}
+}
+
+// evict removes the elements older than the window ending at now. The
+// caller must hold the mutex.
+func (w *Window[T]) evict(now time.Time) {
+	cutoff := now.Add(-w.window)
+
+	for {
+		entry, ok := w.entries.Peek()
+		if !ok || entry.Time.After(cutoff) {
+			return
+		}
+
+		w.entries.Pop()
+	}
+}
+
+// Push adds an element with the time t and evicts the elements that are
+// older than the window ending at t
+func (w *Window[T]) Push(t time.Time, item T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.evict(t)
+	w.entries.Push(Entry[T]{Time: t, Value: item})
+}
+
+// Evict removes the elements older than the window ending at now
+func (w *Window[T]) Evict(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.evict(now)
+}
+
+// Entries returns the elements in the window ending at now, oldest first
+func (w *Window[T]) Entries(now time.Time) []Entry[T] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.evict(now)
+
+	return w.entries.ToSlice()
+}
+
+// Values returns the values of the elements in the window ending at now,
+// oldest first
+func (w *Window[T]) Values(now time.Time) []T {
+	entries := w.Entries(now)
+
+	values := make([]T, len(entries))
+	for i, entry := range entries {
+		values[i] = entry.Value
+	}
+
+	return values
+}
+
+// Size returns the number of elements held, including those that expired
+// since the last push or read
+func (w *Window[T]) Size() int {
+	return w.entries.Size()
+}
+
+// Duration returns the duration of the window
+func (w *Window[T]) Duration() time.Duration {
+	return w.window
+}
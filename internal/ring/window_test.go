@@ -0,0 +1,50 @@
+package ring
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWindowEviction(t *testing.T) {
+	w := NewWindow[int](3*time.Second, 100)
+	start := time.Unix(1000, 0)
+
+	for i := range 5 {
+		w.Push(start.Add(time.Duration(i)*time.Second), i)
+	}
+
+	// Pushing at 4s evicts everything at or before 1s
+	if got := w.Values(start.Add(4 * time.Second)); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("Expected [2 3 4], got %v", got)
+	}
+
+	// Reading later evicts as well, without a push
+	entries := w.Entries(start.Add(6500 * time.Millisecond))
+	if len(entries) != 1 || entries[0].Value != 4 || !entries[0].Time.Equal(start.Add(4*time.Second)) {
+		t.Errorf("Expected only the entry of 4s, got %v", entries)
+	}
+
+	w.Evict(start.Add(time.Minute))
+
+	if w.Size() != 0 {
+		t.Errorf("Expected empty window, got size %d", w.Size())
+	}
+
+	if w.Duration() != 3*time.Second {
+		t.Errorf("Expected duration 3s, got %v", w.Duration())
+	}
+}
+
+func TestWindowMaxSize(t *testing.T) {
+	w := NewWindow[int](time.Minute, 3)
+	start := time.Unix(1000, 0)
+
+	for i := range 5 {
+		w.Push(start.Add(time.Duration(i)*time.Millisecond), i)
+	}
+
+	if got := w.Values(start); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("Expected the last 3 elements [2 3 4], got %v", got)
+	}
+}
@@ -0,0 +1,367 @@
+// Package senderreport analyzes the RTCP sender reports of a stream: the
+// mapping between the NTP wallclock and the RTP timestamps of a sender, the
+// drift of its clocks against the local and the PTP clock, and the round
+// trips of the receiver reports that refer to its reports.
+package senderreport
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+const (
+	// maxRecent is the number of sender reports per sender remembered to
+	// match the receiver reports referring to them
+	maxRecent = 16
+
+	// maxReporters is the number of receivers per sender round trips are
+	// kept for. The one heard from longest ago is dropped for a new one.
+	maxReporters = 32
+
+	// minSpan is the time sender reports must span for rates and drifts to
+	// be computed, as the arrival times are skewed by the network jitter
+	minSpan = time.Second
+)
+
+// ntpEpoch is the start of the NTP timescale
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NTPTime converts a 64 bit NTP timestamp to a time
+func NTPTime(ntp uint64) time.Time {
+	seconds := time.Duration(ntp>>32) * time.Second
+	fraction := time.Duration((ntp & 0xffffffff) * uint64(time.Second) >> 32)
+
+	return ntpEpoch.Add(seconds + fraction)
+}
+
+// Report is a received sender report
+type Report struct {
+	// Arrival is the local time the report was received at
+	Arrival time.Time
+
+	// PTP is the PTP time at the arrival, zero if unknown
+	PTP time.Time
+
+	NTP     time.Time
+	RTP     uint32
+	Packets uint32
+	Octets  uint32
+
+	// LSR is the middle 32 bits of the NTP timestamp, by which receiver
+	// reports refer to the report
+	LSR uint32
+}
+
+// RoundTrip is the round trip time of a sender report, measured with a
+// receiver report referring to it. The time is that from the sender to the
+// receiver and from the receiver to the monitor, minus that from the sender
+// to the monitor, so it is the round trip between sender and receiver if the
+// monitor is close to the sender.
+type RoundTrip struct {
+	Reporter uint32
+	Time     time.Duration
+	Arrival  time.Time
+}
+
+// Sender is the analysis of the sender reports of one SSRC
+type Sender struct {
+	SSRC    uint32
+	Reports uint64
+
+	// First and Last are the first report since the start or the last
+	// restart of the sender, and the most recent one
+	First Report
+	Last  Report
+
+	// RTPElapsed is the RTP clock units between the first and the last
+	// report, with the wrap-arounds of the timestamp counted
+	RTPElapsed int64
+
+	// PacketsElapsed and OctetsElapsed are sent between the first and the
+	// last report
+	PacketsElapsed uint64
+	OctetsElapsed  uint64
+
+	// RoundTrips holds the last round trip per receiver, by SSRC
+	RoundTrips []RoundTrip
+}
+
+// span returns the time between the first and the last report by the
+// clock of the sender, and false if it is too short to compute rates from
+func (s Sender) span() (time.Duration, bool) {
+	span := s.Last.NTP.Sub(s.First.NTP)
+	return span, span >= minSpan
+}
+
+// ppm returns by how many parts per million a is longer than b
+func ppm(a, b time.Duration) float64 {
+	return (a - b).Seconds() / b.Seconds() * 1e6
+}
+
+// ClockRate returns the RTP clock rate measured against the NTP clock of
+// the sender, and false if the reports don't span enough time yet
+func (s Sender) ClockRate() (float64, bool) {
+	span, ok := s.span()
+	if !ok {
+		return 0, false
+	}
+
+	return float64(s.RTPElapsed) / span.Seconds(), true
+}
+
+// RTPDrift returns by how many parts per million the RTP clock of the
+// sender is faster than the nominal clock rate, measured against its NTP
+// clock
+func (s Sender) RTPDrift(clockRate uint32) (float64, bool) {
+	rate, ok := s.ClockRate()
+	if !ok || clockRate == 0 {
+		return 0, false
+	}
+
+	return (rate/float64(clockRate) - 1) * 1e6, true
+}
+
+// LocalDrift returns by how many parts per million the NTP clock of the
+// sender is faster than the local clock
+func (s Sender) LocalDrift() (float64, bool) {
+	span, ok := s.span()
+	local := s.Last.Arrival.Sub(s.First.Arrival)
+	if !ok || local < minSpan {
+		return 0, false
+	}
+
+	return ppm(span, local), true
+}
+
+// PTPDrift returns by how many parts per million the NTP clock of the
+// sender is faster than the PTP clock
+func (s Sender) PTPDrift() (float64, bool) {
+	if s.First.PTP.IsZero() || s.Last.PTP.IsZero() {
+		return 0, false
+	}
+
+	span, ok := s.span()
+	ptp := s.Last.PTP.Sub(s.First.PTP)
+	if !ok || ptp < minSpan {
+		return 0, false
+	}
+
+	return ppm(span, ptp), true
+}
+
+// LocalOffset returns how far the NTP clock of the sender is ahead of the
+// local clock, as of the last report. It includes the network delay.
+func (s Sender) LocalOffset() time.Duration {
+	return s.Last.NTP.Sub(s.Last.Arrival)
+}
+
+// PTPOffset returns how far the NTP clock of the sender is ahead of the
+// PTP clock, as of the last report, and false if the PTP time is unknown.
+// Senders that take their NTP time from PTP are a few microseconds ahead,
+// or the TAI-UTC offset if they send UTC.
+func (s Sender) PTPOffset() (time.Duration, bool) {
+	if s.Last.PTP.IsZero() {
+		return 0, false
+	}
+
+	return s.Last.NTP.Sub(s.Last.PTP), true
+}
+
+// RTPAt returns the RTP timestamp of the sender at the given NTP time, from
+// the last report and the measured clock rate, or the nominal one if it
+// can't be measured yet
+func (s Sender) RTPAt(t time.Time, clockRate uint32) uint32 {
+	rate := float64(clockRate)
+	if measured, ok := s.ClockRate(); ok {
+		rate = measured
+	}
+
+	return s.Last.RTP + uint32(int64(t.Sub(s.Last.NTP).Seconds()*rate))
+}
+
+// PacketRate returns the packets per second sent between the first and the
+// last report
+func (s Sender) PacketRate() (float64, bool) {
+	span, ok := s.span()
+	if !ok {
+		return 0, false
+	}
+
+	return float64(s.PacketsElapsed) / span.Seconds(), true
+}
+
+// Bitrate returns the payload bits per second sent between the first and
+// the last report
+func (s Sender) Bitrate() (float64, bool) {
+	span, ok := s.span()
+	if !ok {
+		return 0, false
+	}
+
+	return float64(s.OctetsElapsed*8) / span.Seconds(), true
+}
+
+// recentReport is a sender report remembered to match receiver reports
+type recentReport struct {
+	lsr     uint32 // the middle 32 bits of the NTP timestamp
+	arrival time.Time
+}
+
+type sender struct {
+	Sender
+
+	recent     []recentReport
+	roundTrips map[uint32]RoundTrip
+}
+
+// add accounts a sender report
+func (s *sender) add(r Report) {
+	last := s.Last
+
+	s.Reports++
+	s.Last = r
+
+	// A clock going backwards or counters starting over are a restart
+	if s.Reports == 1 || r.NTP.Before(last.NTP) || r.Packets < last.Packets {
+		s.First = r
+		s.RTPElapsed = 0
+		s.PacketsElapsed = 0
+		s.OctetsElapsed = 0
+	} else {
+		s.RTPElapsed += int64(int32(r.RTP - last.RTP))
+		s.PacketsElapsed += uint64(r.Packets - last.Packets)
+		s.OctetsElapsed += uint64(r.Octets - last.Octets)
+	}
+
+	if len(s.recent) == maxRecent {
+		s.recent = slices.Delete(s.recent, 0, 1)
+	}
+
+	s.recent = append(s.recent, recentReport{
+		lsr:     r.LSR,
+		arrival: r.Arrival,
+	})
+}
+
+// addReception accounts a reception report block of the given reporter
+// that refers to a report of the sender
+func (s *sender) addReception(reporter uint32, block rtcp.ReceptionReport, arrival time.Time) {
+	if block.LastSenderReport == 0 {
+		return
+	}
+
+	i := slices.IndexFunc(s.recent, func(r recentReport) bool {
+		return r.lsr == block.LastSenderReport
+	})
+	if i < 0 {
+		return
+	}
+
+	delay := time.Duration(uint64(block.Delay) * uint64(time.Second) >> 16)
+
+	if _, ok := s.roundTrips[reporter]; !ok && len(s.roundTrips) == maxReporters {
+		var oldest *RoundTrip
+		for _, rt := range s.roundTrips {
+			if oldest == nil || rt.Arrival.Before(oldest.Arrival) {
+				oldest = &rt
+			}
+		}
+
+		delete(s.roundTrips, oldest.Reporter)
+	}
+
+	s.roundTrips[reporter] = RoundTrip{
+		Reporter: reporter,
+		Time:     arrival.Sub(s.recent[i].arrival) - delay,
+		Arrival:  arrival,
+	}
+}
+
+// Analyzer follows the sender reports of the senders of a stream
+type Analyzer struct {
+	mutex   sync.Mutex
+	senders map[uint32]*sender
+}
+
+// New creates a new, empty analyzer
+func New() *Analyzer {
+	return &Analyzer{
+		senders: make(map[uint32]*sender),
+	}
+}
+
+// Add accounts an RTCP packet received at the local time arrival, when the
+// PTP time was ptp, which is zero if unknown
+func (a *Analyzer) Add(packet rtcp.Packet, arrival, ptp time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var reporter uint32
+	var reports []rtcp.ReceptionReport
+
+	switch p := packet.(type) {
+	case *rtcp.SenderReport:
+		s, ok := a.senders[p.SSRC]
+		if !ok {
+			s = &sender{
+				Sender:     Sender{SSRC: p.SSRC},
+				roundTrips: make(map[uint32]RoundTrip),
+			}
+			a.senders[p.SSRC] = s
+		}
+
+		s.add(Report{
+			Arrival: arrival,
+			PTP:     ptp,
+			NTP:     NTPTime(p.NTPTime),
+			RTP:     p.RTPTime,
+			Packets: p.PacketCount,
+			Octets:  p.OctetCount,
+			LSR:     uint32(p.NTPTime >> 16),
+		})
+
+		reporter, reports = p.SSRC, p.Reports
+
+	case *rtcp.ReceiverReport:
+		reporter, reports = p.SSRC, p.Reports
+	}
+
+	for _, block := range reports {
+		if s, ok := a.senders[block.SSRC]; ok {
+			s.addReception(reporter, block, arrival)
+		}
+	}
+}
+
+// Senders returns the analysis of all senders, sorted by SSRC
+func (a *Analyzer) Senders() []Sender {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	senders := make([]Sender, 0, len(a.senders))
+
+	for _, s := range a.senders {
+		result := s.Sender
+		result.RoundTrips = make([]RoundTrip, 0, len(s.roundTrips))
+
+		for _, rt := range s.roundTrips {
+			result.RoundTrips = append(result.RoundTrips, rt)
+		}
+
+		slices.SortFunc(result.RoundTrips, func(a, b RoundTrip) int {
+			return cmp.Compare(a.Reporter, b.Reporter)
+		})
+
+		senders = append(senders, result)
+	}
+
+	slices.SortFunc(senders, func(a, b Sender) int {
+		return cmp.Compare(a.SSRC, b.SSRC)
+	})
+
+	return senders
+}
@@ -0,0 +1,152 @@
+package senderreport
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+var testStart = time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)
+
+// toNTP converts a time to a 64 bit NTP timestamp
+func toNTP(t time.Time) uint64 {
+	d := t.Sub(ntpEpoch)
+	seconds := uint64(d / time.Second)
+	fraction := uint64(d%time.Second) << 32 / uint64(time.Second)
+
+	return seconds<<32 | fraction
+}
+
+func TestNTPTime(t *testing.T) {
+	want := testStart.Add(250 * time.Millisecond)
+
+	if got := NTPTime(toNTP(want)); got.Sub(want).Abs() > time.Microsecond {
+		t.Errorf("NTPTime() = %v, want %v", got, want)
+	}
+}
+
+// sendReports adds sender reports every 5 s for a minute, from a sender
+// whose NTP clock runs ntpPPM faster than the local and PTP clocks and
+// whose RTP clock runs rtpPPM faster than 48 kHz by its NTP clock
+func sendReports(a *Analyzer, ntpPPM, rtpPPM float64) {
+	for i := range 13 {
+		local := time.Duration(i) * 5 * time.Second
+		ntp := time.Duration(float64(local) * (1 + ntpPPM/1e6))
+		rtp := uint32(ntp.Seconds() * 48000 * (1 + rtpPPM/1e6))
+
+		arrival := testStart.Add(local)
+
+		a.Add(&rtcp.SenderReport{
+			SSRC:        0x1234,
+			NTPTime:     toNTP(testStart.Add(ntp)),
+			RTPTime:     0xffff0000 + rtp,
+			PacketCount: uint32(i * 5000),
+			OctetCount:  uint32(i * 5000 * 288),
+		}, arrival, arrival)
+	}
+}
+
+func TestSenderDrift(t *testing.T) {
+	a := New()
+	sendReports(a, 20, -10)
+
+	senders := a.Senders()
+	if len(senders) != 1 || senders[0].SSRC != 0x1234 || senders[0].Reports != 13 {
+		t.Fatalf("got senders %+v, want one with 13 reports", senders)
+	}
+
+	s := senders[0]
+
+	for _, c := range []struct {
+		name string
+		fn   func() (float64, bool)
+		want float64
+	}{
+		{"RTPDrift", func() (float64, bool) { return s.RTPDrift(48000) }, -10},
+		{"LocalDrift", s.LocalDrift, 20},
+		{"PTPDrift", s.PTPDrift, 20},
+		{"PacketRate", s.PacketRate, 1000},
+	} {
+		got, ok := c.fn()
+		// The RTP timestamps are whole samples, 0.35 ppm of a minute
+		if !ok || math.Abs(got-c.want) > 0.5 {
+			t.Errorf("%s() = %f, %v, want %f", c.name, got, ok, c.want)
+		}
+	}
+
+	// The RTP timestamp 1 s after the last report, 10 ppm short of 48000
+	want := s.Last.RTP + 47999
+	if got := s.RTPAt(s.Last.NTP.Add(time.Second), 48000); got != want {
+		t.Errorf("RTPAt() = %d, want %d", got, want)
+	}
+}
+
+func TestSenderTooShort(t *testing.T) {
+	a := New()
+
+	a.Add(&rtcp.SenderReport{SSRC: 1, NTPTime: toNTP(testStart)}, testStart, time.Time{})
+
+	s := a.Senders()[0]
+
+	if _, ok := s.RTPDrift(48000); ok {
+		t.Error("RTPDrift() succeeded with a single report")
+	}
+
+	if _, ok := s.PTPOffset(); ok {
+		t.Error("PTPOffset() succeeded without PTP time")
+	}
+
+	// Without a measured clock rate, the nominal one is used
+	if got := s.RTPAt(testStart.Add(time.Second), 48000); got != 48000 {
+		t.Errorf("RTPAt() = %d, want 48000", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	a := New()
+
+	sent := toNTP(testStart)
+	a.Add(&rtcp.SenderReport{SSRC: 1, NTPTime: sent}, testStart, time.Time{})
+
+	// The receiver held the report for 100 ms and the receiver report
+	// arrived 102 ms after the sender report
+	a.Add(&rtcp.ReceiverReport{
+		SSRC: 2,
+		Reports: []rtcp.ReceptionReport{{
+			SSRC:             1,
+			LastSenderReport: uint32(sent >> 16),
+			Delay:            65536 / 10,
+		}},
+	}, testStart.Add(102*time.Millisecond), time.Time{})
+
+	// A report referring to an unknown sender report is ignored
+	a.Add(&rtcp.ReceiverReport{
+		SSRC:    3,
+		Reports: []rtcp.ReceptionReport{{SSRC: 1, LastSenderReport: 42}},
+	}, testStart.Add(time.Second), time.Time{})
+
+	roundTrips := a.Senders()[0].RoundTrips
+	if len(roundTrips) != 1 || roundTrips[0].Reporter != 2 {
+		t.Fatalf("got round trips %+v, want one of reporter 2", roundTrips)
+	}
+
+	if got := roundTrips[0].Time; (got - 2*time.Millisecond).Abs() > 100*time.Microsecond {
+		t.Errorf("round trip = %v, want 2ms", got)
+	}
+}
+
+func TestSenderRestart(t *testing.T) {
+	a := New()
+	sendReports(a, 0, 0)
+
+	// The sender starts over with its counters
+	arrival := testStart.Add(time.Minute + 5*time.Second)
+	a.Add(&rtcp.SenderReport{SSRC: 0x1234, NTPTime: toNTP(arrival)}, arrival, arrival)
+
+	s := a.Senders()[0]
+	if !s.First.Arrival.Equal(arrival) || s.RTPElapsed != 0 {
+		t.Errorf("first report at %v with %d RTP elapsed, want %v and 0", s.First.Arrival, s.RTPElapsed, arrival)
+	}
+}
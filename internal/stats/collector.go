@@ -0,0 +1,488 @@
+package stats
+
+import (
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/receiverreport"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp/v2"
+)
+
+const (
+	// rateWindow is the length of the buckets packet rates are computed over
+	rateWindow = time.Second
+
+	// staleTimeout is the time without packets after which the rate drops to zero
+	staleTimeout = 2 * rateWindow
+
+	// levelWindow is the time constant the peak levels decay with
+	levelWindow = 500 * time.Millisecond
+)
+
+// SourceSnapshot holds the statistics of one stream source at a point in time
+type SourceSnapshot struct {
+	Packets        uint64
+	RTPErrors      uint64
+	SequenceErrors uint64
+	Lost           int64 // from the extended sequence numbers, see stream.SequenceStats
+	Reordered      uint64
+	PacketRate     float64
+	Bytes          uint64
+	Bitrate        float64 // bits per second
+	Jitter         time.Duration
+	LastPacket     time.Time
+
+	// Ended is when all senders of the source said goodbye with an RTCP
+	// BYE, zero unless they did and no packets arrived since
+	Ended time.Time
+
+	// Peak is the decaying peak level of all channels in dBFS, if the
+	// snapshot has levels
+	Peak float64
+}
+
+// Snapshot holds the statistics of all sources of a stream
+type Snapshot struct {
+	Started time.Time
+	Sources []SourceSnapshot
+
+	// Levels is set if the peak levels of the sources are measured
+	Levels bool
+}
+
+// Peak returns the highest peak level of all sources in dBFS
+func (s Snapshot) Peak() float64 {
+	peak := float64(levels.SilenceDB)
+	for _, source := range s.Sources {
+		peak = max(peak, source.Peak)
+	}
+
+	return peak
+}
+
+// PacketRate returns the summed packet rate of all sources
+func (s Snapshot) PacketRate() float64 {
+	var rate float64
+	for _, source := range s.Sources {
+		rate += source.PacketRate
+	}
+
+	return rate
+}
+
+// SequenceErrors returns the summed sequence errors of all sources
+func (s Snapshot) SequenceErrors() uint64 {
+	var n uint64
+	for _, source := range s.Sources {
+		n += source.SequenceErrors
+	}
+
+	return n
+}
+
+// Ended returns when the last source of the stream ended with an RTCP BYE,
+// zero unless all of them did
+func (s Snapshot) Ended() time.Time {
+	var t time.Time
+	for _, source := range s.Sources {
+		if source.Ended.IsZero() {
+			return time.Time{}
+		}
+
+		if source.Ended.After(t) {
+			t = source.Ended
+		}
+	}
+
+	return t
+}
+
+// LastPacket returns the arrival time of the most recent packet on any source
+func (s Snapshot) LastPacket() time.Time {
+	var t time.Time
+	for _, source := range s.Sources {
+		if source.LastPacket.After(t) {
+			t = source.LastPacket
+		}
+	}
+
+	return t
+}
+
+type sourceStats struct {
+	packets     uint64
+	bytes       uint64
+	lastPacket  time.Time
+	windowStart time.Time
+	windowCount uint64
+	windowBytes uint64
+	packetRate  float64
+	bitrate     float64
+
+	// Interarrival jitter as in RFC 3550, section 6.4.1, in units of the
+	// RTP clock
+	clockRate     uint32
+	jitter        float64
+	firstArrival  time.Time
+	elapsed       float64 // RTP clock units since the first packet
+	lastTransit   float64
+	lastTimestamp uint32
+}
+
+// add accounts a packet of the given size received at the given time
+func (s *sourceStats) add(now time.Time, size int) {
+	s.packets++
+	s.bytes += uint64(size)
+	s.lastPacket = now
+
+	if s.windowStart.IsZero() {
+		s.windowStart = now
+	}
+
+	if elapsed := now.Sub(s.windowStart); elapsed >= rateWindow {
+		s.packetRate = float64(s.windowCount) / elapsed.Seconds()
+		s.bitrate = float64(s.windowBytes*8) / elapsed.Seconds()
+		s.windowStart = now
+		s.windowCount = 0
+		s.windowBytes = 0
+	}
+
+	s.windowCount++
+	s.windowBytes += uint64(size)
+}
+
+// addTimestamp updates the interarrival jitter with the RTP timestamp of a
+// packet received at the given time
+func (s *sourceStats) addTimestamp(now time.Time, timestamp uint32) {
+	if s.clockRate == 0 {
+		return
+	}
+
+	first := s.firstArrival.IsZero()
+	if first {
+		s.firstArrival = now
+	}
+
+	// Arrival time in RTP clock units, relative to the first packet as only
+	// differences matter
+	arrival := now.Sub(s.firstArrival).Seconds() * float64(s.clockRate)
+
+	// Accumulate the timestamp differences to handle wrap-arounds
+	if !first {
+		s.elapsed += float64(int32(timestamp - s.lastTimestamp))
+	}
+
+	transit := arrival - s.elapsed
+
+	// Packets with the same timestamp (e.g. of a video frame) are skipped
+	if !first && timestamp != s.lastTimestamp {
+		s.jitter += (math.Abs(transit-s.lastTransit) - s.jitter) / 16
+	}
+
+	s.lastTransit = transit
+	s.lastTimestamp = timestamp
+}
+
+// jitterDuration returns the interarrival jitter as a duration
+func (s *sourceStats) jitterDuration() time.Duration {
+	if s.clockRate == 0 {
+		return 0
+	}
+
+	return time.Duration(s.jitter / float64(s.clockRate) * float64(time.Second))
+}
+
+// rate returns the packet rate of the last complete window, or zero if the
+// source went silent
+func (s *sourceStats) rate(now time.Time) float64 {
+	if now.Sub(s.lastPacket) > staleTimeout {
+		return 0
+	}
+
+	return s.packetRate
+}
+
+// bits returns the bitrate of the last complete window, or zero if the
+// source went silent
+func (s *sourceStats) bits(now time.Time) float64 {
+	if now.Sub(s.lastPacket) > staleTimeout {
+		return 0
+	}
+
+	return s.bitrate
+}
+
+type entry struct {
+	// mutex guards the statistics updated by the packets of the stream,
+	// sources, peaks and samples, and the receiver until it is set
+	mutex sync.Mutex
+
+	started  time.Time
+	receiver *stream.RTPReceiver
+	reporter *receiverreport.Reporter // nil unless receiver reports are enabled
+	sources  []*sourceStats
+
+	// rtcp receives the SDES and BYE packets for tracker, nil if RTCP
+	// can't be received
+	rtcp    *stream.RTCPReceiver
+	tracker *RTCPTracker
+
+	// peaks holds the peak level of all channels per source, measured
+	// while levels are enabled for audio streams
+	audio   bool
+	peaks   []levels.Running
+	samples []stream.Sample
+}
+
+// addLevels accounts the samples of a packet in the peak level of a source.
+// The caller must hold the mutex.
+func (e *entry) addLevels(sourceIndex int, packet *rtp.Packet) {
+	var err error
+
+	e.samples, err = e.receiver.DecodeSamples(e.samples, packet)
+	if err != nil {
+		return
+	}
+
+	for _, value := range e.samples {
+		s := float64(int32(value)) / math.MaxInt32
+		e.peaks[sourceIndex].Add(s * s)
+	}
+}
+
+// close stops the receivers and the reporter of the entry
+func (e *entry) close() {
+	e.receiver.Close()
+
+	if e.rtcp != nil {
+		e.rtcp.Close()
+	}
+
+	if e.reporter != nil {
+		e.reporter.Close()
+	}
+}
+
+// Collector runs background RTP receivers for a set of streams and keeps
+// statistics for them, independent of any open modal.
+type Collector struct {
+	// mutex guards the entries, whose statistics have their own lock, so
+	// the packets of different streams don't contend for it
+	mutex   sync.Mutex
+	entries map[string]*entry
+
+	// receiverReports configures the RTCP receiver reports sent for the
+	// collected streams, nil if disabled
+	receiverReports *receiverreport.Options
+
+	// levels enables measuring the peak levels, which decodes every packet
+	levels atomic.Bool
+}
+
+// NewCollector creates a new, empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		entries: make(map[string]*entry),
+	}
+}
+
+// EnableReceiverReports makes the collector send RTCP receiver reports for
+// the streams started afterwards
+func (c *Collector) EnableReceiverReports(opts receiverreport.Options) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.receiverReports = &opts
+}
+
+// EnableLevels enables or disables measuring the peak levels of the audio
+// streams statistics are collected for
+func (c *Collector) EnableLevels(enabled bool) {
+	c.levels.Store(enabled)
+}
+
+// Start starts collecting statistics for a stream. Starting a stream that is
+// already collected is a no-op.
+func (c *Collector) Start(s *stream.Stream) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.entries[s.ID]; ok {
+		return nil
+	}
+
+	e := &entry{
+		started: time.Now(),
+		sources: make([]*sourceStats, len(s.Description.Sources)),
+		tracker: NewRTCPTracker(len(s.Description.Sources)),
+		audio:   levels.IsAudio(s),
+		peaks:   make([]levels.Running, len(s.Description.Sources)),
+	}
+
+	for i := range e.sources {
+		e.sources[i] = &sourceStats{
+			clockRate: s.Description.SampleRate,
+		}
+
+		// All channels are accounted in one level, which decays per sample
+		e.peaks[i] = levels.NewRunning(levelWindow, s.Description.SampleRate*uint32(max(s.Description.ChannelCount, 1)))
+	}
+
+	receiver, err := s.NewRTPReceiver(func(sourceIndex int, _ net.Addr, packet *rtp.Packet, arrival time.Time) {
+		e.mutex.Lock()
+		defer e.mutex.Unlock()
+
+		if sourceIndex < len(e.sources) {
+			e.sources[sourceIndex].add(arrival, packet.MarshalSize())
+			e.sources[sourceIndex].addTimestamp(arrival, packet.Timestamp)
+
+			// Packets may arrive before the receiver is set
+			if e.audio && c.levels.Load() && e.receiver != nil {
+				e.addLevels(sourceIndex, packet)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	e.mutex.Lock()
+	e.receiver = receiver
+	e.mutex.Unlock()
+
+	if c.receiverReports != nil {
+		if e.reporter, err = receiverreport.Start(s, *c.receiverReports); err != nil {
+			receiver.Close()
+			return err
+		}
+	}
+
+	// Statistics don't depend on RTCP, so failing to receive it is not an
+	// error
+	e.rtcp, _ = s.NewRTCPReceiver(func(sourceIndex int, _ net.Addr, packet rtcp.Packet) {
+		e.tracker.Add(sourceIndex, packet, time.Now())
+	})
+
+	c.entries[s.ID] = e
+
+	return nil
+}
+
+// Stop stops collecting statistics for the stream with the given ID
+func (c *Collector) Stop(id string) {
+	c.mutex.Lock()
+	e, ok := c.entries[id]
+	delete(c.entries, id)
+	c.mutex.Unlock()
+
+	if ok {
+		e.close()
+	}
+}
+
+// StopAll stops all background receivers
+func (c *Collector) StopAll() {
+	c.mutex.Lock()
+	entries := c.entries
+	c.entries = make(map[string]*entry)
+	c.mutex.Unlock()
+
+	for _, e := range entries {
+		e.close()
+	}
+}
+
+// StopMissing stops collecting statistics for all streams not in the list
+func (c *Collector) StopMissing(streams []*stream.Stream) {
+	ids := make(map[string]bool, len(streams))
+	for _, s := range streams {
+		ids[s.ID] = true
+	}
+
+	c.mutex.Lock()
+	var missing []string
+	for id := range c.entries {
+		if !ids[id] {
+			missing = append(missing, id)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, id := range missing {
+		c.Stop(id)
+	}
+}
+
+// IsRunning returns whether statistics are collected for the given stream ID
+func (c *Collector) IsRunning(id string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, ok := c.entries[id]
+	return ok
+}
+
+// Count returns the number of streams statistics are collected for
+func (c *Collector) Count() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.entries)
+}
+
+// Snapshot returns the current statistics for the given stream ID
+func (c *Collector) Snapshot(id string) (Snapshot, bool) {
+	c.mutex.Lock()
+	e, ok := c.entries[id]
+	if !ok {
+		c.mutex.Unlock()
+		return Snapshot{}, false
+	}
+
+	c.mutex.Unlock()
+
+	now := time.Now()
+
+	snapshot := Snapshot{
+		Started: e.started,
+		Sources: make([]SourceSnapshot, len(e.sources)),
+		Levels:  e.audio && c.levels.Load(),
+	}
+
+	e.mutex.Lock()
+
+	for i, s := range e.sources {
+		snapshot.Sources[i] = SourceSnapshot{
+			Packets:    s.packets,
+			PacketRate: s.rate(now),
+			Bytes:      s.bytes,
+			Bitrate:    s.bits(now),
+			Jitter:     s.jitterDuration(),
+			LastPacket: s.lastPacket,
+			Peak:       e.peaks[i].Level().Peak,
+		}
+	}
+
+	e.mutex.Unlock()
+
+	// The receiver and the tracker have their own locks, don't hold the
+	// one of the entry while querying them
+	for i := range snapshot.Sources {
+		snapshot.Sources[i].Ended = e.tracker.Ended(i, snapshot.Sources[i].LastPacket)
+
+		snapshot.Sources[i].RTPErrors = e.receiver.RTPErrors(i)
+		snapshot.Sources[i].SequenceErrors = e.receiver.SequenceErrors(i)
+
+		sequence := e.receiver.SequenceStats(i)
+		snapshot.Sources[i].Lost = sequence.Lost
+		snapshot.Sources[i].Reordered = sequence.Reordered
+	}
+
+	return snapshot, true
+}
@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSourceStatsRate(t *testing.T) {
+	s := &sourceStats{}
+	start := time.Unix(1000, 0)
+
+	// 1000 packets per second for two seconds
+	for i := range 2000 {
+		s.add(start.Add(time.Duration(i)*time.Millisecond), 100)
+	}
+
+	now := start.Add(2 * time.Second)
+
+	if got := s.rate(now); math.Abs(got-1000) > 1 {
+		t.Errorf("rate() = %f, want 1000", got)
+	}
+
+	if s.packets != 2000 {
+		t.Errorf("packets = %d, want 2000", s.packets)
+	}
+
+	if got := s.bits(now); math.Abs(got-800000) > 1000 {
+		t.Errorf("bits() = %f, want 800000", got)
+	}
+}
+
+func TestSourceStatsJitter(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	// 48 kHz, one packet of 48 samples per millisecond
+	steady := &sourceStats{clockRate: 48000}
+	for i := range 1000 {
+		steady.addTimestamp(start.Add(time.Duration(i)*time.Millisecond), uint32(i*48))
+	}
+
+	if got := steady.jitterDuration(); got > time.Microsecond {
+		t.Errorf("jitterDuration() = %v for steady packets, want 0", got)
+	}
+
+	// Every other packet arrives 100µs late
+	late := &sourceStats{clockRate: 48000}
+	for i := range 1000 {
+		arrival := start.Add(time.Duration(i) * time.Millisecond)
+		if i%2 == 1 {
+			arrival = arrival.Add(100 * time.Microsecond)
+		}
+
+		late.addTimestamp(arrival, uint32(i*48))
+	}
+
+	if got := late.jitterDuration(); got < 90*time.Microsecond || got > 110*time.Microsecond {
+		t.Errorf("jitterDuration() = %v, want about 100µs", got)
+	}
+}
+
+func TestSourceStatsNoRateBeforeFirstWindow(t *testing.T) {
+	s := &sourceStats{}
+	start := time.Unix(1000, 0)
+
+	for i := range 500 {
+		s.add(start.Add(time.Duration(i)*time.Millisecond), 100)
+	}
+
+	if got := s.rate(start.Add(500 * time.Millisecond)); got != 0 {
+		t.Errorf("rate() = %f, want 0 before the first window completed", got)
+	}
+}
+
+func TestSourceStatsStale(t *testing.T) {
+	s := &sourceStats{}
+	start := time.Unix(1000, 0)
+
+	for i := range 1500 {
+		s.add(start.Add(time.Duration(i)*time.Millisecond), 100)
+	}
+
+	if got := s.rate(start.Add(1500 * time.Millisecond)); got == 0 {
+		t.Error("Expected non-zero rate while packets arrive")
+	}
+
+	if got := s.rate(start.Add(10 * time.Second)); got != 0 {
+		t.Errorf("rate() = %f, want 0 after source went silent", got)
+	}
+}
+
+func TestSnapshotAggregates(t *testing.T) {
+	now := time.Unix(2000, 0)
+
+	s := Snapshot{
+		Sources: []SourceSnapshot{
+			{PacketRate: 1000, SequenceErrors: 2, LastPacket: now.Add(-time.Second), Peak: -20, Ended: now},
+			{PacketRate: 999, SequenceErrors: 3, LastPacket: now, Peak: -6, Ended: now.Add(time.Second)},
+		},
+	}
+
+	if got := s.PacketRate(); got != 1999 {
+		t.Errorf("PacketRate() = %f, want 1999", got)
+	}
+
+	if got := s.SequenceErrors(); got != 5 {
+		t.Errorf("SequenceErrors() = %d, want 5", got)
+	}
+
+	if got := s.LastPacket(); !got.Equal(now) {
+		t.Errorf("LastPacket() = %v, want %v", got, now)
+	}
+
+	if got := s.Peak(); got != -6 {
+		t.Errorf("Peak() = %f, want -6", got)
+	}
+
+	if got := s.Ended(); !got.Equal(now.Add(time.Second)) {
+		t.Errorf("Ended() = %v, want the time of the last BYE", got)
+	}
+
+	s.Sources[0].Ended = time.Time{}
+
+	if got := s.Ended(); !got.IsZero() {
+		t.Errorf("Ended() = %v with a source still running, want zero", got)
+	}
+}
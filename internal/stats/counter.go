@@ -0,0 +1,111 @@
+package stats
+
+import (
+	"math"
+	"time"
+)
+
+// Counter follows a cumulative counter of the given bit width that wraps
+// around, such as the counters of network hardware, and computes its rate
+// between two readings
+type Counter struct {
+	bits  uint
+	last  uint64
+	since time.Time
+
+	// Total is the increase since the first reading or the last Reset
+	Total uint64
+
+	// Rate is the increase per second between the last two readings
+	Rate float64
+
+	// MinRate and MaxRate are the extremes of Rate since the first reading
+	// or the last Reset, NaN before two readings were taken
+	MinRate float64
+	MaxRate float64
+}
+
+// NewCounter creates a counter that wraps around at the given bit width
+func NewCounter(bits uint) *Counter {
+	c := &Counter{bits: bits}
+	c.Reset()
+
+	return c
+}
+
+// Update accounts a reading of the counter taken at the given time
+func (c *Counter) Update(value uint64, now time.Time) {
+	if c.since.IsZero() {
+		c.last = value
+		c.since = now
+
+		return
+	}
+
+	delta := (value - c.last) & (1<<c.bits - 1)
+	elapsed := now.Sub(c.since).Seconds()
+
+	c.last = value
+	c.since = now
+	c.Total += delta
+
+	if elapsed <= 0 {
+		return
+	}
+
+	c.Rate = float64(delta) / elapsed
+
+	if math.IsNaN(c.MinRate) || c.Rate < c.MinRate {
+		c.MinRate = c.Rate
+	}
+
+	if math.IsNaN(c.MaxRate) || c.Rate > c.MaxRate {
+		c.MaxRate = c.Rate
+	}
+}
+
+// Reset starts the total and the extremes of the rate over. The last
+// reading is kept, so the next one adds to the total.
+func (c *Counter) Reset() {
+	c.Total = 0
+	c.Rate = 0
+	c.MinRate = math.NaN()
+	c.MaxRate = math.NaN()
+}
+
+// Gauge tracks the extremes of a value that goes up and down
+type Gauge struct {
+	Last float64
+
+	// Min and Max are the extremes since the first reading or the last
+	// Reset, NaN before any reading was taken
+	Min float64
+	Max float64
+}
+
+// NewGauge creates a gauge without readings
+func NewGauge() *Gauge {
+	g := &Gauge{}
+	g.Reset()
+
+	return g
+}
+
+// Update accounts a reading of the value
+func (g *Gauge) Update(value float64) {
+	g.Last = value
+
+	if math.IsNaN(g.Min) || value < g.Min {
+		g.Min = value
+	}
+
+	if math.IsNaN(g.Max) || value > g.Max {
+		g.Max = value
+	}
+}
+
+// Reset starts the extremes over
+func (g *Gauge) Reset() {
+	g.Min = math.NaN()
+	g.Max = math.NaN()
+}
@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCounterWrap(t *testing.T) {
+	c := NewCounter(16)
+	now := time.Unix(1000, 0)
+
+	c.Update(65000, now)
+	c.Update(65500, now.Add(time.Second))
+
+	// The counter wraps around between the readings
+	c.Update(464, now.Add(3*time.Second))
+
+	if c.Total != 1000 || c.Rate != 250 || c.MinRate != 250 || c.MaxRate != 500 {
+		t.Errorf("got total %d, rate %f (%f to %f), want 1000, 250 (250 to 500)", c.Total, c.Rate, c.MinRate, c.MaxRate)
+	}
+
+	c.Reset()
+
+	if c.Total != 0 || !math.IsNaN(c.MinRate) {
+		t.Errorf("got total %d, min rate %f after Reset(), want 0 and NaN", c.Total, c.MinRate)
+	}
+
+	// The reading before the reset is the base of the next one
+	c.Update(564, now.Add(4*time.Second))
+
+	if c.Total != 100 || c.MinRate != 100 || c.MaxRate != 100 {
+		t.Errorf("got total %d, rate %f to %f, want 100 and 100 to 100", c.Total, c.MinRate, c.MaxRate)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGauge()
+
+	for _, v := range []float64{5, 2, 9, 4} {
+		g.Update(v)
+	}
+
+	if g.Last != 4 || g.Min != 2 || g.Max != 9 {
+		t.Errorf("got %+v, want last 4, min 2, max 9", g)
+	}
+
+	g.Reset()
+	g.Update(7)
+
+	if g.Min != 7 || g.Max != 7 {
+		t.Errorf("got %+v after Reset(), want min and max 7", g)
+	}
+}
@@ -0,0 +1,271 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/pion/rtp/v2"
+)
+
+const (
+	// BucketLength is the time span of an Engine bucket
+	BucketLength = time.Second
+
+	// HistoryLength is the number of complete buckets an Engine keeps
+	HistoryLength = 60
+)
+
+// Bucket holds the statistics of a source over one BucketLength
+type Bucket struct {
+	Start          time.Time
+	Packets        uint64
+	SequenceErrors uint64
+
+	// Lost is negative if duplicates were received
+	Lost int64
+
+	// Jitter is the interarrival jitter at the end of the bucket
+	Jitter time.Duration
+}
+
+// Rate returns the packet rate of the bucket
+func (b Bucket) Rate() float64 {
+	return float64(b.Packets) / BucketLength.Seconds()
+}
+
+// SequenceErrorRate returns the sequence errors per second of the bucket
+func (b Bucket) SequenceErrorRate() float64 {
+	return float64(b.SequenceErrors) / BucketLength.Seconds()
+}
+
+// EngineSource holds the statistics of one source of an Engine
+type EngineSource struct {
+	Packets        uint64
+	SequenceErrors uint64
+	Sequence       stream.SequenceStats
+	Jitter         time.Duration
+	LastTimestamp  uint32
+	LastPacket     time.Time
+
+	// Last is the most recent complete bucket, zero if there is none yet
+	Last Bucket
+}
+
+// EngineDelta is the change of the statistics of a source since a mark
+type EngineDelta struct {
+	Elapsed        time.Duration
+	Packets        uint64
+	SequenceErrors uint64
+	Expected       uint64
+
+	// Lost is negative if more duplicates than losses occurred
+	Lost      int64
+	Reordered uint64
+
+	// Jitter is the change of the interarrival jitter
+	Jitter time.Duration
+}
+
+// Rate returns the average packet rate since the mark
+func (d EngineDelta) Rate() float64 {
+	if d.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(d.Packets) / d.Elapsed.Seconds()
+}
+
+// Since returns the change of the statistics since mark, taken elapsed
+// before them. Counters that went back, as after a reset of the sequence
+// statistics, count from zero.
+func (s EngineSource) Since(mark EngineSource, elapsed time.Duration) EngineDelta {
+	since := func(now, then uint64) uint64 {
+		if now < then {
+			return now
+		}
+
+		return now - then
+	}
+
+	d := EngineDelta{
+		Elapsed:        elapsed,
+		Packets:        since(s.Packets, mark.Packets),
+		SequenceErrors: since(s.SequenceErrors, mark.SequenceErrors),
+		Expected:       since(s.Sequence.Expected, mark.Sequence.Expected),
+		Reordered:      since(s.Sequence.Reordered, mark.Sequence.Reordered),
+		Jitter:         s.Jitter - mark.Jitter,
+	}
+
+	if s.Sequence.Expected < mark.Sequence.Expected {
+		d.Lost = s.Sequence.Lost
+	} else {
+		d.Lost = s.Sequence.Lost - mark.Sequence.Lost
+	}
+
+	return d
+}
+
+type engineSource struct {
+	packets        uint64
+	sequenceErrors uint64
+	lastSequence   uint16
+	lastTimestamp  uint32
+	sequence       stream.SequenceTracker
+	jitter         sourceStats
+	lastPacket     time.Time
+
+	// The sequence statistics at the start of the current bucket, to
+	// compute its losses from
+	base stream.SequenceStats
+
+	current Bucket
+	history *ring.Window[Bucket]
+}
+
+// newEngineSource creates the statistics of a source with the given RTP
+// clock rate
+func newEngineSource(clockRate uint32) *engineSource {
+	return &engineSource{
+		jitter: sourceStats{clockRate: clockRate},
+		// The window holds one bucket more than the history, as the
+		// oldest bucket leaves it before the newest one is complete
+		history: ring.NewWindow[Bucket]((HistoryLength+1)*BucketLength, HistoryLength),
+	}
+}
+
+// closeBucket finalizes the current bucket and adds it to the history
+func (s *engineSource) closeBucket() {
+	sequence := s.sequence.Stats()
+	s.current.Lost += int64(sequence.Expected-s.base.Expected) - int64(sequence.Received-s.base.Received)
+	s.current.Jitter = s.jitter.jitterDuration()
+	s.base = sequence
+
+	s.history.Push(s.current.Start, s.current)
+}
+
+// advance closes the buckets that ended before now, including the empty
+// ones of a silence, and starts the bucket now falls into
+func (s *engineSource) advance(now time.Time) {
+	start := now.Truncate(BucketLength)
+
+	if s.current.Start.IsZero() {
+		s.current.Start = start
+		return
+	}
+
+	for s.current.Start.Before(start) {
+		s.closeBucket()
+
+		next := s.current.Start.Add(BucketLength)
+
+		// Empty buckets older than the history are not worth adding
+		if oldest := start.Add(-HistoryLength * BucketLength); next.Before(oldest) {
+			next = oldest
+		}
+
+		s.current = Bucket{Start: next}
+	}
+}
+
+// add accounts a packet received at the given time
+func (s *engineSource) add(packet *rtp.Packet, arrival time.Time) {
+	s.advance(arrival)
+
+	if s.packets > 0 && packet.SequenceNumber != s.lastSequence+1 {
+		s.sequenceErrors++
+		s.current.SequenceErrors++
+	}
+
+	s.packets++
+	s.current.Packets++
+	s.lastSequence = packet.SequenceNumber
+	s.lastTimestamp = packet.Timestamp
+	s.lastPacket = arrival
+
+	// The sequence statistics start over with a reset, keep the losses
+	// counted until then
+	before := s.sequence.Stats()
+	if s.sequence.Update(packet.SequenceNumber) == stream.SequenceReset {
+		s.current.Lost += int64(before.Expected-s.base.Expected) - int64(before.Received-s.base.Received)
+		s.base = stream.SequenceStats{Expected: 1, Received: 1}
+	}
+
+	s.jitter.addTimestamp(arrival, packet.Timestamp)
+}
+
+// snapshot returns the statistics of the source, with the buckets that
+// ended before now closed
+func (s *engineSource) snapshot(now time.Time) EngineSource {
+	s.advance(now)
+
+	result := EngineSource{
+		Packets:        s.packets,
+		SequenceErrors: s.sequenceErrors,
+		Sequence:       s.sequence.Stats(),
+		Jitter:         s.jitter.jitterDuration(),
+		LastTimestamp:  s.lastTimestamp,
+		LastPacket:     s.lastPacket,
+	}
+
+	if buckets := s.history.Values(now); len(buckets) > 0 {
+		result.Last = buckets[len(buckets)-1]
+	}
+
+	return result
+}
+
+// Engine computes the packet rate, losses and jitter of the sources of a
+// stream in fixed buckets of BucketLength, aligned to the wall clock.
+// Packets are accounted to the bucket of their arrival time, so the results
+// don't depend on when or how often they are read.
+type Engine struct {
+	mutex   sync.Mutex
+	sources []*engineSource
+}
+
+// NewEngine creates an engine for the given number of sources of a stream
+// with the given RTP clock rate
+func NewEngine(sources int, clockRate uint32) *Engine {
+	e := &Engine{
+		sources: make([]*engineSource, sources),
+	}
+
+	for i := range e.sources {
+		e.sources[i] = newEngineSource(clockRate)
+	}
+
+	return e
+}
+
+// Add accounts a packet of the source with the given index
+func (e *Engine) Add(sourceIndex int, packet *rtp.Packet, arrival time.Time) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if sourceIndex < len(e.sources) {
+		e.sources[sourceIndex].add(packet, arrival)
+	}
+}
+
+// Source returns the statistics of the source with the given index, with
+// the buckets that ended before now closed
+func (e *Engine) Source(sourceIndex int, now time.Time) EngineSource {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.sources[sourceIndex].snapshot(now)
+}
+
+// History returns the complete buckets of the source with the given index
+// of the last HistoryLength buckets before now, oldest first
+func (e *Engine) History(sourceIndex int, now time.Time) []Bucket {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	s := e.sources[sourceIndex]
+	s.advance(now)
+
+	return s.history.Values(now)
+}
@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+// addPackets feeds an engine with n packets of 1 ms, 48 samples each,
+// starting at the given time and sequence number
+func addPackets(e *Engine, start time.Time, seq uint16, n int) {
+	for i := range n {
+		e.Add(0, &rtp.Packet{Header: rtp.Header{
+			SequenceNumber: seq + uint16(i),
+			Timestamp:      uint32(seq+uint16(i)) * 48,
+		}}, start.Add(time.Duration(i)*time.Millisecond))
+	}
+}
+
+func TestEngineBuckets(t *testing.T) {
+	e := NewEngine(1, 48000)
+	start := time.Unix(1000, 0)
+
+	addPackets(e, start, 0, 3000)
+
+	// The rate is that of the last complete bucket, no matter how often or
+	// when it is read
+	for _, now := range []time.Duration{3 * time.Second, 3*time.Second + 500*time.Millisecond} {
+		s := e.Source(0, start.Add(now))
+
+		if got := s.Last.Rate(); got != 1000 {
+			t.Errorf("rate at %v = %f, want 1000", now, got)
+		}
+
+		if s.Packets != 3000 {
+			t.Errorf("packets = %d, want 3000", s.Packets)
+		}
+	}
+
+	if got := len(e.History(0, start.Add(3*time.Second))); got != 3 {
+		t.Errorf("history has %d buckets, want 3", got)
+	}
+
+	// A silence closes empty buckets
+	s := e.Source(0, start.Add(10*time.Second))
+	if got := s.Last.Rate(); got != 0 {
+		t.Errorf("rate after silence = %f, want 0", got)
+	}
+
+	history := e.History(0, start.Add(10*time.Second))
+	if len(history) != 10 {
+		t.Fatalf("history has %d buckets, want 10", len(history))
+	}
+
+	for i, b := range history {
+		if want := start.Add(time.Duration(i) * time.Second); !b.Start.Equal(want) {
+			t.Errorf("bucket %d starts at %v, want %v", i, b.Start, want)
+		}
+	}
+
+	// The history is bounded after a long silence
+	if got := len(e.History(0, start.Add(time.Hour))); got != HistoryLength {
+		t.Errorf("history has %d buckets, want %d", got, HistoryLength)
+	}
+}
+
+func TestEngineLoss(t *testing.T) {
+	e := NewEngine(1, 48000)
+	start := time.Unix(1000, 0)
+
+	// 1000 packets in the first second, then 10 missing in the second one
+	addPackets(e, start, 0, 1000)
+	addPackets(e, start.Add(time.Second), 1010, 990)
+
+	history := e.History(0, start.Add(2*time.Second))
+	if len(history) != 2 {
+		t.Fatalf("history has %d buckets, want 2", len(history))
+	}
+
+	if history[0].Lost != 0 || history[0].SequenceErrors != 0 {
+		t.Errorf("first bucket lost %d with %d sequence errors, want none", history[0].Lost, history[0].SequenceErrors)
+	}
+
+	if history[1].Lost != 10 || history[1].SequenceErrors != 1 {
+		t.Errorf("second bucket lost %d with %d sequence errors, want 10 and 1", history[1].Lost, history[1].SequenceErrors)
+	}
+
+	s := e.Source(0, start.Add(2*time.Second))
+	if s.Sequence.Lost != 10 || s.SequenceErrors != 1 {
+		t.Errorf("lost %d with %d sequence errors, want 10 and 1", s.Sequence.Lost, s.SequenceErrors)
+	}
+
+	if s.Jitter > time.Microsecond {
+		t.Errorf("jitter = %v for steady packets, want 0", s.Jitter)
+	}
+}
+
+func TestEngineSince(t *testing.T) {
+	e := NewEngine(1, 48000)
+	start := time.Unix(1000, 0)
+
+	addPackets(e, start, 0, 1000)
+	mark := e.Source(0, start.Add(time.Second))
+
+	// 10 packets missing after the mark
+	addPackets(e, start.Add(time.Second), 1010, 990)
+
+	d := e.Source(0, start.Add(2*time.Second)).Since(mark, time.Second)
+
+	if d.Packets != 990 || d.Lost != 10 || d.Expected != 1000 || d.SequenceErrors != 1 {
+		t.Errorf("got %d packets, %d lost of %d, %d sequence errors; want 990, 10 of 1000, 1",
+			d.Packets, d.Lost, d.Expected, d.SequenceErrors)
+	}
+
+	if rate := d.Rate(); rate != 990 {
+		t.Errorf("rate = %f, want 990", rate)
+	}
+}
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+// InterfaceStats holds the statistics of a source as received on one
+// network interface
+type InterfaceStats struct {
+	Interface string
+	EngineSource
+}
+
+// Interfaces computes the statistics of the sources of a stream separately
+// for each interface they are received on, so problems of one network leg
+// can be told from those of the sender
+type Interfaces struct {
+	mutex     sync.Mutex
+	clockRate uint32
+	sources   []map[string]*engineSource
+}
+
+// NewInterfaces creates per interface statistics for the given number of
+// sources of a stream with the given RTP clock rate
+func NewInterfaces(sources int, clockRate uint32) *Interfaces {
+	s := &Interfaces{
+		clockRate: clockRate,
+		sources:   make([]map[string]*engineSource, sources),
+	}
+
+	for i := range s.sources {
+		s.sources[i] = make(map[string]*engineSource)
+	}
+
+	return s
+}
+
+// Add accounts a packet of the source with the given index received on the
+// named interface
+func (s *Interfaces) Add(sourceIndex int, ifi string, packet *rtp.Packet, arrival time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if sourceIndex >= len(s.sources) {
+		return
+	}
+
+	source, ok := s.sources[sourceIndex][ifi]
+	if !ok {
+		source = newEngineSource(s.clockRate)
+		s.sources[sourceIndex][ifi] = source
+	}
+
+	source.add(packet, arrival)
+}
+
+// Source returns the statistics of the source with the given index for
+// each interface it was received on, sorted by interface name
+func (s *Interfaces) Source(sourceIndex int, now time.Time) []InterfaceStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]InterfaceStats, 0, len(s.sources[sourceIndex]))
+	for name, source := range s.sources[sourceIndex] {
+		result = append(result, InterfaceStats{
+			Interface:    name,
+			EngineSource: source.snapshot(now),
+		})
+	}
+
+	slices.SortFunc(result, func(a, b InterfaceStats) int {
+		return cmp.Compare(a.Interface, b.Interface)
+	})
+
+	return result
+}
@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+func TestInterfaces(t *testing.T) {
+	s := NewInterfaces(1, 48000)
+	start := time.Unix(1000, 0)
+
+	// eth1 misses every tenth packet, eth0 receives all of them
+	for i := range 1000 {
+		packet := &rtp.Packet{Header: rtp.Header{
+			SequenceNumber: uint16(i),
+			Timestamp:      uint32(i) * 48,
+		}}
+		arrival := start.Add(time.Duration(i) * time.Millisecond)
+
+		s.Add(0, "eth0", packet, arrival)
+
+		if i%10 != 5 {
+			s.Add(0, "eth1", packet, arrival)
+		}
+	}
+
+	// Out of range sources are ignored
+	s.Add(1, "eth0", &rtp.Packet{}, start)
+
+	got := s.Source(0, start.Add(time.Second))
+	if len(got) != 2 {
+		t.Fatalf("got %d interfaces, want 2", len(got))
+	}
+
+	if got[0].Interface != "eth0" || got[1].Interface != "eth1" {
+		t.Errorf("interfaces = %s, %s, want eth0, eth1", got[0].Interface, got[1].Interface)
+	}
+
+	if got[0].Packets != 1000 || got[0].Sequence.Lost != 0 {
+		t.Errorf("eth0: %d packets, %d lost, want 1000, 0", got[0].Packets, got[0].Sequence.Lost)
+	}
+
+	if got[1].Packets != 900 || got[1].Sequence.Lost != 100 {
+		t.Errorf("eth1: %d packets, %d lost, want 900, 100", got[1].Packets, got[1].Sequence.Lost)
+	}
+
+	if rate := got[1].Last.Rate(); rate != 900 {
+		t.Errorf("eth1 rate = %f, want 900", rate)
+	}
+}
@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of recent packets per source the latency
+// distribution is computed from. At 1000 packets per second it covers about
+// eight seconds.
+const latencySamples = 8192
+
+// LatencyStats holds the distribution of the latency of the recent packets
+// of a source. Latencies are negative if packets arrive before the media
+// clock sample of their timestamp, i.e. the sender is not locked to the PTP
+// time received.
+type LatencyStats struct {
+	// Samples is the number of packets the distribution is computed from
+	Samples int
+
+	Min time.Duration
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+
+	// Histogram counts the packets in equally sized bins from Min to P99.
+	// Packets beyond P99 are counted in the last bin.
+	Histogram []uint64
+}
+
+type latencySource struct {
+	samples [latencySamples]time.Duration
+	next    int
+	count   int
+}
+
+// Latency measures the latency of the packets of a stream: the time from
+// the media clock sample of their RTP timestamp to their arrival, both on
+// the PTP time scale. It includes the packet time, the buffering of the
+// sender and the network, where the interarrival jitter only shows how it
+// varies.
+type Latency struct {
+	mutex     sync.Mutex
+	clockRate uint32
+	sources   []latencySource
+}
+
+// NewLatency creates a latency measurement of the given number of sources
+// with the media clock rate of the stream
+func NewLatency(sources int, clockRate uint32) *Latency {
+	return &Latency{
+		clockRate: clockRate,
+		sources:   make([]latencySource, sources),
+	}
+}
+
+// MediaClockSample returns the media clock sample at the PTP time t, which
+// counts samples since the PTP epoch, as RFC 7273 direct media clocks do
+func MediaClockSample(t time.Time, clockRate uint32) uint32 {
+	seconds := uint64(t.Unix()) * uint64(clockRate)
+	fraction := uint64(t.Nanosecond()) * uint64(clockRate) / uint64(time.Second)
+
+	return uint32(seconds + fraction)
+}
+
+// Add accounts a packet of the source with the given index and returns its
+// latency. sample is its RTP timestamp less the media clock offset of the
+// source, arrival the PTP time it arrived at.
+func (l *Latency) Add(sourceIndex int, sample uint32, arrival time.Time) time.Duration {
+	if l.clockRate == 0 || sourceIndex >= len(l.sources) {
+		return 0
+	}
+
+	// The difference wraps like the RTP timestamps
+	samples := int32(MediaClockSample(arrival, l.clockRate) - sample)
+	latency := time.Duration(samples) * time.Second / time.Duration(l.clockRate)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	s := &l.sources[sourceIndex]
+	s.samples[s.next] = latency
+	s.next = (s.next + 1) % latencySamples
+	s.count = min(s.count+1, latencySamples)
+
+	return latency
+}
+
+// percentile returns the value at the percentile p of sorted values
+func percentile(sorted []time.Duration, p int) time.Duration {
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// Stats returns the latency distribution of the source with the given index
+// with a histogram of the given number of bins
+func (l *Latency) Stats(sourceIndex, bins int) LatencyStats {
+	if sourceIndex >= len(l.sources) {
+		return LatencyStats{}
+	}
+
+	l.mutex.Lock()
+	s := &l.sources[sourceIndex]
+	sorted := slices.Clone(s.samples[:s.count])
+	l.mutex.Unlock()
+
+	if len(sorted) == 0 {
+		return LatencyStats{}
+	}
+
+	slices.Sort(sorted)
+
+	stats := LatencyStats{
+		Samples:   len(sorted),
+		Min:       sorted[0],
+		P50:       percentile(sorted, 50),
+		P90:       percentile(sorted, 90),
+		P99:       percentile(sorted, 99),
+		Max:       sorted[len(sorted)-1],
+		Histogram: make([]uint64, bins),
+	}
+
+	if bins == 0 {
+		return stats
+	}
+
+	width := (stats.P99 - stats.Min) / time.Duration(bins)
+
+	for _, latency := range sorted {
+		bin := 0
+		if width > 0 {
+			bin = min(int((latency-stats.Min)/width), bins-1)
+		}
+
+		stats.Histogram[bin]++
+	}
+
+	return stats
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMediaClockSample(t *testing.T) {
+	// 2^32 samples at 48 kHz wrap after 89478.4853 seconds
+	at := time.Unix(89478, 485333334)
+
+	if got := MediaClockSample(at, 48000); got != 0 {
+		t.Errorf("got %d, want the timestamp to wrap to 0", got)
+	}
+
+	if got := MediaClockSample(time.Unix(1, 500000000), 48000); got != 72000 {
+		t.Errorf("got %d, want 72000", got)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	l := NewLatency(2, 48000)
+	start := time.Unix(100000, 0)
+
+	for i := range 100 {
+		arrival := start.Add(time.Duration(i) * time.Millisecond)
+		sample := MediaClockSample(arrival, 48000)
+
+		// The packets leave 1 ms after their first sample, one in ten is
+		// delayed by another 2 ms
+		latency := uint32(48)
+		if i%10 == 0 {
+			latency += 96
+		}
+
+		l.Add(0, sample-latency, arrival)
+
+		// The second source runs 1 ms ahead of PTP
+		l.Add(1, sample+48, arrival)
+	}
+
+	stats := l.Stats(0, 4)
+	if stats.Samples != 100 || stats.Min != time.Millisecond || stats.P50 != time.Millisecond || stats.P99 != 3*time.Millisecond || stats.Max != 3*time.Millisecond {
+		t.Errorf("got %+v, want 100 samples from 1 to 3 ms", stats)
+	}
+
+	if stats.Histogram[0] != 90 || stats.Histogram[3] != 10 {
+		t.Errorf("histogram = %v, want 90 in the first bin and 10 in the last", stats.Histogram)
+	}
+
+	if got := l.Stats(1, 4).P50; got != -time.Millisecond {
+		t.Errorf("P50 of source 2 = %v, want -1ms", got)
+	}
+
+	if got := l.Stats(2, 4); got.Samples != 0 {
+		t.Errorf("got %+v for an unknown source", got)
+	}
+}
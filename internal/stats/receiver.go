@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// LinkOffsets are the link offsets common receivers can be set to, which the
+// virtual receiver counts late packets for besides its own
+var LinkOffsets = []time.Duration{
+	125 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+}
+
+// VirtualReceiverStats holds the packets of a source a virtual receiver
+// received and those that arrived too late to be played out
+type VirtualReceiverStats struct {
+	LinkOffset time.Duration
+	Packets    uint64
+	Late       uint64
+
+	// LongestRun is the largest number of consecutive late packets, which
+	// tells whether the receiver would drop out or just click
+	LongestRun uint64
+
+	// LateAt counts the late packets at each of LinkOffsets
+	LateAt []uint64
+
+	// MaxLatency is the largest latency of the packets received
+	MaxLatency time.Duration
+}
+
+// SafeLinkOffset returns the smallest of LinkOffsets no packet arrived too
+// late for, and false if there is none
+func (s VirtualReceiverStats) SafeLinkOffset() (time.Duration, bool) {
+	for i, late := range s.LateAt {
+		if late == 0 {
+			return LinkOffsets[i], true
+		}
+	}
+
+	return 0, false
+}
+
+type virtualSource struct {
+	packets    uint64
+	late       uint64
+	run        uint64
+	longestRun uint64
+	lateAt     []uint64
+	maxLatency time.Duration
+}
+
+// VirtualReceiver simulates a receiver that plays out each sample its link
+// offset after the PTP time of its RTP timestamp, as AES67 receivers do. A
+// packet whose latency exceeds the link offset arrives after its first
+// sample should have been played out, and is late.
+type VirtualReceiver struct {
+	mutex      sync.Mutex
+	linkOffset time.Duration
+	sources    []virtualSource
+}
+
+// NewVirtualReceiver creates a virtual receiver of the given number of
+// sources with the given link offset
+func NewVirtualReceiver(sources int, linkOffset time.Duration) *VirtualReceiver {
+	r := &VirtualReceiver{
+		linkOffset: linkOffset,
+		sources:    make([]virtualSource, sources),
+	}
+
+	for i := range r.sources {
+		r.sources[i].lateAt = make([]uint64, len(LinkOffsets))
+	}
+
+	return r
+}
+
+// Add accounts a packet of the source with the given index with its latency
+// as measured by Latency
+func (r *VirtualReceiver) Add(sourceIndex int, latency time.Duration) {
+	if sourceIndex >= len(r.sources) {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s := &r.sources[sourceIndex]
+
+	if s.packets == 0 || latency > s.maxLatency {
+		s.maxLatency = latency
+	}
+
+	s.packets++
+
+	for i, offset := range LinkOffsets {
+		if latency > offset {
+			s.lateAt[i]++
+		}
+	}
+
+	if latency <= r.linkOffset {
+		s.run = 0
+		return
+	}
+
+	s.late++
+	s.run++
+	s.longestRun = max(s.longestRun, s.run)
+}
+
+// Stats returns the packets of the source with the given index received so
+// far
+func (r *VirtualReceiver) Stats(sourceIndex int) VirtualReceiverStats {
+	if sourceIndex >= len(r.sources) {
+		return VirtualReceiverStats{LinkOffset: r.linkOffset}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s := r.sources[sourceIndex]
+
+	return VirtualReceiverStats{
+		LinkOffset: r.linkOffset,
+		Packets:    s.packets,
+		Late:       s.late,
+		LongestRun: s.longestRun,
+		LateAt:     append([]uint64(nil), s.lateAt...),
+		MaxLatency: s.maxLatency,
+	}
+}
@@ -0,0 +1,42 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVirtualReceiver(t *testing.T) {
+	r := NewVirtualReceiver(1, time.Millisecond)
+
+	latencies := []time.Duration{
+		800 * time.Microsecond,
+		1500 * time.Microsecond,
+		1200 * time.Microsecond,
+		900 * time.Microsecond,
+		3 * time.Millisecond,
+		time.Millisecond,
+	}
+
+	for _, latency := range latencies {
+		r.Add(0, latency)
+	}
+
+	stats := r.Stats(0)
+	if stats.Packets != 6 || stats.Late != 3 || stats.LongestRun != 2 || stats.MaxLatency != 3*time.Millisecond {
+		t.Errorf("got %+v, want 3 of 6 packets late, 2 in a row", stats)
+	}
+
+	for i, want := range []uint64{6, 6, 6, 3, 1, 0, 0, 0} {
+		if stats.LateAt[i] != want {
+			t.Errorf("late at %v = %d, want %d", LinkOffsets[i], stats.LateAt[i], want)
+		}
+	}
+
+	if offset, ok := stats.SafeLinkOffset(); !ok || offset != 4*time.Millisecond {
+		t.Errorf("SafeLinkOffset() = %v, %v, want 4ms", offset, ok)
+	}
+
+	if got := r.Stats(1); got.Packets != 0 || got.LinkOffset != time.Millisecond {
+		t.Errorf("got %+v for an unknown source", got)
+	}
+}
@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+const (
+	// maxSenders is the number of SSRCs per source kept. The one heard
+	// from longest ago is dropped for a new one.
+	maxSenders = 8
+
+	// byeGrace is how long after a BYE packets may still arrive without
+	// the source being taken as alive again, as senders send their last
+	// packets and the BYE at about the same time
+	byeGrace = 500 * time.Millisecond
+)
+
+// RTCPSender identifies the sender of a source by the SDES items of its RTCP
+// packets, and tells whether it left with a BYE
+type RTCPSender struct {
+	SSRC  uint32
+	CNAME string
+	Name  string
+	Tool  string
+
+	// Bye is when a BYE was received, zero if none was
+	Bye       time.Time
+	ByeReason string
+
+	lastHeard time.Time
+}
+
+// RTCPTracker follows the SDES and BYE packets of the sources of a stream
+type RTCPTracker struct {
+	mutex   sync.Mutex
+	senders []map[uint32]*RTCPSender
+}
+
+// NewRTCPTracker creates a tracker for the given number of sources
+func NewRTCPTracker(sources int) *RTCPTracker {
+	t := &RTCPTracker{
+		senders: make([]map[uint32]*RTCPSender, sources),
+	}
+
+	for i := range t.senders {
+		t.senders[i] = make(map[uint32]*RTCPSender)
+	}
+
+	return t
+}
+
+// sender returns the sender with the given SSRC, which is added if it is
+// not known yet. The caller must hold the mutex.
+func (t *RTCPTracker) sender(sourceIndex int, ssrc uint32, now time.Time) *RTCPSender {
+	senders := t.senders[sourceIndex]
+
+	s, ok := senders[ssrc]
+	if !ok {
+		if len(senders) == maxSenders {
+			var oldest *RTCPSender
+			for _, s := range senders {
+				if oldest == nil || s.lastHeard.Before(oldest.lastHeard) {
+					oldest = s
+				}
+			}
+
+			delete(senders, oldest.SSRC)
+		}
+
+		s = &RTCPSender{SSRC: ssrc}
+		senders[ssrc] = s
+	}
+
+	s.lastHeard = now
+
+	return s
+}
+
+// Add accounts an RTCP packet of the source with the given index
+func (t *RTCPTracker) Add(sourceIndex int, packet rtcp.Packet, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if sourceIndex >= len(t.senders) {
+		return
+	}
+
+	switch p := packet.(type) {
+	case *rtcp.SourceDescription:
+		for _, chunk := range p.Chunks {
+			s := t.sender(sourceIndex, chunk.Source, now)
+
+			for _, item := range chunk.Items {
+				switch item.Type {
+				case rtcp.SDESCNAME:
+					s.CNAME = item.Text
+				case rtcp.SDESName:
+					s.Name = item.Text
+				case rtcp.SDESTool:
+					s.Tool = item.Text
+				}
+			}
+		}
+
+	case *rtcp.Goodbye:
+		for _, ssrc := range p.Sources {
+			s := t.sender(sourceIndex, ssrc, now)
+			s.Bye = now
+			s.ByeReason = p.Reason
+		}
+
+	case *rtcp.SenderReport:
+		// A sender that reports again is back
+		s := t.sender(sourceIndex, p.SSRC, now)
+		s.Bye = time.Time{}
+		s.ByeReason = ""
+	}
+}
+
+// Senders returns the senders of the source with the given index, sorted by
+// SSRC
+func (t *RTCPTracker) Senders(sourceIndex int) []RTCPSender {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	senders := make([]RTCPSender, 0, len(t.senders[sourceIndex]))
+	for _, s := range t.senders[sourceIndex] {
+		senders = append(senders, *s)
+	}
+
+	slices.SortFunc(senders, func(a, b RTCPSender) int {
+		return cmp.Compare(a.SSRC, b.SSRC)
+	})
+
+	return senders
+}
+
+// Ended returns when the source with the given index ended, as the time of
+// the last BYE if all its senders said goodbye and no packets arrived
+// since. lastPacket is the arrival time of the last RTP packet.
+func (t *RTCPTracker) Ended(sourceIndex int, lastPacket time.Time) time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var ended time.Time
+
+	for _, s := range t.senders[sourceIndex] {
+		if s.Bye.IsZero() {
+			return time.Time{}
+		}
+
+		if s.Bye.After(ended) {
+			ended = s.Bye
+		}
+	}
+
+	if !ended.IsZero() && lastPacket.After(ended.Add(byeGrace)) {
+		return time.Time{}
+	}
+
+	return ended
+}
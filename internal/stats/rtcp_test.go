@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+func TestRTCPTrackerSDES(t *testing.T) {
+	tracker := NewRTCPTracker(1)
+	now := time.Unix(1000, 0)
+
+	tracker.Add(0, &rtcp.SourceDescription{Chunks: []rtcp.SourceDescriptionChunk{{
+		Source: 0x1234,
+		Items: []rtcp.SourceDescriptionItem{
+			{Type: rtcp.SDESCNAME, Text: "console@10.0.0.5"},
+			{Type: rtcp.SDESName, Text: "Stage Left"},
+			{Type: rtcp.SDESTool, Text: "Mixer 2.1"},
+			{Type: rtcp.SDESEmail, Text: "ignored@example.com"},
+		},
+	}}}, now)
+
+	// Out of range sources are ignored
+	tracker.Add(1, &rtcp.Goodbye{Sources: []uint32{1}}, now)
+
+	senders := tracker.Senders(0)
+	if len(senders) != 1 {
+		t.Fatalf("got %d senders, want 1", len(senders))
+	}
+
+	s := senders[0]
+	if s.SSRC != 0x1234 || s.CNAME != "console@10.0.0.5" || s.Name != "Stage Left" || s.Tool != "Mixer 2.1" {
+		t.Errorf("got sender %+v", s)
+	}
+}
+
+func TestRTCPTrackerBye(t *testing.T) {
+	tracker := NewRTCPTracker(1)
+	now := time.Unix(1000, 0)
+
+	tracker.Add(0, &rtcp.SenderReport{SSRC: 1}, now)
+	tracker.Add(0, &rtcp.SenderReport{SSRC: 2}, now)
+
+	if got := tracker.Ended(0, now); !got.IsZero() {
+		t.Errorf("Ended() = %v before any BYE, want zero", got)
+	}
+
+	tracker.Add(0, &rtcp.Goodbye{Sources: []uint32{1}, Reason: "shutdown"}, now.Add(time.Second))
+
+	if got := tracker.Ended(0, now); !got.IsZero() {
+		t.Errorf("Ended() = %v with one of two senders gone, want zero", got)
+	}
+
+	bye := now.Add(2 * time.Second)
+	tracker.Add(0, &rtcp.Goodbye{Sources: []uint32{2}}, bye)
+
+	if got := tracker.Ended(0, bye.Add(100*time.Millisecond)); !got.Equal(bye) {
+		t.Errorf("Ended() = %v, want %v", got, bye)
+	}
+
+	if s := tracker.Senders(0)[0]; s.ByeReason != "shutdown" {
+		t.Errorf("reason = %q, want shutdown", s.ByeReason)
+	}
+
+	// Packets after the BYE bring the source back
+	if got := tracker.Ended(0, bye.Add(time.Second)); !got.IsZero() {
+		t.Errorf("Ended() = %v with packets after the BYE, want zero", got)
+	}
+
+	// So does a sender report
+	tracker.Add(0, &rtcp.SenderReport{SSRC: 2}, bye.Add(time.Second))
+
+	if got := tracker.Ended(0, bye); !got.IsZero() {
+		t.Errorf("Ended() = %v after a sender report, want zero", got)
+	}
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+const (
+	// skewSlots is the number of recent packets per leg kept to match the
+	// packets of the other leg against. At 125 µs per packet it covers a
+	// skew of half a second.
+	skewSlots = 4096
+
+	// SkewWarning is the skew beyond which the legs no longer fit the
+	// 1 ms link offset AES67 receivers are typically set to
+	SkewWarning = time.Millisecond
+
+	// SkewError is the skew beyond which the legs no longer fit the 10 ms
+	// of ST 2022-7 low-skew receivers
+	SkewError = 10 * time.Millisecond
+)
+
+type skewPacket struct {
+	valid     bool
+	sequence  uint16
+	timestamp uint32
+	arrival   time.Time
+}
+
+// SkewStats holds the time skew between the two legs of a redundant stream.
+// Skews are positive if the second leg arrives later than the first.
+type SkewStats struct {
+	// Matched is the number of packets received on both legs
+	Matched uint64
+
+	// Current is the skew smoothed over the last matched packets
+	Current time.Duration
+	Min     time.Duration
+	Max     time.Duration
+}
+
+// Skew measures the time skew between the two legs of a redundant stream,
+// such as ST 2022-7, by matching the arrival of packets with the same
+// sequence number and RTP timestamp
+type Skew struct {
+	mutex sync.Mutex
+	legs  [2][skewSlots]skewPacket
+
+	matched uint64
+	current float64
+	min     time.Duration
+	max     time.Duration
+}
+
+// NewSkew creates a skew measurement of a redundant stream
+func NewSkew() *Skew {
+	return &Skew{}
+}
+
+// Add accounts a packet of the source with the given index. Packets of
+// sources other than the first two are ignored.
+func (s *Skew) Add(sourceIndex int, packet *rtp.Packet, arrival time.Time) {
+	if sourceIndex > 1 {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	slot := int(packet.SequenceNumber) % skewSlots
+
+	other := &s.legs[1-sourceIndex][slot]
+	if other.valid && other.sequence == packet.SequenceNumber && other.timestamp == packet.Timestamp {
+		// Each packet is matched once, duplicates on the same leg are not
+		// taken for the other one
+		other.valid = false
+		s.legs[sourceIndex][slot] = skewPacket{}
+
+		skew := arrival.Sub(other.arrival)
+		if sourceIndex == 0 {
+			skew = -skew
+		}
+
+		s.add(skew)
+
+		return
+	}
+
+	s.legs[sourceIndex][slot] = skewPacket{
+		valid:     true,
+		sequence:  packet.SequenceNumber,
+		timestamp: packet.Timestamp,
+		arrival:   arrival,
+	}
+}
+
+// add accounts a measured skew. The caller must hold the mutex.
+func (s *Skew) add(skew time.Duration) {
+	if s.matched == 0 {
+		s.current = float64(skew)
+		s.min = skew
+		s.max = skew
+	} else {
+		// Smoothed like the interarrival jitter of RFC 3550
+		s.current += (float64(skew) - s.current) / 16
+		s.min = min(s.min, skew)
+		s.max = max(s.max, skew)
+	}
+
+	s.matched++
+}
+
+// Stats returns the skew measured so far
+func (s *Skew) Stats() SkewStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return SkewStats{
+		Matched: s.matched,
+		Current: time.Duration(s.current),
+		Min:     s.min,
+		Max:     s.max,
+	}
+}
@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+func TestSkew(t *testing.T) {
+	s := NewSkew()
+	start := time.Unix(1000, 0)
+
+	for i := range 100 {
+		packet := &rtp.Packet{Header: rtp.Header{
+			SequenceNumber: uint16(65500 + i),
+			Timestamp:      uint32(i * 48),
+		}}
+		sent := start.Add(time.Duration(i) * time.Millisecond)
+
+		// The second leg is 3 ms late
+		s.Add(0, packet, sent)
+		s.Add(1, packet, sent.Add(3*time.Millisecond))
+
+		// Duplicates are not matched twice
+		s.Add(1, packet, sent.Add(4*time.Millisecond))
+	}
+
+	stats := s.Stats()
+	if stats.Matched != 100 || stats.Current != 3*time.Millisecond || stats.Min != 3*time.Millisecond || stats.Max != 3*time.Millisecond {
+		t.Errorf("got %+v, want 100 matched with a skew of 3ms", stats)
+	}
+
+	// The first leg being late gives a negative skew
+	late := start.Add(time.Second)
+	packet := &rtp.Packet{Header: rtp.Header{SequenceNumber: 200, Timestamp: 9600}}
+	s.Add(1, packet, late)
+	s.Add(0, packet, late.Add(2*time.Millisecond))
+
+	if got := s.Stats().Min; got != -2*time.Millisecond {
+		t.Errorf("Min = %v, want -2ms", got)
+	}
+
+	// Packets with the same sequence number but another timestamp are of
+	// another sender and don't match
+	s.Add(0, &rtp.Packet{Header: rtp.Header{SequenceNumber: 300, Timestamp: 1}}, late)
+	s.Add(1, &rtp.Packet{Header: rtp.Header{SequenceNumber: 300, Timestamp: 2}}, late)
+
+	if got := s.Stats().Matched; got != 101 {
+		t.Errorf("Matched = %d, want 101", got)
+	}
+}
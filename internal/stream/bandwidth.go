@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"net"
+	"sort"
+	"time"
+)
+
+// bandwidthWindow is the shortest time the bandwidth of a join is averaged
+// over, so frequent callers don't see the jitter of single packets
+const bandwidthWindow = time.Second
+
+// wireOverhead returns the bytes a packet sent to addr takes on an Ethernet
+// link besides its UDP payload: the UDP and IP headers, the Ethernet header
+// and frame check sequence, and the preamble and inter-frame gap. They are
+// a good part of the bandwidth of the small packets of AES67 streams.
+func wireOverhead(addr *net.UDPAddr) uint64 {
+	const (
+		udp      = 8
+		ethernet = 14 + 4
+		gap      = 8 + 12
+	)
+
+	ip := 20
+	if addr.IP.To4() == nil {
+		ip = 40
+	}
+
+	return uint64(udp + ip + ethernet + gap)
+}
+
+// joinKey identifies a group joined on an interface, which is received
+// once no matter how many receivers joined it
+type joinKey struct {
+	ifi    string
+	group  string
+	source string
+}
+
+type bandwidthSample struct {
+	bytes uint64
+	at    time.Time
+
+	// rate is the bandwidth in bits per second since the previous sample
+	rate float64
+}
+
+// InterfaceBandwidth is the multicast traffic of the groups joined on an
+// interface
+type InterfaceBandwidth struct {
+	Interface string
+	Groups    int
+
+	// BitsPerSecond includes the headers of the packets on the link
+	BitsPerSecond float64
+}
+
+// InterfaceBandwidth returns the bandwidth of the groups joined by stream
+// receivers on each interface, sorted by interface. Groups joined by
+// several receivers are counted once. The bandwidth of a group is averaged
+// over at least bandwidthWindow and is 0 until it was joined that long.
+func (m *Manager) InterfaceBandwidth(now time.Time) []InterfaceBandwidth {
+	m.mutex.Lock()
+
+	bytes := make(map[joinKey]uint64)
+
+	for sub := range m.subscriptions {
+		for _, ifi := range sub.ifis {
+			key := joinKey{ifi: ifi.Name, group: sub.addr.String(), source: sub.source.String()}
+			bytes[key] = max(bytes[key], sub.counters[ifi.Index].wireBytes.Load())
+		}
+	}
+
+	m.mutex.Unlock()
+
+	m.bandwidthMutex.Lock()
+	defer m.bandwidthMutex.Unlock()
+
+	samples := make(map[joinKey]bandwidthSample, len(bytes))
+	interfaces := make(map[string]*InterfaceBandwidth)
+
+	for key, n := range bytes {
+		sample, ok := m.bandwidth[key]
+
+		switch {
+		case !ok || n < sample.bytes:
+			// New, or joined again by another receiver
+			sample = bandwidthSample{bytes: n, at: now}
+		case now.Sub(sample.at) >= bandwidthWindow:
+			rate := float64(n-sample.bytes) * 8 / now.Sub(sample.at).Seconds()
+			sample = bandwidthSample{bytes: n, at: now, rate: rate}
+		}
+
+		samples[key] = sample
+
+		b, ok := interfaces[key.ifi]
+		if !ok {
+			b = &InterfaceBandwidth{Interface: key.ifi}
+			interfaces[key.ifi] = b
+		}
+
+		b.Groups++
+		b.BitsPerSecond += sample.rate
+	}
+
+	// Groups left are forgotten
+	m.bandwidth = samples
+
+	result := make([]InterfaceBandwidth, 0, len(interfaces))
+	for _, b := range interfaces {
+		result = append(result, *b)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Interface < result[b].Interface
+	})
+
+	return result
+}
@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestInterfaceBandwidth(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	eth0 := &net.Interface{Index: 1, Name: "eth0"}
+	eth1 := &net.Interface{Index: 2, Name: "eth1"}
+
+	join := func(group string, ifis ...*net.Interface) *subscription {
+		sub := &subscription{
+			addr:     &net.UDPAddr{IP: net.ParseIP(group), Port: 5004},
+			ifis:     ifis,
+			counters: make(map[int]*joinCounter),
+		}
+
+		for _, ifi := range ifis {
+			sub.counters[ifi.Index] = &joinCounter{}
+		}
+
+		return sub
+	}
+
+	a := join("239.1.1.1", eth0, eth1)
+	b := join("239.1.1.2", eth0)
+
+	// A second receiver of the same group
+	c := join("239.1.1.1", eth0)
+
+	m.mutex.Lock()
+	for _, sub := range []*subscription{a, b, c} {
+		m.subscriptions[sub] = struct{}{}
+	}
+	m.mutex.Unlock()
+
+	now := time.Unix(1000, 0)
+
+	if got := m.InterfaceBandwidth(now); len(got) != 2 || got[0].Groups != 2 || got[0].BitsPerSecond != 0 {
+		t.Fatalf("got %+v, want two interfaces without bandwidth yet", got)
+	}
+
+	a.counters[1].wireBytes.Add(125000)
+	a.counters[2].wireBytes.Add(250000)
+	b.counters[1].wireBytes.Add(125000)
+	c.counters[1].wireBytes.Add(125000)
+
+	// Too early for a new sample
+	if got := m.InterfaceBandwidth(now.Add(time.Second / 2)); got[0].BitsPerSecond != 0 {
+		t.Errorf("got %v bit/s within the window, want 0", got[0].BitsPerSecond)
+	}
+
+	got := m.InterfaceBandwidth(now.Add(time.Second))
+	if len(got) != 2 || got[0].Interface != "eth0" || got[1].Interface != "eth1" {
+		t.Fatalf("got %+v, want eth0 and eth1", got)
+	}
+
+	if math.Abs(got[0].BitsPerSecond-2e6) > 1 {
+		t.Errorf("eth0: got %v bit/s, want 2 Mbit/s", got[0].BitsPerSecond)
+	}
+
+	if got[1].Groups != 1 || math.Abs(got[1].BitsPerSecond-2e6) > 1 {
+		t.Errorf("eth1: got %+v, want one group at 2 Mbit/s", got[1])
+	}
+
+	m.mutex.Lock()
+	for _, sub := range []*subscription{a, b, c} {
+		delete(m.subscriptions, sub)
+	}
+	m.mutex.Unlock()
+
+	if got := m.InterfaceBandwidth(now.Add(2 * time.Second)); len(got) != 0 {
+		t.Errorf("got %+v after leaving, want none", got)
+	}
+}
+
+func TestWireOverhead(t *testing.T) {
+	if n := wireOverhead(&net.UDPAddr{IP: net.ParseIP("239.1.1.1")}); n != 66 {
+		t.Errorf("IPv4: got %d, want 66", n)
+	}
+
+	if n := wireOverhead(&net.UDPAddr{IP: net.ParseIP("ff15::1")}); n != 86 {
+		t.Errorf("IPv6: got %d, want 86", n)
+	}
+}
@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// contentTypeOf returns the content type of an rtpmap encoding name, which
+// is case-insensitive
+func contentTypeOf(encoding string) ContentType {
+	switch strings.ToUpper(encoding) {
+	case "L16":
+		return ContentTypePCM16
+	case "L24":
+		return ContentTypePCM24
+	case "OPUS":
+		return ContentTypeOpus
+	case "MPEG4-GENERIC", "MP4A-LATM":
+		return ContentTypeAAC
+	default:
+		return ContentTypeUndefined
+	}
+}
+
+// SamplesError returns why the samples of a stream can't be decoded for
+// meters, levels and recordings, nil if they can. Only linear PCM is
+// decoded; the packets of other streams are still counted.
+func (d *StreamDescription) SamplesError() error {
+	switch d.ContentType {
+	case ContentTypePCM16, ContentTypePCM24:
+		return nil
+	case ContentTypeOpus, ContentTypeAAC:
+		return fmt.Errorf("%w: %s is compressed, only linear PCM (L16, L24) is decoded", ErrUnsupportedContentType, d.ContentType)
+	case "":
+		return fmt.Errorf("%w: the SDP declares no rtpmap", ErrUnsupportedContentType)
+	}
+
+	return fmt.Errorf("%w: %s is not linear PCM (L16, L24)", ErrUnsupportedContentType, d.Encoding)
+}
+
+// FormatParameters returns the parameters of the a=fmtp of the payload
+// type, e.g. "maxaveragebitrate" of Opus, by name in lower case
+func (d *StreamDescription) FormatParameters() map[string]string {
+	parameters := make(map[string]string)
+
+	for _, p := range strings.Split(d.Format, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+		if name != "" {
+			parameters[strings.ToLower(name)] = value
+		}
+	}
+
+	return parameters
+}
+
+// aacObjectTypes names the MPEG-4 audio object types of AAC streams
+var aacObjectTypes = map[string]string{
+	"2":  "AAC LC",
+	"5":  "HE-AAC",
+	"23": "AAC LD",
+	"29": "HE-AAC v2",
+	"39": "AAC ELD",
+}
+
+// CodecDetails describes the parameters of compressed codecs declared in
+// a=fmtp, empty for linear PCM and codecs without any
+func (d *StreamDescription) CodecDetails() string {
+	p := d.FormatParameters()
+
+	var details []string
+
+	add := func(format string, args ...any) {
+		details = append(details, fmt.Sprintf(format, args...))
+	}
+
+	switch d.ContentType {
+	case ContentTypeOpus:
+		if p["stereo"] == "1" {
+			add("stereo")
+		} else {
+			add("mono")
+		}
+
+		if bitrate, err := strconv.Atoi(p["maxaveragebitrate"]); err == nil {
+			add("up to %d kbit/s", bitrate/1000)
+		}
+
+		if p["cbr"] == "1" {
+			add("constant bitrate")
+		}
+
+		if p["useinbandfec"] == "1" {
+			add("in-band FEC")
+		}
+
+		if p["usedtx"] == "1" {
+			add("DTX")
+		}
+
+	case ContentTypeAAC:
+		add("%s", strings.ToUpper(d.Encoding))
+
+		if mode := p["mode"]; mode != "" {
+			add("mode %s", mode)
+		}
+
+		if object, ok := aacObjectTypes[p["object"]]; ok {
+			add("%s", object)
+		}
+
+		if bitrate, err := strconv.Atoi(p["bitrate"]); err == nil {
+			add("%d kbit/s", bitrate/1000)
+		}
+
+		if level := p["profile-level-id"]; level != "" {
+			add("profile-level %s", level)
+		}
+
+	default:
+		return ""
+	}
+
+	return strings.Join(details, ", ")
+}
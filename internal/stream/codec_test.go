@@ -0,0 +1,57 @@
+package stream
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const opusSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Commentary
+c=IN IP4 239.1.1.2/32
+t=0 0
+m=audio 5004 RTP/AVP 111
+a=rtpmap:111 opus/48000/2
+a=fmtp:111 stereo=1; maxaveragebitrate=128000; useinbandfec=1
+a=ptime:20
+`
+
+func TestParseSDPCompressed(t *testing.T) {
+	desc, _, err := ParseSDP([]byte(opusSDP))
+	if err != nil {
+		t.Fatalf("ParseSDP() failed: %v", err)
+	}
+
+	s := &Stream{Description: *desc}
+
+	if desc.ContentType != ContentTypeOpus || s.CodecInfo() != "Opus 48000Hz 2ch" {
+		t.Errorf("got %q, %q; want Opus", desc.ContentType, s.CodecInfo())
+	}
+
+	if got := desc.CodecDetails(); got != "stereo, up to 128 kbit/s, in-band FEC" {
+		t.Errorf("CodecDetails() = %q", got)
+	}
+
+	if err := desc.SamplesError(); !errors.Is(err, ErrUnsupportedContentType) || !strings.Contains(err.Error(), "Opus is compressed") {
+		t.Errorf("SamplesError() = %v", err)
+	}
+
+	// The number of channels may be omitted, and unknown encodings are
+	// named as declared
+	sdp := strings.Replace(opusSDP, "opus/48000/2", "MP4A-LATM/90000", 1)
+	sdp = strings.Replace(sdp, "a=fmtp:111 stereo=1; maxaveragebitrate=128000; useinbandfec=1", "a=fmtp:111 object=2; bitrate=64000", 1)
+
+	if desc, _, _ = ParseSDP([]byte(sdp)); desc.ContentType != ContentTypeAAC || desc.ChannelCount != 1 || desc.CodecDetails() != "MP4A-LATM, AAC LC, 64 kbit/s" {
+		t.Errorf("got %q, %d channels, %q; want AAC LC with one channel", desc.ContentType, desc.ChannelCount, desc.CodecDetails())
+	}
+
+	desc, _, _ = ParseSDP([]byte(strings.Replace(opusSDP, "opus/48000/2", "G722/8000", 1)))
+	if s := (&Stream{Description: *desc}); s.CodecInfo() != "G722 8000Hz 1ch" || desc.SamplesError() == nil {
+		t.Errorf("got %q, %v; want G722 without samples", s.CodecInfo(), desc.SamplesError())
+	}
+
+	if desc, _, _ := ParseSDP([]byte(validSDP)); desc.SamplesError() != nil || desc.CodecDetails() != "" {
+		t.Errorf("got %v, %q for linear PCM", desc.SamplesError(), desc.CodecDetails())
+	}
+}
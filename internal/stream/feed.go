@@ -0,0 +1,244 @@
+package stream
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+// packetPool holds the packets parsed by feeds, which are only used while
+// they are passed to the subscribers
+var packetPool = sync.Pool{
+	New: func() any {
+		return &rtp.Packet{}
+	},
+}
+
+// feedSubscriber is called with each packet of a feed and the interface it
+// was received on. The packet is shared by all subscribers and must not be
+// modified or retained after the call; it is nil if the payload is not a
+// valid RTP packet.
+type feedSubscriber struct {
+	fn RTPInterfaceCallback
+
+	// mutex is read locked while a packet is delivered, so closing the
+	// subscriber waits for it. No packets are delivered once closed is set.
+	mutex  sync.RWMutex
+	closed bool
+}
+
+// deliver calls the subscriber with a packet unless it is closed
+func (sub *feedSubscriber) deliver(i int, ifi *net.Interface, src net.Addr, packet *rtp.Packet, arrival time.Time) {
+	sub.mutex.RLock()
+	defer sub.mutex.RUnlock()
+
+	if !sub.closed {
+		sub.fn(i, ifi, src, packet, arrival)
+	}
+}
+
+// close waits for the packet being delivered, if any, and stops the delivery
+// of further packets
+func (sub *feedSubscriber) close() {
+	sub.mutex.Lock()
+	sub.closed = true
+	sub.mutex.Unlock()
+}
+
+// rtpFeed is the shared receiver of a stream. It joins the multicast groups
+// of all sources once, queues the packets for the worker pool, parses each
+// packet once and fans it out to the RTP receivers opened on the stream.
+type rtpFeed struct {
+	key           string
+	streamID      string
+	subscriptions []*subscription
+	sourceErrors  map[int]error
+
+	// rtsp is the session unicast streams are received with instead of
+	// subscriptions
+	rtsp *rtspSession
+
+	pool      *workerPool
+	queue     chan queuedPacket
+	scheduled atomic.Bool
+
+	// drops counts the packets dropped as the queue was full
+	drops atomic.Uint64
+
+	mutex sync.Mutex
+
+	// subscribers is replaced rather than modified, so packets are
+	// delivered without holding the mutex
+	subscribers []*feedSubscriber
+
+	// ssrcs holds when each SSRC was last seen from a sender, to detect
+	// collisions
+	ssrcs map[ssrcUse]time.Time
+}
+
+// feedKey identifies the feed of a stream. Receivers opened after the SDP
+// or the interface of a stream changed get a new feed, as they join other
+// groups.
+func feedKey(s *Stream, ifis []*net.Interface) string {
+	var b strings.Builder
+
+	b.WriteString(s.ID)
+
+	for _, ifi := range ifis {
+		fmt.Fprintf(&b, " %s", ifi.Name)
+	}
+
+	for _, source := range s.Description.Sources {
+		fmt.Fprintf(&b, " %s:%d/%s/%v", source.DestinationAddress, source.DestinationPort, source.joinSource(), source.RTCPMux)
+	}
+
+	return b.String()
+}
+
+// receive parses a packet of source i and passes it to all subscribers.
+// The payload of the packet refers to the queued buffer, it is not copied.
+func (f *rtpFeed) receive(i int, ifi *net.Interface, src net.Addr, payload []byte, arrival time.Time) {
+	pooled := packetPool.Get().(*rtp.Packet)
+
+	packet := pooled
+	if err := packet.Unmarshal(payload); err != nil {
+		packet = nil
+	}
+
+	f.mutex.Lock()
+	subscribers := f.subscribers
+	if packet != nil {
+		f.addSSRC(i, packet.SSRC, src, arrival)
+	}
+	f.mutex.Unlock()
+
+	for _, sub := range subscribers {
+		sub.deliver(i, ifi, src, packet, arrival)
+	}
+
+	// Do not keep the received buffer alive through the pool
+	pooled.Payload = nil
+	packetPool.Put(pooled)
+}
+
+// playRTSP receives the sources of a unicast stream with an RTSP session
+func (f *rtpFeed) playRTSP(s *Stream) error {
+	sources := len(s.Description.Sources)
+
+	session, err := newRTSPSession(s.RTSPURL(), func(i int, src net.Addr, payload []byte, arrival time.Time) {
+		if i >= 0 && i < sources {
+			f.enqueue(i, nil, false, src, payload, arrival)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to play RTSP session %s: %w", s.RTSPURL(), err)
+	}
+
+	f.rtsp = session
+
+	return nil
+}
+
+// subscribeFeed adds a subscriber to the feed of a stream, opening the feed
+// if the stream has none yet
+func (m *Manager) subscribeFeed(s *Stream, sub *feedSubscriber) (*rtpFeed, error) {
+	m.feedMutex.Lock()
+	defer m.feedMutex.Unlock()
+
+	ifis := m.receiverInterfaces(s)
+	key := feedKey(s, ifis)
+
+	f, ok := m.feeds[key]
+	if !ok {
+		f = &rtpFeed{
+			key:          key,
+			streamID:     s.ID,
+			sourceErrors: make(map[int]error),
+			pool:         m.workers,
+			queue:        make(chan queuedPacket, feedQueueSize),
+		}
+
+		if s.UnicastRTSP() {
+			if err := f.playRTSP(s); err != nil {
+				return nil, err
+			}
+		} else {
+			for i, source := range s.Description.Sources {
+				addr := net.UDPAddr{
+					IP:   source.DestinationAddress,
+					Port: int(source.DestinationPort),
+				}
+
+				c, err := m.subscribe(&addr, source.joinSource(), ifis, func(ifi *net.Interface, src net.Addr, payload []byte, arrival time.Time) {
+					f.enqueue(i, ifi, source.RTCPMux, src, payload, arrival)
+				})
+				if err == nil {
+					f.subscriptions = append(f.subscriptions, c)
+				} else {
+					f.sourceErrors[i] = err
+				}
+			}
+
+			if err := receiverError(len(f.subscriptions), f.sourceErrors); err != nil {
+				return nil, err
+			}
+		}
+
+		m.feeds[key] = f
+	}
+
+	f.mutex.Lock()
+	f.subscribers = append(slices.Clip(f.subscribers), sub)
+	f.mutex.Unlock()
+
+	return f, nil
+}
+
+// unsubscribeFeed removes a subscriber from a feed and closes the feed when
+// its last subscriber is gone. Once it returns, the subscriber is not called
+// anymore, so its owner can release what the callback uses.
+func (m *Manager) unsubscribeFeed(f *rtpFeed, sub *feedSubscriber) {
+	sub.close()
+
+	m.feedMutex.Lock()
+	defer m.feedMutex.Unlock()
+
+	f.mutex.Lock()
+	i := slices.Index(f.subscribers, sub)
+	if i >= 0 {
+		f.subscribers = slices.Delete(slices.Clone(f.subscribers), i, i+1)
+	}
+	remaining := len(f.subscribers)
+	f.mutex.Unlock()
+
+	if i < 0 || remaining > 0 {
+		return
+	}
+
+	if m.feeds[f.key] == f {
+		delete(m.feeds, f.key)
+	}
+
+	for _, c := range f.subscriptions {
+		m.unsubscribe(c)
+	}
+
+	if f.rtsp != nil {
+		f.rtsp.Close()
+	}
+}
+
+// FeedCount returns the number of streams received for RTP receivers, each
+// shared by all receivers of its stream
+func (m *Manager) FeedCount() int {
+	m.feedMutex.Lock()
+	defer m.feedMutex.Unlock()
+
+	return len(m.feeds)
+}
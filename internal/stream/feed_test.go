@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+func TestFeedFanOut(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	s, err := m.AddStreamFromSDP([]byte(validSDP), DiscoveryMethodManual, "test")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	var got [2]*rtp.Packet
+
+	first, err := s.NewRTPReceiver(func(_ int, _ net.Addr, p *rtp.Packet, _ time.Time) { got[0] = p })
+	if err != nil {
+		t.Fatalf("NewRTPReceiver() failed: %v", err)
+	}
+
+	second, err := s.NewRTPReceiver(func(_ int, _ net.Addr, p *rtp.Packet, _ time.Time) { got[1] = p })
+	if err != nil {
+		t.Fatalf("NewRTPReceiver() failed: %v", err)
+	}
+
+	if first.feed != second.feed || m.FeedCount() != 1 || len(m.subscriptions) != 1 {
+		t.Fatalf("receivers of one stream do not share a feed")
+	}
+
+	payload, _ := (&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 98, SequenceNumber: 7}}).Marshal()
+	first.feed.receive(0, nil, nil, payload, time.Now())
+
+	if got[0] == nil || got[0] != got[1] {
+		t.Errorf("packet was not parsed once for both receivers: %p, %p", got[0], got[1])
+	}
+
+	first.feed.receive(0, nil, nil, []byte{0}, time.Now())
+
+	for _, r := range []*RTPReceiver{first, second} {
+		if r.PacketCount(0) != 1 || r.RTPErrors(0) != 1 {
+			t.Errorf("receiver counted %d packets and %d errors, want 1 each", r.PacketCount(0), r.RTPErrors(0))
+		}
+	}
+
+	first.Close()
+
+	if m.FeedCount() != 1 {
+		t.Error("feed closed while a receiver is still open")
+	}
+
+	second.Close()
+
+	if m.FeedCount() != 0 || len(m.subscriptions) != 0 {
+		t.Error("feed still open after closing all receivers")
+	}
+}
+
+func TestFeedCloseWaitsForDelivery(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	s, err := m.AddStreamFromSDP([]byte(validSDP), DiscoveryMethodManual, "test")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	var delivered atomic.Int32
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	r, err := s.NewRTPReceiver(func(_ int, _ net.Addr, _ *rtp.Packet, _ time.Time) {
+		if delivered.Add(1) == 1 {
+			close(entered)
+			<-release
+		}
+	})
+	if err != nil {
+		t.Fatalf("NewRTPReceiver() failed: %v", err)
+	}
+
+	payload, _ := (&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 98}}).Marshal()
+
+	go r.feed.receive(0, nil, nil, payload, time.Now())
+	<-entered
+
+	closed := make(chan struct{})
+
+	go func() {
+		r.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close() returned while a packet was being delivered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-closed
+
+	r.feed.receive(0, nil, nil, payload, time.Now())
+
+	if n := delivered.Load(); n != 1 {
+		t.Errorf("callback called %d times, want 1", n)
+	}
+}
+
+func BenchmarkFeedReceive(b *testing.B) {
+	m := NewManager(b.Context(), nil)
+	defer m.Close()
+
+	s, err := m.AddStreamFromSDP([]byte(validSDP), DiscoveryMethodManual, "test")
+	if err != nil {
+		b.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	var samples []Sample
+
+	var r *RTPReceiver
+
+	r, err = s.NewRTPReceiver(func(_ int, _ net.Addr, p *rtp.Packet, _ time.Time) {
+		samples, _ = r.DecodeSamples(samples, p)
+	})
+	if err != nil {
+		b.Fatalf("NewRTPReceiver() failed: %v", err)
+	}
+	defer r.Close()
+
+	payload, _ := (&rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: 98},
+		Payload: make([]byte, 48*2*3),
+	}).Marshal()
+
+	now := time.Now()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		r.feed.receive(0, nil, nil, payload, now)
+	}
+}
@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+// readBatchSize is the number of packets read with one system call. At
+// 1000 packets per second, a batch collects the packets of a few
+// milliseconds at most when the receiver falls behind.
+const readBatchSize = 16
+
+// minPacketSize is the smallest receive buffer, used on interfaces that
+// report a lower MTU. Larger buffers are sized from the MTU, so jumbo
+// frames carrying high sample rates or many channels are received whole.
+const minPacketSize = 1500
+
+// readRetryDelay is the longest time waited before reading again after
+// reading failed
+const readRetryDelay = 100 * time.Millisecond
+
+// packetCallback is called with each packet received on an interface and
+// the time it arrived, taken by the kernel where supported
+type packetCallback func(ifi *net.Interface, src net.Addr, payload []byte, arrival time.Time)
+
+// packetSize returns the largest packet received on an interface. The
+// buffers hold one more byte, so larger packets, which are truncated when
+// read, can be detected.
+func packetSize(ifi *net.Interface) int {
+	return max(ifi.MTU, minPacketSize)
+}
+
+// groupConsumer receives the packets sent to a multicast group, joined
+// source-specific with IGMPv3 if a source is given or any-source otherwise,
+// on every multicast interface. Packets are read in batches, with recvmmsg
+// on Linux.
+type groupConsumer struct {
+	addr   *net.UDPAddr
+	source net.IP
+	cb     packetCallback
+	conns  []*ipv4.PacketConn
+	wg     sync.WaitGroup
+
+	// oversized is called for each packet dropped as it is larger than
+	// the receive buffer of the interface, if set
+	oversized func(ifi *net.Interface)
+}
+
+func newGroupConsumer(addr *net.UDPAddr, source net.IP, ifis []*net.Interface, cb packetCallback, oversized func(ifi *net.Interface)) (*groupConsumer, error) {
+	if !addr.IP.IsMulticast() {
+		return nil, fmt.Errorf("address %s is not a multicast address", addr)
+	}
+
+	c := &groupConsumer{
+		addr:      addr,
+		source:    source,
+		cb:        cb,
+		oversized: oversized,
+	}
+
+	for _, ifi := range ifis {
+		if ifi.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+
+		lc := net.ListenConfig{
+			Control: func(_, _ string, rc syscall.RawConn) error {
+				return setMulticastSocketOptions(rc, ifi)
+			},
+		}
+
+		conn, err := lc.ListenPacket(context.Background(), "udp4", addr.String())
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to open multicast socket on interface %s: %w", ifi.Name, err)
+		}
+
+		pc := ipv4.NewPacketConn(conn)
+
+		if source != nil {
+			err = pc.JoinSourceSpecificGroup(ifi, &net.UDPAddr{IP: addr.IP}, &net.UDPAddr{IP: source})
+		} else {
+			err = pc.JoinGroup(ifi, &net.UDPAddr{IP: addr.IP})
+		}
+
+		if err != nil {
+			pc.Close()
+			c.Close()
+			return nil, fmt.Errorf("failed to join group %s on interface %s: %w", addr.IP, ifi.Name, err)
+		}
+
+		c.conns = append(c.conns, pc)
+
+		c.wg.Add(1)
+		go c.readLoop(pc, ifi)
+	}
+
+	return c, nil
+}
+
+func (c *groupConsumer) readLoop(pc *ipv4.PacketConn, ifi *net.Interface) {
+	defer c.wg.Done()
+
+	size := packetSize(ifi)
+	messages := make([]ipv4.Message, readBatchSize)
+
+	for i := range messages {
+		messages[i].Buffers = [][]byte{make([]byte, size+1)}
+		messages[i].OOB = make([]byte, timestampOOBSize)
+	}
+
+	// Reading fails repeatedly e.g. while an interface is down, so the
+	// delay before reading again grows with each failure, and only the
+	// first one is logged
+	failures := 0
+	truncated := false
+
+	for {
+		n, err := pc.ReadBatch(messages, 0)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			if failures == 0 {
+				slog.Warn("Reading multicast group failed", "group", c.addr, "interface", ifi.Name, "error", err)
+			}
+
+			failures++
+			time.Sleep(min(time.Millisecond<<min(failures, 7), readRetryDelay))
+
+			continue
+		}
+
+		if failures > 0 {
+			slog.Info("Reading multicast group recovered", "group", c.addr, "interface", ifi.Name, "failures", failures)
+			failures = 0
+		}
+
+		// The buffers are passed on without a copy, as receivers are done
+		// with a packet when the callback returns
+		for _, m := range messages[:n] {
+			if m.N > size {
+				if !truncated {
+					slog.Warn("Dropping packets larger than the receive buffer", "group", c.addr, "interface", ifi.Name, "size", size)
+					truncated = true
+				}
+
+				if c.oversized != nil {
+					c.oversized(ifi)
+				}
+
+				continue
+			}
+
+			c.cb(ifi, m.Addr, m.Buffers[0][:m.N], arrivalTime(m.OOB[:m.NN]))
+		}
+	}
+}
+
+// Close leaves the group and waits for the receiving goroutines to return
+func (c *groupConsumer) Close() {
+	for _, pc := range c.conns {
+		_ = pc.Close()
+	}
+
+	c.wg.Wait()
+	c.conns = nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+
+package stream
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// timestampOOBSize is zero, as there are no kernel receive timestamps on
+// this platform
+const timestampOOBSize = 0
+
+// setMulticastSocketOptions is not implemented on this platform, so groups
+// are joined with the multicast library and any-source only
+func setMulticastSocketOptions(rc syscall.RawConn, ifi *net.Interface) error {
+	return errors.New("source-specific multicast is not supported on this platform")
+}
+
+// arrivalTime returns the current time
+func arrivalTime(_ []byte) time.Time {
+	return time.Now()
+}
@@ -0,0 +1,59 @@
+//go:build linux
+
+package stream
+
+import (
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// timestampOOBSize is the size of the control message holding the kernel
+// receive timestamp of a packet
+var timestampOOBSize = syscall.CmsgSpace(int(unsafe.Sizeof(syscall.Timespec{})))
+
+// setMulticastSocketOptions allows several sockets to bind to a group, binds
+// the socket to the interface, so it receives the packets of that interface
+// only, and enables kernel receive timestamps
+func setMulticastSocketOptions(rc syscall.RawConn, ifi *net.Interface) error {
+	var sockErr error
+
+	err := rc.Control(func(fd uintptr) {
+		if sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); sockErr != nil {
+			return
+		}
+
+		if sockErr = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, ifi.Name); sockErr != nil {
+			return
+		}
+
+		// Without timestamps, the arrival is taken when the packet is read
+		_ = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_TIMESTAMPNS, 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sockErr
+}
+
+// arrivalTime returns the kernel receive timestamp in the control messages
+// of a packet, or the current time if there is none
+func arrivalTime(oob []byte) time.Time {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return time.Now()
+	}
+
+	for _, m := range messages {
+		if m.Header.Level == syscall.SOL_SOCKET && m.Header.Type == syscall.SCM_TIMESTAMPNS &&
+			len(m.Data) >= int(unsafe.Sizeof(syscall.Timespec{})) {
+			ts := (*syscall.Timespec)(unsafe.Pointer(&m.Data[0]))
+
+			return time.Unix(ts.Unix())
+		}
+	}
+
+	return time.Now()
+}
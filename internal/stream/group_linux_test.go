@@ -0,0 +1,33 @@
+//go:build linux
+
+package stream
+
+import (
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestArrivalTime(t *testing.T) {
+	want := time.Unix(1700000000, 123456789)
+
+	oob := make([]byte, timestampOOBSize)
+
+	h := (*syscall.Cmsghdr)(unsafe.Pointer(&oob[0]))
+	h.Level = syscall.SOL_SOCKET
+	h.Type = syscall.SCM_TIMESTAMPNS
+	h.SetLen(syscall.CmsgLen(int(unsafe.Sizeof(syscall.Timespec{}))))
+
+	ts := syscall.NsecToTimespec(want.UnixNano())
+	*(*syscall.Timespec)(unsafe.Pointer(&oob[syscall.CmsgLen(0)])) = ts
+
+	if got := arrivalTime(oob); !got.Equal(want) {
+		t.Errorf("arrivalTime() = %v, want %v", got, want)
+	}
+
+	// Without a timestamp, the arrival is the time the packet is read
+	if got := arrivalTime(nil); time.Since(got) > time.Second {
+		t.Errorf("arrivalTime() without timestamp = %v, want now", got)
+	}
+}
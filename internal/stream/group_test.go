@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+func TestReadLoopDropsTruncatedPackets(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() failed: %v", err)
+	}
+
+	received := make(chan int, 2)
+	var oversized atomic.Uint64
+
+	c := &groupConsumer{
+		addr: conn.LocalAddr().(*net.UDPAddr),
+		cb: func(_ *net.Interface, _ net.Addr, payload []byte, _ time.Time) {
+			received <- len(payload)
+		},
+		conns: []*ipv4.PacketConn{ipv4.NewPacketConn(conn)},
+		oversized: func(*net.Interface) {
+			oversized.Add(1)
+		},
+	}
+
+	ifi := &net.Interface{Name: "lo", MTU: 1000}
+	size := packetSize(ifi)
+
+	c.wg.Add(1)
+	go c.readLoop(c.conns[0], ifi)
+	defer c.Close()
+
+	sender, err := net.Dial("udp4", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	defer sender.Close()
+
+	for _, size := range []int{size + 100, size} {
+		if _, err := sender.Write(make([]byte, size)); err != nil {
+			t.Fatalf("Write() failed: %v", err)
+		}
+	}
+
+	select {
+	case n := <-received:
+		if n != size {
+			t.Errorf("received a packet of %d bytes, want %d", n, size)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no packet received")
+	}
+
+	select {
+	case n := <-received:
+		t.Errorf("received a truncated packet of %d bytes", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if n := oversized.Load(); n != 1 {
+		t.Errorf("counted %d oversized packets, want 1", n)
+	}
+}
+
+func TestPacketSize(t *testing.T) {
+	tests := []struct {
+		mtu  int
+		want int
+	}{
+		{0, minPacketSize},
+		{576, minPacketSize},
+		{1500, 1500},
+		{9000, 9000},
+	}
+
+	for _, tt := range tests {
+		if got := packetSize(&net.Interface{MTU: tt.mtu}); got != tt.want {
+			t.Errorf("packetSize(MTU %d) = %d, want %d", tt.mtu, got, tt.want)
+		}
+	}
+}
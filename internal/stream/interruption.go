@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// interruptionThreshold is the time without packets on an interface
+	// after which the delivery of a group counts as interrupted
+	interruptionThreshold = 250 * time.Millisecond
+
+	// maxInterruption is the longest gap taken as an interruption rather
+	// than the stream stopping and starting again. The sequence numbers of
+	// a stream at 1000 packets per second wrap after about a minute.
+	maxInterruption = 60 * time.Second
+)
+
+// Interruption is a gap in the delivery of a multicast group on an
+// interface while its sender kept sending, as told by the sequence numbers
+// of the RTP packets before and after it. Such gaps are caused by the
+// network, e.g. when a membership expired on a switch or the IGMP querier
+// was lost, rather than by the sender.
+type Interruption struct {
+	Group     *net.UDPAddr
+	Interface string
+
+	// Start is the arrival time of the last packet before the gap, End
+	// that of the first one after it
+	Start time.Time
+	End   time.Time
+
+	// Lost is the number of packets the sender sent during the gap
+	Lost int
+
+	// Elsewhere is set if other interfaces kept receiving the group during
+	// the gap, which rules out the sender
+	Elsewhere bool
+}
+
+// Duration returns the length of the gap
+func (i Interruption) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// InterruptionCallback is called with each interruption detected
+type InterruptionCallback func(Interruption)
+
+// gapDetector follows the sequence numbers of the RTP packets received on
+// an interface to detect interruptions of their delivery
+type gapDetector struct {
+	mutex sync.Mutex
+
+	sequence   uint16
+	lastPacket time.Time
+
+	interruptions uint64
+	last          Interruption
+}
+
+// rtpSequence returns the sequence number of an RTP packet, and false for
+// RTCP packets multiplexed on the same port and anything else
+func rtpSequence(payload []byte) (uint16, bool) {
+	if len(payload) < 12 || payload[0]>>6 != 2 {
+		return 0, false
+	}
+
+	// RTCP packet types 192-223 as told apart in RFC 5761
+	if pt := payload[1] & 0x7f; pt >= 64 && pt <= 95 {
+		return 0, false
+	}
+
+	return uint16(payload[2])<<8 | uint16(payload[3]), true
+}
+
+// add accounts a packet and returns the gap before it and the number of
+// packets lost in it, if delivery was interrupted. A gap without missing
+// sequence numbers is a pause of the sender.
+func (d *gapDetector) add(payload []byte, arrival time.Time) (time.Time, int, bool) {
+	sequence, ok := rtpSequence(payload)
+	if !ok {
+		return time.Time{}, 0, false
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	start := d.lastPacket
+	lost := int(sequence - d.sequence - 1)
+
+	d.sequence = sequence
+	d.lastPacket = arrival
+
+	if start.IsZero() || lost <= 0 || lost >= 1<<15 {
+		return time.Time{}, 0, false
+	}
+
+	if gap := arrival.Sub(start); gap < interruptionThreshold || gap > maxInterruption {
+		return time.Time{}, 0, false
+	}
+
+	return start, lost, true
+}
+
+// record stores an interruption detected on the interface
+func (d *gapDetector) record(i Interruption) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.interruptions++
+	d.last = i
+}
+
+// stats returns the number of interruptions and the last one
+func (d *gapDetector) stats() (uint64, Interruption) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.interruptions, d.last
+}
+
+// OnInterruption registers a callback that is called with each interruption
+// of the delivery of a group joined by stream receivers. It is called from
+// the goroutines receiving packets and must not block.
+func (m *Manager) OnInterruption(callback InterruptionCallback) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.interruptionCallbacks = append(m.interruptionCallbacks, callback)
+}
+
+// interrupted passes an interruption to the registered callbacks
+func (m *Manager) interrupted(i Interruption) {
+	m.mutex.Lock()
+	callbacks := m.interruptionCallbacks
+	m.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback(i)
+	}
+}
@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// rtpPacket returns a minimal RTP packet with the given sequence number
+func rtpPacket(sequence uint16) []byte {
+	return []byte{0x80, 97, byte(sequence >> 8), byte(sequence), 0, 0, 0, 0, 0, 0, 0, 0}
+}
+
+func TestGapDetector(t *testing.T) {
+	var d gapDetector
+
+	start := time.Unix(1000, 0)
+
+	for i := range 10 {
+		if _, _, ok := d.add(rtpPacket(uint16(65530+i)), start.Add(time.Duration(i)*time.Millisecond)); ok {
+			t.Fatalf("interruption at packet %d of a continuous stream", i)
+		}
+	}
+
+	last := start.Add(9 * time.Millisecond)
+
+	// A pause of the sender keeps the sequence numbers continuous
+	if _, _, ok := d.add(rtpPacket(4), last.Add(time.Second)); ok {
+		t.Error("sender pause taken as interruption")
+	}
+
+	last = last.Add(time.Second)
+
+	// RTCP packets are ignored
+	if _, _, ok := d.add([]byte{0x80, 200, 0, 6, 0, 0, 0, 0, 0, 0, 0, 0}, last.Add(time.Second)); ok {
+		t.Error("RTCP packet taken as interruption")
+	}
+
+	// Short losses are not interruptions
+	if _, _, ok := d.add(rtpPacket(10), last.Add(6*time.Millisecond)); ok {
+		t.Error("short loss taken as interruption")
+	}
+
+	last = last.Add(6 * time.Millisecond)
+
+	gapStart, lost, ok := d.add(rtpPacket(510), last.Add(500*time.Millisecond))
+	if !ok || lost != 499 || !gapStart.Equal(last) {
+		t.Errorf("got start %v, %d lost, %v; want %v, 499, true", gapStart, lost, ok, last)
+	}
+}
+
+func TestInterruptionElsewhere(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	var got []Interruption
+	m.OnInterruption(func(i Interruption) {
+		got = append(got, i)
+	})
+
+	eth0 := &net.Interface{Index: 1, Name: "eth0"}
+	eth1 := &net.Interface{Index: 2, Name: "eth1"}
+
+	sub := &subscription{
+		addr: &net.UDPAddr{IP: net.IPv4(239, 1, 2, 3), Port: 5004},
+		counters: map[int]*joinCounter{
+			eth0.Index: {},
+			eth1.Index: {},
+		},
+	}
+
+	start := time.Unix(1000, 0)
+
+	// eth0 kept receiving during the gap of eth1
+	sub.counters[eth0.Index].lastPacket.Store(start.Add(time.Second).UnixNano())
+	m.interruption(sub, eth1, start, start.Add(time.Second), 1000)
+
+	if len(got) != 1 || got[0].Interface != "eth1" || !got[0].Elsewhere || got[0].Duration() != time.Second {
+		t.Fatalf("got %+v, want an interruption of eth1 for 1s, received elsewhere", got)
+	}
+
+	if n, last := sub.counters[eth1.Index].gaps.stats(); n != 1 || last.Lost != 1000 {
+		t.Errorf("eth1 has %d interruptions, last %+v", n, last)
+	}
+}
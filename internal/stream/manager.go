@@ -1,18 +1,21 @@
 package stream
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
 	"net"
 	"net/url"
 	"os"
 	"path"
+	"runtime"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/bluenviron/gortsplib/v5"
-	"github.com/bluenviron/gortsplib/v5/pkg/base"
 	"github.com/godbus/dbus/v5"
 	"github.com/holoplot/go-avahi"
 	"github.com/holoplot/go-multicast/pkg/multicast"
@@ -23,7 +26,12 @@ const (
 	cleanupPeriod = 5 * time.Second
 	sapTimeout    = 10 * time.Minute
 
+	// updateDelay coalesces the changes of streams in quick succession,
+	// e.g. by a burst of SAP announcements, into one update
+	updateDelay = 250 * time.Millisecond
+
 	mDnsRavennaServiceName = "_ravenna_session._sub._rtsp._tcp"
+	mDnsRTSPServiceType    = "_rtsp._tcp"
 	mDnsResolveTimeout     = time.Minute
 
 	sapAddress = "239.255.255.255:9875"
@@ -36,16 +44,54 @@ type Manager struct {
 	mutex   sync.Mutex
 	streams map[string]*Stream
 
-	updateCallback UpdateCallback
+	updateCallbacks []UpdateCallback
+
+	// interruptionCallbacks are called with interruptions of the delivery
+	// of joined groups
+	interruptionCallbacks []InterruptionCallback
+
+	// updateTimer is set while an update is pending. updated is the
+	// fingerprint of the streams last passed to the callbacks.
+	updateTimer *time.Timer
+	updated     uint64
 
 	multicastListener *multicast.Listener
 
 	sapConsumer *multicast.Consumer
 
+	// subscriptions holds the multicast joins of stream receivers
+	subscriptions map[*subscription]struct{}
+
+	// feeds holds the shared receivers of streams, by feedKey
+	feedMutex sync.Mutex
+	feeds     map[string]*rtpFeed
+
+	// workers process the packets of the feeds
+	workers *workerPool
+
+	// bandwidth holds the last byte count and rate of each join, see
+	// InterfaceBandwidth
+	bandwidthMutex sync.Mutex
+	bandwidth      map[joinKey]bandwidthSample
+
+	// streamInterfaces maps the ID hashes of streams to the interface their
+	// receivers join on instead of all interfaces
+	streamInterfaces map[string]*net.Interface
+
+	// expected holds the ID hashes of streams that raise alerts when they
+	// disappear or stop
+	expected map[string]bool
+
 	// mDnsServiceStreams maps an avahi service key to the stream ID it most
 	// recently resolved to, so we can drop the matching mDNS Discovery record
 	// when the service goes away.
 	mDnsServiceStreams map[string]mDnsServiceRef
+
+	avahiServer *avahi.Server
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 type mDnsServiceRef struct {
@@ -53,40 +99,151 @@ type mDnsServiceRef struct {
 	source   string
 }
 
-// NewManager creates a new stream manager
-func NewManager(ifis []*net.Interface) *Manager {
+// NewManager creates a new stream manager. Its goroutines run until ctx is
+// done or the manager is closed.
+func NewManager(ctx context.Context, ifis []*net.Interface) *Manager {
 	m := &Manager{
 		multicastListener:  multicast.NewListener(ifis),
 		streams:            make(map[string]*Stream),
+		subscriptions:      make(map[*subscription]struct{}),
+		feeds:              make(map[string]*rtpFeed),
+		workers:            newWorkerPool(runtime.GOMAXPROCS(0)),
+		streamInterfaces:   make(map[string]*net.Interface),
+		expected:           make(map[string]bool),
 		mDnsServiceStreams: make(map[string]mDnsServiceRef),
 	}
 
+	m.ctx, m.cancel = context.WithCancel(ctx)
+
+	m.wg.Add(1)
 	go func() {
+		defer m.wg.Done()
+
 		ticker := time.NewTicker(cleanupPeriod)
 		defer ticker.Stop()
-		for range ticker.C {
-			m.cleanupStaleStreams()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				m.cleanupStaleStreams()
+			}
 		}
 	}()
 
 	return m
 }
 
+// Close stops discovering streams, waits for the goroutines of the manager
+// and leaves all multicast groups, including those of receivers that are
+// still open
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+
+	m.mutex.Lock()
+	if m.updateTimer != nil {
+		m.updateTimer.Stop()
+		m.updateTimer = nil
+	}
+	subscriptions := make([]*subscription, 0, len(m.subscriptions))
+	for sub := range m.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	avahiServer := m.avahiServer
+	m.avahiServer = nil
+	m.mutex.Unlock()
+
+	for _, sub := range subscriptions {
+		m.unsubscribe(sub)
+	}
+
+	m.feedMutex.Lock()
+	clear(m.feeds)
+	m.feedMutex.Unlock()
+
+	m.workers.Close()
+
+	// Leaves the SAP group
+	m.multicastListener.Close()
+
+	if avahiServer != nil {
+		avahiServer.Close()
+	}
+}
+
+// update schedules the update callbacks, unless an update is pending
+// already
 func (m *Manager) update() {
-	if m.updateCallback == nil {
-		return
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.updateTimer == nil && m.ctx.Err() == nil {
+		m.updateTimer = time.AfterFunc(updateDelay, m.dispatchUpdate)
 	}
+}
 
+// dispatchUpdate calls the update callbacks with all streams, unless they
+// did not change since the last update. Refreshed announcements alone do not
+// change the streams.
+func (m *Manager) dispatchUpdate() {
 	m.mutex.Lock()
 
+	m.updateTimer = nil
+
+	callbacks := m.updateCallbacks
+	if len(callbacks) == 0 {
+		m.mutex.Unlock()
+		return
+	}
+
 	streams := make([]*Stream, 0, len(m.streams))
 	for _, stream := range m.streams {
 		streams = append(streams, stream)
 	}
 
+	fingerprint := streamsFingerprint(streams)
+	if fingerprint == m.updated {
+		m.mutex.Unlock()
+		return
+	}
+
+	m.updated = fingerprint
+
 	m.mutex.Unlock()
 
-	// Sort by name, with ID as secondary sort key
+	SortByName(streams)
+
+	for _, callback := range callbacks {
+		callback(streams)
+	}
+}
+
+// streamsFingerprint hashes what is shown of streams: their SDP, host and
+// discoveries, but not when they were last seen. The caller must hold the
+// mutex of the manager.
+func streamsFingerprint(streams []*Stream) uint64 {
+	sorted := slices.Clone(streams)
+	slices.SortFunc(sorted, func(a, b *Stream) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	h := fnv.New64a()
+
+	for _, s := range sorted {
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00", s.ID, s.Host(), s.SDP)
+
+		for _, d := range s.Discoveries {
+			fmt.Fprintf(h, "%s@%s\x00", d.Method, d.Source)
+		}
+	}
+
+	return h.Sum64()
+}
+
+// SortByName sorts streams by name, with ID as secondary sort key
+func SortByName(streams []*Stream) {
 	sort.Slice(streams, func(i, j int) bool {
 		nameA := streams[i].Name()
 		nameB := streams[j].Name()
@@ -95,70 +252,66 @@ func (m *Manager) update() {
 		}
 		return nameA < nameB
 	})
-
-	m.updateCallback(streams)
 }
 
+// OnUpdate registers a callback that is called with all streams, sorted by
+// name, whenever streams are added, changed or removed
 func (m *Manager) OnUpdate(callback UpdateCallback) {
-	m.updateCallback = callback
-}
-
-func readRTSP(uri string) ([]byte, error) {
-	u, err := base.ParseURL(uri)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	c := gortsplib.Client{
-		Scheme: u.Scheme,
-		Host:   u.Host,
-	}
-
-	if err := c.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start client: %w", err)
-	}
-
-	_, response, err := c.Describe(u)
-	if err != nil {
-		return nil, fmt.Errorf("failed to describe stream: %w", err)
-	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-	return response.Body, nil
+	m.updateCallbacks = append(m.updateCallbacks, callback)
 }
 
 func (m *Manager) MonitorMDns() error {
 	var err error
 
-	dbusConn, err := dbus.SystemBus()
+	// A private connection, as closing the avahi server closes it
+	dbusConn, err := dbus.ConnectSystemBus()
 	if err != nil {
 		return fmt.Errorf("can not connect to dbus: %w", err)
 	}
 
 	avahiServer, err := avahi.ServerNew(dbusConn)
 	if err != nil {
+		dbusConn.Close()
 		return fmt.Errorf("avahi.ServerNew() failed: %w", err)
 	}
 
+	m.mutex.Lock()
+	m.avahiServer = avahiServer
+	m.mutex.Unlock()
+
 	keyForService := func(service avahi.Service) string {
 		return fmt.Sprintf("%s.%s@%d_%d", service.Name, service.Domain, service.Interface, service.Protocol)
 	}
 
+	m.wg.Add(1)
 	go func() {
+		defer m.wg.Done()
+
 		serviceBrowser, err := avahiServer.ServiceBrowserNew(avahi.InterfaceUnspec, avahi.ProtoUnspec,
 			mDnsRavennaServiceName, "local", 0)
 		if err != nil {
 			fmt.Printf("avahi.ServiceBrowserNew() failed: %v\n", err)
 			return
 		}
+		defer avahiServer.ServiceBrowserFree(serviceBrowser)
 
 		for {
 			select {
+			case <-m.ctx.Done():
+				return
+
 			case avahiService, ok := <-serviceBrowser.AddChannel:
 				if !ok {
 					return
 				}
 
+				m.wg.Add(1)
 				go func(service avahi.Service) {
+					defer m.wg.Done()
+
 					resolver, err := avahiServer.ServiceResolverNew(
 						service.Interface, service.Protocol, service.Name,
 						service.Type, service.Domain, service.Protocol, 0)
@@ -166,14 +319,17 @@ func (m *Manager) MonitorMDns() error {
 						fmt.Printf("avahi.ServiceResolverNew() failed: %v\n", err)
 						return
 					}
+					defer avahiServer.ServiceResolverFree(resolver)
 
 					for {
 						select {
+						case <-m.ctx.Done():
+							return
+
 						case r := <-resolver.FoundChannel:
-							uri := fmt.Sprintf("rtsp://%s:%d/by-name/%s",
-								r.Address, r.Port, url.PathEscape(service.Name))
+							uri := ravennaURI(r.Address, r.Port, service.Name)
 
-							sdpBytes, err := readRTSP(uri)
+							sdpBytes, err := ReadRTSP(uri)
 							if err != nil {
 								return
 							}
@@ -196,6 +352,11 @@ func (m *Manager) MonitorMDns() error {
 							}
 							m.mutex.Unlock()
 
+							stream.setRTSP(r.Host, uri)
+
+							// Regroup the UI now that the host name is known
+							m.update()
+
 							return
 
 						case <-time.After(mDnsResolveTimeout):
@@ -280,6 +441,36 @@ func (m *Manager) LoadSDPFile(filename string) error {
 	return nil
 }
 
+// LoadRTSPURLs describes the streams at the given RTSP URLs and adds them.
+// Streams not sent to multicast groups are received by playing an RTSP
+// session.
+func (m *Manager) LoadRTSPURLs(uris []string) error {
+	for _, uri := range uris {
+		sdp, err := ReadRTSP(uri)
+		if err != nil {
+			return fmt.Errorf("failed to describe %s: %w", uri, err)
+		}
+
+		u, err := url.Parse(uri)
+		if err != nil {
+			return fmt.Errorf("failed to parse URL %s: %w", uri, err)
+		}
+
+		stream, err := m.AddStreamFromSDP(sdp, DiscoveryMethodManual, u.Host)
+		if err != nil {
+			return fmt.Errorf("failed to add stream from %s: %w", uri, err)
+		}
+
+		stream.setRTSP("", uri)
+
+		slog.Info("Loaded stream", "name", stream.Name(), "url", uri, "unicast", stream.UnicastRTSP())
+	}
+
+	m.update()
+
+	return nil
+}
+
 func (m *Manager) AddStreamFromSDP(sdp []byte, discoveryMethod DiscoveryMethod, source string) (*Stream, error) {
 	description, uniqueID, err := ParseSDP(sdp)
 	if err != nil {
@@ -345,6 +536,8 @@ func (m *Manager) GetAllStreams() []*Stream {
 		streams = append(streams, stream)
 	}
 
+	SortByName(streams)
+
 	return streams
 }
 
@@ -388,3 +581,85 @@ func (m *Manager) Count() int {
 
 	return len(m.streams)
 }
+
+// ConsumerCount returns the number of multicast groups consumed for SAP and
+// stream receivers
+func (m *Manager) ConsumerCount() int {
+	m.mutex.Lock()
+	subscriptions := len(m.subscriptions)
+	m.mutex.Unlock()
+
+	return len(m.multicastListener.Consumers()) + subscriptions
+}
+
+// Interfaces returns the interfaces the manager listens on
+func (m *Manager) Interfaces() []*net.Interface {
+	return m.multicastListener.Interfaces()
+}
+
+// SetStreamInterface makes the receivers of the stream with the given ID
+// hash join on the given interface only, or on all interfaces if ifi is nil.
+// Receivers that are already open keep their interfaces.
+func (m *Manager) SetStreamInterface(idHash string, ifi *net.Interface) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if ifi == nil {
+		delete(m.streamInterfaces, idHash)
+	} else {
+		m.streamInterfaces[idHash] = ifi
+	}
+}
+
+// StreamInterface returns the interface set for the stream with the given ID
+// hash, nil if its receivers join on all interfaces
+func (m *Manager) StreamInterface(idHash string) *net.Interface {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.streamInterfaces[idHash]
+}
+
+// SetExpected marks the stream with the given ID hash as expected or not
+func (m *Manager) SetExpected(idHash string, expected bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if expected {
+		m.expected[idHash] = true
+	} else {
+		delete(m.expected, idHash)
+	}
+}
+
+// IsExpected returns whether the stream with the given ID hash is expected
+func (m *Manager) IsExpected(idHash string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.expected[idHash]
+}
+
+// ExpectedIDs returns the ID hashes of the expected streams, sorted
+func (m *Manager) ExpectedIDs() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ids := make([]string, 0, len(m.expected))
+	for idHash := range m.expected {
+		ids = append(ids, idHash)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// receiverInterfaces returns the interfaces the receivers of a stream join on
+func (m *Manager) receiverInterfaces(s *Stream) []*net.Interface {
+	if ifi := s.Interface(); ifi != nil {
+		return []*net.Interface{ifi}
+	}
+
+	return m.Interfaces()
+}
@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerClose(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+
+	addr := &net.UDPAddr{IP: net.IPv4(239, 1, 2, 3), Port: 5004}
+
+	sub, err := m.subscribe(addr, nil, nil, func(*net.Interface, net.Addr, []byte, time.Time) {})
+	if err != nil {
+		t.Fatalf("subscribe() failed: %v", err)
+	}
+
+	m.Close()
+
+	if n := m.ConsumerCount(); n != 0 {
+		t.Errorf("%d consumers left after Close()", n)
+	}
+
+	// Receivers closed after the manager must not close their joins twice
+	m.unsubscribe(sub)
+}
+
+func TestManagerContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	m := NewManager(ctx, nil)
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutines still running after the context is done")
+	}
+}
+
+func TestManagerUpdate(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	updates := make(chan []*Stream, 10)
+	m.OnUpdate(func(streams []*Stream) {
+		updates <- streams
+	})
+
+	// A burst of announcements is coalesced into one update
+	var sdps []string
+
+	for i, name := range []string{"Stage Left", "Stage Right", "Stage Center"} {
+		sdp := strings.Replace(validSDP, "s=Stage Left", "s="+name, 1)
+		sdps = append(sdps, strings.Replace(sdp, "o=- 1 1", "o=- "+strconv.Itoa(i)+" 1", 1))
+
+		if _, err := m.AddStreamFromSDP([]byte(sdps[i]), DiscoveryMethodSAP, "eth0"); err != nil {
+			t.Fatalf("AddStreamFromSDP() failed: %v", err)
+		}
+	}
+
+	select {
+	case streams := <-updates:
+		if len(streams) != 3 {
+			t.Errorf("update with %d streams, want 3", len(streams))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+
+	// Repeated announcements do not change anything shown
+	if _, err := m.AddStreamFromSDP([]byte(sdps[0]), DiscoveryMethodSAP, "eth0"); err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	time.Sleep(2 * updateDelay)
+
+	if len(updates) != 0 {
+		t.Errorf("%d updates without a change", len(updates))
+	}
+}
@@ -0,0 +1,38 @@
+package stream
+
+import (
+	"math"
+	"time"
+)
+
+// RateFamily returns the base rate of the family of a sample rate, 44.1 or
+// 48 kHz, and the multiple of it the rate is, e.g. 48000 and 2 for 96 kHz.
+// It returns false for rates of neither family.
+func RateFamily(sampleRate uint32) (uint32, uint32, bool) {
+	for _, base := range []uint32{48000, 44100} {
+		if sampleRate >= base && sampleRate%base == 0 {
+			return base, sampleRate / base, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// NominalRate returns the rate of the 48 kHz family whose packet times name
+// the packets of a stream. Packets at 44.1 kHz carry as many frames as at
+// 48 kHz, as AES67 specifies, so 1 ms packets last 1.088 ms at 44.1 kHz.
+// Rates of neither family are returned as they are.
+func NominalRate(sampleRate uint32) uint32 {
+	if _, multiple, ok := RateFamily(sampleRate); ok {
+		return 48000 * multiple
+	}
+
+	return sampleRate
+}
+
+// FramesIn returns the number of frames in a packet of the given length at
+// the given sample rate, rounded as packet times are declared with a few
+// decimals only
+func FramesIn(packetTime time.Duration, sampleRate uint32) uint32 {
+	return uint32(math.Round(packetTime.Seconds() * float64(sampleRate)))
+}
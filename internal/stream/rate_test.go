@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateFamily(t *testing.T) {
+	for _, tt := range []struct {
+		sampleRate uint32
+		base       uint32
+		multiple   uint32
+		nominal    uint32
+	}{
+		{44100, 44100, 1, 48000},
+		{48000, 48000, 1, 48000},
+		{88200, 44100, 2, 96000},
+		{96000, 48000, 2, 96000},
+		{176400, 44100, 4, 192000},
+		{192000, 48000, 4, 192000},
+		{32000, 0, 0, 32000},
+	} {
+		base, multiple, _ := RateFamily(tt.sampleRate)
+		if base != tt.base || multiple != tt.multiple {
+			t.Errorf("RateFamily(%d) = %d, %d; want %d, %d", tt.sampleRate, base, multiple, tt.base, tt.multiple)
+		}
+
+		if got := NominalRate(tt.sampleRate); got != tt.nominal {
+			t.Errorf("NominalRate(%d) = %d, want %d", tt.sampleRate, got, tt.nominal)
+		}
+	}
+}
+
+func TestFramesIn(t *testing.T) {
+	for _, tt := range []struct {
+		packetTime time.Duration
+		sampleRate uint32
+		want       uint32
+	}{
+		{time.Millisecond, 48000, 48},
+		{1090 * time.Microsecond, 44100, 48},
+		{333 * time.Microsecond, 48000, 16},
+		{125 * time.Microsecond, 192000, 24},
+	} {
+		if got := FramesIn(tt.packetTime, tt.sampleRate); got != tt.want {
+			t.Errorf("FramesIn(%s, %d) = %d, want %d", tt.packetTime, tt.sampleRate, got, tt.want)
+		}
+	}
+}
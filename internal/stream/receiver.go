@@ -1,152 +1,163 @@
 package stream
 
 import (
-	"errors"
+	"maps"
 	"net"
+	"slices"
 	"sync"
+	"time"
 
-	"github.com/holoplot/go-multicast/pkg/multicast"
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp/v2"
 )
 
-type RTPReceiverCallback func(int, net.Addr, *rtp.Packet)
+// isRTCP reports whether a packet received on a port shared by RTP and RTCP
+// is RTCP, by the packet types of RFC 5761, section 4
+func isRTCP(payload []byte) bool {
+	return len(payload) >= 2 && payload[1] >= 192 && payload[1] <= 223
+}
+
+// RTPReceiverCallback is called with the index of the source, the sender and
+// the arrival time of each packet, the time the kernel received it where
+// supported
+type RTPReceiverCallback func(int, net.Addr, *rtp.Packet, time.Time)
+
+// RTPInterfaceCallback is called like RTPReceiverCallback, and also with the
+// interface the packet was received on, nil if it was not received from a
+// multicast group
+type RTPInterfaceCallback func(int, *net.Interface, net.Addr, *rtp.Packet, time.Time)
 
+// RTPReceiver receives the RTP packets of a stream. All receivers of a
+// stream share one feed, which joins its multicast groups and parses each
+// packet once; the counters are kept per receiver.
 type RTPReceiver struct {
 	mutex          sync.Mutex
 	stream         *Stream
-	consumers      []*multicast.Consumer
+	feed           *rtpFeed
+	subscriber     *feedSubscriber
 	packetCount    map[int]uint64
 	rtpErrors      map[int]uint64
 	sequenceErrors map[int]uint64
 	lastSequence   map[int]uint16
+	sequences      map[int]*SequenceTracker
+
+	// payloadTypeErrors counts the packets with another payload type than
+	// the SDP declares, lastPayloadType holds the last of them
+	payloadTypeErrors map[int]uint64
+	lastPayloadType   map[int]uint8
 }
 
+// NewRTPReceiver opens a receiver that calls cb with each RTP packet of the
+// stream. The packet is shared with the other receivers of the stream and
+// reused afterwards, so it must not be modified or retained after the call.
 func (s *Stream) NewRTPReceiver(cb RTPReceiverCallback) (*RTPReceiver, error) {
+	if cb == nil {
+		return s.NewRTPInterfaceReceiver(nil)
+	}
+
+	return s.NewRTPInterfaceReceiver(func(i int, _ *net.Interface, src net.Addr, packet *rtp.Packet, arrival time.Time) {
+		cb(i, src, packet, arrival)
+	})
+}
+
+// NewRTPInterfaceReceiver opens a receiver like NewRTPReceiver, whose
+// callback is also told the interface each packet was received on
+func (s *Stream) NewRTPInterfaceReceiver(cb RTPInterfaceCallback) (*RTPReceiver, error) {
 	r := &RTPReceiver{
 		stream:         s,
-		consumers:      make([]*multicast.Consumer, 0),
 		packetCount:    make(map[int]uint64),
 		rtpErrors:      make(map[int]uint64),
 		sequenceErrors: make(map[int]uint64),
 		lastSequence:   make(map[int]uint16),
-	}
+		sequences:      make(map[int]*SequenceTracker),
 
-	for i, source := range s.Description.Sources {
-		addr := net.UDPAddr{
-			IP:   source.DestinationAddress,
-			Port: int(source.DestinationPort),
-		}
-
-		c, err := s.manager.multicastListener.AddConsumer(&addr, func(ifi *net.Interface, src net.Addr, payload []byte) {
-			packet := &rtp.Packet{}
-			if err := packet.Unmarshal(payload); err == nil {
-				r.mutex.Lock()
-
-				r.packetCount[i]++
-
-				if r.packetCount[i] > 1 {
-					if packet.SequenceNumber != r.lastSequence[i]+1 {
-						r.sequenceErrors[i]++
-					}
-				}
+		payloadTypeErrors: make(map[int]uint64),
+		lastPayloadType:   make(map[int]uint8),
+	}
 
-				r.lastSequence[i] = packet.SequenceNumber
+	for i := range s.Description.Sources {
+		r.sequences[i] = &SequenceTracker{}
+	}
 
-				r.mutex.Unlock()
+	r.subscriber = &feedSubscriber{
+		fn: func(i int, ifi *net.Interface, src net.Addr, packet *rtp.Packet, arrival time.Time) {
+			r.mutex.Lock()
 
-				if cb != nil {
-					cb(i, src, packet)
-				}
-			} else {
-				r.mutex.Lock()
+			if packet == nil {
 				r.rtpErrors[i]++
 				r.mutex.Unlock()
 
+				return
 			}
-		})
-		if err == nil {
-			r.consumers = append(r.consumers, c)
-		} else {
-			return nil, err
-		}
-	}
-
-	return r, nil
-}
-
-type (
-	Sample      int32
-	SampleFrame []Sample
-)
-
-var (
-	ErrUnsupportedContentType = errors.New("unsupported content type")
-)
-
-func (r *RTPReceiver) ExtractSamples(packet *rtp.Packet) ([]SampleFrame, error) {
-	var bytesPerSample uint32
-
-	switch r.stream.Description.ContentType {
-	case ContentTypePCM16:
-		bytesPerSample = 2
-	case ContentTypePCM24:
-		bytesPerSample = 3
-	default:
-		return nil, ErrUnsupportedContentType
-	}
 
-	channels := r.stream.Description.ChannelCount
-	bytesPerFrame := bytesPerSample * channels
-	numFrames := uint32(len(packet.Payload)) / bytesPerFrame
+			r.packetCount[i]++
 
-	var (
-		i      uint32
-		frames []SampleFrame
-	)
+			if r.packetCount[i] > 1 {
+				if packet.SequenceNumber != r.lastSequence[i]+1 {
+					r.sequenceErrors[i]++
+				}
+			}
 
-	for range numFrames {
-		frame := make(SampleFrame, channels)
+			r.lastSequence[i] = packet.SequenceNumber
+			r.sequences[i].Update(packet.SequenceNumber)
 
-		for ch := range channels {
-			switch bytesPerSample {
-			case 2:
-				value := uint32(packet.Payload[i])<<24 |
-					uint32(packet.Payload[i+1])<<16
+			if packet.PayloadType != s.Description.PayloadType {
+				r.payloadTypeErrors[i]++
+				r.lastPayloadType[i] = packet.PayloadType
+			}
 
-				frame[ch] = Sample(value)
+			r.mutex.Unlock()
 
-			case 3:
-				value := uint32(packet.Payload[i])<<24 |
-					uint32(packet.Payload[i+1])<<16 |
-					uint32(packet.Payload[i+2])<<8
+			if cb != nil {
+				cb(i, ifi, src, packet, arrival)
+			}
+		},
+	}
 
-				frame[ch] = Sample(value)
+	feed, err := s.manager.subscribeFeed(s, r.subscriber)
+	if err != nil {
+		return nil, err
+	}
 
-			default:
-				return nil, ErrUnsupportedContentType
-			}
+	r.feed = feed
 
-			i += bytesPerSample
-		}
+	return r, nil
+}
 
-		frames = append(frames, frame)
+// receiverError returns the error of the first source if no source could be
+// received. Sources that failed while others are received are reported by
+// SourceError, so a single unusable media description does not break the
+// whole stream.
+func receiverError(received int, sourceErrors map[int]error) error {
+	if received > 0 || len(sourceErrors) == 0 {
+		return nil
 	}
 
-	return frames, nil
+	return sourceErrors[slices.Min(slices.Collect(maps.Keys(sourceErrors)))]
 }
 
+// Close stops receiving the stream. It waits for the callback to return if a
+// packet is being delivered, and the callback is not called afterwards, so
+// it must not be called from the callback or while holding a lock the
+// callback takes.
 func (r *RTPReceiver) Close() {
-	for _, c := range r.consumers {
-		r.stream.manager.multicastListener.RemoveConsumer(c)
-	}
+	r.stream.manager.unsubscribeFeed(r.feed, r.subscriber)
 }
 
 func (r *RTPReceiver) NumSources() int {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	return len(r.stream.Description.Sources)
+}
+
+// SourceError returns why a source is not received, nil if it is
+func (r *RTPReceiver) SourceError(i int) error {
+	return r.feed.sourceErrors[i]
+}
 
-	return len(r.consumers)
+// QueueDrops returns the number of packets of the stream dropped because its
+// receivers did not keep up or the memory budget was exhausted. It is shared
+// by all receivers of the stream.
+func (r *RTPReceiver) QueueDrops() uint64 {
+	return r.feed.drops.Load()
 }
 
 func (r *RTPReceiver) PacketCount(i int) uint64 {
@@ -170,29 +181,54 @@ func (r *RTPReceiver) SequenceErrors(i int) uint64 {
 	return r.sequenceErrors[i]
 }
 
+// PayloadTypeErrors returns the number of packets of a source with another
+// payload type than the SDP declares, and the payload type of the last one
+func (r *RTPReceiver) PayloadTypeErrors(i int) (uint64, uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.payloadTypeErrors[i], r.lastPayloadType[i]
+}
+
+// SequenceStats returns the loss and reordering of a source, from its
+// extended sequence numbers
+func (r *RTPReceiver) SequenceStats(i int) SequenceStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t, ok := r.sequences[i]; ok {
+		return t.Stats()
+	}
+
+	return SequenceStats{}
+}
+
 type RTCPReceiverCallback func(int, net.Addr, rtcp.Packet)
 
 type RTCPReceiver struct {
-	mutex      sync.Mutex
-	stream     *Stream
-	consumers  []*multicast.Consumer
-	rtcpErrors map[int]uint64
+	mutex         sync.Mutex
+	stream        *Stream
+	subscriptions []*subscription
+	sourceErrors  map[int]error
+	rtcpErrors    map[int]uint64
 }
 
 func (s *Stream) NewRTCPReceiver(cb RTCPReceiverCallback) (*RTCPReceiver, error) {
 	r := &RTCPReceiver{
-		stream:     s,
-		consumers:  make([]*multicast.Consumer, 0),
-		rtcpErrors: make(map[int]uint64),
+		stream:        s,
+		subscriptions: make([]*subscription, 0),
+		sourceErrors:  make(map[int]error),
+		rtcpErrors:    make(map[int]uint64),
 	}
 
+	ifis := s.manager.receiverInterfaces(s)
+
 	for i, source := range s.Description.Sources {
-		addr := net.UDPAddr{
-			IP:   source.DestinationAddress,
-			Port: int(source.DestinationPort) + 1,
-		}
+		c, err := s.manager.subscribe(source.RTCPAddr(), source.joinSource(), ifis, func(_ *net.Interface, src net.Addr, payload []byte, _ time.Time) {
+			if source.RTCPMux && !isRTCP(payload) {
+				return
+			}
 
-		c, err := s.manager.multicastListener.AddConsumer(&addr, func(ifi *net.Interface, src net.Addr, payload []byte) {
 			if pkts, err := rtcp.Unmarshal(payload); err != nil {
 				r.mutex.Lock()
 				defer r.mutex.Unlock()
@@ -205,17 +241,27 @@ func (s *Stream) NewRTCPReceiver(cb RTCPReceiverCallback) (*RTCPReceiver, error)
 			}
 		})
 		if err == nil {
-			r.consumers = append(r.consumers, c)
+			r.subscriptions = append(r.subscriptions, c)
 		} else {
-			return nil, err
+			r.sourceErrors[i] = err
 		}
 	}
 
+	if err := receiverError(len(r.subscriptions), r.sourceErrors); err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
+// SourceError returns why the RTCP packets of a source are not received, nil
+// if they are
+func (r *RTCPReceiver) SourceError(i int) error {
+	return r.sourceErrors[i]
+}
+
 func (r *RTCPReceiver) Close() {
-	for _, c := range r.consumers {
-		r.stream.manager.multicastListener.RemoveConsumer(c)
+	for _, c := range r.subscriptions {
+		r.stream.manager.unsubscribe(c)
 	}
 }
@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PTPClock is a PTP reference clock as declared with a=ts-refclk in RFC
+// 7273, e.g. "ptp=IEEE1588-2008:39-A7-94-FF-FE-07-CB-D0:0"
+type PTPClock struct {
+	Version string
+
+	// Grandmaster is the clock identity of the grandmaster, empty for a
+	// traceable clock, which accepts any grandmaster
+	Grandmaster string
+	Domain      uint8
+}
+
+// ParsePTPClock parses a PTP reference clock. The domain defaults to 0.
+func ParsePTPClock(refClock string) (PTPClock, bool) {
+	clock, ok := strings.CutPrefix(refClock, "ptp=")
+	if !ok {
+		return PTPClock{}, false
+	}
+
+	// The version, the grandmaster identity or "traceable", and the domain
+	fields := strings.Split(clock, ":")
+
+	c := PTPClock{Version: fields[0]}
+
+	if len(fields) >= 2 && fields[1] != "traceable" {
+		c.Grandmaster = fields[1]
+	}
+
+	if len(fields) == 3 {
+		if n, err := strconv.ParseUint(fields[2], 10, 8); err == nil {
+			c.Domain = uint8(n)
+		}
+	}
+
+	return c, true
+}
+
+// ClockLabel names the clock the source is synchronized to: the PTP domain
+// and grandmaster of its reference clock, or of its RAVENNA clock domain if
+// it declares none
+func (s StreamSource) ClockLabel() string {
+	if c, ok := ParsePTPClock(s.ReferenceClock); ok {
+		if c.Grandmaster == "" {
+			return fmt.Sprintf("PTP domain %d, any grandmaster", c.Domain)
+		}
+
+		return fmt.Sprintf("PTP domain %d, grandmaster %s", c.Domain, strings.ToUpper(c.Grandmaster))
+	}
+
+	// RAVENNA declares a=clock-domain:PTPv2 0
+	if domain, ok := strings.CutPrefix(s.ClockDomain, "PTPv2 "); ok {
+		if n, err := strconv.ParseUint(domain, 10, 8); err == nil {
+			return fmt.Sprintf("PTP domain %d, grandmaster not declared", n)
+		}
+	}
+
+	if clock, _, _ := strings.Cut(s.ReferenceClock, "="); clock != "" {
+		return "Reference clock " + clock
+	}
+
+	return "No reference clock"
+}
+
+// ClockLabel names the clocks the sources of the stream are synchronized
+// to, see StreamSource.ClockLabel
+func (s *Stream) ClockLabel() string {
+	var labels []string
+
+	for _, source := range s.Description.Sources {
+		if label := source.ClockLabel(); !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(labels) == 0 {
+		return "No reference clock"
+	}
+
+	return strings.Join(labels, " + ")
+}
@@ -0,0 +1,28 @@
+package stream
+
+import "testing"
+
+func TestClockLabel(t *testing.T) {
+	tests := []struct {
+		source StreamSource
+		want   string
+	}{
+		{StreamSource{ReferenceClock: "ptp=IEEE1588-2008:39-a7-94-ff-fe-07-cb-d0:0"}, "PTP domain 0, grandmaster 39-A7-94-FF-FE-07-CB-D0"},
+		{StreamSource{ReferenceClock: "ptp=IEEE1588-2008:traceable"}, "PTP domain 0, any grandmaster"},
+		{StreamSource{ReferenceClock: "ptp=IEEE1588-2019:00-1D-C1-FF-FE-12-34-56:127"}, "PTP domain 127, grandmaster 00-1D-C1-FF-FE-12-34-56"},
+		{StreamSource{ClockDomain: "PTPv2 3"}, "PTP domain 3, grandmaster not declared"},
+		{StreamSource{ReferenceClock: "localmac=7C-E9-D3-1B-9A-AF"}, "Reference clock localmac"},
+		{StreamSource{}, "No reference clock"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.source.ClockLabel(); got != tt.want {
+			t.Errorf("ClockLabel() of %+v = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+
+	s := &Stream{Description: StreamDescription{Sources: []StreamSource{tests[0].source, tests[0].source}}}
+	if got := s.ClockLabel(); got != tests[0].want {
+		t.Errorf("stream ClockLabel() = %q, want %q", got, tests[0].want)
+	}
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/bluenviron/gortsplib/v5"
+	"github.com/bluenviron/gortsplib/v5/pkg/base"
+	"github.com/godbus/dbus/v5"
+	"github.com/holoplot/go-avahi"
+)
+
+// ReadRTSP fetches the SDP of a stream with an RTSP DESCRIBE request
+func ReadRTSP(uri string) ([]byte, error) {
+	u, err := base.ParseURL(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	c := gortsplib.Client{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+	}
+
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start client: %w", err)
+	}
+	defer c.Close()
+
+	_, response, err := c.Describe(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe stream: %w", err)
+	}
+
+	return response.Body, nil
+}
+
+// ravennaURI returns the RTSP URL of a RAVENNA session announced via mDNS
+func ravennaURI(address string, port uint16, name string) string {
+	return fmt.Sprintf("rtsp://%s/by-name/%s",
+		net.JoinHostPort(address, strconv.Itoa(int(port))), url.PathEscape(name))
+}
+
+// ResolveMDNS resolves the RAVENNA session announced via mDNS with the given
+// service name and returns its RTSP URL
+func ResolveMDNS(name string) (string, error) {
+	// A private connection, as closing the server closes it
+	dbusConn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return "", fmt.Errorf("can not connect to dbus: %w", err)
+	}
+
+	avahiServer, err := avahi.ServerNew(dbusConn)
+	if err != nil {
+		return "", fmt.Errorf("avahi.ServerNew() failed: %w", err)
+	}
+	defer avahiServer.Close()
+
+	service, err := avahiServer.ResolveService(avahi.InterfaceUnspec, avahi.ProtoUnspec,
+		name, mDnsRTSPServiceType, "local", avahi.ProtoUnspec, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", name, err)
+	}
+
+	return ravennaURI(service.Address, service.Port, name), nil
+}
@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v5"
+	"github.com/bluenviron/gortsplib/v5/pkg/base"
+	"github.com/bluenviron/gortsplib/v5/pkg/description"
+	"github.com/bluenviron/gortsplib/v5/pkg/format"
+	rtpv1 "github.com/pion/rtp"
+)
+
+// rtspTimeout limits how long setting up an RTSP session may take, as
+// receivers are opened while the UI waits
+const rtspTimeout = 5 * time.Second
+
+// rtspSession receives the RTP packets of a unicast stream by setting up and
+// playing an RTSP session, for senders that do not send to multicast groups.
+// The packets are sent over UDP, or interleaved with the RTSP connection if
+// UDP does not get through.
+type rtspSession struct {
+	client *gortsplib.Client
+}
+
+// newRTSPSession describes the stream at uri, sets up all its media and
+// plays them. cb is called with the index of the media, which is that of the
+// source, the sender and the payload of each RTP packet.
+func newRTSPSession(uri string, cb func(int, net.Addr, []byte, time.Time)) (*rtspSession, error) {
+	u, err := base.ParseURL(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	c := &gortsplib.Client{
+		Scheme:       u.Scheme,
+		Host:         u.Host,
+		ReadTimeout:  rtspTimeout,
+		WriteTimeout: rtspTimeout,
+
+		// Losses are counted by the receivers, and the library would log
+		// to the terminal
+		OnPacketsLost: func(uint64) {},
+		OnDecodeError: func(err error) {
+			slog.Debug("RTSP decode error", "url", uri, "error", err)
+		},
+		OnTransportSwitch: func(err error) {
+			slog.Info("RTSP transport switched", "url", uri, "reason", err)
+		},
+	}
+
+	if err := c.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start client: %w", err)
+	}
+
+	desc, _, err := c.Describe(u)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to describe stream: %w", err)
+	}
+
+	if err := c.SetupAll(desc.BaseURL, desc.Medias); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to set up stream: %w", err)
+	}
+
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host = u.Host
+	}
+
+	sender := &net.UDPAddr{IP: net.ParseIP(host)}
+
+	c.OnPacketRTPAny(func(medi *description.Media, _ format.Format, pkt *rtpv1.Packet) {
+		payload, err := pkt.Marshal()
+		if err != nil {
+			return
+		}
+
+		cb(slices.Index(desc.Medias, medi), sender, payload, time.Now())
+	})
+
+	if _, err := c.Play(nil); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to play stream: %w", err)
+	}
+
+	return &rtspSession{client: c}, nil
+}
+
+// Close tears down the session
+func (s *rtspSession) Close() {
+	s.client.Close()
+}
+
+// UnicastRTSP returns whether the stream is received by playing an RTSP
+// session, as it was described via RTSP and none of its sources is sent to
+// a multicast group
+func (s *Stream) UnicastRTSP() bool {
+	if s.RTSPURL() == "" {
+		return false
+	}
+
+	for _, source := range s.Description.Sources {
+		if source.DestinationAddress.IsMulticast() {
+			return false
+		}
+	}
+
+	return true
+}
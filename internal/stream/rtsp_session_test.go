@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v5"
+	"github.com/bluenviron/gortsplib/v5/pkg/base"
+	"github.com/bluenviron/gortsplib/v5/pkg/description"
+	"github.com/bluenviron/gortsplib/v5/pkg/format"
+	rtpv1 "github.com/pion/rtp"
+	"github.com/pion/rtp/v2"
+)
+
+// rtspHandler serves one stream to every client
+type rtspHandler struct {
+	stream *gortsplib.ServerStream
+}
+
+func (h *rtspHandler) OnDescribe(*gortsplib.ServerHandlerOnDescribeCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	return &base.Response{StatusCode: base.StatusOK}, h.stream, nil
+}
+
+func (h *rtspHandler) OnSetup(*gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	return &base.Response{StatusCode: base.StatusOK}, h.stream, nil
+}
+
+func (h *rtspHandler) OnPlay(*gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+	return &base.Response{StatusCode: base.StatusOK}, nil
+}
+
+// freeAddress returns a local TCP address that is free
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+func TestRTSPSession(t *testing.T) {
+	h := &rtspHandler{}
+
+	server := &gortsplib.Server{
+		Handler:     h,
+		RTSPAddress: freeAddress(t),
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	media := &description.Media{
+		Type:    description.MediaTypeAudio,
+		Formats: []format.Format{&format.LPCM{PayloadTyp: 98, BitDepth: 24, SampleRate: 48000, ChannelCount: 2}},
+	}
+
+	h.stream = &gortsplib.ServerStream{
+		Server: server,
+		Desc:   &description.Session{Medias: []*description.Media{media}},
+	}
+
+	if err := h.stream.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer h.stream.Close()
+
+	type received struct {
+		index  int
+		packet rtp.Packet
+	}
+
+	packets := make(chan received, 16)
+
+	// The server only offers TCP, so the packets are interleaved
+	session, err := newRTSPSession("rtsp://"+server.RTSPAddress+"/stream", func(i int, _ net.Addr, payload []byte, _ time.Time) {
+		var p rtp.Packet
+		if err := p.Unmarshal(payload); err == nil {
+			packets <- received{i, p}
+		}
+	})
+	if err != nil {
+		t.Fatalf("newRTSPSession() failed: %v", err)
+	}
+	defer session.Close()
+
+	sent := &rtpv1.Packet{
+		Header: rtpv1.Header{
+			Version:        2,
+			PayloadType:    98,
+			SequenceNumber: 1234,
+			Timestamp:      48000,
+			SSRC:           0x1234,
+		},
+		Payload: make([]byte, 6*48),
+	}
+
+	if err := h.stream.WritePacketRTP(media, sent); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-packets:
+		if r.index != 0 || r.packet.SequenceNumber != 1234 || r.packet.Timestamp != 48000 || len(r.packet.Payload) != 6*48 {
+			t.Errorf("got packet of source %d: %+v", r.index, r.packet.Header)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no packet received")
+	}
+}
+
+func TestUnicastRTSP(t *testing.T) {
+	s := &Stream{
+		Description: StreamDescription{
+			Sources: []StreamSource{{DestinationAddress: net.ParseIP("192.168.1.20")}},
+		},
+	}
+
+	if s.UnicastRTSP() {
+		t.Error("UnicastRTSP() = true without an RTSP URL")
+	}
+
+	s.rtspURL = "rtsp://192.168.1.10/by-name/Stage"
+	if !s.UnicastRTSP() {
+		t.Error("UnicastRTSP() = false for a unicast destination")
+	}
+
+	s.Description.Sources = append(s.Description.Sources, StreamSource{DestinationAddress: net.ParseIP("239.1.1.1")})
+	if s.UnicastRTSP() {
+		t.Error("UnicastRTSP() = true with a multicast source")
+	}
+}
@@ -0,0 +1,94 @@
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+const rtspSDP = "v=0\r\n" +
+	"o=- 1 1 IN IP4 192.168.1.10\r\n" +
+	"s=Stage Left\r\n" +
+	"c=IN IP4 239.1.1.1/32\r\n" +
+	"t=0 0\r\n" +
+	"m=audio 5004 RTP/AVP 98\r\n" +
+	"a=rtpmap:98 L24/48000/2\r\n"
+
+// serveRTSP answers RTSP requests on a local port with the given SDP and
+// returns the address
+func serveRTSP(t *testing.T, sdp string) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := textproto.NewReader(bufio.NewReader(conn))
+
+		for {
+			line, err := r.ReadLine()
+			if err != nil {
+				return
+			}
+
+			header, err := r.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+
+			response := fmt.Sprintf("RTSP/1.0 200 OK\r\nCSeq: %s\r\n", header.Get("CSeq"))
+
+			if strings.HasPrefix(line, "DESCRIBE ") {
+				response += fmt.Sprintf("Content-Type: application/sdp\r\nContent-Base: %s/\r\nContent-Length: %d\r\n\r\n%s",
+					strings.Fields(line)[1], len(sdp), sdp)
+			} else {
+				response += "Public: DESCRIBE\r\n\r\n"
+			}
+
+			if _, err := conn.Write([]byte(response)); err != nil {
+				return
+			}
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestReadRTSP(t *testing.T) {
+	addr := serveRTSP(t, rtspSDP)
+
+	sdp, err := ReadRTSP("rtsp://" + addr + "/by-name/Stage%20Left")
+	if err != nil {
+		t.Fatalf("ReadRTSP() failed: %v", err)
+	}
+
+	if string(sdp) != rtspSDP {
+		t.Errorf("got SDP %q, want %q", sdp, rtspSDP)
+	}
+}
+
+func TestRavennaURI(t *testing.T) {
+	for _, tc := range []struct {
+		address string
+		want    string
+	}{
+		{"192.168.1.10", "rtsp://192.168.1.10:9010/by-name/Stage%20Left"},
+		{"fe80::1", "rtsp://[fe80::1]:9010/by-name/Stage%20Left"},
+	} {
+		if got := ravennaURI(tc.address, 9010, "Stage Left"); got != tc.want {
+			t.Errorf("ravennaURI(%s) = %s, want %s", tc.address, got, tc.want)
+		}
+	}
+}
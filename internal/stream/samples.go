@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"errors"
+
+	"github.com/pion/rtp/v2"
+)
+
+// Sample is a PCM sample, left-aligned to 32 bits
+type Sample int32
+
+var (
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrPayloadTypeMismatch    = errors.New("payload type differs from the SDP")
+)
+
+// DecodeSamples appends the samples of a PCM payload to dst[:0], interleaved
+// by channel, so frame i of a stream with n channels is at [i*n:(i+1)*n].
+// Passing the result of the previous call as dst decodes without
+// allocating once it is large enough for a packet.
+func DecodeSamples(dst []Sample, payload []byte, contentType ContentType, channels uint32) ([]Sample, error) {
+	var bytesPerSample int
+
+	switch contentType {
+	case ContentTypePCM16:
+		bytesPerSample = 2
+	case ContentTypePCM24:
+		bytesPerSample = 3
+	default:
+		return dst[:0], ErrUnsupportedContentType
+	}
+
+	bytesPerFrame := bytesPerSample * int(channels)
+	if bytesPerFrame == 0 {
+		return dst[:0], nil
+	}
+
+	// Trailing bytes of an incomplete frame are ignored
+	payload = payload[:len(payload)/bytesPerFrame*bytesPerFrame]
+
+	n := len(payload) / bytesPerSample
+	if cap(dst) < n {
+		dst = make([]Sample, n)
+	}
+
+	dst = dst[:n]
+
+	switch bytesPerSample {
+	case 2:
+		for i := range dst {
+			dst[i] = Sample(uint32(payload[2*i])<<24 | uint32(payload[2*i+1])<<16)
+		}
+
+	case 3:
+		for i := range dst {
+			dst[i] = Sample(uint32(payload[3*i])<<24 | uint32(payload[3*i+1])<<16 | uint32(payload[3*i+2])<<8)
+		}
+	}
+
+	return dst, nil
+}
+
+// DecodeSamples decodes the samples of a packet of the stream into dst, as
+// DecodeSamples. Packets of another payload type than the SDP declares are
+// rejected, as their payload would be decoded as noise.
+func (r *RTPReceiver) DecodeSamples(dst []Sample, packet *rtp.Packet) ([]Sample, error) {
+	desc := &r.stream.Description
+
+	if packet.PayloadType != desc.PayloadType {
+		return dst[:0], ErrPayloadTypeMismatch
+	}
+
+	return DecodeSamples(dst, packet.Payload, desc.ContentType, desc.ChannelCount)
+}
@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pion/rtp/v2"
+)
+
+func TestDecodeSamples(t *testing.T) {
+	// Two frames of two channels and a trailing partial frame
+	payload := []byte{
+		0x7f, 0xff, 0xff, 0x80, 0x00, 0x00,
+		0x00, 0x00, 0x01, 0xff, 0xff, 0xff,
+		0x12,
+	}
+
+	samples, err := DecodeSamples(nil, payload, ContentTypePCM24, 2)
+	if err != nil {
+		t.Fatalf("DecodeSamples() failed: %v", err)
+	}
+
+	want := []Sample{0x7fffff00, -0x80000000, 0x00000100, -0x100}
+	if !slices.Equal(samples, want) {
+		t.Errorf("DecodeSamples() = %x, want %x", samples, want)
+	}
+
+	// The buffer is reused
+	reused, _ := DecodeSamples(samples, payload[:6], ContentTypePCM24, 2)
+	if len(reused) != 2 || &reused[0] != &samples[0] {
+		t.Errorf("DecodeSamples() did not decode into the given buffer")
+	}
+
+	if _, err := DecodeSamples(nil, payload, ContentTypeUndefined, 2); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("DecodeSamples() of an unknown format = %v, want ErrUnsupportedContentType", err)
+	}
+}
+
+// benchmarkPacket is a packet of 1 ms of 8 channels of L24 at 48 kHz
+func benchmarkPacket() (*RTPReceiver, *rtp.Packet) {
+	r := &RTPReceiver{
+		stream: &Stream{
+			Description: StreamDescription{
+				ContentType:  ContentTypePCM24,
+				ChannelCount: 8,
+				PayloadType:  98,
+			},
+		},
+	}
+
+	packet := &rtp.Packet{
+		Header:  rtp.Header{PayloadType: 98},
+		Payload: make([]byte, 48*8*3),
+	}
+
+	return r, packet
+}
+
+func BenchmarkDecodeSamples(b *testing.B) {
+	r, packet := benchmarkPacket()
+
+	var samples []Sample
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		samples, _ = r.DecodeSamples(samples, packet)
+	}
+}
+
+// BenchmarkDecodeSamplesAllocating decodes into a new buffer per packet, as
+// needed when the samples are handed on, e.g. to a recording
+func BenchmarkDecodeSamplesAllocating(b *testing.B) {
+	r, packet := benchmarkPacket()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_, _ = r.DecodeSamples(nil, packet)
+	}
+}
@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SDPSeverity is the severity of an SDP validation issue
+type SDPSeverity int
+
+const (
+	SDPWarning SDPSeverity = iota
+	SDPError
+)
+
+func (s SDPSeverity) String() string {
+	if s == SDPError {
+		return "error"
+	}
+	return "warning"
+}
+
+// SDPIssue is a problem found in an SDP. Line is the zero-based line number
+// the issue refers to, or -1 if it concerns the SDP as a whole.
+type SDPIssue struct {
+	Line     int
+	Severity SDPSeverity
+	Message  string
+}
+
+// sdpFieldTypes lists all field types defined by RFC 4566
+const sdpFieldTypes = "vosiuepcbtrzkam"
+
+// ValidateSDP checks an SDP for syntax errors and for attributes AES67
+// receivers rely on. It is deliberately lenient and does not replace a full
+// parser; it helps spotting problems when looking at an SDP.
+func ValidateSDP(b []byte) []SDPIssue {
+	var issues []SDPIssue
+
+	add := func(line int, severity SDPSeverity, format string, args ...any) {
+		issues = append(issues, SDPIssue{
+			Line:     line,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\r\n"), "\n")
+
+	type media struct {
+		line       int
+		connection bool
+		attributes map[string]bool
+		formats    []string
+	}
+
+	seen := make(map[byte]bool)
+	var medias []*media
+	var current *media
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		if len(line) < 2 || line[1] != '=' {
+			add(i, SDPError, "line is not of the form <type>=<value>")
+			continue
+		}
+
+		t := line[0]
+		value := line[2:]
+
+		if !strings.ContainsRune(sdpFieldTypes, rune(t)) {
+			add(i, SDPError, "unknown field type %q", t)
+			continue
+		}
+
+		if i == 0 && (t != 'v' || value != "0") {
+			add(i, SDPError, "SDP must start with v=0")
+		}
+
+		switch t {
+		case 'm':
+			fields := strings.Fields(value)
+			if len(fields) < 4 {
+				add(i, SDPError, "media description needs media, port, protocol and format")
+			}
+
+			current = &media{
+				line:       i,
+				attributes: make(map[string]bool),
+			}
+
+			if len(fields) > 3 {
+				current.formats = fields[3:]
+			}
+
+			medias = append(medias, current)
+
+		case 'c':
+			if len(strings.Fields(value)) != 3 {
+				add(i, SDPError, "connection data needs network type, address type and address")
+			}
+
+			if current != nil {
+				current.connection = true
+			} else {
+				seen[t] = true
+			}
+
+		case 'a':
+			name, attrValue, _ := strings.Cut(value, ":")
+
+			if current == nil {
+				seen[t] = true
+				continue
+			}
+
+			current.attributes[name] = true
+
+			if name == "rtpmap" {
+				format, _, _ := strings.Cut(attrValue, " ")
+				current.attributes["rtpmap:"+format] = true
+			}
+
+			if name == "rtcp" {
+				if _, _, err := parseRTCPAttribute(attrValue); err != nil {
+					add(i, SDPWarning, "%v, RTCP is expected on the RTP port plus one", err)
+				}
+			}
+
+		default:
+			if current == nil {
+				seen[t] = true
+			}
+		}
+	}
+
+	for _, t := range []byte{'v', 'o', 's', 't'} {
+		if !seen[t] {
+			add(-1, SDPError, "missing required %c= field", t)
+		}
+	}
+
+	if len(medias) == 0 {
+		add(-1, SDPWarning, "no media descriptions")
+	}
+
+	for _, m := range medias {
+		if !m.connection && !seen['c'] {
+			add(m.line, SDPError, "no connection data for this media")
+		}
+
+		for _, format := range m.formats {
+			if !m.attributes["rtpmap:"+format] {
+				add(m.line, SDPWarning, "no a=rtpmap for payload type %s", format)
+			}
+		}
+
+		for _, name := range []string{"ptime", "ts-refclk", "mediaclk"} {
+			if !m.attributes[name] && !sessionAttribute(lines, name) {
+				add(m.line, SDPWarning, "no a=%s attribute, required by AES67", name)
+			}
+		}
+	}
+
+	return issues
+}
+
+// sessionAttribute returns whether an attribute is present at session level
+func sessionAttribute(lines []string, name string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, "m=") {
+			return false
+		}
+
+		if attr, ok := strings.CutPrefix(strings.TrimSuffix(line, "\r"), "a="); ok {
+			if n, _, _ := strings.Cut(attr, ":"); n == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+)
+
+const validSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+a=ts-refclk:ptp=IEEE1588-2008:00-1D-C1-FF-FE-12-34-56:0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+a=mediaclk:direct=0
+`
+
+func TestValidateSDPValid(t *testing.T) {
+	if issues := ValidateSDP([]byte(validSDP)); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestValidateSDPCRLF(t *testing.T) {
+	sdp := strings.ReplaceAll(validSDP, "\n", "\r\n")
+
+	if issues := ValidateSDP([]byte(sdp)); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestValidateSDPIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdp      string
+		line     int
+		severity SDPSeverity
+		message  string
+	}{
+		{
+			name:     "bad version",
+			sdp:      strings.Replace(validSDP, "v=0", "v=1", 1),
+			line:     0,
+			severity: SDPError,
+			message:  "v=0",
+		},
+		{
+			name:     "malformed line",
+			sdp:      strings.Replace(validSDP, "s=Stage Left", "Stage Left", 1),
+			line:     2,
+			severity: SDPError,
+			message:  "<type>=<value>",
+		},
+		{
+			name:     "unknown field",
+			sdp:      strings.Replace(validSDP, "t=0 0", "t=0 0\nx=1", 1),
+			line:     5,
+			severity: SDPError,
+			message:  "unknown field type",
+		},
+		{
+			name:     "missing session name",
+			sdp:      strings.Replace(validSDP, "s=Stage Left\n", "", 1),
+			line:     -1,
+			severity: SDPError,
+			message:  "missing required s=",
+		},
+		{
+			name:     "missing connection",
+			sdp:      strings.Replace(validSDP, "c=IN IP4 239.1.1.1/32\n", "", 1),
+			line:     5,
+			severity: SDPError,
+			message:  "no connection data",
+		},
+		{
+			name:     "missing rtpmap",
+			sdp:      strings.Replace(validSDP, "a=rtpmap:98 L24/48000/2\n", "", 1),
+			line:     6,
+			severity: SDPWarning,
+			message:  "a=rtpmap",
+		},
+		{
+			name:     "missing ptime",
+			sdp:      strings.Replace(validSDP, "a=ptime:1\n", "", 1),
+			line:     6,
+			severity: SDPWarning,
+			message:  "a=ptime",
+		},
+		{
+			name:     "malformed rtcp",
+			sdp:      strings.Replace(validSDP, "a=ptime:1\n", "a=ptime:1\na=rtcp:IN IP4 239.1.1.1\n", 1),
+			line:     9,
+			severity: SDPWarning,
+			message:  "a=rtcp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := ValidateSDP([]byte(tt.sdp))
+
+			for _, issue := range issues {
+				if issue.Line == tt.line && issue.Severity == tt.severity &&
+					strings.Contains(issue.Message, tt.message) {
+					return
+				}
+			}
+
+			t.Errorf("Expected %s on line %d containing %q, got %+v", tt.severity, tt.line, tt.message, issues)
+		})
+	}
+}
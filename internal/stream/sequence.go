@@ -0,0 +1,116 @@
+package stream
+
+// Limits of the sequence number validation of RFC 3550, appendix A.1
+const (
+	maxDropout  = 3000
+	maxMisorder = 100
+	seqMod      = 1 << 16
+)
+
+// SequenceUpdate tells how a packet relates to the sequence numbers seen
+// before
+type SequenceUpdate int
+
+const (
+	// SequenceInOrder is a packet after the highest sequence number so far,
+	// with a permissible gap
+	SequenceInOrder SequenceUpdate = iota
+
+	// SequenceReordered is a packet up to maxMisorder behind the highest
+	// sequence number, late or duplicated
+	SequenceReordered
+
+	// SequenceDropped is a packet after a large jump of the sequence number.
+	// It is not counted until the next packet confirms the jump.
+	SequenceDropped
+
+	// SequenceReset is the first packet, or a confirmed jump taken as a
+	// restart of the sender. The statistics start over with it.
+	SequenceReset
+)
+
+// SequenceStats holds the sequence statistics of a source
+type SequenceStats struct {
+	// ExtendedHighest is the highest sequence number received, with the
+	// wrap-arounds of the 16 bit sequence number counted in the upper bits
+	ExtendedHighest uint64
+	Expected        uint64
+	Received        uint64
+
+	// Lost is negative if duplicates were received
+	Lost      int64
+	Reordered uint64
+}
+
+// SequenceTracker follows the sequence numbers of a source as in RFC 3550,
+// appendix A.1. Counting the wrap-arounds keeps the expected and lost packet
+// counts correct for observations of any length.
+type SequenceTracker struct {
+	started   bool
+	maxSeq    uint16
+	cycles    uint64
+	baseSeq   uint64
+	badSeq    uint32
+	received  uint64
+	reordered uint64
+}
+
+func (t *SequenceTracker) init(seq uint16) {
+	*t = SequenceTracker{
+		started: true,
+		maxSeq:  seq,
+		baseSeq: uint64(seq),
+		badSeq:  seqMod + 1,
+	}
+}
+
+// Update accounts a packet with the given sequence number
+func (t *SequenceTracker) Update(seq uint16) SequenceUpdate {
+	result := SequenceInOrder
+
+	if !t.started {
+		t.init(seq)
+		result = SequenceReset
+	} else if delta := seq - t.maxSeq; delta < maxDropout {
+		if seq < t.maxSeq {
+			t.cycles += seqMod
+		}
+
+		t.maxSeq = seq
+	} else if delta <= seqMod-maxMisorder {
+		// Two sequential packets after a large jump are taken as a restart
+		// of the sender
+		if uint32(seq) != t.badSeq {
+			t.badSeq = (uint32(seq) + 1) & (seqMod - 1)
+			return SequenceDropped
+		}
+
+		t.init(seq)
+		result = SequenceReset
+	} else {
+		t.reordered++
+		result = SequenceReordered
+	}
+
+	t.received++
+
+	return result
+}
+
+// Stats returns the statistics since the first packet or the last restart
+func (t *SequenceTracker) Stats() SequenceStats {
+	if !t.started {
+		return SequenceStats{}
+	}
+
+	highest := t.cycles + uint64(t.maxSeq)
+	expected := highest - t.baseSeq + 1
+
+	return SequenceStats{
+		ExtendedHighest: highest,
+		Expected:        expected,
+		Received:        t.received,
+		Lost:            int64(expected) - int64(t.received),
+		Reordered:       t.reordered,
+	}
+}
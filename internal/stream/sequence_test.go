@@ -0,0 +1,72 @@
+package stream
+
+import "testing"
+
+func TestSequenceTrackerWrapAround(t *testing.T) {
+	var s SequenceTracker
+
+	// Three wrap-arounds of the 16 bit sequence number, leaving out every
+	// 1000th packet
+	const n = 3*seqMod + 10
+
+	for i := range n {
+		if i%1000 == 999 {
+			continue
+		}
+
+		s.Update(uint16(65000 + i))
+	}
+
+	got := s.Stats()
+
+	if want := uint64(65000 + n - 1); got.ExtendedHighest != want {
+		t.Errorf("extended highest sequence number = %d, want %d", got.ExtendedHighest, want)
+	}
+
+	if got.Expected != n || got.Lost != n/1000 || got.Reordered != 0 {
+		t.Errorf("got %d expected, %d lost, %d reordered; want %d, %d, 0", got.Expected, got.Lost, got.Reordered, n, n/1000)
+	}
+}
+
+func TestSequenceTrackerReorder(t *testing.T) {
+	var s SequenceTracker
+
+	// 65535 arrives after 0, across the wrap-around, and 2 is duplicated
+	for _, seq := range []uint16{65533, 65534, 0, 65535, 1, 2, 2, 3} {
+		s.Update(seq)
+	}
+
+	got := s.Stats()
+
+	if got.ExtendedHighest != seqMod+3 || got.Expected != 7 || got.Lost != -1 || got.Reordered != 1 {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestSequenceTrackerRestart(t *testing.T) {
+	var s SequenceTracker
+
+	for i := range 100 {
+		s.Update(uint16(i))
+	}
+
+	// A single packet far off is dropped
+	if got := s.Update(40000); got != SequenceDropped {
+		t.Errorf("Update() after a jump = %d, want SequenceDropped", got)
+	}
+
+	if got := s.Update(100); got != SequenceInOrder {
+		t.Errorf("Update() = %d, want SequenceInOrder", got)
+	}
+
+	// Two sequential packets after a jump restart the statistics
+	s.Update(30000)
+
+	if got := s.Update(30001); got != SequenceReset {
+		t.Errorf("Update() after a confirmed jump = %d, want SequenceReset", got)
+	}
+
+	if got := s.Stats(); got.Expected != 1 || got.Received != 1 || got.ExtendedHighest != 30001 {
+		t.Errorf("got %+v after a restart", got)
+	}
+}
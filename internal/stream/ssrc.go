@@ -0,0 +1,183 @@
+package stream
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ssrcTimeout is how long an SSRC is remembered after its last packet
+	ssrcTimeout = 10 * time.Second
+
+	// maxFeedSSRCs bounds the SSRCs remembered per feed, so a sender
+	// changing its SSRC with every packet does not grow it without end
+	maxFeedSSRCs = 64
+)
+
+// ssrcUse is an SSRC seen from a sender on a source of a feed
+type ssrcUse struct {
+	ssrc   uint32
+	source int
+	sender netip.Addr
+}
+
+// SSRCUser is a sender that sends a source of a stream with an SSRC
+type SSRCUser struct {
+	StreamID string
+	Source   int
+	Sender   netip.Addr
+	LastSeen time.Time
+}
+
+// SSRCCollision is an SSRC used by several senders on the streams received.
+// The sources of a redundant stream may share their SSRC; two senders on
+// the same source or on different streams must not.
+type SSRCCollision struct {
+	SSRC  uint32
+	Users []SSRCUser
+}
+
+// senderAddr returns the address of the sender of a packet, or the zero
+// address if it is not a UDP packet
+func senderAddr(src net.Addr) netip.Addr {
+	if addr, ok := src.(*net.UDPAddr); ok {
+		return addr.AddrPort().Addr().Unmap()
+	}
+
+	return netip.Addr{}
+}
+
+// addSSRC remembers the SSRC of a packet. The caller must hold the mutex.
+func (f *rtpFeed) addSSRC(i int, ssrc uint32, src net.Addr, arrival time.Time) {
+	if f.ssrcs == nil {
+		f.ssrcs = make(map[ssrcUse]time.Time)
+	}
+
+	use := ssrcUse{ssrc: ssrc, source: i, sender: senderAddr(src)}
+
+	if _, ok := f.ssrcs[use]; ok || len(f.ssrcs) < maxFeedSSRCs {
+		f.ssrcs[use] = arrival
+	}
+}
+
+// collides reports whether two users of an SSRC collide: different senders
+// on the same source, or on different streams
+func (a SSRCUser) collides(b SSRCUser) bool {
+	if a.Sender == b.Sender {
+		return false
+	}
+
+	return a.StreamID != b.StreamID || a.Source == b.Source
+}
+
+// ssrcCollisions returns the SSRCs whose users collide, sorted by SSRC
+func ssrcCollisions(bySSRC map[uint32][]SSRCUser) []SSRCCollision {
+	var collisions []SSRCCollision
+
+	for ssrc, users := range bySSRC {
+		collision := false
+
+		for i := range users {
+			for j := i + 1; j < len(users); j++ {
+				collision = collision || users[i].collides(users[j])
+			}
+		}
+
+		if !collision {
+			continue
+		}
+
+		slices.SortFunc(users, func(a, b SSRCUser) int {
+			return cmp.Or(
+				strings.Compare(a.StreamID, b.StreamID),
+				cmp.Compare(a.Source, b.Source),
+				a.Sender.Compare(b.Sender))
+		})
+
+		collisions = append(collisions, SSRCCollision{SSRC: ssrc, Users: users})
+	}
+
+	slices.SortFunc(collisions, func(a, b SSRCCollision) int {
+		return cmp.Compare(a.SSRC, b.SSRC)
+	})
+
+	return collisions
+}
+
+// SSRCCollisions returns the SSRCs used by several senders in the packets
+// of the streams received in the last ssrcTimeout, by views or background
+// statistics. They confuse some receivers and usually come from devices
+// with cloned configurations.
+func (m *Manager) SSRCCollisions(now time.Time) []SSRCCollision {
+	m.feedMutex.Lock()
+	feeds := make([]*rtpFeed, 0, len(m.feeds))
+	for _, f := range m.feeds {
+		feeds = append(feeds, f)
+	}
+	m.feedMutex.Unlock()
+
+	// Several feeds of a stream, joined on other interfaces, see the same
+	// senders
+	type key struct {
+		ssrcUse
+		streamID string
+	}
+
+	seen := make(map[key]SSRCUser)
+
+	for _, f := range feeds {
+		f.mutex.Lock()
+		uses := make(map[ssrcUse]time.Time, len(f.ssrcs))
+		for use, t := range f.ssrcs {
+			if now.Sub(t) > ssrcTimeout {
+				delete(f.ssrcs, use)
+				continue
+			}
+
+			uses[use] = t
+		}
+		f.mutex.Unlock()
+
+		for use, t := range uses {
+			k := key{ssrcUse: use, streamID: f.streamID}
+
+			if u, ok := seen[k]; !ok || t.After(u.LastSeen) {
+				seen[k] = SSRCUser{StreamID: f.streamID, Source: use.source, Sender: use.sender, LastSeen: t}
+			}
+		}
+	}
+
+	bySSRC := make(map[uint32][]SSRCUser)
+	for k, u := range seen {
+		bySSRC[k.ssrc] = append(bySSRC[k.ssrc], u)
+	}
+
+	return ssrcCollisions(bySSRC)
+}
+
+// CollisionLabel describes the users of a colliding SSRC by the names of
+// their streams and their senders
+func (m *Manager) CollisionLabel(c SSRCCollision) string {
+	labels := make([]string, 0, len(c.Users))
+
+	for _, u := range c.Users {
+		name := u.StreamID
+		if s, ok := m.GetStream(u.StreamID); ok {
+			name = s.Name()
+		}
+
+		sender := "unknown sender"
+		if u.Sender.IsValid() {
+			sender = u.Sender.String()
+		}
+
+		labels = append(labels, fmt.Sprintf("%s source %d from %s", name, u.Source+1, sender))
+	}
+
+	return fmt.Sprintf("SSRC %08x used by %s", c.SSRC, strings.Join(labels, ", "))
+}
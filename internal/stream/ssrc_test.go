@@ -0,0 +1,69 @@
+package stream
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestSSRCCollisions(t *testing.T) {
+	a := netip.MustParseAddr("10.0.0.1")
+	b := netip.MustParseAddr("10.0.0.2")
+
+	tests := []struct {
+		name  string
+		users []SSRCUser
+		want  bool
+	}{
+		{"redundant legs", []SSRCUser{{StreamID: "s1", Source: 0, Sender: a}, {StreamID: "s1", Source: 1, Sender: b}}, false},
+		{"same sender, two streams", []SSRCUser{{StreamID: "s1", Sender: a}, {StreamID: "s2", Sender: a}}, false},
+		{"two senders, one source", []SSRCUser{{StreamID: "s1", Sender: a}, {StreamID: "s1", Sender: b}}, true},
+		{"two senders, two streams", []SSRCUser{{StreamID: "s1", Sender: a}, {StreamID: "s2", Sender: b}}, true},
+	}
+
+	for _, tt := range tests {
+		got := ssrcCollisions(map[uint32][]SSRCUser{1: tt.users})
+		if (len(got) > 0) != tt.want {
+			t.Errorf("%s: got %v, want collision %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestManagerSSRCCollisions(t *testing.T) {
+	m := NewManager(t.Context(), nil)
+	defer m.Close()
+
+	now := time.Unix(1000, 0)
+
+	f1 := &rtpFeed{streamID: "s1"}
+	f2 := &rtpFeed{streamID: "s2"}
+
+	f1.addSSRC(0, 0x1234, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1)}, now)
+	f2.addSSRC(0, 0x1234, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2)}, now)
+	f2.addSSRC(0, 0x5678, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2)}, now)
+
+	m.feedMutex.Lock()
+	m.feeds["f1"] = f1
+	m.feeds["f2"] = f2
+	m.feedMutex.Unlock()
+
+	got := m.SSRCCollisions(now.Add(time.Second))
+	if len(got) != 1 || got[0].SSRC != 0x1234 || len(got[0].Users) != 2 || got[0].Users[0].StreamID != "s1" {
+		t.Fatalf("got %+v, want a collision of SSRC 1234 between s1 and s2", got)
+	}
+
+	if label := m.CollisionLabel(got[0]); label != "SSRC 00001234 used by s1 source 1 from 10.0.0.1, s2 source 1 from 10.0.0.2" {
+		t.Errorf("label = %q", label)
+	}
+
+	// SSRCs not seen for a while are forgotten
+	if got := m.SSRCCollisions(now.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("got %+v after the timeout, want none", got)
+	}
+
+	m.feedMutex.Lock()
+	delete(m.feeds, "f1")
+	delete(m.feeds, "f2")
+	m.feedMutex.Unlock()
+}
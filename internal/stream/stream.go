@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/holoplot/sdp"
@@ -26,6 +27,10 @@ const (
 	ContentTypeUndefined ContentType = "Undefined"
 	ContentTypePCM16     ContentType = "PCM16"
 	ContentTypePCM24     ContentType = "PCM24"
+
+	// Compressed audio, whose packets are counted but not decoded
+	ContentTypeOpus ContentType = "Opus"
+	ContentTypeAAC  ContentType = "AAC"
 )
 
 func (d DiscoveryMethod) String() string {
@@ -39,10 +44,122 @@ type StreamSource struct {
 	TTL                uint8
 	FramesPerPacket    uint32
 
+	// PacketTime is declared with a=ptime, or derived from a=framecount if
+	// absent, zero if neither is known
+	PacketTime time.Duration
+
 	ClockDomain    string
 	ReferenceClock string
 	MediaClock     string
 	SyncTime       uint32
+
+	// SourceSpecific is set if SenderAddress is declared with
+	// a=source-filter, so the group is joined source-specific
+	SourceSpecific bool
+
+	// RTCPPort and RTCPAddress are declared with a=rtcp, zero and nil if
+	// absent
+	RTCPPort    uint16
+	RTCPAddress net.IP
+
+	// RTCPMux is set by a=rtcp-mux, for RTCP sent on the RTP port
+	RTCPMux bool
+}
+
+// joinSource returns the source to join the group of the source from, nil
+// for an any-source join
+func (s StreamSource) joinSource() net.IP {
+	if s.SourceSpecific {
+		return s.SenderAddress
+	}
+
+	return nil
+}
+
+// MediaClockOffset returns the offset of the RTP timestamps to the media
+// clock declared with a=mediaclk:direct=<offset>. For PTP referenced
+// sources, the media clock counts samples since the PTP epoch, so RTP
+// timestamps minus the offset are comparable across sources and streams of
+// the same clock.
+func (s StreamSource) MediaClockOffset() (uint32, bool) {
+	value, ok := strings.CutPrefix(s.MediaClock, "direct=")
+	if !ok {
+		return 0, false
+	}
+
+	value, _, _ = strings.Cut(value, " ")
+
+	offset, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(offset), true
+}
+
+// RTCPAddr returns the address RTCP packets of the source are sent to: the
+// RTP port if multiplexed, the port and address of a=rtcp if declared, and
+// the RTP port plus one otherwise
+func (s StreamSource) RTCPAddr() *net.UDPAddr {
+	addr := &net.UDPAddr{
+		IP:   s.DestinationAddress,
+		Port: int(s.DestinationPort) + 1,
+	}
+
+	switch {
+	case s.RTCPMux:
+		addr.Port = int(s.DestinationPort)
+	case s.RTCPPort != 0:
+		addr.Port = int(s.RTCPPort)
+
+		if s.RTCPAddress != nil {
+			addr.IP = s.RTCPAddress
+		}
+	}
+
+	return addr
+}
+
+// RTCPLabel describes where RTCP packets of the source are sent to, and
+// whether that was declared in the SDP
+func (s StreamSource) RTCPLabel() string {
+	addr := s.RTCPAddr()
+
+	switch {
+	case s.RTCPMux:
+		return fmt.Sprintf("%s, multiplexed with RTP (a=rtcp-mux)", addr)
+	case s.RTCPPort != 0 && addr.Port != int(s.DestinationPort)+1:
+		return fmt.Sprintf("%s, non-standard port (a=rtcp)", addr)
+	case s.RTCPPort != 0:
+		return fmt.Sprintf("%s (a=rtcp)", addr)
+	default:
+		return addr.String()
+	}
+}
+
+// parseRTCPAttribute parses the value of an a=rtcp attribute of RFC 3605,
+// "<port> [IN IP4 <address>]"
+func parseRTCPAttribute(value string) (uint16, net.IP, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 1 && len(fields) != 4 {
+		return 0, nil, fmt.Errorf("invalid a=rtcp value %q", value)
+	}
+
+	port, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil || port == 0 {
+		return 0, nil, fmt.Errorf("invalid a=rtcp port %q", fields[0])
+	}
+
+	if len(fields) == 1 {
+		return uint16(port), nil, nil
+	}
+
+	ip := net.ParseIP(fields[3])
+	if fields[1] != "IN" || ip == nil {
+		return 0, nil, fmt.Errorf("invalid a=rtcp address %q", strings.Join(fields[1:], " "))
+	}
+
+	return uint16(port), ip, nil
 }
 
 type StreamDescription struct {
@@ -52,6 +169,17 @@ type StreamDescription struct {
 	SampleRate   uint32
 	ChannelCount uint32
 	ContentType  ContentType
+
+	// Encoding is the encoding name of the rtpmap, e.g. "L24"
+	Encoding string
+
+	// Format holds the parameters of the a=fmtp of the payload type, e.g.
+	// "stereo=1; useinbandfec=1"
+	Format string
+
+	// PayloadType is the RTP payload type of the rtpmap, or the first format
+	// of the media description without one
+	PayloadType uint8
 }
 
 func ParseSDP(b []byte) (*StreamDescription, string, error) {
@@ -99,16 +227,29 @@ func ParseSDP(b []byte) (*StreamDescription, string, error) {
 			TTL:                uint8(connection.TTL),
 			ClockDomain:        media.Attribute("clock-domain"),
 			ReferenceClock:     media.Attribute("ts-refclk"),
+			RTCPMux:            media.Flag("rtcp-mux"),
+		}
+
+		// A malformed a=rtcp is ignored, ValidateSDP reports it
+		if rtcp := media.Attribute("rtcp"); rtcp != "" {
+			source.RTCPPort, source.RTCPAddress, _ = parseRTCPAttribute(rtcp)
 		}
 
 		i, _ := strconv.Atoi(media.Attribute("framecount"))
 		source.FramesPerPacket = uint32(i)
 
+		if ms, err := strconv.ParseFloat(media.Attribute("ptime"), 64); err == nil && ms > 0 {
+			source.PacketTime = time.Duration(ms * float64(time.Millisecond))
+		}
+
 		s := media.Attribute("source-filter")
 		a := strings.Split(s, " ")
 
 		if len(a) == 6 {
-			source.SenderAddress = net.ParseIP(a[5])
+			if ip := net.ParseIP(a[5]); ip != nil {
+				source.SenderAddress = ip
+				source.SourceSpecific = true
+			}
 		}
 
 		if len(source.ClockDomain) == 0 {
@@ -120,7 +261,11 @@ func ParseSDP(b []byte) (*StreamDescription, string, error) {
 		}
 
 		mediaclk := media.Attribute("mediaclk")
-		if len(s) > 0 {
+		if len(mediaclk) == 0 {
+			mediaclk = message.Attribute("mediaclk")
+		}
+
+		if len(mediaclk) > 0 {
 			source.MediaClock = mediaclk
 
 			if i, err := strconv.Atoi(media.Attribute("sync-time")); err == nil {
@@ -131,28 +276,50 @@ func ParseSDP(b []byte) (*StreamDescription, string, error) {
 		s = media.Attribute("rtpmap")
 		a = strings.Split(s, " ")
 
-		if len(a) > 1 {
+		// All sources are decoded in the format of the first one, further
+		// media descriptions must not change it
+		if len(a) > 1 && sd.SampleRate == 0 {
+			// The number of channels may be omitted for one channel
 			b := strings.Split(a[1], "/")
-			if len(b) == 3 {
-				sd.ContentType = func(s string) ContentType {
-					switch s {
-					case "L24":
-						return ContentTypePCM24
-					default:
-						return ContentTypeUndefined
-					}
-				}(b[0])
+			if len(b) == 2 || len(b) == 3 {
+				if pt, err := strconv.ParseUint(a[0], 10, 7); err == nil {
+					sd.PayloadType = uint8(pt)
+				}
+
+				sd.Encoding = b[0]
+				sd.ContentType = contentTypeOf(b[0])
 
 				if sampleRate, err := strconv.Atoi(b[1]); err == nil {
 					sd.SampleRate = uint32(sampleRate)
 				}
 
-				if channelCount, err := strconv.Atoi(b[2]); err == nil {
-					sd.ChannelCount = uint32(channelCount)
+				sd.ChannelCount = 1
+				if len(b) == 3 {
+					if channelCount, err := strconv.Atoi(b[2]); err == nil {
+						sd.ChannelCount = uint32(channelCount)
+					}
+				}
+
+				if pt, format, ok := strings.Cut(media.Attribute("fmtp"), " "); ok && pt == a[0] {
+					sd.Format = strings.TrimSpace(format)
 				}
 			}
 		}
 
+		switch {
+		case sd.SampleRate == 0:
+		case source.PacketTime == 0 && source.FramesPerPacket > 0:
+			source.PacketTime = time.Duration(source.FramesPerPacket) * time.Second / time.Duration(sd.SampleRate)
+		case source.FramesPerPacket == 0 && source.PacketTime > 0:
+			source.FramesPerPacket = FramesIn(source.PacketTime, sd.SampleRate)
+		}
+
+		if len(sd.Sources) == 0 && sd.SampleRate == 0 && len(media.Description.Formats) > 0 {
+			if pt, err := strconv.ParseUint(media.Description.Formats[0], 10, 7); err == nil {
+				sd.PayloadType = uint8(pt)
+			}
+		}
+
 		sd.Sources = append(sd.Sources, source)
 	}
 
@@ -182,9 +349,51 @@ type Stream struct {
 	// All discovery records for this stream, in the order they were first seen.
 	Discoveries []Discovery
 
+	// rtspMutex guards host and rtspURL, which are learned after the
+	// stream was added
+	rtspMutex sync.Mutex
+
+	// host is the host name of the sender as announced via mDNS, if known.
+	host string
+
+	// rtspURL is the URL the SDP was described from, if it was described
+	// via RTSP.
+	rtspURL string
+
 	manager *Manager
 }
 
+// Host returns the host name of the sender as announced via mDNS, empty if
+// it is not known
+func (s *Stream) Host() string {
+	s.rtspMutex.Lock()
+	defer s.rtspMutex.Unlock()
+
+	return s.host
+}
+
+// RTSPURL returns the URL the SDP was described from, empty if it was not
+// described via RTSP
+func (s *Stream) RTSPURL() string {
+	s.rtspMutex.Lock()
+	defer s.rtspMutex.Unlock()
+
+	return s.rtspURL
+}
+
+// setRTSP records the URL the SDP was described from and, if not empty, the
+// host name of the sender
+func (s *Stream) setRTSP(host, url string) {
+	s.rtspMutex.Lock()
+	defer s.rtspMutex.Unlock()
+
+	if host != "" {
+		s.host = host
+	}
+
+	s.rtspURL = url
+}
+
 func (s *Stream) Name() string {
 	return s.Description.Name
 }
@@ -193,6 +402,16 @@ func (s *Stream) IDHash() string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(s.ID)))[:10]
 }
 
+// Interface returns the interface set for the receivers of the stream to
+// join on, nil if they join on all interfaces of the manager
+func (s *Stream) Interface() *net.Interface {
+	if s.manager == nil {
+		return nil
+	}
+
+	return s.manager.StreamInterface(s.IDHash())
+}
+
 // findDiscovery returns the index of a matching (method, source) record, or -1.
 func (s *Stream) findDiscovery(method DiscoveryMethod, source string) int {
 	for i, d := range s.Discoveries {
@@ -243,6 +462,34 @@ func (s *Stream) DiscoveryLabel() string {
 	return strings.Join(parts, ", ")
 }
 
+// LastAnnounced returns the time of the most recent SAP announcement, or the
+// zero time if the stream is not announced via SAP.
+func (s *Stream) LastAnnounced() time.Time {
+	var t time.Time
+	for _, d := range s.Discoveries {
+		if d.Method == DiscoveryMethodSAP && d.LastSeen.After(t) {
+			t = d.LastSeen
+		}
+	}
+	return t
+}
+
+// SenderLabel returns a name for the device sending the stream, preferring
+// the mDNS host name over the sender address from the SDP.
+func (s *Stream) SenderLabel() string {
+	if host := s.Host(); host != "" {
+		return host
+	}
+
+	for _, source := range s.Description.Sources {
+		if source.SenderAddress != nil {
+			return source.SenderAddress.String()
+		}
+	}
+
+	return "unknown"
+}
+
 // Address returns the formatted network address
 func (s *Stream) Address() string {
 	a := []string{}
@@ -254,14 +501,32 @@ func (s *Stream) Address() string {
 	return strings.Join(a, ", ")
 }
 
-// CodecInfo returns formatted codec information
+// ReceivesGroup returns whether a source of the stream is sent to addr
+func (s *Stream) ReceivesGroup(addr *net.UDPAddr) bool {
+	for _, source := range s.Description.Sources {
+		if source.DestinationAddress.Equal(addr.IP) && int(source.DestinationPort) == addr.Port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CodecInfo returns formatted codec information. Encodings that are not
+// recognized are named as the SDP declares them.
 func (s *Stream) CodecInfo() string {
 	desc := s.Description
-	if desc.ContentType != "" {
+
+	codec := string(desc.ContentType)
+	if desc.ContentType == ContentTypeUndefined && desc.Encoding != "" {
+		codec = desc.Encoding
+	}
+
+	if codec != "" {
 		if desc.SampleRate > 0 && desc.ChannelCount > 0 {
-			return fmt.Sprintf("%s %dHz %dch", desc.ContentType, desc.SampleRate, desc.ChannelCount)
+			return fmt.Sprintf("%s %dHz %dch", codec, desc.SampleRate, desc.ChannelCount)
 		}
-		return string(desc.ContentType)
+		return codec
 	}
 	return "Unknown"
 }
@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+func TestParseSDPRTCP(t *testing.T) {
+	tests := []struct {
+		name      string
+		attribute string
+		want      string
+		mux       bool
+	}{
+		{name: "default", want: "239.1.1.1:5005"},
+		{name: "port", attribute: "a=rtcp:6000\n", want: "239.1.1.1:6000"},
+		{name: "port and address", attribute: "a=rtcp:6000 IN IP4 239.1.1.2\n", want: "239.1.1.2:6000"},
+		{name: "mux", attribute: "a=rtcp-mux\n", want: "239.1.1.1:5004", mux: true},
+		{name: "malformed", attribute: "a=rtcp:port\n", want: "239.1.1.1:5005"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdp := strings.Replace(validSDP, "a=ptime:1\n", "a=ptime:1\n"+tt.attribute, 1)
+
+			desc, _, err := ParseSDP([]byte(sdp))
+			if err != nil {
+				t.Fatalf("ParseSDP() failed: %v", err)
+			}
+
+			source := desc.Sources[0]
+
+			if got := source.RTCPAddr().String(); got != tt.want || source.RTCPMux != tt.mux {
+				t.Errorf("RTCP on %s, mux %v; want %s, mux %v", got, source.RTCPMux, tt.want, tt.mux)
+			}
+		})
+	}
+}
+
+func TestIsRTCP(t *testing.T) {
+	for _, tt := range []struct {
+		payload []byte
+		want    bool
+	}{
+		{[]byte{0x80, 200}, true}, // SR
+		{[]byte{0x81, 201}, true}, // RR
+		{[]byte{0x80, 98}, false}, // RTP, payload type 98
+		{[]byte{0x80, 226}, false},
+		{[]byte{0x80}, false},
+	} {
+		if got := isRTCP(tt.payload); got != tt.want {
+			t.Errorf("isRTCP(% x) = %v, want %v", tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestParseSDPSourceFilter(t *testing.T) {
+	desc, _, err := ParseSDP([]byte(validSDP))
+	if err != nil {
+		t.Fatalf("ParseSDP() failed: %v", err)
+	}
+
+	if source := desc.Sources[0]; source.SourceSpecific || source.joinSource() != nil {
+		t.Errorf("source without a=source-filter is joined from %s", source.joinSource())
+	}
+
+	sdp := strings.Replace(validSDP, "a=ptime:1\n", "a=ptime:1\na=source-filter: incl IN IP4 239.1.1.1 192.168.1.20\n", 1)
+
+	if desc, _, err = ParseSDP([]byte(sdp)); err != nil {
+		t.Fatalf("ParseSDP() failed: %v", err)
+	}
+
+	if source := desc.Sources[0]; !source.SourceSpecific || !source.joinSource().Equal(net.IPv4(192, 168, 1, 20)) {
+		t.Errorf("source with a=source-filter is joined from %s", source.joinSource())
+	}
+}
+
+func TestParseSDPThreeSources(t *testing.T) {
+	sdp := validSDP +
+		"m=audio 5004 RTP/AVP 98\nc=IN IP4 239.1.1.2/32\na=rtpmap:98 L24/48000/2\n" +
+		"m=audio 5006 RTP/AVP 99\nc=IN IP4 239.1.1.3/32\na=rtpmap:99 L16/96000/8\n"
+
+	desc, _, err := ParseSDP([]byte(sdp))
+	if err != nil {
+		t.Fatalf("ParseSDP() failed: %v", err)
+	}
+
+	if len(desc.Sources) != 3 || !desc.Sources[2].DestinationAddress.Equal(net.IPv4(239, 1, 1, 3)) {
+		t.Fatalf("got sources %+v, want three", desc.Sources)
+	}
+
+	if desc.ContentType != ContentTypePCM24 || desc.SampleRate != 48000 || desc.ChannelCount != 2 {
+		t.Errorf("format %v %d Hz %d ch is not the one of the first source", desc.ContentType, desc.SampleRate, desc.ChannelCount)
+	}
+}
+
+func TestReceiverError(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	if err := receiverError(1, map[int]error{0: errFirst}); err != nil {
+		t.Errorf("receiverError() = %v with a received source", err)
+	}
+
+	if err := receiverError(0, map[int]error{2: errSecond, 1: errFirst}); err != errFirst {
+		t.Errorf("receiverError() = %v, want the error of the first source", err)
+	}
+
+	if err := receiverError(0, nil); err != nil {
+		t.Errorf("receiverError() = %v without sources", err)
+	}
+}
+
+func TestParseSDPPayloadType(t *testing.T) {
+	desc, _, err := ParseSDP([]byte(validSDP))
+	if err != nil || desc.PayloadType != 98 || desc.Encoding != "L24" || desc.Sources[0].PacketTime != time.Millisecond {
+		t.Errorf("got payload type %d, encoding %q, packet time %s, %v; want 98, L24, 1ms", desc.PayloadType, desc.Encoding, desc.Sources[0].PacketTime, err)
+	}
+
+	// A static payload type without rtpmap
+	sdp := strings.Replace(strings.Replace(validSDP, "RTP/AVP 98", "RTP/AVP 11", 1), "a=rtpmap:98 L24/48000/2\n", "", 1)
+
+	if desc, _, err := ParseSDP([]byte(sdp)); err != nil || desc.PayloadType != 11 {
+		t.Errorf("got payload type %d, %v; want 11", desc.PayloadType, err)
+	}
+}
+
+func TestDecodeSamplesPayloadType(t *testing.T) {
+	r := &RTPReceiver{
+		stream: &Stream{
+			Description: StreamDescription{
+				ContentType:  ContentTypePCM16,
+				ChannelCount: 1,
+				PayloadType:  98,
+			},
+		},
+	}
+
+	packet := &rtp.Packet{
+		Header:  rtp.Header{PayloadType: 98},
+		Payload: []byte{0x12, 0x34},
+	}
+
+	if samples, err := r.DecodeSamples(nil, packet); err != nil || len(samples) != 1 || samples[0] != 0x12340000 {
+		t.Errorf("DecodeSamples() = %v, %v; want one sample of 0x12340000", samples, err)
+	}
+
+	packet.PayloadType = 97
+
+	if _, err := r.DecodeSamples(nil, packet); !errors.Is(err, ErrPayloadTypeMismatch) {
+		t.Errorf("DecodeSamples() of another payload type = %v, want ErrPayloadTypeMismatch", err)
+	}
+}
@@ -0,0 +1,226 @@
+package stream
+
+import (
+	"log/slog"
+	"net"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/holoplot/go-multicast/pkg/multicast"
+)
+
+// Join is a multicast group joined on an interface by stream receivers
+type Join struct {
+	Group     *net.UDPAddr
+	Source    net.IP // nil for an any-source join
+	Interface string
+	Joined    time.Time
+
+	Packets    uint64
+	LastPacket time.Time // zero if no packet was received
+
+	// Interruptions counts the gaps in the delivery of the group on the
+	// interface, the last of which is LastInterruption
+	Interruptions    uint64
+	LastInterruption Interruption
+
+	// Oversized counts the packets dropped as they were larger than the
+	// MTU of the interface
+	Oversized uint64
+}
+
+// joinCounter counts the packets received on an interface
+type joinCounter struct {
+	packets    atomic.Uint64
+	lastPacket atomic.Int64 // in nanoseconds since the epoch
+
+	// wireBytes counts the bytes of the packets on the link, see
+	// wireOverhead
+	wireBytes atomic.Uint64
+
+	// oversized counts the packets dropped as they did not fit the
+	// receive buffer
+	oversized atomic.Uint64
+
+	gaps gapDetector
+}
+
+// subscription receives the packets sent to a multicast group for a
+// receiver, joined either source-specific or any-source
+type subscription struct {
+	asm   *multicast.Consumer
+	group *groupConsumer
+
+	addr   *net.UDPAddr
+	source net.IP
+	ifis   []*net.Interface
+	joined time.Time
+
+	// counters holds a counter for each interface index
+	counters map[int]*joinCounter
+}
+
+// subscribe receives the packets sent to addr on the given interfaces. With
+// a source, the group is joined source-specific, falling back to an
+// any-source join if that fails. Where sockets cannot be set up for batched
+// reads, the multicast library joins any-source instead. The payload passed
+// to cb may be reused once it returns.
+func (m *Manager) subscribe(addr *net.UDPAddr, source net.IP, ifis []*net.Interface, cb packetCallback) (*subscription, error) {
+	sub := &subscription{
+		addr:     addr,
+		ifis:     ifis,
+		joined:   time.Now(),
+		counters: make(map[int]*joinCounter, len(ifis)),
+	}
+
+	for _, ifi := range ifis {
+		sub.counters[ifi.Index] = &joinCounter{}
+	}
+
+	overhead := wireOverhead(addr)
+
+	counted := func(ifi *net.Interface, src net.Addr, payload []byte, arrival time.Time) {
+		if c := sub.counters[ifi.Index]; c != nil {
+			c.packets.Add(1)
+			c.wireBytes.Add(uint64(len(payload)) + overhead)
+			c.lastPacket.Store(arrival.UnixNano())
+
+			if start, lost, ok := c.gaps.add(payload, arrival); ok {
+				m.interruption(sub, ifi, start, arrival, lost)
+			}
+		}
+
+		cb(ifi, src, payload, arrival)
+	}
+
+	oversized := func(ifi *net.Interface) {
+		if c := sub.counters[ifi.Index]; c != nil {
+			c.oversized.Add(1)
+		}
+	}
+
+	c, err := newGroupConsumer(addr, source, ifis, counted, oversized)
+	if err == nil {
+		sub.group = c
+		sub.source = source
+	} else if source != nil {
+		slog.Warn("Source-specific join failed, joining any-source", "group", addr, "source", source, "error", err)
+	}
+
+	if sub.group == nil {
+		c, err := multicast.NewConsumer(addr, ifis, func(ifi *net.Interface, src net.Addr, payload []byte) {
+			counted(ifi, src, payload, time.Now())
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sub.asm = c
+	}
+
+	m.mutex.Lock()
+	m.subscriptions[sub] = struct{}{}
+	m.mutex.Unlock()
+
+	return sub, nil
+}
+
+// interruption records an interruption of the delivery of a subscription
+// on an interface and passes it to the callbacks
+func (m *Manager) interruption(sub *subscription, ifi *net.Interface, start, end time.Time, lost int) {
+	i := Interruption{
+		Group:     sub.addr,
+		Interface: ifi.Name,
+		Start:     start,
+		End:       end,
+		Lost:      lost,
+	}
+
+	for index, c := range sub.counters {
+		if index != ifi.Index && time.Unix(0, c.lastPacket.Load()).After(start.Add(interruptionThreshold)) {
+			i.Elsewhere = true
+		}
+	}
+
+	sub.counters[ifi.Index].gaps.record(i)
+	m.interrupted(i)
+}
+
+// unsubscribe stops receiving the packets of a subscription. Subscriptions
+// already left when the manager was closed are skipped.
+func (m *Manager) unsubscribe(sub *subscription) {
+	m.mutex.Lock()
+	_, ok := m.subscriptions[sub]
+	delete(m.subscriptions, sub)
+	m.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if sub.asm != nil {
+		sub.asm.Close()
+	} else {
+		sub.group.Close()
+	}
+}
+
+// Joins returns the multicast groups joined by stream receivers on each
+// interface, sorted by interface and group. Groups joined by several
+// receivers are listed once, since all of them receive the same packets.
+func (m *Manager) Joins() []Join {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	joins := make(map[string]*Join)
+
+	for sub := range m.subscriptions {
+		for _, ifi := range sub.ifis {
+			c := sub.counters[ifi.Index]
+			key := ifi.Name + " " + sub.addr.String() + " " + sub.source.String()
+
+			j, ok := joins[key]
+			if !ok {
+				j = &Join{
+					Group:     sub.addr,
+					Source:    sub.source,
+					Interface: ifi.Name,
+					Joined:    sub.joined,
+				}
+				joins[key] = j
+			}
+
+			if sub.joined.Before(j.Joined) {
+				j.Joined = sub.joined
+			}
+
+			j.Packets = max(j.Packets, c.packets.Load())
+			j.Oversized = max(j.Oversized, c.oversized.Load())
+
+			if n, last := c.gaps.stats(); n > j.Interruptions {
+				j.Interruptions = n
+				j.LastInterruption = last
+			}
+
+			if last := c.lastPacket.Load(); last != 0 && time.Unix(0, last).After(j.LastPacket) {
+				j.LastPacket = time.Unix(0, last)
+			}
+		}
+	}
+
+	result := make([]Join, 0, len(joins))
+	for _, j := range joins {
+		result = append(result, *j)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Interface != result[b].Interface {
+			return result[a].Interface < result[b].Interface
+		}
+
+		return result[a].Group.String() < result[b].Group.String()
+	})
+
+	return result
+}
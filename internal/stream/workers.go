@@ -0,0 +1,215 @@
+package stream
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/memory"
+)
+
+const (
+	// feedQueueSize is the number of packets queued per stream, about half
+	// a second of a stream with two sources at a packet time of 1 ms
+	feedQueueSize = 1024
+
+	// workBatch is the number of packets a worker processes of one stream
+	// before it turns to the next, so a busy stream cannot starve others
+	workBatch = 32
+)
+
+// queueMemory accounts the buffers of queued packets
+var queueMemory = memory.NewAccount("packet queues")
+
+// payloadPool holds the buffers packets are copied into while queued
+var payloadPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1500)
+		return &b
+	},
+}
+
+// queuedPacket is a received packet waiting for a worker
+type queuedPacket struct {
+	source  int
+	ifi     *net.Interface
+	src     net.Addr
+	buf     *[]byte
+	arrival time.Time
+}
+
+// workerPool processes the packets of all feeds on a bounded number of
+// goroutines. The socket goroutines only queue the packets of a feed; a feed
+// with queued packets is scheduled once and taken by one worker at a time,
+// so its subscribers are called in the order the packets were queued.
+type workerPool struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	ready  []*rtpFeed
+	closed bool
+
+	// drops counts the packets dropped by all feeds as their queue was full
+	drops atomic.Uint64
+
+	wg sync.WaitGroup
+}
+
+func newWorkerPool(workers int) *workerPool {
+	p := &workerPool{}
+	p.cond = sync.NewCond(&p.mutex)
+
+	for range workers {
+		p.wg.Add(1)
+		go p.run()
+	}
+
+	return p
+}
+
+// schedule adds a feed to the feeds waiting for a worker. The packets of the
+// feed are dropped once the pool is closed.
+func (p *workerPool) schedule(f *rtpFeed) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		f.discard()
+		return
+	}
+
+	p.ready = append(p.ready, f)
+	p.cond.Signal()
+}
+
+// next waits for a scheduled feed and returns nil once the pool is closed
+func (p *workerPool) next() *rtpFeed {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for len(p.ready) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+
+	if p.closed {
+		return nil
+	}
+
+	f := p.ready[0]
+	p.ready[0] = nil
+	p.ready = p.ready[1:]
+
+	return f
+}
+
+func (p *workerPool) run() {
+	defer p.wg.Done()
+
+	for {
+		f := p.next()
+		if f == nil {
+			return
+		}
+
+		f.process()
+
+		// Packets queued while the feed was processed did not schedule it
+		// again, so it is put back at the end of the line
+		f.scheduled.Store(false)
+
+		if len(f.queue) > 0 && f.scheduled.CompareAndSwap(false, true) {
+			p.schedule(f)
+		}
+	}
+}
+
+// Close stops the workers and waits for them to return. Packets still
+// queued are dropped.
+func (p *workerPool) Close() {
+	p.mutex.Lock()
+	p.closed = true
+	for _, f := range p.ready {
+		f.discard()
+	}
+	p.ready = nil
+	p.cond.Broadcast()
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+}
+
+// enqueue copies a packet of source i into the queue of the feed and
+// schedules the feed. It is called on the socket goroutines; the packet is
+// dropped and counted if the queue is full or the memory budget exhausted.
+func (f *rtpFeed) enqueue(i int, ifi *net.Interface, rtcpMux bool, src net.Addr, payload []byte, arrival time.Time) {
+	if rtcpMux && isRTCP(payload) {
+		return
+	}
+
+	buf := payloadPool.Get().(*[]byte)
+	*buf = append((*buf)[:0], payload...)
+
+	if !queueMemory.Reserve(cap(*buf)) {
+		payloadPool.Put(buf)
+		f.drop()
+
+		return
+	}
+
+	select {
+	case f.queue <- queuedPacket{source: i, ifi: ifi, src: src, buf: buf, arrival: arrival}:
+	default:
+		f.release(buf)
+		f.drop()
+
+		return
+	}
+
+	if f.scheduled.CompareAndSwap(false, true) {
+		f.pool.schedule(f)
+	}
+}
+
+// drop counts a packet that was not queued
+func (f *rtpFeed) drop() {
+	f.drops.Add(1)
+	f.pool.drops.Add(1)
+}
+
+// release returns the buffer of a queued packet to the pool and the budget
+func (f *rtpFeed) release(buf *[]byte) {
+	queueMemory.Release(cap(*buf))
+	payloadPool.Put(buf)
+}
+
+// process passes up to workBatch queued packets to the subscribers
+func (f *rtpFeed) process() {
+	for range workBatch {
+		select {
+		case p := <-f.queue:
+			f.receive(p.source, p.ifi, p.src, *p.buf, p.arrival)
+			f.release(p.buf)
+		default:
+			return
+		}
+	}
+}
+
+// discard drops the queued packets
+func (f *rtpFeed) discard() {
+	for {
+		select {
+		case p := <-f.queue:
+			f.release(p.buf)
+		default:
+			return
+		}
+	}
+}
+
+// QueueDrops returns the number of packets of all streams dropped because
+// the receivers of a stream did not keep up or the memory budget was
+// exhausted
+func (m *Manager) QueueDrops() uint64 {
+	return m.workers.drops.Load()
+}
@@ -0,0 +1,103 @@
+package stream
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/v2"
+)
+
+func testFeed(pool *workerPool, fn func(int, net.Addr, *rtp.Packet, time.Time)) *rtpFeed {
+	return &rtpFeed{
+		pool:  pool,
+		queue: make(chan queuedPacket, feedQueueSize),
+		subscribers: []*feedSubscriber{{fn: func(i int, _ *net.Interface, src net.Addr, p *rtp.Packet, arrival time.Time) {
+			fn(i, src, p, arrival)
+		}}},
+	}
+}
+
+func TestWorkerPoolOrder(t *testing.T) {
+	pool := newWorkerPool(4)
+	defer pool.Close()
+
+	const packets = 500
+
+	var (
+		mutex sync.Mutex
+		got   [2][]uint16
+		done  sync.WaitGroup
+	)
+
+	done.Add(2 * packets)
+
+	feeds := make([]*rtpFeed, 2)
+	for i := range feeds {
+		feeds[i] = testFeed(pool, func(_ int, _ net.Addr, p *rtp.Packet, _ time.Time) {
+			mutex.Lock()
+			got[i] = append(got[i], p.SequenceNumber)
+			mutex.Unlock()
+
+			done.Done()
+		})
+	}
+
+	buf := make([]byte, 12)
+
+	for seq := range uint16(packets) {
+		_, _ = (&rtp.Packet{Header: rtp.Header{Version: 2, SequenceNumber: seq}}).MarshalTo(buf)
+
+		for _, f := range feeds {
+			// The payload is copied, so the buffer can be reused right away
+			for !f.tryEnqueue(buf) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+
+	done.Wait()
+
+	// Each feed is processed by one worker at a time, in order
+	for i := range got {
+		for seq, s := range got[i] {
+			if s != uint16(seq) {
+				t.Fatalf("feed %d received sequence number %d at position %d", i, s, seq)
+			}
+		}
+	}
+}
+
+// tryEnqueue queues a packet unless the queue is full
+func (f *rtpFeed) tryEnqueue(payload []byte) bool {
+	drops := f.drops.Load()
+	f.enqueue(0, nil, false, nil, payload, time.Now())
+
+	return f.drops.Load() == drops
+}
+
+func TestWorkerPoolDrops(t *testing.T) {
+	// Without workers, nothing is taken from the queue
+	pool := newWorkerPool(0)
+	defer pool.Close()
+
+	f := testFeed(pool, nil)
+	payload, _ := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
+
+	for range feedQueueSize + 5 {
+		f.enqueue(0, nil, false, nil, payload, time.Now())
+	}
+
+	if f.drops.Load() != 5 || pool.drops.Load() != 5 {
+		t.Errorf("dropped %d packets, %d in total; want 5", f.drops.Load(), pool.drops.Load())
+	}
+
+	// RTCP packets on a shared port are not queued
+	rtcp := []byte{0x80, 200, 0, 1}
+	f.enqueue(0, nil, true, nil, rtcp, time.Now())
+
+	if f.drops.Load() != 5 {
+		t.Errorf("RTCP packet was queued")
+	}
+}
@@ -0,0 +1,276 @@
+// Package syslog forwards alerts and stream events to a syslog server as
+// RFC 5424 messages, over UDP or TCP.
+package syslog
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+const (
+	// appName is the APP-NAME of the messages
+	appName = "rtp-monitor"
+
+	// sdID is the ID of the structured data element of the messages. 32473
+	// is the private enterprise number reserved for documentation.
+	sdID = "rtp-monitor@32473"
+
+	// defaultPort is used if the URL has no port
+	defaultPort = "514"
+
+	// dialTimeout and writeTimeout limit how long connecting to and sending
+	// to the server may take
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Facilities are the names of the supported syslog facilities and their codes
+var Facilities = map[string]int{
+	"user":   1,
+	"daemon": 3,
+	"local0": 16,
+	"local1": 17,
+	"local2": 18,
+	"local3": 19,
+	"local4": 20,
+	"local5": 21,
+	"local6": 22,
+	"local7": 23,
+}
+
+// Severities
+const (
+	severityError   = 3
+	severityWarning = 4
+	severityNotice  = 5
+	severityInfo    = 6
+)
+
+// forwarded are the event types sent to the server
+var forwarded = []string{
+	events.TypeStreamAdded, events.TypeStreamUpdated, events.TypeStreamRemoved, events.TypeAlert,
+}
+
+// Options configures a Forwarder
+type Options struct {
+	Watcher *events.Watcher
+
+	// URL is the server, udp://host[:port] or tcp://host[:port]
+	URL string
+
+	// Facility is the name of the facility of the messages
+	Facility string
+}
+
+// Forwarder sends events to a syslog server
+type Forwarder struct {
+	watcher  *events.Watcher
+	network  string
+	address  string
+	facility int
+	hostname string
+	procID   string
+	done     chan struct{}
+
+	conn net.Conn
+
+	// streams are the known streams by ID hash, to name the stream of an
+	// alert
+	streams map[string]inventory.Entry
+}
+
+// New creates a forwarder and checks its configuration
+func New(opts Options) (*Forwarder, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid syslog URL %q, must be udp://host[:port] or tcp://host[:port]", opts.URL)
+	}
+
+	facility, ok := Facilities[opts.Facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", opts.Facility)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	return &Forwarder{
+		watcher:  opts.Watcher,
+		network:  u.Scheme,
+		address:  net.JoinHostPort(u.Hostname(), port),
+		facility: facility,
+		hostname: hostname,
+		procID:   strconv.Itoa(os.Getpid()),
+		done:     make(chan struct{}),
+		streams:  make(map[string]inventory.Entry),
+	}, nil
+}
+
+// Start forwards events in the background until Stop is called, starting
+// with the streams that are already known. Connections are reestablished
+// for the next event when they fail. Errors are reported to errorFn.
+func (f *Forwarder) Start(errorFn func(error)) {
+	ch := f.watcher.SubscribeWithStreams()
+
+	go func() {
+		defer f.watcher.Unsubscribe(ch)
+
+		defer func() {
+			if f.conn != nil {
+				f.conn.Close()
+			}
+		}()
+
+		for {
+			select {
+			case <-f.done:
+				return
+			case event := <-ch:
+				if !slices.Contains(forwarded, event.Type) {
+					continue
+				}
+
+				if err := f.send(f.message(event)); err != nil {
+					errorFn(err)
+				}
+
+				f.track(event)
+			}
+		}
+	}()
+}
+
+// Stop stops forwarding events
+func (f *Forwarder) Stop() {
+	close(f.done)
+}
+
+// track updates the known streams
+func (f *Forwarder) track(event events.Event) {
+	switch event.Type {
+	case events.TypeStreamAdded, events.TypeStreamUpdated:
+		f.streams[event.IDHash] = event.Data.(inventory.Entry)
+	case events.TypeStreamRemoved:
+		delete(f.streams, event.IDHash)
+	}
+}
+
+// send sends a message, connecting first if needed. On TCP, messages are
+// framed by octet counting as in RFC 6587.
+func (f *Forwarder) send(message []byte) error {
+	if f.conn == nil {
+		conn, err := net.DialTimeout(f.network, f.address, dialTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog server: %w", err)
+		}
+
+		f.conn = conn
+	}
+
+	if f.network == "tcp" {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	_ = f.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	if _, err := f.conn.Write(message); err != nil {
+		f.conn.Close()
+		f.conn = nil
+
+		return fmt.Errorf("failed to send to syslog server: %w", err)
+	}
+
+	return nil
+}
+
+// message formats an event as RFC 5424 message, with the event type as
+// MSGID and the details as structured data
+func (f *Forwarder) message(event events.Event) []byte {
+	severity := severityInfo
+
+	var (
+		text   string
+		params [][2]string
+	)
+
+	if event.IDHash != "" {
+		params = append(params, [2]string{"id-hash", event.IDHash})
+	}
+
+	switch data := event.Data.(type) {
+	case inventory.Entry:
+		var addresses []string
+		for _, source := range data.Sources {
+			addresses = append(addresses, fmt.Sprintf("%s:%d", source.Destination, source.Port))
+		}
+
+		verb := strings.TrimPrefix(event.Type, "stream-")
+		text = fmt.Sprintf("Stream %s: %s (%s)", verb, data.Name, strings.Join(addresses, ", "))
+
+		params = append(params,
+			[2]string{"name", data.Name},
+			[2]string{"address", strings.Join(addresses, " ")},
+			[2]string{"discovery", data.Discovery})
+
+		if event.Type == events.TypeStreamRemoved {
+			severity = severityNotice
+		}
+
+	case events.Alert:
+		text = data.Message
+
+		if e, ok := f.streams[event.IDHash]; ok {
+			params = append(params, [2]string{"name", e.Name})
+		}
+
+		if data.Metric != "" {
+			params = append(params,
+				[2]string{"metric", data.Metric},
+				[2]string{"value", strconv.FormatFloat(data.Value, 'g', -1, 64)})
+		}
+
+		severity = severityWarning
+		if data.Level == "error" {
+			severity = severityError
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		f.facility*8+severity,
+		event.Time.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		f.hostname, appName, f.procID, event.Type)
+
+	b.WriteString("[" + sdID)
+	for _, p := range params {
+		fmt.Fprintf(&b, ` %s="%s"`, p[0], escapeParam(p[1]))
+	}
+	b.WriteString("] ")
+
+	b.WriteString(text)
+
+	return []byte(b.String())
+}
+
+// escapeParam escapes the characters that are special in structured data
+// parameter values
+func escapeParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
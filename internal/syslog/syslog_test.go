@@ -0,0 +1,152 @@
+package syslog
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+)
+
+var testEntry = inventory.Entry{
+	IDHash:    "71cb8481ed",
+	Name:      `Stage "Left"`,
+	Discovery: "SAP",
+	Sources: []inventory.Source{
+		{Destination: "239.1.1.1", Port: 5004},
+		{Destination: "239.2.1.1", Port: 5004},
+	},
+}
+
+var testTime = time.Date(2026, 1, 12, 10, 31, 2, 0, time.UTC)
+
+func newForwarder(t *testing.T, url string) *Forwarder {
+	t.Helper()
+
+	f, err := New(Options{URL: url, Facility: "local0"})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	f.hostname, f.procID = "monitor", "42"
+
+	return f
+}
+
+func TestNew(t *testing.T) {
+	for _, url := range []string{"", "http://host", "udp://", "syslog.example.com:514"} {
+		if _, err := New(Options{URL: url, Facility: "daemon"}); err == nil {
+			t.Errorf("New() accepted URL %q", url)
+		}
+	}
+
+	if _, err := New(Options{URL: "udp://host", Facility: "mail"}); err == nil {
+		t.Errorf("New() accepted an unsupported facility")
+	}
+
+	f := newForwarder(t, "tcp://[::1]")
+	if f.address != "[::1]:514" {
+		t.Errorf("address is %s", f.address)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	f := newForwarder(t, "udp://localhost")
+
+	event := events.Event{Type: events.TypeStreamRemoved, Time: testTime, IDHash: testEntry.IDHash, Data: testEntry}
+
+	want := `<133>1 2026-01-12T10:31:02.000000Z monitor rtp-monitor 42 stream-removed ` +
+		`[rtp-monitor@32473 id-hash="71cb8481ed" name="Stage \"Left\"" address="239.1.1.1:5004 239.2.1.1:5004" discovery="SAP"] ` +
+		`Stream removed: Stage "Left" (239.1.1.1:5004, 239.2.1.1:5004)`
+
+	if got := string(f.message(event)); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	f.track(events.Event{Type: events.TypeStreamAdded, IDHash: testEntry.IDHash, Data: testEntry})
+
+	event = events.Event{
+		Type:   events.TypeAlert,
+		Time:   testTime,
+		IDHash: testEntry.IDHash,
+		Data:   events.Alert{Level: "error", Message: "Stage Left: no packets received", Metric: "last-packet-age-s", Value: 5.5},
+	}
+
+	want = `<131>1 2026-01-12T10:31:02.000000Z monitor rtp-monitor 42 alert ` +
+		`[rtp-monitor@32473 id-hash="71cb8481ed" name="Stage \"Left\"" metric="last-packet-age-s" value="5.5"] ` +
+		`Stage Left: no packets received`
+
+	if got := string(f.message(event)); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestSendUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	f := newForwarder(t, "udp://"+conn.LocalAddr().String())
+
+	for _, message := range []string{"first", "second"} {
+		if err := f.send([]byte(message)); err != nil {
+			t.Fatalf("send() failed: %v", err)
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		b := make([]byte, 64)
+		n, _, err := conn.ReadFrom(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b[:n]) != message {
+			t.Errorf("received %q, want %q", b[:n], message)
+		}
+	}
+}
+
+func TestSendTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		b, _ := io.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	f := newForwarder(t, "tcp://"+l.Addr().String())
+
+	for _, message := range []string{"first", "second message"} {
+		if err := f.send([]byte(message)); err != nil {
+			t.Fatalf("send() failed: %v", err)
+		}
+	}
+
+	f.conn.Close()
+
+	select {
+	case got := <-received:
+		if want := "5 first14 second message"; got != want {
+			t.Errorf("received %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+}
@@ -1,9 +1,14 @@
 package theme
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"sort"
 
-// Colors defines all colors used in the application (Monokai dark theme)
-var Colors = struct {
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palette defines all colors used in the application
+type Palette struct {
 	// Table colors
 	TableHeader        lipgloss.Color
 	TableBorder        lipgloss.Color
@@ -27,7 +32,14 @@ var Colors = struct {
 	Primary   lipgloss.Color
 	Secondary lipgloss.Color
 	Highlight lipgloss.Color
-}{
+
+	// MeterGradient holds the colors of the level meters, from the lowest
+	// to the highest level
+	MeterGradient []lipgloss.Color
+}
+
+// monokai is the default dark theme
+var monokai = Palette{
 	// Table colors - Monokai dark
 	TableHeader:        lipgloss.Color("#F8F8F2"),
 	TableBorder:        lipgloss.Color("#75715E"),
@@ -51,4 +63,171 @@ var Colors = struct {
 	Primary:   lipgloss.Color("#66D9EF"),
 	Secondary: lipgloss.Color("#AE81FF"),
 	Highlight: lipgloss.Color("#FD971F"),
+
+	MeterGradient: gradients["green-red"],
+}
+
+// solarizedLight is a light theme for bright terminals
+var solarizedLight = Palette{
+	// Table colors - Solarized light
+	TableHeader:        lipgloss.Color("#FDF6E3"),
+	TableBorder:        lipgloss.Color("#93A1A1"),
+	TableRow:           lipgloss.Color("#586E75"),
+	TableRowSelected:   lipgloss.Color("#FDF6E3"),
+	TableRowSelectedBg: lipgloss.Color("#268BD2"),
+
+	// UI element colors
+	Background:     lipgloss.Color("#FDF6E3"),
+	Foreground:     lipgloss.Color("#586E75"),
+	ScrollBar:      lipgloss.Color("#93A1A1"),
+	ScrollBarThumb: lipgloss.Color("#6C71C4"),
+
+	// Status colors
+	StatusActive:   lipgloss.Color("#859900"),
+	StatusInactive: lipgloss.Color("#93A1A1"),
+	StatusError:    lipgloss.Color("#DC322F"),
+	StatusWarning:  lipgloss.Color("#B58900"),
+
+	// Accent colors
+	Primary:   lipgloss.Color("#268BD2"),
+	Secondary: lipgloss.Color("#6C71C4"),
+	Highlight: lipgloss.Color("#CB4B16"),
+
+	MeterGradient: gradients["green-red"],
+}
+
+// colorblindDark is a dark theme that avoids telling states apart by green
+// and red only, using the Okabe-Ito colors
+var colorblindDark = Palette{
+	// Table colors - Monokai dark with blue selection
+	TableHeader:        lipgloss.Color("#F8F8F2"),
+	TableBorder:        lipgloss.Color("#75715E"),
+	TableRow:           lipgloss.Color("#F8F8F2"),
+	TableRowSelected:   lipgloss.Color("#272822"),
+	TableRowSelectedBg: lipgloss.Color("#56B4E9"),
+
+	// UI element colors
+	Background:     lipgloss.Color("#272822"),
+	Foreground:     lipgloss.Color("#F8F8F2"),
+	ScrollBar:      lipgloss.Color("#75715E"),
+	ScrollBarThumb: lipgloss.Color("#56B4E9"),
+
+	// Status colors - blue for good, orange for bad
+	StatusActive:   lipgloss.Color("#56B4E9"),
+	StatusInactive: lipgloss.Color("#75715E"),
+	StatusError:    lipgloss.Color("#E69F00"),
+	StatusWarning:  lipgloss.Color("#F0E442"),
+
+	// Accent colors
+	Primary:   lipgloss.Color("#56B4E9"),
+	Secondary: lipgloss.Color("#CC79A7"),
+	Highlight: lipgloss.Color("#E69F00"),
+
+	MeterGradient: gradients["blue-orange"],
+}
+
+// colorblindLight is the light counterpart of colorblindDark
+var colorblindLight = Palette{
+	// Table colors - Solarized light with blue selection
+	TableHeader:        lipgloss.Color("#FDF6E3"),
+	TableBorder:        lipgloss.Color("#93A1A1"),
+	TableRow:           lipgloss.Color("#586E75"),
+	TableRowSelected:   lipgloss.Color("#FDF6E3"),
+	TableRowSelectedBg: lipgloss.Color("#0072B2"),
+
+	// UI element colors
+	Background:     lipgloss.Color("#FDF6E3"),
+	Foreground:     lipgloss.Color("#586E75"),
+	ScrollBar:      lipgloss.Color("#93A1A1"),
+	ScrollBarThumb: lipgloss.Color("#0072B2"),
+
+	// Status colors - blue for good, vermillion for bad
+	StatusActive:   lipgloss.Color("#0072B2"),
+	StatusInactive: lipgloss.Color("#93A1A1"),
+	StatusError:    lipgloss.Color("#D55E00"),
+	StatusWarning:  lipgloss.Color("#E69F00"),
+
+	// Accent colors
+	Primary:   lipgloss.Color("#0072B2"),
+	Secondary: lipgloss.Color("#CC79A7"),
+	Highlight: lipgloss.Color("#D55E00"),
+
+	MeterGradient: gradients["blue-orange"],
+}
+
+// gradients holds the meter gradients that can be chosen independently of
+// the theme
+var gradients = map[string][]lipgloss.Color{
+	"green-red":   {lipgloss.Color("#00FF00"), lipgloss.Color("#FF0000")},
+	"blue-orange": {lipgloss.Color("#0072B2"), lipgloss.Color("#56B4E9"), lipgloss.Color("#E69F00")},
+	"viridis": {
+		lipgloss.Color("#440154"), lipgloss.Color("#31688E"),
+		lipgloss.Color("#35B779"), lipgloss.Color("#FDE725"),
+	},
+}
+
+// GradientFromTheme is the gradient name that keeps the gradient of the theme
+const GradientFromTheme = "theme"
+
+var themes = map[string]Palette{
+	"monokai":         monokai,
+	"solarized-light": solarizedLight,
+
+	"colorblind-dark":  colorblindDark,
+	"colorblind-light": colorblindLight,
+}
+
+// Colors holds the colors of the active theme (Monokai dark by default)
+var Colors = monokai
+
+// Names returns the names of all available themes, sorted
+func Names() []string {
+	return sortedKeys(themes)
+}
+
+// GradientNames returns the names of all available meter gradients, sorted
+func GradientNames() []string {
+	return sortedKeys(gradients)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Set activates the theme with the given name. Styles created before need to
+// be recreated to pick up the new colors.
+func Set(name string) error {
+	p, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q", name)
+	}
+
+	Colors = p
+
+	return nil
+}
+
+// SetGradient replaces the meter gradient of the active theme.
+// GradientFromTheme keeps the gradient of the theme, so this has to be
+// called after Set.
+func SetGradient(name string) error {
+	if name == GradientFromTheme {
+		return nil
+	}
+
+	g, ok := gradients[name]
+	if !ok {
+		return fmt.Errorf("unknown meter gradient %q", name)
+	}
+
+	Colors.MeterGradient = g
+
+	return nil
 }
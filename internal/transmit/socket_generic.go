@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package transmit
+
+import (
+	"errors"
+	"net"
+)
+
+// setSocketOptions is not implemented on this platform
+func setSocketOptions(conn *net.UDPConn, laddr *net.UDPAddr, ttl, dscp int) error {
+	return errors.New("not supported on this platform")
+}
@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package transmit
+
+import (
+	"net"
+	"syscall"
+)
+
+// setSocketOptions sets the multicast TTL and the DSCP of the packets, and
+// the interface to send them on if laddr is given
+func setSocketOptions(conn *net.UDPConn, laddr *net.UDPAddr, ttl, dscp int) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+
+	err = rc.Control(func(fd uintptr) {
+		if laddr != nil {
+			var addr [4]byte
+			copy(addr[:], laddr.IP.To4())
+
+			if sockErr = syscall.SetsockoptInet4Addr(int(fd), syscall.IPPROTO_IP, syscall.IP_MULTICAST_IF, addr); sockErr != nil {
+				return
+			}
+		}
+
+		if sockErr = syscall.SetsockoptByte(int(fd), syscall.IPPROTO_IP, syscall.IP_MULTICAST_TTL, byte(ttl)); sockErr != nil {
+			return
+		}
+
+		// The DSCP is the upper six bits of the former TOS field
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, dscp<<2)
+	})
+	if err != nil {
+		return err
+	}
+
+	return sockErr
+}
@@ -0,0 +1,83 @@
+// Package transmit sends RTP streams to multicast groups and announces them
+// via SAP, for the generate and relay commands.
+package transmit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/holoplot/go-sap/pkg/sap"
+)
+
+// sapAddress is the multicast group SAP announcements are sent to, as in
+// AES67
+var sapAddress = net.IPv4(239, 255, 255, 255)
+
+// sapInterval is the minimum interval between two SAP announcements, as
+// recommended by AES67
+const sapInterval = 30 * time.Second
+
+// interfaceAddress returns the first IPv4 address of an interface
+func interfaceAddress(ifi *net.Interface) (net.IP, error) {
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of %s: %w", ifi.Name, err)
+	}
+
+	for _, addr := range addrs {
+		if ip, _, err := net.ParseCIDR(addr.String()); err == nil && ip.To4() != nil {
+			return ip.To4(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s has no IPv4 address", ifi.Name)
+}
+
+// Dial opens a socket sending to destination with the given multicast TTL
+// and DSCP, on ifi or on the default route if ifi is nil
+func Dial(destination *net.UDPAddr, ifi *net.Interface, ttl, dscp int) (*net.UDPConn, error) {
+	var laddr *net.UDPAddr
+
+	if ifi != nil {
+		ip, err := interfaceAddress(ifi)
+		if err != nil {
+			return nil, err
+		}
+
+		laddr = &net.UDPAddr{IP: ip}
+	}
+
+	conn, err := net.DialUDP("udp4", laddr, destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open socket: %w", err)
+	}
+
+	if err := setSocketOptions(conn, laddr, ttl, dscp); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set socket options: %w", err)
+	}
+
+	return conn, nil
+}
+
+// Announce announces an SDP via SAP until ctx is done, and then sends a
+// deletion
+func Announce(ctx context.Context, origin net.IP, sdp []byte) error {
+	p := &sap.Packet{
+		IDHash:      uint16(rand.Uint32()),
+		Origin:      origin,
+		PayloadType: sap.SDPPayloadType,
+		Payload:     sdp,
+	}
+
+	err := sap.AnnouncePeriodically(ctx, sapAddress, p, sap.WithMinInterval(sapInterval))
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed to announce stream: %w", err)
+	}
+
+	return nil
+}
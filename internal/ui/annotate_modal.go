@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// annotationFields lists the fields of an annotation in the order they are
+// shown
+var annotationFields = []struct {
+	label string
+	field func(a *config.Annotation) *string
+}{
+	{"Alias", func(a *config.Annotation) *string { return &a.Alias }},
+	{"Note", func(a *config.Annotation) *string { return &a.Note }},
+}
+
+// AnnotateModalContent implements ModalContentProvider for editing the alias
+// and note of a stream. Every change is persisted immediately.
+type AnnotateModalContent struct {
+	stream   *stream.Stream
+	config   *config.Config
+	onChange func()
+
+	selected int
+	editing  bool
+	input    string
+
+	selectedStyle lipgloss.Style
+	hintStyle     lipgloss.Style
+}
+
+// NewAnnotateModalContent creates a new annotation editor for a stream.
+// onChange is called after the annotation was changed.
+func NewAnnotateModalContent(s *stream.Stream, cfg *config.Config, onChange func()) *AnnotateModalContent {
+	return &AnnotateModalContent{
+		stream:   s,
+		config:   cfg,
+		onChange: onChange,
+		selectedStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.TableRowSelected).
+			Background(theme.Colors.TableRowSelectedBg).
+			Bold(true),
+		hintStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Secondary),
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (a *AnnotateModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (a *AnnotateModalContent) Close() {}
+
+// HandleKey implements ModalKeyHandler for selecting and editing the fields
+func (a *AnnotateModalContent) HandleKey(key string, action keymap.Action) bool {
+	if a.editing {
+		return a.handleTextInput(key)
+	}
+
+	switch {
+	case action == keymap.ActionUp:
+		a.selected = max(a.selected-1, 0)
+	case action == keymap.ActionDown:
+		a.selected = min(a.selected+1, len(annotationFields)-1)
+	case key == "enter":
+		annotation := a.config.Annotation(a.stream.IDHash())
+		a.editing = true
+		a.input = *annotationFields[a.selected].field(&annotation)
+	case key == "delete":
+		a.set("")
+	default:
+		return false
+	}
+
+	return true
+}
+
+// handleTextInput edits the value of the selected field. Enter confirms, Esc
+// cancels; all other keys are consumed so they don't trigger actions.
+func (a *AnnotateModalContent) handleTextInput(key string) bool {
+	switch key {
+	case "enter":
+		a.editing = false
+		a.set(a.input)
+	case "esc":
+		a.editing = false
+	case "backspace":
+		if r := []rune(a.input); len(r) > 0 {
+			a.input = string(r[:len(r)-1])
+		}
+	case " ":
+		a.input += " "
+	default:
+		if r := []rune(key); len(r) == 1 {
+			a.input += key
+		}
+	}
+
+	return true
+}
+
+// set changes the selected field and persists the annotation
+func (a *AnnotateModalContent) set(value string) {
+	annotation := a.config.Annotation(a.stream.IDHash())
+	*annotationFields[a.selected].field(&annotation) = value
+
+	a.config.SetAnnotation(a.stream.IDHash(), annotation)
+	a.onChange()
+}
+
+// Content returns the content lines to be displayed
+func (a *AnnotateModalContent) Content() []string {
+	annotation := a.config.Annotation(a.stream.IDHash())
+
+	lines := []string{
+		fmt.Sprintf("  %-10s %s", "Name", a.stream.Name()),
+		fmt.Sprintf("  %-10s %s", "ID hash", a.stream.IDHash()),
+		"",
+	}
+
+	for i, f := range annotationFields {
+		value := *f.field(&annotation)
+		if a.editing && i == a.selected {
+			value = a.input + "█"
+		}
+
+		line := fmt.Sprintf("  %-10s %s", f.label, value)
+		if i == a.selected {
+			line = a.selectedStyle.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+
+	hint := "↑/↓: select, Enter: edit, Del: clear"
+	if a.editing {
+		hint = "Enter: confirm, Esc: cancel"
+	}
+
+	lines = append(lines,
+		a.hintStyle.Render("  "+hint),
+		a.hintStyle.Render("  The alias is shown instead of the announced name, followed by the note"),
+		a.hintStyle.Render("  Changes are saved to "+a.config.Path()))
+
+	return lines
+}
+
+// Title returns the modal title
+func (a *AnnotateModalContent) Title() string {
+	return "ANNOTATE"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (a *AnnotateModalContent) UpdateInterval() time.Duration {
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (a *AnnotateModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (a *AnnotateModalContent) Update() {}
@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/levels"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+const (
+	// compareLabelWidth is the width of the field name column
+	compareLabelWidth = 22
+
+	// programMatch is the confidence from which two channels are taken to
+	// carry the same program
+	programMatch = 0.8
+)
+
+// CompareModalContent implements ModalContentProvider for comparing two
+// streams side by side
+type CompareModalContent struct {
+	a, b *stream.Stream
+
+	// fingerprints of the audio of both streams, nil if they are not both
+	// PCM audio or can't be received
+	fingerprintA, fingerprintB *levels.Fingerprinter
+	fingerprintErr             error
+
+	columnWidth int
+
+	headerStyle lipgloss.Style
+	diffStyle   lipgloss.Style
+}
+
+// compareField is a labelled value of one of the compared streams
+type compareField struct {
+	label string
+	value string
+}
+
+// NewCompareModalContent creates a new compare modal content provider
+func NewCompareModalContent(a, b *stream.Stream) *CompareModalContent {
+	return &CompareModalContent{
+		a: a,
+		b: b,
+		headerStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Primary).
+			Bold(true),
+		diffStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Highlight).
+			Bold(true),
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (c *CompareModalContent) Init(width, height int) {
+	contentWidth := modalAvailableWidth(width, height)
+	c.columnWidth = max((contentWidth-compareLabelWidth-4)/2, 10)
+
+	if !levels.IsAudio(c.a) || !levels.IsAudio(c.b) {
+		return
+	}
+
+	var err error
+
+	if c.fingerprintA, err = levels.NewFingerprinter(c.a); err != nil {
+		c.fingerprintErr = err
+		return
+	}
+
+	if c.fingerprintB, err = levels.NewFingerprinter(c.b); err != nil {
+		c.fingerprintA.Close()
+		c.fingerprintA = nil
+		c.fingerprintErr = err
+	}
+}
+
+// Close closes the modal content provider
+func (c *CompareModalContent) Close() {
+	if c.fingerprintA != nil {
+		c.fingerprintA.Close()
+		c.fingerprintB.Close()
+	}
+}
+
+// Content returns the content lines to be displayed
+func (c *CompareModalContent) Content() []string {
+	var lines []string
+
+	lines = append(lines, c.headerStyle.Render(c.row("", c.a.Name(), c.b.Name())), "")
+
+	lines = append(lines, c.headerStyle.Render("Stream"))
+	lines = append(lines, c.rows(describeStream(c.a), describeStream(c.b))...)
+	lines = append(lines, "")
+
+	lines = append(lines, c.program()...)
+	lines = append(lines, "")
+
+	sources := max(len(c.a.Description.Sources), len(c.b.Description.Sources))
+	for i := range sources {
+		lines = append(lines, c.headerStyle.Render(fmt.Sprintf("Source %d", i+1)))
+		lines = append(lines, c.rows(describeSource(c.a, i), describeSource(c.b, i))...)
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, c.headerStyle.Render("SDP"))
+
+	sdpA := strings.Split(strings.TrimRight(string(c.a.SDP), "\r\n"), "\n")
+	sdpB := strings.Split(strings.TrimRight(string(c.b.SDP), "\r\n"), "\n")
+
+	for i := range max(len(sdpA), len(sdpB)) {
+		lines = append(lines, c.compare("", lineAt(sdpA, i), lineAt(sdpB, i)))
+	}
+
+	return lines
+}
+
+// program compares the audio of each channel of the two streams by their
+// fingerprints, to verify that redundant or backup feeds carry the same
+// program
+func (c *CompareModalContent) program() []string {
+	lines := []string{c.headerStyle.Render("Program (fingerprints of the last 10 s)")}
+
+	switch {
+	case c.fingerprintErr != nil:
+		return append(lines, fmt.Sprintf("  Not compared: %v", c.fingerprintErr))
+	case c.fingerprintA == nil:
+		return append(lines, "  Not compared, both streams must carry PCM audio")
+	}
+
+	a := c.fingerprintA.Fingerprint()
+	b := c.fingerprintB.Fingerprint()
+
+	for ch := range a.Channels() {
+		label := fmt.Sprintf("Channel %d", ch+1)
+
+		match, ok := levels.Match(a, ch, b, ch)
+		if !ok {
+			lines = append(lines, "  "+programRow(label, "no audio to compare yet"))
+			continue
+		}
+
+		result := fmt.Sprintf("%.0f%% match%s", match.Confidence*100, offsetLabel(match.Offset))
+		if match.Confidence >= programMatch {
+			lines = append(lines, "  "+programRow(label, result))
+			continue
+		}
+
+		// The program may be on another channel of the second stream
+		for other := range b.Channels() {
+			if m, ok := levels.Match(a, ch, b, other); ok && other != ch && m.Confidence >= programMatch {
+				result += fmt.Sprintf(", carried on channel %d (%.0f%%%s)", other+1, m.Confidence*100, offsetLabel(m.Offset))
+				break
+			}
+		}
+
+		lines = append(lines, c.diffStyle.Render("≠ "+programRow(label, result)))
+	}
+
+	if a.Channels() != b.Channels() {
+		lines = append(lines, fmt.Sprintf("  The streams have %d and %d channels", a.Channels(), b.Channels()))
+	}
+
+	return lines
+}
+
+// programRow lays out a channel and the result of its comparison
+func programRow(label, result string) string {
+	return fmt.Sprintf("%-*s%s", compareLabelWidth, label, result)
+}
+
+// offsetLabel describes how much later the program arrives in the second
+// stream, empty if not noticeably
+func offsetLabel(offset time.Duration) string {
+	switch {
+	case offset > 0:
+		return fmt.Sprintf(", %s later on the right", offset)
+	case offset < 0:
+		return fmt.Sprintf(", %s earlier on the right", -offset)
+	}
+
+	return ""
+}
+
+// rows renders a list of field pairs
+func (c *CompareModalContent) rows(a, b []compareField) []string {
+	var lines []string
+
+	for i := range max(len(a), len(b)) {
+		label := ""
+		if i < len(a) {
+			label = a[i].label
+		} else {
+			label = b[i].label
+		}
+
+		var valueA, valueB string
+		if i < len(a) {
+			valueA = a[i].value
+		}
+		if i < len(b) {
+			valueB = b[i].value
+		}
+
+		lines = append(lines, c.compare(label, valueA, valueB))
+	}
+
+	return lines
+}
+
+// compare renders one line, highlighting it if the values differ
+func (c *CompareModalContent) compare(label, a, b string) string {
+	line := c.row(label, a, b)
+
+	if a != b {
+		return c.diffStyle.Render("≠ " + line)
+	}
+
+	return "  " + line
+}
+
+// row lays out a label and two values in columns
+func (c *CompareModalContent) row(label, a, b string) string {
+	column := func(s string, width int) string {
+		s = ansi.Truncate(SanitizeASCII(s), width, "…")
+		return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+	}
+
+	return column(label, compareLabelWidth) + column(a, c.columnWidth) + " │ " + column(b, c.columnWidth)
+}
+
+// describeStream returns the stream level fields to compare
+func describeStream(s *stream.Stream) []compareField {
+	return []compareField{
+		{label: "ID hash", value: s.IDHash()},
+		{label: "Codec", value: s.CodecInfo()},
+		{label: "Sample rate", value: fmt.Sprintf("%d Hz", s.Description.SampleRate)},
+		{label: "Channels", value: fmt.Sprintf("%d", s.Description.ChannelCount)},
+		{label: "Sources", value: fmt.Sprintf("%d", len(s.Description.Sources))},
+		{label: "Discovery", value: s.DiscoveryLabel()},
+		{label: "Sender", value: s.SenderLabel()},
+	}
+}
+
+// describeSource returns the fields of a stream source to compare, or
+// placeholders if the stream has no source with that index
+func describeSource(s *stream.Stream, i int) []compareField {
+	if i >= len(s.Description.Sources) {
+		return []compareField{{label: "Source", value: "-"}}
+	}
+
+	source := s.Description.Sources[i]
+
+	return []compareField{
+		{label: "Sender address", value: source.SenderAddress.String()},
+		{label: "Destination", value: fmt.Sprintf("%s:%d", source.DestinationAddress, source.DestinationPort)},
+		{label: "TTL", value: fmt.Sprintf("%d", source.TTL)},
+		{label: "Frames per packet", value: fmt.Sprintf("%d", source.FramesPerPacket)},
+		{label: "Clock domain", value: source.ClockDomain},
+		{label: "Reference clock", value: source.ReferenceClock},
+		{label: "Media clock", value: source.MediaClock},
+		{label: "Sync time", value: fmt.Sprintf("%d", source.SyncTime)},
+	}
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return strings.TrimSuffix(lines[i], "\r")
+	}
+
+	return ""
+}
+
+// Title returns the modal title
+func (c *CompareModalContent) Title() string {
+	return "COMPARE WITH " + c.b.Name()
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (c *CompareModalContent) UpdateInterval() time.Duration {
+	if c.fingerprintA != nil {
+		return time.Second
+	}
+
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (c *CompareModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (c *CompareModalContent) Update() {}
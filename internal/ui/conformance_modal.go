@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/analyze"
+	"github.com/holoplot/rtp-monitor/internal/conformance"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// ConformanceModalContent implements ModalContentProvider for the AES67 and
+// ST 2110-30 conformance of a stream. The stream is received while the modal
+// is open, to judge its pacing.
+type ConformanceModalContent struct {
+	stream   *stream.Stream
+	analyzer *analyze.Analyzer
+	err      error
+}
+
+// NewConformanceModalContent creates a new conformance content provider
+func NewConformanceModalContent(s *stream.Stream) *ConformanceModalContent {
+	return &ConformanceModalContent{
+		stream: s,
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (c *ConformanceModalContent) Init(width, height int) {
+	c.analyzer, c.err = analyze.Start(analyze.Options{Stream: c.stream})
+}
+
+// Close closes the modal content provider
+func (c *ConformanceModalContent) Close() {
+	if c.analyzer != nil {
+		c.analyzer.Close()
+	}
+}
+
+// statusLabel returns the colored label of a check status
+func statusLabel(s conformance.Status) string {
+	color := theme.Colors.StatusActive
+
+	switch s {
+	case conformance.Warn:
+		color = theme.Colors.StatusWarning
+	case conformance.Fail:
+		color = theme.Colors.StatusError
+	}
+
+	return lipgloss.NewStyle().Foreground(color).Bold(true).Render(s.String())
+}
+
+// Content returns the content lines to be displayed
+func (c *ConformanceModalContent) Content() []string {
+	l := newLineBuffer(lipgloss.NewStyle())
+
+	var pacing []string
+	if c.analyzer != nil {
+		pacing = conformance.Pacing(c.analyzer.Report())
+	} else {
+		l.p("Pacing not measured: %v", c.err)
+		l.p("")
+	}
+
+	report := conformance.Check(c.stream, pacing)
+
+	for _, p := range report.Profiles {
+		l.p("%s: %s", p.Name, statusLabel(p.Status))
+
+		for i, item := range p.Items {
+			prefix := "├─"
+			if i == len(p.Items)-1 {
+				prefix = "└─"
+			}
+
+			l.p("  %s %s %-16s %s", prefix, statusLabel(item.Status), item.Name+":", item.Detail)
+		}
+
+		l.p("")
+	}
+
+	return l.lines()
+}
+
+// Title returns the modal title
+func (c *ConformanceModalContent) Title() string {
+	return "CONFORMANCE"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (c *ConformanceModalContent) UpdateInterval() time.Duration {
+	return time.Second
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (c *ConformanceModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (c *ConformanceModalContent) Update() {}
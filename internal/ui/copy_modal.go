@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// copyFormat is one way of copying streams to the clipboard
+type copyFormat struct {
+	key   string
+	label string
+
+	// text returns the text to copy, or an error if the streams have
+	// nothing to copy in this format
+	text func(streams []*stream.Stream) (string, error)
+}
+
+// copyFormats lists the formats in the order they are shown
+var copyFormats = []copyFormat{
+	{"s", "SDP", copySDP},
+	{"j", "JSON metadata", copyJSON},
+	{"a", "multicast address:port", copyAddresses},
+	{"u", "RTSP URL", copyRTSPURLs},
+}
+
+// copySDP returns the SDPs of the streams, separated by blank lines
+func copySDP(streams []*stream.Stream) (string, error) {
+	sdps := make([]string, len(streams))
+	for i, s := range streams {
+		sdps[i] = strings.TrimRight(string(s.SDP), "\r\n")
+	}
+
+	return strings.Join(sdps, "\n\n") + "\n", nil
+}
+
+// copyJSON returns the streams in the JSON format of the stream list export
+func copyJSON(streams []*stream.Stream) (string, error) {
+	var b bytes.Buffer
+	if err := inventory.WriteJSON(&b, inventory.FromStreams(streams)); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// copyAddresses returns the destination of each source, one per line
+func copyAddresses(streams []*stream.Stream) (string, error) {
+	var lines []string
+
+	for _, s := range streams {
+		for _, source := range s.Description.Sources {
+			lines = append(lines, net.JoinHostPort(source.DestinationAddress.String(),
+				strconv.Itoa(int(source.DestinationPort))))
+		}
+	}
+
+	if len(lines) == 0 {
+		return "", errors.New("no sources")
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// copyRTSPURLs returns the RTSP URLs of the streams announced via mDNS
+func copyRTSPURLs(streams []*stream.Stream) (string, error) {
+	var lines []string
+
+	for _, s := range streams {
+		if url := s.RTSPURL(); url != "" {
+			lines = append(lines, url)
+		}
+	}
+
+	if len(lines) == 0 {
+		return "", errors.New("no RTSP URL known, only streams announced via mDNS have one")
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// CopyModalContent implements ModalContentProvider for choosing the format
+// streams are copied to the clipboard in
+type CopyModalContent struct {
+	streams []*stream.Stream
+	onCopy  func(text, what string, err error)
+
+	selected int
+
+	selectedStyle lipgloss.Style
+	hintStyle     lipgloss.Style
+}
+
+// NewCopyModalContent creates a new copy chooser for the given streams.
+// onCopy is called with the text in the chosen format, or an error.
+func NewCopyModalContent(streams []*stream.Stream, onCopy func(text, what string, err error)) *CopyModalContent {
+	return &CopyModalContent{
+		streams: streams,
+		onCopy:  onCopy,
+		selectedStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.TableRowSelected).
+			Background(theme.Colors.TableRowSelectedBg).
+			Bold(true),
+		hintStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Secondary),
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (c *CopyModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (c *CopyModalContent) Close() {}
+
+// HandleKey implements ModalKeyHandler to choose a format
+func (c *CopyModalContent) HandleKey(key string, action keymap.Action) bool {
+	switch {
+	case action == keymap.ActionUp:
+		c.selected = max(c.selected-1, 0)
+	case action == keymap.ActionDown:
+		c.selected = min(c.selected+1, len(copyFormats)-1)
+	case key == "enter" || action == keymap.ActionCopy:
+		c.copy(copyFormats[c.selected])
+	default:
+		for _, f := range copyFormats {
+			if key == f.key {
+				c.copy(f)
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// copy passes the streams in format f to onCopy
+func (c *CopyModalContent) copy(f copyFormat) {
+	text, err := f.text(c.streams)
+	c.onCopy(text, f.label, err)
+}
+
+// Content returns the content lines to be displayed
+func (c *CopyModalContent) Content() []string {
+	var lines []string
+
+	if len(c.streams) == 1 {
+		lines = append(lines, fmt.Sprintf("Copy %s as", c.streams[0].Name()), "")
+	} else {
+		lines = append(lines, fmt.Sprintf("Copy %d streams as", len(c.streams)), "")
+	}
+
+	for i, f := range copyFormats {
+		line := fmt.Sprintf("  %s  %s", f.key, f.label)
+		if i == c.selected {
+			line = c.selectedStyle.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "", c.hintStyle.Render("↑/↓ and Enter, or the key of a format, to copy"))
+
+	return lines
+}
+
+// Title returns the modal title
+func (c *CopyModalContent) Title() string {
+	return "COPY"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (c *CopyModalContent) UpdateInterval() time.Duration {
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (c *CopyModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (c *CopyModalContent) Update() {}
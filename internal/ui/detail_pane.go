@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// DetailPane shows live statistics of the selected stream below the table
+type DetailPane struct {
+	keyMap     *keymap.KeyMap
+	ptpMonitor *ptp.Monitor
+	config     *config.Config
+	stream     *stream.Stream
+	details    *DetailsModalContent
+
+	titleStyle lipgloss.Style
+	lineStyle  lipgloss.Style
+}
+
+// NewDetailPane creates a new, empty detail pane
+func NewDetailPane(keyMap *keymap.KeyMap, ptpMonitor *ptp.Monitor, cfg *config.Config) *DetailPane {
+	return &DetailPane{
+		keyMap:     keyMap,
+		ptpMonitor: ptpMonitor,
+		config:     cfg,
+		titleStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Primary).
+			Bold(true),
+		lineStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.TableBorder),
+	}
+}
+
+// SetStream switches the pane to a different stream, tearing down the
+// receiver of the previous one. Passing nil empties the pane.
+func (p *DetailPane) SetStream(s *stream.Stream) {
+	if p.stream == s {
+		return
+	}
+
+	p.Close()
+
+	p.stream = s
+
+	if s != nil {
+		p.details = NewDetailsModalContent(p.keyMap, s, p.ptpMonitor, p.config)
+		p.details.Init(0, 0)
+	}
+}
+
+// Close releases the receiver of the current stream
+func (p *DetailPane) Close() {
+	if p.details != nil {
+		p.details.Close()
+	}
+
+	p.details = nil
+	p.stream = nil
+}
+
+// Render renders the pane with a title line into exactly height lines
+func (p *DetailPane) Render(width, height int) string {
+	if height <= 0 {
+		return ""
+	}
+
+	title := " Details "
+	if p.stream != nil {
+		name := p.stream.Name()
+		if alias := p.config.Annotation(p.stream.IDHash()).Alias; alias != "" {
+			name = alias
+		}
+
+		title = " Details: " + name + " "
+	}
+
+	title = ansi.Truncate(title, max(width-4, 0), "…")
+	rule := p.lineStyle.Render("──") +
+		p.titleStyle.Render(title) +
+		p.lineStyle.Render(strings.Repeat("─", max(width-2-lipgloss.Width(title), 0)))
+
+	var lines []string
+
+	if p.details != nil {
+		lines = p.details.Summary()
+	} else {
+		lines = []string{"No stream selected"}
+	}
+
+	result := []string{rule}
+
+	for i := 0; i < height-1; i++ {
+		line := ""
+		if i < len(lines) {
+			line = ansi.Truncate(lines[i], width, "…")
+		}
+
+		result = append(result, line+strings.Repeat(" ", max(width-ansi.StringWidth(line), 0)))
+	}
+
+	return strings.Join(result, "\n")
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"net"
 	"slices"
 	"strings"
@@ -8,87 +9,161 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
 	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/stats"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/holoplot/rtp-monitor/internal/theme"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp/v2"
 )
 
+// latencyBins is the number of bins of the latency histograms
+const latencyBins = 30
+
 // DetailsModalContent implements ModalContentProvider for stream details
 type DetailsModalContent struct {
 	mutex sync.Mutex
 
+	keyMap     *keymap.KeyMap
 	stream     *stream.Stream
 	receiver   *stream.RTPReceiver
 	ptpMonitor *ptp.Monitor
+	config     *config.Config
+
+	engine  *stats.Engine
+	senders []map[string]struct{}
+
+	// interfaceStats breaks the statistics down by the interface packets
+	// are received on
+	interfaceStats *stats.Interfaces
+
+	// skew is measured between the legs of streams with two sources
+	skew *stats.Skew
+
+	// latency is measured against PTP for the sources with a PTP reference
+	// clock and a direct media clock, whose offsets are in mediaClocks
+	latency     *stats.Latency
+	mediaClocks map[int]uint32
+
+	// virtualReceiver simulates a receiver with the link offset of the
+	// settings from the latencies measured
+	virtualReceiver *stats.VirtualReceiver
+
+	// rtcp receives the SDES and BYE packets for tracker, nil if RTCP
+	// can't be received
+	rtcp    *stream.RTCPReceiver
+	tracker *stats.RTCPTracker
 
-	lastUpdate       time.Time
-	sourceStatistics []*sourceStatistics
+	// mark holds the statistics marked with the mark-stats action, nil if
+	// there is no mark
+	mark *detailsMark
 
 	err          error
 	contentWidth int
 	headerStyle  lipgloss.Style
+	errorStyle   lipgloss.Style
 }
 
-type sourceStatistics struct {
-	packetCount      uint64
-	lastPacketCount  uint64
-	packetRate       float64
-	lastRTPTimestamp uint32
-	lastPacketTime   time.Time
-	senders          map[string]struct{}
+// detailsMark is a snapshot of the statistics of the sources, to show how
+// they changed since, e.g. before and after a network change
+type detailsMark struct {
+	time      time.Time
+	sources   []stats.EngineSource
+	rtpErrors []uint64
 }
 
-// NewDetailsModalContent creates a new details modal content provider
-func NewDetailsModalContent(stream *stream.Stream, ptpMonitor *ptp.Monitor) *DetailsModalContent {
+// NewDetailsModalContent creates a new details modal content provider. The
+// alias and note of the stream are read from cfg.
+func NewDetailsModalContent(keyMap *keymap.KeyMap, stream *stream.Stream, ptpMonitor *ptp.Monitor, cfg *config.Config) *DetailsModalContent {
 	d := &DetailsModalContent{
-		stream:           stream,
-		ptpMonitor:       ptpMonitor,
-		sourceStatistics: make([]*sourceStatistics, len(stream.Description.Sources)),
+		keyMap:         keyMap,
+		stream:         stream,
+		ptpMonitor:     ptpMonitor,
+		config:         cfg,
+		engine:         stats.NewEngine(len(stream.Description.Sources), stream.Description.SampleRate),
+		interfaceStats: stats.NewInterfaces(len(stream.Description.Sources), stream.Description.SampleRate),
+		senders:        make([]map[string]struct{}, len(stream.Description.Sources)),
+		tracker:        stats.NewRTCPTracker(len(stream.Description.Sources)),
+		skew:           stats.NewSkew(),
+		latency:        stats.NewLatency(len(stream.Description.Sources), stream.Description.SampleRate),
+		mediaClocks:    mediaClocks(stream.Description.Sources),
+		virtualReceiver: stats.NewVirtualReceiver(len(stream.Description.Sources),
+			cfg.Settings.LinkOffset()),
 		headerStyle: lipgloss.NewStyle().
 			Foreground(theme.Colors.Primary).
 			Bold(true),
+		errorStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusError).
+			Bold(true),
 	}
 
-	for i := range len(d.sourceStatistics) {
-		d.sourceStatistics[i] = &sourceStatistics{
-			senders: make(map[string]struct{}),
-		}
+	for i := range d.senders {
+		d.senders[i] = make(map[string]struct{})
 	}
 
 	return d
 }
 
-func (d *DetailsModalContent) rtpReceiverCallback(sourceIndex int, src net.Addr, packet *rtp.Packet) {
+// mediaClocks returns the media clock offsets of the sources whose latency
+// can be measured against PTP, by source index
+func mediaClocks(sources []stream.StreamSource) map[int]uint32 {
+	offsets := make(map[int]uint32)
+
+	for i, source := range sources {
+		_, ptpClock := stream.ParsePTPClock(source.ReferenceClock)
+		if offset, ok := source.MediaClockOffset(); ok && ptpClock {
+			offsets[i] = offset
+		}
+	}
+
+	return offsets
+}
+
+func (d *DetailsModalContent) rtpReceiverCallback(sourceIndex int, ifi *net.Interface, src net.Addr, packet *rtp.Packet, arrival time.Time) {
 	// The callback might fire before NewRTPReceiver() returns. Just ignore that packet.
 	if d.receiver == nil {
 		return
 	}
 
-	now := time.Now()
+	d.engine.Add(sourceIndex, packet, arrival)
 
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
+	if ifi != nil {
+		d.interfaceStats.Add(sourceIndex, ifi.Name, packet, arrival)
+	}
 
-	stat := d.sourceStatistics[sourceIndex]
+	if len(d.stream.Description.Sources) == 2 {
+		d.skew.Add(sourceIndex, packet, arrival)
+	}
+
+	if offset, ok := d.mediaClocks[sourceIndex]; ok && d.ptpMonitor != nil {
+		if ptpArrival, ok := d.ptpMonitor.TimeAt(arrival); ok {
+			latency := d.latency.Add(sourceIndex, packet.Timestamp-offset, ptpArrival)
+			d.virtualReceiver.Add(sourceIndex, latency)
+		}
+	}
 
-	stat.packetCount++
-	stat.lastRTPTimestamp = packet.Timestamp
-	stat.lastPacketTime = now
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 
-	stat.senders[src.String()] = struct{}{}
+	d.senders[sourceIndex][src.String()] = struct{}{}
 }
 
 // Init initializes the content provider with dimensions
 func (d *DetailsModalContent) Init(width, height int) {
-	d.lastUpdate = time.Now()
-
-	if receiver, err := d.stream.NewRTPReceiver(d.rtpReceiverCallback); err == nil {
+	if receiver, err := d.stream.NewRTPInterfaceReceiver(d.rtpReceiverCallback); err == nil {
 		d.receiver = receiver
 	} else {
 		d.err = err
 	}
 
+	// The senders are only identified if RTCP is received, the statistics
+	// don't need it
+	d.rtcp, _ = d.stream.NewRTCPReceiver(func(sourceIndex int, _ net.Addr, packet rtcp.Packet) {
+		d.tracker.Add(sourceIndex, packet, time.Now())
+	})
+
 	d.contentWidth = width
 }
 
@@ -96,6 +171,10 @@ func (d *DetailsModalContent) Close() {
 	if d.receiver != nil {
 		d.receiver.Close()
 	}
+
+	if d.rtcp != nil {
+		d.rtcp.Close()
+	}
 }
 
 // Content returns the content lines to be displayed
@@ -107,7 +186,15 @@ func (d *DetailsModalContent) Content() []string {
 	l.p("Basic Information")
 	l.p("  ├─ ID:               %s", s.ID)
 	l.p("  ├─ ID hash:          %s", s.IDHash())
-	l.p("  └─ Name:             %s", s.Name())
+
+	a := d.config.Annotation(s.IDHash())
+	if a.Alias == "" && a.Note == "" {
+		l.p("  └─ Name:             %s", s.Name())
+	} else {
+		l.p("  ├─ Name:             %s", s.Name())
+		l.p("  ├─ Alias:            %s", a.Alias)
+		l.p("  └─ Note:             %s", a.Note)
+	}
 	l.p("")
 
 	l.p("Discovered via (%d)", len(s.Discoveries))
@@ -136,7 +223,18 @@ func (d *DetailsModalContent) Content() []string {
 	l.p("  ├─ Content Type:   %s", s.Description.ContentType)
 	l.p("  ├─ Sample Rate:    %d Hz", s.Description.SampleRate)
 	l.p("  ├─ Channels:       %d", s.Description.ChannelCount)
-	l.p("  └─ Codec Info:     %s", s.CodecInfo())
+	l.p("  ├─ Codec Info:     %s", s.CodecInfo())
+
+	if details := s.Description.CodecDetails(); details != "" {
+		l.p("  ├─ Codec details:  %s", details)
+	}
+
+	if err := s.Description.SamplesError(); err != nil {
+		l.p("  ├─ Samples:        %s", d.errorStyle.Render(fmt.Sprintf("not decoded, no meters or recording: %v", err)))
+	}
+
+	l.p("  ├─ Payload Type:   %d", s.Description.PayloadType)
+	l.p("  └─ Interfaces:     %s", d.interfaces())
 	l.p("")
 
 	d.mutex.Lock()
@@ -145,44 +243,72 @@ func (d *DetailsModalContent) Content() []string {
 	if d.err != nil {
 		l.p("Error creating stream receiver: %v", d.err)
 	} else {
-		dur := time.Since(d.lastUpdate)
+		now := time.Now()
 
-		if dur > time.Second {
-			for _, stats := range d.sourceStatistics {
-				stats.packetRate = float64(stats.packetCount-stats.lastPacketCount) / dur.Seconds()
-				stats.lastPacketCount = stats.packetCount
-			}
+		if drops := d.receiver.QueueDrops(); drops > 0 {
+			l.p("%s", d.errorStyle.Render(fmt.Sprintf("%d packets dropped, the receivers of the stream did not keep up or the memory budget is exhausted", drops)))
+			l.p("")
+		}
 
-			d.lastUpdate = time.Now()
+		if d.mark == nil {
+			l.p("%s: mark the statistics to show how they change from now on", d.keyMap.Label(keymap.ActionMarkStats))
+		} else {
+			l.p("Marked at %s, %s │ %s: mark again, %s: clear the mark",
+				d.mark.time.Format(time.TimeOnly), formatAgo(now.Sub(d.mark.time)),
+				d.keyMap.Label(keymap.ActionMarkStats), d.keyMap.Label(keymap.ActionClearMark))
 		}
+		l.p("")
 
 		for i, source := range s.Description.Sources {
-			stats := d.sourceStatistics[i]
+			stat := d.engine.Source(i, now)
+			history := d.engine.History(i, now)
 
 			l.p("Source %d statistics (%s:%d):", i+1,
 				source.DestinationAddress.String(),
 				source.DestinationPort)
 
-			var senders []string
+			if err := d.receiver.SourceError(i); err != nil {
+				l.p("  └─ Not received: %v", err)
+				l.p("")
 
-			for sender := range stats.senders {
-				senders = append(senders, sender)
+				continue
 			}
 
-			slices.Sort(senders)
-
-			l.p("  ├─ Senders:         %s", strings.Join(senders, ", "))
-			l.p("  ├─ Packets count:   %d", stats.packetCount)
-			l.p("  ├─ Packets rate:    %.2f/s", stats.packetRate)
+			l.p("  ├─ Senders:         %s", d.sendersOf(i))
+			l.p("  ├─ Packets count:   %d", stat.Packets)
+			l.p("  ├─ Packets rate:    %.2f/s", stat.Last.Rate())
 			l.p("  ├─ Parsing errors:  %d", d.receiver.RTPErrors(i))
-			l.p("  ├─ Sequence errors: %d", d.receiver.SequenceErrors(i))
-			l.p("  └─ Last timestamp:  %d", stats.lastRTPTimestamp)
+			l.p("  ├─ Sequence errors: %d", stat.SequenceErrors)
+
+			if n, pt := d.receiver.PayloadTypeErrors(i); n > 0 {
+				l.p("  ├─ Payload type:    %s", d.errorStyle.Render(fmt.Sprintf(
+					"%d packets of type %d, the SDP declares %d", n, pt, s.Description.PayloadType)))
+			}
+
+			sequence := stat.Sequence
+			l.p("  ├─ Lost:            %d of %d, %d reordered", max(sequence.Lost, 0), sequence.Expected, sequence.Reordered)
+			l.p("  ├─ Jitter:          %s", stat.Jitter.Round(time.Microsecond))
+			d.sinceMark(l, i, stat, now)
+			d.interfaceBreakdown(l, i, now)
+			d.latencyDistribution(l, i)
+			l.p("  ├─ Rate (60s):      %s", historyGraph(history, stats.Bucket.Rate, "/s"))
+			l.p("  ├─ Errors (60s):    %s", historyGraph(history, stats.Bucket.SequenceErrorRate, "/s"))
+			l.p("  ├─ Last timestamp:  %d", stat.LastTimestamp)
+			d.rtcpSenders(l, i, stat.LastPacket, now)
 			l.p("")
 		}
+
+		if len(s.Description.Sources) == 2 {
+			d.redundancy(l)
+		}
 	}
 
 	if d.ptpMonitor != nil {
 		d.ptpMonitor.ForEachTransmitter(func(ci ptp.ClockIdentity, t *ptp.Transmitter) {
+			if t.LastTimestamp.IsZero() {
+				return
+			}
+
 			ptpSamples := t.LastTimestamp.InSamples(d.stream.Description.SampleRate)
 
 			l.p("PTP Transmitter %s, domain %d, interface %s:", ci, t.Domain, t.IfiName)
@@ -200,6 +326,7 @@ func (d *DetailsModalContent) Content() []string {
 		l.p("  ├─ Sender address:         %s", source.SenderAddress)
 		l.p("  ├─ Destination address:    %s:%d", source.DestinationAddress, source.DestinationPort)
 		l.p("  ├─ TTL:                    %d", source.TTL)
+		l.p("  ├─ RTCP:                   %s", source.RTCPLabel())
 		l.p("  ├─ Frames per packet:      %d", source.FramesPerPacket)
 		l.p("  ├─ Clock domain:           %s", source.ClockDomain)
 		l.p("  ├─ Reference clock:        %s", source.ReferenceClock)
@@ -211,6 +338,333 @@ func (d *DetailsModalContent) Content() []string {
 	return l.lines()
 }
 
+// redundancy adds the skew between the two legs of a redundant stream
+func (d *DetailsModalContent) redundancy(l *lineBuffer) {
+	skew := d.skew.Stats()
+
+	l.p("Redundancy (skew of source 2 against source 1):")
+
+	if skew.Matched == 0 {
+		l.p("  └─ No packets received on both legs")
+		l.p("")
+
+		return
+	}
+
+	l.p("  ├─ Matched packets: %d", skew.Matched)
+	l.p("  ├─ Skew:            %s", d.skewLabel(skew.Current))
+	l.p("  ├─ Range:           %s to %s", d.skewLabel(skew.Min), d.skewLabel(skew.Max))
+
+	worst := max(skew.Min.Abs(), skew.Max.Abs())
+
+	switch {
+	case worst > stats.SkewError:
+		l.p("  └─ %s", d.errorStyle.Render("Exceeds the 10 ms ST 2022-7 low-skew receivers buffer"))
+	case worst > stats.SkewWarning:
+		l.p("  └─ %s", d.errorStyle.Render("Exceeds the 1 ms link offset of typical AES67 receivers"))
+	default:
+		l.p("  └─ Within the buffer of typical receivers")
+	}
+
+	l.p("")
+}
+
+// skewLabel formats a skew in milliseconds and, if the packet time of the
+// stream is known, in packets
+func (d *DetailsModalContent) skewLabel(skew time.Duration) string {
+	label := fmt.Sprintf("%+.3f ms", float64(skew)/float64(time.Millisecond))
+
+	if packetTime := d.stream.Description.Sources[0].PacketTime; packetTime > 0 {
+		label += fmt.Sprintf(" (%+.1f packets)", float64(skew)/float64(packetTime))
+	}
+
+	return label
+}
+
+// historyGraph renders a value of the buckets of a history as a sparkline
+// followed by its peak value
+func historyGraph(history []stats.Bucket, value func(stats.Bucket) float64, unit string) string {
+	values := make([]float64, len(history))
+	peak := 0.0
+
+	for i, b := range history {
+		values[i] = value(b)
+		peak = max(peak, values[i])
+	}
+
+	return fmt.Sprintf("%s  max %.0f%s", sparkline(values, stats.HistoryLength), peak, unit)
+}
+
+// rtcpSenders describes the senders of a source as identified by their RTCP
+// SDES items, and whether they said goodbye
+func (d *DetailsModalContent) rtcpSenders(l *lineBuffer, sourceIndex int, lastPacket, now time.Time) {
+	if d.rtcp == nil {
+		l.p("  └─ RTCP senders:    RTCP not received")
+		return
+	}
+
+	senders := d.tracker.Senders(sourceIndex)
+	if len(senders) == 0 {
+		l.p("  └─ RTCP senders:    none identified yet")
+		return
+	}
+
+	if ended := d.tracker.Ended(sourceIndex, lastPacket); !ended.IsZero() {
+		l.p("  ├─ %s", d.errorStyle.Render(fmt.Sprintf("Ended %s, all senders said goodbye", formatAgo(now.Sub(ended)))))
+	}
+
+	l.p("  └─ RTCP senders:")
+
+	for i, sender := range senders {
+		branch, indent := "├─", "│ "
+		if i == len(senders)-1 {
+			branch, indent = "└─", "  "
+		}
+
+		l.p("       %s SSRC %x: CNAME %s", branch, sender.SSRC, valueOrDash(sender.CNAME))
+
+		if sender.Name != "" || sender.Tool != "" {
+			l.p("       %s   name %s, tool %s", indent, valueOrDash(sender.Name), valueOrDash(sender.Tool))
+		}
+
+		if !sender.Bye.IsZero() {
+			bye := "BYE " + formatAgo(now.Sub(sender.Bye))
+			if sender.ByeReason != "" {
+				bye += ": " + sender.ByeReason
+			}
+
+			l.p("       %s   %s", indent, bye)
+		}
+	}
+}
+
+// HandleKey implements ModalKeyHandler to mark the statistics and to clear
+// the mark
+func (d *DetailsModalContent) HandleKey(_ string, action keymap.Action) bool {
+	switch action {
+	case keymap.ActionMarkStats:
+		d.setMark(time.Now())
+	case keymap.ActionClearMark:
+		d.mutex.Lock()
+		d.mark = nil
+		d.mutex.Unlock()
+	default:
+		return false
+	}
+
+	return true
+}
+
+// setMark takes a snapshot of the statistics of all sources
+func (d *DetailsModalContent) setMark(now time.Time) {
+	if d.receiver == nil {
+		return
+	}
+
+	sources := len(d.stream.Description.Sources)
+	mark := &detailsMark{
+		time:      now,
+		sources:   make([]stats.EngineSource, sources),
+		rtpErrors: make([]uint64, sources),
+	}
+
+	for i := range sources {
+		mark.sources[i] = d.engine.Source(i, now)
+		mark.rtpErrors[i] = d.receiver.RTPErrors(i)
+	}
+
+	d.mutex.Lock()
+	d.mark = mark
+	d.mutex.Unlock()
+}
+
+// sinceMark adds the changes of the statistics of a source since the mark.
+// The caller must hold d.mutex.
+func (d *DetailsModalContent) sinceMark(l *lineBuffer, sourceIndex int, stat stats.EngineSource, now time.Time) {
+	if d.mark == nil {
+		return
+	}
+
+	delta := stat.Since(d.mark.sources[sourceIndex], now.Sub(d.mark.time))
+	rtpErrors := d.receiver.RTPErrors(sourceIndex) - d.mark.rtpErrors[sourceIndex]
+
+	jitter := delta.Jitter.Round(time.Microsecond).String()
+	if delta.Jitter >= 0 {
+		jitter = "+" + jitter
+	}
+
+	l.p("  ├─ Since mark:      %d packets, %.2f/s, %d parsing errors, %d sequence errors",
+		delta.Packets, delta.Rate(), rtpErrors, delta.SequenceErrors)
+	l.p("  │                  lost %d of %d, %d reordered, jitter %s",
+		max(delta.Lost, 0), delta.Expected, delta.Reordered, jitter)
+}
+
+// interfaceBreakdown adds the statistics of a source per interface if it is
+// received on more than one, and where losses are likely to occur
+func (d *DetailsModalContent) interfaceBreakdown(l *lineBuffer, sourceIndex int, now time.Time) {
+	interfaces := d.interfaceStats.Source(sourceIndex, now)
+	if len(interfaces) < 2 {
+		return
+	}
+
+	l.p("  ├─ Interfaces:")
+
+	var lossy []string
+
+	for i, ifi := range interfaces {
+		branch := "├─"
+		if i == len(interfaces)-1 {
+			branch = "└─"
+		}
+
+		sequence := ifi.Sequence
+		l.p("  │   %s %-8s %d packets, %.2f/s, lost %d of %d, jitter %s", branch, ifi.Interface,
+			ifi.Packets, ifi.Last.Rate(), max(sequence.Lost, 0), sequence.Expected,
+			ifi.Jitter.Round(time.Microsecond))
+
+		if sequence.Lost > 0 {
+			lossy = append(lossy, ifi.Interface)
+		}
+	}
+
+	switch {
+	case len(lossy) == len(interfaces):
+		l.p("  ├─ %s", d.errorStyle.Render("Lost on all interfaces, check the sender"))
+	case len(lossy) > 0:
+		l.p("  ├─ %s", d.errorStyle.Render(fmt.Sprintf("Lost on %s only, check that network leg", strings.Join(lossy, ", "))))
+	}
+}
+
+// latencyDistribution adds the percentiles and the histogram of the latency
+// of the packets of a source against PTP
+func (d *DetailsModalContent) latencyDistribution(l *lineBuffer, sourceIndex int) {
+	if _, ok := d.mediaClocks[sourceIndex]; !ok {
+		l.p("  ├─ Latency:         - (needs a PTP reference clock and a direct media clock)")
+		return
+	}
+
+	latency := d.latency.Stats(sourceIndex, latencyBins)
+	if latency.Samples == 0 {
+		l.p("  ├─ Latency:         - (no PTP time received)")
+		return
+	}
+
+	l.p("  ├─ Latency:         p50 %s, p90 %s, p99 %s, max %s",
+		latencyLabel(latency.P50), latencyLabel(latency.P90), latencyLabel(latency.P99), latencyLabel(latency.Max))
+
+	values := make([]float64, len(latency.Histogram))
+	for i, n := range latency.Histogram {
+		values[i] = float64(n)
+	}
+
+	l.p("  ├─ Latency spread:  %s  %s to %s, last %d packets", sparkline(values, latencyBins),
+		latencyLabel(latency.Min), latencyLabel(latency.P99), latency.Samples)
+
+	if latency.Min < 0 {
+		l.p("  ├─ %s", d.errorStyle.Render("Packets arrive before their timestamp, the sender is not locked to the PTP time received"))
+	}
+
+	d.virtualReceiverResult(l, sourceIndex)
+}
+
+// virtualReceiverResult adds the packets of a source a receiver with the
+// link offset of the settings would have received too late, and the late
+// packets at the common link offsets
+func (d *DetailsModalContent) virtualReceiverResult(l *lineBuffer, sourceIndex int) {
+	r := d.virtualReceiver.Stats(sourceIndex)
+
+	result := fmt.Sprintf("%d of %d packets late at %s (%.3f%%)", r.Late, r.Packets,
+		latencyLabel(r.LinkOffset), float64(r.Late)/float64(max(r.Packets, 1))*100)
+	if r.Late > 0 {
+		result = d.errorStyle.Render(fmt.Sprintf("%s, up to %d in a row", result, r.LongestRun))
+	}
+
+	l.p("  ├─ Receiver:        %s", result)
+
+	lateAt := make([]string, len(stats.LinkOffsets))
+	for i, offset := range stats.LinkOffsets {
+		lateAt[i] = fmt.Sprintf("%s %d", offset, r.LateAt[i])
+	}
+
+	l.p("  │                  late at %s", strings.Join(lateAt, ", "))
+
+	if offset, ok := r.SafeLinkOffset(); ok {
+		l.p("  │                  none late from %s, the latency was at most %s", offset, latencyLabel(r.MaxLatency))
+	} else {
+		l.p("  │                  late at all common link offsets, the latency was up to %s", latencyLabel(r.MaxLatency))
+	}
+}
+
+// latencyLabel formats a latency in milliseconds
+func latencyLabel(latency time.Duration) string {
+	return fmt.Sprintf("%.3f ms", float64(latency)/float64(time.Millisecond))
+}
+
+// valueOrDash returns s, or a dash if it is empty
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// sendersOf returns the sorted addresses packets of a source were received
+// from. The caller must hold d.mutex.
+func (d *DetailsModalContent) sendersOf(sourceIndex int) string {
+	var senders []string
+
+	for sender := range d.senders[sourceIndex] {
+		senders = append(senders, sender)
+	}
+
+	slices.Sort(senders)
+
+	return strings.Join(senders, ", ")
+}
+
+// Summary returns a compact, one line per source version of the statistics
+// for display outside of a modal
+func (d *DetailsModalContent) Summary() []string {
+	s := d.stream
+
+	l := newLineBuffer(d.headerStyle)
+
+	l.p("ID %s │ %s │ %s", s.IDHash(), s.CodecInfo(), s.DiscoveryLabel())
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.err != nil {
+		l.p("Error creating stream receiver: %v", d.err)
+		return l.lines()
+	}
+
+	now := time.Now()
+
+	for i, source := range s.Description.Sources {
+		stat := d.engine.Source(i, now)
+
+		l.p("Source %d %s:%d │ packets %d │ rate %.2f/s │ parsing errors %d │ sequence errors %d │ senders %s",
+			i+1, source.DestinationAddress, source.DestinationPort,
+			stat.Packets, stat.Last.Rate(),
+			d.receiver.RTPErrors(i), stat.SequenceErrors,
+			d.sendersOf(i))
+
+		if n, pt := d.receiver.PayloadTypeErrors(i); n > 0 {
+			l.p("Source %d │ %s", i+1, d.errorStyle.Render(fmt.Sprintf(
+				"%d packets of payload type %d, the SDP declares %d", n, pt, s.Description.PayloadType)))
+		}
+	}
+
+	if skew := d.skew.Stats(); len(s.Description.Sources) == 2 && skew.Matched > 0 {
+		l.p("Redundancy │ skew %s │ range %s to %s",
+			d.skewLabel(skew.Current), d.skewLabel(skew.Min), d.skewLabel(skew.Max))
+	}
+
+	return l.lines()
+}
+
 // Title returns the modal title
 func (d *DetailsModalContent) Title() string {
 	return "STREAM DETAILS"
@@ -226,6 +680,15 @@ func (d *DetailsModalContent) AutoScroll() bool {
 	return false
 }
 
-// Update is called periodically to refresh content
-func (d *DetailsModalContent) Update() {
+// Update is called periodically to refresh content. The statistics are
+// computed as packets arrive, so there is nothing to do.
+func (d *DetailsModalContent) Update() {}
+
+// interfaces returns the names of the interfaces the stream is received on
+func (d *DetailsModalContent) interfaces() string {
+	if ifi := d.stream.Interface(); ifi != nil {
+		return ifi.Name + " (set for this stream)"
+	}
+
+	return "all"
 }
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/ansihtml"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// exportSDP writes the SDP of a stream to a file in the given folder
+func exportSDP(s *stream.Stream, folder string) error {
+	fileName := fmt.Sprintf("%s_%s.sdp", recorder.SanitizeFileName(s.Name()), s.IDHash())
+
+	return os.WriteFile(path.Join(folder, fileName), s.SDP, 0o644)
+}
+
+// exportInventory writes the list of streams to timestamped CSV and JSON
+// files in the given folder and returns the file names
+func exportInventory(streams []*stream.Stream, folder string) ([]string, error) {
+	entries := inventory.FromStreams(streams)
+	base := fmt.Sprintf("streams_%s", time.Now().Format("2006-01-02_15-04-05"))
+
+	var fileNames []string
+
+	for _, format := range inventory.Formats {
+		fileName := base + "." + format
+
+		f, err := os.Create(path.Join(folder, fileName))
+		if err != nil {
+			return fileNames, err
+		}
+
+		err = inventory.Write(f, format, entries)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return fileNames, fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+
+		fileNames = append(fileNames, fileName)
+	}
+
+	return fileNames, nil
+}
+
+// saveModalContent writes the current content of a modal to a timestamped
+// text file in the given folder and returns the file name
+func saveModalContent(modal *ModalModel, folder string) (string, error) {
+	name := modal.provider.Title()
+	if modal.stream != nil {
+		name += "_" + modal.stream.Name()
+	}
+
+	fileName := fmt.Sprintf("%s_%s.txt",
+		recorder.SanitizeFileName(name), time.Now().Format("2006-01-02_15-04-05"))
+
+	// Strip colors so the file is readable in any editor
+	var b strings.Builder
+	for _, line := range modal.provider.Content() {
+		b.WriteString(ansi.Strip(line))
+		b.WriteString("\n")
+	}
+
+	content := b.String()
+
+	return fileName, os.WriteFile(path.Join(folder, fileName), []byte(content), 0o644)
+}
+
+// saveScreenshot writes a rendered view to timestamped files in the given
+// folder: plain text, the raw ANSI sequences for viewing with cat or less,
+// and HTML. It returns the file names.
+func saveScreenshot(view, folder string) ([]string, error) {
+	base := fmt.Sprintf("screenshot_%s", time.Now().Format("2006-01-02_15-04-05"))
+
+	files := []struct {
+		extension string
+		content   string
+	}{
+		{"txt", ansi.Strip(view) + "\n"},
+		{"ans", view + "\n"},
+		{"html", ansihtml.Document(view, "RTP Stream Monitor "+base,
+			string(theme.Colors.Foreground), string(theme.Colors.Background))},
+	}
+
+	var fileNames []string
+
+	for _, f := range files {
+		fileName := base + "." + f.extension
+
+		if err := os.WriteFile(path.Join(folder, fileName), []byte(f.content), 0o644); err != nil {
+			return fileNames, err
+		}
+
+		fileNames = append(fileNames, fileName)
+	}
+
+	return fileNames, nil
+}
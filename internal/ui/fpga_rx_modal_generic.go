@@ -5,18 +5,23 @@ package ui
 import (
 	"time"
 
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 )
 
-// DetailsModalContent implements ModalContentProvider for stream details
+// FpgaRxModalContent implements ModalContentProvider for receiving a stream
+// with a RAVENNA FPGA, which is only supported on Linux
 type FpgaRxModalContent struct {
 }
 
-func NewFpgaRxModalContent(stream *stream.Stream) *FpgaRxModalContent {
+// NewFpgaRxModalContent creates a new FPGA RX modal content provider
+func NewFpgaRxModalContent(keyMap *keymap.KeyMap, stream *stream.Stream, params *config.FPGA) *FpgaRxModalContent {
 	return &FpgaRxModalContent{}
 }
 
-func FpgaRxModalContentAvailable() bool {
+// FpgaRxModalContentAvailable returns whether the stream device exists
+func FpgaRxModalContentAvailable(device string) bool {
 	return false
 }
 
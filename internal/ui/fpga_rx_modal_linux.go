@@ -5,108 +5,229 @@ package ui
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	rsd "github.com/holoplot/ravenna-fpga-drivers/go/stream-device"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/stats"
 	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
 	"github.com/pion/rtp/v2"
 )
 
-const (
-	streamDeviceName           = "/dev/ravenna-stream-device"
-	streamDeviceSampleRate     = 48000
-	streamDeviceStartTrack     = 0
-	streamDeviceRtpOffset      = 500
-	streamDeviceRtpPayloadType = 98
-)
+// fpgaField is a parameter of the edit form of the FPGA RX modal
+type fpgaField struct {
+	label string
+	get   func(p *config.FPGA) int
+	set   func(p *config.FPGA, v int)
+	min   int
+	max   int
+}
+
+// fpgaFields lists the parameters of the edit form in the order they are
+// shown
+var fpgaFields = []fpgaField{
+	{
+		label: "Payload type (0: SDP)",
+		get:   func(p *config.FPGA) int { return int(p.PayloadType) },
+		set:   func(p *config.FPGA, v int) { p.PayloadType = uint8(v) },
+		max:   127,
+	},
+	{
+		label: "RTP offset (samples)",
+		get:   func(p *config.FPGA) int { return p.RTPOffset },
+		set:   func(p *config.FPGA, v int) { p.RTPOffset = v },
+		min:   1,
+		max:   65535,
+	},
+	{
+		label: "Start track",
+		get:   func(p *config.FPGA) int { return p.StartTrack },
+		set:   func(p *config.FPGA, v int) { p.StartTrack = v },
+		max:   rsd.MaxChannels - 1,
+	},
+}
+
+// fpgaCounters follows the RTCP counters of one interface of the FPGA
+// between readings
+type fpgaCounters struct {
+	received   *stats.Counter
+	misordered *stats.Counter
+	late       *stats.Counter
+	early      *stats.Counter
+	timeouts   *stats.Counter
+
+	peakJitter *stats.Gauge
+	jitter     *stats.Gauge
+	marginMin  *stats.Gauge
+	marginMax  *stats.Gauge
+}
+
+func newFpgaCounters() *fpgaCounters {
+	return &fpgaCounters{
+		received:   stats.NewCounter(32),
+		misordered: stats.NewCounter(16),
+		late:       stats.NewCounter(16),
+		early:      stats.NewCounter(16),
+		timeouts:   stats.NewCounter(16),
+		peakJitter: stats.NewGauge(),
+		jitter:     stats.NewGauge(),
+		marginMin:  stats.NewGauge(),
+		marginMax:  stats.NewGauge(),
+	}
+}
+
+// update accounts a reading of the counters
+func (c *fpgaCounters) update(i rsd.RxRTCPInterfaceData, now time.Time) {
+	c.received.Update(uint64(i.ReceivedPackets), now)
+	c.misordered.Update(uint64(i.MisorderedPackets), now)
+	c.late.Update(uint64(i.LatePackets), now)
+	c.early.Update(uint64(i.EarlyPackets), now)
+	c.timeouts.Update(uint64(i.TimeoutCounter), now)
+
+	c.peakJitter.Update(float64(i.PeakJitter))
+	c.jitter.Update(float64(i.EstimatedJitter))
+	c.marginMin.Update(float64(i.BufferMarginMin))
+	c.marginMax.Update(float64(i.BufferMarginMax))
+}
+
+// reset starts the totals and extremes over
+func (c *fpgaCounters) reset() {
+	for _, counter := range []*stats.Counter{c.received, c.misordered, c.late, c.early, c.timeouts} {
+		counter.Reset()
+	}
+
+	for _, gauge := range []*stats.Gauge{c.peakJitter, c.jitter, c.marginMin, c.marginMax} {
+		gauge.Reset()
+	}
+}
+
+// counterLabel formats a raw counter with its rate, the extremes of the
+// rate and its increase since the last reset
+func counterLabel(raw uint64, c *stats.Counter) string {
+	if math.IsNaN(c.MinRate) {
+		return fmt.Sprintf("%-10d", raw)
+	}
 
-// DetailsModalContent implements ModalContentProvider for stream details
+	return fmt.Sprintf("%-10d %+.0f/s (%.0f to %.0f/s), %d since reset", raw, c.Rate, c.MinRate, c.MaxRate, c.Total)
+}
+
+// gaugeLabel formats a raw value with its extremes since the last reset
+func gaugeLabel(raw int64, g *stats.Gauge) string {
+	if math.IsNaN(g.Min) {
+		return fmt.Sprintf("%-10d", raw)
+	}
+
+	return fmt.Sprintf("%-10d %.0f to %.0f since reset", raw, g.Min, g.Max)
+}
+
+// FpgaRxModalContent implements ModalContentProvider for receiving a stream
+// with a RAVENNA FPGA
 type FpgaRxModalContent struct {
 	mutex sync.Mutex
 
 	stream   *stream.Stream
 	receiver *stream.RTPReceiver
 
+	// params are shared with later modals, so changes made in the edit
+	// form last for the session
+	params *config.FPGA
+	keyMap *keymap.KeyMap
+
+	// The edit form is shown while editing is set. typing is set while
+	// the value of the selected field is entered.
+	editing  bool
+	typing   bool
+	selected int
+	input    string
+	formErr  error
+
 	streamDevice *rsd.Device
 	rxStream     *rsd.RxStream
 	rtcpData     *rsd.RxRTCPData
 
+	// The counters of the primary and secondary interface and the path
+	// differential, since resetAt
+	primary          *fpgaCounters
+	secondary        *fpgaCounters
+	pathDifferential *stats.Gauge
+	resetAt          time.Time
+
 	lastUpdate time.Time
 	err        error
 	cancelFunc context.CancelFunc
+	wg         sync.WaitGroup
 }
 
-func NewFpgaRxModalContent(stream *stream.Stream) *FpgaRxModalContent {
+// NewFpgaRxModalContent creates a new FPGA RX modal content provider that
+// sets up the stream with the given parameters
+func NewFpgaRxModalContent(keyMap *keymap.KeyMap, stream *stream.Stream, params *config.FPGA) *FpgaRxModalContent {
 	d := &FpgaRxModalContent{
-		stream: stream,
+		stream:           stream,
+		params:           params,
+		keyMap:           keyMap,
+		primary:          newFpgaCounters(),
+		secondary:        newFpgaCounters(),
+		pathDifferential: stats.NewGauge(),
 	}
 
 	return d
 }
 
-func FpgaRxModalContentAvailable() bool {
-	if _, err := os.Stat(streamDeviceName); err == nil {
+// FpgaRxModalContentAvailable returns whether the stream device exists
+func FpgaRxModalContentAvailable(device string) bool {
+	if _, err := os.Stat(device); err == nil {
 		return true
 	}
 
 	return false
 }
 
-func (d *FpgaRxModalContent) Init(width, _ int) {
-	d.lastUpdate = time.Now()
-
-	if d.stream.Description.SampleRate != streamDeviceSampleRate {
-		d.err = fmt.Errorf("error: sample rate is not %d Hz", streamDeviceSampleRate)
-
-		return
-	}
-
-	var codecType rsd.Codec
-
+// codec returns the codec of the FPGA for the content type of the stream
+func (d *FpgaRxModalContent) codec() (rsd.Codec, error) {
 	switch d.stream.Description.ContentType {
 	case stream.ContentTypePCM24:
-		codecType = rsd.StreamCodecL24
-	default:
-		d.err = fmt.Errorf("error: unsupported content type")
-
-		return
-	}
-
-	var err error
-
-	// Create a dummy RTP receiver to join the multicast group
-	d.receiver, err = d.stream.NewRTPReceiver(func(_ int, _ net.Addr, _ *rtp.Packet) {})
-	if err != nil {
-		d.err = fmt.Errorf("error creating RTP receiver: %v", err)
-
-		return
+		return rsd.StreamCodecL24, nil
+	case stream.ContentTypePCM16:
+		return rsd.StreamCodecL16, nil
 	}
 
-	d.streamDevice, err = rsd.Open(streamDeviceName)
-	if err != nil {
-		d.err = fmt.Errorf("error opening stream device: %v", err)
+	return 0, fmt.Errorf("unsupported content type %s", d.stream.Description.ContentType)
+}
 
-		return
+// description returns the description of the RX stream with the current
+// parameters
+func (d *FpgaRxModalContent) description(codecType rsd.Codec) rsd.RxStreamDescription {
+	payloadType := d.stream.Description.PayloadType
+	if d.params.PayloadType != 0 {
+		payloadType = d.params.PayloadType
 	}
 
 	rxDesc := rsd.RxStreamDescription{
 		Active:             true,
 		Synchronous:        true,
 		CodecType:          codecType,
-		RtpPayloadType:     streamDeviceRtpPayloadType,
-		RtpOffset:          streamDeviceRtpOffset,
-		JitterBufferMargin: streamDeviceRtpOffset,
+		RtpPayloadType:     payloadType,
+		RtpOffset:          uint32(d.params.RTPOffset),
+		JitterBufferMargin: uint16(d.params.RTPOffset),
 		NumChannels:        uint16(d.stream.Description.ChannelCount),
 	}
 
 	for ch := range d.stream.Description.ChannelCount {
-		rxDesc.Tracks[ch] = streamDeviceStartTrack + int16(ch)
+		rxDesc.Tracks[ch] = int16(d.params.StartTrack + int(ch))
 	}
 
+	// The FPGA receives a primary and a secondary destination, further
+	// sources are left out
 	for i, source := range d.stream.Description.Sources {
 		switch i {
 		case 0:
@@ -121,14 +242,67 @@ func (d *FpgaRxModalContent) Init(width, _ int) {
 			}
 
 			// rxDesc.HitlessProtection = true
-		default:
-			d.err = fmt.Errorf("too many sources")
+		}
+	}
+
+	return rxDesc
+}
+
+// checkTracks returns an error if the channels of the stream don't fit the
+// tracks of the device from the start track on
+func (d *FpgaRxModalContent) checkTracks() error {
+	if last := d.params.StartTrack + int(d.stream.Description.ChannelCount); last > d.streamDevice.Info().MaxTracks {
+		return fmt.Errorf("channels end at track %d, the device has %d tracks", last-1, d.streamDevice.Info().MaxTracks)
+	}
+
+	return nil
+}
 
-			return
+func (d *FpgaRxModalContent) Init(width, _ int) {
+	d.lastUpdate = time.Now()
+	d.resetAt = d.lastUpdate
+
+	if !slices.Contains(d.params.SampleRates, d.stream.Description.SampleRate) {
+		rates := make([]string, len(d.params.SampleRates))
+		for i, rate := range d.params.SampleRates {
+			rates[i] = fmt.Sprintf("%d Hz", rate)
 		}
+
+		d.err = fmt.Errorf("error: sample rate of %d Hz is not supported, the FPGA runs at %s (sample_rates in the fpga section of the config file)",
+			d.stream.Description.SampleRate, strings.Join(rates, ", "))
+
+		return
 	}
 
-	d.rxStream, err = d.streamDevice.AddRxStream(rxDesc)
+	codecType, err := d.codec()
+	if err != nil {
+		d.err = fmt.Errorf("error: %w", err)
+
+		return
+	}
+
+	// Create a dummy RTP receiver to join the multicast group
+	d.receiver, err = d.stream.NewRTPReceiver(func(_ int, _ net.Addr, _ *rtp.Packet, _ time.Time) {})
+	if err != nil {
+		d.err = fmt.Errorf("error creating RTP receiver: %v", err)
+
+		return
+	}
+
+	d.streamDevice, err = rsd.Open(d.params.Device)
+	if err != nil {
+		d.err = fmt.Errorf("error opening stream device: %v", err)
+
+		return
+	}
+
+	if err := d.checkTracks(); err != nil {
+		d.err = fmt.Errorf("error: %w", err)
+
+		return
+	}
+
+	d.rxStream, err = d.streamDevice.AddRxStream(d.description(codecType))
 	if err != nil {
 		d.err = fmt.Errorf("error adding RX stream: %v", err)
 
@@ -138,17 +312,25 @@ func (d *FpgaRxModalContent) Init(width, _ int) {
 	ctx, cancel := context.WithCancel(context.Background())
 	d.cancelFunc = cancel
 
+	d.wg.Add(1)
 	go func() {
+		defer d.wg.Done()
+
 		for {
 			select {
 			case <-ctx.Done():
-				// return
+				return
 			case <-time.After(time.Second):
 				rtcpData, err := d.rxStream.ReadRTCP(time.Second)
 				if err == nil {
+					now := time.Now()
+
 					d.mutex.Lock()
 					d.rtcpData = &rtcpData
-					d.lastUpdate = time.Now()
+					d.lastUpdate = now
+					d.primary.update(rtcpData.Primary, now)
+					d.secondary.update(rtcpData.Secondary, now)
+					d.pathDifferential.Update(float64(rtcpData.PathDifferential))
 					d.mutex.Unlock()
 				}
 			}
@@ -161,6 +343,9 @@ func (d *FpgaRxModalContent) Close() {
 		d.cancelFunc()
 	}
 
+	// The RTCP loop reads from the RX stream until it returns
+	d.wg.Wait()
+
 	if d.receiver != nil {
 		d.receiver.Close()
 	}
@@ -182,8 +367,17 @@ func (d *FpgaRxModalContent) Content() []string {
 		return l.lines()
 	}
 
+	if d.editing {
+		d.form(l)
+	}
+
 	desc := d.rxStream.Description()
 
+	if n := len(d.stream.Description.Sources); n > 2 {
+		l.p("Only the first 2 of %d sources are received, the FPGA supports a primary and a secondary destination", n)
+		l.p("")
+	}
+
 	l.p("Description (stream index %d):", d.rxStream.Index())
 	l.p("  ├─ Primary Destination:   %s", desc.PrimaryDestination.String())
 	l.p("  ├─ Secondary Destination: %s", desc.SecondaryDestination.String())
@@ -203,38 +397,38 @@ func (d *FpgaRxModalContent) Content() []string {
 	l.p("")
 
 	if d.rtcpData != nil {
-		l.p("RTCP statistics:")
+		l.p("RTCP statistics (counters reset %s ago, %s resets them):", time.Since(d.resetAt).Truncate(time.Second), d.keyMap.Label(keymap.ActionClear))
 		l.p("  ├─ Last update:       %s", d.lastUpdate.Format(time.RFC3339))
 		l.p("  ├─ RTP Timestamp:     %d", d.rtcpData.RtpTimestamp)
 		l.p("  ├─ Device State:      %d", d.rtcpData.DevState)
 		l.p("  ├─ RTP Payload ID:    %d", d.rtcpData.RtpPayloadId)
 		l.p("  ├─ Offset Estimation: %d", d.rtcpData.OffsetEstimation)
-		l.p("  └─ Path Differential: %d", d.rtcpData.PathDifferential)
+		l.p("  └─ Path Differential: %s", gaugeLabel(int64(d.rtcpData.PathDifferential), d.pathDifferential))
 		l.p("")
 
-		forInterface := func(s string, i rsd.RxRTCPInterfaceData) {
+		forInterface := func(s string, i rsd.RxRTCPInterfaceData, c *fpgaCounters) {
 			l.p("%s:", s)
 			l.p("  ├─ Playing:            %t", i.Playing)
 			l.p("  ├─ Error:              %t", i.Error)
-			l.p("  ├─ Misordered Packets: %d", i.MisorderedPackets)
+			l.p("  ├─ Misordered Packets: %s", counterLabel(uint64(i.MisorderedPackets), c.misordered))
 			l.p("  ├─ Base Sequence Nr:   %d", i.BaseSequenceNr)
 			l.p("  ├─ Extended Max SeqNr: %d", i.ExtendedMaxSequenceNr)
-			l.p("  ├─ Received Packets:   %d", i.ReceivedPackets)
-			l.p("  ├─ Peak Jitter:        %d", i.PeakJitter)
-			l.p("  ├─ Estimated Jitter:   %d", i.EstimatedJitter)
+			l.p("  ├─ Received Packets:   %s", counterLabel(uint64(i.ReceivedPackets), c.received))
+			l.p("  ├─ Peak Jitter:        %s", gaugeLabel(int64(i.PeakJitter), c.peakJitter))
+			l.p("  ├─ Estimated Jitter:   %s", gaugeLabel(int64(i.EstimatedJitter), c.jitter))
 			l.p("  ├─ Last Transit Time:  %d", i.LastTransitTime)
 			l.p("  ├─ Offset Estimation:  %d", i.CurrentOffsetEstimation)
 			l.p("  ├─ Last SSRC:          %08x", i.LastSsrc)
-			l.p("  ├─ Buffer Margin Min:  %d", i.BufferMarginMin)
-			l.p("  ├─ Buffer Margin Max:  %d", i.BufferMarginMax)
-			l.p("  ├─ Late Packets:       %d", i.LatePackets)
-			l.p("  ├─ Early Packets:      %d", i.EarlyPackets)
-			l.p("  └─ Timeout Counter:    %d", i.TimeoutCounter)
+			l.p("  ├─ Buffer Margin Min:  %s", gaugeLabel(int64(i.BufferMarginMin), c.marginMin))
+			l.p("  ├─ Buffer Margin Max:  %s", gaugeLabel(int64(i.BufferMarginMax), c.marginMax))
+			l.p("  ├─ Late Packets:       %s", counterLabel(uint64(i.LatePackets), c.late))
+			l.p("  ├─ Early Packets:      %s", counterLabel(uint64(i.EarlyPackets), c.early))
+			l.p("  └─ Timeout Counter:    %s", counterLabel(uint64(i.TimeoutCounter), c.timeouts))
 			l.p("")
 		}
 
-		forInterface("Primary", d.rtcpData.Primary)
-		forInterface("Secondary", d.rtcpData.Secondary)
+		forInterface("Primary", d.rtcpData.Primary, d.primary)
+		forInterface("Secondary", d.rtcpData.Secondary, d.secondary)
 	} else {
 		l.p("No RTCP data available")
 	}
@@ -242,6 +436,133 @@ func (d *FpgaRxModalContent) Content() []string {
 	return l.lines()
 }
 
+// form adds the edit form of the parameters
+func (d *FpgaRxModalContent) form(l *lineBuffer) {
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(theme.Colors.TableRowSelected).
+		Background(theme.Colors.TableRowSelectedBg).
+		Bold(true)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Colors.Secondary)
+
+	l.p("Parameters:")
+
+	for i, field := range fpgaFields {
+		value := strconv.Itoa(field.get(d.params))
+		if d.typing && i == d.selected {
+			value = d.input + "█"
+		}
+
+		line := fmt.Sprintf("  %-24s %s", field.label, value)
+		if i == d.selected {
+			line = selectedStyle.Render(line)
+		}
+
+		l.p("%s", line)
+	}
+
+	if d.formErr != nil {
+		l.p("  Error: %s", d.formErr)
+	}
+
+	hint := fmt.Sprintf("↑/↓: select, Enter: edit, %s/Esc: close the form", d.keyMap.Label(keymap.ActionEdit))
+	if d.typing {
+		hint = "Enter: apply, Esc: cancel"
+	}
+
+	l.p("%s", hintStyle.Render("  "+hint))
+	l.p("")
+}
+
+// HandleKey implements ModalKeyHandler for the edit form of the parameters,
+// opened with the edit action
+func (d *FpgaRxModalContent) HandleKey(key string, action keymap.Action) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.err != nil {
+		return false
+	}
+
+	if !d.editing {
+		switch action {
+		case keymap.ActionEdit:
+			d.editing = true
+			d.formErr = nil
+		case keymap.ActionClear:
+			d.primary.reset()
+			d.secondary.reset()
+			d.pathDifferential.Reset()
+			d.resetAt = time.Now()
+		default:
+			return false
+		}
+
+		return true
+	}
+
+	if d.typing {
+		switch {
+		case key == "enter":
+			d.typing = false
+			d.formErr = d.apply()
+		case key == "esc":
+			d.typing = false
+		case key == "backspace":
+			if len(d.input) > 0 {
+				d.input = d.input[:len(d.input)-1]
+			}
+		case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+			d.input += key
+		}
+
+		// All other keys are consumed so they don't trigger actions
+		return true
+	}
+
+	switch {
+	case action == keymap.ActionUp:
+		d.selected = max(d.selected-1, 0)
+	case action == keymap.ActionDown:
+		d.selected = min(d.selected+1, len(fpgaFields)-1)
+	case key == "enter":
+		d.typing = true
+		d.input = strconv.Itoa(fpgaFields[d.selected].get(d.params))
+	case action == keymap.ActionEdit || key == "esc":
+		d.editing = false
+	default:
+		return false
+	}
+
+	return true
+}
+
+// apply sets the selected parameter to the entered value and updates the
+// RX stream. The caller must hold the mutex.
+func (d *FpgaRxModalContent) apply() error {
+	field := fpgaFields[d.selected]
+
+	v, err := strconv.Atoi(d.input)
+	if err != nil || v < field.min || v > field.max {
+		return fmt.Errorf("%s must be between %d and %d", field.label, field.min, field.max)
+	}
+
+	previous := field.get(d.params)
+	field.set(d.params, v)
+
+	if err := d.checkTracks(); err != nil {
+		field.set(d.params, previous)
+		return err
+	}
+
+	codecType, _ := d.codec()
+	if err := d.rxStream.Update(d.description(codecType)); err != nil {
+		field.set(d.params, previous)
+		return fmt.Errorf("error updating RX stream: %w", err)
+	}
+
+	return nil
+}
+
 func (d *FpgaRxModalContent) Title() string {
 	return "RAVENNA FPGA RX STREAMING"
 }
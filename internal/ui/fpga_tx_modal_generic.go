@@ -0,0 +1,54 @@
+//go:build !linux
+
+package ui
+
+import (
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// FpgaTxModalContent implements ModalContentProvider for a test stream sent
+// by a RAVENNA FPGA, which is only supported on Linux
+type FpgaTxModalContent struct {
+}
+
+// NewFpgaTxModalContent creates a new FPGA TX modal content provider
+func NewFpgaTxModalContent(keyMap *keymap.KeyMap, s *stream.Stream, params *config.FPGA, manager *stream.Manager) *FpgaTxModalContent {
+	return &FpgaTxModalContent{}
+}
+
+// FpgaTxModalContentAvailable returns whether the stream device exists
+func FpgaTxModalContentAvailable(device string) bool {
+	return false
+}
+
+func (d *FpgaTxModalContent) Init(_, _ int) {}
+
+func (d *FpgaTxModalContent) Close() {
+}
+
+// Content returns the content lines to be displayed
+func (d *FpgaTxModalContent) Content() []string {
+	return []string{"FPGA streaming is only available on Linux"}
+}
+
+func (d *FpgaTxModalContent) Title() string {
+	return "RAVENNA FPGA TX TEST STREAM [UNAVAILABLE]"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (d *FpgaTxModalContent) UpdateInterval() time.Duration {
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (d *FpgaTxModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically to refresh content
+func (d *FpgaTxModalContent) Update() {
+}
@@ -0,0 +1,294 @@
+//go:build linux
+
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	rsd "github.com/holoplot/ravenna-fpga-drivers/go/stream-device"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// FpgaTxModalContent implements ModalContentProvider for a test stream sent
+// by a RAVENNA FPGA. The selected stream is received into the tracks of the
+// FPGA as in the RX modal and sent back out from them, and the sent stream
+// is added to the stream list, so the hardware path can be checked end to
+// end.
+type FpgaTxModalContent struct {
+	mutex sync.Mutex
+
+	stream  *stream.Stream
+	params  *config.FPGA
+	manager *stream.Manager
+
+	// rx receives the looped back stream into the tracks
+	rx *FpgaRxModalContent
+
+	txStream *rsd.TxStream
+	rtcpData *rsd.TxRTCPData
+
+	// loopback is the sent stream as added to the stream list
+	loopback *stream.Stream
+
+	lastUpdate time.Time
+	err        error
+	cancelFunc context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewFpgaTxModalContent creates a new FPGA TX modal content provider that
+// loops back the given stream
+func NewFpgaTxModalContent(keyMap *keymap.KeyMap, s *stream.Stream, params *config.FPGA, manager *stream.Manager) *FpgaTxModalContent {
+	return &FpgaTxModalContent{
+		stream:  s,
+		params:  params,
+		manager: manager,
+		rx:      NewFpgaRxModalContent(keyMap, s, params),
+	}
+}
+
+// FpgaTxModalContentAvailable returns whether the stream device exists
+func FpgaTxModalContentAvailable(device string) bool {
+	return FpgaRxModalContentAvailable(device)
+}
+
+func (d *FpgaTxModalContent) Init(width, height int) {
+	d.lastUpdate = time.Now()
+
+	source := net.ParseIP(d.params.TXSource).To4()
+	if source == nil {
+		d.err = fmt.Errorf("error: tx_source in the fpga section of the config must be the IPv4 address of the network interface of the FPGA")
+
+		return
+	}
+
+	destination, err := net.ResolveUDPAddr("udp4", d.params.TXDestination)
+	if err != nil {
+		d.err = fmt.Errorf("error: invalid TX destination %q: %w", d.params.TXDestination, err)
+
+		return
+	}
+
+	d.rx.Init(width, height)
+	if d.rx.err != nil {
+		d.err = d.rx.err
+
+		return
+	}
+
+	desc := d.rx.description(d.rx.rxStream.Description().CodecType)
+
+	samples := d.framesPerPacket()
+	if samples > 255 {
+		d.err = fmt.Errorf("error: the FPGA sends at most 255 samples per packet, the stream has %d", samples)
+
+		return
+	}
+
+	txDesc := rsd.TxStreamDescription{
+		Primary: rsd.TxStreamDescriptionNetworkInterface{
+			Destination: *destination,
+			Source:      net.UDPAddr{IP: source, Port: destination.Port},
+		},
+		Active:         true,
+		Multicast:      destination.IP.IsMulticast(),
+		UsePrimary:     true,
+		CodecType:      desc.CodecType,
+		NumSamples:     uint8(samples),
+		RtpPayloadType: desc.RtpPayloadType,
+		Ttl:            d.ttl(),
+		RtpSsrc:        rand.Uint32(),
+		NumChannels:    desc.NumChannels,
+		Tracks:         desc.Tracks,
+	}
+
+	d.txStream, err = d.rx.streamDevice.AddTxStream(txDesc)
+	if err != nil {
+		d.err = fmt.Errorf("error adding TX stream: %v", err)
+
+		return
+	}
+
+	d.loopback, err = d.manager.AddStreamFromSDP(d.sdp(txDesc, samples), stream.DiscoveryMethodManual, "FPGA TX")
+	if err != nil {
+		d.err = fmt.Errorf("error adding the sent stream to the stream list: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancelFunc = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+				rtcpData, err := d.txStream.ReadRTCP(time.Second)
+				if err == nil {
+					d.mutex.Lock()
+					d.rtcpData = &rtcpData
+					d.lastUpdate = time.Now()
+					d.mutex.Unlock()
+				}
+			}
+		}
+	}()
+}
+
+// framesPerPacket returns the number of frames per packet of the looped
+// back stream, 1 ms worth if it is not known, which is 48 frames at 44.1 kHz
+// as at 48 kHz
+func (d *FpgaTxModalContent) framesPerPacket() int {
+	source := d.stream.Description.Sources[0]
+
+	switch {
+	case source.FramesPerPacket > 0:
+		return int(source.FramesPerPacket)
+	case source.PacketTime > 0:
+		return int(stream.FramesIn(source.PacketTime, d.stream.Description.SampleRate))
+	}
+
+	return int(stream.NominalRate(d.stream.Description.SampleRate) / 1000)
+}
+
+// ttl returns the TTL of the looped back stream, at least 1
+func (d *FpgaTxModalContent) ttl() uint8 {
+	return max(d.stream.Description.Sources[0].TTL, 1)
+}
+
+// sdp returns the session description of the sent stream
+func (d *FpgaTxModalContent) sdp(txDesc rsd.TxStreamDescription, samples int) []byte {
+	desc := d.stream.Description
+	source := desc.Sources[0]
+	packetTime := (time.Duration(samples) * time.Second / time.Duration(desc.SampleRate)).Round(time.Microsecond)
+
+	lines := []string{
+		"v=0",
+		fmt.Sprintf("o=- %d 0 IN IP4 %s", txDesc.RtpSsrc, txDesc.Primary.Source.IP),
+		fmt.Sprintf("s=%s (FPGA loopback)", d.stream.Name()),
+		fmt.Sprintf("c=IN IP4 %s/%d", txDesc.Primary.Destination.IP, txDesc.Ttl),
+		"t=0 0",
+		fmt.Sprintf("m=audio %d RTP/AVP %d", txDesc.Primary.Destination.Port, txDesc.RtpPayloadType),
+		fmt.Sprintf("a=rtpmap:%d %s/%d/%d", txDesc.RtpPayloadType, txDesc.CodecType, desc.SampleRate, txDesc.NumChannels),
+		"a=ptime:" + strconv.FormatFloat(float64(packetTime)/float64(time.Millisecond), 'f', -1, 64),
+		fmt.Sprintf("a=framecount:%d", samples),
+		"a=recvonly",
+	}
+
+	// The FPGA sends with the media clock the stream is received with
+	if source.ReferenceClock != "" {
+		lines = append(lines, "a=ts-refclk:"+source.ReferenceClock)
+	}
+
+	lines = append(lines, "a=mediaclk:direct=0")
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func (d *FpgaTxModalContent) Close() {
+	if d.cancelFunc != nil {
+		d.cancelFunc()
+	}
+
+	// The RTCP loop reads from the TX stream until it returns
+	d.wg.Wait()
+
+	if d.txStream != nil {
+		_ = d.txStream.Close()
+	}
+
+	if d.loopback != nil {
+		d.manager.RemoveStream(d.loopback.ID)
+	}
+
+	d.rx.Close()
+}
+
+// Content returns the content lines to be displayed
+func (d *FpgaTxModalContent) Content() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	l := newLineBuffer(lipgloss.NewStyle())
+
+	if d.err != nil {
+		l.p("Error: %s", d.err)
+		return l.lines()
+	}
+
+	desc := d.txStream.Description()
+
+	l.p("Description (stream index %d):", d.txStream.Index())
+	l.p("  ├─ Destination:      %s", desc.Primary.Destination.String())
+	l.p("  ├─ Source:           %s", desc.Primary.Source.String())
+	l.p("  ├─ Num Channels:     %d", desc.NumChannels)
+	l.p("  ├─ Samples/Packet:   %d", desc.NumSamples)
+	l.p("  ├─ Codec Type:       %s", desc.CodecType)
+	l.p("  ├─ RTP Payload Type: %d", desc.RtpPayloadType)
+	l.p("  ├─ RTP SSRC:         %08x", desc.RtpSsrc)
+	l.p("  ├─ TTL:              %d", desc.Ttl)
+	l.p("  ├─ Tracks:           %d-%d", desc.Tracks[0], desc.Tracks[desc.NumChannels-1])
+	l.p("  └─ Stream list:      %s", d.loopback.Name())
+	l.p("")
+
+	if d.rtcpData != nil {
+		l.p("RTCP statistics:")
+		l.p("  ├─ Last update:   %s", d.lastUpdate.Format(time.RFC3339))
+		l.p("  ├─ RTP Timestamp: %d", d.rtcpData.RtpTimestamp)
+		l.p("  ├─ Sent Packets:  %d", d.rtcpData.Primary.SentPackets)
+		l.p("  └─ Sent Bytes:    %d", d.rtcpData.Primary.SentRTPBytes)
+	} else {
+		l.p("No RTCP data available")
+	}
+
+	l.p("")
+	l.p("Looped back stream:")
+
+	for _, line := range d.rx.Content() {
+		l.p("  %s", line)
+	}
+
+	return l.lines()
+}
+
+// HandleKey implements ModalKeyHandler to reset the counters of the looped
+// back stream with the clear action. Its parameters can't be edited while its tracks are
+// sent.
+func (d *FpgaTxModalContent) HandleKey(key string, action keymap.Action) bool {
+	if action != keymap.ActionClear {
+		return false
+	}
+
+	return d.rx.HandleKey(key, action)
+}
+
+func (d *FpgaTxModalContent) Title() string {
+	return "RAVENNA FPGA TX TEST STREAM"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (d *FpgaTxModalContent) UpdateInterval() time.Duration {
+	return 500 * time.Millisecond
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (d *FpgaTxModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically to refresh content
+func (d *FpgaTxModalContent) Update() {
+}
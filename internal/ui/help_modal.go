@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+)
+
+// HelpModalContent implements ModalContentProvider for the key binding help
+type HelpModalContent struct {
+	keyMap *keymap.KeyMap
+
+	// fpgaRx lists the FPGA keys, which are only useful if the device exists
+	fpgaRx bool
+}
+
+// NewHelpModalContent creates a new help modal content provider
+func NewHelpModalContent(keyMap *keymap.KeyMap, fpgaRx bool) *HelpModalContent {
+	return &HelpModalContent{
+		keyMap: keyMap,
+		fpgaRx: fpgaRx,
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (h *HelpModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (h *HelpModalContent) Close() {}
+
+// Content returns the content lines to be displayed
+func (h *HelpModalContent) Content() []string {
+	l := newLineBuffer(lipgloss.NewStyle())
+
+	for _, b := range h.keyMap.Bindings() {
+		if (b.Action == keymap.ActionFpgaRx || b.Action == keymap.ActionFpgaTx) && !h.fpgaRx {
+			continue
+		}
+
+		labels := make([]string, len(b.Keys))
+		for i, key := range b.Keys {
+			labels[i] = keymap.KeyLabel(key)
+		}
+
+		l.p("  %-16s %s", strings.Join(labels, ", "), b.Description)
+	}
+
+	return l.lines()
+}
+
+// Title returns the modal title
+func (h *HelpModalContent) Title() string {
+	return "KEY BINDINGS"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (h *HelpModalContent) UpdateInterval() time.Duration {
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (h *HelpModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (h *HelpModalContent) Update() {}
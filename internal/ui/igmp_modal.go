@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/igmp"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// IGMPModalContent implements ModalContentProvider for the IGMP and
+// multicast join diagnostics of each interface
+type IGMPModalContent struct {
+	manager *stream.Manager
+	monitor *igmp.Monitor // nil if IGMP messages are not monitored
+	err     error
+}
+
+// NewIGMPModalContent creates a new IGMP diagnostics content provider.
+// monitorErr is the error that prevented monitoring IGMP messages, if any.
+func NewIGMPModalContent(manager *stream.Manager, monitor *igmp.Monitor, monitorErr error) *IGMPModalContent {
+	return &IGMPModalContent{
+		manager: manager,
+		monitor: monitor,
+		err:     monitorErr,
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (g *IGMPModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (g *IGMPModalContent) Close() {}
+
+// ago formats the time since t, or "never" for a zero time
+func ago(now, t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return now.Sub(t).Truncate(time.Second).String() + " ago"
+}
+
+// Content returns the content lines to be displayed
+func (g *IGMPModalContent) Content() []string {
+	l := newLineBuffer(lipgloss.NewStyle())
+	now := time.Now()
+
+	interfaces := make(map[string]*igmp.Interface)
+
+	if g.monitor == nil {
+		l.p("IGMP monitoring unavailable: %v", g.err)
+		l.p("")
+	} else {
+		for _, i := range g.monitor.Interfaces() {
+			interfaces[i.Name] = &i
+
+			l.p("Interface %s", i.Name)
+
+			if i.Querier == nil {
+				l.p("  ├─ Querier:             none seen since %s", g.monitor.Started().Format(time.TimeOnly))
+			} else {
+				state := "present"
+				if !i.QuerierPresent(now) {
+					state = "gone"
+				}
+
+				l.p("  ├─ Querier:             %s (IGMPv%d), %s", i.Querier, i.QueryVersion, state)
+				l.p("  ├─ Last query:          %s, %d in total", ago(now, i.LastQuery), i.Queries)
+				l.p("  ├─ Query interval:      %s", i.QueryInterval)
+			}
+
+			l.p("  ├─ Reports:             %d, last %s", i.Reports, ago(now, i.LastReport))
+
+			if i.Querier != nil {
+				l.p("  ├─ Answering reports:   %d since the last query", i.ReportsAfterQuery)
+			}
+
+			l.p("  └─ Groups reported:     %d", len(i.Groups))
+			l.p("")
+		}
+	}
+
+	joins := g.manager.Joins()
+
+	l.p("Joined groups (%d)", len(joins))
+
+	if len(joins) == 0 {
+		l.p("  Open a view of a stream or enable its background statistics to join its groups")
+	}
+
+	for _, j := range joins {
+		source := "any source"
+		if j.Source != nil {
+			source = "from " + j.Source.String()
+		}
+
+		l.p("  %s on %s, %s, joined %s", j.Group, j.Interface, source, ago(now, j.Joined))
+		l.p("    %d packets, last %s: %s", j.Packets, ago(now, j.LastPacket),
+			igmp.Diagnose(interfaces[j.Interface], j.Joined, j.Packets, j.LastPacket, now))
+
+		if j.Interruptions > 0 {
+			last := j.LastInterruption
+			l.p("    %d delivery interruptions, last %s for %s, %d packets lost", j.Interruptions,
+				ago(now, last.End), last.Duration().Round(time.Millisecond), last.Lost)
+		}
+
+		if j.Oversized > 0 {
+			l.p("    %d packets dropped as they exceeded the MTU of %s", j.Oversized, j.Interface)
+		}
+	}
+
+	return l.lines()
+}
+
+// Title returns the modal title
+func (g *IGMPModalContent) Title() string {
+	return "IGMP DIAGNOSTICS"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (g *IGMPModalContent) UpdateInterval() time.Duration {
+	return time.Second
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (g *IGMPModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (g *IGMPModalContent) Update() {}
@@ -10,19 +10,13 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
-	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/levels"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/holoplot/rtp-monitor/internal/theme"
 	"github.com/pion/rtp/v2"
 )
 
-type floatSample float64
-
-const (
-	clipThreshold = -0.1 // dBFS
-	clipTimeout   = time.Second * 5
-)
-
 // MeterModalContent implements ModalContentProvider for Meter meter display
 type MeterModalContent struct {
 	mutex sync.Mutex
@@ -32,11 +26,23 @@ type MeterModalContent struct {
 	styles       MeterModalStyles
 	contentWidth int
 
+	keyMap   *keymap.KeyMap
 	stream   *stream.Stream
 	receiver *stream.RTPReceiver
 
 	err error
 
+	// clipThreshold is the peak level in dBFS above which a channel clips
+	clipThreshold float64
+	clipHold      time.Duration
+
+	// layout and page are changed with keys; page 0 shows all sources
+	layout meterLayout
+	page   int
+
+	// availableWidth is the width of the modal content area
+	availableWidth int
+
 	sourceMeters []*sourceMeters
 }
 
@@ -48,24 +54,35 @@ type MeterModalStyles struct {
 	Background lipgloss.Style
 }
 
+// meterWindow is the time constant of the levels shown
+const meterWindow = 50 * time.Millisecond
+
 type sourceMeters struct {
 	channelMeters []*channelMeter
-	lastUpdate    time.Time
+
+	// mutex guards the levels, which are updated as packets arrive, and
+	// the decode buffer reused for every packet of the source
+	mutex      sync.Mutex
+	lastUpdate time.Time
+	samples    []stream.Sample
 }
 
 // channelMeter holds the current state of a meter channel
 type channelMeter struct {
-	levels      *ring.RingBuffer[floatSample]
+	level       levels.Running
 	clipTime    time.Time
 	progressBar *MeterProgress
 }
 
 // NewMeterModalContent creates a new Meter modal content provider
-func NewMeterModalContent(s *stream.Stream) *MeterModalContent {
+func NewMeterModalContent(keyMap *keymap.KeyMap, s *stream.Stream, clipThreshold float64, clipHold time.Duration) *MeterModalContent {
 	v := &MeterModalContent{
-		stream:       s,
-		styles:       createMeterModalStyles(),
-		sourceMeters: make([]*sourceMeters, len(s.Description.Sources)),
+		keyMap:        keyMap,
+		stream:        s,
+		styles:        createMeterModalStyles(),
+		clipThreshold: clipThreshold,
+		clipHold:      clipHold,
+		sourceMeters:  make([]*sourceMeters, len(s.Description.Sources)),
 	}
 
 	for i := range len(s.Description.Sources) {
@@ -78,7 +95,7 @@ func NewMeterModalContent(s *stream.Stream) *MeterModalContent {
 
 		for i := range s.Description.ChannelCount {
 			sourceMeter.channelMeters[i] = &channelMeter{
-				levels:      ring.NewRingBuffer[floatSample](2400),
+				level:       levels.NewRunning(meterWindow, s.Description.SampleRate),
 				progressBar: NewMeterProgress(50, v.styles.Background), // Default width
 			}
 		}
@@ -106,7 +123,7 @@ func createMeterModalStyles() MeterModalStyles {
 	}
 }
 
-func (v *MeterModalContent) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet) {
+func (v *MeterModalContent) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet, arrival time.Time) {
 	// The callback might fire before NewRTPReceiver() returns. Just ignore that packet.
 	if v.receiver == nil {
 		return
@@ -116,19 +133,23 @@ func (v *MeterModalContent) rtpReceiverCallback(sourceIndex int, _ net.Addr, pac
 		panic(fmt.Sprintf("source %d out of range", sourceIndex))
 	}
 
-	channelMeters := v.sourceMeters[sourceIndex].channelMeters
-	v.sourceMeters[sourceIndex].lastUpdate = time.Now()
+	meters := v.sourceMeters[sourceIndex]
+
+	meters.mutex.Lock()
+	defer meters.mutex.Unlock()
+
+	meters.lastUpdate = arrival
+
+	var err error
 
-	sampleFrames, err := v.receiver.ExtractSamples(packet)
-	if err != nil {
+	meters.samples, err = v.receiver.DecodeSamples(meters.samples, packet)
+	if err != nil || len(meters.channelMeters) == 0 {
 		return
 	}
 
-	for _, frame := range sampleFrames {
-		for ch, value := range frame {
-			s := floatSample(int32(value)) / floatSample(math.MaxInt32)
-			channelMeters[ch].levels.Push(s * s)
-		}
+	for i, value := range meters.samples {
+		s := float64(int32(value)) / math.MaxInt32
+		meters.channelMeters[i%len(meters.channelMeters)].level.Add(s * s)
 	}
 }
 
@@ -144,6 +165,13 @@ func (v *MeterModalContent) Init(width, height int) {
 	}
 	v.contentWidth -= 4 // Account for modal padding
 
+	v.availableWidth = modalAvailableWidth(width, height)
+
+	// Wide bars don't fit on narrow terminals
+	if width < narrowWidth {
+		v.layout = meterLayoutCompact
+	}
+
 	if receiver, err := v.stream.NewRTPReceiver(v.rtpReceiverCallback); err == nil {
 		v.receiver = receiver
 	} else {
@@ -160,66 +188,224 @@ func (v *MeterModalContent) Close() {
 	}
 }
 
+// meterLayout selects how the channels of a source are drawn
+type meterLayout int
+
+const (
+	// meterLayoutRows draws one wide bar per channel
+	meterLayoutRows meterLayout = iota
+
+	// meterLayoutCompact draws short bars in as many columns as fit
+	meterLayoutCompact
+
+	// meterLayoutVertical draws one vertical bar per channel
+	meterLayoutVertical
+
+	meterLayoutCount
+)
+
+func (l meterLayout) String() string {
+	switch l {
+	case meterLayoutCompact:
+		return "compact"
+	case meterLayoutVertical:
+		return "vertical"
+	default:
+		return "rows"
+	}
+}
+
+const (
+	// compactCellWidth is the width of one channel in the compact layout
+	compactCellWidth = 26
+
+	// verticalMeterHeight is the number of lines of a vertical bar
+	verticalMeterHeight = 12
+)
+
+// channelLevel holds the levels of a channel at render time
+type channelLevel struct {
+	rmsDB    float64
+	peakDB   float64
+	clipping bool
+}
+
+// channelLevels computes the current levels of all channels of a source.
+// The caller must hold v.mutex.
+func (v *MeterModalContent) channelLevels(sm *sourceMeters) []channelLevel {
+	result := make([]channelLevel, len(sm.channelMeters))
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	stale := time.Since(sm.lastUpdate) > time.Second
+
+	for ch, meter := range sm.channelMeters {
+		if stale {
+			meter.level.Reset()
+		}
+
+		level := meter.level.Level()
+
+		if level.Peak > v.clipThreshold {
+			meter.clipTime = time.Now()
+		}
+
+		result[ch] = channelLevel{
+			rmsDB:    level.RMS,
+			peakDB:   level.Peak,
+			clipping: time.Since(meter.clipTime) < v.clipHold,
+		}
+	}
+
+	return result
+}
+
 func (v *MeterModalContent) renderSourceMeters(sm *sourceMeters, meterWidth int) []string {
 	if len(sm.channelMeters) == 0 {
 		return []string{"No meter data available"}
 	}
 
+	levels := v.channelLevels(sm)
+
 	var lines []string
 
-	// dB Scale (shown once at the top)
-	scale := fmt.Sprintf("%15s%s", "", v.renderDBScale(meterWidth))
-	lines = append(lines, scale)
+	switch v.layout {
+	case meterLayoutCompact:
+		lines = v.renderCompactMeters(sm, levels)
+	case meterLayoutVertical:
+		lines = v.renderVerticalMeters(sm, levels)
+	default:
+		// dB Scale (shown once at the top)
+		scale := fmt.Sprintf("%15s%s", "", v.renderDBScale(meterWidth))
+		lines = append(lines, scale)
+		lines = append(lines, "")
+
+		for ch, meter := range sm.channelMeters {
+			level := levels[ch]
+
+			channelLabel := fmt.Sprintf("Ch%d", ch+1)
+			dbText := fmt.Sprintf("%6.1f dB", level.rmsDB)
+			meterLine := v.renderMeterMeter(meter, level.peakDB, level.rmsDB, meterWidth)
+			clipIndicator := v.renderClipIndicator(level.clipping)
+
+			line := fmt.Sprintf("  %-3s %s %s %s", channelLabel, dbText, meterLine, clipIndicator)
+			lines = append(lines, line)
+		}
+	}
+
+	lines = append(lines, "")
 	lines = append(lines, "")
 
-	for ch, meter := range sm.channelMeters {
-		if time.Since(sm.lastUpdate) > time.Second {
-			meter.levels.Clear()
+	return lines
+}
+
+// renderCompactMeters renders short bars in as many columns as fit the width
+func (v *MeterModalContent) renderCompactMeters(sm *sourceMeters, levels []channelLevel) []string {
+	columns := max(v.availableWidth/compactCellWidth, 1)
+	rows := (len(levels) + columns - 1) / columns
+
+	// Label, dB value and clip marker take 14 characters
+	barWidth := compactCellWidth - 15
+
+	lines := make([]string, rows)
+
+	// Fill column by column, so channels are numbered top to bottom
+	for ch, level := range levels {
+		clip := " "
+		if level.clipping {
+			clip = v.styles.MeterClip.Render("!")
 		}
 
-		samples := meter.levels.ToSlice()
-		rmsDB := math.Inf(-1)
-		peakDB := math.Inf(-1)
+		cell := fmt.Sprintf("%4s %s %5.0f%s ",
+			fmt.Sprintf("%d", ch+1), v.renderMeterMeter(sm.channelMeters[ch], level.peakDB, level.rmsDB, barWidth),
+			math.Max(level.rmsDB, -99), clip)
 
-		if len(samples) > 0 {
-			sumSquares := floatSample(0)
-			peakSquared := floatSample(0)
+		lines[ch%rows] += cell
+	}
 
-			for _, sample := range samples {
-				sumSquares += sample
-				if sample > peakSquared {
-					peakSquared = sample
-				}
+	return lines
+}
+
+// renderVerticalMeters renders one vertical bar per channel, wrapping into
+// multiple blocks if the channels don't fit the width
+func (v *MeterModalContent) renderVerticalMeters(sm *sourceMeters, levels []channelLevel) []string {
+	// Two characters per channel, and a scale on the left
+	perBlock := max((v.availableWidth-6)/2, 1)
+
+	var lines []string
+
+	for start := 0; start < len(levels); start += perBlock {
+		end := min(start+perBlock, len(levels))
+
+		for row := range verticalMeterHeight {
+			// The threshold of this row, from 1.0 at the top down to the bottom
+			threshold := float64(verticalMeterHeight-row) / float64(verticalMeterHeight)
+
+			label := ""
+			if row%3 == 0 {
+				label = fmt.Sprintf("%d", int(threshold*100-100))
 			}
 
-			meanSquares := sumSquares / floatSample(len(samples))
-			rmsDB = 10 * math.Log10(float64(meanSquares))
-			peakDB = 10 * math.Log10(float64(peakSquared))
+			line := v.styles.ScaleLabel.Render(fmt.Sprintf("%5s ", label))
 
-			if math.IsNaN(rmsDB) {
-				panic(fmt.Sprintf("NaN encountered in channel %d, len(samples)=%d, meanSquares=%f samples=%v", ch+1, len(samples), meanSquares, samples))
+			color := lipgloss.NewStyle().Foreground(sm.channelMeters[start].progressBar.getGradientColor(threshold))
+
+			for ch := start; ch < end; ch++ {
+				level := levels[ch]
+
+				switch {
+				case row == 0 && level.clipping:
+					line += v.styles.MeterClip.Render("▀") + " "
+				case v.dbToPercentage(level.rmsDB) >= threshold:
+					line += color.Render("█") + " "
+				case v.dbToPercentage(level.peakDB) >= threshold:
+					line += color.Render("▒") + " "
+				default:
+					line += "  "
+				}
 			}
 
-			if peakDB > clipThreshold {
-				meter.clipTime = time.Now()
+			lines = append(lines, line)
+		}
+
+		// Channel numbers, tens above ones
+		tens, ones := "      ", "      "
+		for ch := start; ch < end; ch++ {
+			n := ch + 1
+			if n >= 10 {
+				tens += fmt.Sprintf("%d ", (n/10)%10)
+			} else {
+				tens += "  "
 			}
+			ones += fmt.Sprintf("%d ", n%10)
 		}
 
-		clipping := time.Since(meter.clipTime) < clipTimeout
+		lines = append(lines, v.styles.ScaleLabel.Render(tens), v.styles.ScaleLabel.Render(ones), "")
+	}
 
-		channelLabel := fmt.Sprintf("Ch%d", ch+1)
-		dbText := fmt.Sprintf("%6.1f dB", rmsDB)
-		meterLine := v.renderMeterMeter(meter, peakDB, rmsDB, meterWidth)
-		clipIndicator := v.renderClipIndicator(clipping)
+	return lines
+}
 
-		line := fmt.Sprintf("  %-3s %s %s %s", channelLabel, dbText, meterLine, clipIndicator)
-		lines = append(lines, line)
-	}
+// HandleKey implements ModalKeyHandler to switch layouts and page through sources
+func (v *MeterModalContent) HandleKey(_ string, action keymap.Action) bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
 
-	lines = append(lines, "")
-	lines = append(lines, "")
+	sources := len(v.stream.Description.Sources)
+
+	switch action {
+	case keymap.ActionMeterLayout:
+		v.layout = (v.layout + 1) % meterLayoutCount
+	case keymap.ActionNextSource:
+		v.page = (v.page + 1) % (sources + 1)
+	case keymap.ActionPrevSource:
+		v.page = (v.page + sources) % (sources + 1)
+	default:
+		return false
+	}
 
-	return lines
+	return true
 }
 
 // Content returns the content lines to be displayed
@@ -238,10 +424,29 @@ func (v *MeterModalContent) Content() []string {
 		return lines
 	}
 
+	page := "all sources"
+	if v.page > 0 {
+		page = fmt.Sprintf("source %d/%d", v.page, len(v.stream.Description.Sources))
+	}
+
+	lines = append(lines, v.styles.ScaleLabel.Render(
+		fmt.Sprintf("Layout: %s, showing %s | %s: layout, %s/%s: source", v.layout, page,
+			v.keyMap.Label(keymap.ActionMeterLayout), v.keyMap.Label(keymap.ActionPrevSource), v.keyMap.Label(keymap.ActionNextSource))), "")
+
 	for i, source := range v.stream.Description.Sources {
+		if v.page > 0 && i != v.page-1 {
+			continue
+		}
+
 		ip := fmt.Sprintf("%s:%d", source.DestinationAddress, source.DestinationPort)
 		lines = append(lines, fmt.Sprintf("%s:", ip))
 		lines = append(lines, "")
+
+		if err := v.receiver.SourceError(i); err != nil {
+			lines = append(lines, fmt.Sprintf("Not received: %v", err), "")
+			continue
+		}
+
 		lines = append(lines, v.renderSourceMeters(v.sourceMeters[i], meterWidth)...)
 	}
 
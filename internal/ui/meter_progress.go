@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/theme"
 	"github.com/lucasb-eyer/go-colorful"
 )
 
@@ -80,12 +81,19 @@ func (p *MeterProgress) getGradientColor(pos float64) lipgloss.Color {
 		pos = 1
 	}
 
-	// Simple gradient from green to red across the entire width
-	green, _ := colorful.Hex("#00FF00")
-	red, _ := colorful.Hex("#FF0000")
+	// Blend between the two stops around the position
+	stops := theme.Colors.MeterGradient
+	if len(stops) == 1 {
+		return stops[0]
+	}
+
+	scaled := pos * float64(len(stops)-1)
+	i := min(int(scaled), len(stops)-2)
+
+	from, _ := colorful.Hex(string(stops[i]))
+	to, _ := colorful.Hex(string(stops[i+1]))
 
-	// Blend from green to red based on position
-	c := green.BlendLuv(red, pos)
+	c := from.BlendLuv(to, scaled-float64(i))
 
 	return lipgloss.Color(c.Hex())
 }
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+)
+
+const (
+	// miniMeterWidth is the width of the level meters in the table
+	miniMeterWidth = 8
+
+	// miniMeterRange is the range of the level meters in the table, in dB
+	// below full scale
+	miniMeterRange = 60
+)
+
+// miniMeterBlocks are the characters that fill a cell of a level meter, from
+// an eighth to full
+var miniMeterBlocks = []rune("▏▎▍▌▋▊▉█")
+
+// miniMeter renders a peak level in dBFS as a bar of miniMeterWidth
+// characters, or "silent" below the range of the meter. Levels at or above
+// the clip threshold end in an exclamation mark.
+func miniMeter(peakDB, clipThreshold float64) string {
+	if peakDB <= -miniMeterRange {
+		return "silent"
+	}
+
+	eighths := int((peakDB + miniMeterRange) / miniMeterRange * miniMeterWidth * 8)
+	eighths = max(min(eighths, miniMeterWidth*8), 1)
+
+	bar := []rune(strings.Repeat("█", eighths/8))
+	if rest := eighths % 8; rest > 0 {
+		bar = append(bar, miniMeterBlocks[rest-1])
+	}
+
+	bar = append(bar, []rune(strings.Repeat(" ", miniMeterWidth-len(bar)))...)
+
+	if peakDB >= clipThreshold {
+		bar[miniMeterWidth-1] = '!'
+	}
+
+	return string(bar)
+}
@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/holoplot/rtp-monitor/internal/theme"
 )
@@ -36,6 +37,28 @@ type ModalContentProvider interface {
 	Close()
 }
 
+// ModalKeyHandler can optionally be implemented by a ModalContentProvider to
+// handle keys itself. It sees keys before the modal does and gets both the
+// key and the action it is bound to, if any.
+type ModalKeyHandler interface {
+	// HandleKey returns true if the key was consumed
+	HandleKey(key string, action keymap.Action) bool
+}
+
+// ModalErrorReporter can optionally be implemented by a ModalContentProvider
+// to report errors that occur while the modal is open, e.g. in a background tab
+type ModalErrorReporter interface {
+	// Err returns the first error that occurred, or nil
+	Err() error
+}
+
+// ModalCopyProvider can optionally be implemented by a ModalContentProvider
+// to copy something other than the displayed content to the clipboard
+type ModalCopyProvider interface {
+	// CopyContent returns the text to copy
+	CopyContent() string
+}
+
 // sanitizeASCII removes or replaces non-printable characters from a string
 func SanitizeASCII(s string) string {
 	var result strings.Builder
@@ -63,6 +86,9 @@ type ModalModel struct {
 	visible      bool
 	styles       ModalStyles
 	lastUpdate   time.Time
+
+	// interval overrides the update interval of the provider if not zero
+	interval time.Duration
 }
 
 // ModalStyles holds the styling for the modal
@@ -201,7 +227,7 @@ func (m *ModalModel) UpdateContent() {
 		return
 	}
 
-	updateInterval := m.provider.UpdateInterval()
+	updateInterval := m.updateInterval()
 	if updateInterval > 0 && time.Since(m.lastUpdate) >= updateInterval {
 		m.provider.Update()
 		if m.provider.AutoScroll() {
@@ -211,19 +237,54 @@ func (m *ModalModel) UpdateContent() {
 	}
 }
 
-// getModalDimensions returns consistent modal and content dimensions
-func (m *ModalModel) getModalDimensions() (modalWidth, modalHeight, contentWidth, contentHeight int) {
-	// Calculate modal dimensions (80% of screen, but at least 60x20)
-	modalWidth = max((m.width*80)/100, 60)
-	if modalWidth > m.width-4 {
-		modalWidth = m.width - 4
+// updateInterval returns how often the content is updated, zero if it is
+// not. Providers that are not updated at all can't be made to.
+func (m *ModalModel) updateInterval() time.Duration {
+	if m.provider == nil {
+		return 0
+	}
+
+	interval := m.provider.UpdateInterval()
+	if interval > 0 && m.interval > 0 {
+		interval = m.interval
+	}
+
+	return interval
+}
+
+// modalSize returns the outer size of a modal for the given terminal size.
+// Modals take 80% of the screen, but at least 60x20, and nearly all of it on
+// narrow terminals.
+func modalSize(width, height int) (modalWidth, modalHeight int) {
+	modalWidth = max((width*80)/100, 60)
+	if modalWidth > width-4 {
+		modalWidth = width - 4
 	}
 
-	modalHeight = max((m.height*80)/100, 20)
-	if modalHeight > m.height-4 {
-		modalHeight = m.height - 4
+	if width < narrowWidth {
+		modalWidth = width - 2
 	}
 
+	modalHeight = max((height*80)/100, 20)
+	if modalHeight > height-4 {
+		modalHeight = height - 4
+	}
+
+	return modalWidth, modalHeight
+}
+
+// modalAvailableWidth returns the width available to modal content, minus
+// borders, padding and scrollbar
+func modalAvailableWidth(width, height int) int {
+	modalWidth, _ := modalSize(width, height)
+
+	return modalWidth - 6
+}
+
+// getModalDimensions returns consistent modal and content dimensions
+func (m *ModalModel) getModalDimensions() (modalWidth, modalHeight, contentWidth, contentHeight int) {
+	modalWidth, modalHeight = modalSize(m.width, m.height)
+
 	// Content area dimensions (account for borders and padding)
 	contentWidth = modalWidth - 4
 	contentHeight = modalHeight - 4
@@ -292,7 +353,10 @@ func (m *ModalModel) Render() string {
 	}
 
 	// Create title line (centered)
-	title := m.provider.Title() + " | " + m.stream.Name()
+	title := m.provider.Title()
+	if m.stream != nil {
+		title += " | " + m.stream.Name()
+	}
 	titleLine := m.createCenteredTitle(title, contentWidth)
 
 	// Join content and apply content styling to ensure proper foreground color
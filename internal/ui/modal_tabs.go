@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// ModalTabs holds all open modals and tracks which one is shown.
+// Modals in background tabs keep running so their receivers keep collecting data.
+type ModalTabs struct {
+	modals []*ModalModel
+	active int
+	width  int
+	height int
+	styles ModalTabStyles
+
+	// intervals overrides the update intervals of modals by refreshName
+	intervals map[string]time.Duration
+}
+
+// ModalTabStyles holds the styling for the tab bar
+type ModalTabStyles struct {
+	Tab       lipgloss.Style
+	ActiveTab lipgloss.Style
+}
+
+// NewModalTabs creates an empty set of modal tabs
+func NewModalTabs() *ModalTabs {
+	return &ModalTabs{
+		styles: createModalTabStyles(),
+	}
+}
+
+// createModalTabStyles creates the tab bar styles using the current theme
+func createModalTabStyles() ModalTabStyles {
+	return ModalTabStyles{
+		Tab: lipgloss.NewStyle().
+			Foreground(theme.Colors.Secondary).
+			Padding(0, 1),
+		ActiveTab: lipgloss.NewStyle().
+			Foreground(theme.Colors.TableRowSelected).
+			Background(theme.Colors.Primary).
+			Bold(true).
+			Padding(0, 1),
+	}
+}
+
+// RefreshStyles updates the styles of the tab bar and all open modals
+func (t *ModalTabs) RefreshStyles() {
+	t.styles = createModalTabStyles()
+
+	for _, modal := range t.modals {
+		modal.styles = createModalStyles()
+	}
+}
+
+// Open shows the provider in a new tab and activates it. If a modal with the
+// same title for the same stream is already open, that tab is activated
+// instead and the new provider is discarded.
+func (t *ModalTabs) Open(s *stream.Stream, provider ModalContentProvider, width, height int) {
+	t.width = width
+	t.height = height
+
+	for i, modal := range t.modals {
+		if modal.provider.Title() == provider.Title() && sameStream(modal.stream, s) {
+			t.active = i
+			return
+		}
+	}
+
+	modal := NewModalModel()
+	modal.interval = t.intervals[refreshName(provider)]
+	modal.Show(s, provider, width, height)
+
+	t.modals = append(t.modals, modal)
+	t.active = len(t.modals) - 1
+}
+
+func sameStream(a, b *stream.Stream) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.ID == b.ID
+}
+
+// IsVisible returns whether any modal is open
+func (t *ModalTabs) IsVisible() bool {
+	return len(t.modals) > 0
+}
+
+// Active returns the modal in the active tab, or nil
+func (t *ModalTabs) Active() *ModalModel {
+	if t.active < 0 || t.active >= len(t.modals) {
+		return nil
+	}
+
+	return t.modals[t.active]
+}
+
+// Count returns the number of open tabs
+func (t *ModalTabs) Count() int {
+	return len(t.modals)
+}
+
+// CloseActive closes the modal in the active tab
+func (t *ModalTabs) CloseActive() {
+	t.close(t.active)
+}
+
+func (t *ModalTabs) close(i int) {
+	if i < 0 || i >= len(t.modals) {
+		return
+	}
+
+	t.modals[i].Hide()
+	t.modals = append(t.modals[:i], t.modals[i+1:]...)
+
+	if t.active >= len(t.modals) {
+		t.active = len(t.modals) - 1
+	} else if t.active > i {
+		t.active--
+	}
+
+	t.active = max(t.active, 0)
+}
+
+// CloseAll closes all open modals
+func (t *ModalTabs) CloseAll() {
+	for _, modal := range t.modals {
+		modal.Hide()
+	}
+
+	t.modals = nil
+	t.active = 0
+}
+
+// CloseMissingStreams closes all tabs whose stream is not in the given list.
+// Tabs without a stream are kept.
+func (t *ModalTabs) CloseMissingStreams(streams []*stream.Stream) {
+	ids := make(map[string]struct{}, len(streams))
+	for _, s := range streams {
+		ids[s.ID] = struct{}{}
+	}
+
+	for i := len(t.modals) - 1; i >= 0; i-- {
+		s := t.modals[i].stream
+		if s == nil {
+			continue
+		}
+
+		if _, ok := ids[s.ID]; !ok {
+			t.close(i)
+		}
+	}
+}
+
+// Next activates the next tab, wrapping around
+func (t *ModalTabs) Next() {
+	if len(t.modals) > 0 {
+		t.active = (t.active + 1) % len(t.modals)
+	}
+}
+
+// Prev activates the previous tab, wrapping around
+func (t *ModalTabs) Prev() {
+	if len(t.modals) > 0 {
+		t.active = (t.active + len(t.modals) - 1) % len(t.modals)
+	}
+}
+
+// Select activates the tab with the given index, if it exists
+func (t *ModalTabs) Select(i int) {
+	if i >= 0 && i < len(t.modals) {
+		t.active = i
+	}
+}
+
+// SetSize updates the dimensions of all open modals
+func (t *ModalTabs) SetSize(width, height int) {
+	t.width = width
+	t.height = height
+
+	for _, modal := range t.modals {
+		modal.width = width
+		modal.height = height
+	}
+}
+
+// SetUpdateIntervals overrides the update intervals of modals, keyed by the
+// names returned by refreshName. Modals without an entry use the interval of
+// their provider.
+func (t *ModalTabs) SetUpdateIntervals(intervals map[string]time.Duration) {
+	t.intervals = intervals
+
+	for _, modal := range t.modals {
+		modal.interval = intervals[refreshName(modal.provider)]
+	}
+}
+
+// UpdateInterval returns the shortest update interval of the open modals,
+// zero if none of them is updated
+func (t *ModalTabs) UpdateInterval() time.Duration {
+	var shortest time.Duration
+
+	for _, modal := range t.modals {
+		if interval := modal.updateInterval(); interval > 0 && (shortest == 0 || interval < shortest) {
+			shortest = interval
+		}
+	}
+
+	return shortest
+}
+
+// refreshName returns the name of the modals of a provider in the refresh
+// settings, empty for modals that are never updated
+func refreshName(provider ModalContentProvider) string {
+	switch provider.(type) {
+	case *DetailsModalContent:
+		return "details"
+	case *MeterModalContent:
+		return "meters"
+	case *CompareModalContent:
+		return "compare"
+	case *RecordModalContent:
+		return "record"
+	case *RTCPModalContent:
+		return "rtcp"
+	case *FpgaRxModalContent:
+		return "fpga-rx"
+	case *FpgaTxModalContent:
+		return "fpga-tx"
+	case *StatusModalContent:
+		return "status"
+	case *IGMPModalContent:
+		return "igmp"
+	case *ConformanceModalContent:
+		return "conformance"
+	}
+
+	return ""
+}
+
+// UpdateContent refreshes all open modals, including background tabs
+func (t *ModalTabs) UpdateContent() {
+	for _, modal := range t.modals {
+		modal.UpdateContent()
+	}
+}
+
+// View implements the overlay foreground. It renders a tab bar above the
+// active modal when more than one modal is open.
+func (t *ModalTabs) View() string {
+	active := t.Active()
+	if active == nil {
+		return ""
+	}
+
+	view := active.Render()
+
+	if len(t.modals) < 2 {
+		return view
+	}
+
+	var tabs []string
+
+	for i, modal := range t.modals {
+		label := fmt.Sprintf("%d %s", i+1, modal.provider.Title())
+		if modal.stream != nil {
+			label += " · " + ansi.Truncate(modal.stream.Name(), 16, "…")
+		}
+
+		style := t.styles.Tab
+		if i == t.active {
+			style = t.styles.ActiveTab
+		}
+
+		tabs = append(tabs, style.Render(label))
+	}
+
+	bar := ansi.Truncate(strings.Join(tabs, ""), lipgloss.Width(view), "…")
+
+	return lipgloss.JoinVertical(lipgloss.Left, bar, view)
+}
@@ -2,19 +2,38 @@ package ui
 
 import (
 	"fmt"
+	"net"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/holoplot/rtp-monitor/internal/capability"
 	"github.com/holoplot/rtp-monitor/internal/clipboard"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/igmp"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
 	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
+	"github.com/holoplot/rtp-monitor/internal/stats"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/holoplot/rtp-monitor/internal/theme"
 	"github.com/holoplot/rtp-monitor/internal/version"
 	overlay "github.com/rmhubbert/bubbletea-overlay"
 )
 
+const (
+	// narrowWidth is the terminal width below which a compact layout with
+	// fewer columns is used
+	narrowWidth = 80
+
+	// minWidth and minHeight are the smallest usable terminal size
+	minWidth  = 40
+	minHeight = 12
+)
+
 // BackgroundModel represents just the background view for overlay
 type BackgroundModel struct {
 	parent *Model
@@ -36,34 +55,115 @@ func (b *BackgroundModel) View() string {
 	return b.parent.renderMainView()
 }
 
+// Options holds the dependencies and settings of the UI model
+type Options struct {
+	Manager    *stream.Manager
+	PTPMonitor *ptp.Monitor
+	Config     *config.Config
+
+	// IGMPMonitor is nil if IGMP messages are not monitored, because of
+	// IGMPError
+	IGMPMonitor *igmp.Monitor
+	IGMPError   error
+
+	KeyMap    *keymap.KeyMap
+	Collector *stats.Collector
+
+	// WavFileFolder is where recordings are written to. If empty, the
+	// folder from the settings is used.
+	WavFileFolder string
+
+	// ExportFolder is where exported files such as SDPs are written to. If
+	// empty, the folder from the settings is used.
+	ExportFolder string
+
+	// FPGA holds the parameters of streams received with a RAVENNA FPGA,
+	// from the config with command line overrides applied
+	FPGA config.FPGA
+
+	// Capabilities tells which features are unavailable for lack of
+	// privileges or devices, nil if that was not checked
+	Capabilities *capability.Report
+}
+
 // Model represents the main UI model
 type Model struct {
 	table         *TableModel
-	modal         *ModalModel
+	modals        *ModalTabs
 	overlay       *overlay.Model
 	background    *BackgroundModel
 	streamManager *stream.Manager
 	ptpMonitor    *ptp.Monitor
+	igmpMonitor   *igmp.Monitor
+	igmpErr       error
+	keyMap        *keymap.KeyMap
+	config        *config.Config
+	collector     *stats.Collector
+	detailPane    *DetailPane
+	toasts        *Toasts
+	alerts        *alertState
+
+	// expectedErrs holds why the expected streams failed to be received
+	// in the background, so each error is reported once
+	expectedErrs map[string]string
+
+	splitView     bool
+	jumping       bool
+	ticking       bool
 	width         int
 	height        int
 	lastUpdate    time.Time
 	quitting      bool
 	wavFileFolder string
+	exportFolder  string
+	fpga          config.FPGA
+	capabilities  *capability.Report
 }
 
 // NewModel creates a new UI model
-func NewModel(manager *stream.Manager, ptpMonitor *ptp.Monitor, wavFileFolder string) *Model {
+func NewModel(opts Options) *Model {
 	m := &Model{
-		table:         NewTableModel(),
-		modal:         NewModalModel(),
-		streamManager: manager,
-		ptpMonitor:    ptpMonitor,
+		table:         NewTableModel(opts.Collector),
+		modals:        NewModalTabs(),
+		streamManager: opts.Manager,
+		ptpMonitor:    opts.PTPMonitor,
+		igmpMonitor:   opts.IGMPMonitor,
+		igmpErr:       opts.IGMPError,
+		keyMap:        opts.KeyMap,
+		config:        opts.Config,
+		collector:     opts.Collector,
+		detailPane:    NewDetailPane(opts.KeyMap, opts.PTPMonitor, opts.Config),
+		toasts:        NewToasts(),
+		alerts:        newAlertState(),
+		expectedErrs:  make(map[string]string),
 		width:         80,
 		height:        24,
 		lastUpdate:    time.Now(),
-		wavFileFolder: wavFileFolder,
+		wavFileFolder: opts.WavFileFolder,
+		exportFolder:  opts.ExportFolder,
+		fpga:          opts.FPGA,
+		capabilities:  opts.Capabilities,
 	}
 	m.background = &BackgroundModel{parent: m}
+	m.table.SetFavorites(m.config.Favorites)
+	m.table.SetExpected(m.config.Expected)
+	m.table.SetAnnotations(m.config.Annotations)
+	m.table.SetStaleTimeout(m.config.Settings.StaleTimeout())
+	m.table.SetMiniMeters(m.config.Settings.MiniMeters, m.config.Settings.ClipThreshold)
+	m.collector.EnableLevels(m.config.Settings.MiniMeters)
+	m.modals.SetUpdateIntervals(m.config.Settings.ModalRefreshIntervals())
+
+	if m.capabilities != nil {
+		if unavailable := m.capabilities.Unavailable(); len(unavailable) > 0 {
+			names := make([]string, len(unavailable))
+			for i, f := range unavailable {
+				names[i] = f.Name
+			}
+
+			m.toasts.Add(toastWarning, "Unavailable: %s, see the status for why", strings.Join(names, ", "))
+		}
+	}
+
 	return m
 }
 
@@ -77,7 +177,8 @@ func (m *Model) Init() tea.Cmd {
 				}
 			}
 		}(),
-		m.modalTickCmd(),
+		m.tickCmd(),
+		clockCmd(),
 	)
 }
 
@@ -87,47 +188,51 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.table.SetSize(msg.Width, msg.Height-2) // Leave space for header and footer
-
-		// Pass window size to overlay if it exists
-		if m.overlay != nil {
-			m.overlay.Update(msg)
-		}
+		m.layout()
+		m.modals.SetSize(msg.Width, msg.Height)
 		return m, nil
 
 	case tea.KeyMsg:
 		return m.handleKeypress(msg)
 
 	case modalTickMsg:
-		if !m.quitting && m.modal.IsVisible() {
-			m.modal.UpdateContent()
-			return m, tea.Batch(m.modalTickCmd())
+		m.ticking = false
+
+		if m.quitting {
+			return m, nil
 		}
-		return m, nil
+
+		m.modals.UpdateContent()
+
+		if m.splitView {
+			m.detailPane.SetStream(m.table.GetSelected())
+		}
+
+		return m, m.tickCmd()
+
+	case clockTickMsg:
+		// The re-render refreshes relative times
+		m.checkAlerts()
+		m.toasts.Prune(time.Time(msg))
+		return m, clockCmd()
 
 	case UpdateStreamsMsg:
+		m.notifyStreamChanges(msg.Streams)
 		m.table.SetStreams(msg.Streams)
 		m.lastUpdate = time.Now()
 
-		modalStreamMissing := func() bool {
-			if !m.modal.IsVisible() {
-				return false
-			}
+		m.modals.CloseMissingStreams(msg.Streams)
+		m.collector.StopMissing(msg.Streams)
 
-			for _, stream := range msg.Streams {
-				if stream.ID == m.modal.stream.ID {
-					return false
-				}
+		// Expected streams are received in the background, so they raise
+		// an alert when they stop
+		for _, s := range msg.Streams {
+			if m.streamManager.IsExpected(s.IDHash()) {
+				m.startExpected(s)
 			}
-
-			return true
 		}
 
-		if modalStreamMissing() {
-			m.modal.Hide()
-		}
-
-		return m, nil
+		return m, m.tickCmd()
 	}
 
 	return m, nil
@@ -135,190 +240,580 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeypress handles keyboard input
 func (m *Model) handleKeypress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.keyMap.Action(msg.String())
+
 	// Handle modal input first if any modal is visible
-	if m.modal.IsVisible() {
-		switch msg.String() {
-		case "x", "q":
-			m.modal.Hide()
+	if modal := m.modals.Active(); modal != nil {
+		// Let the provider handle keys it knows about, e.g. for text input
+		if handler, ok := modal.provider.(ModalKeyHandler); ok && handler.HandleKey(msg.String(), action) {
+			return m, nil
+		}
+
+		switch action {
+		case keymap.ActionClose, keymap.ActionQuit:
+			m.modals.CloseActive()
+			return m, nil
+		case keymap.ActionNextTab:
+			m.modals.Next()
 			return m, nil
-		case "up", "k":
-			m.modal.ScrollUp()
+		case keymap.ActionPrevTab:
+			m.modals.Prev()
 			return m, nil
-		case "down", "j":
-			m.modal.ScrollDown()
+		case keymap.ActionUp:
+			modal.ScrollUp()
 			return m, nil
-		case "pgup", "page_up":
-			m.modal.ScrollPageUp()
+		case keymap.ActionDown:
+			modal.ScrollDown()
 			return m, nil
-		case "pgdown", "page_down":
-			m.modal.ScrollPageDown()
+		case keymap.ActionPageUp:
+			modal.ScrollPageUp()
 			return m, nil
-		case "home":
-			m.modal.ScrollToTop()
+		case keymap.ActionPageDown:
+			modal.ScrollPageDown()
 			return m, nil
-		case "end":
-			m.modal.ScrollToBottom()
+		case keymap.ActionHome:
+			modal.ScrollToTop()
 			return m, nil
-		case "c", "d", "f", "m", "r", "R", "s":
+		case keymap.ActionEnd:
+			modal.ScrollToBottom()
+			return m, nil
+		case keymap.ActionSave:
+			if fileName, err := saveModalContent(modal, m.exportDir()); err != nil {
+				m.toasts.Add(toastError, "Saving failed: %v", err)
+			} else {
+				m.toasts.Add(toastInfo, "Saved %s", fileName)
+			}
+			return m, nil
+		case keymap.ActionCopy, keymap.ActionDetails, keymap.ActionFpgaRx, keymap.ActionFpgaTx, keymap.ActionMeters,
+			keymap.ActionRTCP, keymap.ActionRecord, keymap.ActionSDP, keymap.ActionHelp, keymap.ActionCompare, keymap.ActionSettings, keymap.ActionIGMP, keymap.ActionStatus,
+			keymap.ActionConformance, keymap.ActionScreenshot, keymap.ActionAnnotate:
 			// Allow modal switching - fall through to main keypress handling
 		default:
+			// Unbound digits select a tab directly
+			if key := msg.String(); action == "" && len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
+				m.modals.Select(int(key[0] - '1'))
+			}
+
 			// For any other keys when modal is open, consume the input
 			return m, nil
 		}
 	}
 
+	// While jumping, letters select streams and other keys end it
+	if m.jumping {
+		if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
+			if !m.table.JumpTo(msg.Runes[0]) {
+				m.toasts.Add(toastWarning, "No stream starts with %q", msg.Runes[0])
+			}
+
+			return m, nil
+		}
+
+		m.jumping = false
+
+		if msg.Type == tea.KeyEsc {
+			return m, nil
+		}
+	}
+
 	// Handle main UI input
-	switch msg.String() {
-	case "q", "ctrl+c":
+	switch action {
+	case keymap.ActionQuit:
 		m.quitting = true
+		m.modals.CloseAll()
+		m.detailPane.Close()
+		m.collector.StopAll()
 		return m, tea.Quit
 
-	case "up", "k":
+	case keymap.ActionSplitView:
+		m.splitView = !m.splitView
+		if !m.splitView {
+			m.detailPane.Close()
+		}
+		m.layout()
+		return m, m.tickCmd()
+
+	case keymap.ActionJump:
+		m.jumping = true
+		return m, nil
+
+	case keymap.ActionUp:
 		m.table.MoveUp()
 		return m, nil
 
-	case "down", "j":
+	case keymap.ActionDown:
 		m.table.MoveDown()
 		return m, nil
 
-	case "c":
-		// Show controls modal for selected stream
-		selected := m.table.GetSelected()
+	case keymap.ActionCopy:
+		// Copy modal content, or the marked or selected streams
+		var err error
+
+		if modal := m.modals.Active(); modal != nil {
+			if copier, ok := modal.provider.(ModalCopyProvider); ok {
+				err = clipboard.WriteString(copier.CopyContent())
+			} else {
+				s := ansi.Strip(strings.Join(modal.provider.Content(), "\n"))
+				err = clipboard.WriteString(s)
+			}
+		} else if targets := m.targetStreams(); len(targets) > 0 {
+			// Let the user choose the format to copy the streams in
+			var s *stream.Stream
+			if len(targets) == 1 {
+				s = targets[0]
+			}
+
+			return m, m.showModal(s, NewCopyModalContent(targets, m.copyStreams))
+		}
 
-		if m.modal.IsVisible() {
-			s := strings.Join(m.modal.provider.Content(), "\n")
-			_ = clipboard.WriteString(s)
-		} else if selected != nil {
-			_ = clipboard.Write(selected.SDP)
+		if err != nil {
+			m.toasts.Add(toastError, "Copying failed: %v", err)
 		}
 
 		return m, nil
 
-	case "d":
-		// Show details modal for selected stream
-		selected := m.table.GetSelected()
-		if selected != nil {
-			if m.modal.IsVisible() {
-				m.modal.Hide()
+	case keymap.ActionMark:
+		m.table.ToggleMark()
+		m.table.MoveDown()
+		return m, nil
+
+	case keymap.ActionMarkAll:
+		m.table.ToggleMarkAll()
+		return m, nil
+
+	case keymap.ActionExportSDP:
+		for _, s := range m.targetStreams() {
+			if err := exportSDP(s, m.exportDir()); err != nil {
+				m.toasts.Add(toastError, "Exporting %s failed: %v", s.Name(), err)
 			}
-			detailsProvider := NewDetailsModalContent(selected, m.ptpMonitor)
-			m.modal.Show(selected, detailsProvider, m.width, m.height)
-			return m, m.modalTickCmd() // Start updates immediately
 		}
 		return m, nil
 
-	case "f":
-		if FpgaRxModalContentAvailable() {
-			// Show FPGA RX modal for selected stream
-			selected := m.table.GetSelected()
-			if selected != nil {
-				if m.modal.IsVisible() {
-					m.modal.Hide()
-				}
-				fpgaRxProvider := NewFpgaRxModalContent(selected)
-				m.modal.Show(selected, fpgaRxProvider, m.width, m.height)
-				return m, m.modalTickCmd() // Start updates immediately
+	case keymap.ActionExportTable:
+		fileNames, err := exportInventory(m.table.streams, m.exportDir())
+		if err != nil {
+			m.toasts.Add(toastError, "Exporting the stream list failed: %v", err)
+		} else {
+			m.toasts.Add(toastInfo, "Exported %d streams to %s", len(m.table.streams), strings.Join(fileNames, ", "))
+		}
+		return m, nil
+
+	case keymap.ActionScreenshot:
+		// Notifications are left out, they would only cover the screen
+		if fileNames, err := saveScreenshot(m.renderView(), m.exportDir()); err != nil {
+			m.toasts.Add(toastError, "Saving the screenshot failed: %v", err)
+		} else {
+			m.toasts.Add(toastInfo, "Saved %s", strings.Join(fileNames, ", "))
+		}
+		return m, nil
+
+	case keymap.ActionBackgroundStats:
+		targets := m.targetStreams()
+
+		// Toggle as a group: stop if all targets are running, start otherwise
+		allRunning := len(targets) > 0
+		for _, s := range targets {
+			allRunning = allRunning && m.collector.IsRunning(s.ID)
+		}
+
+		for _, s := range targets {
+			if allRunning {
+				m.collector.Stop(s.ID)
+			} else {
+				m.startCollector(s)
 			}
 		}
+
+		return m, m.tickCmd()
+
+	case keymap.ActionInterface:
+		if selected := m.table.GetSelected(); selected != nil {
+			m.cycleStreamInterface(selected)
+		}
+
+		return m, nil
+
+	case keymap.ActionCompare:
+		marked := m.table.Marked()
+		if len(marked) != 2 {
+			m.toasts.Add(toastWarning, "Mark exactly two streams to compare them")
+			return m, nil
+		}
+
+		return m, m.showModal(marked[0], NewCompareModalContent(marked[0], marked[1]))
+
+	case keymap.ActionHelp:
+		return m, m.showModal(nil, NewHelpModalContent(m.keyMap, FpgaRxModalContentAvailable(m.fpga.Device)))
+
+	case keymap.ActionSettings:
+		return m, m.showModal(nil, NewSettingsModalContent(m.config, m.applySettings))
+
+	case keymap.ActionIGMP:
+		return m, m.showModal(nil, NewIGMPModalContent(m.streamManager, m.igmpMonitor, m.igmpErr))
+
+	case keymap.ActionStatus:
+		return m, m.showModal(nil, NewStatusModalContent(m.config, m.streamManager, m.collector, m.capabilities))
+
+	case keymap.ActionConformance:
+		if selected := m.table.GetSelected(); selected != nil {
+			return m, m.showModal(selected, NewConformanceModalContent(selected))
+		}
+		return m, nil
+
+	case keymap.ActionDetails:
+		// Show details modal for selected stream
+		if selected := m.table.GetSelected(); selected != nil {
+			return m, m.showModal(selected, NewDetailsModalContent(m.keyMap, selected, m.ptpMonitor, m.config))
+		}
+		return m, nil
+
+	case keymap.ActionFpgaRx:
+		// Show FPGA RX modal for selected stream
+		if selected := m.table.GetSelected(); selected != nil && FpgaRxModalContentAvailable(m.fpga.Device) {
+			return m, m.showModal(selected, NewFpgaRxModalContent(m.keyMap, selected, &m.fpga))
+		}
+		return m, nil
+
+	case keymap.ActionFpgaTx:
+		// Loop the selected stream back through the FPGA
+		if selected := m.table.GetSelected(); selected != nil && FpgaTxModalContentAvailable(m.fpga.Device) {
+			return m, m.showModal(selected, NewFpgaTxModalContent(m.keyMap, selected, &m.fpga, m.streamManager))
+		}
 		return m, nil
 
-	case "m":
+	case keymap.ActionMeters:
 		// Show meters modal for selected stream
-		selected := m.table.GetSelected()
-		if selected != nil {
-			if m.modal.IsVisible() {
-				m.modal.Hide()
+		if selected := m.table.GetSelected(); selected != nil {
+			if err := selected.Description.SamplesError(); err != nil {
+				m.toasts.Add(toastWarning, "No meters for %s: %v", selected.Name(), err)
+				return m, nil
 			}
-			meterProvider := NewMeterModalContent(selected)
-			m.modal.Show(selected, meterProvider, m.width, m.height)
-			return m, m.modalTickCmd() // Start updates immediately
+
+			return m, m.showModal(selected, NewMeterModalContent(m.keyMap, selected, m.config.Settings.ClipThreshold, m.config.Settings.ClipHold()))
 		}
 		return m, nil
 
-	case "s":
+	case keymap.ActionSDP:
 		// Show SDP modal for selected stream
-		selected := m.table.GetSelected()
-		if selected != nil {
-			if m.modal.IsVisible() {
-				m.modal.Hide()
-			}
-			sdpProvider := NewSDPModalContent(selected)
-			m.modal.Show(selected, sdpProvider, m.width, m.height)
-			return m, m.modalTickCmd() // Start updates immediately
+		if selected := m.table.GetSelected(); selected != nil {
+			return m, m.showModal(selected, NewSDPModalContent(selected))
 		}
 		return m, nil
 
-	case "r":
-		// Show SDP modal for selected stream
-		selected := m.table.GetSelected()
-		if selected != nil {
-			if m.modal.IsVisible() {
-				m.modal.Hide()
-			}
-			rtcpProvider := NewRTCPModalContent(selected)
-			m.modal.Show(selected, rtcpProvider, m.width, m.height)
-			return m, m.modalTickCmd() // Start updates immediately
+	case keymap.ActionRTCP:
+		// Show RTCP modal for selected stream
+		if selected := m.table.GetSelected(); selected != nil {
+			return m, m.showModal(selected, NewRTCPModalContent(m.keyMap, selected, m.config.Settings.LogRetention, m.ptpMonitor))
 		}
 		return m, nil
 
-	case "R":
-		// Show recording modal for selected stream
-		selected := m.table.GetSelected()
-		if selected != nil {
-			if m.modal.IsVisible() {
-				m.modal.Hide()
+	case keymap.ActionRecord:
+		// Show a recording modal for each marked stream, or the selected
+		// one, with their files aligned to each other
+		var cmds []tea.Cmd
+		alignment := recorder.NewAlignment()
+		for _, s := range m.targetStreams() {
+			if err := s.Description.SamplesError(); err != nil {
+				m.toasts.Add(toastWarning, "Not recording %s: %v", s.Name(), err)
+				continue
 			}
-			recordProvider := NewRecordModalContent(selected, m.wavFileFolder)
-			m.modal.Show(selected, recordProvider, m.width, m.height)
-			return m, m.modalTickCmd() // Start updates immediately
+
+			cmds = append(cmds, m.showModal(s, NewRecordModalContent(m.keyMap, s, m.wavDir(), alignment, m.config.Settings.ClipThreshold)))
 		}
+		return m, tea.Batch(cmds...)
+
+	case keymap.ActionHome:
+		m.table.MoveToTop()
+		return m, nil
+
+	case keymap.ActionEnd:
+		m.table.MoveToBottom()
+		return m, nil
+
+	case keymap.ActionPageUp:
+		m.table.PageUp()
+		return m, nil
+
+	case keymap.ActionPageDown:
+		m.table.PageDown()
+		return m, nil
+
+	case keymap.ActionGroup:
+		m.toasts.Add(toastInfo, "Grouping: %s", m.table.CycleGrouping())
 		return m, nil
 
-	case "home":
-		m.table.selectedIndex = 0
-		m.table.adjustView()
+	case keymap.ActionCollapse:
+		m.table.ToggleCollapse()
 		return m, nil
 
-	case "end":
-		if len(m.table.streams) > 0 {
-			m.table.selectedIndex = len(m.table.streams) - 1
-			m.table.adjustView()
+	case keymap.ActionFavorite:
+		if selected := m.table.GetSelected(); selected != nil {
+			m.config.ToggleFavorite(selected.IDHash())
+			if err := m.config.Save(); err != nil {
+				m.toasts.Add(toastError, "Saving favorites failed: %v", err)
+			}
+			m.table.SetFavorites(m.config.Favorites)
 		}
 		return m, nil
 
-	case "pgup", "page_up":
-		visibleRows := m.table.height - 3
-		for i := 0; i < visibleRows && m.table.selectedIndex > 0; i++ {
-			m.table.selectedIndex--
+	case keymap.ActionExpected:
+		if selected := m.table.GetSelected(); selected != nil {
+			expected := m.config.ToggleExpected(selected.IDHash())
+			if err := m.config.Save(); err != nil {
+				m.toasts.Add(toastError, "Saving the expected streams failed: %v", err)
+			}
+
+			m.streamManager.SetExpected(selected.IDHash(), expected)
+			m.table.SetExpected(m.config.Expected)
+
+			if expected {
+				m.toasts.Add(toastInfo, "Expecting %s, alerting when it disappears or stops", selected.Name())
+				m.startCollector(selected)
+
+				return m, m.tickCmd()
+			}
+
+			m.toasts.Add(toastInfo, "No longer expecting %s", selected.Name())
 		}
-		m.table.adjustView()
 		return m, nil
 
-	case "pgdown", "page_down":
-		visibleRows := m.table.height - 3
-		maxIndex := len(m.table.streams) - 1
-		for i := 0; i < visibleRows && m.table.selectedIndex < maxIndex; i++ {
-			m.table.selectedIndex++
+	case keymap.ActionAnnotate:
+		if selected := m.table.GetSelected(); selected != nil {
+			return m, m.showModal(selected, NewAnnotateModalContent(selected, m.config, m.applyAnnotations))
 		}
-		m.table.adjustView()
+		return m, nil
+
+	case keymap.ActionFavoritesOnly:
+		m.table.ToggleFavoritesOnly()
 		return m, nil
 	}
 
 	return m, nil
 }
 
+// cycleStreamInterface makes the receivers of a stream join on the next
+// interface of the manager, or on all of them after the last one, and
+// restarts its background statistics so they use the new interface
+func (m *Model) cycleStreamInterface(s *stream.Stream) {
+	ifis := m.streamManager.Interfaces()
+
+	var next *net.Interface
+
+	if current := m.streamManager.StreamInterface(s.IDHash()); current == nil {
+		next = ifis[0]
+	} else if i := slices.IndexFunc(ifis, func(ifi *net.Interface) bool { return ifi.Index == current.Index }); i >= 0 && i+1 < len(ifis) {
+		next = ifis[i+1]
+	}
+
+	m.streamManager.SetStreamInterface(s.IDHash(), next)
+
+	name := ""
+	if next != nil {
+		name = next.Name
+	}
+
+	m.config.SetStreamInterface(s.IDHash(), name)
+
+	if err := m.config.Save(); err != nil {
+		m.toasts.Add(toastError, "Saving the interface failed: %v", err)
+	}
+
+	if m.collector.IsRunning(s.ID) {
+		m.collector.Stop(s.ID)
+		m.startCollector(s)
+	}
+
+	if next == nil {
+		m.toasts.Add(toastInfo, "Receiving %s on all interfaces, reopen views to apply", s.Name())
+	} else {
+		m.toasts.Add(toastInfo, "Receiving %s on %s only, reopen views to apply", s.Name(), next.Name)
+	}
+}
+
+// startCollector starts receiving a stream in the background
+func (m *Model) startCollector(s *stream.Stream) {
+	if err := m.collector.Start(s); err != nil {
+		m.toasts.Add(toastError, "Receiving %s in the background failed: %v", s.Name(), err)
+	}
+}
+
+// startExpected starts receiving an expected stream in the background on
+// each update of the streams, reporting an error only when it changes
+func (m *Model) startExpected(s *stream.Stream) {
+	err := m.collector.Start(s)
+	if err == nil {
+		delete(m.expectedErrs, s.ID)
+		return
+	}
+
+	if m.expectedErrs[s.ID] != err.Error() {
+		m.expectedErrs[s.ID] = err.Error()
+		m.toasts.Add(toastError, "Receiving %s in the background failed: %v", s.Name(), err)
+	}
+}
+
+// targetStreams returns the streams batch actions apply to: the marked
+// streams if there are any, the selected stream otherwise
+func (m *Model) targetStreams() []*stream.Stream {
+	if marked := m.table.Marked(); len(marked) > 0 {
+		return marked
+	}
+
+	if selected := m.table.GetSelected(); selected != nil {
+		return []*stream.Stream{selected}
+	}
+
+	return nil
+}
+
+// copyStreams copies the streams in the format chosen in the copy modal and
+// closes it
+func (m *Model) copyStreams(text, what string, err error) {
+	if err == nil {
+		err = clipboard.WriteString(text)
+	}
+
+	if err != nil {
+		m.toasts.Add(toastError, "Copying %s failed: %v", what, err)
+		return
+	}
+
+	m.modals.CloseActive()
+	m.toasts.Add(toastInfo, "Copied %s", what)
+}
+
+// showModal opens the provider in a new modal tab, or activates the tab that
+// already shows the same content for the same stream
+func (m *Model) showModal(s *stream.Stream, provider ModalContentProvider) tea.Cmd {
+	m.modals.Open(s, provider, m.width, m.height)
+
+	return m.tickCmd() // Start updates immediately
+}
+
+// wavDir returns the folder recordings are written to
+func (m *Model) wavDir() string {
+	if m.wavFileFolder != "" {
+		return m.wavFileFolder
+	}
+
+	return m.config.Settings.WavFolder
+}
+
+// exportDir returns the folder exported files are written to
+func (m *Model) exportDir() string {
+	if m.exportFolder != "" {
+		return m.exportFolder
+	}
+
+	return m.config.Settings.ExportFolder
+}
+
+// applySettings applies changed settings and persists them. Settings that
+// are read when needed, like the refresh interval, need no action here.
+func (m *Model) applySettings() {
+	s := m.config.Settings
+
+	if err := theme.Set(s.Theme); err != nil {
+		m.toasts.Add(toastError, "Setting the theme failed: %v", err)
+	} else {
+		if err := theme.SetGradient(s.MeterGradient); err != nil {
+			m.toasts.Add(toastError, "Setting the meter gradient failed: %v", err)
+		}
+
+		m.table.RefreshStyles()
+		m.modals.RefreshStyles()
+		m.toasts.RefreshStyles()
+	}
+
+	m.table.SetStaleTimeout(s.StaleTimeout())
+	m.table.SetMiniMeters(s.MiniMeters, s.ClipThreshold)
+	m.collector.EnableLevels(s.MiniMeters)
+	m.modals.SetUpdateIntervals(s.ModalRefreshIntervals())
+
+	if err := m.config.Save(); err != nil {
+		m.toasts.Add(toastError, "Saving settings failed: %v", err)
+	}
+}
+
+// applyAnnotations shows changed aliases and notes and persists them
+func (m *Model) applyAnnotations() {
+	m.table.SetAnnotations(m.config.Annotations)
+
+	if err := m.config.Save(); err != nil {
+		m.toasts.Add(toastError, "Saving the annotation failed: %v", err)
+	}
+}
+
+// detailPaneHeight returns the number of lines used by the detail pane
+func (m *Model) detailPaneHeight() int {
+	if !m.splitView {
+		return 0
+	}
+
+	return max((m.height-2)*40/100, 5)
+}
+
+// layout distributes the available height between the table and the detail pane
+func (m *Model) layout() {
+	// Leave space for header and footer
+	m.table.SetSize(m.width, m.height-1-m.headerHeight()-m.detailPaneHeight())
+}
+
+// narrow returns whether the terminal is too narrow for the full layout
+func (m *Model) narrow() bool {
+	return m.width < narrowWidth
+}
+
+// headerHeight returns the number of lines of the header, which is stacked
+// on narrow terminals
+func (m *Model) headerHeight() int {
+	if m.narrow() {
+		return 2
+	}
+
+	return 1
+}
+
 // View renders the UI
 func (m *Model) View() string {
 	if m.quitting {
 		return "Goodbye!\n"
 	}
 
+	if m.width < minWidth || m.height < minHeight {
+		return m.renderTooSmall()
+	}
+
+	view := m.renderView()
+
+	// Notifications go on top of everything, just above the footer
+	if toasts := m.toasts.Render(); toasts != "" {
+		view = overlay.Composite(toasts, view, overlay.Right, overlay.Bottom, -1, -2)
+	}
+
+	return view
+}
+
+// renderTooSmall renders a message asking for a larger terminal
+func (m *Model) renderTooSmall() string {
+	message := fmt.Sprintf("Terminal too small\n%dx%d, need at least %dx%d",
+		m.width, m.height, minWidth, minHeight)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusWarning).
+			Align(lipgloss.Center).
+			Render(message))
+}
+
+// renderView renders the main view with the modal overlay, if any
+func (m *Model) renderView() string {
 	// If modal is visible, create overlay
-	if m.modal.IsVisible() {
+	if m.modals.IsVisible() {
 		if m.overlay == nil {
 			// Create overlay with modal centered over main view
 			m.overlay = overlay.New(
-				m.modal,                        // foreground (modal)
+				m.modals,                       // foreground (modal tabs)
 				m.background,                   // background (main view)
 				overlay.Center, overlay.Center, // center position
 				0, 0, // no offset
@@ -344,10 +839,13 @@ func (m *Model) renderMainView() string {
 	// Footer
 	footer := m.renderFooter()
 
+	// Detail pane, only in split view
+	paneHeight := m.detailPaneHeight()
+
 	// Calculate available height for table and add padding to push footer to bottom
 	headerHeight := lipgloss.Height(header)
 	footerHeight := lipgloss.Height(footer)
-	tableHeight := m.height - headerHeight - footerHeight - 1
+	tableHeight := m.height - headerHeight - footerHeight - paneHeight - 1
 
 	// Add padding to push footer to bottom
 	padding := ""
@@ -358,13 +856,16 @@ func (m *Model) renderMainView() string {
 		}
 	}
 
+	parts := []string{header, table, padding}
+
+	if paneHeight > 0 {
+		parts = append(parts, m.detailPane.Render(m.width, paneHeight))
+	}
+
+	parts = append(parts, footer)
+
 	// Combine all parts
-	return lipgloss.JoinVertical(lipgloss.Left,
-		header,
-		table,
-		padding,
-		footer,
-	)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
 
 func (m *Model) renderHeader() string {
@@ -374,14 +875,26 @@ func (m *Model) renderHeader() string {
 		Render(fmt.Sprintf("RTP Stream Monitor %s", version.GetShortVersion()))
 
 	streamCount := fmt.Sprintf("Streams: %d", len(m.table.streams))
+	if m.table.FavoritesOnly() {
+		streamCount = fmt.Sprintf("Favorites: %d/%d", m.table.VisibleCount(), len(m.table.streams))
+	}
 	lastUpdate := fmt.Sprintf("Last Update: %s", m.lastUpdate.Format("15:04:05"))
 
+	if n := len(m.table.Marked()); n > 0 {
+		streamCount = fmt.Sprintf("Marked: %d │ %s", n, streamCount)
+	}
+
 	info := lipgloss.JoinHorizontal(lipgloss.Bottom,
 		lipgloss.NewStyle().Foreground(theme.Colors.Secondary).Render(streamCount),
 		lipgloss.NewStyle().Margin(0, 2).Render("│"),
 		lipgloss.NewStyle().Foreground(theme.Colors.Secondary).Render(lastUpdate),
 	)
 
+	// Narrow terminals get the info below the title
+	if m.narrow() {
+		return lipgloss.JoinVertical(lipgloss.Left, title, ansi.Truncate(info, m.width, "…"))
+	}
+
 	// Create a full-width header with title on left, info on right
 	titleWidth := lipgloss.Width(title)
 	infoWidth := lipgloss.Width(info)
@@ -404,48 +917,132 @@ func (m *Model) renderFooter() string {
 		selectedInfo = "No stream selected"
 	}
 
+	if first, last, total := m.table.Page(); total > 0 {
+		selectedInfo = fmt.Sprintf("%d–%d of %d │ %s", first, last, total, selectedInfo)
+	}
+
+	k := m.keyMap.Label
+
+	if m.jumping {
+		help := fmt.Sprintf("Jump: type the first letter of a stream name │ %s: Done", keymap.KeyLabel("esc"))
+
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.footerInfo(selectedInfo),
+			lipgloss.NewStyle().
+				Foreground(theme.Colors.Secondary).
+				Render(ansi.Truncate(help, m.width, "…")))
+	}
+
+	// Narrow terminals only get the essential keys, the rest is in the help
+	if m.narrow() {
+		help := []string{
+			fmt.Sprintf("%s/%s: Navigate", k(keymap.ActionUp), k(keymap.ActionDown)),
+			fmt.Sprintf("%s: Help", k(keymap.ActionHelp)),
+			fmt.Sprintf("%s: Quit", k(keymap.ActionQuit)),
+		}
+
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.footerInfo(selectedInfo),
+			lipgloss.NewStyle().
+				Foreground(theme.Colors.Secondary).
+				Render(ansi.Truncate(strings.Join(help, " │ "), m.width, "…")))
+	}
+
 	help := []string{
-		"↑/↓: Navigate",
-		"c: Copy to clipboard",
-		"d: Details",
+		fmt.Sprintf("%s/%s: Navigate", k(keymap.ActionUp), k(keymap.ActionDown)),
+		fmt.Sprintf("%s: Jump", k(keymap.ActionJump)),
+		fmt.Sprintf("%s: Copy to clipboard", k(keymap.ActionCopy)),
+		fmt.Sprintf("%s: Details", k(keymap.ActionDetails)),
 	}
 
-	if FpgaRxModalContentAvailable() {
-		help = append(help, "f: FPGA RX")
+	if FpgaRxModalContentAvailable(m.fpga.Device) {
+		help = append(help, fmt.Sprintf("%s: FPGA RX", k(keymap.ActionFpgaRx)))
+		help = append(help, fmt.Sprintf("%s: FPGA TX", k(keymap.ActionFpgaTx)))
 	}
 
 	help = append(help, []string{
-		"r: RTCP",
-		"R: Record wav",
-		"s: SDP",
-		"m: Metering",
-		"q: Quit",
+		fmt.Sprintf("%s: RTCP", k(keymap.ActionRTCP)),
+		fmt.Sprintf("%s: Record wav", k(keymap.ActionRecord)),
+		fmt.Sprintf("%s: SDP", k(keymap.ActionSDP)),
+		fmt.Sprintf("%s: Metering", k(keymap.ActionMeters)),
+		fmt.Sprintf("%s: Split view", k(keymap.ActionSplitView)),
+		fmt.Sprintf("%s: Group", k(keymap.ActionGroup)),
+		fmt.Sprintf("%s: Favorite", k(keymap.ActionFavorite)),
+		fmt.Sprintf("%s: Mark", k(keymap.ActionMark)),
+		fmt.Sprintf("%s: Export SDP", k(keymap.ActionExportSDP)),
+		fmt.Sprintf("%s: Export list", k(keymap.ActionExportTable)),
+		fmt.Sprintf("%s: Screenshot", k(keymap.ActionScreenshot)),
+		fmt.Sprintf("%s: Compare", k(keymap.ActionCompare)),
+		fmt.Sprintf("%s: Background stats", k(keymap.ActionBackgroundStats)),
+		fmt.Sprintf("%s: Settings", k(keymap.ActionSettings)),
+		fmt.Sprintf("%s: Help", k(keymap.ActionHelp)),
+		fmt.Sprintf("%s: Quit", k(keymap.ActionQuit)),
 	}...)
 
-	selectedStyle := lipgloss.NewStyle().
-		Foreground(theme.Colors.Highlight).
-		Render(selectedInfo)
-
 	helpStyle := lipgloss.NewStyle().
 		Foreground(theme.Colors.Secondary).
 		Render(strings.Join(help, " │ "))
 
 	return lipgloss.JoinVertical(lipgloss.Left,
-		selectedStyle,
+		m.footerInfo(selectedInfo),
 		helpStyle,
 	)
 }
 
+// footerInfo renders the first line of the footer with the selected stream
+// and the interfaces approaching the link capacity
+func (m *Model) footerInfo(selectedInfo string) string {
+	info := lipgloss.NewStyle().
+		Foreground(theme.Colors.Highlight).
+		Render(selectedInfo)
+
+	if busy := m.busyInterfaces(); busy != "" {
+		info += lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusWarning).
+			Render(" │ " + busy)
+	}
+
+	return ansi.Truncate(info, m.width, "…")
+}
+
 // modalTickMsg represents a modal update tick message
 type modalTickMsg time.Time
 
-// modalTickCmd returns a command that sends modal tick messages
-func (m *Model) modalTickCmd() tea.Cmd {
-	return tea.Tick(50*time.Millisecond, func(t time.Time) tea.Msg {
+// tickCmd returns a command that sends modal tick messages while a modal, the
+// detail pane or the background statistics in the table need refreshing, as
+// often as the most frequently updated of them, but not more often than the
+// refresh interval. Only one tick is kept in flight at a time.
+func (m *Model) tickCmd() tea.Cmd {
+	if m.ticking {
+		return nil
+	}
+
+	interval := m.modals.UpdateInterval()
+	if m.splitView || m.collector.Count() > 0 {
+		interval = m.config.Settings.RefreshInterval()
+	}
+
+	if interval == 0 {
+		return nil
+	}
+
+	m.ticking = true
+
+	return tea.Tick(max(interval, m.config.Settings.RefreshInterval()), func(t time.Time) tea.Msg {
 		return modalTickMsg(t)
 	})
 }
 
+// clockTickMsg is sent once per second to refresh relative times
+type clockTickMsg time.Time
+
+// clockCmd returns a command that sends the next clock tick
+func clockCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return clockTickMsg(t)
+	})
+}
+
 // UpdateStreamsMsg contains updated stream data
 type UpdateStreamsMsg struct {
 	Streams []*stream.Stream
@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// bandwidthWarning is the share of the link capacity above which the
+// bandwidth of an interface is warned about
+const bandwidthWarning = 0.8
+
+// alertState remembers what has been reported already, so every event
+// results in a single notification
+type alertState struct {
+	// streams maps the IDs of known streams to the streams, nil before the
+	// first stream list was received
+	streams map[string]*stream.Stream
+
+	// sequenceErrors holds the last seen sequence error count per stream ID
+	sequenceErrors map[string]uint64
+
+	// silent holds the IDs of streams reported to have stopped receiving
+	silent map[string]bool
+
+	// errors holds the providers whose error has been reported
+	errors map[ModalErrorReporter]bool
+
+	// collisions holds the SSRCs reported to be used by several senders
+	collisions map[uint32]bool
+
+	// busy holds the bandwidth of the interfaces above bandwidthWarning,
+	// by interface name
+	busy map[string]stream.InterfaceBandwidth
+}
+
+func newAlertState() *alertState {
+	return &alertState{
+		sequenceErrors: make(map[string]uint64),
+		silent:         make(map[string]bool),
+		errors:         make(map[ModalErrorReporter]bool),
+		collisions:     make(map[uint32]bool),
+		busy:           make(map[string]stream.InterfaceBandwidth),
+	}
+}
+
+// notifyStreamChanges reports streams that appeared or disappeared since the
+// last update. The initial stream list is not reported.
+func (m *Model) notifyStreamChanges(streams []*stream.Stream) {
+	current := make(map[string]*stream.Stream, len(streams))
+	for _, s := range streams {
+		current[s.ID] = s
+	}
+
+	if m.alerts.streams != nil {
+		for _, s := range streams {
+			if _, ok := m.alerts.streams[s.ID]; !ok {
+				m.toasts.Add(toastInfo, "New stream: %s", s.Name())
+			}
+		}
+
+		for id, s := range m.alerts.streams {
+			if _, ok := current[id]; ok {
+				continue
+			}
+
+			if m.streamManager.IsExpected(s.IDHash()) {
+				m.toasts.Add(toastError, "Expected stream disappeared: %s", s.Name())
+			} else {
+				m.toasts.Add(toastWarning, "Stream removed: %s", s.Name())
+			}
+		}
+	}
+
+	m.alerts.streams = current
+}
+
+// checkAlerts reports new sequence errors and streams that stopped
+// receiving for all streams with background statistics, as well as errors of
+// open modals such as failed recordings
+func (m *Model) checkAlerts() {
+	for _, s := range m.table.streams {
+		snapshot, ok := m.collector.Snapshot(s.ID)
+		if !ok {
+			delete(m.alerts.sequenceErrors, s.ID)
+			delete(m.alerts.silent, s.ID)
+			continue
+		}
+
+		n := snapshot.SequenceErrors()
+		threshold := uint64(m.config.Settings.SequenceErrorThreshold)
+		if last, ok := m.alerts.sequenceErrors[s.ID]; ok && n >= last+threshold {
+			m.toasts.Add(toastWarning, "%s: %d new sequence errors", s.Name(), n-last)
+		}
+		m.alerts.sequenceErrors[s.ID] = n
+
+		status, _ := m.table.status(s, time.Now())
+		silent := status != statusReceiving && !snapshot.LastPacket().IsZero()
+
+		if silent && !m.alerts.silent[s.ID] {
+			m.toasts.Add(toastError, "%s: no packets received", s.Name())
+		}
+		m.alerts.silent[s.ID] = silent
+	}
+
+	colliding := make(map[uint32]bool)
+
+	for _, c := range m.streamManager.SSRCCollisions(time.Now()) {
+		if !m.alerts.collisions[c.SSRC] {
+			m.toasts.Add(toastWarning, "%s, cloned device configurations?", m.streamManager.CollisionLabel(c))
+		}
+
+		colliding[c.SSRC] = true
+	}
+
+	m.alerts.collisions = colliding
+
+	m.checkBandwidth()
+
+	open := make(map[ModalErrorReporter]bool)
+
+	for _, modal := range m.modals.modals {
+		reporter, ok := modal.provider.(ModalErrorReporter)
+		if !ok {
+			continue
+		}
+
+		open[reporter] = true
+
+		if err := reporter.Err(); err != nil && !m.alerts.errors[reporter] {
+			m.toasts.Add(toastError, "%s: %v", modal.provider.Title(), err)
+			m.alerts.errors[reporter] = true
+		}
+	}
+
+	// Forget closed modals
+	for reporter := range m.alerts.errors {
+		if !open[reporter] {
+			delete(m.alerts.errors, reporter)
+		}
+	}
+}
+
+// checkBandwidth reports interfaces whose joined groups approach the link
+// capacity, once each time they do
+func (m *Model) checkBandwidth() {
+	capacity := m.config.Settings.LinkCapacity()
+	busy := make(map[string]stream.InterfaceBandwidth)
+
+	for _, b := range m.streamManager.InterfaceBandwidth(time.Now()) {
+		if b.BitsPerSecond < capacity*bandwidthWarning {
+			continue
+		}
+
+		if _, ok := m.alerts.busy[b.Interface]; !ok {
+			m.toasts.Add(toastWarning, "%s: %s of %d Mbit/s link capacity used",
+				b.Interface, formatBitRate(b.BitsPerSecond), m.config.Settings.LinkCapacityMbps)
+		}
+
+		busy[b.Interface] = b
+	}
+
+	m.alerts.busy = busy
+}
+
+// busyInterfaces describes the interfaces approaching the link capacity for
+// the footer, empty if there are none
+func (m *Model) busyInterfaces() string {
+	capacity := m.config.Settings.LinkCapacity()
+	labels := make([]string, 0, len(m.alerts.busy))
+
+	for _, b := range m.alerts.busy {
+		labels = append(labels, fmt.Sprintf("%s at %.0f%% of link capacity", b.Interface, b.BitsPerSecond/capacity*100))
+	}
+
+	slices.Sort(labels)
+
+	return strings.Join(labels, " │ ")
+}
@@ -1,24 +1,21 @@
 package ui
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"net"
-	"os"
-	"path"
-	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/docker/go-units"
-	"github.com/go-audio/audio"
-	"github.com/go-audio/wav"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/recorder"
 	"github.com/holoplot/rtp-monitor/internal/stream"
-	"github.com/pion/rtp/v2"
+	"github.com/holoplot/rtp-monitor/internal/theme"
 )
 
-// VUModalContent implements ModalContentProvider for VU meter display
+// RecordModalContent implements ModalContentProvider for recording WAV files
 type RecordModalContent struct {
 	mutex sync.Mutex
 
@@ -26,57 +23,39 @@ type RecordModalContent struct {
 	height       int
 	contentWidth int
 
-	stream   *stream.Stream
-	receiver *stream.RTPReceiver
-
-	startTime time.Time
-
-	cancelFunc    context.CancelFunc
-	err           error
+	keyMap        *keymap.KeyMap
+	stream        *stream.Stream
+	recorder      *recorder.Recorder
 	wavFileFolder string
+	alignment     *recorder.Alignment
 
-	recordings []*recording
-}
+	// clipThreshold is the peak level in dBFS at or above which channels
+	// are reported as over level
+	clipThreshold float64
 
-type recording struct {
-	ch               chan []stream.SampleFrame
-	file             *os.File
-	wavEncoder       *wav.Encoder
-	bytesCounter     uint64
-	lastRecordedTime time.Time
-	err              error
+	// Before recording starts, the planned files are shown for confirmation
+	freeSpace uint64
+	freeErr   error
+	notice    string
 }
 
-// NewRecordModalContent creates a new VU modal content provider
-func NewRecordModalContent(s *stream.Stream, wavFileFolder string) *RecordModalContent {
+// NewRecordModalContent creates a new record modal content provider. The
+// files of recordings sharing the alignment start on the same media clock
+// sample.
+func NewRecordModalContent(keyMap *keymap.KeyMap, s *stream.Stream, wavFileFolder string, alignment *recorder.Alignment, clipThreshold float64) *RecordModalContent {
 	v := &RecordModalContent{
+		keyMap:        keyMap,
 		stream:        s,
-		recordings:    make([]*recording, 0),
 		wavFileFolder: wavFileFolder,
+		alignment:     alignment,
+		clipThreshold: clipThreshold,
 	}
 
 	return v
 }
 
-func (r *RecordModalContent) rtpReceiverCallback(sourceIndex int, _ net.Addr, packet *rtp.Packet) {
-	// The callback might fire before NewRTPReceiver() returns. Just ignore that packet.
-	if r.receiver == nil {
-		return
-	}
-
-	sampleFrames, err := r.receiver.ExtractSamples(packet)
-	if err != nil {
-		return
-	}
-
-	if sourceIndex >= len(r.recordings) {
-		return
-	}
-
-	r.recordings[sourceIndex].ch <- sampleFrames
-}
-
-// Init initializes the content provider with dimensions
+// Init initializes the content provider with dimensions and plans the files
+// to record to. Recording starts once confirmed.
 func (r *RecordModalContent) Init(width, height int) {
 	r.width = width
 	r.height = height
@@ -88,137 +67,298 @@ func (r *RecordModalContent) Init(width, height int) {
 	}
 	r.contentWidth -= 4 // Account for modal padding
 
-	r.startTime = time.Now()
+	r.recorder = recorder.New(r.stream, r.wavFileFolder)
+	r.recorder.SetAlignment(r.alignment)
+	r.freeSpace, r.freeErr = recorder.FreeDiskSpace(r.wavFileFolder)
+}
 
-	ctx, cancelFunc := context.WithCancel(context.Background())
-	r.cancelFunc = cancelFunc
+// HandleKey implements ModalKeyHandler to confirm the start of the recording,
+// to pause it and to stop it. Closing the modal while recording stops the
+// recording instead, and leaves the modal open to show the finalized files.
+func (r *RecordModalContent) HandleKey(key string, action keymap.Action) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	for i := range r.stream.Description.Sources {
-		rec := &recording{
-			ch:               make(chan []stream.SampleFrame, 1000),
-			lastRecordedTime: r.startTime,
+	if status := r.recorder.Status(); status.Started {
+		if status.Stopped {
+			return false
 		}
 
-		re := regexp.MustCompile(`[^a-zA-Z0-9]`)
-		streamName := re.ReplaceAllString(r.stream.Name(), "_")
-		fileName := fmt.Sprintf("%s_%s-%d.wav", streamName, r.startTime.Format(time.RFC3339), i)
-		fileName = path.Join(r.wavFileFolder, fileName)
-
-		outFile, err := os.Create(fileName)
-		if err != nil {
-			rec.err = err
+		switch {
+		case action == keymap.ActionPause:
+			r.recorder.SetPaused(!r.recorder.Paused())
+		case action == keymap.ActionClose:
+			r.recorder.Stop()
+		default:
+			return false
 		}
 
-		rec.file = outFile
-
-		rec.wavEncoder = wav.NewEncoder(outFile, int(r.stream.Description.SampleRate), 32,
-			int(r.stream.Description.ChannelCount), 1)
-
-		r.recordings = append(r.recordings, rec)
+		return true
+	}
 
-		if rec.err != nil {
-			continue
+	switch key {
+	case "enter", "y":
+		if err := r.recorder.Start(false); errors.Is(err, recorder.ErrFilesExist) {
+			r.notice = "Refusing to overwrite existing files, press O to overwrite"
+		}
+	case "O":
+		if !r.recorder.AnyExisting() {
+			return false
 		}
 
-		go func() {
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case frames := <-rec.ch:
-					buf := &audio.IntBuffer{
-						Format: &audio.Format{
-							NumChannels: int(r.stream.Description.ChannelCount),
-							SampleRate:  int(r.stream.Description.SampleRate),
-						},
-						Data:           make([]int, 0),
-						SourceBitDepth: 32,
-					}
-
-					for _, frame := range frames {
-						for _, sample := range frame {
-							buf.Data = append(buf.Data, int(sample))
-						}
-					}
-
-					if err := rec.wavEncoder.Write(buf); err != nil {
-						rec.err = fmt.Errorf("failed to write to WAV file: %w", err)
-						return
-					}
-
-					rec.bytesCounter += uint64(len(buf.Data) * 4)
-					rec.lastRecordedTime = time.Now()
-				}
-			}
-		}()
+		_ = r.recorder.Start(true)
+	default:
+		return false
 	}
 
-	if receiver, err := r.stream.NewRTPReceiver(r.rtpReceiverCallback); err == nil {
-		r.receiver = receiver
-	} else {
-		r.err = err
-	}
+	return true
 }
 
+// Close stops the recording
 func (r *RecordModalContent) Close() {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	if r.receiver != nil {
-		r.receiver.Close()
-	}
-
-	for _, rec := range r.recordings {
-		if rec.wavEncoder != nil {
-			_ = rec.wavEncoder.Close()
-		}
-
-		if rec.file != nil {
-			_ = rec.file.Close()
+	r.recorder.Stop()
+}
 
-			// Empty files are worthless, so remove them to avoid confusion
-			if rec.bytesCounter == 0 {
-				_ = os.Remove(rec.file.Name())
-			}
-		}
-	}
+// Err implements ModalErrorReporter
+func (r *RecordModalContent) Err() error {
+	return r.recorder.Err()
 }
 
 // Content returns the content lines to be displayed
 func (r *RecordModalContent) Content() []string {
 	l := newLineBuffer(lipgloss.NewStyle())
 
-	if r.err != nil {
-		l.p("Error: %s", r.err)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := r.recorder.Status()
+
+	if status.Err != nil {
+		l.p("Error: %s", status.Err)
 		return l.lines()
 	}
 
-	l.p("RECORDING ...")
+	if !status.Started {
+		return r.confirmationContent(l)
+	}
+
+	if status.Stopped {
+		return r.summaryContent(l, status)
+	}
+
+	if status.Paused {
+		l.p("PAUSED")
+	} else {
+		l.p("RECORDING ...")
+	}
+
 	l.p("")
 
-	for i, rec := range r.recordings {
+	for i, file := range status.Files {
 		l.p("Recording %d:", i+1)
 
-		if rec.err != nil {
-			l.p("  Error: %s", rec.err)
+		if file.Err != nil {
+			l.p("  Error: %s", file.Err)
 			l.p("")
 		} else {
-			dur := rec.lastRecordedTime.Sub(r.startTime)
 			l.p("  ├─Channels:       %d", r.stream.Description.ChannelCount)
 			l.p("  ├─Sample Rate:    %d", r.stream.Description.SampleRate)
-			l.p("  ├─File:           %s", rec.file.Name())
-			l.p("  ├─Duration:       %02d:%02d.%03d",
-				int(dur.Minutes()),
-				int(dur.Seconds())%60,
-				int(dur.Milliseconds())%1000)
+			l.p("  ├─File:           %s", file.Name)
+			l.p("  ├─Elapsed:        %s", formatDuration(time.Since(status.StartTime)))
+			l.p("  ├─Recorded:       %s", formatDuration(file.Recorded))
+
+			if file.Aligned {
+				l.p("  ├─Start:          media clock sample %d", file.StartSample)
+			} else if file.Bytes > 0 {
+				l.p("  ├─Start:          first packet, no PTP media clock to align to")
+			}
 
-			l.p("  └─Recorded bytes: %s", units.HumanSize(float64(rec.bytesCounter)))
+			l.p("  ├─Lost packets:   %d, filled in %d gaps", file.PacketsLost, file.GapsFilled)
+			l.p("  └─Recorded bytes: %s", units.HumanSize(float64(file.Bytes)))
 			l.p("")
 
-			l.p("Hit 'q' to stop")
+			r.levelContent(l, file)
 		}
 	}
 
+	if len(status.Pauses) > 0 {
+		l.p("Pauses:")
+
+		for i, pause := range status.Pauses {
+			prefix := "  ├─ "
+			if i == len(status.Pauses)-1 {
+				prefix = "  └─ "
+			}
+
+			end := pause.End
+			if end.IsZero() {
+				end = time.Now()
+			}
+
+			l.p("%sat %s for %s", prefix, formatDuration(pause.Position), formatDuration(end.Sub(pause.Start)))
+		}
+
+		l.p("")
+	}
+
+	if status.Paused {
+		l.p("%s: resume, Esc: stop and finalize the files", r.keyMap.Label(keymap.ActionPause))
+	} else {
+		l.p("%s: pause, Esc: stop and finalize the files", r.keyMap.Label(keymap.ActionPause))
+	}
+
+	return l.lines()
+}
+
+// summaryContent lists the finalized files of a stopped recording
+func (r *RecordModalContent) summaryContent(l *lineBuffer, status recorder.Status) []string {
+	l.p("RECORDING STOPPED")
+	l.p("")
+
+	for i, file := range status.Files {
+		l.p("Recording %d:", i+1)
+
+		switch {
+		case file.Removed:
+			l.p("  └─File:         %s, removed as no audio was received", file.Name)
+		case file.Err != nil || file.Summary == nil:
+			l.p("  ├─File:         %s", file.Name)
+			l.p("  └─Error:        %s", file.Err)
+		default:
+			summary := file.Summary
+
+			peaks := make([]string, len(summary.PeakLevels))
+			for i, peak := range summary.PeakLevels {
+				peaks[i] = fmt.Sprintf("%.1f", peak)
+			}
+
+			l.p("  ├─File:         %s", file.Name)
+			l.p("  ├─Duration:     %s, %d frames", formatDuration(file.Recorded), summary.Frames)
+			l.p("  ├─Size:         %s", units.HumanSize(float64(file.Size)))
+			l.p("  ├─Lost packets: %d, %d arrived late", summary.PacketsLost, summary.PacketsLate)
+			l.p("  ├─Gaps filled:  %d, %d frames of silence", summary.GapsFilled, summary.FramesFilled)
+			l.p("  ├─Peak levels:  %s dBFS", strings.Join(peaks, " "))
+			l.p("  ├─SHA-256:      %s", summary.SHA256)
+			l.p("  └─Summary:      %s", recorder.SummaryFileName(file.Name))
+		}
+
+		l.p("")
+	}
+
+	l.p("Esc: close")
+
+	return l.lines()
+}
+
+// levelColumnWidth is the width of the peak meter of a channel in the record
+// modal, including the gap to the next one
+const levelColumnWidth = 21
+
+// levelContent shows the peak meters of the channels of a recorded file and
+// warns of channels that reached the clip threshold or of silence
+func (r *RecordModalContent) levelContent(l *lineBuffer, file recorder.FileStatus) {
+	if len(file.Levels) == 0 {
+		return
+	}
+
+	warning := lipgloss.NewStyle().Foreground(theme.Colors.StatusError).Bold(true)
+	columns := max(r.contentWidth/levelColumnWidth, 1)
+
+	var (
+		cells  []string
+		over   []string
+		signal bool
+	)
+
+	for i, level := range file.Levels {
+		cells = append(cells, fmt.Sprintf("%2d %-8s %6.1f", i+1, miniMeter(level.Peak, r.clipThreshold), level.Peak))
+
+		if file.MaxPeaks[i] >= r.clipThreshold {
+			over = append(over, fmt.Sprintf("%d (%.1f dBFS)", i+1, file.MaxPeaks[i]))
+		}
+
+		signal = signal || level.Peak > -miniMeterRange
+	}
+
+	l.p("  Peak levels (dBFS):")
+
+	for start := 0; start < len(cells); start += columns {
+		l.p("    %s", strings.Join(cells[start:min(start+columns, len(cells))], "   "))
+	}
+
+	if len(over) > 0 {
+		l.p("  %s", warning.Render(fmt.Sprintf("OVER LEVEL: channels %s peaked at or above %.1f dBFS", strings.Join(over, ", "), r.clipThreshold)))
+	}
+
+	if !signal {
+		l.p("  %s", warning.Render("NO SIGNAL on any channel"))
+	}
+
+	l.p("")
+}
+
+// formatDuration formats a duration as minutes, seconds and milliseconds
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d.%03d",
+		int(d.Minutes()),
+		int(d.Seconds())%60,
+		int(d.Milliseconds())%1000)
+}
+
+// confirmationContent lists the planned files, the expected data rate and the
+// free disk space. The caller must hold the mutex.
+func (r *RecordModalContent) confirmationContent(l *lineBuffer) []string {
+	files := r.recorder.FileNames()
+	existing := r.recorder.Existing()
+	total := r.recorder.BytesPerSecond() * uint64(len(files))
+
+	l.p("Start recording?")
+	l.p("")
+	l.p("Files:")
+
+	for i, file := range files {
+		prefix := "  ├─ "
+		if i == len(files)-1 {
+			prefix = "  └─ "
+		}
+
+		if existing[i] {
+			l.p("%s%s (exists)", prefix, file)
+		} else {
+			l.p("%s%s", prefix, file)
+		}
+	}
+
+	l.p("")
+	l.p("Format:          %d channels, %d Hz, 32 bit",
+		r.stream.Description.ChannelCount, r.stream.Description.SampleRate)
+	l.p("Estimated rate:  %.1f Mbit/s (%s per hour)",
+		float64(total*8)/1e6, units.HumanSize(float64(total*3600)))
+
+	switch {
+	case r.freeErr != nil:
+		l.p("Free disk space: unknown (%s)", r.freeErr)
+	case total > 0:
+		l.p("Free disk space: %s (about %s of recording)",
+			units.HumanSize(float64(r.freeSpace)),
+			(time.Duration(r.freeSpace/total) * time.Second).Round(time.Minute))
+	default:
+		l.p("Free disk space: %s", units.HumanSize(float64(r.freeSpace)))
+	}
+
+	l.p("")
+
+	if r.notice != "" {
+		l.p("%s", r.notice)
+		l.p("")
+	}
+
+	if r.recorder.AnyExisting() {
+		l.p("O: overwrite existing files and start, Esc: cancel")
+	} else {
+		l.p("Enter: start recording, Esc: cancel")
+	}
+
 	return l.lines()
 }
 
@@ -3,32 +3,78 @@ package ui
 import (
 	"fmt"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/memory"
+	"github.com/holoplot/rtp-monitor/internal/ptp"
+	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/senderreport"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/pion/rtcp"
 )
 
-// DetailsModalContent implements ModalContentProvider for stream details
+const (
+	// rtcpMaxSSRCs is the maximum number of sender SSRCs offered as filter.
+	// The SSRC seen first is dropped when a new one appears.
+	rtcpMaxSSRCs = 32
+)
+
+// logMemory accounts the text held in RTCP logs
+var logMemory = memory.NewAccount("logs")
+
+// rtcpPacketTypes lists the packet type filters in the order they are cycled
+var rtcpPacketTypes = []string{"", "SR", "RR", "SDES", "other"}
+
+// rtcpLogEntry holds the log lines of one received RTCP packet
+type rtcpLogEntry struct {
+	packetType string
+	ssrc       uint32
+	lines      []string
+	size       int
+}
+
+// RTCPModalContent implements ModalContentProvider for the analysis of the
+// sender reports of a stream and the RTCP log
 type RTCPModalContent struct {
 	mutex sync.Mutex
 
-	stream   *stream.Stream
-	receiver *stream.RTCPReceiver
+	keyMap     *keymap.KeyMap
+	stream     *stream.Stream
+	receiver   *stream.RTCPReceiver
+	ptpMonitor *ptp.Monitor
+	analyzer   *senderreport.Analyzer
 
 	err        error
 	lastUpdate time.Time
-	log        []string
+	log        *ring.RingBuffer[rtcpLogEntry]
+	logBytes   int
+
+	// ssrcs holds the last sender SSRCs seen, in order of appearance
+	ssrcs []uint32
+
+	showLog    bool
+	paused     bool
+	typeFilter int
+	ssrcFilter int // index into ssrcs + 1, 0 means all
 
 	height int
 }
 
-func NewRTCPModalContent(stream *stream.Stream) *RTCPModalContent {
+// NewRTCPModalContent creates a new RTCP content provider whose log keeps
+// the given number of packets. The sender clocks are compared to PTP if
+// ptpMonitor is not nil.
+func NewRTCPModalContent(keyMap *keymap.KeyMap, stream *stream.Stream, retention int, ptpMonitor *ptp.Monitor) *RTCPModalContent {
 	d := &RTCPModalContent{
-		stream: stream,
-		log:    make([]string, 0),
+		keyMap:     keyMap,
+		stream:     stream,
+		ptpMonitor: ptpMonitor,
+		analyzer:   senderreport.New(),
+		log:        ring.NewRingBuffer[rtcpLogEntry](retention),
 	}
 
 	return d
@@ -42,14 +88,28 @@ func (d *RTCPModalContent) rtpReceiverCallback(sourceIndex int, src net.Addr, pk
 
 	now := time.Now()
 
+	var ptpTime time.Time
+	if d.ptpMonitor != nil {
+		ptpTime, _ = d.ptpMonitor.TimeAt(now)
+	}
+
+	d.analyzer.Add(pkt, now, ptpTime)
+
 	var lines []string
+	var packetType string
+	var ssrc uint32
 
 	switch p := pkt.(type) {
 	case *rtcp.SenderReport:
-		s := fmt.Sprintf("SenderReport from %x, NTPTime %d.%d, RTPTime %d, PacketCount %d, OctetCount %d",
-			p.SSRC, p.NTPTime>>32, p.NTPTime&0xFFFFFFFF, p.RTPTime, p.PacketCount, p.OctetCount)
+		packetType, ssrc = "SR", p.SSRC
+
+		s := fmt.Sprintf("SenderReport from %x, NTPTime %d.%d (%s), RTPTime %d, PacketCount %d, OctetCount %d",
+			p.SSRC, p.NTPTime>>32, p.NTPTime&0xFFFFFFFF, senderreport.NTPTime(p.NTPTime).Format(time.RFC3339Nano),
+			p.RTPTime, p.PacketCount, p.OctetCount)
 		lines = append(lines, s)
 	case *rtcp.ReceiverReport:
+		packetType, ssrc = "RR", p.SSRC
+
 		if p.SSRC != 0 {
 			s := fmt.Sprintf("ReceiverReport from %x", p.SSRC)
 			lines = append(lines, s)
@@ -61,6 +121,8 @@ func (d *RTCPModalContent) rtpReceiverCallback(sourceIndex int, src net.Addr, pk
 			}
 		}
 	case *rtcp.SourceDescription:
+		packetType = "SDES"
+
 		var chunks []string
 
 		for _, i := range p.Chunks {
@@ -68,10 +130,20 @@ func (d *RTCPModalContent) rtpReceiverCallback(sourceIndex int, src net.Addr, pk
 			chunks = append(chunks, s)
 		}
 
+		if len(p.Chunks) > 0 {
+			ssrc = p.Chunks[0].Source
+		}
+
 		s := fmt.Sprintf("SourceDescription: %s", strings.Join(chunks, ", "))
 		lines = append(lines, s)
 
 	default:
+		packetType = "other"
+
+		if ssrcs := p.DestinationSSRC(); len(ssrcs) > 0 {
+			ssrc = ssrcs[0]
+		}
+
 		s := fmt.Sprintf("Unsupported packet type %T", p)
 		lines = append(lines, s)
 	}
@@ -80,16 +152,87 @@ func (d *RTCPModalContent) rtpReceiverCallback(sourceIndex int, src net.Addr, pk
 		return
 	}
 
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%s | %s | %s", now.Format(time.RFC3339), src, line)
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
+	if ssrc != 0 && !slices.Contains(d.ssrcs, ssrc) {
+		d.addSSRC(ssrc)
+	}
+
+	entry := rtcpLogEntry{
+		packetType: packetType,
+		ssrc:       ssrc,
+		lines:      lines,
+	}
+
 	for _, line := range lines {
-		d.log = append(d.log, fmt.Sprintf("%s | %s | %s", now.Format(time.RFC3339), src, line))
+		entry.size += len(line)
 	}
 
+	d.pushLog(entry)
+
 	d.lastUpdate = now
 }
 
+// pushLog adds an entry to the log. The oldest entries are dropped when the
+// log is full or the memory budget is exhausted. The caller must hold the
+// mutex.
+func (d *RTCPModalContent) pushLog(entry rtcpLogEntry) {
+	if d.log.IsFull() {
+		d.dropOldest()
+	}
+
+	for !logMemory.Reserve(entry.size) {
+		if !d.dropOldest() {
+			return
+		}
+	}
+
+	d.logBytes += entry.size
+	d.log.Push(entry)
+}
+
+// dropOldest removes the oldest entry of the log and returns false if the
+// log is empty. The caller must hold the mutex.
+func (d *RTCPModalContent) dropOldest() bool {
+	entry, ok := d.log.Pop()
+	if !ok {
+		return false
+	}
+
+	logMemory.Release(entry.size)
+	d.logBytes -= entry.size
+
+	return true
+}
+
+// clearLog removes all entries of the log. The caller must hold the mutex.
+func (d *RTCPModalContent) clearLog() {
+	d.log.Clear()
+
+	logMemory.Release(d.logBytes)
+	d.logBytes = 0
+}
+
+// addSSRC adds a sender SSRC to the filter choices, dropping the oldest one
+// if there are too many. The caller must hold the mutex.
+func (d *RTCPModalContent) addSSRC(ssrc uint32) {
+	if len(d.ssrcs) == rtcpMaxSSRCs {
+		d.ssrcs = slices.Delete(d.ssrcs, 0, 1)
+
+		// Keep the filter on the same SSRC, or show all if it was dropped
+		if d.ssrcFilter > 0 {
+			d.ssrcFilter--
+		}
+	}
+
+	d.ssrcs = append(d.ssrcs, ssrc)
+}
+
 func (d *RTCPModalContent) Init(width, height int) {
 	d.lastUpdate = time.Now()
 
@@ -106,6 +249,41 @@ func (d *RTCPModalContent) Close() {
 	if d.receiver != nil {
 		d.receiver.Close()
 	}
+
+	d.mutex.Lock()
+	d.clearLog()
+	d.mutex.Unlock()
+}
+
+// HandleKey implements ModalKeyHandler to switch between the analysis and
+// the log, and to pause, clear and filter the log
+func (d *RTCPModalContent) HandleKey(_ string, action keymap.Action) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if action == keymap.ActionRTCPLog {
+		d.showLog = !d.showLog
+		return true
+	}
+
+	if !d.showLog {
+		return false
+	}
+
+	switch action {
+	case keymap.ActionPause:
+		d.paused = !d.paused
+	case keymap.ActionClear:
+		d.clearLog()
+	case keymap.ActionFilterType:
+		d.typeFilter = (d.typeFilter + 1) % len(rtcpPacketTypes)
+	case keymap.ActionFilterSSRC:
+		d.ssrcFilter = (d.ssrcFilter + 1) % (len(d.ssrcs) + 1)
+	default:
+		return false
+	}
+
+	return true
 }
 
 // Content returns the content lines to be displayed
@@ -119,13 +297,143 @@ func (d *RTCPModalContent) Content() []string {
 		lines = append(lines, fmt.Sprintf("Error creating stream receiver: %v", d.err))
 	}
 
-	lines = append(lines, d.log...)
+	for i, source := range d.stream.Description.Sources {
+		lines = append(lines, fmt.Sprintf("Source %d: RTCP on %s", i+1, source.RTCPLabel()))
+	}
+
+	if !d.showLog {
+		lines = append(lines, fmt.Sprintf("[analysis] %s: log", d.keyMap.Label(keymap.ActionRTCPLog)), "")
+		return append(lines, d.analysis(time.Now())...)
+	}
+
+	packetType := rtcpPacketTypes[d.typeFilter]
+
+	var ssrc uint32
+	if d.ssrcFilter > 0 {
+		ssrc = d.ssrcs[d.ssrcFilter-1]
+	}
+
+	lines = append(lines, d.statusLine(packetType, ssrc), "")
+
+	for _, entry := range d.log.ToSlice() {
+		if packetType != "" && entry.packetType != packetType {
+			continue
+		}
+
+		if ssrc != 0 && entry.ssrc != ssrc {
+			continue
+		}
+
+		lines = append(lines, entry.lines...)
+	}
 
 	return lines
 }
 
+// statusLine describes the active filters and the keys to change them.
+// The caller must hold the mutex.
+func (d *RTCPModalContent) statusLine(packetType string, ssrc uint32) string {
+	scroll := "auto-scroll"
+	if d.paused {
+		scroll = "paused"
+	}
+
+	if packetType == "" {
+		packetType = "all"
+	}
+
+	ssrcLabel := "all"
+	if ssrc != 0 {
+		ssrcLabel = fmt.Sprintf("%x", ssrc)
+	}
+
+	k := d.keyMap.Label
+
+	return fmt.Sprintf("[%s] type: %s, SSRC: %s, %d/%d packets | %s: analysis, %s: pause, %s: clear, %s: type, %s: SSRC",
+		scroll, packetType, ssrcLabel, d.log.Size(), d.log.MaxSize(),
+		k(keymap.ActionRTCPLog), k(keymap.ActionPause), k(keymap.ActionClear), k(keymap.ActionFilterType), k(keymap.ActionFilterSSRC))
+}
+
+// analysis describes the sender reports of each sender: the mapping of its
+// NTP clock to its RTP timestamps, the drift of its clocks, and the round
+// trips of the receiver reports referring to them
+func (d *RTCPModalContent) analysis(now time.Time) []string {
+	senders := d.analyzer.Senders()
+	if len(senders) == 0 {
+		return []string{"No sender reports received yet"}
+	}
+
+	clockRate := d.stream.Description.SampleRate
+
+	l := newLineBuffer(lipgloss.Style{})
+
+	for _, s := range senders {
+		age := now.Sub(s.Last.Arrival)
+
+		l.p("Sender %x (%d reports, the last %s ago)", s.SSRC, s.Reports, age.Truncate(time.Millisecond))
+		l.p("  ├─ NTP ↔ RTP:       RTP %d at %s", s.Last.RTP, s.Last.NTP.Format(time.RFC3339Nano))
+		l.p("  ├─ RTP now:         %d (extrapolated)", s.RTPAt(now.Add(s.LocalOffset()), clockRate))
+
+		if rate, ok := s.ClockRate(); ok {
+			drift, _ := s.RTPDrift(clockRate)
+			l.p("  ├─ RTP clock:       %.2f Hz, %s against %d Hz", rate, ppmLabel(drift), clockRate)
+		} else {
+			l.p("  ├─ RTP clock:       measuring, needs reports spanning a second")
+		}
+
+		if drift, ok := s.LocalDrift(); ok {
+			l.p("  ├─ Local clock:     %s, NTP ahead by %s", ppmLabel(drift), s.LocalOffset())
+		} else {
+			l.p("  ├─ Local clock:     NTP ahead by %s", s.LocalOffset())
+		}
+
+		offset, hasPTP := s.PTPOffset()
+		if drift, ok := s.PTPDrift(); ok {
+			l.p("  ├─ PTP clock:       %s, NTP ahead by %s", ppmLabel(drift), offset)
+		} else if hasPTP {
+			l.p("  ├─ PTP clock:       NTP ahead by %s", offset)
+		} else {
+			l.p("  ├─ PTP clock:       no PTP time received")
+		}
+
+		if packetRate, ok := s.PacketRate(); ok {
+			bitrate, _ := s.Bitrate()
+			l.p("  ├─ Sent:            %.1f packets/s, %.1f kbit/s payload", packetRate, bitrate/1000)
+		}
+
+		l.p("  ├─ LSR:             %08x, DLSR %d/65536 s", s.Last.LSR, uint32(age.Seconds()*65536))
+
+		if len(s.RoundTrips) == 0 {
+			l.p("  └─ Round trips:     no receiver reports refer to its reports")
+		} else {
+			l.p("  └─ Round trips:")
+
+			for i, rt := range s.RoundTrips {
+				branch := "├─"
+				if i == len(s.RoundTrips)-1 {
+					branch = "└─"
+				}
+
+				l.p("       %s %x: %s, %s ago", branch, rt.Reporter, rt.Time.Round(time.Microsecond), now.Sub(rt.Arrival).Truncate(time.Second))
+			}
+		}
+
+		l.p("")
+	}
+
+	l.p("The drifts are averaged since the first report. NTP offsets include the network delay,")
+	l.p("round trips are exact if the monitor is close to the sender.")
+
+	return l.lines()
+}
+
+// ppmLabel formats a clock drift
+func ppmLabel(ppm float64) string {
+	return fmt.Sprintf("%+.2f ppm", ppm)
+}
+
 func (d *RTCPModalContent) Title() string {
-	return "RTCP LOG"
+	return "RTCP"
 }
 
 // UpdateInterval returns how often the modal content should be updated
@@ -135,7 +443,10 @@ func (d *RTCPModalContent) UpdateInterval() time.Duration {
 
 // AutoScroll returns whether the modal should automatically scroll to the bottom
 func (d *RTCPModalContent) AutoScroll() bool {
-	return true
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.showLog && !d.paused
 }
 
 // Update is called periodically to refresh content
@@ -4,24 +4,47 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
 )
 
 // SDPModalContent implements ModalContentProvider for raw SDP display
 type SDPModalContent struct {
 	stream *stream.Stream
+	issues []stream.SDPIssue
+	styles sdpStyles
+}
+
+type sdpStyles struct {
+	Type      lipgloss.Style
+	Separator lipgloss.Style
+	Media     lipgloss.Style
+	Attribute lipgloss.Style
+	Value     lipgloss.Style
+	Error     lipgloss.Style
+	Warning   lipgloss.Style
 }
 
 // NewSDPModalContent creates a new SDP modal content provider
 func NewSDPModalContent(stream *stream.Stream) *SDPModalContent {
 	return &SDPModalContent{
 		stream: stream,
+		styles: sdpStyles{
+			Type:      lipgloss.NewStyle().Foreground(theme.Colors.Primary).Bold(true),
+			Separator: lipgloss.NewStyle().Foreground(theme.Colors.TableBorder),
+			Media:     lipgloss.NewStyle().Foreground(theme.Colors.Highlight).Bold(true),
+			Attribute: lipgloss.NewStyle().Foreground(theme.Colors.Secondary),
+			Value:     lipgloss.NewStyle().Foreground(theme.Colors.Foreground),
+			Error:     lipgloss.NewStyle().Foreground(theme.Colors.StatusError),
+			Warning:   lipgloss.NewStyle().Foreground(theme.Colors.StatusWarning),
+		},
 	}
 }
 
 // Init initializes the content provider with dimensions
 func (s *SDPModalContent) Init(width, height int) {
-	// No initialization needed for SDP modal - stream is set in constructor
+	s.issues = stream.ValidateSDP(s.stream.SDP)
 }
 
 // Close closes the modal content provider
@@ -29,18 +52,75 @@ func (s *SDPModalContent) Close() {
 	// No cleanup needed for SDP modal
 }
 
-// Content returns the SDP content lines to be displayed
+// Content returns the SDP content lines to be displayed, highlighted and
+// with validation issues shown below the lines they refer to
 func (s *SDPModalContent) Content() []string {
 	var lines []string
 
-	sdpLines := strings.SplitSeq(string(s.stream.SDP), "\n")
-	for line := range sdpLines {
-		lines = append(lines, SanitizeASCII(line))
+	// Issues concerning the SDP as a whole go first
+	for _, issue := range s.issues {
+		if issue.Line < 0 {
+			lines = append(lines, s.renderIssue(issue, "! "))
+		}
+	}
+
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	for i, line := range strings.Split(string(s.stream.SDP), "\n") {
+		lines = append(lines, s.highlight(SanitizeASCII(line)))
+
+		for _, issue := range s.issues {
+			if issue.Line == i {
+				lines = append(lines, s.renderIssue(issue, "  └─ "))
+			}
+		}
 	}
 
 	return lines
 }
 
+// highlight colorizes the field type, attribute name and value of an SDP line
+func (s *SDPModalContent) highlight(line string) string {
+	t, value, ok := strings.Cut(line, "=")
+	if !ok || len(t) != 1 {
+		return line
+	}
+
+	prefix := s.styles.Type.Render(t) + s.styles.Separator.Render("=")
+
+	switch t {
+	case "a":
+		name, attrValue, hasValue := strings.Cut(value, ":")
+		if !hasValue {
+			return prefix + s.styles.Attribute.Render(name)
+		}
+
+		return prefix + s.styles.Attribute.Render(name) +
+			s.styles.Separator.Render(":") + s.styles.Value.Render(attrValue)
+	case "m":
+		return prefix + s.styles.Media.Render(value)
+	default:
+		return prefix + s.styles.Value.Render(value)
+	}
+}
+
+// renderIssue renders a validation issue
+func (s *SDPModalContent) renderIssue(issue stream.SDPIssue, prefix string) string {
+	style := s.styles.Warning
+	if issue.Severity == stream.SDPError {
+		style = s.styles.Error
+	}
+
+	return style.Render(prefix + issue.Severity.String() + ": " + issue.Message)
+}
+
+// CopyContent implements ModalCopyProvider, copying the plain SDP
+func (s *SDPModalContent) CopyContent() string {
+	return string(s.stream.SDP)
+}
+
 // Title returns the modal title
 func (s *SDPModalContent) Title() string {
 	return "SDP Content"
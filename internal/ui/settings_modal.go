@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/keymap"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// setting is one line of the settings modal. Settings either cycle through
+// a list of choices or are edited as text.
+type setting struct {
+	label string
+
+	// choices and index are used for settings with a fixed set of values
+	choices []string
+	index   func(s *config.Settings) int
+	set     func(s *config.Settings, i int)
+
+	// text points to the value of free text settings
+	text func(s *config.Settings) *string
+}
+
+// settingsList lists all settings in the order they are shown
+var settingsList = []setting{
+	intSetting("Refresh interval", "%d ms", []int{50, 100, 250, 500, 1000},
+		func(s *config.Settings) *int { return &s.RefreshIntervalMs }),
+	modalRefreshSetting("Meters refresh", "meters"),
+	modalRefreshSetting("Details refresh", "details"),
+	modalRefreshSetting("RTCP refresh", "rtcp"),
+	{
+		label:   "Meter clip threshold",
+		choices: []string{"-0.1 dBFS", "-0.5 dBFS", "-1.0 dBFS", "-3.0 dBFS"},
+		index: func(s *config.Settings) int {
+			return max(slices.Index([]float64{-0.1, -0.5, -1, -3}, s.ClipThreshold), 0)
+		},
+		set: func(s *config.Settings, i int) {
+			s.ClipThreshold = []float64{-0.1, -0.5, -1, -3}[i]
+		},
+	},
+	intSetting("Meter clip hold", "%d s", []int{1, 3, 5, 10},
+		func(s *config.Settings) *int { return &s.ClipHoldSeconds }),
+	{
+		label: "Recording folder",
+		text:  func(s *config.Settings) *string { return &s.WavFolder },
+	},
+	{
+		label: "Export folder",
+		text:  func(s *config.Settings) *string { return &s.ExportFolder },
+	},
+	{
+		label:   "Theme",
+		choices: theme.Names(),
+		index: func(s *config.Settings) int {
+			return max(slices.Index(theme.Names(), s.Theme), 0)
+		},
+		set: func(s *config.Settings, i int) {
+			s.Theme = theme.Names()[i]
+		},
+	},
+	{
+		label:   "Meter gradient",
+		choices: gradientChoices(),
+		index: func(s *config.Settings) int {
+			return max(slices.Index(gradientChoices(), s.MeterGradient), 0)
+		},
+		set: func(s *config.Settings, i int) {
+			s.MeterGradient = gradientChoices()[i]
+		},
+	},
+	intSetting("Stale after", "%d s", []int{30, 60, 120, 300, 600},
+		func(s *config.Settings) *int { return &s.StaleTimeoutSeconds }),
+	intSetting("Sequence error alert", "%d/s", []int{1, 10, 100, 1000},
+		func(s *config.Settings) *int { return &s.SequenceErrorThreshold }),
+	intSetting("Log retention", "%d entries", []int{1000, 5000, 20000, 100000},
+		func(s *config.Settings) *int { return &s.LogRetention }),
+	intSetting("Virtual receiver link offset", "%d µs", []int{125, 250, 500, 1000, 2000, 4000, 10000, 20000},
+		func(s *config.Settings) *int { return &s.LinkOffsetUs }),
+	intSetting("Link capacity", "%d Mbit/s", []int{100, 1000, 2500, 10000, 25000},
+		func(s *config.Settings) *int { return &s.LinkCapacityMbps }),
+	{
+		label:   "Table level meters",
+		choices: []string{"off", "on"},
+		index: func(s *config.Settings) int {
+			if s.MiniMeters {
+				return 1
+			}
+
+			return 0
+		},
+		set: func(s *config.Settings, i int) {
+			s.MiniMeters = i == 1
+		},
+	},
+}
+
+// gradientChoices lists the meter gradients, starting with the one of the theme
+func gradientChoices() []string {
+	return append([]string{theme.GradientFromTheme}, theme.GradientNames()...)
+}
+
+// intSetting creates a setting that cycles through a list of integers
+func intSetting(label, format string, values []int, field func(s *config.Settings) *int) setting {
+	choices := make([]string, len(values))
+	for i, v := range values {
+		choices[i] = fmt.Sprintf(format, v)
+	}
+
+	return setting{
+		label:   label,
+		choices: choices,
+		index: func(s *config.Settings) int {
+			return max(slices.Index(values, *field(s)), 0)
+		},
+		set: func(s *config.Settings, i int) {
+			*field(s) = values[i]
+		},
+	}
+}
+
+// modalRefreshIntervals are the choices of the modal refresh settings, zero
+// meaning the default of the modal
+var modalRefreshIntervals = []int{0, 50, 100, 250, 500, 1000, 2000}
+
+// modalRefreshSetting creates a setting for the refresh interval of the
+// modals with the given name in the ModalRefreshMs settings
+func modalRefreshSetting(label, name string) setting {
+	choices := make([]string, len(modalRefreshIntervals))
+	for i, ms := range modalRefreshIntervals {
+		choices[i] = fmt.Sprintf("%d ms", ms)
+	}
+
+	choices[0] = "default"
+
+	return setting{
+		label:   label,
+		choices: choices,
+		index: func(s *config.Settings) int {
+			return max(slices.Index(modalRefreshIntervals, s.ModalRefreshMs[name]), 0)
+		},
+		set: func(s *config.Settings, i int) {
+			if modalRefreshIntervals[i] == 0 {
+				delete(s.ModalRefreshMs, name)
+				return
+			}
+
+			if s.ModalRefreshMs == nil {
+				s.ModalRefreshMs = make(map[string]int)
+			}
+
+			s.ModalRefreshMs[name] = modalRefreshIntervals[i]
+		},
+	}
+}
+
+// SettingsModalContent implements ModalContentProvider for editing the
+// settings. Every change is applied and persisted immediately.
+type SettingsModalContent struct {
+	config   *config.Config
+	onChange func()
+
+	selected int
+	editing  bool
+	input    string
+
+	selectedStyle lipgloss.Style
+	hintStyle     lipgloss.Style
+}
+
+// NewSettingsModalContent creates a new settings modal content provider.
+// onChange is called after a setting was changed.
+func NewSettingsModalContent(cfg *config.Config, onChange func()) *SettingsModalContent {
+	return &SettingsModalContent{
+		config:   cfg,
+		onChange: onChange,
+		selectedStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.TableRowSelected).
+			Background(theme.Colors.TableRowSelectedBg).
+			Bold(true),
+		hintStyle: lipgloss.NewStyle().
+			Foreground(theme.Colors.Secondary),
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (s *SettingsModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (s *SettingsModalContent) Close() {}
+
+// HandleKey implements ModalKeyHandler for navigating and changing settings
+func (s *SettingsModalContent) HandleKey(key string, action keymap.Action) bool {
+	if s.editing {
+		return s.handleTextInput(key)
+	}
+
+	current := settingsList[s.selected]
+
+	switch {
+	case action == keymap.ActionUp:
+		s.selected = max(s.selected-1, 0)
+	case action == keymap.ActionDown:
+		s.selected = min(s.selected+1, len(settingsList)-1)
+	case key == "left" || key == "h":
+		s.cycle(current, -1)
+	case key == "right" || key == "l":
+		s.cycle(current, 1)
+	case key == "enter":
+		if current.text != nil {
+			s.editing = true
+			s.input = *current.text(&s.config.Settings)
+		} else {
+			s.cycle(current, 1)
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// handleTextInput edits the value of a text setting. Enter confirms, Esc
+// cancels; all other keys are consumed so they don't trigger actions.
+func (s *SettingsModalContent) handleTextInput(key string) bool {
+	switch key {
+	case "enter":
+		*settingsList[s.selected].text(&s.config.Settings) = s.input
+		s.editing = false
+		s.onChange()
+	case "esc":
+		s.editing = false
+	case "backspace":
+		if r := []rune(s.input); len(r) > 0 {
+			s.input = string(r[:len(r)-1])
+		}
+	case " ":
+		s.input += " "
+	default:
+		if r := []rune(key); len(r) == 1 {
+			s.input += key
+		}
+	}
+
+	return true
+}
+
+// cycle selects the next or previous choice of a setting
+func (s *SettingsModalContent) cycle(current setting, delta int) {
+	if current.choices == nil {
+		return
+	}
+
+	n := len(current.choices)
+	i := (current.index(&s.config.Settings) + delta + n) % n
+
+	current.set(&s.config.Settings, i)
+	s.onChange()
+}
+
+// Content returns the content lines to be displayed
+func (s *SettingsModalContent) Content() []string {
+	var lines []string
+
+	for i, item := range settingsList {
+		var value string
+
+		switch {
+		case item.text != nil && s.editing && i == s.selected:
+			value = s.input + "█"
+		case item.text != nil:
+			value = *item.text(&s.config.Settings)
+			if value == "" {
+				value = "(current directory)"
+			}
+		default:
+			value = "◀ " + item.choices[item.index(&s.config.Settings)] + " ▶"
+		}
+
+		line := fmt.Sprintf("  %-24s %s", item.label, value)
+		if i == s.selected {
+			line = s.selectedStyle.Render(line)
+		}
+
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+
+	hint := "↑/↓: select, ←/→: change, Enter: edit text"
+	if s.editing {
+		hint = "Enter: confirm, Esc: cancel"
+	}
+
+	lines = append(lines,
+		s.hintStyle.Render("  "+hint),
+		s.hintStyle.Render("  Changes are saved to "+s.config.Path()),
+		s.hintStyle.Render("  --wav and --export-dir take precedence over the folders set here"))
+
+	return lines
+}
+
+// Title returns the modal title
+func (s *SettingsModalContent) Title() string {
+	return "SETTINGS"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (s *SettingsModalContent) UpdateInterval() time.Duration {
+	return 0
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (s *SettingsModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (s *SettingsModalContent) Update() {}
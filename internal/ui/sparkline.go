@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+)
+
+// sparkBlocks are the characters used to draw sparklines, from low to high
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a single line of block characters scaled to
+// the largest value. The graph is right-aligned and padded to width.
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+
+	b.WriteString(strings.Repeat(" ", width-len(values)))
+
+	for _, v := range values {
+		i := 0
+		if peak > 0 {
+			i = int(v / peak * float64(len(sparkBlocks)-1))
+		}
+
+		b.WriteRune(sparkBlocks[max(min(i, len(sparkBlocks)-1), 0)])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/capability"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/memory"
+	"github.com/holoplot/rtp-monitor/internal/ring"
+	"github.com/holoplot/rtp-monitor/internal/stats"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+// StatusModalContent implements ModalContentProvider for the memory budget
+// and runtime counters of the monitor itself, and the features unavailable
+// to it
+type StatusModalContent struct {
+	config       *config.Config
+	manager      *stream.Manager
+	collector    *stats.Collector
+	capabilities *capability.Report
+}
+
+// NewStatusModalContent creates a new status content provider. capabilities
+// may be nil if they were not checked.
+func NewStatusModalContent(cfg *config.Config, manager *stream.Manager, collector *stats.Collector, capabilities *capability.Report) *StatusModalContent {
+	return &StatusModalContent{
+		config:       cfg,
+		manager:      manager,
+		collector:    collector,
+		capabilities: capabilities,
+	}
+}
+
+// Init initializes the content provider with dimensions
+func (s *StatusModalContent) Init(width, height int) {}
+
+// Close closes the modal content provider
+func (s *StatusModalContent) Close() {}
+
+// formatBytes formats a number of bytes with a binary unit, e.g. "1.5 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatBitRate formats a bandwidth in bits per second, e.g. "12.3 Mbit/s"
+func formatBitRate(bps float64) string {
+	return fmt.Sprintf("%.1f Mbit/s", bps/1e6)
+}
+
+// Content returns the content lines to be displayed
+func (s *StatusModalContent) Content() []string {
+	l := newLineBuffer(lipgloss.NewStyle())
+
+	if s.capabilities != nil {
+		features := s.capabilities.Features()
+
+		l.p("Features")
+		for i, f := range features {
+			branch := "├─"
+			if i == len(features)-1 {
+				branch = "└─"
+			}
+
+			if f.Err != nil {
+				l.p("  %s %-20s unavailable: %v", branch, f.Name+":", f.Err)
+			} else {
+				l.p("  %s %-20s available", branch, f.Name+":")
+			}
+		}
+
+		l.p("")
+	}
+
+	l.p("Memory budget")
+	l.p("  ├─ Used:                %s of %s", formatBytes(memory.Used()), formatBytes(memory.Limit()))
+
+	accounts := memory.Accounts()
+	for i, a := range accounts {
+		branch := "├─"
+		if i == len(accounts)-1 {
+			branch = "└─"
+		}
+
+		l.p("  %s %-20s %s, %d times exhausted", branch, a.Name+":", formatBytes(a.Bytes), a.Denied)
+	}
+
+	l.p("")
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	l.p("Go runtime")
+	l.p("  ├─ Heap in use:         %s", formatBytes(int64(ms.HeapInuse)))
+	l.p("  ├─ Obtained from OS:    %s", formatBytes(int64(ms.Sys)))
+	l.p("  ├─ Garbage collections: %d", ms.NumGC)
+	l.p("  └─ Goroutines:          %d", runtime.NumGoroutine())
+	l.p("")
+
+	buffers, elements, capacity := ring.Stats()
+
+	l.p("Receivers")
+	l.p("  ├─ Streams:             %d", s.manager.Count())
+	l.p("  ├─ Shared receivers:    %d", s.manager.FeedCount())
+	l.p("  ├─ Multicast consumers: %d", s.manager.ConsumerCount())
+	l.p("  ├─ Dropped packets:     %d", s.manager.QueueDrops())
+	l.p("  ├─ Statistics:          %d streams", s.collector.Count())
+	l.p("  └─ Ring buffers:        %d, %d of %d elements used", buffers, elements, capacity)
+
+	s.bandwidth(l)
+
+	return l.lines()
+}
+
+// bandwidth adds the bandwidth of the groups joined on each interface
+// against the link capacity
+func (s *StatusModalContent) bandwidth(l *lineBuffer) {
+	bandwidth := s.manager.InterfaceBandwidth(time.Now())
+	if len(bandwidth) == 0 {
+		return
+	}
+
+	capacity := s.config.Settings.LinkCapacity()
+
+	l.p("")
+	l.p("Bandwidth (link capacity %d Mbit/s)", s.config.Settings.LinkCapacityMbps)
+
+	for i, b := range bandwidth {
+		branch := "├─"
+		if i == len(bandwidth)-1 {
+			branch = "└─"
+		}
+
+		line := fmt.Sprintf("  %s %-20s %s, %.0f%% of link, %d groups",
+			branch, b.Interface+":", formatBitRate(b.BitsPerSecond), b.BitsPerSecond/capacity*100, b.Groups)
+
+		if b.BitsPerSecond >= capacity*bandwidthWarning {
+			l.p("%s", lipgloss.NewStyle().Foreground(theme.Colors.StatusWarning).Render(line+", approaching capacity"))
+		} else {
+			l.p("%s", line)
+		}
+	}
+}
+
+// Title returns the modal title
+func (s *StatusModalContent) Title() string {
+	return "STATUS"
+}
+
+// UpdateInterval returns how often the modal content should be updated
+func (s *StatusModalContent) UpdateInterval() time.Duration {
+	return time.Second
+}
+
+// AutoScroll returns whether the modal should automatically scroll to the bottom
+func (s *StatusModalContent) AutoScroll() bool {
+	return false
+}
+
+// Update is called periodically if UpdateInterval > 0
+func (s *StatusModalContent) Update() {}
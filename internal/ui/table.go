@@ -1,21 +1,136 @@
 package ui
 
 import (
+	"fmt"
+	"maps"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/stats"
 	"github.com/holoplot/rtp-monitor/internal/stream"
 	"github.com/holoplot/rtp-monitor/internal/theme"
 )
 
+const (
+	// receivingTimeout is the time without packets after which a stream with
+	// a background receiver is no longer considered receiving
+	receivingTimeout = 2 * time.Second
+)
+
+// streamStatus is the state shown in the status column
+type streamStatus int
+
+const (
+	statusIdle streamStatus = iota
+	statusReceiving
+	statusStale
+)
+
 // TableModel represents the table component state
 type TableModel struct {
 	streams       []*stream.Stream
+	rows          []tableRow
 	selectedIndex int
 	viewStart     int
-	height        int
-	width         int
-	styles        TableStyles
+
+	// selectedID and selectedGroup identify the selected row independent of
+	// its position, so the selection follows the stream when rows move
+	selectedID    string
+	selectedGroup string
+
+	height int
+	width  int
+	styles TableStyles
+
+	// grouping selects what streams are grouped by
+	grouping  Grouping
+	collapsed map[string]bool
+
+	// favorites holds the ID hashes of streams pinned to the top
+	favorites map[string]bool
+
+	// expected holds the ID hashes of streams that raise alerts when they
+	// disappear or stop
+	expected      map[string]bool
+	favoritesOnly bool
+
+	// annotations holds the aliases and notes of streams by ID hash
+	annotations map[string]config.Annotation
+
+	// marked holds the IDs of streams selected for batch actions
+	marked map[string]bool
+
+	collector *stats.Collector
+
+	// staleTimeout is the age of the last SAP announcement after which a
+	// stream is considered stale
+	staleTimeout time.Duration
+
+	// miniMeters shows the level column, with peaks at or above
+	// clipThreshold marked as clipping
+	miniMeters    bool
+	clipThreshold float64
+
+	// rowCache holds the rendered rows of the last frame by rowID, so rows
+	// whose content did not change are not styled again. nextRowCache is
+	// filled while rendering and swapped in afterwards, which drops the rows
+	// that scrolled out of view.
+	rowCache     map[string]cachedRow
+	nextRowCache map[string]cachedRow
+}
+
+// cachedRow is a rendered row together with the content it was rendered from
+type cachedRow struct {
+	key      string
+	rendered string
+}
+
+// Grouping is what the rows of the table are grouped by
+type Grouping int
+
+const (
+	GroupingNone Grouping = iota
+	GroupingSender
+	GroupingClock
+
+	groupingCount
+)
+
+// String returns a description of the grouping
+func (g Grouping) String() string {
+	switch g {
+	case GroupingSender:
+		return "by sender"
+	case GroupingClock:
+		return "by clock domain"
+	}
+
+	return "none"
+}
+
+// tableRow is either a stream or, in grouped mode, a group header
+type tableRow struct {
+	stream *stream.Stream
+	group  string
+	count  int
+}
+
+func (r tableRow) isHeader() bool {
+	return r.stream == nil
+}
+
+// rowID identifies a row in the render cache
+func (r tableRow) rowID() string {
+	if r.isHeader() {
+		return "group:" + r.group
+	}
+
+	return r.stream.ID
 }
 
 // TableStyles holds the styling for the table
@@ -24,14 +139,26 @@ type TableStyles struct {
 	Border      lipgloss.Style
 	Row         lipgloss.Style
 	RowSelected lipgloss.Style
+	GroupHeader lipgloss.Style
+	Receiving   lipgloss.Style
+	Idle        lipgloss.Style
+	Stale       lipgloss.Style
 	ScrollBar   lipgloss.Style
 	ScrollThumb lipgloss.Style
 }
 
 // NewTableModel creates a new table model
-func NewTableModel() *TableModel {
+func NewTableModel(collector *stats.Collector) *TableModel {
 	return &TableModel{
 		streams:       []*stream.Stream{},
+		collapsed:     make(map[string]bool),
+		favorites:     make(map[string]bool),
+		expected:      make(map[string]bool),
+		marked:        make(map[string]bool),
+		collector:     collector,
+		staleTimeout:  2 * time.Minute,
+		rowCache:      make(map[string]cachedRow),
+		nextRowCache:  make(map[string]cachedRow),
 		selectedIndex: 0,
 		viewStart:     0,
 		height:        20,
@@ -59,6 +186,20 @@ func createTableStyles() TableStyles {
 			Background(theme.Colors.TableRowSelectedBg).
 			Bold(true).
 			Padding(0, 0),
+		GroupHeader: lipgloss.NewStyle().
+			Foreground(theme.Colors.Secondary).
+			Background(theme.Colors.Background).
+			Bold(true).
+			Padding(0, 0),
+		Receiving: lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusActive).
+			Background(theme.Colors.Background),
+		Idle: lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusInactive).
+			Background(theme.Colors.Background),
+		Stale: lipgloss.NewStyle().
+			Foreground(theme.Colors.StatusError).
+			Background(theme.Colors.Background),
 		ScrollBar: lipgloss.NewStyle().
 			Foreground(theme.Colors.ScrollBar),
 		ScrollThumb: lipgloss.NewStyle().
@@ -69,40 +210,322 @@ func createTableStyles() TableStyles {
 
 // SetStreams updates the streams displayed in the table
 // When new streams are discovered:
-// - The currently selected stream remains selected if it still exists
-// - The selection remains visible with respect to the scrolled table view
-// - If the selected stream disappears, the first stream in the list is selected
+//   - The currently selected stream remains selected if it still exists
+//   - The selection remains visible with respect to the scrolled table view
+//   - If the selected stream disappears, the row at the same position is selected
+//   - A stream hidden by a filter or a collapsed group is selected again once it
+//     becomes visible, unless the selection was moved in the meantime
 func (t *TableModel) SetStreams(streams []*stream.Stream) {
-	var currentlySelectedID string
+	t.streams = streams
 
-	// Remember the currently selected stream ID
-	if t.selectedIndex >= 0 && t.selectedIndex < len(t.streams) {
-		currentlySelectedID = t.streams[t.selectedIndex].ID
+	// Forget marks of streams that went away
+	ids := make(map[string]bool, len(streams))
+	for _, s := range streams {
+		ids[s.ID] = true
 	}
 
-	t.streams = streams
+	for id := range t.marked {
+		if !ids[id] {
+			delete(t.marked, id)
+		}
+	}
+
+	t.rebuildRows()
+}
+
+// rebuildRows recreates the visible rows from the stream list and the
+// grouping state, keeping the current selection where possible
+func (t *TableModel) rebuildRows() {
+	previousIndex := t.selectedIndex
+
+	t.rows = t.rows[:0]
+
+	streams := t.visibleStreams()
+
+	if t.grouping != GroupingNone {
+		groups := make(map[string][]*stream.Stream)
+		var labels []string
+
+		for _, s := range streams {
+			label := t.groupLabel(s)
+			if _, ok := groups[label]; !ok {
+				labels = append(labels, label)
+			}
+			groups[label] = append(groups[label], s)
+		}
+
+		sort.Strings(labels)
+
+		for _, label := range labels {
+			t.rows = append(t.rows, tableRow{group: label, count: len(groups[label])})
+
+			if t.collapsed[label] {
+				continue
+			}
+
+			for _, s := range groups[label] {
+				t.rows = append(t.rows, tableRow{stream: s, group: label})
+			}
+		}
+	} else {
+		for _, s := range streams {
+			t.rows = append(t.rows, tableRow{stream: s})
+		}
+	}
+
+	if i, ok := t.findSelection(); ok {
+		t.selectedIndex = i
+		t.adjustView()
+		return
+	}
+
+	// The selected row is not visible, stay at the same position
+	t.selectedIndex = max(min(previousIndex, len(t.rows)-1), 0)
+
+	// Only give up on the remembered stream if it is gone for good, so it is
+	// selected again when a filter is lifted or its group is expanded
+	if !t.hasStream(t.selectedID) {
+		t.rememberSelection()
+	}
+
+	t.adjustView()
+}
 
-	// Try to maintain the current selection by finding the same stream ID
-	if currentlySelectedID != "" {
-		for i, stream := range streams {
-			if stream.ID == currentlySelectedID {
-				t.selectedIndex = i
-				t.adjustView() // Keep selection visible in scrolled view
-				return
+// findSelection returns the index of the row showing the selected stream,
+// falling back to the header of the group the selection was in
+func (t *TableModel) findSelection() (int, bool) {
+	if t.selectedID != "" {
+		for i, row := range t.rows {
+			if !row.isHeader() && row.stream.ID == t.selectedID {
+				return i, true
 			}
 		}
 	}
 
-	// If the previously selected stream is not found, select the first stream
-	if len(streams) > 0 {
-		t.selectedIndex = 0
+	if t.selectedGroup != "" {
+		for i, row := range t.rows {
+			if row.isHeader() && row.group == t.selectedGroup {
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// hasStream returns whether a stream with the given ID exists, whether it
+// is visible or not
+func (t *TableModel) hasStream(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, s := range t.streams {
+		if s.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rememberSelection records the identity of the row at the selected index
+func (t *TableModel) rememberSelection() {
+	t.selectedID = ""
+	t.selectedGroup = ""
+
+	if t.selectedIndex < 0 || t.selectedIndex >= len(t.rows) {
+		return
+	}
+
+	row := t.rows[t.selectedIndex]
+	if row.isHeader() {
+		t.selectedGroup = row.group
 	} else {
-		t.selectedIndex = 0
+		t.selectedID = row.stream.ID
+		t.selectedGroup = t.groupLabel(row.stream)
 	}
+}
 
+// selectIndex selects the row at the given index and keeps it visible
+func (t *TableModel) selectIndex(i int) {
+	t.selectedIndex = i
+	t.rememberSelection()
 	t.adjustView()
 }
 
+// visibleStreams applies the favorites filter and moves favorites to the
+// front, keeping the order of the stream list otherwise
+func (t *TableModel) visibleStreams() []*stream.Stream {
+	var favorites, others []*stream.Stream
+
+	for _, s := range t.streams {
+		if t.favorites[s.IDHash()] {
+			favorites = append(favorites, s)
+		} else if !t.favoritesOnly {
+			others = append(others, s)
+		}
+	}
+
+	return append(favorites, others...)
+}
+
+// SetFavorites sets the ID hashes of the streams to pin to the top
+func (t *TableModel) SetFavorites(idHashes []string) {
+	t.favorites = make(map[string]bool, len(idHashes))
+	for _, h := range idHashes {
+		t.favorites[h] = true
+	}
+
+	t.rebuildRows()
+}
+
+// SetExpected sets the ID hashes of the streams marked as expected
+func (t *TableModel) SetExpected(idHashes []string) {
+	t.expected = make(map[string]bool, len(idHashes))
+	for _, h := range idHashes {
+		t.expected[h] = true
+	}
+}
+
+// SetAnnotations sets the aliases and notes shown in the name column
+func (t *TableModel) SetAnnotations(annotations map[string]config.Annotation) {
+	t.annotations = maps.Clone(annotations)
+}
+
+// displayName returns the alias of a stream if it has one, its name
+// otherwise, followed by its note
+func (t *TableModel) displayName(s *stream.Stream) string {
+	a := t.annotations[s.IDHash()]
+
+	name := s.Name()
+	if a.Alias != "" {
+		name = a.Alias
+	}
+
+	if a.Note != "" {
+		name += " – " + a.Note
+	}
+
+	return name
+}
+
+// ToggleFavoritesOnly switches between showing all streams and favorites only
+func (t *TableModel) ToggleFavoritesOnly() {
+	t.favoritesOnly = !t.favoritesOnly
+	t.rebuildRows()
+}
+
+// FavoritesOnly returns whether only favorites are shown
+func (t *TableModel) FavoritesOnly() bool {
+	return t.favoritesOnly
+}
+
+// VisibleCount returns the number of streams shown after filtering
+func (t *TableModel) VisibleCount() int {
+	n := 0
+	for _, row := range t.rows {
+		if !row.isHeader() {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ToggleMark marks or unmarks the selected stream
+func (t *TableModel) ToggleMark() {
+	s := t.GetSelected()
+	if s == nil {
+		return
+	}
+
+	if t.marked[s.ID] {
+		delete(t.marked, s.ID)
+	} else {
+		t.marked[s.ID] = true
+	}
+}
+
+// ToggleMarkAll marks all visible streams, or clears all marks if any are set
+func (t *TableModel) ToggleMarkAll() {
+	if len(t.marked) > 0 {
+		t.marked = make(map[string]bool)
+		return
+	}
+
+	for _, row := range t.rows {
+		if !row.isHeader() {
+			t.marked[row.stream.ID] = true
+		}
+	}
+}
+
+// Marked returns the marked streams in table order
+func (t *TableModel) Marked() []*stream.Stream {
+	var marked []*stream.Stream
+
+	for _, s := range t.visibleStreams() {
+		if t.marked[s.ID] {
+			marked = append(marked, s)
+		}
+	}
+
+	return marked
+}
+
+// CycleGrouping switches from the flat list to grouping by sender, then by
+// clock domain and back, and returns the new grouping
+func (t *TableModel) CycleGrouping() Grouping {
+	t.grouping = (t.grouping + 1) % groupingCount
+	t.rebuildRows()
+
+	return t.grouping
+}
+
+// groupLabel returns the label of the group a stream belongs to
+func (t *TableModel) groupLabel(s *stream.Stream) string {
+	if t.grouping == GroupingClock {
+		return s.ClockLabel()
+	}
+
+	return s.SenderLabel()
+}
+
+// ToggleCollapse collapses or expands the group of the selected row
+func (t *TableModel) ToggleCollapse() {
+	if t.grouping == GroupingNone || t.selectedIndex < 0 || t.selectedIndex >= len(t.rows) {
+		return
+	}
+
+	row := t.rows[t.selectedIndex]
+	t.collapsed[row.group] = !t.collapsed[row.group]
+
+	// Move the selection onto the header so it does not vanish with the group
+	for i, r := range t.rows {
+		if r.isHeader() && r.group == row.group {
+			t.selectedIndex = i
+			t.rememberSelection()
+			break
+		}
+	}
+
+	t.rebuildRows()
+}
+
+// SetStaleTimeout sets the age of the last SAP announcement after which a
+// stream is shown as stale
+func (t *TableModel) SetStaleTimeout(d time.Duration) {
+	t.staleTimeout = d
+}
+
+// SetMiniMeters shows or hides the level column. Peaks at or above the clip
+// threshold in dBFS are marked as clipping.
+func (t *TableModel) SetMiniMeters(enabled bool, clipThreshold float64) {
+	t.miniMeters = enabled
+	t.clipThreshold = clipThreshold
+}
+
 // SetSize sets the dimensions of the table
 func (t *TableModel) SetSize(width, height int) {
 	t.width = width
@@ -113,30 +536,89 @@ func (t *TableModel) SetSize(width, height int) {
 // MoveUp moves the selection up
 func (t *TableModel) MoveUp() {
 	if t.selectedIndex > 0 {
-		t.selectedIndex--
-		t.adjustView()
+		t.selectIndex(t.selectedIndex - 1)
 	}
 }
 
 // MoveDown moves the selection down
 func (t *TableModel) MoveDown() {
-	if t.selectedIndex < len(t.streams)-1 {
-		t.selectedIndex++
-		t.adjustView()
+	if t.selectedIndex < len(t.rows)-1 {
+		t.selectIndex(t.selectedIndex + 1)
+	}
+}
+
+// MoveToTop selects the first row
+func (t *TableModel) MoveToTop() {
+	t.selectIndex(0)
+}
+
+// MoveToBottom selects the last row
+func (t *TableModel) MoveToBottom() {
+	if len(t.rows) > 0 {
+		t.selectIndex(len(t.rows) - 1)
+	}
+}
+
+// PageUp moves the selection up by one page
+func (t *TableModel) PageUp() {
+	visibleRows := t.height - 3
+	t.selectIndex(max(t.selectedIndex-visibleRows, 0))
+}
+
+// PageDown moves the selection down by one page
+func (t *TableModel) PageDown() {
+	visibleRows := t.height - 3
+	t.selectIndex(max(min(t.selectedIndex+visibleRows, len(t.rows)-1), 0))
+}
+
+// JumpTo selects the next stream after the selection whose name starts with
+// the given letter, ignoring case and wrapping around. It returns false if
+// there is none.
+func (t *TableModel) JumpTo(letter rune) bool {
+	letter = unicode.ToLower(letter)
+
+	for n := 1; n <= len(t.rows); n++ {
+		i := (t.selectedIndex + n) % len(t.rows)
+
+		row := t.rows[i]
+		if row.isHeader() {
+			continue
+		}
+
+		if first, _ := utf8.DecodeRuneInString(t.displayName(row.stream)); unicode.ToLower(first) == letter {
+			t.selectIndex(i)
+			return true
+		}
 	}
+
+	return false
+}
+
+// Page returns the range of rows in view, counted from 1, and the number of
+// rows. first is 0 if there are no rows.
+func (t *TableModel) Page() (first, last, total int) {
+	total = len(t.rows)
+	if total == 0 {
+		return 0, 0, 0
+	}
+
+	visibleRows := max(t.height-1, 1)
+
+	return t.viewStart + 1, min(t.viewStart+visibleRows, total), total
 }
 
-// GetSelected returns the currently selected stream
+// GetSelected returns the currently selected stream, or nil if nothing or a
+// group header is selected
 func (t *TableModel) GetSelected() *stream.Stream {
-	if t.selectedIndex >= 0 && t.selectedIndex < len(t.streams) {
-		return t.streams[t.selectedIndex]
+	if t.selectedIndex >= 0 && t.selectedIndex < len(t.rows) {
+		return t.rows[t.selectedIndex].stream
 	}
 	return nil
 }
 
 // adjustView ensures the selected item is visible
 func (t *TableModel) adjustView() {
-	if len(t.streams) == 0 {
+	if len(t.rows) == 0 {
 		return
 	}
 
@@ -152,7 +634,7 @@ func (t *TableModel) adjustView() {
 	}
 
 	// Ensure view doesn't go beyond bounds
-	maxViewStart := max(len(t.streams)-visibleRows, 0)
+	maxViewStart := max(len(t.rows)-visibleRows, 0)
 	if t.viewStart > maxViewStart {
 		t.viewStart = maxViewStart
 	}
@@ -163,7 +645,7 @@ func (t *TableModel) adjustView() {
 
 // Render renders the table as a string
 func (t *TableModel) Render() string {
-	if len(t.streams) == 0 {
+	if len(t.rows) == 0 {
 		return t.renderEmpty()
 	}
 
@@ -188,17 +670,22 @@ func (t *TableModel) renderScrollableContent() string {
 	visibleRows := max(t.height-1, 1)
 
 	// Render actual stream rows first
-	endIndex := min(t.viewStart+visibleRows, len(t.streams))
+	endIndex := min(t.viewStart+visibleRows, len(t.rows))
+	widths := t.calculateColumnWidths()
+	now := time.Now()
 
 	rowsRendered := 0
 	for i := t.viewStart; i < endIndex; i++ {
 		if rowsRendered > 0 {
 			b.WriteString("\n")
 		}
-		b.WriteString(t.renderRow(i))
+		b.WriteString(t.renderCachedRow(i, widths, now))
 		rowsRendered++
 	}
 
+	t.rowCache, t.nextRowCache = t.nextRowCache, t.rowCache
+	clear(t.nextRowCache)
+
 	// Fill remaining space with empty rows to push footer to bottom
 	for rowsRendered < visibleRows {
 		b.WriteString("\n")
@@ -207,7 +694,7 @@ func (t *TableModel) renderScrollableContent() string {
 	}
 
 	// Add scrollbar if needed (only to scrollable content)
-	if len(t.streams) > visibleRows {
+	if len(t.rows) > visibleRows {
 		result := t.addScrollbar(b.String(), visibleRows)
 		return result
 	}
@@ -218,6 +705,9 @@ func (t *TableModel) renderScrollableContent() string {
 // renderEmpty renders an empty table message
 func (t *TableModel) renderEmpty() string {
 	message := "No RTP streams detected"
+	if t.favoritesOnly && len(t.streams) > 0 {
+		message = "No favorite streams detected"
+	}
 
 	return t.styles.Row.
 		Width(t.width).
@@ -226,67 +716,105 @@ func (t *TableModel) renderEmpty() string {
 		Render(message)
 }
 
-// calculateColumnWidths calculates optimal column widths for the table
+// tableColumns lists the headers of all table columns
+var tableColumns = []string{"ID", "Name", "Address", "Codec", "Discovery", "Stats", "Level", "Status"}
+
+// narrow returns whether the table is too narrow to show all columns
+func (t *TableModel) narrow() bool {
+	return t.width < narrowWidth
+}
+
+// rowWidth returns the width of a table row, without the scrollbar
+func (t *TableModel) rowWidth() int {
+	if t.narrow() {
+		return max(t.width-2, 0)
+	}
+
+	// Minimum usable width
+	return max(t.width-2, 60)
+}
+
+// calculateColumnWidths calculates optimal column widths for the table.
+// Columns that are hidden have a width of 0.
 func (t *TableModel) calculateColumnWidths() []int {
 	// Always reserve space for scrollbar to prevent layout shifts
-	availableWidth := max(
-		// Reserve 2 spaces for scrollbar
-		t.width-2,
-		// Minimum usable width
-		60)
+	availableWidth := t.rowWidth()
+
+	// The level column is optional and of fixed width
+	levelWidth := 0
+	if t.miniMeters {
+		levelWidth = miniMeterWidth + 1
+		availableWidth -= levelWidth
+	}
+
+	if t.narrow() {
+		// Only name, address, level and status: 35%, 40% and the rest
+		nameWidth := (availableWidth * 35) / 100
+		addressWidth := (availableWidth * 40) / 100
+		statusWidth := availableWidth - nameWidth - addressWidth
+
+		return []int{0, nameWidth, addressWidth, 0, 0, 0, levelWidth, statusWidth}
+	}
 
 	// Distribute width proportionally to accommodate primary/secondary IPs
-	// ID: 10%, Name: 25%, Address: 35%, Codec: 15%, Discovery: 15%
-	idWidth := (availableWidth * 10) / 100
-	nameWidth := (availableWidth * 25) / 100
-	addressWidth := (availableWidth * 35) / 100
-	codecWidth := (availableWidth * 15) / 100
-	discoveryWidth := (availableWidth * 15) / 100
+	// ID: 11%, Name: 20%, Address: 25%, Codec: 12%, Discovery: 11%, Stats: 9%, Status: 12%
+	idWidth := (availableWidth * 11) / 100
+	nameWidth := (availableWidth * 20) / 100
+	addressWidth := (availableWidth * 25) / 100
+	codecWidth := (availableWidth * 12) / 100
+	discoveryWidth := (availableWidth * 11) / 100
+	statsWidth := (availableWidth * 9) / 100
+	statusWidth := (availableWidth * 12) / 100
 
 	// Ensure minimum widths
 	if idWidth < 10 {
 		idWidth = 10
 	}
 
-	if nameWidth < 15 {
-		nameWidth = 15
+	if nameWidth < 10 {
+		nameWidth = 10
 	}
-	if addressWidth < 25 {
-		addressWidth = 25
+	if addressWidth < 16 {
+		addressWidth = 16
 	}
 	if codecWidth < 10 {
 		codecWidth = 10
 	}
-	if discoveryWidth < 12 {
-		discoveryWidth = 12
+	if discoveryWidth < 8 {
+		discoveryWidth = 8
+	}
+	if statsWidth < 8 {
+		statsWidth = 8
+	}
+	if statusWidth < 13 {
+		statusWidth = 13
 	}
 
-	return []int{idWidth, nameWidth, addressWidth, codecWidth, discoveryWidth}
+	return []int{idWidth, nameWidth, addressWidth, codecWidth, discoveryWidth, statsWidth, levelWidth, statusWidth}
 }
 
 // renderHeader renders the table header
 func (t *TableModel) renderHeader() string {
-	headers := []string{"ID", "Name", "Address", "Codec", "Discovery"}
 	widths := t.calculateColumnWidths()
 
 	var headerParts []string
-	for i, header := range headers {
-		if i < len(widths) {
-			cellContent := truncateString(header, widths[i])
-			headerParts = append(headerParts, t.styles.Header.
-				Width(widths[i]).
-				Height(1).
-				Align(lipgloss.Left).
-				Render(cellContent))
+	for i, header := range tableColumns {
+		if widths[i] == 0 {
+			continue
 		}
+
+		cellContent := truncateString(header, widths[i])
+		headerParts = append(headerParts, t.styles.Header.
+			Width(widths[i]).
+			Height(1).
+			Align(lipgloss.Left).
+			Render(cellContent))
 	}
 
 	headerLine := lipgloss.JoinHorizontal(lipgloss.Top, headerParts...)
 
 	// Ensure we don't exceed actual terminal width
-	targetWidth := max(
-		// Always reserve space for scrollbar
-		t.width-2, 60)
+	targetWidth := t.rowWidth()
 
 	// Ensure the header uses correct width
 	headerWidth := lipgloss.Width(headerLine)
@@ -299,23 +827,121 @@ func (t *TableModel) renderHeader() string {
 	return headerLine
 }
 
-// renderRow renders a single table row
-func (t *TableModel) renderRow(index int) string {
-	stream := t.streams[index]
-	widths := t.calculateColumnWidths()
+// renderCachedRow renders a row, or returns it from the last frame if its
+// content and selection did not change. The key holds the plain text of the
+// cells, which are padded to the column widths, so resizing the table
+// renders the rows again.
+func (t *TableModel) renderCachedRow(index int, widths []int, now time.Time) string {
+	row := t.rows[index]
+	selected := index == t.selectedIndex
+	id := row.rowID()
+
+	var (
+		text   string
+		cells  []string
+		status streamStatus
+		key    string
+	)
+
+	if row.isHeader() {
+		text = t.groupHeaderText(row)
+		key = fmt.Sprintf("%v\x1f%s", selected, text)
+	} else {
+		cells, status = t.rowCells(row.stream, widths, now)
+		key = fmt.Sprintf("%v\x1f%d\x1f%d\x1f%s", selected, status, t.rowWidth(), strings.Join(cells, "\x1f"))
+	}
+
+	cached, ok := t.rowCache[id]
+	if !ok || cached.key != key {
+		cached.key = key
 
+		if row.isHeader() {
+			cached.rendered = t.renderGroupHeader(text, selected)
+		} else {
+			cached.rendered = t.renderRow(cells, status, widths, selected)
+		}
+	}
+
+	t.nextRowCache[id] = cached
+
+	return cached.rendered
+}
+
+// groupHeaderText returns the text of the header row of a sender group,
+// padded to the row width
+func (t *TableModel) groupHeaderText(row tableRow) string {
+	marker := "▼"
+	if t.collapsed[row.group] {
+		marker = "▶"
+	}
+
+	noun := "streams"
+	if row.count == 1 {
+		noun = "stream"
+	}
+
+	return truncateString(fmt.Sprintf("%s %s (%d %s)", marker, row.group, row.count, noun), t.rowWidth())
+}
+
+// renderGroupHeader renders the header row of a sender group
+func (t *TableModel) renderGroupHeader(text string, selected bool) string {
+	style := t.styles.GroupHeader
+	if selected {
+		style = t.styles.RowSelected
+	}
+
+	return style.Width(t.rowWidth()).Height(1).Render(text)
+}
+
+// rowCells returns the cells of the row of a stream, truncated and padded
+// to the column widths, and the status of the stream
+func (t *TableModel) rowCells(stream *stream.Stream, widths []int, now time.Time) ([]string, streamStatus) {
 	// Prepare row data
+	indent := ""
+	if t.grouping != GroupingNone {
+		indent = "  "
+	}
+
+	if t.marked[stream.ID] {
+		indent += "● "
+	}
+
+	if t.favorites[stream.IDHash()] {
+		indent += "★ "
+	}
+
+	if t.expected[stream.IDHash()] {
+		indent += "! "
+	}
+
+	// Without the ID column, markers go in front of the name
+	id, name := indent+stream.IDHash(), t.displayName(stream)
+	if widths[0] == 0 {
+		id, name = "", indent+name
+	}
+
 	rowData := []string{
-		truncateString(stream.IDHash(), widths[0]),
-		truncateString(stream.Name(), widths[1]),
+		truncateString(id, widths[0]),
+		truncateString(name, widths[1]),
 		truncateString(stream.Address(), widths[2]),
 		truncateString(stream.CodecInfo(), widths[3]),
 		truncateString(stream.DiscoveryLabel(), widths[4]),
+		truncateString(t.statsLabel(stream), widths[5]),
+		// The meters are multi-byte and always fit the column
+		t.levelLabel(stream, now),
 	}
 
+	status, statusText := t.status(stream, now)
+	rowData = append(rowData, truncateString(statusText, widths[7]))
+
+	return rowData, status
+}
+
+// renderRow styles the cells of a stream row
+func (t *TableModel) renderRow(rowData []string, status streamStatus, widths []int, selected bool) string {
 	// Choose style based on selection and alternating rows
 	var style lipgloss.Style
-	if index == t.selectedIndex {
+	if selected {
 		style = t.styles.RowSelected
 	} else {
 		style = t.styles.Row
@@ -323,16 +949,23 @@ func (t *TableModel) renderRow(index int) string {
 
 	var rowParts []string
 	for i, data := range rowData {
-		cellStyle := style.Width(widths[i]).Height(1).Align(lipgloss.Left)
+		if widths[i] == 0 {
+			continue
+		}
+
+		cellStyle := style
+		if i == len(rowData)-1 && !selected {
+			cellStyle = t.statusStyle(status)
+		}
+
+		cellStyle = cellStyle.Width(widths[i]).Height(1).Align(lipgloss.Left)
 		rowParts = append(rowParts, cellStyle.Render(data))
 	}
 
 	rowLine := lipgloss.JoinHorizontal(lipgloss.Top, rowParts...)
 
 	// Ensure we don't exceed actual terminal width
-	targetWidth := max(
-		// Always reserve space for scrollbar
-		t.width-2, 60)
+	targetWidth := t.rowWidth()
 
 	// Ensure the row uses correct width
 	rowWidth := lipgloss.Width(rowLine)
@@ -345,6 +978,98 @@ func (t *TableModel) renderRow(index int) string {
 	return rowLine
 }
 
+// statsLabel returns a short summary of the background statistics of a
+// stream, or "-" if none are collected
+func (t *TableModel) statsLabel(s *stream.Stream) string {
+	snapshot, ok := t.collector.Snapshot(s.ID)
+	if !ok {
+		return "-"
+	}
+
+	label := fmt.Sprintf("%.0f/s", snapshot.PacketRate())
+	if n := snapshot.SequenceErrors(); n > 0 {
+		label += fmt.Sprintf(" !%d", n)
+	}
+
+	return label
+}
+
+// levelLabel returns the mini meter of the peak level of a stream, or "-" if
+// its levels are not measured or no packets arrive
+func (t *TableModel) levelLabel(s *stream.Stream, now time.Time) string {
+	if !t.miniMeters {
+		return ""
+	}
+
+	snapshot, ok := t.collector.Snapshot(s.ID)
+	if !ok || !snapshot.Levels || now.Sub(snapshot.LastPacket()) > receivingTimeout {
+		return "-"
+	}
+
+	return miniMeter(snapshot.Peak(), t.clipThreshold)
+}
+
+// status returns the state of a stream and a label with the relative time it
+// was last seen. Packets seen by a background receiver take precedence over
+// SAP announcements, and an RTCP BYE of all its senders over both.
+func (t *TableModel) status(s *stream.Stream, now time.Time) (streamStatus, string) {
+	announced := s.LastAnnounced()
+	lastSeen := announced
+
+	if snapshot, ok := t.collector.Snapshot(s.ID); ok {
+		if ended := snapshot.Ended(); !ended.IsZero() {
+			return statusIdle, "ended " + formatAgo(now.Sub(ended))
+		}
+
+		lastPacket := snapshot.LastPacket()
+		if !lastPacket.IsZero() && now.Sub(lastPacket) <= receivingTimeout {
+			return statusReceiving, "receiving"
+		}
+
+		if lastPacket.After(lastSeen) {
+			lastSeen = lastPacket
+		}
+	}
+
+	status, label := statusIdle, "idle"
+
+	if !announced.IsZero() && now.Sub(announced) > t.staleTimeout {
+		status, label = statusStale, "stale"
+	}
+
+	if lastSeen.IsZero() {
+		return status, label
+	}
+
+	return status, label + " " + formatAgo(now.Sub(lastSeen))
+}
+
+// statusStyle returns the cell style for a stream status
+func (t *TableModel) statusStyle(status streamStatus) lipgloss.Style {
+	switch status {
+	case statusReceiving:
+		return t.styles.Receiving
+	case statusStale:
+		return t.styles.Stale
+	default:
+		return t.styles.Idle
+	}
+}
+
+// formatAgo formats a duration as a short relative time, e.g. "3s ago"
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+}
+
 // addScrollbar adds a scrollbar to the rendered content
 func (t *TableModel) addScrollbar(content string, visibleRows int) string {
 	lines := strings.Split(content, "\n")
@@ -352,7 +1077,7 @@ func (t *TableModel) addScrollbar(content string, visibleRows int) string {
 		return content
 	}
 
-	totalStreams := len(t.streams)
+	totalStreams := len(t.rows)
 	if totalStreams <= visibleRows {
 		return content // No scrollbar needed
 	}
@@ -421,11 +1146,12 @@ func truncateString(s string, width int) string {
 
 // renderEmptyRow renders an empty row with proper width
 func (t *TableModel) renderEmptyRow() string {
-	targetWidth := t.width - 2 // Always reserve space for scrollbar
+	targetWidth := max(t.width-2, 0) // Always reserve space for scrollbar
 	return strings.Repeat(" ", targetWidth)
 }
 
-// RefreshStyles updates the table styles
+// RefreshStyles updates the table styles and renders all rows again
 func (t *TableModel) RefreshStyles() {
 	t.styles = createTableStyles()
+	clear(t.rowCache)
 }
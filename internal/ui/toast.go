@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/holoplot/rtp-monitor/internal/theme"
+)
+
+const (
+	// toastDuration is how long a notification stays visible
+	toastDuration = 5 * time.Second
+
+	// maxToasts is the number of notifications shown at once
+	maxToasts = 5
+
+	// toastWidth is the width of a notification, including its border
+	toastWidth = 48
+)
+
+// toastLevel is the severity of a notification
+type toastLevel int
+
+const (
+	toastInfo toastLevel = iota
+	toastWarning
+	toastError
+)
+
+type toast struct {
+	level   toastLevel
+	text    string
+	expires time.Time
+}
+
+// Toasts holds transient notifications shown in the bottom right corner
+type Toasts struct {
+	items  []toast
+	styles map[toastLevel]lipgloss.Style
+}
+
+// NewToasts creates an empty notification area
+func NewToasts() *Toasts {
+	return &Toasts{
+		styles: createToastStyles(),
+	}
+}
+
+// createToastStyles creates the notification styles using the current theme
+func createToastStyles() map[toastLevel]lipgloss.Style {
+	style := lipgloss.NewStyle().
+		Background(theme.Colors.Background).
+		Border(lipgloss.RoundedBorder()).
+		Padding(0, 1).
+		Width(toastWidth - 2)
+
+	return map[toastLevel]lipgloss.Style{
+		toastInfo: style.
+			BorderForeground(theme.Colors.Primary).
+			Foreground(theme.Colors.Foreground),
+		toastWarning: style.
+			BorderForeground(theme.Colors.StatusWarning).
+			Foreground(theme.Colors.StatusWarning),
+		toastError: style.
+			BorderForeground(theme.Colors.StatusError).
+			Foreground(theme.Colors.StatusError),
+	}
+}
+
+// RefreshStyles updates the notification styles
+func (t *Toasts) RefreshStyles() {
+	t.styles = createToastStyles()
+}
+
+// Add shows a new notification. The oldest one is dropped if too many are shown.
+func (t *Toasts) Add(level toastLevel, format string, args ...any) {
+	t.items = append(t.items, toast{
+		level:   level,
+		text:    fmt.Sprintf(format, args...),
+		expires: time.Now().Add(toastDuration),
+	})
+
+	if len(t.items) > maxToasts {
+		t.items = t.items[len(t.items)-maxToasts:]
+	}
+}
+
+// Prune removes expired notifications
+func (t *Toasts) Prune(now time.Time) {
+	items := t.items[:0]
+
+	for _, item := range t.items {
+		if now.Before(item.expires) {
+			items = append(items, item)
+		}
+	}
+
+	t.items = items
+}
+
+// Render renders all notifications stacked on top of each other, newest at
+// the bottom, or an empty string if there are none
+func (t *Toasts) Render() string {
+	if len(t.items) == 0 {
+		return ""
+	}
+
+	boxes := make([]string, len(t.items))
+	for i, item := range t.items {
+		boxes[i] = t.styles[item.level].Render(truncateString(item.text, toastWidth-4))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Right, boxes...)
+}
@@ -0,0 +1,299 @@
+// Package webhook posts notifications about alerts and other events to HTTP
+// endpoints, e.g. chat services such as Slack or Microsoft Teams.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/inventory"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+// httpTimeout is how long posting to a webhook may take
+const httpTimeout = 10 * time.Second
+
+// defaultEvents are the event types posted unless configured otherwise
+var defaultEvents = []string{events.TypeAlert, events.TypeStreamRemoved}
+
+// templates are the bodies of the built-in formats
+var templates = map[string]string{
+	"slack": `{"text": {{json .Text}}}`,
+	"teams": `{
+  "@type": "MessageCard",
+  "@context": "https://schema.org/extensions",
+  "themeColor": "{{if eq .Level "error"}}D7263D{{else if eq .Level "warning"}}F4A259{{else}}3A86FF{{end}}",
+  "summary": {{json .Text}},
+  "title": "rtp-monitor: {{.Type}}",
+  "text": {{json .Text}}
+}`,
+}
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Notification is posted to webhooks as JSON, or rendered with their
+// template
+type Notification struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Text  string    `json:"text"`
+
+	// Metric and Value are the offending metric of an alert
+	Metric string  `json:"metric,omitempty"`
+	Value  float64 `json:"value,omitempty"`
+
+	// Stream is the stream the event is about, if any
+	Stream *inventory.Entry `json:"stream,omitempty"`
+}
+
+// hook is a configured webhook
+type hook struct {
+	config.Webhook
+	template *template.Template // nil to post JSON
+}
+
+// Options configures a Notifier
+type Options struct {
+	Watcher  *events.Watcher
+	Manager  *stream.Manager
+	Webhooks []config.Webhook
+}
+
+// Notifier posts events to webhooks
+type Notifier struct {
+	watcher *events.Watcher
+	manager *stream.Manager
+	hooks   []hook
+	client  *http.Client
+	done    chan struct{}
+}
+
+// New creates a notifier for the given webhooks and checks their
+// configuration
+func New(opts Options) (*Notifier, error) {
+	n := &Notifier{
+		watcher: opts.Watcher,
+		manager: opts.Manager,
+		client:  &http.Client{Timeout: httpTimeout},
+		done:    make(chan struct{}),
+	}
+
+	for i, w := range opts.Webhooks {
+		h, err := newHook(w)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %d: %w", i+1, err)
+		}
+
+		n.hooks = append(n.hooks, h)
+	}
+
+	return n, nil
+}
+
+func newHook(w config.Webhook) (hook, error) {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return hook{}, fmt.Errorf("invalid URL %q", w.URL)
+	}
+
+	if len(w.Events) == 0 {
+		w.Events = defaultEvents
+	}
+
+	for _, typ := range w.Events {
+		if typ == events.TypeStats || !slices.Contains(events.Types, typ) {
+			return hook{}, fmt.Errorf("unsupported event type %q", typ)
+		}
+	}
+
+	h := hook{Webhook: w}
+
+	if w.Template != "" {
+		text, ok := templates[w.Template]
+		if !ok {
+			text = w.Template
+		}
+
+		h.template, err = template.New("body").Funcs(funcs).Parse(text)
+		if err != nil {
+			return hook{}, fmt.Errorf("invalid template: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// Start posts events in the background until Stop is called. Errors are
+// reported to errorFn.
+func (n *Notifier) Start(errorFn func(error)) {
+	ch := n.watcher.Subscribe()
+
+	go func() {
+		defer n.watcher.Unsubscribe(ch)
+
+		for {
+			select {
+			case <-n.done:
+				return
+			case event := <-ch:
+				for _, err := range n.notify(event) {
+					errorFn(err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops posting events
+func (n *Notifier) Stop() {
+	close(n.done)
+}
+
+// notify posts an event to the webhooks configured for it and returns the
+// errors that occurred
+func (n *Notifier) notify(event events.Event) []error {
+	var (
+		errs         []error
+		notification *Notification
+	)
+
+	for _, h := range n.hooks {
+		if !slices.Contains(h.Events, event.Type) {
+			continue
+		}
+
+		if notification == nil {
+			notification = n.notification(event)
+		}
+
+		if err := n.post(h, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// lookup returns the entry of the stream with the given ID hash, or nil
+func (n *Notifier) lookup(idHash string) *inventory.Entry {
+	for _, s := range n.manager.GetAllStreams() {
+		if s.IDHash() == idHash {
+			return &inventory.FromStreams([]*stream.Stream{s})[0]
+		}
+	}
+
+	return nil
+}
+
+// address returns the destinations of the sources of a stream
+func address(e *inventory.Entry) string {
+	a := make([]string, len(e.Sources))
+
+	for i, source := range e.Sources {
+		a[i] = fmt.Sprintf("%s:%d", source.Destination, source.Port)
+	}
+
+	return strings.Join(a, ", ")
+}
+
+// notification describes an event
+func (n *Notifier) notification(event events.Event) *Notification {
+	result := &Notification{
+		Type:  event.Type,
+		Time:  event.Time,
+		Level: "info",
+	}
+
+	switch data := event.Data.(type) {
+	case events.Alert:
+		result.Level = data.Level
+		result.Text = data.Message
+		result.Metric = data.Metric
+		result.Value = data.Value
+		result.Stream = n.lookup(event.IDHash)
+
+	case inventory.Entry:
+		result.Stream = &data
+
+		verb := strings.TrimPrefix(event.Type, "stream-")
+		result.Text = fmt.Sprintf("Stream %s: %s (%s)", verb, data.Name, address(&data))
+
+		if event.Type == events.TypeStreamRemoved {
+			result.Level = "warning"
+		}
+
+	case events.GrandmasterChange:
+		result.Text = fmt.Sprintf("PTP domain %d: grandmaster is %s", data.Domain, data.Grandmaster)
+
+		if data.Previous != "" {
+			result.Level = "warning"
+			result.Text = fmt.Sprintf("PTP domain %d: grandmaster changed from %s to %s", data.Domain, data.Previous, data.Grandmaster)
+		}
+
+	case events.Recording:
+		verb := strings.TrimPrefix(event.Type, "recording-")
+		result.Text = fmt.Sprintf("Recording %s: %s", verb, data.Name)
+		result.Stream = n.lookup(event.IDHash)
+
+		if data.Error != "" {
+			result.Level = "error"
+			result.Text += ": " + data.Error
+		}
+	}
+
+	return result
+}
+
+// post sends a notification to a webhook
+func (n *Notifier) post(h hook, notification *Notification) error {
+	var body bytes.Buffer
+
+	if h.template != nil {
+		if err := h.template.Execute(&body, notification); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(notification); err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.URL, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range h.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("failed to post to %s: %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/holoplot/rtp-monitor/internal/config"
+	"github.com/holoplot/rtp-monitor/internal/events"
+	"github.com/holoplot/rtp-monitor/internal/stream"
+)
+
+const testSDP = `v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=Stage Left
+c=IN IP4 239.1.1.1/32
+t=0 0
+m=audio 5004 RTP/AVP 98
+a=rtpmap:98 L24/48000/2
+a=ptime:1
+`
+
+type request struct {
+	header http.Header
+	body   string
+}
+
+// newReceiver returns a webhook endpoint that records the requests it gets
+func newReceiver(t *testing.T) (string, chan request) {
+	t.Helper()
+
+	requests := make(chan request, 16)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.Header, string(body)}
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, requests
+}
+
+func newNotifier(t *testing.T, webhooks ...config.Webhook) (*Notifier, *stream.Stream) {
+	t.Helper()
+
+	manager := stream.NewManager(t.Context(), nil)
+
+	s, err := manager.AddStreamFromSDP([]byte(testSDP), stream.DiscoveryMethodManual, "test.sdp")
+	if err != nil {
+		t.Fatalf("AddStreamFromSDP() failed: %v", err)
+	}
+
+	n, err := New(Options{Manager: manager, Webhooks: webhooks})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	return n, s
+}
+
+func alertEvent(s *stream.Stream) events.Event {
+	return events.Event{
+		Type:   events.TypeAlert,
+		Time:   time.Date(2026, 1, 12, 10, 31, 2, 0, time.UTC),
+		IDHash: s.IDHash(),
+		Data: events.Alert{
+			Level:   "warning",
+			Message: "Stage Left: 12 new sequence errors",
+			Metric:  "sequence-errors",
+			Value:   12,
+		},
+	}
+}
+
+func TestNotifyJSON(t *testing.T) {
+	url, requests := newReceiver(t)
+
+	n, s := newNotifier(t, config.Webhook{
+		URL:     url,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+
+	if errs := n.notify(alertEvent(s)); len(errs) > 0 {
+		t.Fatalf("notify() failed: %v", errs)
+	}
+
+	r := <-requests
+
+	if got := r.header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("unexpected Authorization header %q", got)
+	}
+
+	var got Notification
+	if err := json.Unmarshal([]byte(r.body), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", r.body, err)
+	}
+
+	if got.Level != "warning" || got.Metric != "sequence-errors" || got.Value != 12 {
+		t.Errorf("unexpected notification %+v", got)
+	}
+
+	if got.Stream == nil || got.Stream.IDHash != s.IDHash() || got.Stream.Name != "Stage Left" {
+		t.Errorf("unexpected stream %+v", got.Stream)
+	}
+}
+
+func TestNotifyTemplates(t *testing.T) {
+	url, requests := newReceiver(t)
+
+	n, s := newNotifier(t,
+		config.Webhook{URL: url, Template: "slack"},
+		config.Webhook{URL: url, Template: `{{.Stream.Name}} {{.Metric}}={{.Value}}`},
+		config.Webhook{URL: url, Events: []string{events.TypeStreamAdded}},
+	)
+
+	if errs := n.notify(alertEvent(s)); len(errs) > 0 {
+		t.Fatalf("notify() failed: %v", errs)
+	}
+
+	if r := <-requests; r.body != `{"text": "Stage Left: 12 new sequence errors"}` {
+		t.Errorf("unexpected Slack body %q", r.body)
+	}
+
+	if r := <-requests; r.body != "Stage Left sequence-errors=12" {
+		t.Errorf("unexpected template body %q", r.body)
+	}
+
+	select {
+	case r := <-requests:
+		t.Errorf("unexpected request %q for a webhook without alerts", r.body)
+	default:
+	}
+}
+
+func TestNotifyRemoved(t *testing.T) {
+	url, requests := newReceiver(t)
+
+	n, s := newNotifier(t, config.Webhook{URL: url, Template: "teams"})
+
+	n.notify(events.Event{
+		Type:   events.TypeStreamRemoved,
+		IDHash: s.IDHash(),
+		Data:   *n.lookup(s.IDHash()),
+	})
+
+	var card map[string]string
+	if err := json.Unmarshal([]byte((<-requests).body), &card); err != nil {
+		t.Fatalf("invalid Teams card: %v", err)
+	}
+
+	if want := "Stream removed: Stage Left (239.1.1.1:5004)"; card["text"] != want {
+		t.Errorf("got text %q, want %q", card["text"], want)
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n, s := newNotifier(t, config.Webhook{URL: server.URL})
+
+	if errs := n.notify(alertEvent(s)); len(errs) != 1 {
+		t.Errorf("expected one error, got %v", errs)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, w := range []config.Webhook{
+		{URL: "hooks.slack.com/services/x"},
+		{URL: "https://example.com", Events: []string{events.TypeStats}},
+		{URL: "https://example.com", Events: []string{"unknown"}},
+		{URL: "https://example.com", Template: "{{.Text"},
+	} {
+		if _, err := New(Options{Webhooks: []config.Webhook{w}}); err == nil {
+			t.Errorf("expected an error for %+v", w)
+		}
+	}
+}
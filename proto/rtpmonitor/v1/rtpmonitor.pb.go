@@ -0,0 +1,1400 @@
+// gRPC API of rtp-monitor, served on the --api-listen address alongside the
+// HTTP API. Go clients import the generated package
+// github.com/holoplot/rtp-monitor/proto/rtpmonitor/v1, which is regenerated
+// with "make proto". Clients in other languages are generated with protoc.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: rtpmonitor/v1/rtpmonitor.proto
+
+package rtpmonitorv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListStreamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStreamsRequest) Reset() {
+	*x = ListStreamsRequest{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStreamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStreamsRequest) ProtoMessage() {}
+
+func (x *ListStreamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStreamsRequest.ProtoReflect.Descriptor instead.
+func (*ListStreamsRequest) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{0}
+}
+
+type ListStreamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Streams       []*Stream              `protobuf:"bytes,1,rep,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStreamsResponse) Reset() {
+	*x = ListStreamsResponse{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStreamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStreamsResponse) ProtoMessage() {}
+
+func (x *ListStreamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStreamsResponse.ProtoReflect.Descriptor instead.
+func (*ListStreamsResponse) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListStreamsResponse) GetStreams() []*Stream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+type Stream struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IdHash        string                 `protobuf:"bytes,2,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Host          string                 `protobuf:"bytes,4,opt,name=host,proto3" json:"host,omitempty"`
+	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	SampleRate    uint32                 `protobuf:"varint,6,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	Channels      uint32                 `protobuf:"varint,7,opt,name=channels,proto3" json:"channels,omitempty"`
+	Discovery     string                 `protobuf:"bytes,8,opt,name=discovery,proto3" json:"discovery,omitempty"`
+	LastAnnounced *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_announced,json=lastAnnounced,proto3" json:"last_announced,omitempty"`
+	Sources       []*Source              `protobuf:"bytes,10,rep,name=sources,proto3" json:"sources,omitempty"`
+	Sdp           string                 `protobuf:"bytes,11,opt,name=sdp,proto3" json:"sdp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stream) Reset() {
+	*x = Stream{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stream) ProtoMessage() {}
+
+func (x *Stream) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stream.ProtoReflect.Descriptor instead.
+func (*Stream) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Stream) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Stream) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+func (x *Stream) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Stream) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Stream) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Stream) GetSampleRate() uint32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *Stream) GetChannels() uint32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *Stream) GetDiscovery() string {
+	if x != nil {
+		return x.Discovery
+	}
+	return ""
+}
+
+func (x *Stream) GetLastAnnounced() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAnnounced
+	}
+	return nil
+}
+
+func (x *Stream) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *Stream) GetSdp() string {
+	if x != nil {
+		return x.Sdp
+	}
+	return ""
+}
+
+// Source is one source of a stream, e.g. primary or secondary
+type Source struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Sender          string                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Destination     string                 `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Port            uint32                 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Ttl             uint32                 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	FramesPerPacket uint32                 `protobuf:"varint,5,opt,name=frames_per_packet,json=framesPerPacket,proto3" json:"frames_per_packet,omitempty"`
+	ClockDomain     string                 `protobuf:"bytes,6,opt,name=clock_domain,json=clockDomain,proto3" json:"clock_domain,omitempty"`
+	ReferenceClock  string                 `protobuf:"bytes,7,opt,name=reference_clock,json=referenceClock,proto3" json:"reference_clock,omitempty"`
+	MediaClock      string                 `protobuf:"bytes,8,opt,name=media_clock,json=mediaClock,proto3" json:"media_clock,omitempty"`
+	SyncTime        uint32                 `protobuf:"varint,9,opt,name=sync_time,json=syncTime,proto3" json:"sync_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Source) Reset() {
+	*x = Source{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source) ProtoMessage() {}
+
+func (x *Source) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source.ProtoReflect.Descriptor instead.
+func (*Source) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Source) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *Source) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Source) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Source) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Source) GetFramesPerPacket() uint32 {
+	if x != nil {
+		return x.FramesPerPacket
+	}
+	return 0
+}
+
+func (x *Source) GetClockDomain() string {
+	if x != nil {
+		return x.ClockDomain
+	}
+	return ""
+}
+
+func (x *Source) GetReferenceClock() string {
+	if x != nil {
+		return x.ReferenceClock
+	}
+	return ""
+}
+
+func (x *Source) GetMediaClock() string {
+	if x != nil {
+		return x.MediaClock
+	}
+	return ""
+}
+
+func (x *Source) GetSyncTime() uint32 {
+	if x != nil {
+		return x.SyncTime
+	}
+	return 0
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// types limits the events to the given types, e.g. "alert"
+	Types         []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is one of stream-added, stream-updated, stream-removed, alert,
+	// ptp-grandmaster and stats
+	Type   string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Time   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	IdHash string                 `protobuf:"bytes,3,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*Event_Stream
+	//	*Event_Alert
+	//	*Event_Grandmaster
+	//	*Event_Stats
+	Data          isEvent_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+func (x *Event) GetData() isEvent_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetStream() *Stream {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Stream); ok {
+			return x.Stream
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetAlert() *Alert {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Alert); ok {
+			return x.Alert
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetGrandmaster() *GrandmasterChange {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Grandmaster); ok {
+			return x.Grandmaster
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetStats() *Stats {
+	if x != nil {
+		if x, ok := x.Data.(*Event_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+type isEvent_Data interface {
+	isEvent_Data()
+}
+
+type Event_Stream struct {
+	Stream *Stream `protobuf:"bytes,4,opt,name=stream,proto3,oneof"`
+}
+
+type Event_Alert struct {
+	Alert *Alert `protobuf:"bytes,5,opt,name=alert,proto3,oneof"`
+}
+
+type Event_Grandmaster struct {
+	Grandmaster *GrandmasterChange `protobuf:"bytes,6,opt,name=grandmaster,proto3,oneof"`
+}
+
+type Event_Stats struct {
+	Stats *Stats `protobuf:"bytes,7,opt,name=stats,proto3,oneof"`
+}
+
+func (*Event_Stream) isEvent_Data() {}
+
+func (*Event_Alert) isEvent_Data() {}
+
+func (*Event_Grandmaster) isEvent_Data() {}
+
+func (*Event_Stats) isEvent_Data() {}
+
+type Alert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Metric        string                 `protobuf:"bytes,3,opt,name=metric,proto3" json:"metric,omitempty"`
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Alert) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *Alert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Alert) GetMetric() string {
+	if x != nil {
+		return x.Metric
+	}
+	return ""
+}
+
+func (x *Alert) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+type GrandmasterChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        uint32                 `protobuf:"varint,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Grandmaster   string                 `protobuf:"bytes,2,opt,name=grandmaster,proto3" json:"grandmaster,omitempty"`
+	Previous      string                 `protobuf:"bytes,3,opt,name=previous,proto3" json:"previous,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrandmasterChange) Reset() {
+	*x = GrandmasterChange{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrandmasterChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrandmasterChange) ProtoMessage() {}
+
+func (x *GrandmasterChange) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrandmasterChange.ProtoReflect.Descriptor instead.
+func (*GrandmasterChange) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GrandmasterChange) GetDomain() uint32 {
+	if x != nil {
+		return x.Domain
+	}
+	return 0
+}
+
+func (x *GrandmasterChange) GetGrandmaster() string {
+	if x != nil {
+		return x.Grandmaster
+	}
+	return ""
+}
+
+func (x *GrandmasterChange) GetPrevious() string {
+	if x != nil {
+		return x.Previous
+	}
+	return ""
+}
+
+type GetStatsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	IdHash string                 `protobuf:"bytes,1,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	// start starts collecting statistics if they are not collected yet
+	Start         bool `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetStatsRequest) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetStart() bool {
+	if x != nil {
+		return x.Start
+	}
+	return false
+}
+
+type Stats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Started       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=started,proto3" json:"started,omitempty"`
+	Sources       []*SourceStats         `protobuf:"bytes,2,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Stats) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *Stats) GetSources() []*SourceStats {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type SourceStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Packets        uint64                 `protobuf:"varint,1,opt,name=packets,proto3" json:"packets,omitempty"`
+	Bytes          uint64                 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	PacketRate     float64                `protobuf:"fixed64,3,opt,name=packet_rate,json=packetRate,proto3" json:"packet_rate,omitempty"`
+	Bitrate        float64                `protobuf:"fixed64,4,opt,name=bitrate,proto3" json:"bitrate,omitempty"`
+	SequenceErrors uint64                 `protobuf:"varint,5,opt,name=sequence_errors,json=sequenceErrors,proto3" json:"sequence_errors,omitempty"`
+	RtpErrors      uint64                 `protobuf:"varint,6,opt,name=rtp_errors,json=rtpErrors,proto3" json:"rtp_errors,omitempty"`
+	JitterSeconds  float64                `protobuf:"fixed64,7,opt,name=jitter_seconds,json=jitterSeconds,proto3" json:"jitter_seconds,omitempty"`
+	LastPacket     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_packet,json=lastPacket,proto3" json:"last_packet,omitempty"`
+	Lost           int64                  `protobuf:"varint,9,opt,name=lost,proto3" json:"lost,omitempty"`
+	Reordered      uint64                 `protobuf:"varint,10,opt,name=reordered,proto3" json:"reordered,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SourceStats) Reset() {
+	*x = SourceStats{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceStats) ProtoMessage() {}
+
+func (x *SourceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceStats.ProtoReflect.Descriptor instead.
+func (*SourceStats) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SourceStats) GetPackets() uint64 {
+	if x != nil {
+		return x.Packets
+	}
+	return 0
+}
+
+func (x *SourceStats) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *SourceStats) GetPacketRate() float64 {
+	if x != nil {
+		return x.PacketRate
+	}
+	return 0
+}
+
+func (x *SourceStats) GetBitrate() float64 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+func (x *SourceStats) GetSequenceErrors() uint64 {
+	if x != nil {
+		return x.SequenceErrors
+	}
+	return 0
+}
+
+func (x *SourceStats) GetRtpErrors() uint64 {
+	if x != nil {
+		return x.RtpErrors
+	}
+	return 0
+}
+
+func (x *SourceStats) GetJitterSeconds() float64 {
+	if x != nil {
+		return x.JitterSeconds
+	}
+	return 0
+}
+
+func (x *SourceStats) GetLastPacket() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastPacket
+	}
+	return nil
+}
+
+func (x *SourceStats) GetLost() int64 {
+	if x != nil {
+		return x.Lost
+	}
+	return 0
+}
+
+func (x *SourceStats) GetReordered() uint64 {
+	if x != nil {
+		return x.Reordered
+	}
+	return 0
+}
+
+type StartRecordingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IdHash        string                 `protobuf:"bytes,1,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	Overwrite     bool                   `protobuf:"varint,2,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRecordingRequest) Reset() {
+	*x = StartRecordingRequest{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRecordingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRecordingRequest) ProtoMessage() {}
+
+func (x *StartRecordingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRecordingRequest.ProtoReflect.Descriptor instead.
+func (*StartRecordingRequest) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StartRecordingRequest) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+func (x *StartRecordingRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type StopRecordingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IdHash        string                 `protobuf:"bytes,1,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRecordingRequest) Reset() {
+	*x = StopRecordingRequest{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRecordingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRecordingRequest) ProtoMessage() {}
+
+func (x *StopRecordingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRecordingRequest.ProtoReflect.Descriptor instead.
+func (*StopRecordingRequest) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StopRecordingRequest) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+type Recording struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IdHash        string                 `protobuf:"bytes,1,opt,name=id_hash,json=idHash,proto3" json:"id_hash,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Started       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started,proto3" json:"started,omitempty"`
+	Stopped       bool                   `protobuf:"varint,4,opt,name=stopped,proto3" json:"stopped,omitempty"`
+	Paused        bool                   `protobuf:"varint,5,opt,name=paused,proto3" json:"paused,omitempty"`
+	Pauses        []*Pause               `protobuf:"bytes,6,rep,name=pauses,proto3" json:"pauses,omitempty"`
+	Files         []*RecordedFile        `protobuf:"bytes,7,rep,name=files,proto3" json:"files,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recording) Reset() {
+	*x = Recording{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recording) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recording) ProtoMessage() {}
+
+func (x *Recording) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recording.ProtoReflect.Descriptor instead.
+func (*Recording) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Recording) GetIdHash() string {
+	if x != nil {
+		return x.IdHash
+	}
+	return ""
+}
+
+func (x *Recording) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Recording) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *Recording) GetStopped() bool {
+	if x != nil {
+		return x.Stopped
+	}
+	return false
+}
+
+func (x *Recording) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Recording) GetPauses() []*Pause {
+	if x != nil {
+		return x.Pauses
+	}
+	return nil
+}
+
+func (x *Recording) GetFiles() []*RecordedFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *Recording) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Pause struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PositionSeconds float64                `protobuf:"fixed64,1,opt,name=position_seconds,json=positionSeconds,proto3" json:"position_seconds,omitempty"`
+	Start           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End             *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Pause) Reset() {
+	*x = Pause{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pause) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pause) ProtoMessage() {}
+
+func (x *Pause) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pause.ProtoReflect.Descriptor instead.
+func (*Pause) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Pause) GetPositionSeconds() float64 {
+	if x != nil {
+		return x.PositionSeconds
+	}
+	return 0
+}
+
+func (x *Pause) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Pause) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+type RecordedFile struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Bytes           uint64                 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	RecordedSeconds float64                `protobuf:"fixed64,3,opt,name=recorded_seconds,json=recordedSeconds,proto3" json:"recorded_seconds,omitempty"`
+	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RecordedFile) Reset() {
+	*x = RecordedFile{}
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordedFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordedFile) ProtoMessage() {}
+
+func (x *RecordedFile) ProtoReflect() protoreflect.Message {
+	mi := &file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordedFile.ProtoReflect.Descriptor instead.
+func (*RecordedFile) Descriptor() ([]byte, []int) {
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RecordedFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RecordedFile) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *RecordedFile) GetRecordedSeconds() float64 {
+	if x != nil {
+		return x.RecordedSeconds
+	}
+	return 0
+}
+
+func (x *RecordedFile) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_rtpmonitor_v1_rtpmonitor_proto protoreflect.FileDescriptor
+
+const file_rtpmonitor_v1_rtpmonitor_proto_rawDesc = "" +
+	"\n" +
+	"\x1ertpmonitor/v1/rtpmonitor.proto\x12\rrtpmonitor.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x14\n" +
+	"\x12ListStreamsRequest\"F\n" +
+	"\x13ListStreamsResponse\x12/\n" +
+	"\astreams\x18\x01 \x03(\v2\x15.rtpmonitor.v1.StreamR\astreams\"\xdd\x02\n" +
+	"\x06Stream\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\aid_hash\x18\x02 \x01(\tR\x06idHash\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04host\x18\x04 \x01(\tR\x04host\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x1f\n" +
+	"\vsample_rate\x18\x06 \x01(\rR\n" +
+	"sampleRate\x12\x1a\n" +
+	"\bchannels\x18\a \x01(\rR\bchannels\x12\x1c\n" +
+	"\tdiscovery\x18\b \x01(\tR\tdiscovery\x12A\n" +
+	"\x0elast_announced\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\rlastAnnounced\x12/\n" +
+	"\asources\x18\n" +
+	" \x03(\v2\x15.rtpmonitor.v1.SourceR\asources\x12\x10\n" +
+	"\x03sdp\x18\v \x01(\tR\x03sdp\"\x9e\x02\n" +
+	"\x06Source\x12\x16\n" +
+	"\x06sender\x18\x01 \x01(\tR\x06sender\x12 \n" +
+	"\vdestination\x18\x02 \x01(\tR\vdestination\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\rR\x04port\x12\x10\n" +
+	"\x03ttl\x18\x04 \x01(\rR\x03ttl\x12*\n" +
+	"\x11frames_per_packet\x18\x05 \x01(\rR\x0fframesPerPacket\x12!\n" +
+	"\fclock_domain\x18\x06 \x01(\tR\vclockDomain\x12'\n" +
+	"\x0freference_clock\x18\a \x01(\tR\x0ereferenceClock\x12\x1f\n" +
+	"\vmedia_clock\x18\b \x01(\tR\n" +
+	"mediaClock\x12\x1b\n" +
+	"\tsync_time\x18\t \x01(\rR\bsyncTime\"*\n" +
+	"\x12WatchEventsRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\"\xbf\x02\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x17\n" +
+	"\aid_hash\x18\x03 \x01(\tR\x06idHash\x12/\n" +
+	"\x06stream\x18\x04 \x01(\v2\x15.rtpmonitor.v1.StreamH\x00R\x06stream\x12,\n" +
+	"\x05alert\x18\x05 \x01(\v2\x14.rtpmonitor.v1.AlertH\x00R\x05alert\x12D\n" +
+	"\vgrandmaster\x18\x06 \x01(\v2 .rtpmonitor.v1.GrandmasterChangeH\x00R\vgrandmaster\x12,\n" +
+	"\x05stats\x18\a \x01(\v2\x14.rtpmonitor.v1.StatsH\x00R\x05statsB\x06\n" +
+	"\x04data\"e\n" +
+	"\x05Alert\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06metric\x18\x03 \x01(\tR\x06metric\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\"i\n" +
+	"\x11GrandmasterChange\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\rR\x06domain\x12 \n" +
+	"\vgrandmaster\x18\x02 \x01(\tR\vgrandmaster\x12\x1a\n" +
+	"\bprevious\x18\x03 \x01(\tR\bprevious\"@\n" +
+	"\x0fGetStatsRequest\x12\x17\n" +
+	"\aid_hash\x18\x01 \x01(\tR\x06idHash\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\bR\x05start\"s\n" +
+	"\x05Stats\x124\n" +
+	"\astarted\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x124\n" +
+	"\asources\x18\x02 \x03(\v2\x1a.rtpmonitor.v1.SourceStatsR\asources\"\xd6\x02\n" +
+	"\vSourceStats\x12\x18\n" +
+	"\apackets\x18\x01 \x01(\x04R\apackets\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x04R\x05bytes\x12\x1f\n" +
+	"\vpacket_rate\x18\x03 \x01(\x01R\n" +
+	"packetRate\x12\x18\n" +
+	"\abitrate\x18\x04 \x01(\x01R\abitrate\x12'\n" +
+	"\x0fsequence_errors\x18\x05 \x01(\x04R\x0esequenceErrors\x12\x1d\n" +
+	"\n" +
+	"rtp_errors\x18\x06 \x01(\x04R\trtpErrors\x12%\n" +
+	"\x0ejitter_seconds\x18\a \x01(\x01R\rjitterSeconds\x12;\n" +
+	"\vlast_packet\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastPacket\x12\x12\n" +
+	"\x04lost\x18\t \x01(\x03R\x04lost\x12\x1c\n" +
+	"\treordered\x18\n" +
+	" \x01(\x04R\treordered\"N\n" +
+	"\x15StartRecordingRequest\x12\x17\n" +
+	"\aid_hash\x18\x01 \x01(\tR\x06idHash\x12\x1c\n" +
+	"\toverwrite\x18\x02 \x01(\bR\toverwrite\"/\n" +
+	"\x14StopRecordingRequest\x12\x17\n" +
+	"\aid_hash\x18\x01 \x01(\tR\x06idHash\"\x97\x02\n" +
+	"\tRecording\x12\x17\n" +
+	"\aid_hash\x18\x01 \x01(\tR\x06idHash\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x124\n" +
+	"\astarted\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x12\x18\n" +
+	"\astopped\x18\x04 \x01(\bR\astopped\x12\x16\n" +
+	"\x06paused\x18\x05 \x01(\bR\x06paused\x12,\n" +
+	"\x06pauses\x18\x06 \x03(\v2\x14.rtpmonitor.v1.PauseR\x06pauses\x121\n" +
+	"\x05files\x18\a \x03(\v2\x1b.rtpmonitor.v1.RecordedFileR\x05files\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\"\x92\x01\n" +
+	"\x05Pause\x12)\n" +
+	"\x10position_seconds\x18\x01 \x01(\x01R\x0fpositionSeconds\x120\n" +
+	"\x05start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"y\n" +
+	"\fRecordedFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x04R\x05bytes\x12)\n" +
+	"\x10recorded_seconds\x18\x03 \x01(\x01R\x0frecordedSeconds\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error2\x90\x03\n" +
+	"\n" +
+	"RTPMonitor\x12T\n" +
+	"\vListStreams\x12!.rtpmonitor.v1.ListStreamsRequest\x1a\".rtpmonitor.v1.ListStreamsResponse\x12H\n" +
+	"\vWatchEvents\x12!.rtpmonitor.v1.WatchEventsRequest\x1a\x14.rtpmonitor.v1.Event0\x01\x12@\n" +
+	"\bGetStats\x12\x1e.rtpmonitor.v1.GetStatsRequest\x1a\x14.rtpmonitor.v1.Stats\x12P\n" +
+	"\x0eStartRecording\x12$.rtpmonitor.v1.StartRecordingRequest\x1a\x18.rtpmonitor.v1.Recording\x12N\n" +
+	"\rStopRecording\x12#.rtpmonitor.v1.StopRecordingRequest\x1a\x18.rtpmonitor.v1.RecordingBBZ@github.com/holoplot/rtp-monitor/proto/rtpmonitor/v1;rtpmonitorv1b\x06proto3"
+
+var (
+	file_rtpmonitor_v1_rtpmonitor_proto_rawDescOnce sync.Once
+	file_rtpmonitor_v1_rtpmonitor_proto_rawDescData []byte
+)
+
+func file_rtpmonitor_v1_rtpmonitor_proto_rawDescGZIP() []byte {
+	file_rtpmonitor_v1_rtpmonitor_proto_rawDescOnce.Do(func() {
+		file_rtpmonitor_v1_rtpmonitor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rtpmonitor_v1_rtpmonitor_proto_rawDesc), len(file_rtpmonitor_v1_rtpmonitor_proto_rawDesc)))
+	})
+	return file_rtpmonitor_v1_rtpmonitor_proto_rawDescData
+}
+
+var file_rtpmonitor_v1_rtpmonitor_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_rtpmonitor_v1_rtpmonitor_proto_goTypes = []any{
+	(*ListStreamsRequest)(nil),    // 0: rtpmonitor.v1.ListStreamsRequest
+	(*ListStreamsResponse)(nil),   // 1: rtpmonitor.v1.ListStreamsResponse
+	(*Stream)(nil),                // 2: rtpmonitor.v1.Stream
+	(*Source)(nil),                // 3: rtpmonitor.v1.Source
+	(*WatchEventsRequest)(nil),    // 4: rtpmonitor.v1.WatchEventsRequest
+	(*Event)(nil),                 // 5: rtpmonitor.v1.Event
+	(*Alert)(nil),                 // 6: rtpmonitor.v1.Alert
+	(*GrandmasterChange)(nil),     // 7: rtpmonitor.v1.GrandmasterChange
+	(*GetStatsRequest)(nil),       // 8: rtpmonitor.v1.GetStatsRequest
+	(*Stats)(nil),                 // 9: rtpmonitor.v1.Stats
+	(*SourceStats)(nil),           // 10: rtpmonitor.v1.SourceStats
+	(*StartRecordingRequest)(nil), // 11: rtpmonitor.v1.StartRecordingRequest
+	(*StopRecordingRequest)(nil),  // 12: rtpmonitor.v1.StopRecordingRequest
+	(*Recording)(nil),             // 13: rtpmonitor.v1.Recording
+	(*Pause)(nil),                 // 14: rtpmonitor.v1.Pause
+	(*RecordedFile)(nil),          // 15: rtpmonitor.v1.RecordedFile
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_rtpmonitor_v1_rtpmonitor_proto_depIdxs = []int32{
+	2,  // 0: rtpmonitor.v1.ListStreamsResponse.streams:type_name -> rtpmonitor.v1.Stream
+	16, // 1: rtpmonitor.v1.Stream.last_announced:type_name -> google.protobuf.Timestamp
+	3,  // 2: rtpmonitor.v1.Stream.sources:type_name -> rtpmonitor.v1.Source
+	16, // 3: rtpmonitor.v1.Event.time:type_name -> google.protobuf.Timestamp
+	2,  // 4: rtpmonitor.v1.Event.stream:type_name -> rtpmonitor.v1.Stream
+	6,  // 5: rtpmonitor.v1.Event.alert:type_name -> rtpmonitor.v1.Alert
+	7,  // 6: rtpmonitor.v1.Event.grandmaster:type_name -> rtpmonitor.v1.GrandmasterChange
+	9,  // 7: rtpmonitor.v1.Event.stats:type_name -> rtpmonitor.v1.Stats
+	16, // 8: rtpmonitor.v1.Stats.started:type_name -> google.protobuf.Timestamp
+	10, // 9: rtpmonitor.v1.Stats.sources:type_name -> rtpmonitor.v1.SourceStats
+	16, // 10: rtpmonitor.v1.SourceStats.last_packet:type_name -> google.protobuf.Timestamp
+	16, // 11: rtpmonitor.v1.Recording.started:type_name -> google.protobuf.Timestamp
+	14, // 12: rtpmonitor.v1.Recording.pauses:type_name -> rtpmonitor.v1.Pause
+	15, // 13: rtpmonitor.v1.Recording.files:type_name -> rtpmonitor.v1.RecordedFile
+	16, // 14: rtpmonitor.v1.Pause.start:type_name -> google.protobuf.Timestamp
+	16, // 15: rtpmonitor.v1.Pause.end:type_name -> google.protobuf.Timestamp
+	0,  // 16: rtpmonitor.v1.RTPMonitor.ListStreams:input_type -> rtpmonitor.v1.ListStreamsRequest
+	4,  // 17: rtpmonitor.v1.RTPMonitor.WatchEvents:input_type -> rtpmonitor.v1.WatchEventsRequest
+	8,  // 18: rtpmonitor.v1.RTPMonitor.GetStats:input_type -> rtpmonitor.v1.GetStatsRequest
+	11, // 19: rtpmonitor.v1.RTPMonitor.StartRecording:input_type -> rtpmonitor.v1.StartRecordingRequest
+	12, // 20: rtpmonitor.v1.RTPMonitor.StopRecording:input_type -> rtpmonitor.v1.StopRecordingRequest
+	1,  // 21: rtpmonitor.v1.RTPMonitor.ListStreams:output_type -> rtpmonitor.v1.ListStreamsResponse
+	5,  // 22: rtpmonitor.v1.RTPMonitor.WatchEvents:output_type -> rtpmonitor.v1.Event
+	9,  // 23: rtpmonitor.v1.RTPMonitor.GetStats:output_type -> rtpmonitor.v1.Stats
+	13, // 24: rtpmonitor.v1.RTPMonitor.StartRecording:output_type -> rtpmonitor.v1.Recording
+	13, // 25: rtpmonitor.v1.RTPMonitor.StopRecording:output_type -> rtpmonitor.v1.Recording
+	21, // [21:26] is the sub-list for method output_type
+	16, // [16:21] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_rtpmonitor_v1_rtpmonitor_proto_init() }
+func file_rtpmonitor_v1_rtpmonitor_proto_init() {
+	if File_rtpmonitor_v1_rtpmonitor_proto != nil {
+		return
+	}
+	file_rtpmonitor_v1_rtpmonitor_proto_msgTypes[5].OneofWrappers = []any{
+		(*Event_Stream)(nil),
+		(*Event_Alert)(nil),
+		(*Event_Grandmaster)(nil),
+		(*Event_Stats)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rtpmonitor_v1_rtpmonitor_proto_rawDesc), len(file_rtpmonitor_v1_rtpmonitor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rtpmonitor_v1_rtpmonitor_proto_goTypes,
+		DependencyIndexes: file_rtpmonitor_v1_rtpmonitor_proto_depIdxs,
+		MessageInfos:      file_rtpmonitor_v1_rtpmonitor_proto_msgTypes,
+	}.Build()
+	File_rtpmonitor_v1_rtpmonitor_proto = out.File
+	file_rtpmonitor_v1_rtpmonitor_proto_goTypes = nil
+	file_rtpmonitor_v1_rtpmonitor_proto_depIdxs = nil
+}
@@ -0,0 +1,306 @@
+// gRPC API of rtp-monitor, served on the --api-listen address alongside the
+// HTTP API. Go clients import the generated package
+// github.com/holoplot/rtp-monitor/proto/rtpmonitor/v1, which is regenerated
+// with "make proto". Clients in other languages are generated with protoc.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: rtpmonitor/v1/rtpmonitor.proto
+
+package rtpmonitorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RTPMonitor_ListStreams_FullMethodName    = "/rtpmonitor.v1.RTPMonitor/ListStreams"
+	RTPMonitor_WatchEvents_FullMethodName    = "/rtpmonitor.v1.RTPMonitor/WatchEvents"
+	RTPMonitor_GetStats_FullMethodName       = "/rtpmonitor.v1.RTPMonitor/GetStats"
+	RTPMonitor_StartRecording_FullMethodName = "/rtpmonitor.v1.RTPMonitor/StartRecording"
+	RTPMonitor_StopRecording_FullMethodName  = "/rtpmonitor.v1.RTPMonitor/StopRecording"
+)
+
+// RTPMonitorClient is the client API for RTPMonitor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RTPMonitor queries streams and their statistics and controls recordings.
+// Streams are addressed by their ID hash.
+type RTPMonitorClient interface {
+	// ListStreams returns all discovered streams
+	ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error)
+	// WatchEvents streams events as they happen, like the WebSocket event
+	// stream of the HTTP API
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// GetStats returns the live statistics of a stream. Fails with
+	// FAILED_PRECONDITION if no statistics are collected and start is not set.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+	// StartRecording starts recording a stream to WAV files. Fails with
+	// ALREADY_EXISTS if the stream is already recorded or files exist and
+	// overwrite is not set.
+	StartRecording(ctx context.Context, in *StartRecordingRequest, opts ...grpc.CallOption) (*Recording, error)
+	// StopRecording stops a recording started with StartRecording
+	StopRecording(ctx context.Context, in *StopRecordingRequest, opts ...grpc.CallOption) (*Recording, error)
+}
+
+type rTPMonitorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRTPMonitorClient(cc grpc.ClientConnInterface) RTPMonitorClient {
+	return &rTPMonitorClient{cc}
+}
+
+func (c *rTPMonitorClient) ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStreamsResponse)
+	err := c.cc.Invoke(ctx, RTPMonitor_ListStreams_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rTPMonitorClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RTPMonitor_ServiceDesc.Streams[0], RTPMonitor_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPMonitor_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *rTPMonitorClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, RTPMonitor_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rTPMonitorClient) StartRecording(ctx context.Context, in *StartRecordingRequest, opts ...grpc.CallOption) (*Recording, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Recording)
+	err := c.cc.Invoke(ctx, RTPMonitor_StartRecording_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rTPMonitorClient) StopRecording(ctx context.Context, in *StopRecordingRequest, opts ...grpc.CallOption) (*Recording, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Recording)
+	err := c.cc.Invoke(ctx, RTPMonitor_StopRecording_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RTPMonitorServer is the server API for RTPMonitor service.
+// All implementations must embed UnimplementedRTPMonitorServer
+// for forward compatibility.
+//
+// RTPMonitor queries streams and their statistics and controls recordings.
+// Streams are addressed by their ID hash.
+type RTPMonitorServer interface {
+	// ListStreams returns all discovered streams
+	ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error)
+	// WatchEvents streams events as they happen, like the WebSocket event
+	// stream of the HTTP API
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// GetStats returns the live statistics of a stream. Fails with
+	// FAILED_PRECONDITION if no statistics are collected and start is not set.
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	// StartRecording starts recording a stream to WAV files. Fails with
+	// ALREADY_EXISTS if the stream is already recorded or files exist and
+	// overwrite is not set.
+	StartRecording(context.Context, *StartRecordingRequest) (*Recording, error)
+	// StopRecording stops a recording started with StartRecording
+	StopRecording(context.Context, *StopRecordingRequest) (*Recording, error)
+	mustEmbedUnimplementedRTPMonitorServer()
+}
+
+// UnimplementedRTPMonitorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRTPMonitorServer struct{}
+
+func (UnimplementedRTPMonitorServer) ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListStreams not implemented")
+}
+func (UnimplementedRTPMonitorServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedRTPMonitorServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedRTPMonitorServer) StartRecording(context.Context, *StartRecordingRequest) (*Recording, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartRecording not implemented")
+}
+func (UnimplementedRTPMonitorServer) StopRecording(context.Context, *StopRecordingRequest) (*Recording, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopRecording not implemented")
+}
+func (UnimplementedRTPMonitorServer) mustEmbedUnimplementedRTPMonitorServer() {}
+func (UnimplementedRTPMonitorServer) testEmbeddedByValue()                    {}
+
+// UnsafeRTPMonitorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RTPMonitorServer will
+// result in compilation errors.
+type UnsafeRTPMonitorServer interface {
+	mustEmbedUnimplementedRTPMonitorServer()
+}
+
+func RegisterRTPMonitorServer(s grpc.ServiceRegistrar, srv RTPMonitorServer) {
+	// If the following call panics, it indicates UnimplementedRTPMonitorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RTPMonitor_ServiceDesc, srv)
+}
+
+func _RTPMonitor_ListStreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPMonitorServer).ListStreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPMonitor_ListStreams_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPMonitorServer).ListStreams(ctx, req.(*ListStreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RTPMonitor_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RTPMonitorServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPMonitor_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+func _RTPMonitor_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPMonitorServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPMonitor_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPMonitorServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RTPMonitor_StartRecording_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRecordingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPMonitorServer).StartRecording(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPMonitor_StartRecording_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPMonitorServer).StartRecording(ctx, req.(*StartRecordingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RTPMonitor_StopRecording_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRecordingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPMonitorServer).StopRecording(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPMonitor_StopRecording_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPMonitorServer).StopRecording(ctx, req.(*StopRecordingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RTPMonitor_ServiceDesc is the grpc.ServiceDesc for RTPMonitor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RTPMonitor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rtpmonitor.v1.RTPMonitor",
+	HandlerType: (*RTPMonitorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListStreams",
+			Handler:    _RTPMonitor_ListStreams_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _RTPMonitor_GetStats_Handler,
+		},
+		{
+			MethodName: "StartRecording",
+			Handler:    _RTPMonitor_StartRecording_Handler,
+		},
+		{
+			MethodName: "StopRecording",
+			Handler:    _RTPMonitor_StopRecording_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _RTPMonitor_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rtpmonitor/v1/rtpmonitor.proto",
+}